@@ -1,10 +1,14 @@
 import { Elysia } from 'elysia'
+import { describeUpstreamError } from '../utils/upstream'
 
 export interface ErrorResponse {
   success: false
   error: string
   code?: string
   details?: unknown
+  // Status and error code of a failed model provider call
+  upstreamStatus?: number
+  upstreamCode?: string
 }
 
 export const errorMiddleware = new Elysia({ name: 'error' })
@@ -60,6 +64,18 @@ export const errorMiddleware = new Elysia({ name: 'error' })
         if (error instanceof Error) {
           const message = error.message
 
+          const upstream = describeUpstreamError(error)
+          if (upstream.status !== undefined || upstream.code !== undefined) {
+            set.status = 502
+            return {
+              success: false,
+              error: message,
+              code: 'UPSTREAM_ERROR',
+              upstreamStatus: upstream.status,
+              upstreamCode: upstream.code,
+            } satisfies ErrorResponse
+          }
+
           if (
             message.includes('No bearer token') ||
             message.includes('Invalid or expired token')
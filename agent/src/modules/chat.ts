@@ -5,6 +5,7 @@ import { createAuthFetcher, getBaseUrl } from '../index'
 import { bearerMiddleware } from '../middlewares/bearer'
 import { AgentSkillModel, AllowedActionModel, AttachmentModel, IdentityContextModel, InboxItemModel, MCPConnectionModel, ModelConfigModel, ScheduleModel } from '../models'
 import { sseChunked } from '../utils/sse'
+import { describeUpstreamError } from '../utils/upstream'
 
 const AgentModel = z.object({
   model: ModelConfigModel,
@@ -87,6 +88,7 @@ export const chatModule = new Elysia({ prefix: '/chat' })
       yield sseChunked(JSON.stringify({
         type: 'error',
         message,
+        ...describeUpstreamError(error),
       }))
     }
   }, {
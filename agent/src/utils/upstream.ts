@@ -0,0 +1,29 @@
+export interface UpstreamError {
+  // HTTP status returned by the model provider
+  status?: number
+  // Provider error code or type, or the network error code
+  code?: string
+}
+
+const asRecord = (value: unknown): Record<string, unknown> | undefined =>
+  typeof value === 'object' && value !== null ? value as Record<string, unknown> : undefined
+
+const asString = (value: unknown): string | undefined =>
+  typeof value === 'string' && value.trim() ? value.trim() : undefined
+
+// describeUpstreamError extracts the provider status and error code from an
+// AI SDK call error so the server can decide whether to retry or fail over.
+export function describeUpstreamError(error: unknown): UpstreamError {
+  // Retries exhausted by the AI SDK wrap the last call error.
+  const err = asRecord(asRecord(error)?.lastError) ?? asRecord(error)
+  if (!err) {
+    return {}
+  }
+  const status = typeof err.statusCode === 'number' ? err.statusCode : undefined
+  const body = asRecord(asRecord(err.data)?.error)
+  const code = asString(body?.code)
+    ?? asString(body?.type)
+    ?? asString(err.code)
+    ?? asString(asRecord(err.cause)?.code)
+  return { status, code }
+}
@@ -43,15 +43,20 @@ import (
 	"github.com/memohai/memoh/internal/inbox"
 	channelchecker "github.com/memohai/memoh/internal/healthcheck/checkers/channel"
 	mcpchecker "github.com/memohai/memoh/internal/healthcheck/checkers/mcp"
+	memorychecker "github.com/memohai/memoh/internal/healthcheck/checkers/memory"
 	"github.com/memohai/memoh/internal/logger"
 	"github.com/memohai/memoh/internal/mcp"
+	"github.com/memohai/memoh/internal/mcp/botserver"
+	"github.com/memohai/memoh/internal/mcp/catalog"
 	mcpcontainer "github.com/memohai/memoh/internal/mcp/providers/container"
 	mcpcontacts "github.com/memohai/memoh/internal/mcp/providers/contacts"
 	mcpinbox "github.com/memohai/memoh/internal/mcp/providers/inbox"
 	mcpmemory "github.com/memohai/memoh/internal/mcp/providers/memory"
 	mcpmessage "github.com/memohai/memoh/internal/mcp/providers/message"
+	mcpresources "github.com/memohai/memoh/internal/mcp/providers/resources"
 	mcpschedule "github.com/memohai/memoh/internal/mcp/providers/schedule"
 	mcpweb "github.com/memohai/memoh/internal/mcp/providers/web"
+	mcpoauth "github.com/memohai/memoh/internal/mcp/oauth"
 	mcpfederation "github.com/memohai/memoh/internal/mcp/sources/federation"
 	"github.com/memohai/memoh/internal/media"
 	"github.com/memohai/memoh/internal/memory"
@@ -63,6 +68,7 @@ import (
 	"github.com/memohai/memoh/internal/providers"
 	"github.com/memohai/memoh/internal/schedule"
 	"github.com/memohai/memoh/internal/searchproviders"
+	"github.com/memohai/memoh/internal/secrets"
 	"github.com/memohai/memoh/internal/server"
 	"github.com/memohai/memoh/internal/settings"
 	"github.com/memohai/memoh/internal/storage/providers/containerfs"
@@ -146,6 +152,7 @@ func runServe() {
 			provideQdrantStore,
 			memory.NewBM25Indexer,
 			provideMemoryService,
+			provideMessageIndex,
 
 			// domain services (auto-wired)
 			models.NewService,
@@ -168,16 +175,24 @@ func runServe() {
 			provideRouteService,
 			provideMessageService,
 			provideMediaService,
+			provideMCPOAuthService,
+			provideToolPolicyService,
+			mcp.NewToolCallLogService,
+			botserver.NewTokenService,
+			catalog.NewService,
+			provideBotMCPServer,
+			secrets.NewService,
 
 			// channel infrastructure
 			local.NewRouteHub,
 			provideChannelRegistry,
-			channel.NewStore,
+			provideChannelStore,
 			provideChannelRouter,
 			provideChannelManager,
 			provideChannelLifecycleService,
 
 			// conversation flow
+			provideRunQueue,
 			provideChatResolver,
 			provideScheduleTriggerer,
 			schedule.NewService,
@@ -202,9 +217,12 @@ func runServe() {
 			provideServerHandler(handlers.NewScheduleHandler),
 			provideServerHandler(handlers.NewSubagentHandler),
 			provideServerHandler(handlers.NewChannelHandler),
+			provideServerHandler(handlers.NewChannelOutboundHandler),
 			provideServerHandler(provideUsersHandler),
-			provideServerHandler(handlers.NewMCPHandler),
+			provideServerHandler(provideMCPHandler),
+			provideServerHandler(handlers.NewSecretsHandler),
 			provideServerHandler(handlers.NewInboxHandler),
+			provideServerHandler(handlers.NewRunsHandler),
 			provideServerHandler(provideCLIHandler),
 			provideServerHandler(provideWebHandler),
 
@@ -212,8 +230,11 @@ func runServe() {
 		),
 		fx.Invoke(
 			startMemoryWarmup,
+			startSecretsRewrap,
 			startScheduleService,
 			startChannelManager,
+			startMemorySweeper,
+			startToolCallSweeper,
 			startContainerReconciliation,
 			startServer,
 		),
@@ -353,8 +374,48 @@ func provideQdrantStore(log *slog.Logger, cfg config.Config, setup embeddingSetu
 	return store, nil
 }
 
-func provideMemoryService(log *slog.Logger, llm memory.LLM, embedder embeddings.Embedder, store *memory.QdrantStore, resolver *embeddings.Resolver, bm25 *memory.BM25Indexer, setup embeddingSetup) *memory.Service {
-	return memory.NewService(log, llm, embedder, store, resolver, bm25, setup.TextModel.ModelID, setup.MultimodalModel.ModelID)
+func provideMessageIndex(log *slog.Logger, cfg config.Config, conn *pgxpool.Pool, queries *dbsqlc.Queries, embedder embeddings.Embedder, setup embeddingSetup) (*memory.MessageIndex, error) {
+	qcfg := cfg.Qdrant
+	timeout := time.Duration(qcfg.TimeoutSeconds) * time.Second
+	collection := strings.TrimSpace(qcfg.Collection)
+	if collection == "" {
+		collection = "memory"
+	}
+	collection += "_messages"
+	var store *memory.QdrantStore
+	var err error
+	if embedder != nil {
+		store, err = memory.NewQdrantStoreWithVectors(log, qcfg.BaseURL, qcfg.APIKey, collection, map[string]int{setup.TextModel.ModelID: setup.TextModel.Dimensions}, "sparse_hash", timeout)
+	} else {
+		store, err = memory.NewQdrantStore(log, qcfg.BaseURL, qcfg.APIKey, collection, 0, "sparse_hash", timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("qdrant message history init: %w", err)
+	}
+	bm25 := memory.NewBM25Indexer(log)
+	bm25.SetStatsStore(memory.NewDBBM25StatsStore(conn, queries))
+	return memory.NewMessageIndex(log, store, embedder, setup.TextModel.ModelID, bm25), nil
+}
+
+func provideMemoryService(log *slog.Logger, cfg config.Config, conn *pgxpool.Pool, queries *dbsqlc.Queries, llm memory.LLM, embedder embeddings.Embedder, store *memory.QdrantStore, resolver *embeddings.Resolver, bm25 *memory.BM25Indexer, setup embeddingSetup) *memory.Service {
+	service := memory.NewService(log, llm, embedder, store, resolver, bm25, setup.TextModel.ModelID, setup.MultimodalModel.ModelID)
+	service.SetCompactionUndoWindow(time.Duration(cfg.Memory.CompactionUndoMinutes) * time.Minute)
+	service.SetHistoryStore(memory.NewDBHistoryStore(queries))
+	service.SetCompactionStore(memory.NewDBCompactionStore(log, queries))
+	service.SetReembedStore(memory.NewDBReembedStore(log, conn, queries))
+	bm25.SetStatsStore(memory.NewDBBM25StatsStore(conn, queries))
+	service.SetVectorStateStore(memory.NewDBVectorStateStore(queries))
+	service.SetEmbeddingModelResolver(&botEmbeddingModels{queries: queries})
+	service.SetReranker(&lazyReranker{
+		queries: queries,
+		timeout: 30 * time.Second,
+		logger:  log,
+	})
+	service.SetSweepPolicy(memory.SweepPolicy{
+		Interval:   time.Duration(cfg.Memory.SweepIntervalMinutes) * time.Minute,
+		StaleAfter: time.Duration(cfg.Memory.StaleAfterDays) * 24 * time.Hour,
+	})
+	return service
 }
 
 // ---------------------------------------------------------------------------
@@ -365,8 +426,10 @@ func provideRouteService(log *slog.Logger, queries *dbsqlc.Queries, chatService
 	return route.NewService(log, queries, chatService)
 }
 
-func provideMessageService(log *slog.Logger, queries *dbsqlc.Queries, hub *event.Hub) *message.DBService {
-	return message.NewService(log, queries, hub)
+func provideMessageService(log *slog.Logger, queries *dbsqlc.Queries, hub *event.Hub, index *memory.MessageIndex) *message.DBService {
+	service := message.NewService(log, queries, hub)
+	service.SetIndexer(index)
+	return service
 }
 
 func provideScheduleTriggerer(resolver *flow.Resolver) schedule.Triggerer {
@@ -377,11 +440,20 @@ func provideScheduleTriggerer(resolver *flow.Resolver) schedule.Triggerer {
 // conversation flow
 // ---------------------------------------------------------------------------
 
-func provideChatResolver(log *slog.Logger, cfg config.Config, modelsService *models.Service, queries *dbsqlc.Queries, memoryService *memory.Service, chatService *conversation.Service, msgService *message.DBService, settingsService *settings.Service, mediaService *media.Service, containerdHandler *handlers.ContainerdHandler, inboxService *inbox.Service) *flow.Resolver {
+func provideRunQueue(cfg config.Config) *flow.RunQueue {
+	return flow.NewRunQueue(flow.RunQueueConfig{
+		RouteConcurrency:  cfg.Runs.RouteConcurrency,
+		BotConcurrency:    cfg.Runs.BotConcurrency,
+		MaxQueuedPerRoute: cfg.Runs.MaxQueuedPerRoute,
+	})
+}
+
+func provideChatResolver(log *slog.Logger, cfg config.Config, modelsService *models.Service, queries *dbsqlc.Queries, memoryService *memory.Service, chatService *conversation.Service, msgService *message.DBService, settingsService *settings.Service, mediaService *media.Service, containerdHandler *handlers.ContainerdHandler, inboxService *inbox.Service, runQueue *flow.RunQueue) *flow.Resolver {
 	resolver := flow.NewResolver(log, modelsService, queries, memoryService, chatService, msgService, settingsService, cfg.AgentGateway.BaseURL(), 120*time.Second)
 	resolver.SetSkillLoader(&skillLoaderAdapter{handler: containerdHandler})
 	resolver.SetGatewayAssetLoader(&gatewayAssetLoaderAdapter{media: mediaService})
 	resolver.SetInboxService(inboxService)
+	resolver.SetRunQueue(runQueue)
 	return resolver
 }
 
@@ -414,20 +486,37 @@ func provideChannelRouter(
 	bindService *bind.Service,
 	mediaService *media.Service,
 	inboxService *inbox.Service,
+	runQueue *flow.RunQueue,
 	rc *boot.RuntimeConfig,
+	cfg config.Config,
 ) *inbound.ChannelInboundProcessor {
 	processor := inbound.NewChannelInboundProcessor(log, registry, routeService, msgService, resolver, identityService, botService, policyService, preauthService, bindService, rc.JwtSecret, 5*time.Minute)
 	processor.SetMediaService(mediaService)
 	processor.SetStreamObserver(local.NewRouteHubBroadcaster(hub))
 	processor.SetInboxService(inboxService)
+	processor.SetRunCanceller(runQueue)
+	processor.SetDebounceConfig(inbound.DebounceConfig{
+		Window:         time.Duration(cfg.Channels.Debounce.WindowMs) * time.Millisecond,
+		MaxWait:        time.Duration(cfg.Channels.Debounce.MaxWaitMs) * time.Millisecond,
+		CancelInFlight: cfg.Channels.Debounce.CancelInFlight,
+	})
 	return processor
 }
 
-func provideChannelManager(log *slog.Logger, registry *channel.Registry, channelStore *channel.Store, channelRouter *inbound.ChannelInboundProcessor) *channel.Manager {
+func provideChannelStore(conn *pgxpool.Pool, queries *dbsqlc.Queries, registry *channel.Registry, secretsService *secrets.Service) *channel.Store {
+	store := channel.NewStore(queries, registry)
+	store.SetPool(conn)
+	store.SetCredentialResolver(secretsService)
+	return store
+}
+
+func provideChannelManager(log *slog.Logger, registry *channel.Registry, channelStore *channel.Store, channelRouter *inbound.ChannelInboundProcessor, secretsService *secrets.Service) *channel.Manager {
 	mgr := channel.NewManager(log, registry, channelStore, channelRouter)
 	if mw := channelRouter.IdentityMiddleware(); mw != nil {
 		mgr.Use(mw)
 	}
+	mgr.SetOutboundQueue(channelStore)
+	mgr.SetCredentialResolver(secretsService)
 	return mgr
 }
 
@@ -439,11 +528,14 @@ func provideChannelLifecycleService(channelStore *channel.Store, channelManager
 // containerd handler & tool gateway
 // ---------------------------------------------------------------------------
 
-func provideContainerdHandler(log *slog.Logger, service ctr.Service, manager *mcp.Manager, cfg config.Config, botService *bots.Service, accountService *accounts.Service, policyService *policy.Service, queries *dbsqlc.Queries) *handlers.ContainerdHandler {
-	return handlers.NewContainerdHandler(log, service, manager, cfg.MCP, cfg.Containerd.Namespace, botService, accountService, policyService, queries)
+func provideContainerdHandler(log *slog.Logger, service ctr.Service, manager *mcp.Manager, cfg config.Config, botService *bots.Service, accountService *accounts.Service, policyService *policy.Service, queries *dbsqlc.Queries, mcpCatalog *catalog.Service, mcpConnService *mcp.ConnectionService, secretsService *secrets.Service) *handlers.ContainerdHandler {
+	h := handlers.NewContainerdHandler(log, service, manager, cfg.MCP, cfg.Containerd.Namespace, botService, accountService, policyService, queries)
+	h.SetMCPCatalog(mcpCatalog, mcpConnService, secretsService)
+	h.SetJWTSecret(cfg.Auth.JWTSecret)
+	return h
 }
 
-func provideToolGatewayService(log *slog.Logger, cfg config.Config, channelManager *channel.Manager, registry *channel.Registry, routeService *route.DBService, scheduleService *schedule.Service, memoryService *memory.Service, chatService *conversation.Service, accountService *accounts.Service, settingsService *settings.Service, searchProviderService *searchproviders.Service, manager *mcp.Manager, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, mediaService *media.Service, inboxService *inbox.Service) *mcp.ToolGatewayService {
+func provideToolGatewayService(lc fx.Lifecycle, log *slog.Logger, cfg config.Config, channelManager *channel.Manager, registry *channel.Registry, routeService *route.DBService, scheduleService *schedule.Service, memoryService *memory.Service, chatService *conversation.Service, botService *bots.Service, accountService *accounts.Service, settingsService *settings.Service, searchProviderService *searchproviders.Service, manager *mcp.Manager, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, mcpOAuth *mcpoauth.Service, toolPolicies *mcp.ToolPolicyService, toolCalls *mcp.ToolCallLogService, mediaService *media.Service, inboxService *inbox.Service, messageIndex *memory.MessageIndex, messageService *message.DBService, secretsService *secrets.Service) *mcp.ToolGatewayService {
 	var assetResolver mcpmessage.AssetResolver
 	if mediaService != nil {
 		assetResolver = &mediaAssetResolverAdapter{media: mediaService}
@@ -452,6 +544,10 @@ func provideToolGatewayService(log *slog.Logger, cfg config.Config, channelManag
 	contactsExec := mcpcontacts.NewExecutor(log, routeService)
 	scheduleExec := mcpschedule.NewExecutor(log, scheduleService)
 	memoryExec := mcpmemory.NewExecutor(log, memoryService, chatService, accountService)
+	memoryExec.SetHistory(messageIndex, messageService)
+	memoryExec.SetRoleResolver(&memberRoleResolverAdapter{bots: botService, accounts: accountService})
+	memoryExec.SetMemoryReader(memoryService)
+	memoryExec.SetMemoryWriter(memoryService)
 	webExec := mcpweb.NewExecutor(log, settingsService, searchProviderService)
 	inboxExec := mcpinbox.NewExecutor(log, inboxService)
 	execWorkDir := cfg.MCP.DataMount
@@ -459,15 +555,29 @@ func provideToolGatewayService(log *slog.Logger, cfg config.Config, channelManag
 		execWorkDir = config.DefaultDataMount
 	}
 	fsExec := mcpcontainer.NewExecutor(log, manager, execWorkDir)
+	resourcesExec := mcpresources.NewExecutor(log)
 
 	fedGateway := handlers.NewMCPFederationGateway(log, containerdHandler)
+	fedGateway.SetOAuthTokenSource(mcpOAuth)
+	fedGateway.SetSecretExpander(secretsService)
 	fedSource := mcpfederation.NewSource(log, fedGateway, mcpConnService)
 
 	svc := mcp.NewToolGatewayService(
 		log,
-		[]mcp.ToolExecutor{messageExec, contactsExec, scheduleExec, memoryExec, webExec, fsExec, inboxExec},
+		[]mcp.ToolExecutor{messageExec, contactsExec, scheduleExec, memoryExec, webExec, fsExec, inboxExec, resourcesExec},
 		[]mcp.ToolSource{fedSource},
 	)
+	resourcesExec.SetCatalog(svc)
+	svc.SetToolAuthorizer(toolPolicies)
+	svc.SetToolCallRecorder(toolCalls)
+	svc.SetCallLimits(mcp.NewToolCallLimits(cfg.MCP))
+	fedGateway.SetListChangedHandler(svc.Invalidate)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			fedGateway.Close()
+			return nil
+		},
+	})
 	containerdHandler.SetToolGatewayService(svc)
 	return svc
 }
@@ -476,8 +586,9 @@ func provideToolGatewayService(log *slog.Logger, cfg config.Config, channelManag
 // handler providers (interface adaptation / config extraction)
 // ---------------------------------------------------------------------------
 
-func provideMemoryHandler(log *slog.Logger, service *memory.Service, chatService *conversation.Service, accountService *accounts.Service, cfg config.Config, manager *mcp.Manager) *handlers.MemoryHandler {
+func provideMemoryHandler(log *slog.Logger, service *memory.Service, chatService *conversation.Service, accountService *accounts.Service, cfg config.Config, manager *mcp.Manager, messageIndex *memory.MessageIndex, messageService *message.DBService) *handlers.MemoryHandler {
 	h := handlers.NewMemoryHandler(log, service, chatService, accountService)
+	h.SetMessageIndex(messageIndex, messageService)
 	if manager != nil {
 		execWorkDir := cfg.MCP.DataMount
 		if strings.TrimSpace(execWorkDir) == "" {
@@ -498,6 +609,36 @@ func provideMessageHandler(log *slog.Logger, chatService *conversation.Service,
 	return h
 }
 
+func provideMCPOAuthService(log *slog.Logger, queries *dbsqlc.Queries, rc *boot.RuntimeConfig) (*mcpoauth.Service, error) {
+	service, err := mcpoauth.NewService(log, mcpoauth.NewDBTokenStore(queries), rc.JwtSecret)
+	if err != nil {
+		return nil, err
+	}
+	service.SetPendingStore(mcpoauth.NewDBPendingStore(queries))
+	return service, nil
+}
+
+func provideToolPolicyService(log *slog.Logger, queries *dbsqlc.Queries, botService *bots.Service, accountService *accounts.Service) *mcp.ToolPolicyService {
+	svc := mcp.NewToolPolicyService(log, queries)
+	svc.SetRoleResolver(&memberRoleResolverAdapter{bots: botService, accounts: accountService})
+	return svc
+}
+
+func provideMCPHandler(log *slog.Logger, service *mcp.ConnectionService, botService *bots.Service, accountService *accounts.Service, oauthService *mcpoauth.Service, toolPolicies *mcp.ToolPolicyService, toolCalls *mcp.ToolCallLogService, botServer *botserver.Server, mcpTokens *botserver.TokenService, cfg config.Config) *handlers.MCPHandler {
+	h := handlers.NewMCPHandler(log, service, botService, accountService)
+	h.SetOAuth(oauthService, cfg.Server.PublicURL)
+	h.SetToolPolicies(toolPolicies)
+	h.SetToolCalls(toolCalls)
+	h.SetBotServer(botServer, mcpTokens)
+	return h
+}
+
+func provideBotMCPServer(log *slog.Logger, cfg config.Config, toolGateway *mcp.ToolGatewayService, resolver *flow.Resolver) *botserver.Server {
+	server := botserver.NewServer(log, toolGateway)
+	server.SetChatRunner(resolver, cfg.Auth.JWTSecret)
+	return server
+}
+
 func provideMediaService(log *slog.Logger, cfg config.Config) (*media.Service, error) {
 	dataRoot := strings.TrimSpace(cfg.MCP.DataRoot)
 	if dataRoot == "" {
@@ -560,6 +701,19 @@ func startMemoryWarmup(lc fx.Lifecycle, memoryService *memory.Service, logger *s
 	})
 }
 
+func startSecretsRewrap(lc fx.Lifecycle, secretsService *secrets.Service, logger *slog.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				if _, err := secretsService.RewrapKeys(context.Background()); err != nil {
+					logger.Warn("secrets rewrap failed", slog.Any("error", err))
+				}
+			}()
+			return nil
+		},
+	})
+}
+
 func startScheduleService(lc fx.Lifecycle, scheduleService *schedule.Service) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -582,6 +736,36 @@ func startChannelManager(lc fx.Lifecycle, channelManager *channel.Manager) {
 	})
 }
 
+func startMemorySweeper(lc fx.Lifecycle, memoryService *memory.Service) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go memoryService.RunSweeper(ctx)
+			go memoryService.RunReembedJanitor(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+func startToolCallSweeper(lc fx.Lifecycle, toolCalls *mcp.ToolCallLogService, cfg config.Config) {
+	toolCalls.SetRetention(time.Duration(cfg.MCP.ToolCallRetentionDays) * 24 * time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go toolCalls.RunSweeper(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
 func startContainerReconciliation(lc fx.Lifecycle, containerdHandler *handlers.ContainerdHandler, _ *mcp.ToolGatewayService) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -591,7 +775,7 @@ func startContainerReconciliation(lc fx.Lifecycle, containerdHandler *handlers.C
 	})
 }
 
-func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutdowner fx.Shutdowner, cfg config.Config, queries *dbsqlc.Queries, botService *bots.Service, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, toolGateway *mcp.ToolGatewayService, channelManager *channel.Manager) {
+func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutdowner fx.Shutdowner, cfg config.Config, queries *dbsqlc.Queries, botService *bots.Service, containerdHandler *handlers.ContainerdHandler, mcpConnService *mcp.ConnectionService, toolGateway *mcp.ToolGatewayService, channelManager *channel.Manager, memoryService *memory.Service) {
 	fmt.Printf("Starting Memoh Agent %s\n", version.GetInfo())
 
 	lc.Append(fx.Hook{
@@ -606,6 +790,9 @@ func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutd
 			botService.AddRuntimeChecker(healthcheck.NewRuntimeCheckerAdapter(
 				channelchecker.NewChecker(logger, channelManager),
 			))
+			botService.AddRuntimeChecker(healthcheck.NewRuntimeCheckerAdapter(
+				memorychecker.NewChecker(logger, memoryService),
+			))
 
 			go func() {
 				if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -764,6 +951,55 @@ func (c *lazyLLMClient) resolve(ctx context.Context) (memory.LLM, error) {
 	return memory.NewLLMClient(c.logger, memoryProvider.BaseUrl, memoryProvider.ApiKey, memoryModel.ModelID, c.timeout)
 }
 
+// botEmbeddingModels resolves the embedding model in bot settings for
+// re-embedding jobs.
+type botEmbeddingModels struct {
+	queries *dbsqlc.Queries
+}
+
+func (b *botEmbeddingModels) BotEmbeddingModel(ctx context.Context, botID string) (memory.EmbeddingModel, bool, error) {
+	model, ok, err := models.SelectEmbeddingModelForBot(ctx, b.queries, botID)
+	if err != nil || !ok {
+		return memory.EmbeddingModel{}, false, err
+	}
+	return memory.EmbeddingModel{ModelID: model.ModelID, Dimensions: model.Dimensions}, true, nil
+}
+
+// lazyReranker resolves the rerank model of the bot in the context per call:
+// a rerank model is called through its /rerank endpoint, a chat model judges
+// relevance itself.
+type lazyReranker struct {
+	queries *dbsqlc.Queries
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+func (r *lazyReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	botID := memory.BotIDFromContext(ctx)
+	if strings.TrimSpace(botID) == "" {
+		return nil, memory.ErrRerankerNotConfigured
+	}
+	model, provider, ok, err := models.SelectRerankModelForBot(ctx, r.queries, botID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, memory.ErrRerankerNotConfigured
+	}
+	if model.Type == models.ModelTypeChat {
+		client, err := memory.NewLLMClient(r.logger, provider.BaseUrl, provider.ApiKey, model.ModelID, r.timeout)
+		if err != nil {
+			return nil, err
+		}
+		return client.Rerank(ctx, query, documents)
+	}
+	client, err := memory.NewRerankClient(r.logger, provider.BaseUrl, provider.ApiKey, model.ModelID, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return client.Rerank(ctx, query, documents)
+}
+
 // skillLoaderAdapter bridges handlers.ContainerdHandler to flow.SkillLoader.
 type skillLoaderAdapter struct {
 	handler *handlers.ContainerdHandler
@@ -786,6 +1022,22 @@ func (a *skillLoaderAdapter) LoadSkills(ctx context.Context, botID string) ([]fl
 	return entries, nil
 }
 
+// memberRoleResolverAdapter resolves tool policy roles; system admins count
+// as bot admins.
+type memberRoleResolverAdapter struct {
+	bots     *bots.Service
+	accounts *accounts.Service
+}
+
+func (a *memberRoleResolverAdapter) MemberRole(ctx context.Context, botID, userID string) (string, error) {
+	if a.accounts != nil {
+		if isAdmin, err := a.accounts.IsAdmin(ctx, userID); err == nil && isAdmin {
+			return bots.MemberRoleAdmin, nil
+		}
+	}
+	return a.bots.MemberRole(ctx, botID, userID)
+}
+
 // mediaAssetResolverAdapter bridges media.Service to the message tool's AssetResolver interface.
 type mediaAssetResolverAdapter struct {
 	media *media.Service
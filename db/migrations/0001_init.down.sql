@@ -1,3 +1,15 @@
+DROP TABLE IF EXISTS bot_secrets;
+DROP TABLE IF EXISTS bot_mcp_tokens;
+DROP TABLE IF EXISTS tool_calls;
+DROP TABLE IF EXISTS bot_tool_policies;
+DROP TABLE IF EXISTS mcp_oauth_tokens;
+DROP TABLE IF EXISTS bot_memory_vectors;
+DROP TABLE IF EXISTS bot_search_provider_fallbacks;
+DROP TABLE IF EXISTS bm25_term_stats;
+DROP TABLE IF EXISTS bm25_corpus_stats;
+DROP TABLE IF EXISTS memory_history;
+DROP TABLE IF EXISTS bot_model_fallbacks;
+DROP TABLE IF EXISTS channel_outbound_queue;
 DROP TABLE IF EXISTS bot_history_message_assets;
 DROP TABLE IF EXISTS media_assets;
 DROP TABLE IF EXISTS bot_storage_bindings;
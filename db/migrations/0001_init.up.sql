@@ -75,7 +75,7 @@ CREATE TABLE IF NOT EXISTS search_providers (
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   CONSTRAINT search_providers_name_unique UNIQUE (name),
-  CONSTRAINT search_providers_provider_check CHECK (provider IN ('brave'))
+  CONSTRAINT search_providers_provider_check CHECK (provider IN ('brave', 'searxng', 'tavily', 'bing', 'google', 'duckduckgo'))
 );
 
 CREATE TABLE IF NOT EXISTS models (
@@ -90,9 +90,9 @@ CREATE TABLE IF NOT EXISTS models (
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   CONSTRAINT models_model_id_unique UNIQUE (model_id),
-  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding')),
+  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'rerank')),
   CONSTRAINT models_dimensions_check CHECK (type != 'embedding' OR dimensions IS NOT NULL),
-  CONSTRAINT models_client_type_check CHECK (client_type IS NULL OR client_type IN ('openai-responses', 'openai-completions', 'anthropic-messages', 'google-generative-ai')),
+  CONSTRAINT models_client_type_check CHECK (client_type IS NULL OR client_type IN ('openai-responses', 'openai-completions', 'anthropic-messages', 'google-generative-ai', 'ollama', 'cohere', 'voyage')),
   CONSTRAINT models_chat_client_type_check CHECK (type != 'chat' OR client_type IS NOT NULL)
 );
 
@@ -126,6 +126,7 @@ CREATE TABLE IF NOT EXISTS bots (
   memory_model_id UUID REFERENCES models(id) ON DELETE SET NULL,
   embedding_model_id UUID REFERENCES models(id) ON DELETE SET NULL,
   search_provider_id UUID REFERENCES search_providers(id) ON DELETE SET NULL,
+  rerank_model_id UUID REFERENCES models(id) ON DELETE SET NULL,
   metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
@@ -406,3 +407,253 @@ CREATE TABLE IF NOT EXISTS bot_inbox (
 CREATE INDEX IF NOT EXISTS idx_bot_inbox_bot_unread ON bot_inbox(bot_id, created_at DESC) WHERE is_read = FALSE;
 CREATE INDEX IF NOT EXISTS idx_bot_inbox_bot_created ON bot_inbox(bot_id, created_at DESC);
 
+
+-- channel_outbound_queue: persistent outbound delivery queue with dead-letter tracking
+CREATE TABLE IF NOT EXISTS channel_outbound_queue (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  channel_type TEXT NOT NULL,
+  config_id TEXT NOT NULL DEFAULT '',
+  target TEXT NOT NULL,
+  message JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 8,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until TIMESTAMPTZ,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  deferrals INTEGER NOT NULL DEFAULT 0,
+  CONSTRAINT channel_outbound_queue_status_check CHECK (status IN ('pending', 'sending', 'delivered', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_due ON channel_outbound_queue(next_attempt_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_bot_status ON channel_outbound_queue(bot_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_target ON channel_outbound_queue(bot_id, channel_type, target, created_at) WHERE status IN ('pending', 'sending');
+
+-- bot_model_fallbacks: ordered chat model fallback chain per bot
+CREATE TABLE IF NOT EXISTS bot_model_fallbacks (
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  model_id UUID NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+  priority INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, model_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_model_fallbacks_bot_priority ON bot_model_fallbacks(bot_id, priority);
+
+-- memory_history: audit trail of memory changes
+CREATE TABLE IF NOT EXISTS memory_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  memory_id TEXT NOT NULL,
+  bot_id UUID REFERENCES bots(id) ON DELETE CASCADE,
+  event TEXT NOT NULL,
+  old_memory TEXT NOT NULL DEFAULT '',
+  new_memory TEXT NOT NULL DEFAULT '',
+  scope JSONB NOT NULL DEFAULT '{}'::jsonb,
+  actor_type TEXT NOT NULL DEFAULT 'system',
+  actor_id TEXT NOT NULL DEFAULT '',
+  message_id TEXT NOT NULL DEFAULT '',
+  metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_history_event_check CHECK (event IN ('ADD', 'UPDATE', 'DELETE', 'COMPACT', 'REVERT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_history_memory ON memory_history(memory_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_memory_history_bot ON memory_history(bot_id, created_at DESC);
+
+-- memory_compactions: compaction previews and undo snapshots
+CREATE TABLE IF NOT EXISTS memory_compactions (
+  id UUID PRIMARY KEY,
+  bot_id UUID REFERENCES bots(id) ON DELETE CASCADE,
+  scope TEXT NOT NULL,
+  status TEXT NOT NULL,
+  plan JSONB NOT NULL,
+  filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+  original JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created JSONB NOT NULL DEFAULT '[]'::jsonb,
+  locked_until TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_compactions_status_check CHECK (status IN ('preview', 'applied', 'undone'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_compactions_expires ON memory_compactions(expires_at);
+
+-- memory_reembed_jobs: re-embedding job progress and migrated collections awaiting cleanup
+CREATE TABLE IF NOT EXISTS memory_reembed_jobs (
+  id UUID PRIMARY KEY,
+  bot_id TEXT NOT NULL,
+  status TEXT NOT NULL,
+  migrates BOOLEAN NOT NULL DEFAULT false,
+  job JSONB NOT NULL,
+  heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  cleaned_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_reembed_jobs_status_check CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_bot_created ON memory_reembed_jobs(bot_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_status ON memory_reembed_jobs(status);
+
+-- bm25_corpus_stats: BM25 corpus statistics per bot and language
+CREATE TABLE IF NOT EXISTS bm25_corpus_stats (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  doc_count BIGINT NOT NULL DEFAULT 0,
+  total_doc_len BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, lang)
+);
+
+-- bm25_term_stats: BM25 document frequencies per bot, language and term
+CREATE TABLE IF NOT EXISTS bm25_term_stats (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  term TEXT NOT NULL,
+  doc_freq INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (bot_id, lang, term)
+);
+
+-- bm25_warmups: BM25 statistics rebuilt from the vector store per bot and language
+CREATE TABLE IF NOT EXISTS bm25_warmups (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  warmed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, lang)
+);
+
+-- bot_search_provider_fallbacks: ordered web search provider fallback chain per bot
+CREATE TABLE IF NOT EXISTS bot_search_provider_fallbacks (
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  search_provider_id UUID NOT NULL REFERENCES search_providers(id) ON DELETE CASCADE,
+  priority INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, search_provider_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_search_provider_fallbacks_bot_priority ON bot_search_provider_fallbacks(bot_id, priority);
+
+-- bot_memory_vectors: Qdrant named vector serving each bot's text memories
+CREATE TABLE IF NOT EXISTS bot_memory_vectors (
+  bot_id UUID PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  vector_name TEXT NOT NULL,
+  dimensions INTEGER NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_memory_vectors_dimensions_check CHECK (dimensions > 0)
+);
+
+-- mcp_oauth_tokens: encrypted OAuth client registrations and tokens for remote MCP connections
+CREATE TABLE IF NOT EXISTS mcp_oauth_tokens (
+  connection_id UUID PRIMARY KEY REFERENCES mcp_connections(id) ON DELETE CASCADE,
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  resource TEXT NOT NULL DEFAULT '',
+  issuer TEXT NOT NULL DEFAULT '',
+  token_endpoint TEXT NOT NULL,
+  client_id TEXT NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  access_token TEXT NOT NULL,
+  refresh_token TEXT NOT NULL DEFAULT '',
+  token_type TEXT NOT NULL DEFAULT 'Bearer',
+  scope TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_oauth_tokens_bot_id ON mcp_oauth_tokens(bot_id);
+
+-- mcp_oauth_pending: started OAuth authorizations waiting for their callback
+CREATE TABLE IF NOT EXISTS mcp_oauth_pending (
+  state TEXT PRIMARY KEY,
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  connection_id UUID NOT NULL REFERENCES mcp_connections(id) ON DELETE CASCADE,
+  resource TEXT NOT NULL,
+  verifier TEXT NOT NULL,
+  redirect_uri TEXT NOT NULL,
+  return_url TEXT NOT NULL DEFAULT '',
+  server JSONB NOT NULL,
+  client_id TEXT NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_oauth_pending_expires ON mcp_oauth_pending(expires_at);
+
+-- bot_tool_policies: per-bot tool allowlists and per-tool permissions
+CREATE TABLE IF NOT EXISTS bot_tool_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  tool TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  conversation_types TEXT[] NOT NULL DEFAULT '{}',
+  roles TEXT[] NOT NULL DEFAULT '{}',
+  arguments JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_tool_policies_bot_tool_unique UNIQUE (bot_id, tool)
+);
+
+-- tool_calls: audit log of tool calls made through the tool gateway
+CREATE TABLE IF NOT EXISTS tool_calls (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  chat_id TEXT NOT NULL DEFAULT '',
+  channel_identity_id TEXT NOT NULL DEFAULT '',
+  tool TEXT NOT NULL,
+  source TEXT NOT NULL,
+  connection_id UUID,
+  connection_name TEXT NOT NULL DEFAULT '',
+  arguments JSONB NOT NULL DEFAULT '{}'::jsonb,
+  arguments_hash TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT tool_calls_status_check CHECK (status IN ('ok', 'error', 'denied', 'not_found'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_bot_created ON tool_calls(bot_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_bot_tool_created ON tool_calls(bot_id, tool, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_created ON tool_calls(created_at);
+
+-- bot_mcp_tokens: scoped access tokens for external MCP clients connecting to a bot
+CREATE TABLE IF NOT EXISTS bot_mcp_tokens (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  token_hash TEXT NOT NULL,
+  token_prefix TEXT NOT NULL,
+  scopes TEXT[] NOT NULL DEFAULT '{}',
+  issued_by_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_mcp_tokens_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_mcp_tokens_bot_id ON bot_mcp_tokens(bot_id);
+
+-- bot_secrets: encrypted per-bot secrets referenced as ${secret:name} in credentials
+CREATE TABLE IF NOT EXISTS bot_secrets (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  ciphertext BYTEA NOT NULL,
+  wrapped_key BYTEA NOT NULL,
+  key_id TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  rotated_at TIMESTAMPTZ,
+  CONSTRAINT bot_secrets_bot_name_unique UNIQUE (bot_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_secrets_key_id ON bot_secrets(key_id);
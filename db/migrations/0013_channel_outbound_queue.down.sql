@@ -0,0 +1,7 @@
+-- 0013_channel_outbound_queue (rollback)
+-- Remove persistent outbound delivery queue.
+
+DROP INDEX IF EXISTS idx_channel_outbound_queue_target;
+DROP INDEX IF EXISTS idx_channel_outbound_queue_bot_status;
+DROP INDEX IF EXISTS idx_channel_outbound_queue_due;
+DROP TABLE IF EXISTS channel_outbound_queue;
@@ -0,0 +1,25 @@
+-- 0013_channel_outbound_queue
+-- Add persistent outbound delivery queue with dead-letter tracking.
+
+CREATE TABLE IF NOT EXISTS channel_outbound_queue (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  channel_type TEXT NOT NULL,
+  config_id TEXT NOT NULL DEFAULT '',
+  target TEXT NOT NULL,
+  message JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 8,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until TIMESTAMPTZ,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT channel_outbound_queue_status_check CHECK (status IN ('pending', 'sending', 'delivered', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_due ON channel_outbound_queue(next_attempt_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_bot_status ON channel_outbound_queue(bot_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_queue_target ON channel_outbound_queue(bot_id, channel_type, target, created_at) WHERE status IN ('pending', 'sending');
@@ -0,0 +1,5 @@
+-- 0014_bot_model_fallbacks (rollback)
+-- Remove per-bot chat model fallback chains.
+
+DROP INDEX IF EXISTS idx_bot_model_fallbacks_bot_priority;
+DROP TABLE IF EXISTS bot_model_fallbacks;
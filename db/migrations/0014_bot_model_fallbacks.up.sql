@@ -0,0 +1,12 @@
+-- 0014_bot_model_fallbacks
+-- Add ordered per-bot chat model fallback chains.
+
+CREATE TABLE IF NOT EXISTS bot_model_fallbacks (
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  model_id UUID NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+  priority INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, model_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_model_fallbacks_bot_priority ON bot_model_fallbacks(bot_id, priority);
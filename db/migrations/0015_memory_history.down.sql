@@ -0,0 +1,6 @@
+-- 0015_memory_history (rollback)
+-- Remove the memory audit trail.
+
+DROP INDEX IF EXISTS idx_memory_history_bot;
+DROP INDEX IF EXISTS idx_memory_history_memory;
+DROP TABLE IF EXISTS memory_history;
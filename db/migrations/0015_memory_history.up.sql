@@ -0,0 +1,21 @@
+-- 0015_memory_history
+-- Record an audit trail of memory changes.
+
+CREATE TABLE IF NOT EXISTS memory_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  memory_id TEXT NOT NULL,
+  bot_id UUID REFERENCES bots(id) ON DELETE CASCADE,
+  event TEXT NOT NULL,
+  old_memory TEXT NOT NULL DEFAULT '',
+  new_memory TEXT NOT NULL DEFAULT '',
+  scope JSONB NOT NULL DEFAULT '{}'::jsonb,
+  actor_type TEXT NOT NULL DEFAULT 'system',
+  actor_id TEXT NOT NULL DEFAULT '',
+  message_id TEXT NOT NULL DEFAULT '',
+  metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_history_event_check CHECK (event IN ('ADD', 'UPDATE', 'DELETE', 'COMPACT', 'REVERT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_history_memory ON memory_history(memory_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_memory_history_bot ON memory_history(bot_id, created_at DESC);
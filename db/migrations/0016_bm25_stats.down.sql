@@ -0,0 +1,5 @@
+-- 0016_bm25_stats (rollback)
+-- Remove persisted BM25 corpus statistics.
+
+DROP TABLE IF EXISTS bm25_term_stats;
+DROP TABLE IF EXISTS bm25_corpus_stats;
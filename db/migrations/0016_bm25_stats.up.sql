@@ -0,0 +1,19 @@
+-- 0016_bm25_stats
+-- Persist BM25 corpus statistics per bot and language.
+
+CREATE TABLE IF NOT EXISTS bm25_corpus_stats (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  doc_count BIGINT NOT NULL DEFAULT 0,
+  total_doc_len BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, lang)
+);
+
+CREATE TABLE IF NOT EXISTS bm25_term_stats (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  term TEXT NOT NULL,
+  doc_freq INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (bot_id, lang, term)
+);
@@ -0,0 +1,8 @@
+-- 0017_rerank_models (rollback)
+-- Remove the per-bot rerank model and the rerank model type.
+
+ALTER TABLE bots DROP COLUMN IF EXISTS rerank_model_id;
+
+DELETE FROM models WHERE type = 'rerank';
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding'));
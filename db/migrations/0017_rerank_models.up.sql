@@ -0,0 +1,7 @@
+-- 0017_rerank_models
+-- Add the rerank model type and a per-bot rerank model for memory search.
+
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'rerank'));
+
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS rerank_model_id UUID REFERENCES models(id) ON DELETE SET NULL;
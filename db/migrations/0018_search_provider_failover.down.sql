@@ -0,0 +1,9 @@
+-- 0018_search_provider_failover (rollback)
+-- Remove per-bot search provider fallbacks and the additional search providers.
+
+DROP INDEX IF EXISTS idx_bot_search_provider_fallbacks_bot_priority;
+DROP TABLE IF EXISTS bot_search_provider_fallbacks;
+
+DELETE FROM search_providers WHERE provider <> 'brave';
+ALTER TABLE search_providers DROP CONSTRAINT IF EXISTS search_providers_provider_check;
+ALTER TABLE search_providers ADD CONSTRAINT search_providers_provider_check CHECK (provider IN ('brave'));
@@ -0,0 +1,15 @@
+-- 0018_search_provider_failover
+-- Add more web search providers and ordered per-bot search provider fallbacks.
+
+ALTER TABLE search_providers DROP CONSTRAINT IF EXISTS search_providers_provider_check;
+ALTER TABLE search_providers ADD CONSTRAINT search_providers_provider_check CHECK (provider IN ('brave', 'searxng', 'tavily', 'bing', 'google', 'duckduckgo'));
+
+CREATE TABLE IF NOT EXISTS bot_search_provider_fallbacks (
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  search_provider_id UUID NOT NULL REFERENCES search_providers(id) ON DELETE CASCADE,
+  priority INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, search_provider_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_search_provider_fallbacks_bot_priority ON bot_search_provider_fallbacks(bot_id, priority);
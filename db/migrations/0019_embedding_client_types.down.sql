@@ -0,0 +1,6 @@
+-- 0019_embedding_client_types (rollback)
+-- Remove the embedding-only client types.
+
+UPDATE models SET client_type = NULL WHERE client_type IN ('ollama', 'cohere', 'voyage');
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_client_type_check;
+ALTER TABLE models ADD CONSTRAINT models_client_type_check CHECK (client_type IS NULL OR client_type IN ('openai-responses', 'openai-completions', 'anthropic-messages', 'google-generative-ai'));
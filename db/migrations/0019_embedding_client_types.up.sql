@@ -0,0 +1,5 @@
+-- 0019_embedding_client_types
+-- Allow the embedding-only ollama, cohere and voyage client types.
+
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_client_type_check;
+ALTER TABLE models ADD CONSTRAINT models_client_type_check CHECK (client_type IS NULL OR client_type IN ('openai-responses', 'openai-completions', 'anthropic-messages', 'google-generative-ai', 'ollama', 'cohere', 'voyage'));
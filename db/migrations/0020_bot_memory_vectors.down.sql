@@ -0,0 +1,4 @@
+-- 0020_bot_memory_vectors (rollback)
+-- Drop the per-bot memory vector assignments.
+
+DROP TABLE IF EXISTS bot_memory_vectors;
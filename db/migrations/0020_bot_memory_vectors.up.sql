@@ -0,0 +1,10 @@
+-- 0020_bot_memory_vectors
+-- Track which Qdrant named vector serves each bot's text memories.
+
+CREATE TABLE IF NOT EXISTS bot_memory_vectors (
+  bot_id UUID PRIMARY KEY REFERENCES bots(id) ON DELETE CASCADE,
+  vector_name TEXT NOT NULL,
+  dimensions INTEGER NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_memory_vectors_dimensions_check CHECK (dimensions > 0)
+);
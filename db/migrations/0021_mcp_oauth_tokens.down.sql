@@ -0,0 +1,4 @@
+-- 0021_mcp_oauth_tokens (rollback)
+-- Drop stored MCP OAuth tokens.
+
+DROP TABLE IF EXISTS mcp_oauth_tokens;
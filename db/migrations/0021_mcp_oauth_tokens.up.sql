@@ -0,0 +1,20 @@
+-- 0021_mcp_oauth_tokens
+-- Store encrypted OAuth client registrations and tokens for remote MCP connections.
+
+CREATE TABLE IF NOT EXISTS mcp_oauth_tokens (
+  connection_id UUID PRIMARY KEY REFERENCES mcp_connections(id) ON DELETE CASCADE,
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  issuer TEXT NOT NULL DEFAULT '',
+  token_endpoint TEXT NOT NULL,
+  client_id TEXT NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  access_token TEXT NOT NULL,
+  refresh_token TEXT NOT NULL DEFAULT '',
+  token_type TEXT NOT NULL DEFAULT 'Bearer',
+  scope TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_oauth_tokens_bot_id ON mcp_oauth_tokens(bot_id);
@@ -0,0 +1,4 @@
+-- 0022_bot_tool_policies (rollback)
+-- Drop per-bot tool policies.
+
+DROP TABLE IF EXISTS bot_tool_policies;
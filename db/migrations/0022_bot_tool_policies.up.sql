@@ -0,0 +1,15 @@
+-- 0022_bot_tool_policies
+-- Per-bot tool policies: enable/disable tools and restrict them by conversation type, member role and arguments.
+
+CREATE TABLE IF NOT EXISTS bot_tool_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  tool TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  conversation_types TEXT[] NOT NULL DEFAULT '{}',
+  roles TEXT[] NOT NULL DEFAULT '{}',
+  arguments JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_tool_policies_bot_tool_unique UNIQUE (bot_id, tool)
+);
@@ -0,0 +1,4 @@
+-- 0023_tool_calls (rollback)
+-- Drop the tool call audit log.
+
+DROP TABLE IF EXISTS tool_calls;
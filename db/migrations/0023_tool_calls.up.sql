@@ -0,0 +1,23 @@
+-- 0023_tool_calls
+-- Audit log of tool calls made through the tool gateway, for usage analytics.
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  chat_id TEXT NOT NULL DEFAULT '',
+  channel_identity_id TEXT NOT NULL DEFAULT '',
+  tool TEXT NOT NULL,
+  source TEXT NOT NULL,
+  connection_id UUID,
+  connection_name TEXT NOT NULL DEFAULT '',
+  arguments JSONB NOT NULL DEFAULT '{}'::jsonb,
+  arguments_hash TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT tool_calls_status_check CHECK (status IN ('ok', 'error', 'denied', 'not_found'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_bot_created ON tool_calls(bot_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_bot_tool_created ON tool_calls(bot_id, tool, created_at DESC);
@@ -0,0 +1,4 @@
+-- 0024_bot_mcp_tokens (rollback)
+-- Drop scoped access tokens for external MCP clients.
+
+DROP TABLE IF EXISTS bot_mcp_tokens;
@@ -0,0 +1,4 @@
+-- 0025_bot_secrets (rollback)
+-- Drop encrypted per-bot secrets.
+
+DROP TABLE IF EXISTS bot_secrets;
@@ -0,0 +1,19 @@
+-- 0025_bot_secrets
+-- Encrypted per-bot secrets referenced as ${secret:name} in credentials.
+
+CREATE TABLE IF NOT EXISTS bot_secrets (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  ciphertext BYTEA NOT NULL,
+  wrapped_key BYTEA NOT NULL,
+  key_id TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  rotated_at TIMESTAMPTZ,
+  CONSTRAINT bot_secrets_bot_name_unique UNIQUE (bot_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_secrets_key_id ON bot_secrets(key_id);
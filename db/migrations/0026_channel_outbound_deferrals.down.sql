@@ -0,0 +1,4 @@
+-- 0026_channel_outbound_deferrals (rollback)
+-- Drop outbound deferral counts.
+
+ALTER TABLE channel_outbound_queue DROP COLUMN IF EXISTS deferrals;
@@ -0,0 +1,5 @@
+-- 0026_channel_outbound_deferrals
+-- Count rate-limit deferrals of outbound deliveries so endlessly rate-limited
+-- targets end up in the dead-letter queue.
+
+ALTER TABLE channel_outbound_queue ADD COLUMN IF NOT EXISTS deferrals INTEGER NOT NULL DEFAULT 0;
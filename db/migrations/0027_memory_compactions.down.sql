@@ -0,0 +1,4 @@
+-- 0027_memory_compactions (rollback)
+-- Drop persisted memory compactions.
+
+DROP TABLE IF EXISTS memory_compactions;
@@ -0,0 +1,21 @@
+-- 0027_memory_compactions
+-- Persist memory compaction previews and undo snapshots so they survive
+-- restarts and are shared between server instances.
+
+CREATE TABLE IF NOT EXISTS memory_compactions (
+  id UUID PRIMARY KEY,
+  bot_id UUID REFERENCES bots(id) ON DELETE CASCADE,
+  scope TEXT NOT NULL,
+  status TEXT NOT NULL,
+  plan JSONB NOT NULL,
+  filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+  original JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created JSONB NOT NULL DEFAULT '[]'::jsonb,
+  locked_until TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_compactions_status_check CHECK (status IN ('preview', 'applied', 'undone'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_compactions_expires ON memory_compactions(expires_at);
@@ -0,0 +1,4 @@
+-- 0028_bm25_warmups (rollback)
+-- Drop BM25 warmup markers.
+
+DROP TABLE IF EXISTS bm25_warmups;
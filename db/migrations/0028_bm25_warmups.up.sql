@@ -0,0 +1,10 @@
+-- 0028_bm25_warmups
+-- Mark the BM25 statistics of each bot and language that were rebuilt from
+-- the vector store, so warmup runs per bot and language instead of globally.
+
+CREATE TABLE IF NOT EXISTS bm25_warmups (
+  bot_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  warmed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bot_id, lang)
+);
@@ -0,0 +1,4 @@
+-- 0029_memory_reembed_jobs (rollback)
+-- Drop persisted memory re-embedding jobs.
+
+DROP TABLE IF EXISTS memory_reembed_jobs;
@@ -0,0 +1,20 @@
+-- 0029_memory_reembed_jobs
+-- Persist memory re-embedding jobs so their progress is shared between
+-- server instances and collections left behind by migrations can be dropped.
+
+CREATE TABLE IF NOT EXISTS memory_reembed_jobs (
+  id UUID PRIMARY KEY,
+  bot_id TEXT NOT NULL,
+  status TEXT NOT NULL,
+  migrates BOOLEAN NOT NULL DEFAULT false,
+  job JSONB NOT NULL,
+  heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  cleaned_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT memory_reembed_jobs_status_check CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_bot_created ON memory_reembed_jobs(bot_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_memory_reembed_jobs_status ON memory_reembed_jobs(status);
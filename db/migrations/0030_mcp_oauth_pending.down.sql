@@ -0,0 +1,4 @@
+-- 0030_mcp_oauth_pending (rollback)
+-- Drop persisted MCP OAuth authorizations.
+
+DROP TABLE IF EXISTS mcp_oauth_pending;
@@ -0,0 +1,20 @@
+-- 0030_mcp_oauth_pending
+-- Persist started MCP OAuth authorizations so the callback can be completed
+-- on any server instance and survives restarts.
+
+CREATE TABLE IF NOT EXISTS mcp_oauth_pending (
+  state TEXT PRIMARY KEY,
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  connection_id UUID NOT NULL REFERENCES mcp_connections(id) ON DELETE CASCADE,
+  resource TEXT NOT NULL,
+  verifier TEXT NOT NULL,
+  redirect_uri TEXT NOT NULL,
+  return_url TEXT NOT NULL DEFAULT '',
+  server JSONB NOT NULL,
+  client_id TEXT NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_oauth_pending_expires ON mcp_oauth_pending(expires_at);
@@ -0,0 +1,4 @@
+-- 0031_tool_calls_created_index (rollback)
+-- Drop the tool call time index.
+
+DROP INDEX IF EXISTS idx_tool_calls_created;
@@ -0,0 +1,4 @@
+-- 0031_tool_calls_created_index
+-- Index tool calls by time so the retention sweep does not scan the table.
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_created ON tool_calls(created_at);
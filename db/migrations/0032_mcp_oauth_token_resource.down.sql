@@ -0,0 +1,4 @@
+-- 0032_mcp_oauth_token_resource (rollback)
+-- Drop the server URL of stored MCP OAuth tokens.
+
+ALTER TABLE mcp_oauth_tokens DROP COLUMN IF EXISTS resource;
@@ -0,0 +1,10 @@
+-- 0032_mcp_oauth_token_resource
+-- Bind stored MCP OAuth tokens to the server URL they were issued for.
+
+ALTER TABLE mcp_oauth_tokens ADD COLUMN IF NOT EXISTS resource TEXT NOT NULL DEFAULT '';
+
+UPDATE mcp_oauth_tokens
+SET resource = btrim(mcp_connections.config->>'url')
+FROM mcp_connections
+WHERE mcp_connections.id = mcp_oauth_tokens.connection_id
+  AND mcp_oauth_tokens.resource = '';
@@ -0,0 +1,86 @@
+-- name: IncrementBM25Corpus :one
+INSERT INTO bm25_corpus_stats (bot_id, lang, doc_count, total_doc_len)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (bot_id, lang) DO UPDATE
+SET doc_count = bm25_corpus_stats.doc_count + 1,
+    total_doc_len = bm25_corpus_stats.total_doc_len + EXCLUDED.total_doc_len,
+    updated_at = now()
+RETURNING bot_id, lang, doc_count, total_doc_len, updated_at;
+
+-- name: DecrementBM25Corpus :exec
+UPDATE bm25_corpus_stats
+SET doc_count = GREATEST(doc_count - 1, 0),
+    total_doc_len = GREATEST(total_doc_len - $3, 0),
+    updated_at = now()
+WHERE bot_id = $1
+  AND lang = $2;
+
+-- name: GetBM25Corpus :one
+SELECT bot_id, lang, doc_count, total_doc_len, updated_at
+FROM bm25_corpus_stats
+WHERE bot_id = $1
+  AND lang = $2;
+
+-- name: ReplaceBM25Corpus :exec
+INSERT INTO bm25_corpus_stats (bot_id, lang, doc_count, total_doc_len)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (bot_id, lang) DO UPDATE
+SET doc_count = EXCLUDED.doc_count,
+    total_doc_len = EXCLUDED.total_doc_len,
+    updated_at = now();
+
+-- name: LockBM25Corpus :exec
+SELECT pg_advisory_xact_lock(hashtext('bm25:' || sqlc.arg(bot_id)::text || '/' || sqlc.arg(lang)::text));
+
+-- name: LockBM25CorpusShared :exec
+SELECT pg_advisory_xact_lock_shared(hashtext('bm25:' || sqlc.arg(bot_id)::text || '/' || sqlc.arg(lang)::text));
+
+-- name: IsBM25CorpusWarmed :one
+SELECT EXISTS (
+  SELECT 1 FROM bm25_warmups
+  WHERE bot_id = $1
+    AND lang = $2
+) AS warmed;
+
+-- name: MarkBM25CorpusWarmed :exec
+INSERT INTO bm25_warmups (bot_id, lang)
+VALUES ($1, $2)
+ON CONFLICT (bot_id, lang) DO UPDATE
+SET warmed_at = now();
+
+-- name: IncrementBM25Terms :many
+INSERT INTO bm25_term_stats (bot_id, lang, term, doc_freq)
+SELECT sqlc.arg(bot_id)::text, sqlc.arg(lang)::text, unnest(sqlc.arg(terms)::text[]), 1
+ON CONFLICT (bot_id, lang, term) DO UPDATE
+SET doc_freq = bm25_term_stats.doc_freq + 1
+RETURNING term, doc_freq;
+
+-- name: DecrementBM25Terms :exec
+UPDATE bm25_term_stats
+SET doc_freq = doc_freq - 1
+WHERE bot_id = $1
+  AND lang = $2
+  AND term = ANY($3::text[]);
+
+-- name: DeleteEmptyBM25Terms :exec
+DELETE FROM bm25_term_stats
+WHERE bot_id = $1
+  AND lang = $2
+  AND term = ANY($3::text[])
+  AND doc_freq <= 0;
+
+-- name: ListBM25TermStats :many
+SELECT term, doc_freq
+FROM bm25_term_stats
+WHERE bot_id = $1
+  AND lang = $2
+  AND term = ANY($3::text[]);
+
+-- name: DeleteBM25TermsByCorpus :exec
+DELETE FROM bm25_term_stats
+WHERE bot_id = $1
+  AND lang = $2;
+
+-- name: InsertBM25Terms :exec
+INSERT INTO bm25_term_stats (bot_id, lang, term, doc_freq)
+SELECT sqlc.arg(bot_id)::text, sqlc.arg(lang)::text, unnest(sqlc.arg(terms)::text[]), unnest(sqlc.arg(doc_freqs)::int[]);
@@ -0,0 +1,13 @@
+-- name: GetBotMemoryVector :one
+SELECT bot_id, vector_name, dimensions, updated_at
+FROM bot_memory_vectors
+WHERE bot_id = $1;
+
+-- name: UpsertBotMemoryVector :one
+INSERT INTO bot_memory_vectors (bot_id, vector_name, dimensions)
+VALUES ($1, $2, $3)
+ON CONFLICT (bot_id) DO UPDATE
+SET vector_name = EXCLUDED.vector_name,
+    dimensions = EXCLUDED.dimensions,
+    updated_at = now()
+RETURNING bot_id, vector_name, dimensions, updated_at;
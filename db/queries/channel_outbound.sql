@@ -0,0 +1,111 @@
+-- name: EnqueueChannelOutbound :one
+INSERT INTO channel_outbound_queue (bot_id, channel_type, config_id, target, message, max_attempts)
+VALUES (sqlc.arg(bot_id), sqlc.arg(channel_type), sqlc.arg(config_id), sqlc.arg(target), sqlc.arg(message), sqlc.arg(max_attempts))
+RETURNING *;
+
+-- name: ClaimDueChannelOutbound :many
+UPDATE channel_outbound_queue
+SET status = 'sending',
+    attempts = attempts + 1,
+    locked_until = sqlc.arg(locked_until)::timestamptz,
+    updated_at = now()
+WHERE id IN (
+  SELECT q.id FROM channel_outbound_queue q
+  WHERE ((q.status = 'pending' AND q.next_attempt_at <= now())
+     OR (q.status = 'sending' AND q.locked_until < now()))
+    AND NOT EXISTS (
+      SELECT 1 FROM channel_outbound_queue p
+      WHERE p.bot_id = q.bot_id
+        AND p.channel_type = q.channel_type
+        AND p.target = q.target
+        AND p.status IN ('pending', 'sending')
+        AND p.created_at < q.created_at
+    )
+  ORDER BY q.created_at ASC
+  LIMIT sqlc.arg(max_count)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkChannelOutboundDelivered :exec
+UPDATE channel_outbound_queue
+SET status = 'delivered',
+    last_error = '',
+    locked_until = NULL,
+    delivered_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: RescheduleChannelOutbound :exec
+UPDATE channel_outbound_queue
+SET status = 'pending',
+    attempts = attempts - CASE WHEN sqlc.arg(refund_attempt)::boolean THEN 1 ELSE 0 END,
+    deferrals = deferrals + CASE WHEN sqlc.arg(refund_attempt)::boolean THEN 1 ELSE 0 END,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    locked_until = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: MarkChannelOutboundDead :exec
+UPDATE channel_outbound_queue
+SET status = 'dead',
+    last_error = sqlc.arg(last_error),
+    locked_until = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: GetChannelOutboundByID :one
+SELECT * FROM channel_outbound_queue
+WHERE id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListChannelOutboundByBot :many
+SELECT * FROM channel_outbound_queue
+WHERE bot_id = sqlc.arg(bot_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(channel_type)::text IS NULL OR channel_type = sqlc.narg(channel_type)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_count)
+OFFSET sqlc.arg(item_offset);
+
+-- name: CountChannelOutboundByStatus :many
+SELECT status, count(*)::bigint AS count FROM channel_outbound_queue
+WHERE bot_id = sqlc.arg(bot_id)
+GROUP BY status;
+
+-- name: ReplayChannelOutbound :one
+UPDATE channel_outbound_queue
+SET status = 'pending',
+    attempts = 0,
+    deferrals = 0,
+    last_error = '',
+    next_attempt_at = now(),
+    locked_until = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id)
+  AND status = 'dead'
+RETURNING *;
+
+-- name: ReplayDeadChannelOutboundByBot :execrows
+UPDATE channel_outbound_queue
+SET status = 'pending',
+    attempts = 0,
+    deferrals = 0,
+    last_error = '',
+    next_attempt_at = now(),
+    locked_until = NULL,
+    updated_at = now()
+WHERE bot_id = sqlc.arg(bot_id)
+  AND status = 'dead';
+
+-- name: DeleteChannelOutbound :exec
+DELETE FROM channel_outbound_queue
+WHERE id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: PruneDeliveredChannelOutbound :execrows
+DELETE FROM channel_outbound_queue
+WHERE status = 'delivered'
+  AND delivered_at < sqlc.arg(before)::timestamptz;
@@ -0,0 +1,39 @@
+-- name: GetMCPOAuthToken :one
+SELECT connection_id, bot_id, resource, issuer, token_endpoint, client_id, client_secret, access_token, refresh_token, token_type, scope, expires_at, created_at, updated_at
+FROM mcp_oauth_tokens
+WHERE connection_id = $1;
+
+-- name: UpsertMCPOAuthToken :one
+INSERT INTO mcp_oauth_tokens (connection_id, bot_id, resource, issuer, token_endpoint, client_id, client_secret, access_token, refresh_token, token_type, scope, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (connection_id) DO UPDATE
+SET resource = EXCLUDED.resource,
+    issuer = EXCLUDED.issuer,
+    token_endpoint = EXCLUDED.token_endpoint,
+    client_id = EXCLUDED.client_id,
+    client_secret = EXCLUDED.client_secret,
+    access_token = EXCLUDED.access_token,
+    refresh_token = EXCLUDED.refresh_token,
+    token_type = EXCLUDED.token_type,
+    scope = EXCLUDED.scope,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = now()
+RETURNING connection_id, bot_id, resource, issuer, token_endpoint, client_id, client_secret, access_token, refresh_token, token_type, scope, expires_at, created_at, updated_at;
+
+-- name: DeleteMCPOAuthToken :exec
+DELETE FROM mcp_oauth_tokens
+WHERE connection_id = $1;
+
+-- name: InsertMCPOAuthPending :exec
+INSERT INTO mcp_oauth_pending (state, bot_id, connection_id, resource, verifier, redirect_uri, return_url, server, client_id, client_secret, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: TakeMCPOAuthPending :one
+DELETE FROM mcp_oauth_pending
+WHERE state = $1
+  AND expires_at > now()
+RETURNING state, bot_id, connection_id, resource, verifier, redirect_uri, return_url, server, client_id, client_secret, expires_at, created_at;
+
+-- name: DeleteExpiredMCPOAuthPending :exec
+DELETE FROM mcp_oauth_pending
+WHERE expires_at <= now();
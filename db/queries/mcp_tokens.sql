@@ -0,0 +1,24 @@
+-- name: CreateBotMCPToken :one
+INSERT INTO bot_mcp_tokens (bot_id, name, token_hash, token_prefix, scopes, issued_by_user_id, expires_at)
+VALUES (sqlc.arg(bot_id), sqlc.arg(name), sqlc.arg(token_hash), sqlc.arg(token_prefix), sqlc.arg(scopes), sqlc.arg(issued_by_user_id), sqlc.narg(expires_at))
+RETURNING *;
+
+-- name: ListBotMCPTokens :many
+SELECT * FROM bot_mcp_tokens
+WHERE bot_id = sqlc.arg(bot_id)
+ORDER BY created_at DESC;
+
+-- name: GetBotMCPTokenByHash :one
+SELECT * FROM bot_mcp_tokens
+WHERE token_hash = sqlc.arg(token_hash);
+
+-- name: TouchBotMCPToken :exec
+UPDATE bot_mcp_tokens
+SET last_used_at = now()
+WHERE id = sqlc.arg(id)
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: DeleteBotMCPToken :execrows
+DELETE FROM bot_mcp_tokens
+WHERE id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
@@ -0,0 +1,35 @@
+-- name: UpsertMemoryCompaction :exec
+INSERT INTO memory_compactions (id, bot_id, scope, status, plan, filters, original, created, expires_at)
+VALUES (sqlc.arg(id), sqlc.arg(bot_id), sqlc.arg(scope), sqlc.arg(status), sqlc.arg(plan), sqlc.arg(filters), sqlc.arg(original), sqlc.arg(created), sqlc.arg(expires_at))
+ON CONFLICT (id) DO UPDATE SET
+  status = EXCLUDED.status,
+  plan = EXCLUDED.plan,
+  created = EXCLUDED.created,
+  expires_at = EXCLUDED.expires_at,
+  locked_until = NULL,
+  updated_at = now();
+
+-- name: GetMemoryCompaction :one
+SELECT id, bot_id, scope, status, plan, filters, original, created, locked_until, expires_at, created_at, updated_at
+FROM memory_compactions
+WHERE id = sqlc.arg(id)
+  AND scope = sqlc.arg(scope)
+  AND expires_at > now();
+
+-- name: LockMemoryCompaction :execrows
+UPDATE memory_compactions
+SET locked_until = sqlc.arg(locked_until)::timestamptz,
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND expires_at > now()
+  AND (locked_until IS NULL OR locked_until < now());
+
+-- name: UnlockMemoryCompaction :exec
+UPDATE memory_compactions
+SET locked_until = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteExpiredMemoryCompactions :execrows
+DELETE FROM memory_compactions
+WHERE expires_at < now();
@@ -0,0 +1,17 @@
+-- name: InsertMemoryHistory :one
+INSERT INTO memory_history (memory_id, bot_id, event, old_memory, new_memory, scope, actor_type, actor_id, message_id, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, memory_id, bot_id, event, old_memory, new_memory, scope, actor_type, actor_id, message_id, metadata, created_at;
+
+-- name: ListMemoryHistory :many
+SELECT id, memory_id, bot_id, event, old_memory, new_memory, scope, actor_type, actor_id, message_id, metadata, created_at
+FROM memory_history
+WHERE bot_id = $1
+  AND memory_id = $2
+ORDER BY created_at DESC
+LIMIT $3;
+
+-- name: GetMemoryHistoryByID :one
+SELECT id, memory_id, bot_id, event, old_memory, new_memory, scope, actor_type, actor_id, message_id, metadata, created_at
+FROM memory_history
+WHERE id = $1;
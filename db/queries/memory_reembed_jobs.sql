@@ -0,0 +1,63 @@
+-- name: LockMemoryReembedJobs :exec
+SELECT pg_advisory_xact_lock(hashtext('memory_reembed_jobs'));
+
+-- name: ListRunningMemoryReembedJobs :many
+SELECT bot_id, migrates
+FROM memory_reembed_jobs
+WHERE status = 'running';
+
+-- name: InsertMemoryReembedJob :exec
+INSERT INTO memory_reembed_jobs (id, bot_id, status, migrates, job)
+VALUES (sqlc.arg(id), sqlc.arg(bot_id), sqlc.arg(status), sqlc.arg(migrates), sqlc.arg(job));
+
+-- name: UpdateMemoryReembedJob :exec
+UPDATE memory_reembed_jobs
+SET status = sqlc.arg(status),
+    job = sqlc.arg(job),
+    finished_at = sqlc.arg(finished_at),
+    heartbeat_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: TouchMemoryReembedJob :exec
+UPDATE memory_reembed_jobs
+SET heartbeat_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = 'running';
+
+-- name: GetMemoryReembedJob :one
+SELECT job
+FROM memory_reembed_jobs
+WHERE id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: GetLatestMemoryReembedJob :one
+SELECT job
+FROM memory_reembed_jobs
+WHERE bot_id = sqlc.arg(bot_id)
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListStaleMemoryReembedJobs :many
+SELECT job
+FROM memory_reembed_jobs
+WHERE status = 'running'
+  AND heartbeat_at < sqlc.arg(heartbeat_before)::timestamptz;
+
+-- name: ListUncleanedMemoryReembedJobs :many
+SELECT job
+FROM memory_reembed_jobs
+WHERE migrates
+  AND status <> 'running'
+  AND cleaned_at IS NULL;
+
+-- name: MarkMemoryReembedJobCleaned :exec
+UPDATE memory_reembed_jobs
+SET cleaned_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteExpiredMemoryReembedJobs :execrows
+DELETE FROM memory_reembed_jobs
+WHERE finished_at < sqlc.arg(finished_before)::timestamptz
+  AND (NOT migrates OR cleaned_at IS NOT NULL);
@@ -134,6 +134,78 @@ WHERE m.bot_id = sqlc.arg(bot_id)
 ORDER BY m.created_at DESC
 LIMIT sqlc.arg(max_count);
 
+-- name: ListMessagesAround :many
+SELECT
+  around.id,
+  around.bot_id,
+  around.route_id,
+  around.sender_channel_identity_id,
+  around.sender_user_id,
+  around.platform,
+  around.external_message_id,
+  around.source_reply_to_message_id,
+  around.role,
+  around.content,
+  around.metadata,
+  around.usage,
+  around.created_at,
+  around.sender_display_name,
+  around.sender_avatar_url
+FROM (
+  (
+    SELECT
+      m.id,
+      m.bot_id,
+      m.route_id,
+      m.sender_channel_identity_id,
+      m.sender_account_user_id AS sender_user_id,
+      m.channel_type AS platform,
+      m.source_message_id AS external_message_id,
+      m.source_reply_to_message_id,
+      m.role,
+      m.content,
+      m.metadata,
+      m.usage,
+      m.created_at,
+      ci.display_name AS sender_display_name,
+      ci.avatar_url AS sender_avatar_url
+    FROM bot_history_messages m
+    LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id
+    WHERE m.bot_id = sqlc.arg(bot_id)
+      AND (sqlc.narg(route_id)::uuid IS NULL OR m.route_id = sqlc.narg(route_id)::uuid)
+      AND m.created_at < sqlc.arg(created_at)
+    ORDER BY m.created_at DESC
+    LIMIT sqlc.arg(before_count)
+  )
+  UNION ALL
+  (
+    SELECT
+      m.id,
+      m.bot_id,
+      m.route_id,
+      m.sender_channel_identity_id,
+      m.sender_account_user_id AS sender_user_id,
+      m.channel_type AS platform,
+      m.source_message_id AS external_message_id,
+      m.source_reply_to_message_id,
+      m.role,
+      m.content,
+      m.metadata,
+      m.usage,
+      m.created_at,
+      ci.display_name AS sender_display_name,
+      ci.avatar_url AS sender_avatar_url
+    FROM bot_history_messages m
+    LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id
+    WHERE m.bot_id = sqlc.arg(bot_id)
+      AND (sqlc.narg(route_id)::uuid IS NULL OR m.route_id = sqlc.narg(route_id)::uuid)
+      AND m.created_at >= sqlc.arg(created_at)
+    ORDER BY m.created_at ASC
+    LIMIT sqlc.arg(after_count)
+  )
+) AS around
+ORDER BY around.created_at ASC;
+
 -- name: ListMessagesLatest :many
 SELECT
   m.id,
@@ -0,0 +1,14 @@
+-- name: ListBotFallbackModels :many
+SELECT models.model_id
+FROM bot_model_fallbacks
+JOIN models ON models.id = bot_model_fallbacks.model_id
+WHERE bot_model_fallbacks.bot_id = $1
+ORDER BY bot_model_fallbacks.priority ASC;
+
+-- name: DeleteBotFallbackModels :exec
+DELETE FROM bot_model_fallbacks
+WHERE bot_id = $1;
+
+-- name: InsertBotFallbackModel :exec
+INSERT INTO bot_model_fallbacks (bot_id, model_id, priority)
+VALUES (sqlc.arg(bot_id), sqlc.arg(model_id), sqlc.arg(priority));
@@ -0,0 +1,13 @@
+-- name: ListBotFallbackSearchProviders :many
+SELECT search_provider_id
+FROM bot_search_provider_fallbacks
+WHERE bot_id = $1
+ORDER BY priority ASC;
+
+-- name: DeleteBotFallbackSearchProviders :exec
+DELETE FROM bot_search_provider_fallbacks
+WHERE bot_id = $1;
+
+-- name: InsertBotFallbackSearchProvider :exec
+INSERT INTO bot_search_provider_fallbacks (bot_id, search_provider_id, priority)
+VALUES (sqlc.arg(bot_id), sqlc.arg(search_provider_id), sqlc.arg(priority));
@@ -0,0 +1,38 @@
+-- name: UpsertBotSecret :one
+INSERT INTO bot_secrets (bot_id, name, description, ciphertext, wrapped_key, key_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(name), sqlc.arg(description), sqlc.arg(ciphertext), sqlc.arg(wrapped_key), sqlc.arg(key_id))
+ON CONFLICT (bot_id, name) DO UPDATE SET
+  description = EXCLUDED.description,
+  ciphertext = EXCLUDED.ciphertext,
+  wrapped_key = EXCLUDED.wrapped_key,
+  key_id = EXCLUDED.key_id,
+  version = bot_secrets.version + 1,
+  rotated_at = now(),
+  updated_at = now()
+RETURNING *;
+
+-- name: ListBotSecrets :many
+SELECT * FROM bot_secrets
+WHERE bot_id = sqlc.arg(bot_id)
+ORDER BY name ASC;
+
+-- name: GetBotSecret :one
+SELECT * FROM bot_secrets
+WHERE bot_id = sqlc.arg(bot_id)
+  AND name = sqlc.arg(name);
+
+-- name: DeleteBotSecret :execrows
+DELETE FROM bot_secrets
+WHERE bot_id = sqlc.arg(bot_id)
+  AND name = sqlc.arg(name);
+
+-- name: ListBotSecretsNotWrappedWith :many
+SELECT * FROM bot_secrets
+WHERE key_id <> sqlc.arg(key_id)
+ORDER BY created_at ASC;
+
+-- name: UpdateBotSecretWrappedKey :exec
+UPDATE bot_secrets
+SET wrapped_key = sqlc.arg(wrapped_key),
+    key_id = sqlc.arg(key_id)
+WHERE id = sqlc.arg(id);
@@ -9,12 +9,14 @@ SELECT
   chat_models.model_id AS chat_model_id,
   memory_models.model_id AS memory_model_id,
   embedding_models.model_id AS embedding_model_id,
-  search_providers.id AS search_provider_id
+  search_providers.id AS search_provider_id,
+  rerank_models.model_id AS rerank_model_id
 FROM bots
 LEFT JOIN models AS chat_models ON chat_models.id = bots.chat_model_id
 LEFT JOIN models AS memory_models ON memory_models.id = bots.memory_model_id
 LEFT JOIN models AS embedding_models ON embedding_models.id = bots.embedding_model_id
 LEFT JOIN search_providers ON search_providers.id = bots.search_provider_id
+LEFT JOIN models AS rerank_models ON rerank_models.id = bots.rerank_model_id
 WHERE bots.id = $1;
 
 -- name: UpsertBotSettings :one
@@ -29,9 +31,13 @@ WITH updated AS (
       memory_model_id = COALESCE(sqlc.narg(memory_model_id)::uuid, bots.memory_model_id),
       embedding_model_id = COALESCE(sqlc.narg(embedding_model_id)::uuid, bots.embedding_model_id),
       search_provider_id = COALESCE(sqlc.narg(search_provider_id)::uuid, bots.search_provider_id),
+      rerank_model_id = CASE
+        WHEN sqlc.arg(clear_rerank_model)::boolean THEN NULL
+        ELSE COALESCE(sqlc.narg(rerank_model_id)::uuid, bots.rerank_model_id)
+      END,
       updated_at = now()
   WHERE bots.id = sqlc.arg(id)
-  RETURNING bots.id, bots.max_context_load_time, bots.max_context_tokens, bots.max_inbox_items, bots.language, bots.allow_guest, bots.chat_model_id, bots.memory_model_id, bots.embedding_model_id, bots.search_provider_id
+  RETURNING bots.id, bots.max_context_load_time, bots.max_context_tokens, bots.max_inbox_items, bots.language, bots.allow_guest, bots.chat_model_id, bots.memory_model_id, bots.embedding_model_id, bots.search_provider_id, bots.rerank_model_id
 )
 SELECT
   updated.id AS bot_id,
@@ -43,12 +49,14 @@ SELECT
   chat_models.model_id AS chat_model_id,
   memory_models.model_id AS memory_model_id,
   embedding_models.model_id AS embedding_model_id,
-  search_providers.id AS search_provider_id
+  search_providers.id AS search_provider_id,
+  rerank_models.model_id AS rerank_model_id
 FROM updated
 LEFT JOIN models AS chat_models ON chat_models.id = updated.chat_model_id
 LEFT JOIN models AS memory_models ON memory_models.id = updated.memory_model_id
 LEFT JOIN models AS embedding_models ON embedding_models.id = updated.embedding_model_id
-LEFT JOIN search_providers ON search_providers.id = updated.search_provider_id;
+LEFT JOIN search_providers ON search_providers.id = updated.search_provider_id
+LEFT JOIN models AS rerank_models ON rerank_models.id = updated.rerank_model_id;
 
 -- name: DeleteSettingsByBotID :exec
 UPDATE bots
@@ -61,5 +69,6 @@ SET max_context_load_time = 1440,
     memory_model_id = NULL,
     embedding_model_id = NULL,
     search_provider_id = NULL,
+    rerank_model_id = NULL,
     updated_at = now()
 WHERE id = $1;
@@ -0,0 +1,40 @@
+-- name: CreateToolCall :exec
+INSERT INTO tool_calls (
+  bot_id, chat_id, channel_identity_id, tool, source, connection_id, connection_name,
+  arguments, arguments_hash, status, error, duration_ms, created_at
+)
+VALUES (
+  sqlc.arg(bot_id), sqlc.arg(chat_id), sqlc.arg(channel_identity_id), sqlc.arg(tool), sqlc.arg(source),
+  sqlc.narg(connection_id)::uuid, sqlc.arg(connection_name), sqlc.arg(arguments), sqlc.arg(arguments_hash),
+  sqlc.arg(status), sqlc.arg(error), sqlc.arg(duration_ms), sqlc.arg(created_at)
+);
+
+-- name: ListToolCalls :many
+SELECT * FROM tool_calls
+WHERE bot_id = sqlc.arg(bot_id)
+  AND (sqlc.narg(tool)::text IS NULL OR tool = sqlc.narg(tool)::text)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(before)::timestamptz IS NULL OR created_at < sqlc.narg(before)::timestamptz)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_count);
+
+-- name: GetToolCallStats :many
+SELECT
+  tool,
+  source,
+  connection_name,
+  count(*)::bigint AS calls,
+  count(*) FILTER (WHERE status IN ('error', 'not_found'))::bigint AS errors,
+  count(*) FILTER (WHERE status = 'denied')::bigint AS denied,
+  COALESCE(avg(duration_ms), 0)::double precision AS avg_duration_ms,
+  COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0)::double precision AS p95_duration_ms,
+  COALESCE(max(duration_ms), 0)::integer AS max_duration_ms,
+  max(created_at)::timestamptz AS last_called_at
+FROM tool_calls
+WHERE bot_id = sqlc.arg(bot_id)
+  AND created_at >= sqlc.arg(since)
+GROUP BY tool, source, connection_name;
+
+-- name: DeleteToolCallsBefore :execrows
+DELETE FROM tool_calls
+WHERE created_at < sqlc.arg(before);
@@ -0,0 +1,20 @@
+-- name: ListBotToolPolicies :many
+SELECT id, bot_id, tool, enabled, conversation_types, roles, arguments, created_at, updated_at
+FROM bot_tool_policies
+WHERE bot_id = $1
+ORDER BY tool ASC;
+
+-- name: UpsertBotToolPolicy :one
+INSERT INTO bot_tool_policies (bot_id, tool, enabled, conversation_types, roles, arguments)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (bot_id, tool) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    conversation_types = EXCLUDED.conversation_types,
+    roles = EXCLUDED.roles,
+    arguments = EXCLUDED.arguments,
+    updated_at = now()
+RETURNING id, bot_id, tool, enabled, conversation_types, roles, arguments, created_at, updated_at;
+
+-- name: DeleteBotToolPolicy :exec
+DELETE FROM bot_tool_policies
+WHERE id = $1 AND bot_id = $2;
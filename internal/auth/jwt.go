@@ -20,6 +20,8 @@ const (
 	claimBotID             = "bot_id"
 	claimChatID            = "chat_id"
 	claimRouteID           = "route_id"
+	claimScopes            = "scopes"
+	claimConversationType  = "conversation_type"
 	chatTokenType          = "chat_route"
 )
 
@@ -46,6 +48,10 @@ func UserIDFromContext(c echo.Context) (string, error) {
 	if !ok {
 		return "", echo.NewHTTPError(http.StatusUnauthorized, "invalid token claims")
 	}
+	// Scoped chat tokens act for their user on their own bot only.
+	if _, scoped := claims[claimScopes]; scoped && strings.TrimSpace(c.Param("bot_id")) != claimString(claims, claimBotID) {
+		return "", echo.NewHTTPError(http.StatusForbidden, "token is restricted to its bot")
+	}
 	if userID := claimString(claims, claimUserID); userID != "" {
 		return userID, nil
 	}
@@ -90,6 +96,12 @@ type ChatToken struct {
 	RouteID           string
 	UserID            string
 	ChannelIdentityID string
+	// ConversationType is the kind of conversation the chat runs in, such as
+	// "private" or "group". Tool policies trust it only from a signed token.
+	ConversationType string
+	// Scopes restricts a chat started with a scoped token, such as an MCP
+	// bot token. Nil means the chat runs with the user's full access.
+	Scopes []string
 }
 
 // GenerateChatToken creates a signed JWT for chat route reply.
@@ -125,6 +137,12 @@ func GenerateChatToken(info ChatToken, secret string, expiresIn time.Duration) (
 		"iat":                  now.Unix(),
 		"exp":                  expiresAt.Unix(),
 	}
+	if conversationType := strings.TrimSpace(info.ConversationType); conversationType != "" {
+		claims[claimConversationType] = conversationType
+	}
+	if info.Scopes != nil {
+		claims[claimScopes] = info.Scopes
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString([]byte(secret))
 	if err != nil {
@@ -152,6 +170,39 @@ func ChatTokenFromContext(c echo.Context) (ChatToken, error) {
 		RouteID:           claimString(claims, claimRouteID),
 		UserID:            claimString(claims, claimUserID),
 		ChannelIdentityID: claimString(claims, claimChannelIdentityID),
+		ConversationType:  claimString(claims, claimConversationType),
+		Scopes:            claimStrings(claims, claimScopes),
+	}
+	if strings.TrimSpace(info.UserID) == "" {
+		info.UserID = strings.TrimSpace(info.ChannelIdentityID)
+	}
+	return info, nil
+}
+
+// ParseChatToken verifies a signed chat token, such as the session token the
+// agent forwards to tool calls, and returns its claims.
+func ParseChatToken(tokenString, secret string) (ChatToken, error) {
+	if strings.TrimSpace(secret) == "" {
+		return ChatToken{}, fmt.Errorf("jwt secret is required")
+	}
+	token, err := jwt.Parse(strings.TrimSpace(tokenString), func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return ChatToken{}, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claimString(claims, claimType) != chatTokenType {
+		return ChatToken{}, fmt.Errorf("invalid chat token")
+	}
+	info := ChatToken{
+		BotID:             claimString(claims, claimBotID),
+		ChatID:            claimString(claims, claimChatID),
+		RouteID:           claimString(claims, claimRouteID),
+		UserID:            claimString(claims, claimUserID),
+		ChannelIdentityID: claimString(claims, claimChannelIdentityID),
+		ConversationType:  claimString(claims, claimConversationType),
+		Scopes:            claimStrings(claims, claimScopes),
 	}
 	if strings.TrimSpace(info.UserID) == "" {
 		info.UserID = strings.TrimSpace(info.ChannelIdentityID)
@@ -173,3 +224,18 @@ func claimString(claims jwt.MapClaims, key string) string {
 		return fmt.Sprint(raw)
 	}
 }
+
+// claimStrings returns a string list claim, or nil when it is missing.
+func claimStrings(claims jwt.MapClaims, key string) []string {
+	raw, ok := claims[key].([]any)
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
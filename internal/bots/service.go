@@ -550,6 +550,26 @@ func (s *Service) GetMember(ctx context.Context, botID, channelIdentityID string
 	return toBotMember(row), nil
 }
 
+// MemberRole returns the role a user holds in a bot: owner, the member role,
+// or guest when the user is not a member.
+func (s *Service) MemberRole(ctx context.Context, botID, userID string) (string, error) {
+	bot, err := s.Get(ctx, botID)
+	if err != nil {
+		return "", err
+	}
+	if bot.OwnerUserID == userID {
+		return MemberRoleOwner, nil
+	}
+	member, err := s.GetMember(ctx, botID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MemberRoleGuest, nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
 // DeleteMember removes a member from a bot.
 func (s *Service) DeleteMember(ctx context.Context, botID, channelIdentityID string) error {
 	if s.queries == nil {
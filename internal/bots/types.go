@@ -135,4 +135,6 @@ const (
 	MemberRoleOwner  = "owner"
 	MemberRoleAdmin  = "admin"
 	MemberRoleMember = "member"
+	// MemberRoleGuest is reported for users that are neither owner nor member.
+	MemberRoleGuest = "guest"
 )
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"sync/atomic"
+	"time"
 )
 
 // ErrStopNotSupported is returned when a connection does not support graceful shutdown.
@@ -36,6 +37,9 @@ type ProcessingStatusInfo struct {
 	Query             string
 	ReplyTarget       string
 	SourceMessageID   string
+	// RunID and QueuePosition are set while the run waits in the route run queue.
+	RunID         string
+	QueuePosition int
 }
 
 // ProcessingStatusHandle stores channel-specific state between status callbacks.
@@ -51,6 +55,14 @@ type ProcessingStatusNotifier interface {
 	ProcessingFailed(ctx context.Context, cfg ChannelConfig, msg InboundMessage, info ProcessingStatusInfo, handle ProcessingStatusHandle, cause error) error
 }
 
+// ProcessingQueueNotifier is an optional extension of ProcessingStatusNotifier
+// that shows when a run waits behind other runs on the same route.
+// ProcessingDequeued is called once the run starts or is cancelled.
+type ProcessingQueueNotifier interface {
+	ProcessingQueued(ctx context.Context, cfg ChannelConfig, msg InboundMessage, info ProcessingStatusInfo) (ProcessingStatusHandle, error)
+	ProcessingDequeued(ctx context.Context, cfg ChannelConfig, msg InboundMessage, info ProcessingStatusInfo, handle ProcessingStatusHandle) error
+}
+
 // AttachmentPayload contains resolved attachment bytes and optional metadata.
 // Caller must close Reader.
 type AttachmentPayload struct {
@@ -125,6 +137,12 @@ type Reactor interface {
 	Unreact(ctx context.Context, cfg ChannelConfig, target string, messageID string, emoji string) error
 }
 
+// RetryAfterResolver extracts a platform-mandated wait (for example a 429
+// retry_after hint) from a send error. It returns 0 when err carries no hint.
+type RetryAfterResolver interface {
+	RetryAfter(err error) time.Duration
+}
+
 // SelfDiscoverer retrieves the adapter bot's own identity from the platform.
 // The returned map is merged into ChannelConfig.SelfIdentity and persisted.
 type SelfDiscoverer interface {
@@ -27,7 +27,10 @@ type FeishuAdapter struct {
 	logger *slog.Logger
 }
 
-const processingBusyReactionType = "Typing"
+const (
+	processingBusyReactionType   = "Typing"
+	processingQueuedReactionType = "OneSecond"
+)
 
 type messageReactionAPI interface {
 	Create(ctx context.Context, req *larkim.CreateMessageReactionReq, options ...larkcore.RequestOptionFunc) (*larkim.CreateMessageReactionResp, error)
@@ -195,6 +198,28 @@ func (a *FeishuAdapter) ProcessingFailed(ctx context.Context, cfg channel.Channe
 	return a.ProcessingCompleted(ctx, cfg, msg, info, handle)
 }
 
+// ProcessingQueued adds a reaction showing the message waits behind other runs.
+func (a *FeishuAdapter) ProcessingQueued(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, info channel.ProcessingStatusInfo) (channel.ProcessingStatusHandle, error) {
+	messageID := strings.TrimSpace(info.SourceMessageID)
+	if messageID == "" {
+		return channel.ProcessingStatusHandle{}, nil
+	}
+	gateway, err := a.processingReactionGateway(cfg)
+	if err != nil {
+		return channel.ProcessingStatusHandle{}, err
+	}
+	token, err := addProcessingReaction(ctx, gateway, messageID, processingQueuedReactionType)
+	if err != nil {
+		return channel.ProcessingStatusHandle{}, err
+	}
+	return channel.ProcessingStatusHandle{Token: token}, nil
+}
+
+// ProcessingDequeued removes the queued reaction once the run starts or is cancelled.
+func (a *FeishuAdapter) ProcessingDequeued(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, info channel.ProcessingStatusInfo, handle channel.ProcessingStatusHandle) error {
+	return a.ProcessingCompleted(ctx, cfg, msg, info, handle)
+}
+
 func (a *FeishuAdapter) processingReactionGateway(cfg channel.ChannelConfig) (processingReactionGateway, error) {
 	feishuCfg, err := parseConfig(cfg.Credentials)
 	if err != nil {
@@ -108,6 +108,10 @@ func (a *TelegramAdapter) Descriptor() channel.Descriptor {
 			Streaming:      true,
 			BlockStreaming: true,
 		},
+		OutboundPolicy: channel.OutboundPolicy{
+			// Stay below the Bot API's ~30 messages/second global limit.
+			MinIntervalMs: 40,
+		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
 			Fields: map[string]channel.FieldSchema{
@@ -727,6 +731,12 @@ func isTelegramTooManyRequests(err error) bool {
 	return false
 }
 
+// RetryAfter exposes the Bot API retry_after hint so queued deliveries back off
+// for exactly as long as Telegram requests.
+func (a *TelegramAdapter) RetryAfter(err error) time.Duration {
+	return getTelegramRetryAfter(err)
+}
+
 func getTelegramRetryAfter(err error) time.Duration {
 	if err == nil {
 		return 0
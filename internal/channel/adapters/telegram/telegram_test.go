@@ -473,6 +473,7 @@ func TestGetTelegramRetryAfter(t *testing.T) {
 		{"nil", nil, 0},
 		{"no retry_after", tgbotapi.Error{Code: 429, Message: "Too Many Requests"}, 0},
 		{"retry_after 2", tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 2}}, 2 * time.Second},
+		{"wrapped retry_after", fmt.Errorf("send: %w", tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}), 5 * time.Second},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
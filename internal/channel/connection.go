@@ -12,6 +12,9 @@ import (
 type connectionEntry struct {
 	config     ChannelConfig
 	connection Connection
+	// secretVersions identifies the secrets the connection was started
+	// with; a rotation restarts it like a config change.
+	secretVersions string
 }
 
 func (m *Manager) refresh(ctx context.Context) {
@@ -101,9 +104,20 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 
 	m.mu.Lock()
 	entry := m.connections[cfg.ID]
+	m.mu.Unlock()
+	current := ""
+	if entry != nil {
+		current = entry.secretVersions
+	}
+	secretVersions := m.credentialVersions(ctx, cfg, current)
+
+	m.mu.Lock()
+	entry = m.connections[cfg.ID]
 
-	// Config unchanged — nothing to do.
-	if entry != nil && !entry.config.UpdatedAt.Before(cfg.UpdatedAt) {
+	// Config and secrets unchanged — nothing to do. Secret versions are only
+	// compared for the config the entry runs, not for an older copy of it.
+	if entry != nil && !entry.config.UpdatedAt.Before(cfg.UpdatedAt) &&
+		(entry.secretVersions == secretVersions || entry.config.UpdatedAt.After(cfg.UpdatedAt)) {
 		running := entry.connection != nil && entry.connection.Running()
 		m.setConnectionStatusLocked(entry.config, running, nil)
 		m.mu.Unlock()
@@ -187,7 +201,14 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 		// Decouple long-lived adapter connections from short-lived request contexts.
 		connectCtx = context.WithoutCancel(ctx)
 	}
-	conn, err := receiver.Connect(connectCtx, cfg, handler)
+	// The entry keeps the stored config so change detection and status
+	// never hold resolved secrets.
+	resolved, err := m.withResolvedCredentials(connectCtx, cfg)
+	if err != nil {
+		m.markConnectionStatus(cfg, false, err)
+		return err
+	}
+	conn, err := receiver.Connect(connectCtx, resolved, handler)
 	if err != nil {
 		m.markConnectionStatus(cfg, false, err)
 		return err
@@ -204,8 +225,9 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 		return nil
 	}
 	m.connections[cfg.ID] = &connectionEntry{
-		config:     cfg,
-		connection: conn,
+		config:         cfg,
+		connection:     conn,
+		secretVersions: secretVersions,
 	}
 	m.setConnectionStatusLocked(cfg, true, nil)
 	m.mu.Unlock()
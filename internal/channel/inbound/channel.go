@@ -3,6 +3,7 @@ package inbound
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -62,6 +63,10 @@ type ChannelInboundProcessor struct {
 	tokenTTL      time.Duration
 	identity      *IdentityResolver
 	observer      channel.StreamObserver
+
+	debounceDefaults DebounceConfig
+	debouncer        *inboundDebouncer
+	runCanceller     RunCanceller
 }
 
 // NewChannelInboundProcessor creates a processor with channel identity-based resolution.
@@ -95,6 +100,7 @@ func NewChannelInboundProcessor(
 		jwtSecret:     strings.TrimSpace(jwtSecret),
 		tokenTTL:      tokenTTL,
 		identity:      identityResolver,
+		debouncer:     newInboundDebouncer(),
 	}
 }
 
@@ -134,6 +140,8 @@ func (p *ChannelInboundProcessor) SetInboxService(service *inbox.Service) {
 }
 
 // HandleInbound processes an inbound channel message through identity resolution and chat gateway.
+// When debouncing is enabled, consecutive messages from the same sender are
+// buffered and answered once as a single merged query.
 func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.StreamReplySender) error {
 	if p.runner == nil {
 		return fmt.Errorf("channel inbound processor not configured")
@@ -141,6 +149,33 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if sender == nil {
 		return fmt.Errorf("reply sender not configured")
 	}
+	if p.debouncer == nil {
+		return p.processInbound(ctx, cfg, msg, sender)
+	}
+	conf := resolveDebounceConfig(cfg, p.debounceDefaults)
+	key := debounceKey(cfg, msg)
+	if isStopCommand(msg.Message.PlainText()) {
+		// Buffered messages belong to the generation being stopped.
+		p.debouncer.discard(key)
+		return p.processInbound(ctx, cfg, msg, sender)
+	}
+	if conf.CancelInFlight && p.debouncer.cancel(key) && p.logger != nil {
+		p.logger.Info("inbound generation cancelled by newer message",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("conversation_id", strings.TrimSpace(msg.Conversation.ID)),
+		)
+	}
+	// Commands are answered immediately and never merged with chat text.
+	if conf.Window <= 0 || hasCommandPrefix(msg.Message.PlainText(), msg.Metadata) {
+		return p.runInbound(ctx, key, cfg, msg, sender)
+	}
+	p.debouncer.add(ctx, key, cfg, msg, sender, conf, func(burst *inboundBurst) {
+		p.flushInboundBurst(key, burst)
+	})
+	return nil
+}
+
+func (p *ChannelInboundProcessor) processInbound(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.StreamReplySender) error {
 	text := buildInboundQuery(msg.Message)
 	if p.logger != nil {
 		p.logger.Debug("inbound handle start",
@@ -204,6 +239,9 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if err != nil {
 		return fmt.Errorf("resolve route conversation: %w", err)
 	}
+	if isStopCommand(text) {
+		return p.handleStopCommand(ctx, cfg, identity, resolved.RouteID, msg, sender)
+	}
 	// Bot-centric history container:
 	// always persist channel traffic under bot_id so WebUI can view unified cross-platform history.
 	activeChatID := strings.TrimSpace(identity.BotID)
@@ -238,6 +276,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 			RouteID:           resolved.RouteID,
 			UserID:            identity.UserID,
 			ChannelIdentityID: identity.ChannelIdentityID,
+			ConversationType:  msg.Conversation.Type,
 		}, p.jwtSecret, p.tokenTTL)
 		if err != nil {
 			if p.logger != nil {
@@ -356,6 +395,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		return result
 	}
 
+	onRunQueued, onRunDequeued := p.runQueueCallbacks(ctx, cfg, msg, statusInfo)
 	chunkCh, streamErrCh := p.runner.StreamChat(ctx, conversation.ChatRequest{
 		BotID:                   identity.BotID,
 		ChatID:                  activeChatID,
@@ -374,6 +414,8 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		UserMessagePersisted:    userMessagePersisted,
 		Attachments:             attachments,
 		OutboundAssetCollector:  assetCollector,
+		OnRunQueued:             onRunQueued,
+		OnRunDequeued:           onRunDequeued,
 	})
 
 	var (
@@ -444,12 +486,28 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 			if err != nil {
 				streamErr = err
 			}
+		case <-ctx.Done():
+			streamErr = context.Cause(ctx)
 		}
 		if streamErr != nil {
 			break
 		}
 	}
 
+	if streamErr != nil && (errors.Is(context.Cause(ctx), errInboundSuperseded) || errors.Is(streamErr, flow.ErrRunCancelled)) {
+		// A newer message took over or the queued run was cancelled; end quietly.
+		doneCtx := context.WithoutCancel(ctx)
+		_ = stream.Push(doneCtx, channel.StreamEvent{
+			Type:   channel.StreamEventStatus,
+			Status: channel.StreamStatusCompleted,
+		})
+		if statusNotifier != nil {
+			if notifyErr := p.notifyProcessingCompleted(doneCtx, statusNotifier, cfg, msg, statusInfo, statusHandle); notifyErr != nil {
+				p.logProcessingStatusError("processing_completed", msg, identity, notifyErr)
+			}
+		}
+		return nil
+	}
 	if streamErr != nil {
 		if p.logger != nil {
 			p.logger.Error(
@@ -1320,6 +1378,58 @@ func (p *ChannelInboundProcessor) notifyProcessingFailed(
 	return notifier.ProcessingFailed(statusCtx, cfg, msg, info, handle, cause)
 }
 
+// runQueueCallbacks builds the run queue hooks that surface a "queued" status
+// on channels implementing ProcessingQueueNotifier.
+func (p *ChannelInboundProcessor) runQueueCallbacks(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	msg channel.InboundMessage,
+	info channel.ProcessingStatusInfo,
+) (func(runID string, position int), func(runID string)) {
+	if p == nil || p.registry == nil {
+		return nil, nil
+	}
+	notifier, ok := p.registry.GetProcessingQueueNotifier(msg.Channel)
+	if !ok {
+		return nil, nil
+	}
+	var (
+		mu     sync.Mutex
+		handle channel.ProcessingStatusHandle
+		queued channel.ProcessingStatusInfo
+	)
+	onQueued := func(runID string, position int) {
+		queuedInfo := info
+		queuedInfo.RunID = runID
+		queuedInfo.QueuePosition = position
+		statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), processingStatusTimeout)
+		defer cancel()
+		queuedHandle, err := notifier.ProcessingQueued(statusCtx, cfg, msg, queuedInfo)
+		if err != nil {
+			p.logProcessingStatusError("processing_queued", msg, InboundIdentity{UserID: info.UserID, ChannelIdentityID: info.ChannelIdentityID}, err)
+			return
+		}
+		mu.Lock()
+		handle = queuedHandle
+		queued = queuedInfo
+		mu.Unlock()
+	}
+	onDequeued := func(runID string) {
+		mu.Lock()
+		queuedHandle, queuedInfo := handle, queued
+		mu.Unlock()
+		if queuedInfo.RunID != runID {
+			return
+		}
+		statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), processingStatusTimeout)
+		defer cancel()
+		if err := notifier.ProcessingDequeued(statusCtx, cfg, msg, queuedInfo, queuedHandle); err != nil {
+			p.logProcessingStatusError("processing_dequeued", msg, InboundIdentity{UserID: info.UserID, ChannelIdentityID: info.ChannelIdentityID}, err)
+		}
+	}
+	return onQueued, onDequeued
+}
+
 func (p *ChannelInboundProcessor) logProcessingStatusError(
 	stage string,
 	msg channel.InboundMessage,
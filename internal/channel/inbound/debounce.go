@@ -0,0 +1,289 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const (
+	// Routing keys read from channel_configs.routing.
+	routingDebounceMs       = "debounce_ms"
+	routingDebounceMaxMs    = "debounce_max_ms"
+	routingCancelInFlight   = "cancel_in_flight"
+	defaultDebounceMaxScale = 4
+	maxDebounceWindow       = 30 * time.Second
+)
+
+// errInboundSuperseded is the cancellation cause used when a newer message from
+// the same sender interrupts an in-flight generation.
+var errInboundSuperseded = errors.New("inbound generation superseded by newer message")
+
+// DebounceConfig controls how bursts of inbound messages on a route are coalesced.
+// A zero Window disables debouncing.
+type DebounceConfig struct {
+	// Window is how long to wait for a follow-up message before answering.
+	Window time.Duration
+	// MaxWait bounds the total delay of a burst so a chatty sender is still answered.
+	MaxWait time.Duration
+	// CancelInFlight aborts a running generation when the same sender writes again.
+	CancelInFlight bool
+}
+
+// resolveDebounceConfig overlays the per-config routing settings on top of the
+// processor defaults.
+func resolveDebounceConfig(cfg channel.ChannelConfig, fallback DebounceConfig) DebounceConfig {
+	result := fallback
+	if ms, ok := readRoutingInt(cfg.Routing, routingDebounceMs); ok {
+		result.Window = time.Duration(ms) * time.Millisecond
+	}
+	if ms, ok := readRoutingInt(cfg.Routing, routingDebounceMaxMs); ok {
+		result.MaxWait = time.Duration(ms) * time.Millisecond
+	}
+	if _, ok := cfg.Routing[routingCancelInFlight]; ok {
+		result.CancelInFlight = metadataBool(cfg.Routing, routingCancelInFlight)
+	}
+	if result.Window < 0 {
+		result.Window = 0
+	}
+	if result.Window > maxDebounceWindow {
+		result.Window = maxDebounceWindow
+	}
+	if result.MaxWait < result.Window {
+		result.MaxWait = result.Window * defaultDebounceMaxScale
+	}
+	return result
+}
+
+func readRoutingInt(routing map[string]any, key string) (int, bool) {
+	if routing == nil {
+		return 0, false
+	}
+	switch value := routing[key].(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), true
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	default:
+		return 0, false
+	}
+}
+
+// debounceKey scopes bursts to one sender within one conversation thread.
+func debounceKey(cfg channel.ChannelConfig, msg channel.InboundMessage) string {
+	senderID := strings.TrimSpace(msg.Sender.SubjectID)
+	if senderID == "" {
+		senderID = strings.TrimSpace(msg.Sender.DisplayName)
+	}
+	return strings.Join([]string{
+		strings.TrimSpace(cfg.BotID),
+		msg.Channel.String(),
+		strings.TrimSpace(msg.Conversation.ID),
+		extractThreadID(msg),
+		senderID,
+	}, "|")
+}
+
+type inboundBurst struct {
+	ctx      context.Context
+	cfg      channel.ChannelConfig
+	sender   channel.StreamReplySender
+	messages []channel.InboundMessage
+	started  time.Time
+	timer    *time.Timer
+}
+
+// inboundDebouncer buffers bursts per key and tracks running generations so
+// they can be cancelled when superseded.
+type inboundDebouncer struct {
+	mu       sync.Mutex
+	pending  map[string]*inboundBurst
+	inFlight map[string]*inboundRun
+}
+
+type inboundRun struct {
+	cancel context.CancelCauseFunc
+}
+
+func newInboundDebouncer() *inboundDebouncer {
+	return &inboundDebouncer{
+		pending:  map[string]*inboundBurst{},
+		inFlight: map[string]*inboundRun{},
+	}
+}
+
+// add buffers msg under key and (re)arms the flush timer. flush runs once the
+// burst has been quiet for the window or has reached its maximum delay.
+func (d *inboundDebouncer) add(ctx context.Context, key string, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.StreamReplySender, conf DebounceConfig, flush func(*inboundBurst)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	burst, ok := d.pending[key]
+	if !ok {
+		burst = &inboundBurst{ctx: ctx, started: now}
+		d.pending[key] = burst
+	}
+	burst.cfg = cfg
+	burst.sender = sender
+	burst.messages = append(burst.messages, msg)
+	wait := conf.Window
+	if remaining := burst.started.Add(conf.MaxWait).Sub(now); remaining < wait {
+		wait = remaining
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if burst.timer != nil {
+		burst.timer.Stop()
+	}
+	burst.timer = time.AfterFunc(wait, func() {
+		d.mu.Lock()
+		current, ok := d.pending[key]
+		if !ok || current != burst {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.pending, key)
+		d.mu.Unlock()
+		flush(burst)
+	})
+}
+
+// track registers a cancellable generation for key. The returned release must be
+// called once the generation finishes.
+func (d *inboundDebouncer) track(ctx context.Context, key string) (context.Context, func()) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	run := &inboundRun{cancel: cancel}
+	d.mu.Lock()
+	d.inFlight[key] = run
+	d.mu.Unlock()
+	return runCtx, func() {
+		d.mu.Lock()
+		// A newer generation may already have replaced this one.
+		if d.inFlight[key] == run {
+			delete(d.inFlight, key)
+		}
+		d.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel aborts the in-flight generation for key, if any.
+func (d *inboundDebouncer) cancel(key string) bool {
+	d.mu.Lock()
+	run, ok := d.inFlight[key]
+	if ok {
+		delete(d.inFlight, key)
+	}
+	d.mu.Unlock()
+	if ok {
+		run.cancel(errInboundSuperseded)
+	}
+	return ok
+}
+
+// discard drops the pending burst for key without flushing it.
+func (d *inboundDebouncer) discard(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if burst, ok := d.pending[key]; ok {
+		if burst.timer != nil {
+			burst.timer.Stop()
+		}
+		delete(d.pending, key)
+	}
+}
+
+// mergeInboundMessages coalesces a burst into a single inbound message. The
+// newest message is the base so replies thread to it; texts are joined in order
+// and attachments are concatenated.
+func mergeInboundMessages(messages []channel.InboundMessage) channel.InboundMessage {
+	if len(messages) == 0 {
+		return channel.InboundMessage{}
+	}
+	if len(messages) == 1 {
+		return messages[0]
+	}
+	merged := messages[len(messages)-1]
+	texts := make([]string, 0, len(messages))
+	var attachments []channel.Attachment
+	messageIDs := make([]string, 0, len(messages))
+	metadata := make(map[string]any, len(merged.Metadata)+1)
+	addressed := map[string]bool{}
+	for _, item := range messages {
+		if text := strings.TrimSpace(item.Message.PlainText()); text != "" {
+			texts = append(texts, text)
+		}
+		attachments = append(attachments, item.Message.Attachments...)
+		if id := strings.TrimSpace(item.Message.ID); id != "" {
+			messageIDs = append(messageIDs, id)
+		}
+		for k, v := range item.Metadata {
+			metadata[k] = v
+		}
+		for _, flag := range []string{"is_mentioned", "is_reply_to_bot"} {
+			if metadataBool(item.Metadata, flag) {
+				addressed[flag] = true
+			}
+		}
+	}
+	// Any mention in the burst addresses the bot.
+	for flag := range addressed {
+		metadata[flag] = true
+	}
+	metadata["coalesced_message_ids"] = messageIDs
+	merged.Message.Text = strings.Join(texts, "\n")
+	merged.Message.Parts = nil
+	merged.Message.Format = ""
+	merged.Message.Attachments = attachments
+	merged.Metadata = metadata
+	return merged
+}
+
+// SetDebounceConfig sets the default inbound debounce behaviour. Channel configs
+// can override it through routing.debounce_ms, routing.debounce_max_ms and
+// routing.cancel_in_flight.
+func (p *ChannelInboundProcessor) SetDebounceConfig(conf DebounceConfig) {
+	if p == nil {
+		return
+	}
+	p.debounceDefaults = conf
+}
+
+func (p *ChannelInboundProcessor) flushInboundBurst(key string, burst *inboundBurst) {
+	msg := mergeInboundMessages(burst.messages)
+	if p.logger != nil && len(burst.messages) > 1 {
+		p.logger.Debug("inbound burst coalesced",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("conversation_id", strings.TrimSpace(msg.Conversation.ID)),
+			slog.Int("messages", len(burst.messages)),
+		)
+	}
+	if err := p.runInbound(burst.ctx, key, burst.cfg, msg, burst.sender); err != nil && p.logger != nil {
+		p.logger.Error("inbound processing failed",
+			slog.String("channel", msg.Channel.String()),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// runInbound processes msg as a tracked generation that newer messages may cancel.
+func (p *ChannelInboundProcessor) runInbound(ctx context.Context, key string, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.StreamReplySender) error {
+	runCtx, release := p.debouncer.track(ctx, key)
+	defer release()
+	return p.processInbound(runCtx, cfg, msg, sender)
+}
@@ -0,0 +1,193 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/schedule"
+)
+
+type blockingChatGateway struct {
+	mu      sync.Mutex
+	queries []string
+	results chan error
+}
+
+func (g *blockingChatGateway) Chat(ctx context.Context, req conversation.ChatRequest) (conversation.ChatResponse, error) {
+	return conversation.ChatResponse{}, nil
+}
+
+func (g *blockingChatGateway) StreamChat(ctx context.Context, req conversation.ChatRequest) (<-chan conversation.StreamChunk, <-chan error) {
+	g.mu.Lock()
+	g.queries = append(g.queries, req.Query)
+	g.mu.Unlock()
+	chunks := make(chan conversation.StreamChunk)
+	errs := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		errs <- ctx.Err()
+		close(chunks)
+		close(errs)
+	}()
+	return chunks, errs
+}
+
+func (g *blockingChatGateway) TriggerSchedule(ctx context.Context, botID string, payload schedule.TriggerPayload, token string) error {
+	return nil
+}
+
+func newDebounceTestMessage(id, text string) channel.InboundMessage {
+	return channel.InboundMessage{
+		BotID:       "bot-1",
+		Channel:     channel.ChannelType("feishu"),
+		Message:     channel.Message{ID: id, Text: text},
+		ReplyTarget: "target-id",
+		Sender:      channel.Identity{SubjectID: "ext-1", DisplayName: "User1"},
+		Conversation: channel.Conversation{
+			ID:   "chat-1",
+			Type: "p2p",
+		},
+	}
+}
+
+func TestMergeInboundMessages(t *testing.T) {
+	t.Parallel()
+
+	first := newDebounceTestMessage("m1", "hello")
+	first.Metadata = map[string]any{"is_mentioned": true}
+	second := newDebounceTestMessage("m2", "")
+	second.Message.Attachments = []channel.Attachment{{Type: channel.AttachmentImage, URL: "https://example.com/a.png"}}
+	second.Metadata = map[string]any{"is_mentioned": false}
+	third := newDebounceTestMessage("m3", "are you there?")
+
+	merged := mergeInboundMessages([]channel.InboundMessage{first, second, third})
+	if merged.Message.ID != "m3" {
+		t.Fatalf("expected newest message id, got %q", merged.Message.ID)
+	}
+	if merged.Message.Text != "hello\nare you there?" {
+		t.Fatalf("unexpected merged text: %q", merged.Message.Text)
+	}
+	if len(merged.Message.Attachments) != 1 {
+		t.Fatalf("expected attachments to be kept, got %d", len(merged.Message.Attachments))
+	}
+	if !metadataBool(merged.Metadata, "is_mentioned") {
+		t.Fatalf("expected mention in burst to be preserved")
+	}
+	ids, _ := merged.Metadata["coalesced_message_ids"].([]string)
+	if len(ids) != 3 {
+		t.Fatalf("expected coalesced ids, got %v", merged.Metadata["coalesced_message_ids"])
+	}
+}
+
+func TestResolveDebounceConfig(t *testing.T) {
+	t.Parallel()
+
+	conf := resolveDebounceConfig(channel.ChannelConfig{}, DebounceConfig{})
+	if conf.Window != 0 || conf.CancelInFlight {
+		t.Fatalf("expected debouncing disabled by default, got %+v", conf)
+	}
+	conf = resolveDebounceConfig(channel.ChannelConfig{Routing: map[string]any{
+		"debounce_ms":      float64(1500),
+		"cancel_in_flight": "true",
+	}}, DebounceConfig{})
+	if conf.Window != 1500*time.Millisecond || !conf.CancelInFlight {
+		t.Fatalf("unexpected routing override: %+v", conf)
+	}
+	if conf.MaxWait != 4*conf.Window {
+		t.Fatalf("expected default max wait, got %v", conf.MaxWait)
+	}
+	conf = resolveDebounceConfig(channel.ChannelConfig{Routing: map[string]any{"debounce_ms": 600000}}, DebounceConfig{})
+	if conf.Window != maxDebounceWindow {
+		t.Fatalf("expected window to be capped, got %v", conf.Window)
+	}
+}
+
+func TestChannelInboundProcessorDebounceCoalescesBurst(t *testing.T) {
+	t.Parallel()
+
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	memberSvc := &fakeMemberService{isMember: true}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{ChatID: "chat-1", RouteID: "route-1"}}
+	queries := make(chan string, 4)
+	gateway := &fakeChatGateway{
+		onChat: func(req conversation.ChatRequest) { queries <- req.Query },
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, memberSvc, &fakePolicyService{}, nil, nil, "", 0)
+	processor.SetDebounceConfig(DebounceConfig{Window: 50 * time.Millisecond})
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+
+	for i, text := range []string{"hi", "quick question", "how are you"} {
+		if err := processor.HandleInbound(context.Background(), cfg, newDebounceTestMessage(string(rune('a'+i)), text), sender); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	select {
+	case query := <-queries:
+		if query != "hi\nquick question\nhow are you" {
+			t.Fatalf("unexpected merged query: %q", query)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected burst to be flushed")
+	}
+	select {
+	case query := <-queries:
+		t.Fatalf("expected a single generation, got another: %q", query)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestChannelInboundProcessorCancelInFlight(t *testing.T) {
+	t.Parallel()
+
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	memberSvc := &fakeMemberService{isMember: true}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{ChatID: "chat-1", RouteID: "route-1"}}
+	gateway := &blockingChatGateway{}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, memberSvc, &fakePolicyService{}, nil, nil, "", 0)
+	processor.SetDebounceConfig(DebounceConfig{CancelInFlight: true})
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- processor.HandleInbound(context.Background(), cfg, newDebounceTestMessage("m1", "first"), &fakeReplySender{})
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		gateway.mu.Lock()
+		started := len(gateway.queries) > 0
+		gateway.mu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("first generation did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- processor.HandleInbound(secondCtx, cfg, newDebounceTestMessage("m2", "second"), &fakeReplySender{})
+	}()
+
+	select {
+	case err := <-firstDone:
+		if err != nil {
+			t.Fatalf("superseded generation should end quietly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("first generation was not cancelled")
+	}
+	cancelSecond()
+	<-secondDone
+}
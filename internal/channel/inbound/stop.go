@@ -0,0 +1,72 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// RunCanceller stops the queued and running agent runs of a route. A
+// non-empty senderID limits it to the runs that sender started.
+type RunCanceller interface {
+	CancelRoute(botID, routeKey, senderID string) int
+}
+
+// SetRunCanceller configures the run canceller used by the /stop command.
+func (p *ChannelInboundProcessor) SetRunCanceller(canceller RunCanceller) {
+	if p == nil {
+		return
+	}
+	p.runCanceller = canceller
+}
+
+// isStopCommand reports whether text is the /stop command, optionally
+// addressed to a bot as in "/stop@name".
+func isStopCommand(text string) bool {
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) != 1 {
+		return false
+	}
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	return command == "/stop"
+}
+
+// handleStopCommand cancels the in-flight and queued runs of the route and
+// acknowledges the command in the conversation. In group chats only the
+// sender's own runs are stopped, so members cannot stop each other's runs.
+func (p *ChannelInboundProcessor) handleStopCommand(ctx context.Context, cfg channel.ChannelConfig, identity InboundIdentity, routeID string, msg channel.InboundMessage, sender channel.StreamReplySender) error {
+	botID := identity.BotID
+	senderID := ""
+	if isGroupConversationType(msg.Conversation.Type) {
+		senderID = strings.TrimSpace(identity.ChannelIdentityID)
+	}
+	stopped := 0
+	if p.runCanceller != nil {
+		stopped = p.runCanceller.CancelRoute(botID, routeID, senderID)
+	} else if p.debouncer != nil && p.debouncer.cancel(debounceKey(cfg, msg)) {
+		// Without a run queue only the sender's own generation is known.
+		stopped = 1
+	}
+	if p.logger != nil {
+		p.logger.Info("inbound stop command",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("bot_id", strings.TrimSpace(botID)),
+			slog.String("route_id", strings.TrimSpace(routeID)),
+			slog.Int("stopped", stopped),
+		)
+	}
+	reply := "Stopped."
+	if stopped == 0 {
+		reply = "Nothing to stop."
+	}
+	target := strings.TrimSpace(msg.ReplyTarget)
+	return sender.Send(ctx, channel.OutboundMessage{
+		Target: target,
+		Message: channel.Message{
+			Text:  reply,
+			Reply: &channel.ReplyRef{Target: target, MessageID: strings.TrimSpace(msg.Message.ID)},
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/conversation"
+)
+
+type fakeRunCanceller struct {
+	calls   []string
+	stopped int
+}
+
+func (c *fakeRunCanceller) CancelRoute(botID, routeKey, senderID string) int {
+	call := botID + ":" + routeKey
+	if senderID != "" {
+		call += ":" + senderID
+	}
+	c.calls = append(c.calls, call)
+	return c.stopped
+}
+
+func TestIsStopCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"/stop":          true,
+		" /STOP ":        true,
+		"/stop@memo_bot": true,
+		"/stop now":      false,
+		"/stopped":       false,
+		"stop":           false,
+	}
+	for text, want := range cases {
+		if got := isStopCommand(text); got != want {
+			t.Fatalf("isStopCommand(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestChannelInboundProcessorStopCommand(t *testing.T) {
+	t.Parallel()
+
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	memberSvc := &fakeMemberService{isMember: true}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{ChatID: "chat-1", RouteID: "route-1"}}
+	called := false
+	gateway := &fakeChatGateway{onChat: func(conversation.ChatRequest) { called = true }}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, memberSvc, &fakePolicyService{}, nil, nil, "", 0)
+	canceller := &fakeRunCanceller{stopped: 1}
+	processor.SetRunCanceller(canceller)
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+
+	if err := processor.HandleInbound(context.Background(), cfg, newDebounceTestMessage("m1", "/stop"), sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Fatalf("stop command must not reach the agent")
+	}
+	if len(canceller.calls) != 1 || canceller.calls[0] != "bot-1:route-1" {
+		t.Fatalf("unexpected cancel calls: %v", canceller.calls)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Message.Text != "Stopped." {
+		t.Fatalf("expected stop acknowledgement, got %+v", sender.sent)
+	}
+
+	canceller.stopped = 0
+	if err := processor.HandleInbound(context.Background(), cfg, newDebounceTestMessage("m2", "/stop"), sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 2 || sender.sent[1].Message.Text != "Nothing to stop." {
+		t.Fatalf("expected nothing-to-stop reply, got %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStopCommandInGroupStopsOwnRuns(t *testing.T) {
+	t.Parallel()
+
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	memberSvc := &fakeMemberService{isMember: true}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{ChatID: "chat-1", RouteID: "route-1"}}
+	gateway := &fakeChatGateway{}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, memberSvc, &fakePolicyService{}, nil, nil, "", 0)
+	canceller := &fakeRunCanceller{stopped: 1}
+	processor.SetRunCanceller(canceller)
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+
+	msg := newDebounceTestMessage("m1", "/stop")
+	msg.Conversation.Type = "group"
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(canceller.calls) != 1 || canceller.calls[0] != "bot-1:route-1:channelIdentity-1" {
+		t.Fatalf("expected only the sender's runs to be cancelled, got %v", canceller.calls)
+	}
+}
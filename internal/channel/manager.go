@@ -36,6 +36,15 @@ type ConfigStore interface {
 	UpsertChannelIdentityConfig(ctx context.Context, channelIdentityID string, channelType ChannelType, req UpsertChannelIdentityConfigRequest) (ChannelIdentityBinding, error)
 }
 
+// CredentialResolver expands secret references in channel credentials. The
+// stored config keeps the references; adapters only see resolved values.
+// CredentialVersions identifies the referenced secret versions, so rotated
+// secrets restart the connections that use them.
+type CredentialResolver interface {
+	ResolveCredentials(ctx context.Context, botID string, credentials map[string]any) (map[string]any, error)
+	CredentialVersions(ctx context.Context, botID string, credentials map[string]any) (string, error)
+}
+
 // Middleware wraps an InboundHandler to add cross-cutting behavior.
 type Middleware func(next InboundHandler) InboundHandler
 
@@ -65,6 +74,7 @@ type Manager struct {
 	refreshInterval time.Duration
 	logger          *slog.Logger
 	middlewares     []Middleware
+	credentials     CredentialResolver
 
 	inboundQueue   chan inboundTask
 	inboundWorkers int
@@ -75,6 +85,13 @@ type Manager struct {
 	refreshMu      sync.Mutex
 	connections    map[string]*connectionEntry
 	connectionMeta map[string]ConnectionStatus
+
+	outboundQueue        OutboundQueueStore
+	outboundLimiter      *outboundRateLimiter
+	outboundWake         chan struct{}
+	outboundOnce         sync.Once
+	outboundPollInterval time.Duration
+	outboundMaxAttempts  int
 }
 
 // NewManager creates a Manager with the given logger, registry, config store, and inbound processor.
@@ -96,6 +113,11 @@ func NewManager(log *slog.Logger, registry *Registry, service ManagerStore, proc
 		middlewares:     []Middleware{},
 		inboundQueue:    make(chan inboundTask, 256),
 		inboundWorkers:  4,
+
+		outboundLimiter:      newOutboundRateLimiter(),
+		outboundWake:         make(chan struct{}, 1),
+		outboundPollInterval: defaultOutboundPollInterval,
+		outboundMaxAttempts:  defaultOutboundMaxAttempts,
 	}
 }
 
@@ -109,6 +131,43 @@ func (m *Manager) Use(mw ...Middleware) {
 	m.middlewares = append(m.middlewares, mw...)
 }
 
+// SetCredentialResolver configures how secret references in channel
+// credentials are resolved before a config reaches an adapter.
+func (m *Manager) SetCredentialResolver(resolver CredentialResolver) {
+	m.credentials = resolver
+}
+
+// withResolvedCredentials returns a copy of cfg with its credential
+// references resolved.
+func (m *Manager) withResolvedCredentials(ctx context.Context, cfg ChannelConfig) (ChannelConfig, error) {
+	if m.credentials == nil || len(cfg.Credentials) == 0 {
+		return cfg, nil
+	}
+	credentials, err := m.credentials.ResolveCredentials(ctx, cfg.BotID, cfg.Credentials)
+	if err != nil {
+		return ChannelConfig{}, fmt.Errorf("resolve channel credentials: %w", err)
+	}
+	cfg.Credentials = credentials
+	return cfg, nil
+}
+
+// credentialVersions returns the versions of the secrets cfg references.
+// Failures are logged and reported as unchanged, since the connection is
+// still usable until its next restart.
+func (m *Manager) credentialVersions(ctx context.Context, cfg ChannelConfig, current string) string {
+	if m.credentials == nil || len(cfg.Credentials) == 0 {
+		return ""
+	}
+	versions, err := m.credentials.CredentialVersions(ctx, cfg.BotID, cfg.Credentials)
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Warn("check channel secret versions failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return current
+	}
+	return versions
+}
+
 // RegisterAdapter adds an adapter to the registry and logs the registration.
 func (m *Manager) RegisterAdapter(adapter Adapter) {
 	if adapter == nil {
@@ -162,12 +221,14 @@ func (m *Manager) Refresh(ctx context.Context) {
 	}
 }
 
-// Start begins the periodic config refresh loop and inbound worker pool.
+// Start begins the periodic config refresh loop, inbound worker pool and,
+// when a queue is configured, the outbound delivery worker.
 func (m *Manager) Start(ctx context.Context) {
 	if m.logger != nil {
 		m.logger.Info("manager start")
 	}
 	m.startInboundWorkers(ctx)
+	m.startOutboundWorker(ctx)
 	go func() {
 		m.refresh(ctx)
 		ticker := time.NewTicker(m.refreshInterval)
@@ -188,6 +249,8 @@ func (m *Manager) Start(ctx context.Context) {
 }
 
 // Send delivers an outbound message to the specified channel, resolving target and config automatically.
+// With an outbound queue configured, Send returns once the message is durably queued;
+// delivery, rate limiting and retries happen in the background.
 func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelType, req SendRequest) error {
 	if m.service == nil {
 		return fmt.Errorf("channel manager not configured")
@@ -200,6 +263,10 @@ func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelTyp
 	if err != nil {
 		return err
 	}
+	config, err = m.withResolvedCredentials(ctx, config)
+	if err != nil {
+		return err
+	}
 	target := strings.TrimSpace(req.Target)
 	if target == "" {
 		targetChannelIdentityID := strings.TrimSpace(req.ChannelIdentityID)
@@ -235,6 +302,15 @@ func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelTyp
 	if err != nil {
 		return err
 	}
+	if m.outboundQueue != nil {
+		if err := m.enqueueOutbound(ctx, config, outbound); err != nil {
+			if m.logger != nil {
+				m.logger.Error("queue outbound failed", slog.String("channel", channelType.String()), slog.String("bot_id", botID), slog.Any("error", err))
+			}
+			return err
+		}
+		return nil
+	}
 	for _, item := range outbound {
 		if err := m.sendWithConfig(ctx, sender, config, item, policy); err != nil {
 			if m.logger != nil {
@@ -259,6 +335,10 @@ func (m *Manager) React(ctx context.Context, botID string, channelType ChannelTy
 	if err != nil {
 		return err
 	}
+	config, err = m.withResolvedCredentials(ctx, config)
+	if err != nil {
+		return err
+	}
 	target := strings.TrimSpace(req.Target)
 	if target == "" {
 		return fmt.Errorf("target is required for reactions")
@@ -325,3 +325,113 @@ func TestManagerEnsureConnectionDetachesRequestContext(t *testing.T) {
 		t.Fatalf("expected detached context to remain active, got %v", err)
 	}
 }
+
+type fakeCredentialResolver struct {
+	mu       sync.Mutex
+	values   map[string]string
+	versions string
+}
+
+func (f *fakeCredentialResolver) CredentialVersions(ctx context.Context, botID string, credentials map[string]any) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.versions, nil
+}
+
+func (f *fakeCredentialResolver) ResolveCredentials(ctx context.Context, botID string, credentials map[string]any) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resolved := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		if text, ok := value.(string); ok {
+			if secret, ok := f.values[text]; ok {
+				value = secret
+			} else if strings.HasPrefix(text, "${secret:") {
+				return nil, fmt.Errorf("%s: secret not found", key)
+			}
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
+func TestManagerEnsureConnectionResolvesCredentials(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	store := &fakeConfigStore{}
+	reg := NewRegistry()
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(log, reg, store, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetCredentialResolver(&fakeCredentialResolver{values: map[string]string{"${secret:bot_token}": "real-token"}})
+
+	cfg := ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Credentials: map[string]any{"botToken": "${secret:bot_token}"},
+		UpdatedAt:   time.Now(),
+	}
+	if err := manager.EnsureConnection(context.Background(), cfg); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	adapter.mu.Lock()
+	if len(adapter.started) != 1 || adapter.started[0].Credentials["botToken"] != "real-token" {
+		adapter.mu.Unlock()
+		t.Fatalf("expected adapter to receive resolved credentials, got %+v", adapter.started)
+	}
+	adapter.mu.Unlock()
+	if cfg.Credentials["botToken"] != "${secret:bot_token}" {
+		t.Fatalf("expected stored config to keep the reference")
+	}
+
+	missing := cfg
+	missing.ID = "cfg-2"
+	missing.Credentials = map[string]any{"botToken": "${secret:unknown}"}
+	if err := manager.EnsureConnection(context.Background(), missing); err == nil {
+		t.Fatalf("expected unresolved reference to fail")
+	}
+}
+
+func TestManagerEnsureConnectionRestartsOnSecretRotation(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	reg := NewRegistry()
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(log, reg, &fakeConfigStore{}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	resolver := &fakeCredentialResolver{values: map[string]string{"${secret:bot_token}": "token-v1"}, versions: "bot_token@1"}
+	manager.SetCredentialResolver(resolver)
+
+	cfg := ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Credentials: map[string]any{"botToken": "${secret:bot_token}"},
+		UpdatedAt:   time.Now(),
+	}
+	for i := 0; i < 2; i++ {
+		if err := manager.EnsureConnection(context.Background(), cfg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	resolver.mu.Lock()
+	resolver.values["${secret:bot_token}"] = "token-v2"
+	resolver.versions = "bot_token@2"
+	resolver.mu.Unlock()
+	if err := manager.EnsureConnection(context.Background(), cfg); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.started) != 2 || adapter.stops != 1 {
+		t.Fatalf("expected one restart after rotation, got %d starts and %d stops", len(adapter.started), adapter.stops)
+	}
+	if adapter.started[1].Credentials["botToken"] != "token-v2" {
+		t.Fatalf("expected restarted connection to use the rotated secret, got %+v", adapter.started[1].Credentials)
+	}
+}
@@ -27,7 +27,8 @@ const (
 // Chunker splits text into pieces that respect a character limit.
 type Chunker func(text string, limit int) []string
 
-// OutboundPolicy configures how outbound messages are chunked, ordered, and retried.
+// OutboundPolicy configures how outbound messages are chunked, ordered, retried and paced.
+// MinIntervalMs spaces queued deliveries per channel config; zero disables pacing.
 type OutboundPolicy struct {
 	TextChunkLimit int           `json:"text_chunk_limit,omitempty"`
 	ChunkerMode    ChunkerMode   `json:"chunker_mode,omitempty"`
@@ -35,6 +36,7 @@ type OutboundPolicy struct {
 	MediaOrder     OutboundOrder `json:"media_order,omitempty"`
 	RetryMax       int           `json:"retry_max,omitempty"`
 	RetryBackoffMs int           `json:"retry_backoff_ms,omitempty"`
+	MinIntervalMs  int           `json:"min_interval_ms,omitempty"`
 }
 
 // NormalizeOutboundPolicy fills zero-value fields with sensible defaults.
@@ -54,6 +56,9 @@ func NormalizeOutboundPolicy(policy OutboundPolicy) OutboundPolicy {
 	if policy.RetryBackoffMs <= 0 {
 		policy.RetryBackoffMs = 500
 	}
+	if policy.MinIntervalMs < 0 {
+		policy.MinIntervalMs = 0
+	}
 	if policy.Chunker == nil {
 		policy.Chunker = DefaultChunker(policy.ChunkerMode)
 	}
@@ -346,7 +351,9 @@ func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg Channel
 					slog.Int("attempt", i+1),
 					slog.Any("error", err))
 			}
-			time.Sleep(time.Duration(i+1) * time.Duration(policy.RetryBackoffMs) * time.Millisecond)
+			if err := sleepContext(ctx, m.inlineRetryDelay(cfg.ChannelType, err, i, policy)); err != nil {
+				return fmt.Errorf("edit outbound aborted: %w", lastErr)
+			}
 		}
 		return fmt.Errorf("edit outbound failed after retries: %w", lastErr)
 	}
@@ -363,11 +370,21 @@ func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg Channel
 				slog.Int("attempt", i+1),
 				slog.Any("error", err))
 		}
-		time.Sleep(time.Duration(i+1) * time.Duration(policy.RetryBackoffMs) * time.Millisecond)
+		if err := sleepContext(ctx, m.inlineRetryDelay(cfg.ChannelType, err, i, policy)); err != nil {
+			return fmt.Errorf("send outbound aborted: %w", lastErr)
+		}
 	}
 	return fmt.Errorf("send outbound failed after retries: %w", lastErr)
 }
 
+// inlineRetryDelay prefers the platform retry hint over linear backoff.
+func (m *Manager) inlineRetryDelay(channelType ChannelType, err error, attempt int, policy OutboundPolicy) time.Duration {
+	if retryAfter := m.retryAfter(channelType, err); retryAfter > 0 {
+		return retryAfter
+	}
+	return time.Duration(attempt+1) * time.Duration(policy.RetryBackoffMs) * time.Millisecond
+}
+
 func normalizeAttachmentRefs(attachments []Attachment, defaultPlatform ChannelType) ([]Attachment, error) {
 	if len(attachments) == 0 {
 		return nil, nil
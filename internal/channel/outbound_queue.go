@@ -0,0 +1,408 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOutboundMaxAttempts  = 8
+	defaultOutboundPollInterval = 5 * time.Second
+	defaultOutboundLease        = 2 * time.Minute
+	defaultOutboundClaimBatch   = 32
+	defaultOutboundMaxBackoff   = 10 * time.Minute
+	defaultOutboundRetention    = 7 * 24 * time.Hour
+	outboundPruneInterval       = time.Hour
+	// outboundInlineWaitMax is the longest rate-limit wait the worker sleeps
+	// through; longer waits reschedule the delivery instead of blocking others.
+	outboundInlineWaitMax = 2 * time.Second
+	// defaultOutboundMaxDeferrals caps how often a delivery is pushed back
+	// for rate limits without using up an attempt, so a target that stays
+	// rate limited ends up in the dead-letter queue.
+	defaultOutboundMaxDeferrals = 50
+)
+
+// OutboundDeliveryStatus is the lifecycle state of a queued outbound delivery.
+type OutboundDeliveryStatus string
+
+const (
+	OutboundDeliveryPending   OutboundDeliveryStatus = "pending"
+	OutboundDeliverySending   OutboundDeliveryStatus = "sending"
+	OutboundDeliveryDelivered OutboundDeliveryStatus = "delivered"
+	OutboundDeliveryDead      OutboundDeliveryStatus = "dead"
+)
+
+// OutboundDelivery is one persisted outbound message and its delivery state.
+type OutboundDelivery struct {
+	ID            string                 `json:"id"`
+	BotID         string                 `json:"bot_id"`
+	ChannelType   ChannelType            `json:"channel_type"`
+	ConfigID      string                 `json:"config_id"`
+	Target        string                 `json:"target"`
+	Message       Message                `json:"message"`
+	Status        OutboundDeliveryStatus `json:"status"`
+	Attempts      int                    `json:"attempts"`
+	MaxAttempts   int                    `json:"max_attempts"`
+	Deferrals     int                    `json:"deferrals"`
+	LastError     string                 `json:"last_error,omitempty"`
+	NextAttemptAt time.Time              `json:"next_attempt_at"`
+	DeliveredAt   time.Time              `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// OutboundDeliveryFilter narrows queued delivery listings.
+type OutboundDeliveryFilter struct {
+	Status      OutboundDeliveryStatus `json:"status,omitempty"`
+	ChannelType ChannelType            `json:"channel_type,omitempty"`
+	Limit       int                    `json:"limit"`
+	Offset      int                    `json:"offset"`
+}
+
+// OutboundQueueStore persists outbound deliveries so they survive restarts and
+// platform outages. Store implements it on top of Postgres.
+type OutboundQueueStore interface {
+	// EnqueueOutbound persists the deliveries of one send atomically.
+	EnqueueOutbound(ctx context.Context, items []OutboundDelivery) ([]OutboundDelivery, error)
+	ClaimDueOutbound(ctx context.Context, limit int, lease time.Duration) ([]OutboundDelivery, error)
+	MarkOutboundDelivered(ctx context.Context, id string) error
+	RescheduleOutbound(ctx context.Context, id string, nextAttemptAt time.Time, lastError string, refundAttempt bool) error
+	MarkOutboundDead(ctx context.Context, id string, lastError string) error
+	PruneDeliveredOutbound(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SetOutboundQueue enables persistent outbound delivery. When set, Send enqueues
+// messages and a background worker delivers them with rate limiting and retries.
+func (m *Manager) SetOutboundQueue(store OutboundQueueStore) {
+	m.outboundQueue = store
+}
+
+// NotifyOutbound wakes the outbound worker, e.g. after deliveries were replayed.
+func (m *Manager) NotifyOutbound() {
+	select {
+	case m.outboundWake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueueOutbound validates and persists outbound messages for asynchronous delivery.
+func (m *Manager) enqueueOutbound(ctx context.Context, cfg ChannelConfig, items []OutboundMessage) error {
+	for _, item := range items {
+		if err := m.validateOutbound(cfg, item); err != nil {
+			return err
+		}
+	}
+	deliveries := make([]OutboundDelivery, 0, len(items))
+	for _, item := range items {
+		deliveries = append(deliveries, OutboundDelivery{
+			BotID:       cfg.BotID,
+			ChannelType: cfg.ChannelType,
+			ConfigID:    cfg.ID,
+			Target:      strings.TrimSpace(item.Target),
+			Message:     item.Message,
+			MaxAttempts: m.outboundMaxAttempts,
+		})
+	}
+	if _, err := m.outboundQueue.EnqueueOutbound(ctx, deliveries); err != nil {
+		return fmt.Errorf("enqueue outbound: %w", err)
+	}
+	m.NotifyOutbound()
+	return nil
+}
+
+// validateOutbound rejects messages that can never be delivered so they fail
+// synchronously instead of ending up in the dead-letter queue.
+func (m *Manager) validateOutbound(cfg ChannelConfig, msg OutboundMessage) error {
+	if strings.TrimSpace(msg.Target) == "" {
+		return fmt.Errorf("target is required")
+	}
+	if msg.Message.IsEmpty() {
+		return fmt.Errorf("message is required")
+	}
+	attachments, err := normalizeAttachmentRefs(msg.Message.Attachments, cfg.ChannelType)
+	if err != nil {
+		return err
+	}
+	normalized := msg.Message
+	normalized.Attachments = attachments
+	if err := validateMessageCapabilities(m.registry, cfg.ChannelType, normalized); err != nil {
+		return err
+	}
+	if strings.TrimSpace(normalized.ID) != "" {
+		if _, ok := m.registry.GetMessageEditor(cfg.ChannelType); !ok {
+			return fmt.Errorf("channel does not support edit")
+		}
+	}
+	return nil
+}
+
+func (m *Manager) startOutboundWorker(ctx context.Context) {
+	if m.outboundQueue == nil {
+		return
+	}
+	m.outboundOnce.Do(func() {
+		go m.runOutboundWorker(ctx)
+	})
+}
+
+func (m *Manager) runOutboundWorker(ctx context.Context) {
+	ticker := time.NewTicker(m.outboundPollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		m.drainOutbound(ctx)
+		if time.Since(lastPrune) >= outboundPruneInterval {
+			m.pruneOutbound(ctx)
+			lastPrune = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.outboundWake:
+		}
+	}
+}
+
+func (m *Manager) drainOutbound(ctx context.Context) {
+	for ctx.Err() == nil {
+		items, err := m.outboundQueue.ClaimDueOutbound(ctx, defaultOutboundClaimBatch, defaultOutboundLease)
+		if err != nil {
+			if m.logger != nil && ctx.Err() == nil {
+				m.logger.Error("claim outbound failed", slog.Any("error", err))
+			}
+			return
+		}
+		for _, item := range items {
+			if ctx.Err() != nil {
+				return
+			}
+			m.deliverOutbound(ctx, item)
+		}
+		if len(items) < defaultOutboundClaimBatch {
+			return
+		}
+	}
+}
+
+func (m *Manager) pruneOutbound(ctx context.Context) {
+	removed, err := m.outboundQueue.PruneDeliveredOutbound(ctx, time.Now().Add(-defaultOutboundRetention))
+	if err != nil {
+		if m.logger != nil && ctx.Err() == nil {
+			m.logger.Warn("prune outbound failed", slog.Any("error", err))
+		}
+		return
+	}
+	if removed > 0 && m.logger != nil {
+		m.logger.Info("pruned delivered outbound", slog.Int64("count", removed))
+	}
+}
+
+// deliverOutbound makes a single delivery attempt for a claimed item and records the outcome.
+func (m *Manager) deliverOutbound(ctx context.Context, item OutboundDelivery) {
+	log := m.logger
+	if log != nil {
+		log = log.With(
+			slog.String("delivery_id", item.ID),
+			slog.String("channel", item.ChannelType.String()),
+			slog.String("bot_id", item.BotID),
+		)
+	}
+	sender, ok := m.registry.GetSender(item.ChannelType)
+	if !ok {
+		m.failOutbound(ctx, log, item, fmt.Errorf("unsupported channel type: %s", item.ChannelType), true)
+		return
+	}
+	if m.service == nil {
+		m.failOutbound(ctx, log, item, fmt.Errorf("channel manager not configured"), false)
+		return
+	}
+	cfg, err := m.service.ResolveEffectiveConfig(ctx, item.BotID, item.ChannelType)
+	if err != nil {
+		m.failOutbound(ctx, log, item, fmt.Errorf("resolve channel config: %w", err), false)
+		return
+	}
+	cfg, err = m.withResolvedCredentials(ctx, cfg)
+	if err != nil {
+		m.failOutbound(ctx, log, item, err, false)
+		return
+	}
+	key := outboundLimiterKey(cfg)
+	policy := m.resolveOutboundPolicy(item.ChannelType)
+	wait := m.outboundLimiter.reserve(key, time.Duration(policy.MinIntervalMs)*time.Millisecond)
+	if wait > outboundInlineWaitMax {
+		m.deferOutbound(ctx, log, item, time.Now().Add(wait), item.LastError)
+		return
+	}
+	if wait > 0 {
+		if err := sleepContext(ctx, wait); err != nil {
+			m.rescheduleOutbound(ctx, log, item, time.Now(), item.LastError, true)
+			return
+		}
+	}
+	err = m.sendOutboundOnce(ctx, sender, cfg, OutboundMessage{Target: item.Target, Message: item.Message})
+	if err == nil {
+		if markErr := m.outboundQueue.MarkOutboundDelivered(ctx, item.ID); markErr != nil && log != nil {
+			log.Error("mark outbound delivered failed", slog.Any("error", markErr))
+		}
+		return
+	}
+	if retryAfter := m.retryAfter(item.ChannelType, err); retryAfter > 0 {
+		until := time.Now().Add(retryAfter)
+		m.outboundLimiter.block(key, until)
+		if log != nil {
+			log.Warn("outbound rate limited", slog.Duration("retry_after", retryAfter), slog.Any("error", err))
+		}
+		m.deferOutbound(ctx, log, item, until, err.Error())
+		return
+	}
+	m.failOutbound(ctx, log, item, err, false)
+}
+
+// deferOutbound pushes a rate-limited delivery back without counting the
+// attempt, or dead-letters it once it was deferred too often.
+func (m *Manager) deferOutbound(ctx context.Context, log *slog.Logger, item OutboundDelivery, until time.Time, lastError string) {
+	if item.Deferrals >= defaultOutboundMaxDeferrals {
+		cause := fmt.Errorf("rate limited %d times", item.Deferrals)
+		if strings.TrimSpace(lastError) != "" {
+			cause = fmt.Errorf("rate limited %d times: %s", item.Deferrals, lastError)
+		}
+		m.failOutbound(ctx, log, item, cause, true)
+		return
+	}
+	m.rescheduleOutbound(ctx, log, item, until, lastError, true)
+}
+
+// failOutbound schedules a retry with exponential backoff, or dead-letters the
+// delivery once it is out of attempts (or the failure is permanent).
+func (m *Manager) failOutbound(ctx context.Context, log *slog.Logger, item OutboundDelivery, cause error, permanent bool) {
+	if permanent || item.Attempts >= item.MaxAttempts {
+		if log != nil {
+			log.Error("outbound moved to dead letter", slog.Int("attempts", item.Attempts), slog.Any("error", cause))
+		}
+		if err := m.outboundQueue.MarkOutboundDead(ctx, item.ID, cause.Error()); err != nil && log != nil {
+			log.Error("mark outbound dead failed", slog.Any("error", err))
+		}
+		return
+	}
+	backoff := outboundBackoff(item.Attempts, m.resolveOutboundPolicy(item.ChannelType))
+	if log != nil {
+		log.Warn("outbound delivery failed, will retry",
+			slog.Int("attempt", item.Attempts),
+			slog.Duration("backoff", backoff),
+			slog.Any("error", cause))
+	}
+	m.rescheduleOutbound(ctx, log, item, time.Now().Add(backoff), cause.Error(), false)
+}
+
+func (m *Manager) rescheduleOutbound(ctx context.Context, log *slog.Logger, item OutboundDelivery, next time.Time, lastError string, refund bool) {
+	// Use a detached context so the lease is released even during shutdown.
+	if err := m.outboundQueue.RescheduleOutbound(context.WithoutCancel(ctx), item.ID, next, lastError, refund); err != nil && log != nil {
+		log.Error("reschedule outbound failed", slog.Any("error", err))
+	}
+}
+
+// sendOutboundOnce performs exactly one send or edit call against the adapter.
+func (m *Manager) sendOutboundOnce(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage) error {
+	attachments, err := normalizeAttachmentRefs(msg.Message.Attachments, cfg.ChannelType)
+	if err != nil {
+		return err
+	}
+	msg.Message.Attachments = attachments
+	if messageID := strings.TrimSpace(msg.Message.ID); messageID != "" {
+		editor, ok := m.registry.GetMessageEditor(cfg.ChannelType)
+		if !ok {
+			return fmt.Errorf("channel does not support edit")
+		}
+		return editor.Update(ctx, cfg, strings.TrimSpace(msg.Target), messageID, msg.Message)
+	}
+	return sender.Send(ctx, cfg, OutboundMessage{Target: strings.TrimSpace(msg.Target), Message: msg.Message})
+}
+
+// retryAfter asks the adapter for a platform-mandated wait derived from err.
+func (m *Manager) retryAfter(channelType ChannelType, err error) time.Duration {
+	resolver, ok := m.registry.GetRetryAfterResolver(channelType)
+	if !ok {
+		return 0
+	}
+	return resolver.RetryAfter(err)
+}
+
+func outboundBackoff(attempt int, policy OutboundPolicy) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	backoff := time.Duration(policy.RetryBackoffMs) * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= defaultOutboundMaxBackoff {
+			return defaultOutboundMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func outboundLimiterKey(cfg ChannelConfig) string {
+	if id := strings.TrimSpace(cfg.ID); id != "" {
+		return id
+	}
+	return cfg.ChannelType.String() + ":" + strings.TrimSpace(cfg.BotID)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// outboundRateLimiter spaces deliveries per channel config and honors
+// platform back-off windows such as Telegram's retry_after.
+type outboundRateLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newOutboundRateLimiter() *outboundRateLimiter {
+	return &outboundRateLimiter{next: map[string]time.Time{}}
+}
+
+// reserve claims the next send slot for key and returns how long to wait for it.
+func (l *outboundRateLimiter) reserve(key string, interval time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	slot := l.next[key]
+	if slot.Before(now) {
+		slot = now
+	}
+	wait := slot.Sub(now)
+	if wait > outboundInlineWaitMax {
+		// Do not hand out a slot the caller will not use.
+		return wait
+	}
+	if interval > 0 || wait > 0 {
+		l.next[key] = slot.Add(interval)
+	}
+	return wait
+}
+
+// block prevents any send for key until the given time.
+func (l *outboundRateLimiter) block(key string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.next[key]) {
+		l.next[key] = until
+	}
+}
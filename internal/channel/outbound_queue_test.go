@@ -0,0 +1,317 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeOutboundQueue struct {
+	mu          sync.Mutex
+	enqueued    []OutboundDelivery
+	delivered   []string
+	dead        map[string]string
+	rescheduled []fakeReschedule
+}
+
+type fakeReschedule struct {
+	id     string
+	next   time.Time
+	reason string
+	refund bool
+}
+
+func (f *fakeOutboundQueue) EnqueueOutbound(ctx context.Context, items []OutboundDelivery) ([]OutboundDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	enqueued := make([]OutboundDelivery, 0, len(items))
+	for _, item := range items {
+		item.ID = fmt.Sprintf("delivery-%d", len(f.enqueued)+1)
+		item.Status = OutboundDeliveryPending
+		f.enqueued = append(f.enqueued, item)
+		enqueued = append(enqueued, item)
+	}
+	return enqueued, nil
+}
+
+func (f *fakeOutboundQueue) ClaimDueOutbound(ctx context.Context, limit int, lease time.Duration) ([]OutboundDelivery, error) {
+	return nil, nil
+}
+
+func (f *fakeOutboundQueue) MarkOutboundDelivered(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delivered = append(f.delivered, id)
+	return nil
+}
+
+func (f *fakeOutboundQueue) RescheduleOutbound(ctx context.Context, id string, nextAttemptAt time.Time, lastError string, refundAttempt bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rescheduled = append(f.rescheduled, fakeReschedule{id: id, next: nextAttemptAt, reason: lastError, refund: refundAttempt})
+	return nil
+}
+
+func (f *fakeOutboundQueue) MarkOutboundDead(ctx context.Context, id string, lastError string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dead == nil {
+		f.dead = map[string]string{}
+	}
+	f.dead[id] = lastError
+	return nil
+}
+
+func (f *fakeOutboundQueue) PruneDeliveredOutbound(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type rateLimitedAdapter struct {
+	fakeAdapter
+	sendErr    error
+	retryAfter time.Duration
+}
+
+func (a *rateLimitedAdapter) Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) error {
+	if a.sendErr != nil {
+		return a.sendErr
+	}
+	return a.fakeAdapter.Send(ctx, cfg, msg)
+}
+
+func (a *rateLimitedAdapter) RetryAfter(err error) time.Duration {
+	if err != nil && errors.Is(err, a.sendErr) {
+		return a.retryAfter
+	}
+	return 0
+}
+
+func newOutboundTestManager(adapter Adapter, queue *fakeOutboundQueue) *Manager {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	store := &fakeConfigStore{
+		effectiveConfig: ChannelConfig{
+			ID:          "cfg-1",
+			BotID:       "bot-1",
+			ChannelType: ChannelType("test"),
+		},
+	}
+	manager := NewManager(log, NewRegistry(), store, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetOutboundQueue(queue)
+	return manager
+}
+
+func TestManagerSendEnqueuesWhenQueueConfigured(t *testing.T) {
+	t.Parallel()
+
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(adapter, queue)
+
+	err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{
+		Target:  "123",
+		Message: Message{Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("expected 1 enqueued delivery, got %d", len(queue.enqueued))
+	}
+	got := queue.enqueued[0]
+	if got.BotID != "bot-1" || got.ConfigID != "cfg-1" || got.Target != "123" || got.Message.PlainText() != "hello" {
+		t.Fatalf("unexpected delivery: %+v", got)
+	}
+	if got.MaxAttempts != defaultOutboundMaxAttempts {
+		t.Fatalf("expected max attempts %d, got %d", defaultOutboundMaxAttempts, got.MaxAttempts)
+	}
+	if len(adapter.sent) != 0 {
+		t.Fatalf("expected no inline send, got %d", len(adapter.sent))
+	}
+}
+
+func TestManagerSendRejectsInvalidBeforeEnqueue(t *testing.T) {
+	t.Parallel()
+
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(&fakeAdapter{channelType: ChannelType("test")}, queue)
+
+	err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{
+		Target:  "123",
+		Message: Message{Attachments: []Attachment{{Type: AttachmentImage, URL: "https://example.com/a.png"}}},
+	})
+	if err == nil {
+		t.Fatalf("expected capability error")
+	}
+	if len(queue.enqueued) != 0 {
+		t.Fatalf("expected nothing enqueued, got %d", len(queue.enqueued))
+	}
+}
+
+func TestDeliverOutboundMarksDelivered(t *testing.T) {
+	t.Parallel()
+
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(adapter, queue)
+
+	manager.deliverOutbound(context.Background(), OutboundDelivery{
+		ID:          "d-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Target:      "123",
+		Message:     Message{Text: "hi"},
+		Attempts:    1,
+		MaxAttempts: 3,
+	})
+	if len(queue.delivered) != 1 || queue.delivered[0] != "d-1" {
+		t.Fatalf("expected d-1 delivered, got %v", queue.delivered)
+	}
+	if len(adapter.sent) != 1 || adapter.sent[0].Target != "123" {
+		t.Fatalf("unexpected sends: %+v", adapter.sent)
+	}
+}
+
+func TestDeliverOutboundRetryAfterRefundsAttempt(t *testing.T) {
+	t.Parallel()
+
+	adapter := &rateLimitedAdapter{
+		fakeAdapter: fakeAdapter{channelType: ChannelType("test")},
+		sendErr:     errors.New("too many requests"),
+		retryAfter:  30 * time.Second,
+	}
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(adapter, queue)
+
+	before := time.Now()
+	manager.deliverOutbound(context.Background(), OutboundDelivery{
+		ID:          "d-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Target:      "123",
+		Message:     Message{Text: "hi"},
+		Attempts:    3,
+		MaxAttempts: 3,
+	})
+	if len(queue.dead) != 0 {
+		t.Fatalf("rate limited delivery must not be dead-lettered: %v", queue.dead)
+	}
+	if len(queue.rescheduled) != 1 {
+		t.Fatalf("expected 1 reschedule, got %d", len(queue.rescheduled))
+	}
+	got := queue.rescheduled[0]
+	if !got.refund {
+		t.Fatalf("expected attempt to be refunded")
+	}
+	if got.next.Before(before.Add(30 * time.Second)) {
+		t.Fatalf("expected next attempt after retry_after, got %v", got.next)
+	}
+	if wait := manager.outboundLimiter.reserve(outboundLimiterKey(ChannelConfig{ID: "cfg-1"}), 0); wait < 20*time.Second {
+		t.Fatalf("expected limiter to block the config, wait=%v", wait)
+	}
+}
+
+func TestDeliverOutboundDeadLettersAfterMaxDeferrals(t *testing.T) {
+	t.Parallel()
+
+	adapter := &rateLimitedAdapter{
+		fakeAdapter: fakeAdapter{channelType: ChannelType("test")},
+		sendErr:     errors.New("too many requests"),
+		retryAfter:  30 * time.Second,
+	}
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(adapter, queue)
+
+	manager.deliverOutbound(context.Background(), OutboundDelivery{
+		ID:          "d-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Target:      "123",
+		Message:     Message{Text: "hi"},
+		Attempts:    1,
+		MaxAttempts: 3,
+		Deferrals:   defaultOutboundMaxDeferrals,
+	})
+	if len(queue.rescheduled) != 0 {
+		t.Fatalf("expected no further deferral, got %+v", queue.rescheduled)
+	}
+	if queue.dead["d-1"] != "rate limited 50 times: too many requests" {
+		t.Fatalf("expected d-1 dead-lettered after max deferrals, got %v", queue.dead)
+	}
+}
+
+func TestDeliverOutboundDeadLettersAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	adapter := &rateLimitedAdapter{
+		fakeAdapter: fakeAdapter{channelType: ChannelType("test")},
+		sendErr:     errors.New("boom"),
+	}
+	queue := &fakeOutboundQueue{}
+	manager := newOutboundTestManager(adapter, queue)
+
+	item := OutboundDelivery{
+		ID:          "d-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		Target:      "123",
+		Message:     Message{Text: "hi"},
+		Attempts:    1,
+		MaxAttempts: 2,
+	}
+	manager.deliverOutbound(context.Background(), item)
+	if len(queue.rescheduled) != 1 || queue.rescheduled[0].refund {
+		t.Fatalf("expected a counted retry, got %+v", queue.rescheduled)
+	}
+
+	item.Attempts = 2
+	manager.deliverOutbound(context.Background(), item)
+	if queue.dead["d-1"] != "boom" {
+		t.Fatalf("expected d-1 dead-lettered with last error, got %v", queue.dead)
+	}
+}
+
+func TestOutboundBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := OutboundPolicy{RetryBackoffMs: 500}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 500 * time.Millisecond},
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{4, 4 * time.Second},
+		{30, defaultOutboundMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := outboundBackoff(tt.attempt, policy); got != tt.want {
+			t.Fatalf("outboundBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestOutboundRateLimiterSpacesSends(t *testing.T) {
+	t.Parallel()
+
+	limiter := newOutboundRateLimiter()
+	if wait := limiter.reserve("cfg", 100*time.Millisecond); wait != 0 {
+		t.Fatalf("first reserve should not wait, got %v", wait)
+	}
+	if wait := limiter.reserve("cfg", 100*time.Millisecond); wait <= 0 || wait > 100*time.Millisecond {
+		t.Fatalf("second reserve should wait up to the interval, got %v", wait)
+	}
+	if wait := limiter.reserve("other", 100*time.Millisecond); wait != 0 {
+		t.Fatalf("other keys must not be delayed, got %v", wait)
+	}
+	limiter.block("cfg", time.Now().Add(time.Minute))
+	if wait := limiter.reserve("cfg", 0); wait < 50*time.Second {
+		t.Fatalf("blocked key should wait for the block, got %v", wait)
+	}
+}
@@ -0,0 +1,326 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// ErrOutboundDeliveryNotFound indicates the queued delivery does not exist or is not replayable.
+var ErrOutboundDeliveryNotFound = errors.New("outbound delivery not found")
+
+// EnqueueOutbound persists pending outbound deliveries in one transaction,
+// so a reply is queued completely or not at all.
+func (s *Store) EnqueueOutbound(ctx context.Context, items []OutboundDelivery) ([]OutboundDelivery, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	queries := s.queries
+	var tx pgx.Tx
+	if s.pool != nil {
+		var err error
+		if tx, err = s.pool.BeginTx(ctx, pgx.TxOptions{}); err != nil {
+			return nil, err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		queries = s.queries.WithTx(tx)
+	}
+	enqueued := make([]OutboundDelivery, 0, len(items))
+	for _, item := range items {
+		delivery, err := enqueueOutbound(ctx, queries, item)
+		if err != nil {
+			return nil, err
+		}
+		enqueued = append(enqueued, delivery)
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return enqueued, nil
+}
+
+func enqueueOutbound(ctx context.Context, queries *sqlc.Queries, item OutboundDelivery) (OutboundDelivery, error) {
+	botUUID, err := db.ParseUUID(item.BotID)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	payload, err := json.Marshal(item.Message)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	maxAttempts := item.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOutboundMaxAttempts
+	}
+	row, err := queries.EnqueueChannelOutbound(ctx, sqlc.EnqueueChannelOutboundParams{
+		BotID:       botUUID,
+		ChannelType: item.ChannelType.String(),
+		ConfigID:    strings.TrimSpace(item.ConfigID),
+		Target:      strings.TrimSpace(item.Target),
+		Message:     payload,
+		MaxAttempts: int32(maxAttempts),
+	})
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	return outboundDeliveryFromRow(row)
+}
+
+// ClaimDueOutbound leases up to limit due deliveries for the calling worker.
+func (s *Store) ClaimDueOutbound(ctx context.Context, limit int, lease time.Duration) ([]OutboundDelivery, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+	rows, err := s.queries.ClaimDueChannelOutbound(ctx, sqlc.ClaimDueChannelOutboundParams{
+		LockedUntil: pgtype.Timestamptz{Time: time.Now().Add(lease).UTC(), Valid: true},
+		MaxCount:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboundDeliveriesFromRows(rows)
+}
+
+// MarkOutboundDelivered records a successful delivery.
+func (s *Store) MarkOutboundDelivered(ctx context.Context, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.MarkChannelOutboundDelivered(ctx, pgID)
+}
+
+// RescheduleOutbound returns a delivery to the pending state for a later attempt.
+// When refundAttempt is true the current attempt is not counted against max attempts
+// and counts as a deferral instead.
+func (s *Store) RescheduleOutbound(ctx context.Context, id string, nextAttemptAt time.Time, lastError string, refundAttempt bool) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.RescheduleChannelOutbound(ctx, sqlc.RescheduleChannelOutboundParams{
+		RefundAttempt: refundAttempt,
+		LastError:     lastError,
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt.UTC(), Valid: true},
+		ID:            pgID,
+	})
+}
+
+// MarkOutboundDead moves a delivery to the dead-letter state.
+func (s *Store) MarkOutboundDead(ctx context.Context, id string, lastError string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.MarkChannelOutboundDead(ctx, sqlc.MarkChannelOutboundDeadParams{
+		LastError: lastError,
+		ID:        pgID,
+	})
+}
+
+// PruneDeliveredOutbound deletes delivered items older than the given time.
+func (s *Store) PruneDeliveredOutbound(ctx context.Context, before time.Time) (int64, error) {
+	if s.queries == nil {
+		return 0, fmt.Errorf("channel queries not configured")
+	}
+	return s.queries.PruneDeliveredChannelOutbound(ctx, pgtype.Timestamptz{Time: before.UTC(), Valid: true})
+}
+
+// ListOutbound returns queued deliveries for a bot, newest first.
+func (s *Store) ListOutbound(ctx context.Context, botID string, filter OutboundDeliveryFilter) ([]OutboundDelivery, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	status := strings.TrimSpace(string(filter.Status))
+	channelType := strings.TrimSpace(filter.ChannelType.String())
+	rows, err := s.queries.ListChannelOutboundByBot(ctx, sqlc.ListChannelOutboundByBotParams{
+		BotID:       botUUID,
+		Status:      pgtype.Text{String: status, Valid: status != ""},
+		ChannelType: pgtype.Text{String: channelType, Valid: channelType != ""},
+		ItemOffset:  int32(offset),
+		MaxCount:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboundDeliveriesFromRows(rows)
+}
+
+// CountOutboundByStatus returns the number of queued deliveries per status for a bot.
+func (s *Store) CountOutboundByStatus(ctx context.Context, botID string) (map[OutboundDeliveryStatus]int64, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.CountChannelOutboundByStatus(ctx, botUUID)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[OutboundDeliveryStatus]int64{}
+	for _, row := range rows {
+		counts[OutboundDeliveryStatus(row.Status)] = row.Count
+	}
+	return counts, nil
+}
+
+// GetOutbound returns a single queued delivery owned by the bot.
+func (s *Store) GetOutbound(ctx context.Context, botID, id string) (OutboundDelivery, error) {
+	if s.queries == nil {
+		return OutboundDelivery{}, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	row, err := s.queries.GetChannelOutboundByID(ctx, sqlc.GetChannelOutboundByIDParams{
+		ID:    pgID,
+		BotID: botUUID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OutboundDelivery{}, ErrOutboundDeliveryNotFound
+		}
+		return OutboundDelivery{}, err
+	}
+	return outboundDeliveryFromRow(row)
+}
+
+// ReplayOutbound resets a dead-lettered delivery so it is attempted again.
+func (s *Store) ReplayOutbound(ctx context.Context, botID, id string) (OutboundDelivery, error) {
+	if s.queries == nil {
+		return OutboundDelivery{}, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return OutboundDelivery{}, err
+	}
+	row, err := s.queries.ReplayChannelOutbound(ctx, sqlc.ReplayChannelOutboundParams{
+		ID:    pgID,
+		BotID: botUUID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OutboundDelivery{}, ErrOutboundDeliveryNotFound
+		}
+		return OutboundDelivery{}, err
+	}
+	return outboundDeliveryFromRow(row)
+}
+
+// ReplayDeadOutbound resets every dead-lettered delivery of a bot and returns how many were requeued.
+func (s *Store) ReplayDeadOutbound(ctx context.Context, botID string) (int64, error) {
+	if s.queries == nil {
+		return 0, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return 0, err
+	}
+	return s.queries.ReplayDeadChannelOutboundByBot(ctx, botUUID)
+}
+
+// DeleteOutbound removes a queued delivery regardless of its status.
+func (s *Store) DeleteOutbound(ctx context.Context, botID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.DeleteChannelOutbound(ctx, sqlc.DeleteChannelOutboundParams{
+		ID:    pgID,
+		BotID: botUUID,
+	})
+}
+
+func outboundDeliveriesFromRows(rows []sqlc.ChannelOutboundQueue) ([]OutboundDelivery, error) {
+	items := make([]OutboundDelivery, 0, len(rows))
+	for _, row := range rows {
+		item, err := outboundDeliveryFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func outboundDeliveryFromRow(row sqlc.ChannelOutboundQueue) (OutboundDelivery, error) {
+	var msg Message
+	if len(row.Message) > 0 {
+		if err := json.Unmarshal(row.Message, &msg); err != nil {
+			return OutboundDelivery{}, fmt.Errorf("decode outbound message: %w", err)
+		}
+	}
+	return OutboundDelivery{
+		ID:            row.ID.String(),
+		BotID:         row.BotID.String(),
+		ChannelType:   ChannelType(row.ChannelType),
+		ConfigID:      row.ConfigID,
+		Target:        row.Target,
+		Message:       msg,
+		Status:        OutboundDeliveryStatus(row.Status),
+		Attempts:      int(row.Attempts),
+		MaxAttempts:   int(row.MaxAttempts),
+		Deferrals:     int(row.Deferrals),
+		LastError:     row.LastError,
+		NextAttemptAt: db.TimeFromPg(row.NextAttemptAt),
+		DeliveredAt:   db.TimeFromPg(row.DeliveredAt),
+		CreatedAt:     db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:     db.TimeFromPg(row.UpdatedAt),
+	}, nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/memohai/memoh/internal/secrets"
 )
 
 // Registry holds all registered channel adapters and provides dispatch methods
@@ -246,6 +248,17 @@ func (r *Registry) GetProcessingStatusNotifier(channelType ChannelType) (Process
 	return notifier, ok
 }
 
+// GetProcessingQueueNotifier returns the ProcessingQueueNotifier for the given
+// channel type, or nil if unsupported.
+func (r *Registry) GetProcessingQueueNotifier(channelType ChannelType) (ProcessingQueueNotifier, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	notifier, ok := adapter.(ProcessingQueueNotifier)
+	return notifier, ok
+}
+
 // GetAttachmentResolver returns the AttachmentResolver for the given channel
 // type, or nil if unsupported.
 func (r *Registry) GetAttachmentResolver(channelType ChannelType) (AttachmentResolver, bool) {
@@ -257,6 +270,17 @@ func (r *Registry) GetAttachmentResolver(channelType ChannelType) (AttachmentRes
 	return resolver, ok
 }
 
+// GetRetryAfterResolver returns the RetryAfterResolver for the given channel
+// type, or nil if unsupported.
+func (r *Registry) GetRetryAfterResolver(channelType ChannelType) (RetryAfterResolver, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	resolver, ok := adapter.(RetryAfterResolver)
+	return resolver, ok
+}
+
 // DiscoverSelf calls the SelfDiscoverer for the given channel type if supported.
 func (r *Registry) DiscoverSelf(ctx context.Context, channelType ChannelType, credentials map[string]any) (map[string]any, string, error) {
 	adapter, ok := r.Get(channelType)
@@ -270,6 +294,47 @@ func (r *Registry) DiscoverSelf(ctx context.Context, channelType ChannelType, cr
 	return discoverer.DiscoverSelf(ctx, credentials)
 }
 
+// MaskCredentials returns a copy of credentials with the secret fields of
+// the channel's config schema masked for display.
+func (r *Registry) MaskCredentials(channelType ChannelType, credentials map[string]any) map[string]any {
+	if credentials == nil {
+		return nil
+	}
+	schema, _ := r.GetConfigSchema(channelType)
+	masked := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		if field, ok := schema.Fields[key]; ok && field.Type == FieldSecret {
+			if text, ok := value.(string); ok {
+				value = secrets.Mask(text)
+			}
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// RestoreMaskedCredentials returns a copy of incoming in which secret
+// fields still holding the masked form of the stored value are restored, so
+// saving a config read from the API keeps its credentials.
+func (r *Registry) RestoreMaskedCredentials(channelType ChannelType, incoming, stored map[string]any) map[string]any {
+	if len(incoming) == 0 || len(stored) == 0 {
+		return incoming
+	}
+	schema, _ := r.GetConfigSchema(channelType)
+	restored := make(map[string]any, len(incoming))
+	for key, value := range incoming {
+		if field, ok := schema.Fields[key]; ok && field.Type == FieldSecret {
+			text, textOK := value.(string)
+			current, currentOK := stored[key].(string)
+			if textOK && currentOK {
+				value = secrets.Unmask(text, current)
+			}
+		}
+		restored[key] = value
+	}
+	return restored
+}
+
 // --- Dispatch methods (replace former global functions in config.go / target.go) ---
 
 // NormalizeConfig validates and normalizes a channel configuration map.
@@ -101,3 +101,47 @@ func TestGetAttachmentResolver_Unsupported(t *testing.T) {
 		t.Fatalf("GetAttachmentResolver(test) = (%v, %v), want (nil, false)", resolver, ok)
 	}
 }
+
+type secretConfigAdapter struct{}
+
+func (a *secretConfigAdapter) Type() channel.ChannelType { return channel.ChannelType("secret-test") }
+
+func (a *secretConfigAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type: channel.ChannelType("secret-test"),
+		ConfigSchema: channel.ConfigSchema{
+			Fields: map[string]channel.FieldSchema{
+				"appId":     {Type: channel.FieldString},
+				"appSecret": {Type: channel.FieldSecret},
+			},
+		},
+	}
+}
+
+func TestMaskCredentials(t *testing.T) {
+	t.Parallel()
+	reg := channel.NewRegistry()
+	reg.MustRegister(&secretConfigAdapter{})
+	channelType := channel.ChannelType("secret-test")
+
+	stored := map[string]any{"appId": "cli_1234567890", "appSecret": "s3cr3t-value-123"}
+	masked := reg.MaskCredentials(channelType, stored)
+	if masked["appId"] != "cli_1234567890" {
+		t.Fatalf("non-secret field changed: %v", masked["appId"])
+	}
+	if masked["appSecret"] != "s3cr************" {
+		t.Fatalf("secret field not masked: %v", masked["appSecret"])
+	}
+	if stored["appSecret"] != "s3cr3t-value-123" {
+		t.Fatalf("MaskCredentials modified its input")
+	}
+
+	restored := reg.RestoreMaskedCredentials(channelType, masked, stored)
+	if restored["appSecret"] != "s3cr3t-value-123" {
+		t.Fatalf("masked secret not restored: %v", restored["appSecret"])
+	}
+	changed := reg.RestoreMaskedCredentials(channelType, map[string]any{"appSecret": "${secret:app}"}, stored)
+	if changed["appSecret"] != "${secret:app}" {
+		t.Fatalf("new value overwritten: %v", changed["appSecret"])
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/db/sqlc"
@@ -20,8 +21,10 @@ var ErrChannelConfigNotFound = errors.New("channel config not found")
 
 // Store provides CRUD operations for channel configurations, user bindings, and sessions.
 type Store struct {
-	queries  *sqlc.Queries
-	registry *Registry
+	queries     *sqlc.Queries
+	pool        *pgxpool.Pool
+	registry    *Registry
+	credentials CredentialResolver
 }
 
 // NewStore creates a Store backed by the given database queries and adapter registry.
@@ -32,7 +35,20 @@ func NewStore(queries *sqlc.Queries, registry *Registry) *Store {
 	return &Store{queries: queries, registry: registry}
 }
 
-// UpsertConfig creates or updates a bot's channel configuration.
+// SetPool enables transactions for writes that span several rows, such as
+// enqueueing the chunks of one reply.
+func (s *Store) SetPool(pool *pgxpool.Pool) {
+	s.pool = pool
+}
+
+// SetCredentialResolver configures how secret references in credentials are
+// resolved for self discovery. Stored credentials keep the references.
+func (s *Store) SetCredentialResolver(resolver CredentialResolver) {
+	s.credentials = resolver
+}
+
+// UpsertConfig creates or updates a bot's channel configuration. Secret
+// fields sent back in their masked form keep the stored value.
 func (s *Store) UpsertConfig(ctx context.Context, botID string, channelType ChannelType, req UpsertConfigRequest) (ChannelConfig, error) {
 	if s.queries == nil {
 		return ChannelConfig{}, fmt.Errorf("channel queries not configured")
@@ -40,7 +56,13 @@ func (s *Store) UpsertConfig(ctx context.Context, botID string, channelType Chan
 	if channelType == "" {
 		return ChannelConfig{}, fmt.Errorf("channel type is required")
 	}
-	normalized, err := s.registry.NormalizeConfig(channelType, req.Credentials)
+	credentials := req.Credentials
+	if existing, err := s.ResolveEffectiveConfig(ctx, botID, channelType); err == nil {
+		credentials = s.registry.RestoreMaskedCredentials(channelType, credentials, existing.Credentials)
+	} else if !errors.Is(err, ErrChannelConfigNotFound) {
+		return ChannelConfig{}, err
+	}
+	normalized, err := s.registry.NormalizeConfig(channelType, credentials)
 	if err != nil {
 		return ChannelConfig{}, err
 	}
@@ -57,7 +79,7 @@ func (s *Store) UpsertConfig(ctx context.Context, botID string, channelType Chan
 		selfIdentity = map[string]any{}
 	}
 	externalIdentity := strings.TrimSpace(req.ExternalIdentity)
-	if discovered, extID, err := s.registry.DiscoverSelf(ctx, channelType, normalized); err == nil && discovered != nil {
+	if discovered, extID, err := s.discoverSelf(ctx, botID, channelType, normalized); err == nil && discovered != nil {
 		for k, v := range discovered {
 			if _, exists := selfIdentity[k]; !exists {
 				selfIdentity[k] = v
@@ -107,6 +129,19 @@ func (s *Store) UpsertConfig(ctx context.Context, botID string, channelType Chan
 	return normalizeChannelConfigFromRow(row)
 }
 
+// discoverSelf asks the adapter for the bot's own identity using the
+// resolved credentials.
+func (s *Store) discoverSelf(ctx context.Context, botID string, channelType ChannelType, credentials map[string]any) (map[string]any, string, error) {
+	if s.credentials != nil {
+		resolved, err := s.credentials.ResolveCredentials(ctx, botID, credentials)
+		if err != nil {
+			return nil, "", err
+		}
+		credentials = resolved
+	}
+	return s.registry.DiscoverSelf(ctx, channelType, credentials)
+}
+
 // DeleteConfig removes a bot's channel configuration.
 func (s *Store) DeleteConfig(ctx context.Context, botID string, channelType ChannelType) error {
 	if s.queries == nil {
@@ -37,6 +37,10 @@ type Config struct {
 	Postgres     PostgresConfig     `toml:"postgres"`
 	Qdrant       QdrantConfig       `toml:"qdrant"`
 	AgentGateway AgentGatewayConfig `toml:"agent_gateway"`
+	Runs         RunsConfig         `toml:"runs"`
+	Memory       MemoryConfig       `toml:"memory"`
+	Secrets      SecretsConfig      `toml:"secrets"`
+	Channels     ChannelsConfig     `toml:"channels"`
 }
 
 type LogConfig struct {
@@ -45,7 +49,8 @@ type LogConfig struct {
 }
 
 type ServerConfig struct {
-	Addr string `toml:"addr"`
+	Addr      string `toml:"addr"`
+	PublicURL string `toml:"public_url"`
 }
 
 type AdminConfig struct {
@@ -71,6 +76,20 @@ type MCPConfig struct {
 	DataMount    string `toml:"data_mount"`
 	CNIBinaryDir string `toml:"cni_bin_dir"`
 	CNIConfigDir string `toml:"cni_conf_dir"`
+	// CatalogURLs lists remote MCP server registries merged into the
+	// built-in catalog.
+	CatalogURLs []string `toml:"catalog_urls"`
+	// ToolTimeoutSeconds bounds tool calls of tools and MCP connections
+	// that set no timeout of their own.
+	ToolTimeoutSeconds int `toml:"tool_timeout_seconds"`
+	// ToolTimeouts overrides the timeout of single tools, in seconds by
+	// tool name.
+	ToolTimeouts map[string]int `toml:"tool_timeouts"`
+	// MaxConcurrentToolCalls limits the tool calls one bot runs at once.
+	MaxConcurrentToolCalls int `toml:"max_concurrent_tool_calls"`
+	// ToolCallRetentionDays is how long tool calls stay in the audit log;
+	// 0 keeps them forever.
+	ToolCallRetentionDays int `toml:"tool_call_retention_days"`
 }
 
 type PostgresConfig struct {
@@ -94,6 +113,49 @@ type AgentGatewayConfig struct {
 	Port int    `toml:"port"`
 }
 
+// RunsConfig limits how many agent runs execute in parallel.
+type RunsConfig struct {
+	RouteConcurrency  int `toml:"route_concurrency"`
+	BotConcurrency    int `toml:"bot_concurrency"`
+	MaxQueuedPerRoute int `toml:"max_queued_per_route"`
+}
+
+// MemoryConfig configures memory maintenance.
+type MemoryConfig struct {
+	// CompactionUndoMinutes is how long an applied compaction can be undone.
+	CompactionUndoMinutes int `toml:"compaction_undo_minutes"`
+	// SweepIntervalMinutes is how often expired memories are removed; 0 disables the sweeper.
+	SweepIntervalMinutes int `toml:"sweep_interval_minutes"`
+	// StaleAfterDays is how long a memory may go unused before its importance decays.
+	StaleAfterDays int `toml:"stale_after_days"`
+}
+
+type SecretsConfig struct {
+	// MasterKey is the base64 encoded 32-byte key that wraps secret data keys.
+	MasterKey string `toml:"master_key"`
+	// MasterKeyFile reads the master key from a file instead.
+	MasterKeyFile string `toml:"master_key_file"`
+	// PreviousMasterKeys still decrypt data keys wrapped before a master key
+	// rotation; they are re-wrapped with the current key on startup.
+	PreviousMasterKeys []string `toml:"previous_master_keys"`
+}
+
+// ChannelsConfig configures inbound channel handling.
+type ChannelsConfig struct {
+	Debounce ChannelDebounceConfig `toml:"debounce"`
+}
+
+// ChannelDebounceConfig sets the default inbound debounce behaviour; channel
+// configs override it through their routing settings.
+type ChannelDebounceConfig struct {
+	// WindowMs is how long to wait for follow-up messages; 0 disables debouncing.
+	WindowMs int `toml:"window_ms"`
+	// MaxWaitMs bounds the total delay of a burst.
+	MaxWaitMs int `toml:"max_wait_ms"`
+	// CancelInFlight aborts a running reply when the same sender writes again.
+	CancelInFlight bool `toml:"cancel_in_flight"`
+}
+
 func (c AgentGatewayConfig) BaseURL() string {
 	host := c.Host
 	if host == "" {
@@ -128,11 +190,12 @@ func Load(path string) (Config, error) {
 			Namespace:  DefaultNamespace,
 		},
 		MCP: MCPConfig{
-			Image:        DefaultMCPImage,
-			DataRoot:     DefaultDataRoot,
-			DataMount:    DefaultDataMount,
-			CNIBinaryDir: DefaultCNIBinaryDir,
-			CNIConfigDir: DefaultCNIConfigDir,
+			Image:                 DefaultMCPImage,
+			DataRoot:              DefaultDataRoot,
+			DataMount:             DefaultDataMount,
+			CNIBinaryDir:          DefaultCNIBinaryDir,
+			CNIConfigDir:          DefaultCNIConfigDir,
+			ToolCallRetentionDays: 30,
 		},
 		Postgres: PostgresConfig{
 			Host:     DefaultPGHost,
@@ -149,6 +212,14 @@ func Load(path string) (Config, error) {
 			Host: "127.0.0.1",
 			Port: 8081,
 		},
+		Runs: RunsConfig{
+			RouteConcurrency: 1,
+		},
+		Memory: MemoryConfig{
+			CompactionUndoMinutes: 60,
+			SweepIntervalMinutes:  60,
+			StaleAfterDays:        30,
+		},
 	}
 
 	if path == "" {
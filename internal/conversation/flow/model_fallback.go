@@ -0,0 +1,328 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/db/sqlc"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+// failoverPolicy controls retries of one model before the next model in the
+// fallback chain is tried.
+type failoverPolicy struct {
+	// MaxRetries is the number of extra attempts on a transient error.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var defaultFailoverPolicy = failoverPolicy{
+	MaxRetries:     1,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     4 * time.Second,
+}
+
+func (p failoverPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 0; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// modelErrorClass tells the failover loop how to react to a failed attempt.
+type modelErrorClass int
+
+const (
+	// modelErrorFatal aborts the request; another model would fail the same way.
+	modelErrorFatal modelErrorClass = iota
+	// modelErrorTransient is retried with backoff, then fails over.
+	modelErrorTransient
+	// modelErrorUnavailable fails over immediately (bad credentials, unknown model).
+	modelErrorUnavailable
+)
+
+// gatewayError is a failure reported by the agent gateway, either as an HTTP
+// error response or as an "error" event before any output was streamed.
+type gatewayError struct {
+	// Status is the HTTP status of the agent gateway response.
+	Status  int
+	Message string
+	// UpstreamStatus and Code describe the failed model provider call: its
+	// HTTP status and its error code, or the network error code.
+	UpstreamStatus int
+	Code           string
+}
+
+func (e *gatewayError) Error() string {
+	return "agent gateway error: " + e.Message
+}
+
+// newGatewayError builds the error of a failed gateway response, reading
+// the upstream status and code from the JSON error body when present.
+func newGatewayError(status int, body []byte) *gatewayError {
+	gwErr := &gatewayError{Status: status, Message: strings.TrimSpace(string(body))}
+	var parsed struct {
+		UpstreamStatus int    `json:"upstreamStatus"`
+		UpstreamCode   string `json:"upstreamCode"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		gwErr.UpstreamStatus = parsed.UpstreamStatus
+		gwErr.Code = parsed.UpstreamCode
+	}
+	return gwErr
+}
+
+// streamCommittedError is a stream failure after output was forwarded to the
+// client. Retrying would append a second answer to the partial first one.
+type streamCommittedError struct {
+	err error
+}
+
+func (e *streamCommittedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *streamCommittedError) Unwrap() error {
+	return e.err
+}
+
+// upstreamErrorCodes classifies provider error codes and types and network
+// error codes. Codes take precedence over the upstream status, e.g. for
+// quota errors that providers report as 429.
+var upstreamErrorCodes = map[string]modelErrorClass{
+	"rate_limit_exceeded":        modelErrorTransient,
+	"rate_limit_error":           modelErrorTransient,
+	"overloaded_error":           modelErrorTransient,
+	"server_error":               modelErrorTransient,
+	"api_error":                  modelErrorTransient,
+	"service_unavailable":        modelErrorTransient,
+	"econnreset":                 modelErrorTransient,
+	"econnrefused":               modelErrorTransient,
+	"etimedout":                  modelErrorTransient,
+	"epipe":                      modelErrorTransient,
+	"und_err_socket":             modelErrorTransient,
+	"und_err_connect_timeout":    modelErrorTransient,
+	"und_err_headers_timeout":    modelErrorTransient,
+	"und_err_body_timeout":       modelErrorTransient,
+	"insufficient_quota":         modelErrorUnavailable,
+	"billing_hard_limit_reached": modelErrorUnavailable,
+	"invalid_api_key":            modelErrorUnavailable,
+	"authentication_error":       modelErrorUnavailable,
+	"permission_error":           modelErrorUnavailable,
+	"permission_denied":          modelErrorUnavailable,
+	"model_not_found":            modelErrorUnavailable,
+	"not_found_error":            modelErrorUnavailable,
+	"enotfound":                  modelErrorUnavailable,
+	"context_length_exceeded":    modelErrorFatal,
+}
+
+// classifyModelError decides whether err may be solved by retrying or by
+// switching to another model.
+func classifyModelError(ctx context.Context, err error) modelErrorClass {
+	if err == nil || ctx.Err() != nil {
+		return modelErrorFatal
+	}
+	var committedErr *streamCommittedError
+	if errors.As(err, &committedErr) {
+		return modelErrorFatal
+	}
+	var gwErr *gatewayError
+	if errors.As(err, &gwErr) {
+		return gwErr.class()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return modelErrorTransient
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return modelErrorTransient
+	}
+	return modelErrorFatal
+}
+
+func (e *gatewayError) class() modelErrorClass {
+	if e.UpstreamStatus != 0 || e.Code != "" {
+		if class, ok := upstreamErrorCodes[strings.ToLower(strings.TrimSpace(e.Code))]; ok {
+			return class
+		}
+		return classifyUpstreamStatus(e.UpstreamStatus)
+	}
+	// Without upstream details only an unreachable gateway is worth retrying;
+	// other statuses are the gateway rejecting the request itself.
+	switch e.Status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return modelErrorTransient
+	}
+	return modelErrorFatal
+}
+
+func classifyUpstreamStatus(status int) modelErrorClass {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout, 529:
+		return modelErrorTransient
+	case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden, http.StatusNotFound:
+		return modelErrorUnavailable
+	}
+	return modelErrorFatal
+}
+
+// modelAttribution identifies the model that produced a reply.
+type modelAttribution struct {
+	ModelID    string
+	ProviderID string
+	ClientType string
+	// Fallback is the position in the fallback chain; 0 is the primary model.
+	Fallback int
+}
+
+func newModelAttribution(model models.GetResponse, fallback int) modelAttribution {
+	return modelAttribution{
+		ModelID:    model.ModelID,
+		ProviderID: model.LlmProviderID,
+		ClientType: string(model.ClientType),
+		Fallback:   fallback,
+	}
+}
+
+// annotateUsage adds the answering model to a usage object so message usage
+// records which model actually replied.
+func annotateUsage(usage json.RawMessage, model modelAttribution) json.RawMessage {
+	if model.ModelID == "" || len(usage) == 0 || isJSONNull(usage) {
+		return usage
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(usage, &fields); err != nil || fields == nil {
+		return usage
+	}
+	fields["modelId"] = model.ModelID
+	if model.ProviderID != "" {
+		fields["providerId"] = model.ProviderID
+	}
+	if model.ClientType != "" {
+		fields["clientType"] = model.ClientType
+	}
+	if model.Fallback > 0 {
+		fields["fallbackIndex"] = model.Fallback
+	}
+	annotated, err := json.Marshal(fields)
+	if err != nil {
+		return usage
+	}
+	return annotated
+}
+
+// modelCandidate is one entry of a resolved fallback chain.
+type modelCandidate struct {
+	model    models.GetResponse
+	provider sqlc.LlmProvider
+}
+
+// resolveFallbackModels loads the bot's fallback chain, skipping the primary
+// model and entries that cannot be resolved.
+func (r *Resolver) resolveFallbackModels(ctx context.Context, botSettings settings.Settings, primary models.GetResponse) []modelCandidate {
+	if r.modelsService == nil || len(botSettings.FallbackModelIDs) == 0 {
+		return nil
+	}
+	candidates := make([]modelCandidate, 0, len(botSettings.FallbackModelIDs))
+	for _, modelID := range botSettings.FallbackModelIDs {
+		modelID = strings.TrimSpace(modelID)
+		if modelID == "" || modelID == primary.ModelID {
+			continue
+		}
+		model, provider, err := r.fetchChatModel(ctx, modelID)
+		if err != nil {
+			r.logger.Warn("skip fallback model", slog.String("model_id", modelID), slog.Any("error", err))
+			continue
+		}
+		candidates = append(candidates, modelCandidate{model: model, provider: provider})
+	}
+	return candidates
+}
+
+// payloadFor rebuilds the gateway payload for the fallback model at index
+// (1-based); attachments are re-routed for the model's input modalities.
+func (r *Resolver) payloadFor(ctx context.Context, req conversation.ChatRequest, rc resolvedContext, index int) gatewayRequest {
+	if index <= 0 || index > len(rc.fallbacks) {
+		return rc.payload
+	}
+	candidate := rc.fallbacks[index-1]
+	payload := rc.payload
+	payload.Model = gatewayModelConfig{
+		ModelID:    candidate.model.ModelID,
+		ClientType: string(candidate.model.ClientType),
+		Input:      candidate.model.InputModalities,
+		APIKey:     candidate.provider.ApiKey,
+		BaseURL:    candidate.provider.BaseUrl,
+	}
+	if len(req.Attachments) > 0 {
+		payload.Attachments = r.routeAndMergeAttachments(ctx, candidate.model, req)
+		payload.Query = r.formatQuery(req, rc.displayName, payload.Attachments)
+	}
+	payload.attribution = newModelAttribution(candidate.model, index)
+	return payload
+}
+
+// runWithFailover calls attempt with the primary model and, on provider
+// errors, retries with backoff and then walks the bot's fallback chain.
+func (r *Resolver) runWithFailover(ctx context.Context, req conversation.ChatRequest, rc resolvedContext, attempt func(payload gatewayRequest) error) error {
+	policy := r.failover
+	var lastErr error
+	for index := 0; index <= len(rc.fallbacks); index++ {
+		payload := r.payloadFor(ctx, req, rc, index)
+		for try := 0; ; try++ {
+			err := attempt(payload)
+			if err == nil {
+				if index > 0 {
+					r.logger.Info("model fallback answered",
+						slog.String("bot_id", req.BotID),
+						slog.String("model_id", payload.Model.ModelID),
+						slog.Int("fallback_index", index),
+					)
+				}
+				return nil
+			}
+			lastErr = err
+			class := classifyModelError(ctx, err)
+			if class == modelErrorFatal {
+				return err
+			}
+			r.logger.Warn("model attempt failed",
+				slog.String("bot_id", req.BotID),
+				slog.String("model_id", payload.Model.ModelID),
+				slog.Int("fallback_index", index),
+				slog.Int("attempt", try+1),
+				slog.Any("error", err),
+			)
+			if class != modelErrorTransient || try >= policy.MaxRetries {
+				break
+			}
+			timer := time.NewTimer(policy.backoff(try))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return context.Cause(ctx)
+			case <-timer.C:
+			}
+		}
+	}
+	if len(rc.fallbacks) > 0 {
+		return fmt.Errorf("all %d models failed: %w", len(rc.fallbacks)+1, lastErr)
+	}
+	return lastErr
+}
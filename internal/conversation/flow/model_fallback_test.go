@@ -0,0 +1,211 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/models"
+)
+
+func TestClassifyModelError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cases := []struct {
+		err  error
+		want modelErrorClass
+	}{
+		{newGatewayError(502, []byte(`{"error":"Too Many Requests","upstreamStatus":429}`)), modelErrorTransient},
+		{&gatewayError{Message: "Overloaded", UpstreamStatus: 529}, modelErrorTransient},
+		{&gatewayError{Message: "fetch failed", Code: "ECONNRESET"}, modelErrorTransient},
+		{&gatewayError{Message: "quota exceeded", UpstreamStatus: 429, Code: "insufficient_quota"}, modelErrorUnavailable},
+		{&gatewayError{Message: "Incorrect API key provided", UpstreamStatus: 401}, modelErrorUnavailable},
+		{&gatewayError{Message: "model not found", UpstreamStatus: 404}, modelErrorUnavailable},
+		{&gatewayError{Message: "maximum context length is 5000 tokens", UpstreamStatus: 400}, modelErrorFatal},
+		{&gatewayError{Status: 500, Message: "error 500: request 404 failed"}, modelErrorFatal},
+		{&gatewayError{Status: 503, Message: "gateway restarting"}, modelErrorTransient},
+		{&gatewayError{Status: 400, Message: "Validation failed"}, modelErrorFatal},
+		{&gatewayError{Status: 401, Message: "Invalid or expired token"}, modelErrorFatal},
+		{&streamCommittedError{err: context.DeadlineExceeded}, modelErrorFatal},
+		{context.DeadlineExceeded, modelErrorTransient},
+		{errors.New("boom"), modelErrorFatal},
+	}
+	for _, tc := range cases {
+		if got := classifyModelError(ctx, tc.err); got != tc.want {
+			t.Fatalf("classifyModelError(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := classifyModelError(cancelled, &gatewayError{UpstreamStatus: 503}); got != modelErrorFatal {
+		t.Fatalf("expected cancelled context to be fatal, got %d", got)
+	}
+}
+
+func TestAnnotateUsage(t *testing.T) {
+	t.Parallel()
+
+	raw := annotateUsage(json.RawMessage(`{"inputTokens":10,"outputTokens":5}`), modelAttribution{
+		ModelID:    "gpt-4o",
+		ProviderID: "prov-1",
+		ClientType: "openai-completions",
+		Fallback:   1,
+	})
+	var usage map[string]any
+	if err := json.Unmarshal(raw, &usage); err != nil {
+		t.Fatalf("unmarshal usage: %v", err)
+	}
+	if usage["modelId"] != "gpt-4o" || usage["providerId"] != "prov-1" || usage["fallbackIndex"] != float64(1) {
+		t.Fatalf("unexpected annotated usage: %v", usage)
+	}
+	if usage["inputTokens"] != float64(10) {
+		t.Fatalf("expected token counts to be kept: %v", usage)
+	}
+	if got := annotateUsage(nil, modelAttribution{ModelID: "gpt-4o"}); got != nil {
+		t.Fatalf("expected missing usage to stay empty, got %s", string(got))
+	}
+}
+
+func newFallbackTestContext() resolvedContext {
+	primary := models.GetResponse{ModelID: "primary"}
+	primary.ClientType = models.ClientType("anthropic-messages")
+	fallback := models.GetResponse{ModelID: "fallback"}
+	fallback.ClientType = models.ClientType("openai-completions")
+	return resolvedContext{
+		payload: gatewayRequest{
+			Model:       gatewayModelConfig{ModelID: primary.ModelID, ClientType: string(primary.ClientType)},
+			attribution: newModelAttribution(primary, 0),
+		},
+		model:     primary,
+		fallbacks: []modelCandidate{{model: fallback}},
+	}
+}
+
+func TestRunWithFailover(t *testing.T) {
+	t.Parallel()
+
+	r := &Resolver{
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		failover: failoverPolicy{MaxRetries: 1},
+	}
+	rc := newFallbackTestContext()
+
+	var attempts []string
+	err := r.runWithFailover(context.Background(), conversation.ChatRequest{BotID: "bot-1"}, rc, func(payload gatewayRequest) error {
+		attempts = append(attempts, payload.Model.ModelID)
+		if payload.Model.ModelID == "primary" {
+			return &gatewayError{Status: 502, Message: "Service Unavailable", UpstreamStatus: 503}
+		}
+		if payload.attribution.Fallback != 1 {
+			t.Fatalf("expected fallback attribution, got %+v", payload.attribution)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected fallback to answer, got %v", err)
+	}
+	if strings.Join(attempts, ",") != "primary,primary,fallback" {
+		t.Fatalf("unexpected attempts: %v", attempts)
+	}
+
+	attempts = nil
+	err = r.runWithFailover(context.Background(), conversation.ChatRequest{BotID: "bot-1"}, rc, func(payload gatewayRequest) error {
+		attempts = append(attempts, payload.Model.ModelID)
+		return &gatewayError{Status: 400, Message: "Validation failed"}
+	})
+	if err == nil || len(attempts) != 1 {
+		t.Fatalf("expected fatal error without failover, got %v after %v", err, attempts)
+	}
+}
+
+func TestStreamChat_FailsOverBeforeOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model gatewayModelConfig `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"type\":\"agent_start\"}\n\n")
+		if body.Model.ModelID == "primary" {
+			_, _ = io.WriteString(w, "data: {\"type\":\"error\",\"message\":\"rate limit exceeded\",\"status\":429}\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, "data: {\"type\":\"text_delta\",\"delta\":\"hi\"}\n\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	r := &Resolver{
+		gatewayBaseURL:  srv.URL,
+		streamingClient: srv.Client(),
+		logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	rc := newFallbackTestContext()
+	req := conversation.ChatRequest{BotID: "bot-1"}
+
+	chunkCh := make(chan conversation.StreamChunk, 10)
+	err := r.runWithFailover(context.Background(), req, rc, func(payload gatewayRequest) error {
+		return r.streamChat(context.Background(), payload, req, chunkCh)
+	})
+	if err != nil {
+		t.Fatalf("expected stream to fail over, got %v", err)
+	}
+	close(chunkCh)
+	var events []string
+	for chunk := range chunkCh {
+		eventType, _ := streamEventType(chunk)
+		events = append(events, eventType)
+	}
+	if strings.Join(events, ",") != "agent_start,text_delta" {
+		t.Fatalf("unexpected forwarded events: %v", events)
+	}
+}
+
+func TestStreamChat_DoesNotFailOverAfterOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"type\":\"text_delta\",\"delta\":\"partial\"}\n\n")
+		w.(http.Flusher).Flush()
+		// Drop the connection mid-stream.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	r := &Resolver{
+		gatewayBaseURL:  srv.URL,
+		streamingClient: srv.Client(),
+		logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		failover:        failoverPolicy{MaxRetries: 1},
+	}
+	rc := newFallbackTestContext()
+	req := conversation.ChatRequest{BotID: "bot-1"}
+
+	chunkCh := make(chan conversation.StreamChunk, 10)
+	attempts := 0
+	err := r.runWithFailover(context.Background(), req, rc, func(payload gatewayRequest) error {
+		attempts++
+		return r.streamChat(context.Background(), payload, req, chunkCh)
+	})
+	var committedErr *streamCommittedError
+	if !errors.As(err, &committedErr) {
+		t.Fatalf("expected committed stream error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected no retry after output was forwarded, got %d attempts", attempts)
+	}
+}
@@ -79,6 +79,8 @@ type Resolver struct {
 	inboxService    *inbox.Service
 	skillLoader     SkillLoader
 	assetLoader     gatewayAssetLoader
+	runQueue        *RunQueue
+	failover        failoverPolicy
 	gatewayBaseURL  string
 	timeout         time.Duration
 	logger          *slog.Logger
@@ -112,6 +114,7 @@ func NewResolver(
 		conversationSvc: conversationSvc,
 		messageService:  messageService,
 		settingsService: settingsService,
+		failover:        defaultFailoverPolicy,
 		gatewayBaseURL:  gatewayBaseURL,
 		timeout:         timeout,
 		logger:          log.With(slog.String("service", "conversation_resolver")),
@@ -137,6 +140,41 @@ func (r *Resolver) SetInboxService(service *inbox.Service) {
 	r.inboxService = service
 }
 
+// SetRunQueue configures the per-route run queue that serializes agent runs.
+func (r *Resolver) SetRunQueue(queue *RunQueue) {
+	r.runQueue = queue
+}
+
+// acquireRun waits for a run slot on the request's route. It returns a nil
+// ticket when no run queue is configured.
+func (r *Resolver) acquireRun(ctx context.Context, req conversation.ChatRequest) (*RunTicket, error) {
+	if r.runQueue == nil {
+		return nil, nil
+	}
+	routeKey := strings.TrimSpace(req.RouteID)
+	if routeKey == "" {
+		routeKey = strings.TrimSpace(req.ChatID)
+	}
+	var onQueued, onDequeued func(RunInfo)
+	if req.OnRunQueued != nil {
+		onQueued = func(run RunInfo) {
+			r.logger.Info("agent run queued",
+				slog.String("bot_id", req.BotID),
+				slog.String("route_id", routeKey),
+				slog.String("run_id", run.ID),
+				slog.Int("position", run.Position),
+			)
+			req.OnRunQueued(run.ID, run.Position)
+		}
+	}
+	if req.OnRunDequeued != nil {
+		onDequeued = func(run RunInfo) {
+			req.OnRunDequeued(run.ID)
+		}
+	}
+	return r.runQueue.Acquire(ctx, req.BotID, routeKey, req.ChatID, req.SourceChannelIdentityID, req.Query, onQueued, onDequeued)
+}
+
 // --- gateway payload ---
 
 type gatewayModelConfig struct {
@@ -184,6 +222,9 @@ type gatewayRequest struct {
 	Identity          gatewayIdentity             `json:"identity"`
 	Attachments       []any                       `json:"attachments"`
 	Inbox             []gatewayInboxItem          `json:"inbox,omitempty"`
+
+	// attribution identifies Model for usage records; it is not sent.
+	attribution modelAttribution
 }
 
 type gatewayResponse struct {
@@ -239,6 +280,8 @@ type resolvedContext struct {
 	payload      gatewayRequest
 	model        models.GetResponse
 	provider     sqlc.LlmProvider
+	fallbacks    []modelCandidate
+	displayName  string
 	inboxItemIDs []string
 }
 
@@ -274,6 +317,7 @@ func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (r
 		return resolvedContext{}, err
 	}
 	clientType := string(chatModel.ClientType)
+	fallbacks := r.resolveFallbackModels(ctx, botSettings, chatModel)
 
 	maxCtx := coalescePositiveInt(req.MaxContextLoadTime, botSettings.MaxContextLoadTime, defaultMaxContextMinutes)
 	maxTokens := botSettings.MaxContextTokens
@@ -380,16 +424,7 @@ func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (r
 
 	attachments := r.routeAndMergeAttachments(ctx, chatModel, req)
 	displayName := r.resolveDisplayName(ctx, req)
-
-	headerifiedQuery := FormatUserHeader(
-		strings.TrimSpace(req.SourceChannelIdentityID),
-		displayName,
-		req.CurrentChannel,
-		strings.TrimSpace(req.ConversationType),
-		strings.TrimSpace(req.ConversationName),
-		extractFileRefPaths(attachments),
-		req.Query,
-	)
+	headerifiedQuery := r.formatQuery(req, displayName, attachments)
 
 	payload := gatewayRequest{
 		Model: gatewayModelConfig{
@@ -418,33 +453,69 @@ func (r *Resolver) resolve(ctx context.Context, req conversation.ChatRequest) (r
 		},
 		Attachments: attachments,
 		Inbox:       inboxGatewayItems,
+		attribution: newModelAttribution(chatModel, 0),
 	}
 
-	return resolvedContext{payload: payload, model: chatModel, provider: provider, inboxItemIDs: inboxItemIDs}, nil
+	return resolvedContext{
+		payload:      payload,
+		model:        chatModel,
+		provider:     provider,
+		fallbacks:    fallbacks,
+		displayName:  displayName,
+		inboxItemIDs: inboxItemIDs,
+	}, nil
+}
+
+// formatQuery prefixes the user query with the sender header seen by the model.
+func (r *Resolver) formatQuery(req conversation.ChatRequest, displayName string, attachments []any) string {
+	return FormatUserHeader(
+		strings.TrimSpace(req.SourceChannelIdentityID),
+		displayName,
+		req.CurrentChannel,
+		strings.TrimSpace(req.ConversationType),
+		strings.TrimSpace(req.ConversationName),
+		extractFileRefPaths(attachments),
+		req.Query,
+	)
 }
 
 // --- Chat ---
 
 // Chat sends a synchronous chat request to the agent gateway and stores the result.
 func (r *Resolver) Chat(ctx context.Context, req conversation.ChatRequest) (conversation.ChatResponse, error) {
+	ticket, err := r.acquireRun(ctx, req)
+	if err != nil {
+		return conversation.ChatResponse{}, err
+	}
+	defer ticket.Release()
+	ctx = ticket.Context(ctx)
 	rc, err := r.resolve(ctx, req)
 	if err != nil {
 		return conversation.ChatResponse{}, err
 	}
-	req.Query = rc.payload.Query
-	resp, err := r.postChat(ctx, rc.payload, req.Token)
+	var (
+		resp     gatewayResponse
+		answered gatewayRequest
+	)
+	err = r.runWithFailover(ctx, req, rc, func(payload gatewayRequest) error {
+		var postErr error
+		resp, postErr = r.postChat(ctx, payload, req.Token)
+		answered = payload
+		return postErr
+	})
 	if err != nil {
 		return conversation.ChatResponse{}, err
 	}
-	if err := r.storeRound(ctx, req, resp.Messages, resp.Usage, resp.Usages); err != nil {
+	req.Query = answered.Query
+	if err := r.storeRound(ctx, req, answered.attribution, resp.Messages, resp.Usage, resp.Usages); err != nil {
 		return conversation.ChatResponse{}, err
 	}
 	r.markInboxRead(ctx, req.BotID, rc.inboxItemIDs)
 	return conversation.ChatResponse{
 		Messages: resp.Messages,
 		Skills:   resp.Skills,
-		Model:    rc.model.ModelID,
-		Provider: string(rc.model.ClientType),
+		Model:    answered.Model.ModelID,
+		Provider: answered.Model.ClientType,
 	}, nil
 }
 
@@ -466,32 +537,44 @@ func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload sc
 		UserID: payload.OwnerUserID,
 		Token:  token,
 	}
-	rc, err := r.resolve(ctx, req)
+	ticket, err := r.acquireRun(ctx, req)
 	if err != nil {
 		return err
 	}
-
-	schedulePayload := rc.payload
-	schedulePayload.Identity.ChannelIdentityID = strings.TrimSpace(payload.OwnerUserID)
-	schedulePayload.Identity.DisplayName = "Scheduler"
-
-	triggerReq := triggerScheduleRequest{
-		gatewayRequest: schedulePayload,
-		Schedule: gatewaySchedule{
-			ID:          payload.ID,
-			Name:        payload.Name,
-			Description: payload.Description,
-			Pattern:     payload.Pattern,
-			MaxCalls:    payload.MaxCalls,
-			Command:     payload.Command,
-		},
+	defer ticket.Release()
+	ctx = ticket.Context(ctx)
+	rc, err := r.resolve(ctx, req)
+	if err != nil {
+		return err
 	}
 
-	resp, err := r.postTriggerSchedule(ctx, triggerReq, token)
+	var (
+		resp     gatewayResponse
+		answered modelAttribution
+	)
+	err = r.runWithFailover(ctx, req, rc, func(schedulePayload gatewayRequest) error {
+		schedulePayload.Identity.ChannelIdentityID = strings.TrimSpace(payload.OwnerUserID)
+		schedulePayload.Identity.DisplayName = "Scheduler"
+		triggerReq := triggerScheduleRequest{
+			gatewayRequest: schedulePayload,
+			Schedule: gatewaySchedule{
+				ID:          payload.ID,
+				Name:        payload.Name,
+				Description: payload.Description,
+				Pattern:     payload.Pattern,
+				MaxCalls:    payload.MaxCalls,
+				Command:     payload.Command,
+			},
+		}
+		var postErr error
+		resp, postErr = r.postTriggerSchedule(ctx, triggerReq, token)
+		answered = schedulePayload.attribution
+		return postErr
+	})
 	if err != nil {
 		return err
 	}
-	return r.storeRound(ctx, req, resp.Messages, resp.Usage, resp.Usages)
+	return r.storeRound(ctx, req, answered, resp.Messages, resp.Usage, resp.Usages)
 }
 
 // --- StreamChat ---
@@ -510,6 +593,18 @@ func (r *Resolver) StreamChat(ctx context.Context, req conversation.ChatRequest)
 		defer close(errCh)
 
 		streamReq := req
+		ticket, err := r.acquireRun(ctx, streamReq)
+		if err != nil {
+			r.logger.Warn("gateway stream run not started",
+				slog.String("bot_id", streamReq.BotID),
+				slog.String("chat_id", streamReq.ChatID),
+				slog.Any("error", err),
+			)
+			errCh <- err
+			return
+		}
+		defer ticket.Release()
+		ctx := ticket.Context(ctx)
 		rc, err := r.resolve(ctx, streamReq)
 		if err != nil {
 			r.logger.Error("gateway stream resolve failed",
@@ -533,7 +628,24 @@ func (r *Resolver) StreamChat(ctx context.Context, req conversation.ChatRequest)
 			}
 			streamReq.UserMessagePersisted = true
 		}
-		if err := r.streamChat(ctx, rc.payload, streamReq, chunkCh); err != nil {
+		progress := &streamProgress{}
+		err = r.runWithFailover(ctx, streamReq, rc, func(payload gatewayRequest) error {
+			attemptReq := streamReq
+			attemptReq.Query = payload.Query
+			return r.streamChatWithProgress(ctx, payload, attemptReq, chunkCh, progress)
+		})
+		if err != nil {
+			if errors.Is(context.Cause(ctx), ErrRunCancelled) {
+				r.logger.Info("gateway stream cancelled",
+					slog.String("bot_id", streamReq.BotID),
+					slog.String("chat_id", streamReq.ChatID),
+				)
+				if !progress.stored {
+					r.storeCancelledPartial(context.WithoutCancel(ctx), streamReq, progress.text.String())
+				}
+				errCh <- ErrRunCancelled
+				return
+			}
 			r.logger.Error("gateway stream request failed",
 				slog.String("bot_id", streamReq.BotID),
 				slog.String("chat_id", streamReq.ChatID),
@@ -578,7 +690,7 @@ func (r *Resolver) postChat(ctx context.Context, payload gatewayRequest, token s
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		r.logger.Error("gateway error", slog.String("url", url), slog.Int("status", resp.StatusCode), slog.String("body_prefix", truncate(string(respBody), 300)))
-		return gatewayResponse{}, fmt.Errorf("agent gateway error: %s", strings.TrimSpace(string(respBody)))
+		return gatewayResponse{}, newGatewayError(resp.StatusCode, respBody)
 	}
 
 	var parsed gatewayResponse
@@ -614,7 +726,7 @@ func (r *Resolver) postTriggerSchedule(ctx context.Context, payload triggerSched
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		r.logger.Error("gateway trigger-schedule error", slog.String("url", url), slog.Int("status", resp.StatusCode), slog.String("body_prefix", truncate(string(respBody), 300)))
-		return gatewayResponse{}, fmt.Errorf("agent gateway error: %s", strings.TrimSpace(string(respBody)))
+		return gatewayResponse{}, newGatewayError(resp.StatusCode, respBody)
 	}
 
 	var parsed gatewayResponse
@@ -625,7 +737,31 @@ func (r *Resolver) postTriggerSchedule(ctx context.Context, payload triggerSched
 	return parsed, nil
 }
 
+// streamProgress records what a stream produced so a cancelled run can keep its
+// partial output.
+type streamProgress struct {
+	stored bool
+	text   strings.Builder
+}
+
+func (p *streamProgress) observe(data []byte) {
+	if p == nil {
+		return
+	}
+	var event struct {
+		Type  string `json:"type"`
+		Delta string `json:"delta"`
+	}
+	if err := json.Unmarshal(data, &event); err == nil && event.Type == "text_delta" {
+		p.text.WriteString(event.Delta)
+	}
+}
+
 func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, req conversation.ChatRequest, chunkCh chan<- conversation.StreamChunk) error {
+	return r.streamChatWithProgress(ctx, payload, req, chunkCh, nil)
+}
+
+func (r *Resolver) streamChatWithProgress(ctx context.Context, payload gatewayRequest, req conversation.ChatRequest, chunkCh chan<- conversation.StreamChunk, progress *streamProgress) (err error) {
 	url := r.gatewayBaseURL + "/chat/stream"
 	r.logger.Info(
 		"gateway stream request",
@@ -652,11 +788,29 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, req c
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		errBody, _ := io.ReadAll(resp.Body)
 		r.logger.Error("gateway stream error", slog.String("url", url), slog.Int("status", resp.StatusCode), slog.String("body_prefix", truncate(string(errBody), 300)))
-		return fmt.Errorf("agent gateway error: %s", strings.TrimSpace(string(errBody)))
+		return newGatewayError(resp.StatusCode, errBody)
 	}
 
 	stored := false
 	var dataBuf bytes.Buffer
+	// Lifecycle events are held back until the model produces output so a
+	// failed attempt can fail over without the client seeing a second start.
+	var held [][]byte
+	committed := false
+	defer func() {
+		if err != nil && committed {
+			err = &streamCommittedError{err: err}
+		}
+	}()
+
+	forward := func(out []byte) error {
+		select {
+		case chunkCh <- conversation.StreamChunk(out):
+			return nil
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
 
 	flushEvent := func() error {
 		if dataBuf.Len() == 0 {
@@ -667,17 +821,39 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, req c
 		if len(out) == 0 || bytes.Equal(bytes.TrimSpace(out), []byte("[DONE]")) {
 			return nil
 		}
+		if !committed {
+			eventType, gwErr := streamEventType(out)
+			switch eventType {
+			case "agent_start", "text_start", "reasoning_start":
+				held = append(held, out)
+				return nil
+			case "error":
+				if classifyModelError(ctx, gwErr) != modelErrorFatal {
+					return gwErr
+				}
+			}
+			committed = true
+			for _, event := range held {
+				if err := forward(event); err != nil {
+					return err
+				}
+			}
+			held = nil
+		}
 		// Persist final messages before forwarding the "done"/"agent_end" event so the
 		// next user turn can immediately see the assistant output in history.
 		if !stored {
-			if handled, storeErr := r.tryStoreStream(ctx, req, out); storeErr != nil {
+			if handled, storeErr := r.tryStoreStream(ctx, req, payload.attribution, out); storeErr != nil {
 				return storeErr
 			} else if handled {
 				stored = true
+				if progress != nil {
+					progress.stored = true
+				}
 			}
 		}
-		chunkCh <- conversation.StreamChunk(out)
-		return nil
+		progress.observe(out)
+		return forward(out)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
@@ -709,6 +885,9 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, req c
 		_, _ = dataBuf.Write(part)
 	}
 	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
 		if errors.Is(err, bufio.ErrTooLong) {
 			return fmt.Errorf("sse line too long (max %d bytes)", gatewaySSEMaxLineBytes)
 		}
@@ -717,6 +896,24 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, req c
 	return flushEvent()
 }
 
+// streamEventType returns the type of a gateway stream event and, for error
+// events, the reported failure.
+func streamEventType(data []byte) (string, *gatewayError) {
+	var event struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Status  int    `json:"status"`
+		Code    string `json:"code"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", nil
+	}
+	if event.Type != "error" {
+		return event.Type, nil
+	}
+	return event.Type, &gatewayError{Message: event.Message, UpstreamStatus: event.Status, Code: event.Code}
+}
+
 func newJSONRequestWithContext(ctx context.Context, method, url string, payload any) (*http.Request, error) {
 	pr, pw := io.Pipe()
 	go func() {
@@ -733,7 +930,7 @@ func newJSONRequestWithContext(ctx context.Context, method, url string, payload
 }
 
 // tryStoreStream attempts to extract final messages from a stream event and persist them.
-func (r *Resolver) tryStoreStream(ctx context.Context, req conversation.ChatRequest, data []byte) (bool, error) {
+func (r *Resolver) tryStoreStream(ctx context.Context, req conversation.ChatRequest, model modelAttribution, data []byte) (bool, error) {
 	// data: {"type":"text_delta"|"agent_end"|"done", ...}
 	var envelope struct {
 		Type     string                      `json:"type"`
@@ -744,12 +941,12 @@ func (r *Resolver) tryStoreStream(ctx context.Context, req conversation.ChatRequ
 	}
 	if err := json.Unmarshal(data, &envelope); err == nil {
 		if (envelope.Type == "agent_end" || envelope.Type == "done") && len(envelope.Messages) > 0 {
-			return true, r.storeRound(ctx, req, envelope.Messages, envelope.Usage, envelope.Usages)
+			return true, r.storeRound(ctx, req, model, envelope.Messages, envelope.Usage, envelope.Usages)
 		}
 		if envelope.Type == "done" && len(envelope.Data) > 0 {
 			var resp gatewayResponse
 			if err := json.Unmarshal(envelope.Data, &resp); err == nil && len(resp.Messages) > 0 {
-				return true, r.storeRound(ctx, req, resp.Messages, resp.Usage, resp.Usages)
+				return true, r.storeRound(ctx, req, model, resp.Messages, resp.Usage, resp.Usages)
 			}
 		}
 	}
@@ -757,7 +954,7 @@ func (r *Resolver) tryStoreStream(ctx context.Context, req conversation.ChatRequ
 	// fallback: data: {messages: [...]}
 	var resp gatewayResponse
 	if err := json.Unmarshal(data, &resp); err == nil && len(resp.Messages) > 0 {
-		return true, r.storeRound(ctx, req, resp.Messages, resp.Usage, resp.Usages)
+		return true, r.storeRound(ctx, req, model, resp.Messages, resp.Usage, resp.Usages)
 	}
 	return false, nil
 }
@@ -1238,7 +1435,7 @@ func (r *Resolver) persistUserMessage(ctx context.Context, req conversation.Chat
 	return err
 }
 
-func (r *Resolver) storeRound(ctx context.Context, req conversation.ChatRequest, messages []conversation.ModelMessage, usage json.RawMessage, usages []json.RawMessage) error {
+func (r *Resolver) storeRound(ctx context.Context, req conversation.ChatRequest, model modelAttribution, messages []conversation.ModelMessage, usage json.RawMessage, usages []json.RawMessage) error {
 	fullRound := make([]conversation.ModelMessage, 0, len(messages))
 	roundUsages := make([]json.RawMessage, 0, len(usages))
 	for i, m := range messages {
@@ -1254,12 +1451,17 @@ func (r *Resolver) storeRound(ctx context.Context, req conversation.ChatRequest,
 		return nil
 	}
 
-	r.storeMessages(ctx, req, fullRound, usage, roundUsages)
-	go r.storeMemory(context.WithoutCancel(ctx), req.BotID, fullRound)
+	r.storeMessages(ctx, req, model, fullRound, usage, roundUsages)
+	memoryCtx := memory.WithHistoryActor(context.WithoutCancel(ctx), memory.HistoryActor{
+		Type:      memory.HistoryActorChat,
+		ID:        req.SourceChannelIdentityID,
+		MessageID: req.ExternalMessageID,
+	})
+	go r.storeMemory(memoryCtx, req.BotID, fullRound)
 	return nil
 }
 
-func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatRequest, messages []conversation.ModelMessage, usage json.RawMessage, usages []json.RawMessage) {
+func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatRequest, model modelAttribution, messages []conversation.ModelMessage, usage json.RawMessage, usages []json.RawMessage) {
 	if r.messageService == nil {
 		return
 	}
@@ -1314,6 +1516,9 @@ func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatReque
 		} else if i == len(messages)-1 && len(usage) > 0 {
 			msgUsage = usage
 		}
+		if msg.Role == "assistant" {
+			msgUsage = annotateUsage(msgUsage, model)
+		}
 		if _, err := r.messageService.Persist(ctx, messagepkg.PersistInput{
 			BotID:                   req.BotID,
 			RouteID:                 req.RouteID,
@@ -1333,6 +1538,42 @@ func (r *Resolver) storeMessages(ctx context.Context, req conversation.ChatReque
 	}
 }
 
+// storeCancelledPartial persists the text streamed before a run was cancelled as
+// an assistant message marked with metadata.cancelled.
+func (r *Resolver) storeCancelledPartial(ctx context.Context, req conversation.ChatRequest, partial string) {
+	if r.messageService == nil || strings.TrimSpace(req.BotID) == "" {
+		return
+	}
+	partial = strings.TrimSpace(partial)
+	if partial == "" {
+		return
+	}
+	content, err := json.Marshal(conversation.ModelMessage{
+		Role:    "assistant",
+		Content: conversation.NewTextContent(partial),
+	})
+	if err != nil {
+		r.logger.Warn("storeCancelledPartial: marshal failed", slog.Any("error", err))
+		return
+	}
+	meta := buildRouteMetadata(req)
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta["cancelled"] = true
+	if _, err := r.messageService.Persist(ctx, messagepkg.PersistInput{
+		BotID:                  req.BotID,
+		RouteID:                req.RouteID,
+		Platform:               req.CurrentChannel,
+		SourceReplyToMessageID: req.ExternalMessageID,
+		Role:                   "assistant",
+		Content:                content,
+		Metadata:               meta,
+	}); err != nil {
+		r.logger.Warn("persist cancelled partial failed", slog.Any("error", err))
+	}
+}
+
 func isJSONNull(data json.RawMessage) bool {
 	return len(data) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null"))
 }
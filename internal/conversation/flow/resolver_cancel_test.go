@@ -0,0 +1,58 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/memohai/memoh/internal/conversation"
+	messagepkg "github.com/memohai/memoh/internal/message"
+)
+
+type recordingMessageService struct {
+	blockingMessageService
+	persisted []messagepkg.PersistInput
+}
+
+func (s *recordingMessageService) Persist(ctx context.Context, input messagepkg.PersistInput) (messagepkg.Message, error) {
+	s.persisted = append(s.persisted, input)
+	return messagepkg.Message{}, nil
+}
+
+func TestStoreCancelledPartial(t *testing.T) {
+	t.Parallel()
+
+	msgSvc := &recordingMessageService{}
+	r := &Resolver{
+		messageService: msgSvc,
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	progress := &streamProgress{}
+	progress.observe([]byte(`{"type":"text_delta","delta":"Hello, "}`))
+	progress.observe([]byte(`{"type":"tool_call_start","toolName":"search"}`))
+	progress.observe([]byte(`{"type":"text_delta","delta":"wor"}`))
+
+	req := conversation.ChatRequest{BotID: "bot-1", RouteID: "route-1", CurrentChannel: "telegram"}
+	r.storeCancelledPartial(context.Background(), req, progress.text.String())
+	if len(msgSvc.persisted) != 1 {
+		t.Fatalf("expected one persisted message, got %d", len(msgSvc.persisted))
+	}
+	input := msgSvc.persisted[0]
+	if input.Role != "assistant" || input.RouteID != "route-1" {
+		t.Fatalf("unexpected persist input: %+v", input)
+	}
+	if cancelled, _ := input.Metadata["cancelled"].(bool); !cancelled {
+		t.Fatalf("expected cancelled marker, got %v", input.Metadata)
+	}
+	var stored conversation.ModelMessage
+	if err := json.Unmarshal(input.Content, &stored); err != nil || stored.TextContent() != "Hello, wor" {
+		t.Fatalf("expected partial text in content, got %s", string(input.Content))
+	}
+
+	r.storeCancelledPartial(context.Background(), req, "  ")
+	if len(msgSvc.persisted) != 1 {
+		t.Fatalf("empty partial output should not be persisted")
+	}
+}
@@ -0,0 +1,365 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRunQueueFull is returned when a route already has the maximum number of waiting runs.
+	ErrRunQueueFull = errors.New("run queue is full")
+	// ErrRunCancelled is the error (and context cause) of a run cancelled while queued or running.
+	ErrRunCancelled = errors.New("run cancelled")
+	// ErrRunNotFound indicates the run does not exist or belongs to another bot.
+	ErrRunNotFound = errors.New("run not found")
+	// ErrRunNotCancellable indicates the run already started without a cancel handle.
+	ErrRunNotCancellable = errors.New("run cannot be cancelled")
+)
+
+// RunStatus is the lifecycle state of an agent run in the queue.
+type RunStatus string
+
+const (
+	RunStatusQueued  RunStatus = "queued"
+	RunStatusRunning RunStatus = "running"
+)
+
+// RunQueueConfig bounds how many agent runs may execute at once.
+type RunQueueConfig struct {
+	// RouteConcurrency is the number of parallel runs per route. Defaults to 1.
+	RouteConcurrency int
+	// BotConcurrency is the number of parallel runs per bot. Zero means unlimited.
+	BotConcurrency int
+	// MaxQueuedPerRoute caps waiting runs per route. Zero means unlimited.
+	MaxQueuedPerRoute int
+}
+
+// RunInfo describes a queued or running agent run.
+type RunInfo struct {
+	ID         string    `json:"id"`
+	BotID      string    `json:"bot_id"`
+	RouteID    string    `json:"route_id,omitempty"`
+	ChatID     string    `json:"chat_id,omitempty"`
+	SenderID   string    `json:"sender_id,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	Status     RunStatus `json:"status"`
+	Position   int       `json:"position,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+}
+
+type runEntry struct {
+	info     RunInfo
+	routeKey string
+	ready    chan struct{}
+	dropped  chan struct{}
+	cancel   context.CancelCauseFunc
+}
+
+// RunQueue serializes agent runs per route (and optionally per bot) so that
+// persisted history stays in order. It is in-process only.
+type RunQueue struct {
+	mu           sync.Mutex
+	cfg          RunQueueConfig
+	waiting      []*runEntry
+	runs         map[string]*runEntry
+	routeRunning map[string]int
+	botRunning   map[string]int
+}
+
+// NewRunQueue creates a RunQueue with the given limits.
+func NewRunQueue(cfg RunQueueConfig) *RunQueue {
+	if cfg.RouteConcurrency <= 0 {
+		cfg.RouteConcurrency = 1
+	}
+	if cfg.BotConcurrency < 0 {
+		cfg.BotConcurrency = 0
+	}
+	if cfg.MaxQueuedPerRoute < 0 {
+		cfg.MaxQueuedPerRoute = 0
+	}
+	return &RunQueue{
+		cfg:          cfg,
+		runs:         map[string]*runEntry{},
+		routeRunning: map[string]int{},
+		botRunning:   map[string]int{},
+	}
+}
+
+// RunTicket is held by a running agent run and must be released when it ends.
+type RunTicket struct {
+	queue *RunQueue
+	entry *runEntry
+	once  sync.Once
+}
+
+// ID returns the run ID.
+func (t *RunTicket) ID() string {
+	if t == nil || t.entry == nil {
+		return ""
+	}
+	return t.entry.info.ID
+}
+
+// Context derives the run context from parent and registers its cancel handle so
+// the run can be stopped through Cancel or CancelRoute. A nil ticket returns parent.
+func (t *RunTicket) Context(parent context.Context) context.Context {
+	if t == nil || t.queue == nil {
+		return parent
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	t.queue.mu.Lock()
+	t.entry.cancel = cancel
+	t.queue.mu.Unlock()
+	return ctx
+}
+
+// Release frees the run's slot and starts the next waiting run, if any.
+func (t *RunTicket) Release() {
+	if t == nil || t.queue == nil {
+		return
+	}
+	t.once.Do(func() {
+		t.queue.release(t.entry)
+	})
+}
+
+// Acquire waits until req may run. onQueued is invoked (once, before waiting)
+// when the run cannot start immediately; onDequeued is invoked when a queued run
+// leaves the queue for any reason.
+func (q *RunQueue) Acquire(ctx context.Context, botID, routeKey, chatID, senderID, query string, onQueued func(RunInfo), onDequeued func(RunInfo)) (*RunTicket, error) {
+	entry := &runEntry{
+		info: RunInfo{
+			ID:         uuid.NewString(),
+			BotID:      strings.TrimSpace(botID),
+			RouteID:    strings.TrimSpace(routeKey),
+			ChatID:     strings.TrimSpace(chatID),
+			SenderID:   strings.TrimSpace(senderID),
+			Query:      truncateRunQuery(query),
+			Status:     RunStatusQueued,
+			EnqueuedAt: time.Now().UTC(),
+		},
+		routeKey: strings.TrimSpace(botID) + ":" + strings.TrimSpace(routeKey),
+		ready:    make(chan struct{}),
+		dropped:  make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	if q.cfg.MaxQueuedPerRoute > 0 && q.queuedOnRouteLocked(entry.routeKey) >= q.cfg.MaxQueuedPerRoute && !q.canStartLocked(entry) {
+		q.mu.Unlock()
+		return nil, ErrRunQueueFull
+	}
+	q.runs[entry.info.ID] = entry
+	q.waiting = append(q.waiting, entry)
+	q.dispatchLocked()
+	started := entry.info.Status == RunStatusRunning
+	queued := entry.info
+	if !started {
+		queued.Position = q.positionLocked(entry)
+	}
+	q.mu.Unlock()
+
+	ticket := &RunTicket{queue: q, entry: entry}
+	if started {
+		return ticket, nil
+	}
+	if onQueued != nil {
+		onQueued(queued)
+	}
+	defer func() {
+		if onDequeued != nil {
+			onDequeued(queued)
+		}
+	}()
+	select {
+	case <-entry.ready:
+		return ticket, nil
+	case <-entry.dropped:
+		return nil, ErrRunCancelled
+	case <-ctx.Done():
+		q.mu.Lock()
+		if entry.info.Status == RunStatusRunning {
+			// Started concurrently with cancellation; hand the slot back.
+			q.mu.Unlock()
+			ticket.Release()
+			return nil, ctx.Err()
+		}
+		q.removeWaitingLocked(entry)
+		q.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel removes a queued run of the given bot or stops it if it is running.
+func (q *RunQueue) Cancel(botID, runID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.runs[strings.TrimSpace(runID)]
+	if !ok || entry.info.BotID != strings.TrimSpace(botID) {
+		return ErrRunNotFound
+	}
+	if !q.cancelLocked(entry) {
+		return ErrRunNotCancellable
+	}
+	return nil
+}
+
+// CancelRoute stops the running and queued runs on a route and returns how
+// many runs were cancelled. A non-empty senderID limits it to the runs that
+// sender started.
+func (q *RunQueue) CancelRoute(botID, routeKey, senderID string) int {
+	key := strings.TrimSpace(botID) + ":" + strings.TrimSpace(routeKey)
+	senderID = strings.TrimSpace(senderID)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	targets := make([]*runEntry, 0)
+	for _, entry := range q.runs {
+		if entry.routeKey == key && (senderID == "" || entry.info.SenderID == senderID) {
+			targets = append(targets, entry)
+		}
+	}
+	cancelled := 0
+	for _, entry := range targets {
+		if q.cancelLocked(entry) {
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+func (q *RunQueue) cancelLocked(entry *runEntry) bool {
+	if entry.info.Status == RunStatusQueued {
+		q.removeWaitingLocked(entry)
+		close(entry.dropped)
+		return true
+	}
+	if entry.cancel == nil {
+		return false
+	}
+	entry.cancel(ErrRunCancelled)
+	return true
+}
+
+// List returns the queued and running runs of a bot in queue order.
+func (q *RunQueue) List(botID string) []RunInfo {
+	botID = strings.TrimSpace(botID)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := make([]RunInfo, 0)
+	for _, entry := range q.runs {
+		if entry.info.BotID != botID || entry.info.Status != RunStatusRunning {
+			continue
+		}
+		items = append(items, entry.info)
+	}
+	for _, entry := range q.waiting {
+		if entry.info.BotID != botID {
+			continue
+		}
+		info := entry.info
+		info.Position = q.positionLocked(entry)
+		items = append(items, info)
+	}
+	return items
+}
+
+func (q *RunQueue) release(entry *runEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.runs[entry.info.ID]; !ok {
+		return
+	}
+	delete(q.runs, entry.info.ID)
+	if entry.cancel != nil {
+		entry.cancel(nil)
+	}
+	if q.routeRunning[entry.routeKey]--; q.routeRunning[entry.routeKey] <= 0 {
+		delete(q.routeRunning, entry.routeKey)
+	}
+	if q.botRunning[entry.info.BotID]--; q.botRunning[entry.info.BotID] <= 0 {
+		delete(q.botRunning, entry.info.BotID)
+	}
+	q.dispatchLocked()
+}
+
+// dispatchLocked starts waiting runs in FIFO order while limits allow. Runs on
+// the same route keep their order because a blocked route blocks all of its
+// later entries as well.
+func (q *RunQueue) dispatchLocked() {
+	remaining := q.waiting[:0]
+	for _, entry := range q.waiting {
+		if !q.canStartLocked(entry) {
+			remaining = append(remaining, entry)
+			continue
+		}
+		q.routeRunning[entry.routeKey]++
+		q.botRunning[entry.info.BotID]++
+		entry.info.Status = RunStatusRunning
+		entry.info.StartedAt = time.Now().UTC()
+		close(entry.ready)
+	}
+	for i := len(remaining); i < len(q.waiting); i++ {
+		q.waiting[i] = nil
+	}
+	q.waiting = remaining
+}
+
+func (q *RunQueue) canStartLocked(entry *runEntry) bool {
+	if q.routeRunning[entry.routeKey] >= q.cfg.RouteConcurrency {
+		return false
+	}
+	if q.cfg.BotConcurrency > 0 && q.botRunning[entry.info.BotID] >= q.cfg.BotConcurrency {
+		return false
+	}
+	return true
+}
+
+func (q *RunQueue) removeWaitingLocked(entry *runEntry) {
+	delete(q.runs, entry.info.ID)
+	for i, item := range q.waiting {
+		if item == entry {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *RunQueue) queuedOnRouteLocked(routeKey string) int {
+	count := 0
+	for _, entry := range q.waiting {
+		if entry.routeKey == routeKey {
+			count++
+		}
+	}
+	return count
+}
+
+// positionLocked returns the 1-based position of entry among waiting runs of its route.
+func (q *RunQueue) positionLocked(entry *runEntry) int {
+	position := 0
+	for _, item := range q.waiting {
+		if item.routeKey != entry.routeKey {
+			continue
+		}
+		position++
+		if item == entry {
+			return position
+		}
+	}
+	return 0
+}
+
+func truncateRunQuery(query string) string {
+	query = strings.TrimSpace(query)
+	const maxRunes = 120
+	runes := []rune(query)
+	if len(runes) <= maxRunes {
+		return query
+	}
+	return string(runes[:maxRunes]) + "..."
+}
@@ -0,0 +1,208 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func acquireAsync(q *RunQueue, ctx context.Context, botID, route string, queued chan<- RunInfo) (<-chan *RunTicket, <-chan error) {
+	tickets := make(chan *RunTicket, 1)
+	errs := make(chan error, 1)
+	go func() {
+		ticket, err := q.Acquire(ctx, botID, route, "", "", "q", func(run RunInfo) {
+			if queued != nil {
+				queued <- run
+			}
+		}, nil)
+		if err != nil {
+			errs <- err
+			return
+		}
+		tickets <- ticket
+	}()
+	return tickets, errs
+}
+
+func TestRunQueueSerializesRoute(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{})
+	first, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "first", nil, nil)
+	if err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+
+	queued := make(chan RunInfo, 1)
+	tickets, _ := acquireAsync(q, context.Background(), "bot-1", "route-1", queued)
+	select {
+	case run := <-queued:
+		if run.Position != 1 || run.Status != RunStatusQueued {
+			t.Fatalf("unexpected queued run: %+v", run)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected second run to be queued")
+	}
+
+	other, err := q.Acquire(context.Background(), "bot-1", "route-2", "", "", "other", nil, nil)
+	if err != nil {
+		t.Fatalf("other route should not wait: %v", err)
+	}
+	other.Release()
+
+	runs := q.List("bot-1")
+	if len(runs) != 2 || runs[0].Status != RunStatusRunning || runs[1].Status != RunStatusQueued {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+
+	first.Release()
+	select {
+	case ticket := <-tickets:
+		ticket.Release()
+	case <-time.After(time.Second):
+		t.Fatalf("second run did not start after release")
+	}
+	if runs := q.List("bot-1"); len(runs) != 0 {
+		t.Fatalf("expected empty queue, got %+v", runs)
+	}
+}
+
+func TestRunQueueBotConcurrency(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{RouteConcurrency: 2, BotConcurrency: 1})
+	first, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	queued := make(chan RunInfo, 1)
+	tickets, _ := acquireAsync(q, context.Background(), "bot-1", "route-2", queued)
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatalf("expected bot limit to queue the run")
+	}
+	first.Release()
+	select {
+	case ticket := <-tickets:
+		ticket.Release()
+	case <-time.After(time.Second):
+		t.Fatalf("queued run did not start")
+	}
+}
+
+func TestRunQueueCancelQueued(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{})
+	first, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer first.Release()
+
+	queued := make(chan RunInfo, 1)
+	_, errs := acquireAsync(q, context.Background(), "bot-1", "route-1", queued)
+	run := <-queued
+
+	if err := q.Cancel("bot-2", run.ID); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected not found for other bot, got %v", err)
+	}
+	if err := q.Cancel("bot-1", first.ID()); !errors.Is(err, ErrRunNotCancellable) {
+		t.Fatalf("expected unbound running run to be rejected, got %v", err)
+	}
+	if err := q.Cancel("bot-1", run.ID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrRunCancelled) {
+			t.Fatalf("expected ErrRunCancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("cancelled run did not return")
+	}
+}
+
+func TestRunQueueContextAndLimit(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{MaxQueuedPerRoute: 1})
+	first, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer first.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan RunInfo, 1)
+	_, errs := acquireAsync(q, ctx, "bot-1", "route-1", queued)
+	<-queued
+
+	if _, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "", nil, nil); !errors.Is(err, ErrRunQueueFull) {
+		t.Fatalf("expected ErrRunQueueFull, got %v", err)
+	}
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if runs := q.List("bot-1"); len(runs) != 1 {
+		t.Fatalf("expected only the running run, got %+v", runs)
+	}
+}
+
+func TestRunQueueCancelRunning(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{})
+	ticket, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer ticket.Release()
+	ctx := ticket.Context(context.Background())
+
+	queued := make(chan RunInfo, 1)
+	_, errs := acquireAsync(q, context.Background(), "bot-1", "route-1", queued)
+	<-queued
+
+	if got := q.CancelRoute("bot-1", "route-1", ""); got != 2 {
+		t.Fatalf("expected 2 cancelled runs, got %d", got)
+	}
+	if !errors.Is(context.Cause(ctx), ErrRunCancelled) {
+		t.Fatalf("expected running context to be cancelled, got %v", context.Cause(ctx))
+	}
+	if err := <-errs; !errors.Is(err, ErrRunCancelled) {
+		t.Fatalf("expected queued run to be cancelled, got %v", err)
+	}
+}
+
+func TestRunQueueCancelRouteBySender(t *testing.T) {
+	t.Parallel()
+
+	q := NewRunQueue(RunQueueConfig{RouteConcurrency: 2})
+	mine, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "user-1", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer mine.Release()
+	theirs, err := q.Acquire(context.Background(), "bot-1", "route-1", "", "user-2", "", nil, nil)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer theirs.Release()
+	mineCtx := mine.Context(context.Background())
+	theirsCtx := theirs.Context(context.Background())
+
+	if got := q.CancelRoute("bot-1", "route-1", "user-1"); got != 1 {
+		t.Fatalf("expected only the sender's run to be cancelled, got %d", got)
+	}
+	if !errors.Is(context.Cause(mineCtx), ErrRunCancelled) {
+		t.Fatalf("expected the sender's run to be cancelled, got %v", context.Cause(mineCtx))
+	}
+	if theirsCtx.Err() != nil {
+		t.Fatal("expected other senders' runs to keep running")
+	}
+}
@@ -234,6 +234,10 @@ type ChatRequest struct {
 	// OutboundAssetCollector returns asset refs accumulated during outbound streaming.
 	// Set by the inbound channel processor; called by the resolver at persist time.
 	OutboundAssetCollector func() []OutboundAssetRef `json:"-"`
+	// OnRunQueued is called when the run has to wait behind other runs on its
+	// route. OnRunDequeued follows once it leaves the queue (started or cancelled).
+	OnRunQueued   func(runID string, position int) `json:"-"`
+	OnRunDequeued func(runID string)               `json:"-"`
 
 	Query              string           `json:"query"`
 	Model              string           `json:"model,omitempty"`
@@ -289,6 +289,147 @@ func (q *Queries) ListMessages(ctx context.Context, botID pgtype.UUID) ([]ListMe
 	return items, nil
 }
 
+const listMessagesAround = `-- name: ListMessagesAround :many
+SELECT
+  around.id,
+  around.bot_id,
+  around.route_id,
+  around.sender_channel_identity_id,
+  around.sender_user_id,
+  around.platform,
+  around.external_message_id,
+  around.source_reply_to_message_id,
+  around.role,
+  around.content,
+  around.metadata,
+  around.usage,
+  around.created_at,
+  around.sender_display_name,
+  around.sender_avatar_url
+FROM (
+  (
+    SELECT
+      m.id,
+      m.bot_id,
+      m.route_id,
+      m.sender_channel_identity_id,
+      m.sender_account_user_id AS sender_user_id,
+      m.channel_type AS platform,
+      m.source_message_id AS external_message_id,
+      m.source_reply_to_message_id,
+      m.role,
+      m.content,
+      m.metadata,
+      m.usage,
+      m.created_at,
+      ci.display_name AS sender_display_name,
+      ci.avatar_url AS sender_avatar_url
+    FROM bot_history_messages m
+    LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id
+    WHERE m.bot_id = $1
+      AND ($2::uuid IS NULL OR m.route_id = $2::uuid)
+      AND m.created_at < $3
+    ORDER BY m.created_at DESC
+    LIMIT $4
+  )
+  UNION ALL
+  (
+    SELECT
+      m.id,
+      m.bot_id,
+      m.route_id,
+      m.sender_channel_identity_id,
+      m.sender_account_user_id AS sender_user_id,
+      m.channel_type AS platform,
+      m.source_message_id AS external_message_id,
+      m.source_reply_to_message_id,
+      m.role,
+      m.content,
+      m.metadata,
+      m.usage,
+      m.created_at,
+      ci.display_name AS sender_display_name,
+      ci.avatar_url AS sender_avatar_url
+    FROM bot_history_messages m
+    LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id
+    WHERE m.bot_id = $1
+      AND ($2::uuid IS NULL OR m.route_id = $2::uuid)
+      AND m.created_at >= $3
+    ORDER BY m.created_at ASC
+    LIMIT $5
+  )
+) AS around
+ORDER BY around.created_at ASC
+`
+
+type ListMessagesAroundParams struct {
+	BotID       pgtype.UUID        `json:"bot_id"`
+	RouteID     pgtype.UUID        `json:"route_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	BeforeCount int32              `json:"before_count"`
+	AfterCount  int32              `json:"after_count"`
+}
+
+type ListMessagesAroundRow struct {
+	ID                      pgtype.UUID        `json:"id"`
+	BotID                   pgtype.UUID        `json:"bot_id"`
+	RouteID                 pgtype.UUID        `json:"route_id"`
+	SenderChannelIdentityID pgtype.UUID        `json:"sender_channel_identity_id"`
+	SenderUserID            pgtype.UUID        `json:"sender_user_id"`
+	Platform                pgtype.Text        `json:"platform"`
+	ExternalMessageID       pgtype.Text        `json:"external_message_id"`
+	SourceReplyToMessageID  pgtype.Text        `json:"source_reply_to_message_id"`
+	Role                    string             `json:"role"`
+	Content                 []byte             `json:"content"`
+	Metadata                []byte             `json:"metadata"`
+	Usage                   []byte             `json:"usage"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	SenderDisplayName       pgtype.Text        `json:"sender_display_name"`
+	SenderAvatarUrl         pgtype.Text        `json:"sender_avatar_url"`
+}
+
+func (q *Queries) ListMessagesAround(ctx context.Context, arg ListMessagesAroundParams) ([]ListMessagesAroundRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesAround,
+		arg.BotID,
+		arg.RouteID,
+		arg.CreatedAt,
+		arg.BeforeCount,
+		arg.AfterCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMessagesAroundRow
+	for rows.Next() {
+		var i ListMessagesAroundRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BotID,
+			&i.RouteID,
+			&i.SenderChannelIdentityID,
+			&i.SenderUserID,
+			&i.Platform,
+			&i.ExternalMessageID,
+			&i.SourceReplyToMessageID,
+			&i.Role,
+			&i.Content,
+			&i.Metadata,
+			&i.Usage,
+			&i.CreatedAt,
+			&i.SenderDisplayName,
+			&i.SenderAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMessagesBefore = `-- name: ListMessagesBefore :many
 SELECT
   m.id,
@@ -8,6 +8,27 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Bm25CorpusStat struct {
+	BotID       string             `json:"bot_id"`
+	Lang        string             `json:"lang"`
+	DocCount    int64              `json:"doc_count"`
+	TotalDocLen int64              `json:"total_doc_len"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Bm25TermStat struct {
+	BotID   string `json:"bot_id"`
+	Lang    string `json:"lang"`
+	Term    string `json:"term"`
+	DocFreq int32  `json:"doc_freq"`
+}
+
+type Bm25Warmup struct {
+	BotID    string             `json:"bot_id"`
+	Lang     string             `json:"lang"`
+	WarmedAt pgtype.Timestamptz `json:"warmed_at"`
+}
+
 type Bot struct {
 	ID                 pgtype.UUID        `json:"id"`
 	OwnerUserID        pgtype.UUID        `json:"owner_user_id"`
@@ -25,6 +46,7 @@ type Bot struct {
 	MemoryModelID      pgtype.UUID        `json:"memory_model_id"`
 	EmbeddingModelID   pgtype.UUID        `json:"embedding_model_id"`
 	SearchProviderID   pgtype.UUID        `json:"search_provider_id"`
+	RerankModelID      pgtype.UUID        `json:"rerank_model_id"`
 	Metadata           []byte             `json:"metadata"`
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
@@ -94,6 +116,19 @@ type BotInbox struct {
 	ReadAt    pgtype.Timestamptz `json:"read_at"`
 }
 
+type BotMcpToken struct {
+	ID             pgtype.UUID        `json:"id"`
+	BotID          pgtype.UUID        `json:"bot_id"`
+	Name           string             `json:"name"`
+	TokenHash      string             `json:"token_hash"`
+	TokenPrefix    string             `json:"token_prefix"`
+	Scopes         []string           `json:"scopes"`
+	IssuedByUserID pgtype.UUID        `json:"issued_by_user_id"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt     pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
 type BotMember struct {
 	BotID     pgtype.UUID        `json:"bot_id"`
 	UserID    pgtype.UUID        `json:"user_id"`
@@ -101,6 +136,20 @@ type BotMember struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type BotMemoryVector struct {
+	BotID      pgtype.UUID        `json:"bot_id"`
+	VectorName string             `json:"vector_name"`
+	Dimensions int32              `json:"dimensions"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotModelFallback struct {
+	BotID     pgtype.UUID        `json:"bot_id"`
+	ModelID   pgtype.UUID        `json:"model_id"`
+	Priority  int32              `json:"priority"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type BotPreauthKey struct {
 	ID             pgtype.UUID        `json:"id"`
 	BotID          pgtype.UUID        `json:"bot_id"`
@@ -111,6 +160,27 @@ type BotPreauthKey struct {
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
+type BotSearchProviderFallback struct {
+	BotID            pgtype.UUID        `json:"bot_id"`
+	SearchProviderID pgtype.UUID        `json:"search_provider_id"`
+	Priority         int32              `json:"priority"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type BotSecret struct {
+	ID          pgtype.UUID        `json:"id"`
+	BotID       pgtype.UUID        `json:"bot_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Ciphertext  []byte             `json:"ciphertext"`
+	WrappedKey  []byte             `json:"wrapped_key"`
+	KeyID       string             `json:"key_id"`
+	Version     int32              `json:"version"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	RotatedAt   pgtype.Timestamptz `json:"rotated_at"`
+}
+
 type BotStorageBinding struct {
 	ID                pgtype.UUID        `json:"id"`
 	BotID             pgtype.UUID        `json:"bot_id"`
@@ -120,6 +190,18 @@ type BotStorageBinding struct {
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
 }
 
+type BotToolPolicy struct {
+	ID                pgtype.UUID        `json:"id"`
+	BotID             pgtype.UUID        `json:"bot_id"`
+	Tool              string             `json:"tool"`
+	Enabled           bool               `json:"enabled"`
+	ConversationTypes []string           `json:"conversation_types"`
+	Roles             []string           `json:"roles"`
+	Arguments         []byte             `json:"arguments"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
 type ChannelIdentity struct {
 	ID               pgtype.UUID        `json:"id"`
 	UserID           pgtype.UUID        `json:"user_id"`
@@ -143,6 +225,25 @@ type ChannelIdentityBindCode struct {
 	CreatedAt               pgtype.Timestamptz `json:"created_at"`
 }
 
+type ChannelOutboundQueue struct {
+	ID            pgtype.UUID        `json:"id"`
+	BotID         pgtype.UUID        `json:"bot_id"`
+	ChannelType   string             `json:"channel_type"`
+	ConfigID      string             `json:"config_id"`
+	Target        string             `json:"target"`
+	Message       []byte             `json:"message"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	MaxAttempts   int32              `json:"max_attempts"`
+	LastError     string             `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LockedUntil   pgtype.Timestamptz `json:"locked_until"`
+	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	Deferrals     int32              `json:"deferrals"`
+}
+
 type Container struct {
 	ID            pgtype.UUID        `json:"id"`
 	BotID         pgtype.UUID        `json:"bot_id"`
@@ -197,6 +298,38 @@ type McpConnection struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type McpOauthPending struct {
+	State        string             `json:"state"`
+	BotID        pgtype.UUID        `json:"bot_id"`
+	ConnectionID pgtype.UUID        `json:"connection_id"`
+	Resource     string             `json:"resource"`
+	Verifier     string             `json:"verifier"`
+	RedirectUri  string             `json:"redirect_uri"`
+	ReturnUrl    string             `json:"return_url"`
+	Server       []byte             `json:"server"`
+	ClientID     string             `json:"client_id"`
+	ClientSecret string             `json:"client_secret"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type McpOauthToken struct {
+	ConnectionID  pgtype.UUID        `json:"connection_id"`
+	BotID         pgtype.UUID        `json:"bot_id"`
+	Resource      string             `json:"resource"`
+	Issuer        string             `json:"issuer"`
+	TokenEndpoint string             `json:"token_endpoint"`
+	ClientID      string             `json:"client_id"`
+	ClientSecret  string             `json:"client_secret"`
+	AccessToken   string             `json:"access_token"`
+	RefreshToken  string             `json:"refresh_token"`
+	TokenType     string             `json:"token_type"`
+	Scope         string             `json:"scope"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
 type MediaAsset struct {
 	ID                pgtype.UUID        `json:"id"`
 	BotID             pgtype.UUID        `json:"bot_id"`
@@ -214,6 +347,49 @@ type MediaAsset struct {
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 }
 
+type MemoryCompaction struct {
+	ID          pgtype.UUID        `json:"id"`
+	BotID       pgtype.UUID        `json:"bot_id"`
+	Scope       string             `json:"scope"`
+	Status      string             `json:"status"`
+	Plan        []byte             `json:"plan"`
+	Filters     []byte             `json:"filters"`
+	Original    []byte             `json:"original"`
+	Created     []byte             `json:"created"`
+	LockedUntil pgtype.Timestamptz `json:"locked_until"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MemoryHistory struct {
+	ID        pgtype.UUID        `json:"id"`
+	MemoryID  string             `json:"memory_id"`
+	BotID     pgtype.UUID        `json:"bot_id"`
+	Event     string             `json:"event"`
+	OldMemory string             `json:"old_memory"`
+	NewMemory string             `json:"new_memory"`
+	Scope     []byte             `json:"scope"`
+	ActorType string             `json:"actor_type"`
+	ActorID   string             `json:"actor_id"`
+	MessageID string             `json:"message_id"`
+	Metadata  []byte             `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type MemoryReembedJob struct {
+	ID          pgtype.UUID        `json:"id"`
+	BotID       string             `json:"bot_id"`
+	Status      string             `json:"status"`
+	Migrates    bool               `json:"migrates"`
+	Job         []byte             `json:"job"`
+	HeartbeatAt pgtype.Timestamptz `json:"heartbeat_at"`
+	FinishedAt  pgtype.Timestamptz `json:"finished_at"`
+	CleanedAt   pgtype.Timestamptz `json:"cleaned_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Model struct {
 	ID              pgtype.UUID        `json:"id"`
 	ModelID         string             `json:"model_id"`
@@ -294,6 +470,23 @@ type Subagent struct {
 	Usage       []byte             `json:"usage"`
 }
 
+type ToolCall struct {
+	ID                pgtype.UUID        `json:"id"`
+	BotID             pgtype.UUID        `json:"bot_id"`
+	ChatID            string             `json:"chat_id"`
+	ChannelIdentityID string             `json:"channel_identity_id"`
+	Tool              string             `json:"tool"`
+	Source            string             `json:"source"`
+	ConnectionID      pgtype.UUID        `json:"connection_id"`
+	ConnectionName    string             `json:"connection_name"`
+	Arguments         []byte             `json:"arguments"`
+	ArgumentsHash     string             `json:"arguments_hash"`
+	Status            string             `json:"status"`
+	Error             string             `json:"error"`
+	DurationMs        int32              `json:"duration_ms"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
 	ID           pgtype.UUID        `json:"id"`
 	Username     pgtype.Text        `json:"username"`
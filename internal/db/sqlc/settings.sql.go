@@ -22,6 +22,7 @@ SET max_context_load_time = 1440,
     memory_model_id = NULL,
     embedding_model_id = NULL,
     search_provider_id = NULL,
+    rerank_model_id = NULL,
     updated_at = now()
 WHERE id = $1
 `
@@ -42,12 +43,14 @@ SELECT
   chat_models.model_id AS chat_model_id,
   memory_models.model_id AS memory_model_id,
   embedding_models.model_id AS embedding_model_id,
-  search_providers.id AS search_provider_id
+  search_providers.id AS search_provider_id,
+  rerank_models.model_id AS rerank_model_id
 FROM bots
 LEFT JOIN models AS chat_models ON chat_models.id = bots.chat_model_id
 LEFT JOIN models AS memory_models ON memory_models.id = bots.memory_model_id
 LEFT JOIN models AS embedding_models ON embedding_models.id = bots.embedding_model_id
 LEFT JOIN search_providers ON search_providers.id = bots.search_provider_id
+LEFT JOIN models AS rerank_models ON rerank_models.id = bots.rerank_model_id
 WHERE bots.id = $1
 `
 
@@ -62,6 +65,7 @@ type GetSettingsByBotIDRow struct {
 	MemoryModelID      pgtype.Text `json:"memory_model_id"`
 	EmbeddingModelID   pgtype.Text `json:"embedding_model_id"`
 	SearchProviderID   pgtype.UUID `json:"search_provider_id"`
+	RerankModelID      pgtype.Text `json:"rerank_model_id"`
 }
 
 func (q *Queries) GetSettingsByBotID(ctx context.Context, id pgtype.UUID) (GetSettingsByBotIDRow, error) {
@@ -78,6 +82,7 @@ func (q *Queries) GetSettingsByBotID(ctx context.Context, id pgtype.UUID) (GetSe
 		&i.MemoryModelID,
 		&i.EmbeddingModelID,
 		&i.SearchProviderID,
+		&i.RerankModelID,
 	)
 	return i, err
 }
@@ -94,9 +99,13 @@ WITH updated AS (
       memory_model_id = COALESCE($7::uuid, bots.memory_model_id),
       embedding_model_id = COALESCE($8::uuid, bots.embedding_model_id),
       search_provider_id = COALESCE($9::uuid, bots.search_provider_id),
+      rerank_model_id = CASE
+        WHEN $10::boolean THEN NULL
+        ELSE COALESCE($11::uuid, bots.rerank_model_id)
+      END,
       updated_at = now()
-  WHERE bots.id = $10
-  RETURNING bots.id, bots.max_context_load_time, bots.max_context_tokens, bots.max_inbox_items, bots.language, bots.allow_guest, bots.chat_model_id, bots.memory_model_id, bots.embedding_model_id, bots.search_provider_id
+  WHERE bots.id = $12
+  RETURNING bots.id, bots.max_context_load_time, bots.max_context_tokens, bots.max_inbox_items, bots.language, bots.allow_guest, bots.chat_model_id, bots.memory_model_id, bots.embedding_model_id, bots.search_provider_id, bots.rerank_model_id
 )
 SELECT
   updated.id AS bot_id,
@@ -108,12 +117,14 @@ SELECT
   chat_models.model_id AS chat_model_id,
   memory_models.model_id AS memory_model_id,
   embedding_models.model_id AS embedding_model_id,
-  search_providers.id AS search_provider_id
+  search_providers.id AS search_provider_id,
+  rerank_models.model_id AS rerank_model_id
 FROM updated
 LEFT JOIN models AS chat_models ON chat_models.id = updated.chat_model_id
 LEFT JOIN models AS memory_models ON memory_models.id = updated.memory_model_id
 LEFT JOIN models AS embedding_models ON embedding_models.id = updated.embedding_model_id
 LEFT JOIN search_providers ON search_providers.id = updated.search_provider_id
+LEFT JOIN models AS rerank_models ON rerank_models.id = updated.rerank_model_id
 `
 
 type UpsertBotSettingsParams struct {
@@ -126,6 +137,8 @@ type UpsertBotSettingsParams struct {
 	MemoryModelID      pgtype.UUID `json:"memory_model_id"`
 	EmbeddingModelID   pgtype.UUID `json:"embedding_model_id"`
 	SearchProviderID   pgtype.UUID `json:"search_provider_id"`
+	ClearRerankModel   bool        `json:"clear_rerank_model"`
+	RerankModelID      pgtype.UUID `json:"rerank_model_id"`
 	ID                 pgtype.UUID `json:"id"`
 }
 
@@ -140,6 +153,7 @@ type UpsertBotSettingsRow struct {
 	MemoryModelID      pgtype.Text `json:"memory_model_id"`
 	EmbeddingModelID   pgtype.Text `json:"embedding_model_id"`
 	SearchProviderID   pgtype.UUID `json:"search_provider_id"`
+	RerankModelID      pgtype.Text `json:"rerank_model_id"`
 }
 
 func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsParams) (UpsertBotSettingsRow, error) {
@@ -153,6 +167,8 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		arg.MemoryModelID,
 		arg.EmbeddingModelID,
 		arg.SearchProviderID,
+		arg.ClearRerankModel,
+		arg.RerankModelID,
 		arg.ID,
 	)
 	var i UpsertBotSettingsRow
@@ -167,6 +183,7 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		&i.MemoryModelID,
 		&i.EmbeddingModelID,
 		&i.SearchProviderID,
+		&i.RerankModelID,
 	)
 	return i, err
 }
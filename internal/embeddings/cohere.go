@@ -0,0 +1,88 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCohereBaseURL = "https://api.cohere.com"
+	// cohereBatchSize is the limit of texts per /v2/embed request.
+	cohereBatchSize = 96
+)
+
+// CohereEmbedder calls the Cohere v2 embed API.
+type CohereEmbedder struct {
+	apiKey  string
+	baseURL string
+	model   string
+	dims    int
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+type cohereEmbedRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	InputType      string   `json:"input_type"`
+	EmbeddingTypes []string `json:"embedding_types"`
+}
+
+type cohereEmbedResponse struct {
+	Embeddings struct {
+		Float [][]float32 `json:"float"`
+	} `json:"embeddings"`
+}
+
+func NewCohereEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int, timeout time.Duration) (*CohereEmbedder, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("cohere embedder: api key is required")
+	}
+	if err := validateEmbedderModel("cohere", model, dims); err != nil {
+		return nil, err
+	}
+	baseURL = strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(baseURL), "/"), "/v2")
+	if baseURL == "" {
+		baseURL = DefaultCohereBaseURL
+	}
+	return &CohereEmbedder{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		dims:    dims,
+		logger:  log.With(slog.String("embedder", "cohere")),
+		http:    newEmbedderClient(timeout),
+	}, nil
+}
+
+func (e *CohereEmbedder) Dimensions() int {
+	return e.dims
+}
+
+func (e *CohereEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	return embedOne(ctx, e, input)
+}
+
+// EmbedBatch embeds inputs as search documents. Memories and queries share one
+// vector space, so queries use the same input type.
+func (e *CohereEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	return embedInBatches(ctx, inputs, cohereBatchSize, func(ctx context.Context, batch []string) ([][]float32, error) {
+		var parsed cohereEmbedResponse
+		err := postJSON(ctx, e.http, "cohere", e.baseURL+"/v2/embed", map[string]string{
+			"Authorization": "Bearer " + e.apiKey,
+		}, cohereEmbedRequest{
+			Model:          e.model,
+			Texts:          batch,
+			InputType:      "search_document",
+			EmbeddingTypes: []string{"float"},
+		}, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.Embeddings.Float, nil
+	})
+}
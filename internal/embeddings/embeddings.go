@@ -14,9 +14,15 @@ import (
 
 type Embedder interface {
 	Embed(ctx context.Context, input string) ([]float32, error)
+	// EmbedBatch embeds several inputs, returning vectors in input order.
+	// Providers with a batch API serve it in as few requests as possible.
+	EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error)
 	Dimensions() int
 }
 
+// openAIBatchSize caps the inputs sent in one OpenAI embeddings request.
+const openAIBatchSize = 256
+
 type OpenAIEmbedder struct {
 	apiKey  string
 	baseURL string
@@ -27,12 +33,13 @@ type OpenAIEmbedder struct {
 }
 
 type openAIEmbeddingRequest struct {
-	Input string `json:"input"`
-	Model string `json:"model"`
+	Input []string `json:"input"`
+	Model string   `json:"model"`
 }
 
 type openAIEmbeddingResponse struct {
 	Data []struct {
+		Index     int       `json:"index"`
 		Embedding []float32 `json:"embedding"`
 	} `json:"data"`
 }
@@ -44,14 +51,8 @@ func NewOpenAIEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int
 	if strings.TrimSpace(apiKey) == "" {
 		return nil, fmt.Errorf("openai embedder: api key is required")
 	}
-	if strings.TrimSpace(model) == "" {
-		return nil, fmt.Errorf("openai embedder: model is required")
-	}
-	if dims <= 0 {
-		return nil, fmt.Errorf("openai embedder: dimensions must be positive")
-	}
-	if timeout <= 0 {
-		timeout = 10 * time.Second
+	if err := validateEmbedderModel("openai", model, dims); err != nil {
+		return nil, err
 	}
 	return &OpenAIEmbedder{
 		apiKey:  apiKey,
@@ -59,9 +60,7 @@ func NewOpenAIEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int
 		model:   model,
 		dims:    dims,
 		logger:  log.With(slog.String("embedder", "openai")),
-		http: &http.Client{
-			Timeout: timeout,
-		},
+		http:    newEmbedderClient(timeout),
 	}, nil
 }
 
@@ -70,39 +69,112 @@ func (e *OpenAIEmbedder) Dimensions() int {
 }
 
 func (e *OpenAIEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
-	payload, err := json.Marshal(openAIEmbeddingRequest{
-		Input: input,
-		Model: e.model,
+	return embedOne(ctx, e, input)
+}
+
+func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	return embedInBatches(ctx, inputs, openAIBatchSize, func(ctx context.Context, batch []string) ([][]float32, error) {
+		var parsed openAIEmbeddingResponse
+		err := postJSON(ctx, e.http, "openai", e.baseURL+"/v1/embeddings", map[string]string{
+			"Authorization": "Bearer " + e.apiKey,
+		}, openAIEmbeddingRequest{Input: batch, Model: e.model}, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		return indexedVectors("openai", len(batch), parsed)
 	})
+}
+
+// indexedVectors orders the data of an OpenAI-style response by index.
+func indexedVectors(name string, count int, parsed openAIEmbeddingResponse) ([][]float32, error) {
+	vectors := make([][]float32, count)
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= count {
+			return nil, fmt.Errorf("%s embeddings: unexpected index %d", name, item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for _, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("%s embeddings empty response", name)
+		}
+	}
+	return vectors, nil
+}
+
+func validateEmbedderModel(name, model string, dims int) error {
+	if strings.TrimSpace(model) == "" {
+		return fmt.Errorf("%s embedder: model is required", name)
+	}
+	if dims <= 0 {
+		return fmt.Errorf("%s embedder: dimensions must be positive", name)
+	}
+	return nil
+}
+
+func newEmbedderClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// embedOne serves a single input through the batch path.
+func embedOne(ctx context.Context, e Embedder, input string) ([]float32, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{input})
 	if err != nil {
 		return nil, err
 	}
+	return vectors[0], nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v1/embeddings", bytes.NewReader(payload))
+// embedInBatches splits inputs into requests of at most size inputs and
+// concatenates the vectors in input order.
+func embedInBatches(ctx context.Context, inputs []string, size int, embed func(context.Context, []string) ([][]float32, error)) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("embedding input is required")
+	}
+	vectors := make([][]float32, 0, len(inputs))
+	for start := 0; start < len(inputs); start += size {
+		batch := inputs[start:min(start+size, len(inputs))]
+		embedded, err := embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedded) != len(batch) {
+			return nil, fmt.Errorf("embeddings: got %d vectors for %d inputs", len(embedded), len(batch))
+		}
+		vectors = append(vectors, embedded...)
+	}
+	return vectors, nil
+}
+
+// postJSON posts body as JSON and decodes a successful response into out.
+func postJSON(ctx context.Context, client *http.Client, name, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if e.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	for key, value := range headers {
+		req.Header.Set(key, value)
 	}
 
-	resp, err := e.http.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openai embeddings error: %s", strings.TrimSpace(string(body)))
-	}
-
-	var parsed openAIEmbeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, err
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return fmt.Errorf("%s embeddings error: %s", name, strings.TrimSpace(string(raw)))
 	}
-	if len(parsed.Data) == 0 {
-		return nil, fmt.Errorf("openai embeddings empty response")
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s embeddings: decode response: %w", name, err)
 	}
-	return parsed.Data[0].Embedding, nil
+	return nil
 }
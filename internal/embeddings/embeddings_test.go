@@ -0,0 +1,206 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// vectorFor encodes the input length so tests can check ordering.
+func vectorFor(input string) []float32 {
+	return []float32{float32(len(input)), 1}
+}
+
+func inputs(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = "memory " + strings.Repeat("x", i)
+	}
+	return out
+}
+
+func checkVectors(t *testing.T, name string, in []string, got [][]float32) {
+	t.Helper()
+	if len(got) != len(in) {
+		t.Fatalf("%s: expected %d vectors, got %d", name, len(in), len(got))
+	}
+	for i, text := range in {
+		if got[i][0] != float32(len(text)) {
+			t.Fatalf("%s: vector %d out of order", name, i)
+		}
+	}
+}
+
+func TestEmbeddersBatchRequests(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	calls := map[string][]int{}
+	record := func(path string, n int) {
+		mu.Lock()
+		calls[path] = append(calls[path], n)
+		mu.Unlock()
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/embed":
+			var req ollamaEmbedRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			record(r.URL.Path, len(req.Input))
+			resp := ollamaEmbedResponse{}
+			for _, input := range req.Input {
+				resp.Embeddings = append(resp.Embeddings, vectorFor(input))
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/v2/embed":
+			var req cohereEmbedRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if r.Header.Get("Authorization") != "Bearer ck" || req.InputType != "search_document" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			record(r.URL.Path, len(req.Texts))
+			resp := cohereEmbedResponse{}
+			for _, text := range req.Texts {
+				resp.Embeddings.Float = append(resp.Embeddings.Float, vectorFor(text))
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/v1/embeddings":
+			var req openAIEmbeddingRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			record(r.URL.Path, len(req.Input))
+			// Reply out of order; vectors must be placed by index.
+			resp := map[string]any{}
+			data := make([]map[string]any, 0, len(req.Input))
+			for i := len(req.Input) - 1; i >= 0; i-- {
+				data = append(data, map[string]any{"index": i, "embedding": vectorFor(req.Input[i])})
+			}
+			resp["data"] = data
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/v1beta/models/text-embedding-004:batchEmbedContents":
+			var req googleBatchEmbedRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if r.Header.Get("x-goog-api-key") != "gk" || req.Requests[0].OutputDimensionality != 2 || req.Requests[0].Model != "models/text-embedding-004" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			record(r.URL.Path, len(req.Requests))
+			embeddings := make([]map[string]any, 0, len(req.Requests))
+			for _, item := range req.Requests {
+				embeddings = append(embeddings, map[string]any{"values": vectorFor(item.Content.Parts[0].Text)})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	log := testLogger()
+	ollama, err := NewOllamaEmbedder(log, "", server.URL+"/v1", "nomic-embed-text", 2, time.Second)
+	if err != nil {
+		t.Fatalf("ollama: %v", err)
+	}
+	cohere, err := NewCohereEmbedder(log, "ck", server.URL, "embed-v4.0", 2, time.Second)
+	if err != nil {
+		t.Fatalf("cohere: %v", err)
+	}
+	voyage, err := NewVoyageEmbedder(log, "vk", server.URL, "voyage-3", 2, time.Second)
+	if err != nil {
+		t.Fatalf("voyage: %v", err)
+	}
+	google, err := NewGoogleEmbedder(log, "gk", server.URL+"/v1beta/openai/", "text-embedding-004", 2, time.Second)
+	if err != nil {
+		t.Fatalf("google: %v", err)
+	}
+	openai, err := NewOpenAIEmbedder(log, "ok", server.URL, "text-embedding-3-small", 2, time.Second)
+	if err != nil {
+		t.Fatalf("openai: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		embedder Embedder
+		path     string
+		want     []int
+	}{
+		{"ollama", ollama, "/api/embed", []int{64, 64, 22}},
+		{"cohere", cohere, "/v2/embed", []int{96, 54}},
+		{"google", google, "/v1beta/models/text-embedding-004:batchEmbedContents", []int{100, 50}},
+	}
+	in := inputs(150)
+	for _, tc := range cases {
+		got, err := tc.embedder.EmbedBatch(context.Background(), in)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		checkVectors(t, tc.name, in, got)
+		mu.Lock()
+		sizes := calls[tc.path]
+		mu.Unlock()
+		if len(sizes) != len(tc.want) {
+			t.Fatalf("%s: expected batches %v, got %v", tc.name, tc.want, sizes)
+		}
+		for i := range sizes {
+			if sizes[i] != tc.want[i] {
+				t.Fatalf("%s: expected batches %v, got %v", tc.name, tc.want, sizes)
+			}
+		}
+	}
+
+	for name, embedder := range map[string]Embedder{"voyage": voyage, "openai": openai} {
+		got, err := embedder.EmbedBatch(context.Background(), inputs(5))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		checkVectors(t, name, inputs(5), got)
+		single, err := embedder.Embed(context.Background(), "hello")
+		if err != nil || len(single) != 2 || single[0] != 5 {
+			t.Fatalf("%s: unexpected single embedding %v, %v", name, single, err)
+		}
+	}
+}
+
+func TestEmbedderErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/embed":
+			http.Error(w, `{"message":"invalid api token"}`, http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"embeddings":[[1,2]]}`))
+		}
+	}))
+	defer server.Close()
+
+	log := testLogger()
+	cohere, _ := NewCohereEmbedder(log, "bad", server.URL, "embed-v4.0", 2, time.Second)
+	if _, err := cohere.EmbedBatch(context.Background(), []string{"a"}); err == nil {
+		t.Fatalf("expected provider error to surface")
+	}
+	ollama, _ := NewOllamaEmbedder(log, "", server.URL, "nomic-embed-text", 2, time.Second)
+	if _, err := ollama.EmbedBatch(context.Background(), []string{"a", "b"}); err == nil {
+		t.Fatalf("expected vector count mismatch to fail")
+	}
+	if _, err := ollama.EmbedBatch(context.Background(), nil); err == nil {
+		t.Fatalf("expected empty input to fail")
+	}
+	if _, err := NewVoyageEmbedder(log, "", "", "voyage-3", 2, time.Second); err == nil {
+		t.Fatalf("expected missing api key to fail")
+	}
+	if _, err := NewGoogleEmbedder(log, "gk", "", "text-embedding-004", 0, time.Second); err == nil {
+		t.Fatalf("expected missing dimensions to fail")
+	}
+}
@@ -0,0 +1,106 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultGoogleBaseURL = "https://generativelanguage.googleapis.com"
+	// googleBatchSize is the limit of requests per batchEmbedContents call.
+	googleBatchSize = 100
+)
+
+// GoogleEmbedder calls the Gemini API batchEmbedContents method.
+type GoogleEmbedder struct {
+	apiKey  string
+	baseURL string
+	model   string
+	dims    int
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+type googleContent struct {
+	Parts []googlePart `json:"parts"`
+}
+
+type googlePart struct {
+	Text string `json:"text"`
+}
+
+type googleEmbedRequest struct {
+	Model                string        `json:"model"`
+	Content              googleContent `json:"content"`
+	OutputDimensionality int           `json:"outputDimensionality,omitempty"`
+}
+
+type googleBatchEmbedRequest struct {
+	Requests []googleEmbedRequest `json:"requests"`
+}
+
+type googleBatchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+func NewGoogleEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int, timeout time.Duration) (*GoogleEmbedder, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("google embedder: api key is required")
+	}
+	if err := validateEmbedderModel("google", model, dims); err != nil {
+		return nil, err
+	}
+	// Chat providers may point at the versioned or OpenAI-compatible path.
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	baseURL = strings.TrimSuffix(baseURL, "/openai")
+	baseURL = strings.TrimSuffix(baseURL, "/v1beta")
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+	if baseURL == "" {
+		baseURL = DefaultGoogleBaseURL
+	}
+	return &GoogleEmbedder{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   "models/" + strings.TrimPrefix(model, "models/"),
+		dims:    dims,
+		logger:  log.With(slog.String("embedder", "google")),
+		http:    newEmbedderClient(timeout),
+	}, nil
+}
+
+func (e *GoogleEmbedder) Dimensions() int {
+	return e.dims
+}
+
+func (e *GoogleEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	return embedOne(ctx, e, input)
+}
+
+func (e *GoogleEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	endpoint := e.baseURL + "/v1beta/" + e.model + ":batchEmbedContents"
+	return embedInBatches(ctx, inputs, googleBatchSize, func(ctx context.Context, batch []string) ([][]float32, error) {
+		body := googleBatchEmbedRequest{Requests: make([]googleEmbedRequest, 0, len(batch))}
+		for _, input := range batch {
+			body.Requests = append(body.Requests, googleEmbedRequest{
+				Model:                e.model,
+				Content:              googleContent{Parts: []googlePart{{Text: input}}},
+				OutputDimensionality: e.dims,
+			})
+		}
+		var parsed googleBatchEmbedResponse
+		if err := postJSON(ctx, e.http, "google", endpoint, map[string]string{"x-goog-api-key": e.apiKey}, body, &parsed); err != nil {
+			return nil, err
+		}
+		vectors := make([][]float32, 0, len(parsed.Embeddings))
+		for _, embedding := range parsed.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+		return vectors, nil
+	})
+}
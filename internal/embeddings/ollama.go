@@ -0,0 +1,77 @@
+package embeddings
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	ollamaBatchSize      = 64
+)
+
+// OllamaEmbedder calls the /api/embed endpoint of a local Ollama server.
+type OllamaEmbedder struct {
+	apiKey  string
+	baseURL string
+	model   string
+	dims    int
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// NewOllamaEmbedder builds an Ollama embedder. The API key is optional and
+// only sent when Ollama runs behind an authenticating proxy.
+func NewOllamaEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int, timeout time.Duration) (*OllamaEmbedder, error) {
+	if err := validateEmbedderModel("ollama", model, dims); err != nil {
+		return nil, err
+	}
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	// Providers configured for Ollama's OpenAI-compatible API end in /v1.
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	return &OllamaEmbedder{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		dims:    dims,
+		logger:  log.With(slog.String("embedder", "ollama")),
+		http:    newEmbedderClient(timeout),
+	}, nil
+}
+
+func (e *OllamaEmbedder) Dimensions() int {
+	return e.dims
+}
+
+func (e *OllamaEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	return embedOne(ctx, e, input)
+}
+
+func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	headers := map[string]string{}
+	if e.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.apiKey
+	}
+	return embedInBatches(ctx, inputs, ollamaBatchSize, func(ctx context.Context, batch []string) ([][]float32, error) {
+		var parsed ollamaEmbedResponse
+		if err := postJSON(ctx, e.http, "ollama", e.baseURL+"/api/embed", headers, ollamaEmbedRequest{Model: e.model, Input: batch}, &parsed); err != nil {
+			return nil, err
+		}
+		return parsed.Embeddings, nil
+	})
+}
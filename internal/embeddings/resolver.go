@@ -88,36 +88,9 @@ func (r *Resolver) Embed(ctx context.Context, req Request) (Result, error) {
 		return Result{}, errors.New("invalid embeddings type")
 	}
 
-	selected, err := r.selectEmbeddingModel(ctx, req)
-	if err != nil {
-		return Result{}, err
-	}
-	provider, err := r.fetchProvider(ctx, selected.LlmProviderID)
-	if err != nil {
-		return Result{}, err
-	}
-
-	req.Model = selected.ModelID
-	req.Dimensions = selected.Dimensions
-	if selected.ClientType != "" {
-		req.Provider = string(selected.ClientType)
-	}
-	if req.Model == "" {
-		return Result{}, errors.New("embedding model id not configured")
-	}
-	if req.Dimensions <= 0 {
-		return Result{}, errors.New("embedding model dimensions not configured")
-	}
-
-	timeout := r.timeout
-	if timeout <= 0 {
-		timeout = 10 * time.Second
-	}
-
-	// OpenAI-compatible embeddings work for both openai-responses and openai-completions
 	switch req.Type {
 	case TypeText:
-		embedder, err := NewOpenAIEmbedder(r.logger, provider.ApiKey, provider.BaseUrl, req.Model, req.Dimensions, timeout)
+		embedder, resolved, err := r.textEmbedder(ctx, req)
 		if err != nil {
 			return Result{}, err
 		}
@@ -126,19 +99,102 @@ func (r *Resolver) Embed(ctx context.Context, req Request) (Result, error) {
 			return Result{}, err
 		}
 		return Result{
-			Type:       req.Type,
-			Provider:   req.Provider,
-			Model:      req.Model,
-			Dimensions: req.Dimensions,
+			Type:       resolved.Type,
+			Provider:   resolved.Provider,
+			Model:      resolved.Model,
+			Dimensions: resolved.Dimensions,
 			Embedding:  vector,
 		}, nil
 	case TypeMultimodal:
+		if _, _, err := r.resolveModel(ctx, req); err != nil {
+			return Result{}, err
+		}
 		return Result{}, errors.New("multimodal embeddings not supported for current provider types")
 	default:
 		return Result{}, errors.New("invalid embeddings type")
 	}
 }
 
+// EmbedTexts embeds several texts with the text embedding model selected by
+// req, batching requests where the provider supports it. Vectors are returned
+// in input order.
+func (r *Resolver) EmbedTexts(ctx context.Context, req Request, texts []string) ([][]float32, error) {
+	req.Type = TypeText
+	req.Provider = strings.ToLower(strings.TrimSpace(req.Provider))
+	req.Model = strings.TrimSpace(req.Model)
+	if len(texts) == 0 {
+		return nil, errors.New("text input is required")
+	}
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, errors.New("text input is required")
+		}
+	}
+	embedder, _, err := r.textEmbedder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return embedder.EmbedBatch(ctx, texts)
+}
+
+// textEmbedder builds the embedder for the selected text model and returns the
+// request completed with the model's settings.
+func (r *Resolver) textEmbedder(ctx context.Context, req Request) (Embedder, Request, error) {
+	selected, provider, err := r.resolveModel(ctx, req)
+	if err != nil {
+		return nil, Request{}, err
+	}
+	req.Model = selected.ModelID
+	req.Dimensions = selected.Dimensions
+	if selected.ClientType != "" {
+		req.Provider = string(selected.ClientType)
+	}
+	timeout := r.timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	embedder, err := newTextEmbedder(r.logger, selected.ClientType, provider, req.Model, req.Dimensions, timeout)
+	if err != nil {
+		return nil, Request{}, err
+	}
+	return embedder, req, nil
+}
+
+func (r *Resolver) resolveModel(ctx context.Context, req Request) (models.GetResponse, sqlc.LlmProvider, error) {
+	selected, err := r.selectEmbeddingModel(ctx, req)
+	if err != nil {
+		return models.GetResponse{}, sqlc.LlmProvider{}, err
+	}
+	provider, err := r.fetchProvider(ctx, selected.LlmProviderID)
+	if err != nil {
+		return models.GetResponse{}, sqlc.LlmProvider{}, err
+	}
+	if selected.ModelID == "" {
+		return models.GetResponse{}, sqlc.LlmProvider{}, errors.New("embedding model id not configured")
+	}
+	if selected.Dimensions <= 0 {
+		return models.GetResponse{}, sqlc.LlmProvider{}, errors.New("embedding model dimensions not configured")
+	}
+	return selected, provider, nil
+}
+
+// newTextEmbedder picks the embedding API by client type. Models without a
+// dedicated provider use the OpenAI-compatible API.
+func newTextEmbedder(log *slog.Logger, clientType models.ClientType, provider sqlc.LlmProvider, model string, dims int, timeout time.Duration) (Embedder, error) {
+	switch clientType {
+	case models.ClientTypeOllama:
+		return NewOllamaEmbedder(log, provider.ApiKey, provider.BaseUrl, model, dims, timeout)
+	case models.ClientTypeCohere:
+		return NewCohereEmbedder(log, provider.ApiKey, provider.BaseUrl, model, dims, timeout)
+	case models.ClientTypeVoyage:
+		return NewVoyageEmbedder(log, provider.ApiKey, provider.BaseUrl, model, dims, timeout)
+	case models.ClientTypeGoogleGenerativeAI:
+		return NewGoogleEmbedder(log, provider.ApiKey, provider.BaseUrl, model, dims, timeout)
+	default:
+		return NewOpenAIEmbedder(log, provider.ApiKey, provider.BaseUrl, model, dims, timeout)
+	}
+}
+
 func (r *Resolver) selectEmbeddingModel(ctx context.Context, req Request) (models.GetResponse, error) {
 	if r.modelsService == nil {
 		return models.GetResponse{}, errors.New("models service not configured")
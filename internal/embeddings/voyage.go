@@ -0,0 +1,68 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultVoyageBaseURL = "https://api.voyageai.com"
+	// voyageBatchSize is the limit of inputs per Voyage embeddings request.
+	voyageBatchSize = 128
+)
+
+// VoyageEmbedder calls the Voyage AI embeddings API.
+type VoyageEmbedder struct {
+	apiKey  string
+	baseURL string
+	model   string
+	dims    int
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+func NewVoyageEmbedder(log *slog.Logger, apiKey, baseURL, model string, dims int, timeout time.Duration) (*VoyageEmbedder, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("voyage embedder: api key is required")
+	}
+	if err := validateEmbedderModel("voyage", model, dims); err != nil {
+		return nil, err
+	}
+	baseURL = strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(baseURL), "/"), "/v1")
+	if baseURL == "" {
+		baseURL = DefaultVoyageBaseURL
+	}
+	return &VoyageEmbedder{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		dims:    dims,
+		logger:  log.With(slog.String("embedder", "voyage")),
+		http:    newEmbedderClient(timeout),
+	}, nil
+}
+
+func (e *VoyageEmbedder) Dimensions() int {
+	return e.dims
+}
+
+func (e *VoyageEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	return embedOne(ctx, e, input)
+}
+
+func (e *VoyageEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	return embedInBatches(ctx, inputs, voyageBatchSize, func(ctx context.Context, batch []string) ([][]float32, error) {
+		var parsed openAIEmbeddingResponse
+		err := postJSON(ctx, e.http, "voyage", e.baseURL+"/v1/embeddings", map[string]string{
+			"Authorization": "Bearer " + e.apiKey,
+		}, openAIEmbeddingRequest{Input: batch, Model: e.model}, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		return indexedVectors("voyage", len(batch), parsed)
+	})
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// ChannelOutboundHandler exposes the persistent outbound delivery queue for
+// inspection and dead-letter replay.
+type ChannelOutboundHandler struct {
+	store          *channel.Store
+	manager        *channel.Manager
+	registry       *channel.Registry
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// OutboundQueueSummary reports per-status delivery counts for a bot.
+type OutboundQueueSummary struct {
+	Pending   int64 `json:"pending"`
+	Sending   int64 `json:"sending"`
+	Delivered int64 `json:"delivered"`
+	Dead      int64 `json:"dead"`
+}
+
+// OutboundReplayResponse reports how many deliveries were requeued.
+type OutboundReplayResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+func NewChannelOutboundHandler(log *slog.Logger, store *channel.Store, manager *channel.Manager, registry *channel.Registry, botService *bots.Service, accountService *accounts.Service) *ChannelOutboundHandler {
+	return &ChannelOutboundHandler{
+		store:          store,
+		manager:        manager,
+		registry:       registry,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "channel_outbound")),
+	}
+}
+
+func (h *ChannelOutboundHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/outbound")
+	group.GET("", h.List)
+	group.GET("/summary", h.Summary)
+	group.POST("/replay", h.ReplayDead)
+	group.GET("/:id", h.Get)
+	group.POST("/:id/replay", h.Replay)
+	group.DELETE("/:id", h.Delete)
+}
+
+// List godoc
+// @Summary List outbound deliveries
+// @Description List queued, delivered and dead-lettered outbound deliveries for a bot
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Param status query string false "Filter by status (pending, sending, delivered, dead)"
+// @Param platform query string false "Filter by channel platform"
+// @Param limit query int false "Max items to return" default(50)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {array} channel.OutboundDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound [get]
+func (h *ChannelOutboundHandler) List(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	filter := channel.OutboundDeliveryFilter{
+		Limit:  parseIntOr(c.QueryParam("limit"), 50),
+		Offset: parseIntOr(c.QueryParam("offset"), 0),
+	}
+	if status := strings.TrimSpace(c.QueryParam("status")); status != "" {
+		switch parsed := channel.OutboundDeliveryStatus(strings.ToLower(status)); parsed {
+		case channel.OutboundDeliveryPending, channel.OutboundDeliverySending, channel.OutboundDeliveryDelivered, channel.OutboundDeliveryDead:
+			filter.Status = parsed
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+		}
+	}
+	if platform := strings.TrimSpace(c.QueryParam("platform")); platform != "" {
+		channelType, err := h.registry.ParseChannelType(platform)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		filter.ChannelType = channelType
+	}
+	items, err := h.store.ListOutbound(c.Request().Context(), botID, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// Summary godoc
+// @Summary Summarize outbound deliveries
+// @Description Count outbound deliveries per status for a bot
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} OutboundQueueSummary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound/summary [get]
+func (h *ChannelOutboundHandler) Summary(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	counts, err := h.store.CountOutboundByStatus(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, OutboundQueueSummary{
+		Pending:   counts[channel.OutboundDeliveryPending],
+		Sending:   counts[channel.OutboundDeliverySending],
+		Delivered: counts[channel.OutboundDeliveryDelivered],
+		Dead:      counts[channel.OutboundDeliveryDead],
+	})
+}
+
+// Get godoc
+// @Summary Get outbound delivery
+// @Description Get a single outbound delivery including its last error
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} channel.OutboundDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound/{id} [get]
+func (h *ChannelOutboundHandler) Get(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "delivery id is required")
+	}
+	item, err := h.store.GetOutbound(c.Request().Context(), botID, id)
+	if err != nil {
+		if errors.Is(err, channel.ErrOutboundDeliveryNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// Replay godoc
+// @Summary Replay a dead-lettered delivery
+// @Description Reset attempts of a dead-lettered delivery and queue it again
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} channel.OutboundDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound/{id}/replay [post]
+func (h *ChannelOutboundHandler) Replay(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "delivery id is required")
+	}
+	item, err := h.store.ReplayOutbound(c.Request().Context(), botID, id)
+	if err != nil {
+		if errors.Is(err, channel.ErrOutboundDeliveryNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "dead-lettered delivery not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	h.manager.NotifyOutbound()
+	return c.JSON(http.StatusOK, item)
+}
+
+// ReplayDead godoc
+// @Summary Replay all dead-lettered deliveries
+// @Description Queue every dead-lettered delivery of a bot again
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} OutboundReplayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound/replay [post]
+func (h *ChannelOutboundHandler) ReplayDead(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	count, err := h.store.ReplayDeadOutbound(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if count > 0 {
+		h.manager.NotifyOutbound()
+	}
+	return c.JSON(http.StatusOK, OutboundReplayResponse{Requeued: count})
+}
+
+// Delete godoc
+// @Summary Delete outbound delivery
+// @Description Drop a queued or dead-lettered delivery
+// @Tags channel
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Delivery ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/outbound/{id} [delete]
+func (h *ChannelOutboundHandler) Delete(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "delivery id is required")
+	}
+	if err := h.store.DeleteOutbound(c.Request().Context(), botID, id); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *ChannelOutboundHandler) requireBotAccess(c echo.Context) (string, error) {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
+
+func (h *ChannelOutboundHandler) authorizeBotAccess(ctx context.Context, channelIdentityID, botID string) (bots.Bot, error) {
+	return AuthorizeBotAccess(ctx, h.botService, h.accountService, channelIdentityID, botID, bots.AccessPolicy{AllowPublicMember: false})
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/memohai/memoh/internal/db"
 	dbsqlc "github.com/memohai/memoh/internal/db/sqlc"
 	"github.com/memohai/memoh/internal/mcp"
+	"github.com/memohai/memoh/internal/mcp/catalog"
 	"github.com/memohai/memoh/internal/policy"
 )
 
@@ -49,6 +50,10 @@ type ContainerdHandler struct {
 	accountService *accounts.Service
 	policyService  *policy.Service
 	queries        *dbsqlc.Queries
+	mcpCatalog     *catalog.Service
+	mcpConnections *mcp.ConnectionService
+	mcpSecrets     SecretExpander
+	jwtSecret      string
 }
 
 type CreateContainerRequest struct {
@@ -146,6 +151,9 @@ func (h *ContainerdHandler) Register(e *echo.Echo) {
 	root.POST("/mcp-stdio", h.CreateMCPStdio)
 	root.POST("/mcp-stdio/:connection_id", h.HandleMCPStdio)
 	root.POST("/tools", h.HandleMCPTools)
+	root.POST("/mcp-catalog/:id/install", h.InstallMCPCatalogEntry)
+	e.GET("/mcp/catalog", h.ListMCPCatalog)
+	e.GET("/mcp/catalog/:id", h.GetMCPCatalogEntry)
 }
 
 // CreateContainer godoc
@@ -1019,6 +1027,7 @@ func (h *ContainerdHandler) CleanupBotContainer(ctx context.Context, botID strin
 			}
 		}
 	}
+	h.invalidateToolResults(botID)
 	h.logger.Info("CleanupBotContainer finished", slog.String("bot_id", botID))
 	return nil
 }
@@ -470,6 +470,7 @@ func (h *ContainerdHandler) FSWrite(c echo.Context) error {
 	if err := os.WriteFile(pc.hostPath, []byte(req.Content), 0o644); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, fsOpResponse{OK: true})
 }
 
@@ -531,6 +532,7 @@ func (h *ContainerdHandler) FSUpload(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, FSUploadResponse{
 		Path: pc.containerPath,
 		Size: written,
@@ -630,6 +632,7 @@ func (h *ContainerdHandler) FSDelete(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 	}
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, fsOpResponse{OK: true})
 }
 
@@ -682,6 +685,7 @@ func (h *ContainerdHandler) FSRename(c echo.Context) error {
 	if err := os.Rename(oldPC.hostPath, newPC.hostPath); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, fsOpResponse{OK: true})
 }
 
@@ -66,6 +66,7 @@ type mcpSession struct {
 	closeOnce sync.Once
 	closeErr  error
 	onClose   func()
+	onNotify  func(method string)
 }
 
 type mcpSessionInitState uint8
@@ -238,6 +239,12 @@ func (s *mcpSession) readLoop() {
 			s.closeWithError(err)
 			return
 		}
+		if req, ok := msg.(*sdkjsonrpc.Request); ok {
+			if !req.ID.IsValid() {
+				s.handleNotification(req.Method)
+			}
+			continue
+		}
 		resp, ok := msg.(*sdkjsonrpc.Response)
 		if !ok || !resp.ID.IsValid() {
 			continue
@@ -259,6 +266,23 @@ func (s *mcpSession) readLoop() {
 	}
 }
 
+// setNotificationHandler registers a callback for notifications sent by the
+// server, e.g. notifications/tools/list_changed.
+func (s *mcpSession) setNotificationHandler(handler func(method string)) {
+	s.pendingMu.Lock()
+	s.onNotify = handler
+	s.pendingMu.Unlock()
+}
+
+func (s *mcpSession) handleNotification(method string) {
+	s.pendingMu.Lock()
+	handler := s.onNotify
+	s.pendingMu.Unlock()
+	if handler != nil {
+		handler(method)
+	}
+}
+
 func (s *mcpSession) call(ctx context.Context, req mcptools.JSONRPCRequest) (map[string]any, error) {
 	method := strings.TrimSpace(req.Method)
 	if method == "initialize" {
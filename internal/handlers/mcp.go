@@ -13,12 +13,20 @@ import (
 	"github.com/memohai/memoh/internal/accounts"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/mcp"
+	"github.com/memohai/memoh/internal/mcp/botserver"
+	mcpoauth "github.com/memohai/memoh/internal/mcp/oauth"
 )
 
 type MCPHandler struct {
 	service        *mcp.ConnectionService
 	botService     *bots.Service
 	accountService *accounts.Service
+	oauth          *mcpoauth.Service
+	publicURL      string
+	toolPolicies   *mcp.ToolPolicyService
+	toolCalls      *mcp.ToolCallLogService
+	botServer      *botserver.Server
+	mcpTokens      *botserver.TokenService
 	logger         *slog.Logger
 }
 
@@ -38,11 +46,30 @@ func (h *MCPHandler) Register(e *echo.Echo) {
 	group.GET("/:id", h.Get)
 	group.PUT("/:id", h.Update)
 	group.DELETE("/:id", h.Delete)
+	group.POST("/:id/oauth/authorize", h.AuthorizeOAuth)
+	group.GET("/:id/oauth", h.OAuthStatus)
+	group.DELETE("/:id/oauth", h.RevokeOAuth)
+	e.GET(mcpOAuthCallbackPath, h.OAuthCallback)
 
 	ops := e.Group("/bots/:bot_id/mcp-ops")
 	ops.PUT("/import", h.Import)
 	ops.GET("/export", h.Export)
 	ops.POST("/batch-delete", h.BatchDelete)
+
+	policies := e.Group("/bots/:bot_id/tool-policies")
+	policies.GET("", h.ListToolPolicies)
+	policies.PUT("", h.UpsertToolPolicy)
+	policies.DELETE("/:id", h.DeleteToolPolicy)
+
+	calls := e.Group("/bots/:bot_id/tool-calls")
+	calls.GET("", h.ListToolCalls)
+	calls.GET("/stats", h.ToolCallStats)
+
+	tokens := e.Group("/bots/:bot_id/mcp-tokens")
+	tokens.GET("", h.ListMCPTokens)
+	tokens.POST("", h.IssueMCPToken)
+	tokens.DELETE("/:id", h.RevokeMCPToken)
+	e.Match([]string{http.MethodGet, http.MethodPost, http.MethodDelete}, botMCPServerPath, h.ServeBotMCP)
 }
 
 // List godoc
@@ -71,7 +98,7 @@ func (h *MCPHandler) List(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(http.StatusOK, mcp.ListResponse{Items: items})
+	return c.JSON(http.StatusOK, mcp.ListResponse{Items: mcp.MaskConnections(items)})
 }
 
 // Create godoc
@@ -105,7 +132,7 @@ func (h *MCPHandler) Create(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	return c.JSON(http.StatusCreated, resp)
+	return c.JSON(http.StatusCreated, resp.Masked())
 }
 
 // Get godoc
@@ -142,7 +169,7 @@ func (h *MCPHandler) Get(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(http.StatusOK, resp)
+	return c.JSON(http.StatusOK, resp.Masked())
 }
 
 // Update godoc
@@ -184,7 +211,7 @@ func (h *MCPHandler) Update(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	return c.JSON(http.StatusOK, resp)
+	return c.JSON(http.StatusOK, resp.Masked())
 }
 
 // Delete godoc
@@ -250,7 +277,7 @@ func (h *MCPHandler) Import(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	return c.JSON(http.StatusOK, mcp.ListResponse{Items: items})
+	return c.JSON(http.StatusOK, mcp.ListResponse{Items: mcp.MaskConnections(items)})
 }
 
 // BatchDeleteRequest is the body for batch delete.
@@ -318,6 +345,9 @@ func (h *MCPHandler) Export(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	for name, entry := range resp.MCPServers {
+		resp.MCPServers[name] = entry.Masked()
+	}
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+	"github.com/memohai/memoh/internal/mcp/botserver"
+)
+
+// botMCPServerPath is the public endpoint external MCP clients connect to.
+// It sits outside /bots so requests skip the JWT middleware and are
+// authenticated with bot MCP tokens instead.
+const botMCPServerPath = "/mcp/bots/:bot_id"
+
+// SetBotServer enables the public MCP endpoint of bots and its token
+// management endpoints.
+func (h *MCPHandler) SetBotServer(server *botserver.Server, tokens *botserver.TokenService) {
+	h.botServer = server
+	h.mcpTokens = tokens
+}
+
+// ListMCPTokens godoc
+// @Summary List bot MCP tokens
+// @Description List the tokens external MCP clients use to connect to a bot
+// @Tags mcp
+// @Success 200 {object} botserver.TokenListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp-tokens [get]
+func (h *MCPHandler) ListMCPTokens(c echo.Context) error {
+	_, botID, err := h.requireMCPTokensBot(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.mcpTokens.List(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, botserver.TokenListResponse{Items: items})
+}
+
+// IssueMCPToken godoc
+// @Summary Issue a bot MCP token
+// @Description Create a scoped token for external MCP clients. Calls made with it run on behalf of the issuing user. The token is only returned once.
+// @Tags mcp
+// @Param payload body botserver.IssueRequest true "Token name, scopes and expiry"
+// @Success 201 {object} botserver.IssuedToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp-tokens [post]
+func (h *MCPHandler) IssueMCPToken(c echo.Context) error {
+	userID, botID, err := h.requireMCPTokensBot(c)
+	if err != nil {
+		return err
+	}
+	var req botserver.IssueRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	issued, err := h.mcpTokens.Issue(c.Request().Context(), botID, userID, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	issued.Endpoint = h.oauthBaseURL(c) + strings.Replace(botMCPServerPath, ":bot_id", botID, 1)
+	return c.JSON(http.StatusCreated, issued)
+}
+
+// RevokeMCPToken godoc
+// @Summary Revoke a bot MCP token
+// @Description Delete a token; clients using it are rejected from then on
+// @Tags mcp
+// @Param id path string true "Token ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp-tokens/{id} [delete]
+func (h *MCPHandler) RevokeMCPToken(c echo.Context) error {
+	_, botID, err := h.requireMCPTokensBot(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "id is required")
+	}
+	if err := h.mcpTokens.Revoke(c.Request().Context(), botID, id); err != nil {
+		if errors.Is(err, botserver.ErrTokenNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ServeBotMCP godoc
+// @Summary Bot MCP server
+// @Description Streamable HTTP MCP endpoint for external clients, authenticated with a bot MCP token as Bearer token. Offers chat_with_bot, search_memory, add_memory, list_schedules and send_message as far as the token's scopes allow.
+// @Tags mcp
+// @Param bot_id path string true "Bot ID"
+// @Param payload body object true "JSON-RPC request"
+// @Success 200 {object} object "JSON-RPC response: {jsonrpc,id,result|error}"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /mcp/bots/{bot_id} [post]
+func (h *MCPHandler) ServeBotMCP(c echo.Context) error {
+	if h.botServer == nil || h.mcpTokens == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "bot mcp server not configured")
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	req := c.Request()
+	secret, _ := strings.CutPrefix(strings.TrimSpace(req.Header.Get(echo.HeaderAuthorization)), "Bearer ")
+	principal, err := h.mcpTokens.Authenticate(req.Context(), botID, secret)
+	if err != nil {
+		if errors.Is(err, botserver.ErrInvalidToken) {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="memoh"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	// The token only carries the access its issuer still has.
+	if _, err := h.authorizeBotAccess(req.Context(), principal.UserID, botID); err != nil {
+		return err
+	}
+
+	ensureStreamableAcceptHeader(req)
+	handler := sdkmcp.NewStreamableHTTPHandler(
+		func(*http.Request) *sdkmcp.Server {
+			return h.buildBotMCPServer(principal)
+		},
+		&sdkmcp.StreamableHTTPOptions{
+			Stateless:    true,
+			JSONResponse: true,
+			Logger:       h.logger,
+		},
+	)
+	handler.ServeHTTP(c.Response().Writer, req)
+	return nil
+}
+
+func (h *MCPHandler) buildBotMCPServer(principal botserver.Principal) *sdkmcp.Server {
+	server := sdkmcp.NewServer(
+		&sdkmcp.Implementation{
+			Name:    "memoh-bot",
+			Version: "1.0.0",
+		},
+		&sdkmcp.ServerOptions{
+			Capabilities: &sdkmcp.ServerCapabilities{
+				Tools: &sdkmcp.ToolCapabilities{ListChanged: false},
+			},
+		},
+	)
+	server.AddReceivingMiddleware(h.botMCPMiddleware(principal))
+	return server
+}
+
+func (h *MCPHandler) botMCPMiddleware(principal botserver.Principal) sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			switch strings.TrimSpace(method) {
+			case "tools/list":
+				tools, err := h.botServer.ListTools(ctx, principal)
+				if err != nil {
+					return nil, err
+				}
+				return &sdkmcp.ListToolsResult{Tools: convertGatewayToolsToSDK(tools)}, nil
+			case "tools/call":
+				callReq, ok := req.(*sdkmcp.ServerRequest[*sdkmcp.CallToolParamsRaw])
+				if !ok || callReq == nil || callReq.Params == nil {
+					return nil, fmt.Errorf("tools/call params is required")
+				}
+				payload, err := buildToolCallPayloadFromRaw(callReq.Params)
+				if err != nil {
+					return nil, err
+				}
+				result, err := h.botServer.CallTool(ctx, principal, payload.Name, payload.Arguments)
+				if err != nil {
+					if errors.Is(err, mcpgw.ErrToolNotFound) {
+						return convertGatewayCallResultToSDK(mcpgw.BuildToolErrorResult("tool not found: " + payload.Name))
+					}
+					return nil, err
+				}
+				return convertGatewayCallResultToSDK(result)
+			default:
+				return next(ctx, method, req)
+			}
+		}
+	}
+}
+
+func (h *MCPHandler) requireMCPTokensBot(c echo.Context) (string, string, error) {
+	if h.mcpTokens == nil {
+		return "", "", echo.NewHTTPError(http.StatusServiceUnavailable, "bot mcp server not configured")
+	}
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", "", err
+	}
+	return userID, botID, nil
+}
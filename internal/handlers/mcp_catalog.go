@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	ctr "github.com/memohai/memoh/internal/containerd"
+	mcptools "github.com/memohai/memoh/internal/mcp"
+	"github.com/memohai/memoh/internal/mcp/catalog"
+)
+
+// mcpCatalogSetupTimeout bounds the package install of a catalog server;
+// first installs download the package and its dependencies.
+const mcpCatalogSetupTimeout = 5 * time.Minute
+
+// MCPCatalogInstallResponse is the connection created by a catalog install
+// together with the tools the server reported.
+type MCPCatalogInstallResponse struct {
+	Connection mcptools.Connection `json:"connection"`
+	Tools      []string            `json:"tools"`
+	// Probed is false for remote servers, which are not contacted on install.
+	Probed bool `json:"probed"`
+}
+
+// SetMCPCatalog enables the MCP server catalog and one-click installs. The
+// expander resolves secret references in env values for the install probe;
+// the connection keeps the references.
+func (h *ContainerdHandler) SetMCPCatalog(service *catalog.Service, connections *mcptools.ConnectionService, secrets SecretExpander) {
+	h.mcpCatalog = service
+	h.mcpConnections = connections
+	h.mcpSecrets = secrets
+}
+
+// ListMCPCatalog godoc
+// @Summary List MCP catalog
+// @Description List installable MCP servers from the built-in and configured remote registries
+// @Tags mcp
+// @Param q query string false "Search in ID, name, description and tags"
+// @Param tag query string false "Filter by tag"
+// @Success 200 {object} catalog.ListResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /mcp/catalog [get]
+func (h *ContainerdHandler) ListMCPCatalog(c echo.Context) error {
+	if h.mcpCatalog == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "mcp catalog not configured")
+	}
+	items := h.mcpCatalog.List(c.Request().Context(), catalog.ListFilter{
+		Query: c.QueryParam("q"),
+		Tag:   c.QueryParam("tag"),
+	})
+	return c.JSON(http.StatusOK, catalog.ListResponse{Items: items})
+}
+
+// GetMCPCatalogEntry godoc
+// @Summary Get MCP catalog entry
+// @Tags mcp
+// @Param id path string true "Catalog entry ID"
+// @Success 200 {object} catalog.Entry
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /mcp/catalog/{id} [get]
+func (h *ContainerdHandler) GetMCPCatalogEntry(c echo.Context) error {
+	if h.mcpCatalog == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "mcp catalog not configured")
+	}
+	entry, err := h.mcpCatalog.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// InstallMCPCatalogEntry godoc
+// @Summary Install MCP server from catalog
+// @Description Install a catalog server on a bot. Local servers are installed in the bot container after checking its prerequisites, and the connection is only saved once the server answers tools/list. Remote servers are saved directly.
+// @Tags mcp
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Catalog entry ID"
+// @Param payload body catalog.InstallRequest true "Connection name and env values"
+// @Success 200 {object} MCPCatalogInstallResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp-catalog/{id}/install [post]
+func (h *ContainerdHandler) InstallMCPCatalogEntry(c echo.Context) error {
+	if h.mcpCatalog == nil || h.mcpConnections == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "mcp catalog not configured")
+	}
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	var req catalog.InstallRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	ctx := c.Request().Context()
+	entry, err := h.mcpCatalog.Get(ctx, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	plan, err := catalog.PlanInstall(entry, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	resp := MCPCatalogInstallResponse{Tools: []string{}}
+	if entry.Local() {
+		tools, err := h.installMCPCatalogServer(ctx, botID, plan)
+		if err != nil {
+			return err
+		}
+		resp.Tools = tools
+		resp.Probed = true
+	}
+	conns, err := h.mcpConnections.Import(ctx, botID, mcptools.ImportRequest{
+		MCPServers: map[string]mcptools.MCPServerEntry{plan.Name: plan.Server},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if len(conns) == 0 {
+		return echo.NewHTTPError(http.StatusInternalServerError, "connection not created")
+	}
+	resp.Connection = conns[0].Masked()
+	h.logger.Info("mcp catalog server installed",
+		slog.String("bot_id", botID),
+		slog.String("entry", entry.ID),
+		slog.String("name", plan.Name),
+		slog.Int("tools", len(resp.Tools)),
+	)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// installMCPCatalogServer prepares a local catalog server in the bot
+// container and returns the tools it offers.
+func (h *ContainerdHandler) installMCPCatalogServer(ctx context.Context, botID string, plan catalog.Plan) ([]string, error) {
+	containerID, err := h.botContainerID(ctx, botID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "container not found for bot")
+	}
+	if err := h.validateMCPContainer(ctx, containerID, botID); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.ensureContainerAndTask(ctx, containerID, botID); err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	var missing []string
+	for _, binary := range plan.Requires {
+		if _, err := h.execInContainer(ctx, containerID, []string{"sh", "-c", `command -v "$0"`, binary}); err != nil {
+			missing = append(missing, binary)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "missing in bot container: "+strings.Join(missing, ", "))
+	}
+	if len(plan.Setup) > 0 {
+		setupCtx, cancel := context.WithTimeout(ctx, mcpCatalogSetupTimeout)
+		defer cancel()
+		if _, err := h.execInContainer(setupCtx, containerID, plan.Setup); err != nil {
+			return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "install failed: "+err.Error())
+		}
+	}
+
+	env := plan.Server.Env
+	if h.mcpSecrets != nil && len(env) > 0 {
+		if env, err = h.mcpSecrets.ExpandMap(ctx, botID, env); err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	sess, err := h.startContainerdMCPCommandSession(ctx, containerID, MCPStdioRequest{
+		Name:    plan.Name,
+		Command: plan.Server.Command,
+		Args:    plan.Server.Args,
+		Env:     env,
+		Cwd:     plan.Server.Cwd,
+	})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer sess.closeWithError(io.EOF)
+	tools := h.probeMCPTools(ctx, sess, botID, plan.Name)
+	if len(tools) == 0 {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "server started but reported no tools")
+	}
+	return tools, nil
+}
+
+// execInContainer runs a command in the container and returns its stdout.
+// A non-zero exit is an error carrying the command's stderr.
+func (h *ContainerdHandler) execInContainer(ctx context.Context, containerID string, args []string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	result, err := h.service.ExecTask(ctx, containerID, ctr.ExecTaskRequest{
+		Args:   args,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s timed out", args[0])
+		}
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = fmt.Sprintf("exit code %d", result.ExitCode)
+		}
+		return "", fmt.Errorf("%s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func (g *MCPFederationGateway) ListConnectionResources(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ResourceDescriptor, error) {
+	var out struct {
+		Resources []mcpgw.ResourceDescriptor `json:"resources"`
+	}
+	err := g.callConnection(ctx, botID, connection, "resources/list", nil, &out,
+		func(session *sdkmcp.ClientSession) (any, error) {
+			return session.ListResources(ctx, &sdkmcp.ListResourcesParams{})
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out.Resources, nil
+}
+
+func (g *MCPFederationGateway) ReadConnectionResource(ctx context.Context, botID string, connection mcpgw.Connection, uri string) ([]mcpgw.ResourceContent, error) {
+	var out struct {
+		Contents []mcpgw.ResourceContent `json:"contents"`
+	}
+	err := g.callConnection(ctx, botID, connection, "resources/read", map[string]any{"uri": uri}, &out,
+		func(session *sdkmcp.ClientSession) (any, error) {
+			return session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: uri})
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out.Contents, nil
+}
+
+func (g *MCPFederationGateway) ListConnectionPrompts(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.PromptDescriptor, error) {
+	var out struct {
+		Prompts []mcpgw.PromptDescriptor `json:"prompts"`
+	}
+	err := g.callConnection(ctx, botID, connection, "prompts/list", nil, &out,
+		func(session *sdkmcp.ClientSession) (any, error) {
+			return session.ListPrompts(ctx, &sdkmcp.ListPromptsParams{})
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out.Prompts, nil
+}
+
+func (g *MCPFederationGateway) GetConnectionPrompt(ctx context.Context, botID string, connection mcpgw.Connection, name string, arguments map[string]string) (mcpgw.PromptResult, error) {
+	var out mcpgw.PromptResult
+	err := g.callConnection(ctx, botID, connection, "prompts/get", map[string]any{"name": name, "arguments": arguments}, &out,
+		func(session *sdkmcp.ClientSession) (any, error) {
+			return session.GetPrompt(ctx, &sdkmcp.GetPromptParams{Name: name, Arguments: arguments})
+		})
+	if err != nil {
+		return mcpgw.PromptResult{}, err
+	}
+	return out, nil
+}
+
+// callConnection runs one request on the pooled session of a connection and
+// decodes its result into out. Remote connections go through the SDK client;
+// stdio connections speak raw JSON-RPC to the process in the bot container.
+func (g *MCPFederationGateway) callConnection(ctx context.Context, botID string, connection mcpgw.Connection, method string, params any, out any, remote func(*sdkmcp.ClientSession) (any, error)) error {
+	var result any
+	err := g.withSession(ctx, botID, connection.Type, connection, true, func(session *federationSession) error {
+		if session.remote != nil {
+			var err error
+			result, err = remote(session.remote)
+			return err
+		}
+		req := mcpgw.JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      g.nextRequestID(method),
+			Method:  method,
+		}
+		if params != nil {
+			raw, err := json.Marshal(params)
+			if err != nil {
+				return err
+			}
+			req.Params = raw
+		}
+		payload, err := session.stdio.call(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := mcpgw.PayloadError(payload); err != nil {
+			return err
+		}
+		result = payload["result"]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
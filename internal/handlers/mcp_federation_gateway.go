@@ -4,20 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// OAuthTokenSource hands out access tokens for OAuth-authorized connections.
+type OAuthTokenSource interface {
+	AccessToken(ctx context.Context, connection mcpgw.Connection) (string, error)
+}
+
+// SecretExpander resolves ${secret:name} references against a bot's secrets.
+// ReferenceVersions identifies the versions of the referenced secrets.
+type SecretExpander interface {
+	ExpandMap(ctx context.Context, botID string, values map[string]string) (map[string]string, error)
+	ReferenceVersions(ctx context.Context, botID string, values ...string) (string, error)
+}
+
 type MCPFederationGateway struct {
 	handler *ContainerdHandler
 	logger  *slog.Logger
 	client  *http.Client
+	oauth   OAuthTokenSource
+	secrets SecretExpander
+
+	sessionsMu    sync.Mutex
+	sessions      map[string]*federationSession
+	dials         map[string]*federationDial
+	closed        bool
+	stop          chan struct{}
+	janitorOnce   sync.Once
+	requestSeq    atomic.Uint64
+	onListChanged func(botID string)
 }
 
 func NewMCPFederationGateway(log *slog.Logger, handler *ContainerdHandler) *MCPFederationGateway {
@@ -27,101 +51,163 @@ func NewMCPFederationGateway(log *slog.Logger, handler *ContainerdHandler) *MCPF
 	return &MCPFederationGateway{
 		handler: handler,
 		logger:  log.With(slog.String("gateway", "mcp_federation")),
+		// Pooled sessions keep event streams open, so only the wait for
+		// response headers is bounded; requests are bounded by their context.
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 30 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+			},
 		},
 	}
 }
 
+// SetOAuthTokenSource enables bearer tokens for connections with oauth auth.
+func (g *MCPFederationGateway) SetOAuthTokenSource(source OAuthTokenSource) {
+	g.oauth = source
+}
+
+// SetSecretExpander enables secret references in connection env and headers.
+func (g *MCPFederationGateway) SetSecretExpander(expander SecretExpander) {
+	g.secrets = expander
+}
+
+// SetListChangedHandler registers a callback invoked with the bot ID when a
+// downstream server announces that its tools, prompts or resources changed.
+func (g *MCPFederationGateway) SetListChangedHandler(handler func(botID string)) {
+	g.sessionsMu.Lock()
+	g.onListChanged = handler
+	g.sessionsMu.Unlock()
+}
+
 func (g *MCPFederationGateway) ListHTTPConnectionTools(ctx context.Context, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
-	session, err := g.connectStreamableSession(ctx, connection)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = session.Close() }()
-	result, err := session.ListTools(ctx, &sdkmcp.ListToolsParams{})
-	if err != nil {
-		return nil, err
-	}
-	return convertSDKTools(result.Tools), nil
+	return g.listConnectionTools(ctx, connection.BotID, "http", connection)
 }
 
 func (g *MCPFederationGateway) CallHTTPConnectionTool(ctx context.Context, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
-	session, err := g.connectStreamableSession(ctx, connection)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = session.Close() }()
-	result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
-		Name:      strings.TrimSpace(toolName),
-		Arguments: args,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return wrapSDKToolResult(result)
+	return g.callConnectionTool(ctx, connection.BotID, "http", connection, toolName, args)
 }
 
 func (g *MCPFederationGateway) ListSSEConnectionTools(ctx context.Context, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
-	session, err := g.connectSSESession(ctx, connection)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = session.Close() }()
-	result, err := session.ListTools(ctx, &sdkmcp.ListToolsParams{})
-	if err != nil {
-		return nil, err
-	}
-	return convertSDKTools(result.Tools), nil
+	return g.listConnectionTools(ctx, connection.BotID, "sse", connection)
 }
 
 func (g *MCPFederationGateway) CallSSEConnectionTool(ctx context.Context, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
-	session, err := g.connectSSESession(ctx, connection)
+	return g.callConnectionTool(ctx, connection.BotID, "sse", connection, toolName, args)
+}
+
+func (g *MCPFederationGateway) ListStdioConnectionTools(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
+	return g.listConnectionTools(ctx, botID, "stdio", connection)
+}
+
+func (g *MCPFederationGateway) CallStdioConnectionTool(ctx context.Context, botID string, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
+	return g.callConnectionTool(ctx, botID, "stdio", connection, toolName, args)
+}
+
+func (g *MCPFederationGateway) listConnectionTools(ctx context.Context, botID, transport string, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
+	var tools []mcpgw.ToolDescriptor
+	err := g.withSession(ctx, botID, transport, connection, true, func(session *federationSession) error {
+		if session.remote != nil {
+			result, err := session.remote.ListTools(ctx, &sdkmcp.ListToolsParams{})
+			if err != nil {
+				return err
+			}
+			tools = convertSDKTools(result.Tools)
+			return nil
+		}
+		payload, err := session.stdio.call(ctx, mcpgw.JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      g.nextRequestID("tools/list"),
+			Method:  "tools/list",
+		})
+		if err != nil {
+			return err
+		}
+		tools, err = parseGatewayToolsListPayload(payload)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = session.Close() }()
-	result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
-		Name:      strings.TrimSpace(toolName),
-		Arguments: args,
+	return tools, nil
+}
+
+func (g *MCPFederationGateway) callConnectionTool(ctx context.Context, botID, transport string, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
+	var out map[string]any
+	err := g.withSession(ctx, botID, transport, connection, false, func(session *federationSession) error {
+		if session.remote != nil {
+			result, err := session.remote.CallTool(ctx, &sdkmcp.CallToolParams{
+				Name:      strings.TrimSpace(toolName),
+				Arguments: args,
+			})
+			if err != nil {
+				return err
+			}
+			out, err = wrapSDKToolResult(result)
+			return err
+		}
+		params, err := json.Marshal(map[string]any{
+			"name":      toolName,
+			"arguments": args,
+		})
+		if err != nil {
+			return err
+		}
+		out, err = session.stdio.call(ctx, mcpgw.JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      g.nextRequestID("tools/call"),
+			Method:  "tools/call",
+			Params:  params,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return wrapSDKToolResult(result)
+	return out, nil
 }
 
-func (g *MCPFederationGateway) connectStreamableSession(ctx context.Context, connection mcpgw.Connection) (*sdkmcp.ClientSession, error) {
+func (g *MCPFederationGateway) newFederationClient(onListChanged func()) *sdkmcp.Client {
+	return sdkmcp.NewClient(&sdkmcp.Implementation{
+		Name:    "memoh-federation-client",
+		Version: "v1",
+	}, &sdkmcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *sdkmcp.ToolListChangedRequest) {
+			onListChanged()
+		},
+		PromptListChangedHandler: func(context.Context, *sdkmcp.PromptListChangedRequest) {
+			onListChanged()
+		},
+		ResourceListChangedHandler: func(context.Context, *sdkmcp.ResourceListChangedRequest) {
+			onListChanged()
+		},
+	})
+}
+
+func (g *MCPFederationGateway) connectStreamableSession(ctx context.Context, connection mcpgw.Connection, httpClient *http.Client, client *sdkmcp.Client) (*sdkmcp.ClientSession, error) {
 	url := strings.TrimSpace(anyToString(connection.Config["url"]))
 	if url == "" {
 		return nil, fmt.Errorf("http mcp url is required")
 	}
-	client := sdkmcp.NewClient(&sdkmcp.Implementation{
-		Name:    "memoh-federation-client",
-		Version: "v1",
-	}, nil)
 	transport := &sdkmcp.StreamableClientTransport{
 		Endpoint:   url,
-		HTTPClient: g.connectionHTTPClient(connection),
+		HTTPClient: httpClient,
 		MaxRetries: -1,
 	}
 	return client.Connect(ctx, transport, nil)
 }
 
-func (g *MCPFederationGateway) connectSSESession(ctx context.Context, connection mcpgw.Connection) (*sdkmcp.ClientSession, error) {
+func (g *MCPFederationGateway) connectSSESession(ctx context.Context, connection mcpgw.Connection, httpClient *http.Client, client *sdkmcp.Client) (*sdkmcp.ClientSession, error) {
 	endpoints := resolveSSEEndpointCandidates(connection.Config)
 	if len(endpoints) == 0 {
 		return nil, fmt.Errorf("sse mcp url is required")
 	}
 	var lastErr error
 	for _, endpoint := range endpoints {
-		client := sdkmcp.NewClient(&sdkmcp.Implementation{
-			Name:    "memoh-federation-client",
-			Version: "v1",
-		}, nil)
 		transport := &sdkmcp.SSEClientTransport{
 			Endpoint:   endpoint,
-			HTTPClient: g.connectionHTTPClient(connection),
+			HTTPClient: httpClient,
 		}
 		session, err := client.Connect(ctx, transport, nil)
 		if err == nil {
@@ -186,12 +272,36 @@ func resolveSSEEndpointCandidates(config map[string]any) []string {
 	return out
 }
 
-func (g *MCPFederationGateway) connectionHTTPClient(connection mcpgw.Connection) *http.Client {
+// connectionHeaders returns the connection's static headers and, for OAuth
+// connections, a fresh bearer token.
+func (g *MCPFederationGateway) connectionHeaders(ctx context.Context, connection mcpgw.Connection) (map[string]string, error) {
+	static, err := g.expandSecrets(ctx, connection.BotID, normalizeHeaderMap(connection.Config["headers"]))
+	if err != nil {
+		return nil, fmt.Errorf("headers: %w", err)
+	}
+	headers := map[string]string{}
+	for key, value := range static {
+		headers[key] = value
+	}
+	if connection.UsesOAuth() {
+		if g.oauth == nil {
+			return nil, fmt.Errorf("mcp oauth is not configured")
+		}
+		token, err := g.oauth.AccessToken(ctx, connection)
+		if err != nil {
+			return nil, err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+	return headers, nil
+}
+
+// httpClientWithHeaders returns a client that sends headers on every request.
+func (g *MCPFederationGateway) httpClientWithHeaders(headers map[string]string) *http.Client {
 	base := g.client
 	if base == nil {
 		base = &http.Client{Timeout: 30 * time.Second}
 	}
-	headers := normalizeHeaderMap(connection.Config["headers"])
 	if len(headers) == 0 {
 		return base
 	}
@@ -210,46 +320,6 @@ func (g *MCPFederationGateway) connectionHTTPClient(connection mcpgw.Connection)
 	}
 }
 
-func (g *MCPFederationGateway) ListStdioConnectionTools(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
-	sess, err := g.startStdioConnectionSession(ctx, botID, connection)
-	if err != nil {
-		return nil, err
-	}
-	defer sess.closeWithError(io.EOF)
-
-	payload, err := sess.call(ctx, mcpgw.JSONRPCRequest{
-		JSONRPC: "2.0",
-		ID:      mcpgw.RawStringID("federated-stdio-tools-list"),
-		Method:  "tools/list",
-	})
-	if err != nil {
-		return nil, err
-	}
-	return parseGatewayToolsListPayload(payload)
-}
-
-func (g *MCPFederationGateway) CallStdioConnectionTool(ctx context.Context, botID string, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
-	sess, err := g.startStdioConnectionSession(ctx, botID, connection)
-	if err != nil {
-		return nil, err
-	}
-	defer sess.closeWithError(io.EOF)
-
-	params, err := json.Marshal(map[string]any{
-		"name":      toolName,
-		"arguments": args,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return sess.call(ctx, mcpgw.JSONRPCRequest{
-		JSONRPC: "2.0",
-		ID:      mcpgw.RawStringID("federated-stdio-tools-call"),
-		Method:  "tools/call",
-		Params:  params,
-	})
-}
-
 func (g *MCPFederationGateway) startStdioConnectionSession(ctx context.Context, botID string, connection mcpgw.Connection) (*mcpSession, error) {
 	if g.handler == nil {
 		return nil, fmt.Errorf("containerd handler not configured")
@@ -269,16 +339,45 @@ func (g *MCPFederationGateway) startStdioConnectionSession(ctx context.Context,
 	if command == "" {
 		return nil, fmt.Errorf("stdio mcp command is required")
 	}
+	env, err := g.expandSecrets(ctx, botID, normalizeStringMap(connection.Config["env"]))
+	if err != nil {
+		return nil, fmt.Errorf("env: %w", err)
+	}
 	request := MCPStdioRequest{
 		Name:    strings.TrimSpace(connection.Name),
 		Command: command,
 		Args:    normalizeStringSlice(connection.Config["args"]),
-		Env:     normalizeStringMap(connection.Config["env"]),
+		Env:     env,
 		Cwd:     strings.TrimSpace(anyToString(connection.Config["cwd"])),
 	}
 	return g.handler.startContainerdMCPCommandSession(ctx, containerID, request)
 }
 
+// expandSecrets resolves secret references in connection values. Without a
+// configured expander values are used as written.
+func (g *MCPFederationGateway) expandSecrets(ctx context.Context, botID string, values map[string]string) (map[string]string, error) {
+	if g.secrets == nil || len(values) == 0 {
+		return values, nil
+	}
+	return g.secrets.ExpandMap(ctx, botID, values)
+}
+
+// secretVersions identifies the secrets referenced in the connection's env
+// and headers, so a rotation opens a new session.
+func (g *MCPFederationGateway) secretVersions(ctx context.Context, connection mcpgw.Connection) (string, error) {
+	if g.secrets == nil {
+		return "", nil
+	}
+	values := make([]string, 0)
+	for _, value := range normalizeStringMap(connection.Config["env"]) {
+		values = append(values, value)
+	}
+	for _, value := range normalizeHeaderMap(connection.Config["headers"]) {
+		values = append(values, value)
+	}
+	return g.secrets.ReferenceVersions(ctx, connection.BotID, values...)
+}
+
 func parseGatewayToolsListPayload(payload map[string]any) ([]mcpgw.ToolDescriptor, error) {
 	if err := mcpgw.PayloadError(payload); err != nil {
 		return nil, err
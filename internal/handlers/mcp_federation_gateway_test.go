@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
@@ -53,6 +56,7 @@ func TestFederationGatewayHTTPConnectionViaSDK(t *testing.T) {
 	gateway := &MCPFederationGateway{
 		client: httpServer.Client(),
 	}
+	defer gateway.Close()
 	connection := mcpgw.Connection{
 		Config: map[string]any{
 			"url": httpServer.URL,
@@ -79,6 +83,56 @@ func TestFederationGatewayHTTPConnectionViaSDK(t *testing.T) {
 	assertEchoResult(t, payload, "hello-http")
 }
 
+type staticOAuthTokenSource struct {
+	token string
+	calls int
+}
+
+func (s *staticOAuthTokenSource) AccessToken(context.Context, mcpgw.Connection) (string, error) {
+	s.calls++
+	return s.token, nil
+}
+
+func TestFederationGatewayHTTPConnectionWithOAuth(t *testing.T) {
+	server := newTestMCPServer()
+	handler := sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return server
+	}, nil)
+	httpServer := httptest.NewServer(withAuthHeader(handler, "Bearer oauth-token"))
+	defer httpServer.Close()
+
+	gateway := &MCPFederationGateway{
+		client: httpServer.Client(),
+	}
+	defer gateway.Close()
+	connection := mcpgw.Connection{
+		Type: "http",
+		Config: map[string]any{
+			"url":  httpServer.URL,
+			"auth": "oauth",
+			"headers": map[string]any{
+				"Authorization": "Bearer stale-token",
+			},
+		},
+	}
+	if _, err := gateway.ListHTTPConnectionTools(context.Background(), connection); err == nil {
+		t.Fatal("expected oauth connection to fail without a token source")
+	}
+
+	source := &staticOAuthTokenSource{token: "oauth-token"}
+	gateway.SetOAuthTokenSource(source)
+	tools, err := gateway.ListHTTPConnectionTools(context.Background(), connection)
+	if err != nil {
+		t.Fatalf("list http tools failed: %v", err)
+	}
+	if len(tools) != 1 || source.calls != 1 {
+		t.Fatalf("unexpected tools %#v after %d token calls", tools, source.calls)
+	}
+	if headers := connection.Config["headers"].(map[string]any); headers["Authorization"] != "Bearer stale-token" {
+		t.Fatal("expected connection headers to stay untouched")
+	}
+}
+
 func TestFederationGatewaySSEConnectionViaSDK(t *testing.T) {
 	server := newTestMCPServer()
 	handler := sdkmcp.NewSSEHandler(func(*http.Request) *sdkmcp.Server {
@@ -90,6 +144,7 @@ func TestFederationGatewaySSEConnectionViaSDK(t *testing.T) {
 	gateway := &MCPFederationGateway{
 		client: httpServer.Client(),
 	}
+	defer gateway.Close()
 	connection := mcpgw.Connection{
 		Config: map[string]any{
 			"url": httpServer.URL,
@@ -116,6 +171,134 @@ func TestFederationGatewaySSEConnectionViaSDK(t *testing.T) {
 	assertEchoResult(t, payload, "hello-sse")
 }
 
+func TestFederationGatewayPoolsSessions(t *testing.T) {
+	server := newTestMCPServer()
+	handler := sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return server
+	}, nil)
+	var sessions atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Header.Get("Mcp-Session-Id") == "" {
+			sessions.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	gateway := &MCPFederationGateway{
+		client: httpServer.Client(),
+	}
+	defer gateway.Close()
+	changed := make(chan string, 4)
+	gateway.SetListChangedHandler(func(botID string) { changed <- botID })
+	connection := mcpgw.Connection{
+		ID:     "conn-1",
+		BotID:  "bot-1",
+		Config: map[string]any{"url": httpServer.URL},
+	}
+
+	ctx := context.Background()
+	for range 3 {
+		if _, err := gateway.ListHTTPConnectionTools(ctx, connection); err != nil {
+			t.Fatalf("list http tools failed: %v", err)
+		}
+	}
+	if _, err := gateway.CallHTTPConnectionTool(ctx, connection, "echo", map[string]any{"query": "x"}); err != nil {
+		t.Fatalf("call http tool failed: %v", err)
+	}
+	if got := sessions.Load(); got != 1 {
+		t.Fatalf("expected a single pooled session, got %d", got)
+	}
+
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "echo2"}, func(ctx context.Context, request *sdkmcp.CallToolRequest, input testToolInput) (*sdkmcp.CallToolResult, testToolOutput, error) {
+		return nil, testToolOutput{Echo: input.Query}, nil
+	})
+	select {
+	case botID := <-changed:
+		if botID != "bot-1" {
+			t.Fatalf("unexpected bot in list changed notification: %q", botID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected tools list changed notification")
+	}
+
+	// Editing the connection opens a fresh session.
+	connection.Config = map[string]any{"url": httpServer.URL, "headers": map[string]any{"X-Test": "1"}}
+	if _, err := gateway.ListHTTPConnectionTools(ctx, connection); err != nil {
+		t.Fatalf("list http tools failed: %v", err)
+	}
+	if got := sessions.Load(); got != 2 {
+		t.Fatalf("expected a new session after editing the connection, got %d", got)
+	}
+
+	// A session that died is replaced on the next request.
+	gateway.sessionsMu.Lock()
+	pooled := gateway.sessions[federationSessionKey("bot-1", connection)]
+	gateway.sessionsMu.Unlock()
+	pooled.close()
+	<-pooled.done
+	if _, err := gateway.ListHTTPConnectionTools(ctx, connection); err != nil {
+		t.Fatalf("list http tools after reconnect failed: %v", err)
+	}
+	if got := sessions.Load(); got != 3 {
+		t.Fatalf("expected a reconnect, got %d sessions", got)
+	}
+}
+
+type fakeSecretExpander struct {
+	version atomic.Int32
+}
+
+func (f *fakeSecretExpander) ExpandMap(_ context.Context, _ string, values map[string]string) (map[string]string, error) {
+	return values, nil
+}
+
+func (f *fakeSecretExpander) ReferenceVersions(_ context.Context, _ string, values ...string) (string, error) {
+	return fmt.Sprintf("token@%d", f.version.Load()), nil
+}
+
+func TestFederationGatewayReopensSessionOnSecretRotation(t *testing.T) {
+	server := newTestMCPServer()
+	handler := sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return server
+	}, nil)
+	var sessions atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Header.Get("Mcp-Session-Id") == "" {
+			sessions.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	expander := &fakeSecretExpander{}
+	expander.version.Store(1)
+	gateway := &MCPFederationGateway{
+		client: httpServer.Client(),
+	}
+	gateway.SetSecretExpander(expander)
+	defer gateway.Close()
+	connection := mcpgw.Connection{
+		ID:     "conn-1",
+		BotID:  "bot-1",
+		Config: map[string]any{"url": httpServer.URL, "headers": map[string]any{"X-Token": "${secret:token}"}},
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := gateway.ListHTTPConnectionTools(ctx, connection); err != nil {
+			t.Fatalf("list http tools failed: %v", err)
+		}
+	}
+	expander.version.Store(2)
+	if _, err := gateway.ListHTTPConnectionTools(ctx, connection); err != nil {
+		t.Fatalf("list http tools failed: %v", err)
+	}
+	if got := sessions.Load(); got != 2 {
+		t.Fatalf("expected a new session after rotating the secret, got %d sessions", got)
+	}
+}
+
 func TestResolveSSEEndpointCandidatesCompatibility(t *testing.T) {
 	tests := []struct {
 		name      string
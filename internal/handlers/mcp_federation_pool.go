@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// federationSessionIdleTTL is how long an unused session is kept open.
+	federationSessionIdleTTL = 10 * time.Minute
+	// federationSessionCheckInterval is how often idle sessions are pinged.
+	federationSessionCheckInterval = time.Minute
+	// federationSessionDialTimeout bounds connecting and, for stdio servers,
+	// booting the process inside the bot container.
+	federationSessionDialTimeout = 2 * time.Minute
+	federationSessionPingTimeout = 10 * time.Second
+)
+
+var errFederationGatewayClosed = errors.New("mcp federation gateway closed")
+
+// federationSession is a live session to one connection of one bot, shared
+// by every list and call until it fails, idles out or the connection changes.
+type federationSession struct {
+	key         string
+	fingerprint string
+	remote      *sdkmcp.ClientSession
+	stdio       *mcpSession
+	cancel      context.CancelFunc
+	done        <-chan struct{}
+	lastUsedAt  time.Time
+}
+
+type federationDial struct {
+	done    chan struct{}
+	session *federationSession
+	err     error
+}
+
+func (s *federationSession) alive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *federationSession) close() {
+	if s.remote != nil {
+		_ = s.remote.Close()
+	}
+	if s.stdio != nil {
+		s.stdio.closeWithError(io.EOF)
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// Close shuts down every pooled session.
+func (g *MCPFederationGateway) Close() {
+	g.sessionsMu.Lock()
+	if g.closed {
+		g.sessionsMu.Unlock()
+		return
+	}
+	g.closed = true
+	sessions := g.sessions
+	g.sessions = map[string]*federationSession{}
+	if g.stop != nil {
+		close(g.stop)
+	}
+	g.sessionsMu.Unlock()
+	for _, session := range sessions {
+		session.close()
+	}
+}
+
+// withSession runs fn on the pooled session of a connection. When the
+// session turns out to be dead it is dropped, and fn is retried once on a
+// fresh session if the request is idempotent or never reached the server.
+func (g *MCPFederationGateway) withSession(ctx context.Context, botID, transport string, connection mcpgw.Connection, idempotent bool, fn func(*federationSession) error) error {
+	for attempt := 0; ; attempt++ {
+		session, err := g.acquireSession(ctx, botID, transport, connection)
+		if err != nil {
+			return err
+		}
+		err = fn(session)
+		if err == nil {
+			return nil
+		}
+		if session.alive() && !isSessionTransportError(err) {
+			return err
+		}
+		g.dropSession(session)
+		if attempt > 0 || ctx.Err() != nil || !(idempotent || errors.Is(err, sdkmcp.ErrConnectionClosed)) {
+			return err
+		}
+		g.log().Warn("mcp federation session lost, reconnecting",
+			slog.String("bot_id", botID),
+			slog.String("connection_id", connection.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// acquireSession returns the live session of a connection, dialing a new one
+// when there is none or the connection settings changed since it was opened.
+// Concurrent callers share a single dial.
+func (g *MCPFederationGateway) acquireSession(ctx context.Context, botID, transport string, connection mcpgw.Connection) (*federationSession, error) {
+	transport = strings.ToLower(strings.TrimSpace(transport))
+	var headers map[string]string
+	if transport == "http" || transport == "sse" {
+		var err error
+		if headers, err = g.connectionHeaders(ctx, connection); err != nil {
+			return nil, err
+		}
+	}
+	versions, err := g.secretVersions(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	key := federationSessionKey(botID, connection)
+	fingerprint := federationSessionFingerprint(transport, connection, headers, versions)
+	g.startJanitor()
+
+	g.sessionsMu.Lock()
+	if g.closed {
+		g.sessionsMu.Unlock()
+		return nil, errFederationGatewayClosed
+	}
+	if g.sessions == nil {
+		g.sessions = map[string]*federationSession{}
+	}
+	if g.dials == nil {
+		g.dials = map[string]*federationDial{}
+	}
+	if current := g.sessions[key]; current != nil {
+		if current.fingerprint == fingerprint && current.alive() {
+			current.lastUsedAt = time.Now()
+			g.sessionsMu.Unlock()
+			return current, nil
+		}
+		delete(g.sessions, key)
+		go current.close()
+	}
+	pending := g.dials[key]
+	if pending == nil {
+		pending = &federationDial{done: make(chan struct{})}
+		g.dials[key] = pending
+		go g.dialSession(context.WithoutCancel(ctx), key, fingerprint, botID, transport, connection, headers, pending)
+	}
+	g.sessionsMu.Unlock()
+
+	select {
+	case <-pending.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if pending.err != nil {
+		return nil, pending.err
+	}
+	return pending.session, nil
+}
+
+// dialSession opens a session detached from the caller's context, since it
+// outlives the request that triggered it.
+func (g *MCPFederationGateway) dialSession(parent context.Context, key, fingerprint, botID, transport string, connection mcpgw.Connection, headers map[string]string, pending *federationDial) {
+	ctx, cancel := context.WithCancel(parent)
+	timer := time.AfterFunc(federationSessionDialTimeout, cancel)
+	session, err := g.openSession(ctx, botID, transport, connection, headers)
+	if !timer.Stop() && err == nil {
+		session.close()
+		err = fmt.Errorf("connect mcp %s timed out", connection.Name)
+	}
+	if err != nil {
+		cancel()
+	} else {
+		session.key = key
+		session.fingerprint = fingerprint
+		session.cancel = cancel
+		session.lastUsedAt = time.Now()
+	}
+
+	g.sessionsMu.Lock()
+	delete(g.dials, key)
+	if err == nil {
+		if g.closed {
+			session.close()
+			err = errFederationGatewayClosed
+		} else {
+			g.sessions[key] = session
+		}
+	}
+	g.sessionsMu.Unlock()
+
+	pending.session, pending.err = session, err
+	close(pending.done)
+	if err != nil {
+		g.log().Warn("mcp federation connect failed",
+			slog.String("bot_id", botID),
+			slog.String("connection_id", connection.ID),
+			slog.Any("error", err),
+		)
+		return
+	}
+	go func() {
+		<-session.done
+		g.dropSession(session)
+	}()
+}
+
+func (g *MCPFederationGateway) openSession(ctx context.Context, botID, transport string, connection mcpgw.Connection, headers map[string]string) (*federationSession, error) {
+	onListChanged := func() { g.notifyListChanged(botID) }
+	switch transport {
+	case "http", "sse":
+		var (
+			remote *sdkmcp.ClientSession
+			err    error
+		)
+		httpClient := g.httpClientWithHeaders(headers)
+		client := g.newFederationClient(onListChanged)
+		if transport == "sse" {
+			remote, err = g.connectSSESession(ctx, connection, httpClient, client)
+		} else {
+			remote, err = g.connectStreamableSession(ctx, connection, httpClient, client)
+		}
+		if err != nil {
+			return nil, err
+		}
+		done := make(chan struct{})
+		go func() {
+			_ = remote.Wait()
+			close(done)
+		}()
+		return &federationSession{remote: remote, done: done}, nil
+	case "stdio":
+		stdio, err := g.startStdioConnectionSession(ctx, botID, connection)
+		if err != nil {
+			return nil, err
+		}
+		stdio.setNotificationHandler(func(method string) {
+			switch method {
+			case "notifications/tools/list_changed", "notifications/prompts/list_changed", "notifications/resources/list_changed":
+				onListChanged()
+			}
+		})
+		return &federationSession{stdio: stdio, done: stdio.closed}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mcp connection type: %s", transport)
+	}
+}
+
+// dropSession removes a session from the pool and closes it.
+func (g *MCPFederationGateway) dropSession(session *federationSession) {
+	g.sessionsMu.Lock()
+	if current, ok := g.sessions[session.key]; ok && current == session {
+		delete(g.sessions, session.key)
+	}
+	g.sessionsMu.Unlock()
+	session.close()
+}
+
+func (g *MCPFederationGateway) notifyListChanged(botID string) {
+	g.sessionsMu.Lock()
+	handler := g.onListChanged
+	g.sessionsMu.Unlock()
+	if handler != nil && strings.TrimSpace(botID) != "" {
+		handler(botID)
+	}
+}
+
+func (g *MCPFederationGateway) startJanitor() {
+	g.janitorOnce.Do(func() {
+		g.sessionsMu.Lock()
+		if g.closed {
+			g.sessionsMu.Unlock()
+			return
+		}
+		g.stop = make(chan struct{})
+		stop := g.stop
+		g.sessionsMu.Unlock()
+		go func() {
+			ticker := time.NewTicker(federationSessionCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					g.checkSessions()
+				}
+			}
+		}()
+	})
+}
+
+// checkSessions evicts sessions idle for longer than the idle TTL and pings
+// the others that were not used since the last check.
+func (g *MCPFederationGateway) checkSessions() {
+	now := time.Now()
+	var idle, stale []*federationSession
+	g.sessionsMu.Lock()
+	for _, session := range g.sessions {
+		switch unused := now.Sub(session.lastUsedAt); {
+		case unused >= federationSessionIdleTTL:
+			idle = append(idle, session)
+		case unused >= federationSessionCheckInterval:
+			stale = append(stale, session)
+		}
+	}
+	g.sessionsMu.Unlock()
+
+	for _, session := range idle {
+		g.dropSession(session)
+	}
+	for _, session := range stale {
+		ctx, cancel := context.WithTimeout(context.Background(), federationSessionPingTimeout)
+		err := g.pingSession(ctx, session)
+		cancel()
+		if err != nil {
+			g.log().Warn("mcp federation session health check failed",
+				slog.String("session", session.key),
+				slog.Any("error", err),
+			)
+			g.dropSession(session)
+		}
+	}
+}
+
+func (g *MCPFederationGateway) pingSession(ctx context.Context, session *federationSession) error {
+	if !session.alive() {
+		return io.EOF
+	}
+	if session.remote != nil {
+		return session.remote.Ping(ctx, nil)
+	}
+	payload, err := session.stdio.call(ctx, mcpgw.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      g.nextRequestID("ping"),
+		Method:  "ping",
+	})
+	if err != nil {
+		return err
+	}
+	return mcpgw.PayloadError(payload)
+}
+
+func (g *MCPFederationGateway) log() *slog.Logger {
+	if g.logger == nil {
+		return slog.Default()
+	}
+	return g.logger
+}
+
+// nextRequestID returns a JSON-RPC id that is unique within the gateway, as
+// requests of concurrent callers share the same stdio session.
+func (g *MCPFederationGateway) nextRequestID(method string) json.RawMessage {
+	return mcpgw.RawStringID(fmt.Sprintf("federated-%s-%d", strings.ReplaceAll(method, "/", "-"), g.requestSeq.Add(1)))
+}
+
+func federationSessionKey(botID string, connection mcpgw.Connection) string {
+	id := strings.TrimSpace(connection.ID)
+	if id == "" {
+		id = strings.TrimSpace(connection.Name)
+	}
+	return strings.TrimSpace(botID) + "/" + id
+}
+
+// federationSessionFingerprint identifies the settings a session was opened
+// with, so edited connections, rotated tokens and rotated secrets get a new
+// session.
+func federationSessionFingerprint(transport string, connection mcpgw.Connection, headers map[string]string, secretVersions string) string {
+	raw, _ := json.Marshal(map[string]any{
+		"type":    transport,
+		"config":  connection.Config,
+		"headers": headers,
+		"secrets": secretVersions,
+	})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func isSessionTransportError(err error) bool {
+	if errors.Is(err, sdkmcp.ErrConnectionClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+		return true
+	}
+	return strings.Contains(err.Error(), "session not found")
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/mcp"
+	mcpoauth "github.com/memohai/memoh/internal/mcp/oauth"
+)
+
+const mcpOAuthCallbackPath = "/mcp/oauth/callback"
+
+// MCPOAuthAuthorizeRequest starts the OAuth flow of a connection.
+type MCPOAuthAuthorizeRequest struct {
+	ReturnURL string `json:"return_url,omitempty"`
+}
+
+// MCPOAuthAuthorizeResponse carries the URL the user has to open.
+type MCPOAuthAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SetOAuth enables OAuth authorization of remote connections. publicURL is
+// the externally reachable base URL used for the callback; when empty the
+// request host is used.
+func (h *MCPHandler) SetOAuth(service *mcpoauth.Service, publicURL string) {
+	h.oauth = service
+	h.publicURL = strings.TrimRight(strings.TrimSpace(publicURL), "/")
+}
+
+// AuthorizeOAuth godoc
+// @Summary Start MCP OAuth authorization
+// @Description Discover the authorization server of an OAuth MCP connection, register a client and return the URL to open in the browser
+// @Tags mcp
+// @Param id path string true "MCP ID"
+// @Param payload body MCPOAuthAuthorizeRequest false "Authorization options"
+// @Success 200 {object} MCPOAuthAuthorizeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp/{id}/oauth/authorize [post]
+func (h *MCPHandler) AuthorizeOAuth(c echo.Context) error {
+	conn, err := h.requireOAuthConnection(c)
+	if err != nil {
+		return err
+	}
+	var req MCPOAuthAuthorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	returnURL := strings.TrimSpace(req.ReturnURL)
+	if returnURL != "" && !h.allowedReturnURL(c, returnURL) {
+		return echo.NewHTTPError(http.StatusBadRequest, "return_url must be a relative path or on this host")
+	}
+	authURL, err := h.oauth.StartAuthorization(c.Request().Context(), conn, h.oauthRedirectURI(c), returnURL)
+	if err != nil {
+		if errors.Is(err, mcpoauth.ErrNotOAuth) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	return c.JSON(http.StatusOK, MCPOAuthAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// OAuthStatus godoc
+// @Summary Get MCP OAuth status
+// @Description Report whether an OAuth MCP connection holds stored tokens
+// @Tags mcp
+// @Param id path string true "MCP ID"
+// @Success 200 {object} mcpoauth.AuthorizationStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp/{id}/oauth [get]
+func (h *MCPHandler) OAuthStatus(c echo.Context) error {
+	conn, err := h.requireOAuthConnection(c)
+	if err != nil {
+		return err
+	}
+	status, err := h.oauth.Status(c.Request().Context(), conn)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// RevokeOAuth godoc
+// @Summary Revoke MCP OAuth authorization
+// @Description Delete the stored tokens of an OAuth MCP connection
+// @Tags mcp
+// @Param id path string true "MCP ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/mcp/{id}/oauth [delete]
+func (h *MCPHandler) RevokeOAuth(c echo.Context) error {
+	conn, err := h.requireOAuthConnection(c)
+	if err != nil {
+		return err
+	}
+	if err := h.oauth.Revoke(c.Request().Context(), conn); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// OAuthCallback godoc
+// @Summary MCP OAuth callback
+// @Description Redirect target of the authorization server. Exchanges the code and returns the browser to the page that started the flow.
+// @Tags mcp
+// @Param state query string true "OAuth state"
+// @Param code query string false "Authorization code"
+// @Param error query string false "Authorization error"
+// @Success 302 "Found"
+// @Success 200 "Result page"
+// @Router /mcp/oauth/callback [get]
+func (h *MCPHandler) OAuthCallback(c echo.Context) error {
+	if h.oauth == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "mcp oauth is not configured")
+	}
+	state := strings.TrimSpace(c.QueryParam("state"))
+	code := strings.TrimSpace(c.QueryParam("code"))
+	if denied := strings.TrimSpace(c.QueryParam("error")); denied != "" {
+		// Still consume the state so it cannot be replayed.
+		completion, _ := h.oauth.CompleteAuthorization(c.Request().Context(), state, "")
+		return h.finishOAuth(c, completion.ReturnURL, errors.New(denied))
+	}
+	completion, err := h.oauth.CompleteAuthorization(c.Request().Context(), state, code)
+	if err != nil {
+		h.logger.Warn("mcp oauth callback failed",
+			slog.String("bot_id", completion.BotID),
+			slog.String("connection_id", completion.ConnectionID),
+			slog.Any("error", err))
+	}
+	return h.finishOAuth(c, completion.ReturnURL, err)
+}
+
+func (h *MCPHandler) finishOAuth(c echo.Context, returnURL string, err error) error {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	if returnURL != "" {
+		target, parseErr := url.Parse(returnURL)
+		if parseErr == nil {
+			query := target.Query()
+			query.Set("mcp_oauth", result)
+			target.RawQuery = query.Encode()
+			return c.Redirect(http.StatusFound, target.String())
+		}
+	}
+	message := "Authorization completed. You can close this window."
+	status := http.StatusOK
+	if err != nil {
+		message = "Authorization failed: " + err.Error()
+		status = http.StatusBadRequest
+	}
+	return c.HTML(status, "<!doctype html><html><body><p>"+html.EscapeString(message)+"</p></body></html>")
+}
+
+// requireOAuthConnection authorizes the caller and loads the connection
+// addressed by the route.
+func (h *MCPHandler) requireOAuthConnection(c echo.Context) (mcp.Connection, error) {
+	if h.oauth == nil {
+		return mcp.Connection{}, echo.NewHTTPError(http.StatusServiceUnavailable, "mcp oauth is not configured")
+	}
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return mcp.Connection{}, err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return mcp.Connection{}, echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return mcp.Connection{}, err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return mcp.Connection{}, echo.NewHTTPError(http.StatusBadRequest, "id is required")
+	}
+	conn, err := h.service.Get(c.Request().Context(), botID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return mcp.Connection{}, echo.NewHTTPError(http.StatusNotFound, "mcp connection not found")
+		}
+		return mcp.Connection{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !conn.UsesOAuth() {
+		return mcp.Connection{}, echo.NewHTTPError(http.StatusBadRequest, mcpoauth.ErrNotOAuth.Error())
+	}
+	return conn, nil
+}
+
+func (h *MCPHandler) oauthBaseURL(c echo.Context) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+func (h *MCPHandler) oauthRedirectURI(c echo.Context) string {
+	return h.oauthBaseURL(c) + mcpOAuthCallbackPath
+}
+
+// allowedReturnURL keeps the callback from redirecting to foreign sites.
+func (h *MCPHandler) allowedReturnURL(c echo.Context, raw string) bool {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if target.Scheme == "" && target.Host == "" {
+		return strings.HasPrefix(target.Path, "/") && !strings.HasPrefix(raw, "//")
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return false
+	}
+	if strings.EqualFold(target.Host, c.Request().Host) {
+		return true
+	}
+	base, err := url.Parse(h.oauthBaseURL(c))
+	return err == nil && strings.EqualFold(target.Host, base.Host)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMCPHandlerOAuthURLs(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/bots/bot-1/mcp/conn-1/oauth/authorize", nil)
+	req.Host = "memoh.local:8080"
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	h := &MCPHandler{}
+	if got := h.oauthRedirectURI(c); got != "http://memoh.local:8080/mcp/oauth/callback" {
+		t.Fatalf("unexpected redirect uri %q", got)
+	}
+	h.publicURL = "https://memoh.example.com"
+	if got := h.oauthRedirectURI(c); got != "https://memoh.example.com/mcp/oauth/callback" {
+		t.Fatalf("expected public url redirect, got %q", got)
+	}
+
+	cases := []struct {
+		returnURL string
+		allowed   bool
+	}{
+		{returnURL: "/bots/bot-1/mcp", allowed: true},
+		{returnURL: "http://memoh.local:8080/bots", allowed: true},
+		{returnURL: "https://memoh.example.com/bots", allowed: true},
+		{returnURL: "//evil.example.com/x", allowed: false},
+		{returnURL: "https://evil.example.com/x", allowed: false},
+		{returnURL: "javascript:alert(1)", allowed: false},
+		{returnURL: "relative/path", allowed: false},
+	}
+	for _, tc := range cases {
+		if got := h.allowedReturnURL(c, tc.returnURL); got != tc.allowed {
+			t.Fatalf("%s: expected allowed=%v, got %v", tc.returnURL, tc.allowed, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+// SetToolCalls enables the tool call audit log endpoints.
+func (h *MCPHandler) SetToolCalls(service *mcp.ToolCallLogService) {
+	h.toolCalls = service
+}
+
+// ListToolCalls godoc
+// @Summary List tool calls
+// @Description List the most recent tool calls of a bot with redacted arguments, duration and outcome
+// @Tags mcp
+// @Param tool query string false "Only calls of this tool"
+// @Param status query string false "Only calls with this status (ok, error, denied, not_found)"
+// @Param before query string false "Only calls before this time (RFC3339 or epoch millis)"
+// @Param limit query int false "Max items to return" default(50)
+// @Success 200 {object} mcp.ToolCallListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/tool-calls [get]
+func (h *MCPHandler) ListToolCalls(c echo.Context) error {
+	botID, err := h.requireToolCallsBot(c)
+	if err != nil {
+		return err
+	}
+	filter := mcp.ToolCallListFilter{
+		Tool:   strings.TrimSpace(c.QueryParam("tool")),
+		Status: strings.TrimSpace(c.QueryParam("status")),
+		Limit:  parseIntOr(c.QueryParam("limit"), 0),
+	}
+	if raw := strings.TrimSpace(c.QueryParam("before")); raw != "" {
+		before, ok := parseBeforeParam(raw)
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid before")
+		}
+		filter.Before = before
+	}
+	items, err := h.toolCalls.List(c.Request().Context(), botID, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, mcp.ToolCallListResponse{Items: items})
+}
+
+// ToolCallStats godoc
+// @Summary Tool usage stats
+// @Description Aggregate call count, error rate and latency per tool; sort by calls, slowest or failing
+// @Tags mcp
+// @Param since query string false "Window start (RFC3339 or epoch millis), defaults to 7 days ago"
+// @Param sort query string false "Ordering: calls, slowest or failing" default(calls)
+// @Param limit query int false "Max items to return"
+// @Success 200 {object} mcp.ToolUsageStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/tool-calls/stats [get]
+func (h *MCPHandler) ToolCallStats(c echo.Context) error {
+	botID, err := h.requireToolCallsBot(c)
+	if err != nil {
+		return err
+	}
+	query := mcp.ToolUsageStatsQuery{
+		Limit: parseIntOr(c.QueryParam("limit"), 0),
+	}
+	switch sortBy := strings.ToLower(strings.TrimSpace(c.QueryParam("sort"))); sortBy {
+	case "", mcp.ToolStatsSortCalls, mcp.ToolStatsSortSlowest, mcp.ToolStatsSortFailing:
+		query.Sort = sortBy
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sort")
+	}
+	if raw := strings.TrimSpace(c.QueryParam("since")); raw != "" {
+		since, ok := parseBeforeParam(raw)
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid since")
+		}
+		query.Since = since
+	}
+	stats, err := h.toolCalls.Stats(c.Request().Context(), botID, query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (h *MCPHandler) requireToolCallsBot(c echo.Context) (string, error) {
+	if h.toolCalls == nil {
+		return "", echo.NewHTTPError(http.StatusServiceUnavailable, "tool call log not configured")
+	}
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
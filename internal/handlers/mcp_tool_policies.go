@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+// SetToolPolicies enables the tool policy endpoints.
+func (h *MCPHandler) SetToolPolicies(service *mcp.ToolPolicyService) {
+	h.toolPolicies = service
+}
+
+// ListToolPolicies godoc
+// @Summary List tool policies
+// @Description List the tool allowlist and per-tool permissions of a bot
+// @Tags mcp
+// @Success 200 {object} mcp.ToolPolicyListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/tool-policies [get]
+func (h *MCPHandler) ListToolPolicies(c echo.Context) error {
+	botID, err := h.requireToolPolicyBot(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.toolPolicies.List(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, mcp.ToolPolicyListResponse{Items: items})
+}
+
+// UpsertToolPolicy godoc
+// @Summary Create or replace a tool policy
+// @Description Enable or disable a tool (or tool pattern such as mcp_github_*) and restrict it by conversation type, member role and argument values
+// @Tags mcp
+// @Param payload body mcp.ToolPolicyUpsertRequest true "Tool policy"
+// @Success 200 {object} mcp.ToolPolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/tool-policies [put]
+func (h *MCPHandler) UpsertToolPolicy(c echo.Context) error {
+	botID, err := h.requireToolPolicyBot(c)
+	if err != nil {
+		return err
+	}
+	var req mcp.ToolPolicyUpsertRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	policy, err := h.toolPolicies.Upsert(c.Request().Context(), botID, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// DeleteToolPolicy godoc
+// @Summary Delete a tool policy
+// @Description Remove a tool policy; the tool falls back to the next matching pattern or is allowed
+// @Tags mcp
+// @Param id path string true "Tool policy ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/tool-policies/{id} [delete]
+func (h *MCPHandler) DeleteToolPolicy(c echo.Context) error {
+	botID, err := h.requireToolPolicyBot(c)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "id is required")
+	}
+	if err := h.toolPolicies.Delete(c.Request().Context(), botID, id); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *MCPHandler) requireToolPolicyBot(c echo.Context) (string, error) {
+	if h.toolPolicies == nil {
+		return "", echo.NewHTTPError(http.StatusServiceUnavailable, "tool policies not configured")
+	}
+	userID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,16 +16,29 @@ import (
 )
 
 const (
-	headerChannelIdentityID = "X-Memoh-Channel-Identity-Id"
-	headerSessionToken      = "X-Memoh-Session-Token"
-	headerCurrentPlatform   = "X-Memoh-Current-Platform"
-	headerReplyTarget       = "X-Memoh-Reply-Target"
+	headerSessionToken    = "X-Memoh-Session-Token"
+	headerCurrentPlatform = "X-Memoh-Current-Platform"
+	headerReplyTarget     = "X-Memoh-Reply-Target"
 )
 
 func (h *ContainerdHandler) SetToolGatewayService(service *mcpgw.ToolGatewayService) {
 	h.toolGateway = service
 }
 
+// SetJWTSecret lets tool calls read the conversation route from the chat
+// session token the agent forwards.
+func (h *ContainerdHandler) SetJWTSecret(secret string) {
+	h.jwtSecret = secret
+}
+
+// invalidateToolResults drops the bot's cached tool results after its files
+// changed outside of tool calls.
+func (h *ContainerdHandler) invalidateToolResults(botID string) {
+	if h.toolGateway != nil {
+		h.toolGateway.InvalidateToolResults(botID)
+	}
+}
+
 // HandleMCPTools godoc
 // @Summary Unified MCP tools gateway
 // @Description MCP endpoint for tool discovery and invocation.
@@ -119,6 +133,12 @@ func (h *ContainerdHandler) buildToolMCPServer(ctx context.Context) *sdkmcp.Serv
 				Tools: &sdkmcp.ToolCapabilities{
 					ListChanged: false,
 				},
+				Resources: &sdkmcp.ResourceCapabilities{
+					ListChanged: false,
+				},
+				Prompts: &sdkmcp.PromptCapabilities{
+					ListChanged: false,
+				},
 			},
 		},
 	)
@@ -152,6 +172,45 @@ func (h *ContainerdHandler) toolGatewayMiddleware(session mcpgw.ToolSessionConte
 					return nil, err
 				}
 				return convertGatewayCallResultToSDK(result)
+			case "resources/list":
+				resources, err := h.toolGateway.ListResources(ctx, session)
+				if err != nil {
+					return nil, err
+				}
+				out := &sdkmcp.ListResourcesResult{}
+				return out, convertViaJSON(map[string]any{"resources": resources}, out)
+			case "resources/read":
+				readReq, ok := req.(*sdkmcp.ServerRequest[*sdkmcp.ReadResourceParams])
+				if !ok || readReq == nil || readReq.Params == nil {
+					return nil, fmt.Errorf("resources/read params is required")
+				}
+				contents, err := h.toolGateway.ReadResource(ctx, session, readReq.Params.URI)
+				if err != nil {
+					if errors.Is(err, mcpgw.ErrResourceNotFound) {
+						return nil, sdkmcp.ResourceNotFoundError(readReq.Params.URI)
+					}
+					return nil, err
+				}
+				out := &sdkmcp.ReadResourceResult{}
+				return out, convertViaJSON(map[string]any{"contents": contents}, out)
+			case "prompts/list":
+				prompts, err := h.toolGateway.ListPrompts(ctx, session)
+				if err != nil {
+					return nil, err
+				}
+				out := &sdkmcp.ListPromptsResult{}
+				return out, convertViaJSON(map[string]any{"prompts": prompts}, out)
+			case "prompts/get":
+				getReq, ok := req.(*sdkmcp.ServerRequest[*sdkmcp.GetPromptParams])
+				if !ok || getReq == nil || getReq.Params == nil {
+					return nil, fmt.Errorf("prompts/get params is required")
+				}
+				prompt, err := h.toolGateway.GetPrompt(ctx, session, getReq.Params.Name, getReq.Params.Arguments)
+				if err != nil {
+					return nil, err
+				}
+				out := &sdkmcp.GetPromptResult{}
+				return out, convertViaJSON(prompt, out)
 			default:
 				return next(ctx, method, req)
 			}
@@ -223,19 +282,42 @@ func convertGatewayCallResultToSDK(result map[string]any) (*sdkmcp.CallToolResul
 	return &out, nil
 }
 
+// convertViaJSON maps gateway types onto SDK result types, which share the
+// MCP wire format.
+func convertViaJSON(in any, out any) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, out)
+}
+
 func (h *ContainerdHandler) buildToolSessionContext(c echo.Context, botID string) mcpgw.ToolSessionContext {
-	channelIdentityID := strings.TrimSpace(c.Request().Header.Get(headerChannelIdentityID))
-	if channelIdentityID == "" {
-		if ctxIdentityID, err := auth.UserIDFromContext(c); err == nil {
-			channelIdentityID = strings.TrimSpace(ctxIdentityID)
+	session := mcpgw.ToolSessionContext{
+		BotID:           strings.TrimSpace(botID),
+		ChatID:          strings.TrimSpace(botID),
+		SessionToken:    strings.TrimSpace(c.Request().Header.Get(headerSessionToken)),
+		CurrentPlatform: strings.TrimSpace(c.Request().Header.Get(headerCurrentPlatform)),
+		ReplyTarget:     strings.TrimSpace(c.Request().Header.Get(headerReplyTarget)),
+	}
+	// Tool policies authorize by identity, conversation type and route, so
+	// they come from the signed session token only; callers cannot raise
+	// their role or claim another conversation by setting a header.
+	if session.SessionToken != "" && h.jwtSecret != "" {
+		if token, err := auth.ParseChatToken(session.SessionToken, h.jwtSecret); err == nil && strings.TrimSpace(token.BotID) == session.BotID {
+			session.ChannelIdentityID = strings.TrimSpace(token.ChannelIdentityID)
+			if session.ChannelIdentityID == "" {
+				session.ChannelIdentityID = strings.TrimSpace(token.UserID)
+			}
+			session.ConversationType = strings.TrimSpace(token.ConversationType)
+			session.RouteID = strings.TrimSpace(token.RouteID)
+			session.Scopes = token.Scopes
 		}
 	}
-	return mcpgw.ToolSessionContext{
-		BotID:             strings.TrimSpace(botID),
-		ChatID:            strings.TrimSpace(botID),
-		ChannelIdentityID: channelIdentityID,
-		SessionToken:      strings.TrimSpace(c.Request().Header.Get(headerSessionToken)),
-		CurrentPlatform:   strings.TrimSpace(c.Request().Header.Get(headerCurrentPlatform)),
-		ReplyTarget:       strings.TrimSpace(c.Request().Header.Get(headerReplyTarget)),
+	if session.ChannelIdentityID == "" {
+		if userID, err := auth.UserIDFromContext(c); err == nil {
+			session.ChannelIdentityID = strings.TrimSpace(userID)
+		}
 	}
+	return session
 }
@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/memohai/memoh/internal/auth"
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 )
 
@@ -104,12 +106,14 @@ func TestHandleMCPToolsWithGatewayAcceptCompatibility(t *testing.T) {
 	handler := &ContainerdHandler{
 		logger:      slog.Default(),
 		toolGateway: toolGateway,
+		jwtSecret:   "secret",
 	}
+	sessionToken := newTestChatToken(t, auth.ChatToken{BotID: "bot-1", ChatID: "bot-1", ChannelIdentityID: "user-1"})
 
 	listReq := httptest.NewRequest(http.MethodPost, "/bots/bot-1/tools", strings.NewReader(`{"jsonrpc":"2.0","id":"1","method":"tools/list"}`))
 	listReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	listReq.Header.Set("Accept", "application/json")
-	listReq.Header.Set("X-Memoh-Channel-Identity-Id", "user-1")
+	listReq.Header.Set(headerSessionToken, sessionToken)
 	listRec := httptest.NewRecorder()
 	listCtx := e.NewContext(listReq, listRec)
 
@@ -136,7 +140,7 @@ func TestHandleMCPToolsWithGatewayAcceptCompatibility(t *testing.T) {
 	callReq := httptest.NewRequest(http.MethodPost, "/bots/bot-1/tools", strings.NewReader(`{"jsonrpc":"2.0","id":"2","method":"tools/call","params":{"name":"echo_tool","arguments":{"input":"hello"}}}`))
 	callReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	callReq.Header.Set("Accept", "application/json")
-	callReq.Header.Set("X-Memoh-Channel-Identity-Id", "user-1")
+	callReq.Header.Set(headerSessionToken, sessionToken)
 	callRec := httptest.NewRecorder()
 	callCtx := e.NewContext(callReq, callRec)
 
@@ -163,3 +167,57 @@ func TestHandleMCPToolsWithGatewayAcceptCompatibility(t *testing.T) {
 		t.Fatalf("unexpected channel identity id: %#v", structured["channel_identity_id"])
 	}
 }
+
+func newTestChatToken(t *testing.T, info auth.ChatToken) string {
+	t.Helper()
+	signed, _, err := auth.GenerateChatToken(info, "secret", time.Hour)
+	if err != nil {
+		t.Fatalf("generate chat token: %v", err)
+	}
+	return signed
+}
+
+func TestBuildToolSessionContextIgnoresForgedHeaders(t *testing.T) {
+	e := echo.New()
+	handler := &ContainerdHandler{jwtSecret: "secret"}
+	guestToken := newTestChatToken(t, auth.ChatToken{
+		BotID:             "bot-1",
+		ChatID:            "bot-1",
+		RouteID:           "route-1",
+		ChannelIdentityID: "guest-1",
+		ConversationType:  "group",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/bots/bot-1/tools", nil)
+	req.Header.Set(headerSessionToken, guestToken)
+	// A guest claims to be the owner in a direct chat.
+	req.Header.Set("X-Memoh-Channel-Identity-Id", "owner-1")
+	req.Header.Set("X-Memoh-Conversation-Type", "direct")
+	session := handler.buildToolSessionContext(e.NewContext(req, httptest.NewRecorder()), "bot-1")
+	if session.ChannelIdentityID != "guest-1" {
+		t.Fatalf("expected identity from the session token, got %q", session.ChannelIdentityID)
+	}
+	if session.ConversationType != "group" {
+		t.Fatalf("expected conversation type from the session token, got %q", session.ConversationType)
+	}
+	if session.RouteID != "route-1" {
+		t.Fatalf("expected route from the session token, got %q", session.RouteID)
+	}
+
+	// Without a session token the headers still grant nothing.
+	req = httptest.NewRequest(http.MethodPost, "/bots/bot-1/tools", nil)
+	req.Header.Set("X-Memoh-Channel-Identity-Id", "owner-1")
+	req.Header.Set("X-Memoh-Conversation-Type", "direct")
+	session = handler.buildToolSessionContext(e.NewContext(req, httptest.NewRecorder()), "bot-1")
+	if session.ChannelIdentityID != "" || session.ConversationType != "" {
+		t.Fatalf("expected forged headers to be ignored, got %+v", session)
+	}
+
+	// A session token of another bot is not trusted either.
+	req = httptest.NewRequest(http.MethodPost, "/bots/bot-2/tools", nil)
+	req.Header.Set(headerSessionToken, guestToken)
+	session = handler.buildToolSessionContext(e.NewContext(req, httptest.NewRecorder()), "bot-2")
+	if session.ChannelIdentityID != "" || session.ConversationType != "" || session.RouteID != "" {
+		t.Fatalf("expected token of another bot to be ignored, got %+v", session)
+	}
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,9 +24,14 @@ type MemoryHandler struct {
 	chatService    *conversation.Service
 	accountService *accounts.Service
 	memoryFS       *memory.MemoryFS
+	messageIndex   *memory.MessageIndex
+	messageLister  memory.MessageLister
 	logger         *slog.Logger
 }
 
+// memoryImportMaxBytes caps uploaded import files.
+const memoryImportMaxBytes = 20 << 20
+
 type memoryAddPayload struct {
 	Message          string           `json:"message,omitempty"`
 	Messages         []memory.Message `json:"messages,omitempty"`
@@ -33,6 +41,8 @@ type memoryAddPayload struct {
 	Filters          map[string]any   `json:"filters,omitempty"`
 	Infer            *bool            `json:"infer,omitempty"`
 	EmbeddingEnabled *bool            `json:"embedding_enabled,omitempty"`
+	Importance       *float64         `json:"importance,omitempty"`
+	ExpiresAt        string           `json:"expires_at,omitempty"`
 }
 
 type memorySearchPayload struct {
@@ -43,15 +53,22 @@ type memorySearchPayload struct {
 	Sources          []string       `json:"sources,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 	NoStats          bool           `json:"no_stats,omitempty"`
+	NoRerank         bool           `json:"no_rerank,omitempty"`
 }
 
 type memoryDeletePayload struct {
 	MemoryIDs []string `json:"memory_ids,omitempty"`
 }
 
+type memoryRevertPayload struct {
+	HistoryID string `json:"history_id"`
+}
+
 type memoryCompactPayload struct {
 	Ratio     float64 `json:"ratio"`
 	DecayDays *int    `json:"decay_days,omitempty"`
+	// Preview returns the proposed merges without changing memories.
+	Preview bool `json:"preview,omitempty"`
 }
 
 // namespaceScope holds namespace + scopeId for a single memory scope.
@@ -77,17 +94,34 @@ func (h *MemoryHandler) SetMemoryFS(fs *memory.MemoryFS) {
 	h.memoryFS = fs
 }
 
+// SetMessageIndex enables reindexing the bot's message history.
+func (h *MemoryHandler) SetMessageIndex(index *memory.MessageIndex, lister memory.MessageLister) {
+	h.messageIndex = index
+	h.messageLister = lister
+}
+
 // Register registers chat-level memory routes.
 func (h *MemoryHandler) Register(e *echo.Echo) {
 	chatGroup := e.Group("/bots/:bot_id/memory")
 	chatGroup.POST("", h.ChatAdd)
 	chatGroup.POST("/search", h.ChatSearch)
 	chatGroup.POST("/compact", h.ChatCompact)
+	chatGroup.GET("/compact/:compaction_id", h.ChatGetCompaction)
+	chatGroup.POST("/compact/:compaction_id/apply", h.ChatApplyCompaction)
+	chatGroup.POST("/compact/:compaction_id/undo", h.ChatUndoCompaction)
 	chatGroup.POST("/rebuild", h.ChatRebuild)
+	chatGroup.POST("/import", h.ChatImport)
+	chatGroup.GET("/import/:job_id", h.ChatGetImport)
+	chatGroup.GET("/embedding", h.ChatEmbeddingStatus)
+	chatGroup.POST("/reembed", h.ChatReembed)
+	chatGroup.GET("/reembed/:job_id", h.ChatGetReembed)
+	chatGroup.POST("/history/reindex", h.ChatReindexHistory)
 	chatGroup.GET("", h.ChatGetAll)
 	chatGroup.GET("/usage", h.ChatUsage)
 	chatGroup.DELETE("", h.ChatDelete)
 	chatGroup.DELETE("/:memory_id", h.ChatDeleteOne)
+	chatGroup.GET("/:memory_id/history", h.ChatHistory)
+	chatGroup.POST("/:memory_id/revert", h.ChatRevert)
 }
 
 func (h *MemoryHandler) checkService() error {
@@ -128,6 +162,7 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
 		return err
 	}
+	h.attachHistoryActor(c, channelIdentityID)
 
 	var payload memoryAddPayload
 	if err := c.Bind(&payload); err != nil {
@@ -155,6 +190,8 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 		Filters:          filters,
 		Infer:            payload.Infer,
 		EmbeddingEnabled: payload.EmbeddingEnabled,
+		Importance:       payload.Importance,
+		ExpiresAt:        payload.ExpiresAt,
 	}
 	resp, err := h.service.Add(c.Request().Context(), req)
 	if err != nil {
@@ -238,6 +275,7 @@ func (h *MemoryHandler) ChatSearch(c echo.Context) error {
 			Sources:          payload.Sources,
 			EmbeddingEnabled: payload.EmbeddingEnabled,
 			NoStats:          payload.NoStats,
+			NoRerank:         payload.NoRerank,
 		}
 		resp, err := h.service.Search(c.Request().Context(), req)
 		if err != nil {
@@ -341,6 +379,7 @@ func (h *MemoryHandler) ChatDelete(c echo.Context) error {
 	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
 		return err
 	}
+	h.attachHistoryActor(c, channelIdentityID)
 
 	var payload memoryDeletePayload
 	// Body is optional; ignore bind errors for empty body.
@@ -411,6 +450,7 @@ func (h *MemoryHandler) ChatDeleteOne(c echo.Context) error {
 	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
 		return err
 	}
+	h.attachHistoryActor(c, channelIdentityID)
 
 	memoryID := strings.TrimSpace(c.Param("memory_id"))
 	if memoryID == "" {
@@ -429,6 +469,130 @@ func (h *MemoryHandler) ChatDeleteOne(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// ChatHistory godoc
+// @Summary Get memory history
+// @Description List the recorded changes of a memory (ADD, UPDATE, DELETE, COMPACT, REVERT), newest first
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param memory_id path string true "Memory ID"
+// @Param limit query int false "Maximum number of entries (default 50)"
+// @Success 200 {object} memory.HistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/{memory_id}/history [get]
+func (h *MemoryHandler) ChatHistory(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	memoryID := strings.TrimSpace(c.Param("memory_id"))
+	if memoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "memory_id is required")
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+	_, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.History(c.Request().Context(), botID, memoryID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ChatRevert godoc
+// @Summary Revert memory
+// @Description Restore a memory to the version recorded by a history entry. Deleted memories are re-created with their original ID.
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param memory_id path string true "Memory ID"
+// @Param payload body memoryRevertPayload true "History entry to restore"
+// @Success 200 {object} memory.MemoryItem
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/{memory_id}/revert [post]
+func (h *MemoryHandler) ChatRevert(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	h.attachHistoryActor(c, channelIdentityID)
+	memoryID := strings.TrimSpace(c.Param("memory_id"))
+	if memoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "memory_id is required")
+	}
+	var payload memoryRevertPayload
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(payload.HistoryID) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "history_id is required")
+	}
+	scopeID, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	item, err := h.service.RevertMemory(c.Request().Context(), botID, memoryID, payload.HistoryID)
+	if err != nil {
+		if errors.Is(err, memory.ErrHistoryNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if h.memoryFS != nil {
+		if err := h.memoryFS.PersistMemories(c.Request().Context(), botID, []memory.MemoryItem{item}, buildNamespaceFilters(sharedMemoryNamespace, scopeID, nil)); err != nil {
+			h.logger.Warn("revert memory fs persist failed", slog.Any("error", err))
+		}
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// attachHistoryActor records the caller as the actor of memory changes made
+// by this request.
+func (h *MemoryHandler) attachHistoryActor(c echo.Context, channelIdentityID string) {
+	ctx := memory.WithHistoryActor(c.Request().Context(), memory.HistoryActor{
+		Type: memory.HistoryActorUser,
+		ID:   channelIdentityID,
+	})
+	c.SetRequest(c.Request().WithContext(ctx))
+}
+
 // ChatCompact godoc
 // @Summary Compact memories
 // @Description Consolidate memories by merging similar/redundant entries using LLM.
@@ -439,11 +603,15 @@ func (h *MemoryHandler) ChatDeleteOne(c echo.Context) error {
 // @Description - 0.3 = aggressive compression, heavily consolidate, keep ~30%
 // @Description
 // @Description **decay_days** (optional): enable time decay — memories older than N days are treated as low priority and more likely to be merged/dropped.
+// @Description
+// @Description **preview** (optional): return a compaction plan (merges, dropped memories and a diff) without changing anything. Apply it with POST /compact/{compaction_id}/apply.
+// @Description
+// @Description Applied compactions can be undone with POST /compact/{compaction_id}/undo until undo_expires_at.
 // @Tags memory
 // @Accept json
 // @Produce json
 // @Param bot_id path string true "Bot ID"
-// @Param payload body memoryCompactPayload true "ratio (0,1] required; decay_days optional"
+// @Param payload body memoryCompactPayload true "ratio (0,1] required; decay_days and preview optional"
 // @Success 200 {object} memory.CompactResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -451,20 +619,10 @@ func (h *MemoryHandler) ChatDeleteOne(c echo.Context) error {
 // @Failure 503 {object} ErrorResponse
 // @Router /bots/{bot_id}/memory/compact [post]
 func (h *MemoryHandler) ChatCompact(c echo.Context) error {
-	if err := h.checkService(); err != nil {
-		return err
-	}
-	channelIdentityID, err := h.requireChannelIdentityID(c)
+	containerID, filters, err := h.resolveCompactionScope(c)
 	if err != nil {
 		return err
 	}
-	containerID, err := h.resolveBotContainerID(c)
-	if err != nil {
-		return err
-	}
-	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
-		return err
-	}
 
 	var payload memoryCompactPayload
 	if err := c.Bind(&payload); err != nil {
@@ -479,30 +637,420 @@ func (h *MemoryHandler) ChatCompact(c echo.Context) error {
 		decayDays = *payload.DecayDays
 	}
 
-	scopes, err := h.resolveEnabledScopes(c.Request().Context(), containerID)
+	if payload.Preview {
+		plan, err := h.service.PreviewCompaction(c.Request().Context(), filters, ratio, decayDays)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		return c.JSON(http.StatusOK, plan)
+	}
+
+	result, err := h.service.Compact(c.Request().Context(), filters, ratio, decayDays)
+	if err != nil {
+		return compactionHTTPError(err)
+	}
+	h.syncCompactionFiles(c.Request().Context(), containerID, result, filters)
+	return c.JSON(http.StatusOK, result)
+}
+
+// ChatGetCompaction godoc
+// @Summary Get memory compaction
+// @Description Get a compaction preview, or an applied compaction that can still be undone
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param compaction_id path string true "Compaction ID"
+// @Success 200 {object} memory.CompactionPlan
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/{compaction_id} [get]
+func (h *MemoryHandler) ChatGetCompaction(c echo.Context) error {
+	_, filters, err := h.resolveCompactionScope(c)
 	if err != nil {
 		return err
 	}
-	if len(scopes) == 0 {
-		return echo.NewHTTPError(http.StatusBadRequest, "no memory scopes found")
+	plan, err := h.service.GetCompaction(c.Request().Context(), c.Param("compaction_id"), filters)
+	if err != nil {
+		return compactionHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, plan)
+}
+
+// ChatApplyCompaction godoc
+// @Summary Apply memory compaction
+// @Description Apply a compaction preview. New facts are written before the merged memories are deleted; fails with 409 if the memories changed since the preview.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param compaction_id path string true "Compaction ID"
+// @Success 200 {object} memory.CompactResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/{compaction_id}/apply [post]
+func (h *MemoryHandler) ChatApplyCompaction(c echo.Context) error {
+	containerID, filters, err := h.resolveCompactionScope(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.service.ApplyCompaction(c.Request().Context(), c.Param("compaction_id"), filters)
+	if err != nil {
+		return compactionHTTPError(err)
+	}
+	h.syncCompactionFiles(c.Request().Context(), containerID, result, filters)
+	return c.JSON(http.StatusOK, result)
+}
+
+// ChatUndoCompaction godoc
+// @Summary Undo memory compaction
+// @Description Restore the memories replaced by an applied compaction and remove the facts it created
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param compaction_id path string true "Compaction ID"
+// @Success 200 {object} memory.CompactResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/{compaction_id}/undo [post]
+func (h *MemoryHandler) ChatUndoCompaction(c echo.Context) error {
+	containerID, filters, err := h.resolveCompactionScope(c)
+	if err != nil {
+		return err
 	}
+	result, err := h.service.UndoCompaction(c.Request().Context(), c.Param("compaction_id"), filters)
+	if err != nil {
+		return compactionHTTPError(err)
+	}
+	h.syncCompactionFiles(c.Request().Context(), containerID, result, filters)
+	return c.JSON(http.StatusOK, result)
+}
 
-	// Compact the first (primary) scope.
+// resolveCompactionScope authorizes the caller and returns the filters of the
+// primary memory scope, which is the one compactions operate on.
+func (h *MemoryHandler) resolveCompactionScope(c echo.Context) (string, map[string]any, error) {
+	if err := h.checkService(); err != nil {
+		return "", nil, err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", nil, err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return "", nil, err
+	}
+	h.attachHistoryActor(c, channelIdentityID)
+	scopes, err := h.resolveEnabledScopes(c.Request().Context(), containerID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(scopes) == 0 {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, "no memory scopes found")
+	}
 	scope := scopes[0]
-	filters := buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil)
-	result, err := h.service.Compact(c.Request().Context(), filters, ratio, decayDays)
+	return containerID, buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil), nil
+}
+
+func (h *MemoryHandler) syncCompactionFiles(ctx context.Context, containerID string, result memory.CompactResult, filters map[string]any) {
+	if h.memoryFS == nil {
+		return
+	}
+	if err := h.memoryFS.RebuildFiles(ctx, containerID, result.Results, filters); err != nil {
+		h.logger.Warn("compact memory fs rebuild failed", slog.Any("error", err))
+	}
+}
+
+func compactionHTTPError(err error) error {
+	switch {
+	case errors.Is(err, memory.ErrCompactionNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, memory.ErrCompactionStale), errors.Is(err, memory.ErrCompactionState):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// ChatImport godoc
+// @Summary Import memories from a file
+// @Description Extract memories from a Markdown, PDF or text document, a Telegram JSON export or a WhatsApp text export. The file is chunked and processed in the background; poll the returned job for progress.
+// @Tags memory
+// @Accept multipart/form-data
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param file formData file true "File to import"
+// @Param format formData string false "text, markdown, pdf, telegram or whatsapp; detected from the file when omitted"
+// @Param namespace formData string false "Memory namespace"
+// @Success 202 {object} memory.ImportJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/import [post]
+func (h *MemoryHandler) ChatImport(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	h.attachHistoryActor(c, channelIdentityID)
+
+	namespace, err := normalizeSharedMemoryNamespace(c.FormValue("namespace"))
+	if err != nil {
+		return err
+	}
+	var format memory.ImportFormat
+	if raw := strings.TrimSpace(c.FormValue("format")); raw != "" {
+		if format, err = memory.ParseImportFormat(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	if file.Size > memoryImportMaxBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "file exceeds the import size limit")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, memoryImportMaxBytes))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	// Sync rebuild filesystem.
+	scopeID, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	filters := buildNamespaceFilters(namespace, scopeID, nil)
+	req := memory.ImportRequest{
+		Filename: file.Filename,
+		Format:   format,
+		Data:     data,
+		BotID:    botID,
+		Filters:  filters,
+	}
 	if h.memoryFS != nil {
-		if err := h.memoryFS.RebuildFiles(c.Request().Context(), containerID, result.Results, filters); err != nil {
-			h.logger.Warn("compact memory fs rebuild failed", slog.Any("error", err))
+		req.OnResults = func(ctx context.Context, items []memory.MemoryItem) {
+			persistCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			if err := h.memoryFS.PersistMemories(persistCtx, botID, items, filters); err != nil {
+				h.logger.Warn("import memory persist failed", slog.Any("error", err))
+			}
+		}
+	}
+	job, err := h.service.StartImport(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, memory.ErrImportInvalid) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	return c.JSON(http.StatusAccepted, job)
+}
 
-	return c.JSON(http.StatusOK, result)
+// ChatGetImport godoc
+// @Summary Get memory import progress
+// @Description Get the progress of a memory import job. Finished jobs are kept for 24 hours.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param job_id path string true "Import job ID"
+// @Success 200 {object} memory.ImportJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/import/{job_id} [get]
+func (h *MemoryHandler) ChatGetImport(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return err
+	}
+	scopeID, _, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return err
+	}
+	job, err := h.service.GetImport(c.Param("job_id"), buildNamespaceFilters(sharedMemoryNamespace, scopeID, nil))
+	if err != nil {
+		if errors.Is(err, memory.ErrImportNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// ChatEmbeddingStatus godoc
+// @Summary Get memory embedding status
+// @Description Compare the bot's configured embedding model with the vector its memories are searched in, including the latest re-embedding job.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} memory.EmbeddingStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/embedding [get]
+func (h *MemoryHandler) ChatEmbeddingStatus(c echo.Context) error {
+	botID, err := h.requireMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	status, err := h.service.EmbeddingStatus(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// ChatReembed godoc
+// @Summary Re-embed memories with the bot's embedding model
+// @Description Start a background job that embeds every memory of the bot with its configured embedding model and switches search to the new vectors once all are written. When the model's vector does not exist yet, memories are copied into a new collection first. Poll the returned job for progress.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 202 {object} memory.ReembedJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed [post]
+func (h *MemoryHandler) ChatReembed(c echo.Context) error {
+	botID, err := h.requireMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	job, err := h.service.StartReembed(c.Request().Context(), botID)
+	if err != nil {
+		if errors.Is(err, memory.ErrReembedRunning) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, memory.ErrEmbeddingModelNotConfigured) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// ChatGetReembed godoc
+// @Summary Get memory re-embedding progress
+// @Description Get the progress of a re-embedding job. Finished jobs are kept for 24 hours.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param job_id path string true "Re-embedding job ID"
+// @Success 200 {object} memory.ReembedJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed/{job_id} [get]
+func (h *MemoryHandler) ChatGetReembed(c echo.Context) error {
+	botID, err := h.requireMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	job, err := h.service.GetReembed(c.Request().Context(), c.Param("job_id"), botID)
+	if err != nil {
+		if errors.Is(err, memory.ErrReembedNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// ChatReindexHistory godoc
+// @Summary Index the bot's message history
+// @Description Start a background job that adds every persisted message of the bot that is missing from the message history index, so the search_history tool can find it. New messages are indexed as they arrive.
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Success 202
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/history/reindex [post]
+func (h *MemoryHandler) ChatReindexHistory(c echo.Context) error {
+	if h.messageIndex == nil || h.messageLister == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "message history index not available")
+	}
+	botID, err := h.requireMemoryBot(c)
+	if err != nil {
+		return err
+	}
+	if err := h.messageIndex.StartBackfill(botID, h.messageLister); err != nil {
+		if errors.Is(err, memory.ErrHistoryBackfillRunning) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// requireMemoryBot authorizes the caller for the bot's memory and returns
+// the bot ID background memory jobs are keyed by.
+func (h *MemoryHandler) requireMemoryBot(c echo.Context) (string, error) {
+	if err := h.checkService(); err != nil {
+		return "", err
+	}
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	containerID, err := h.resolveBotContainerID(c)
+	if err != nil {
+		return "", err
+	}
+	if err := h.requireChatParticipant(c.Request().Context(), containerID, channelIdentityID); err != nil {
+		return "", err
+	}
+	_, botID, err := h.resolveWriteScope(c.Request().Context(), containerID)
+	if err != nil {
+		return "", err
+	}
+	return botID, nil
 }
 
 // ChatUsage godoc
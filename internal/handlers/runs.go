@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/conversation/flow"
+)
+
+// RunsHandler exposes the in-process agent run queue of a bot.
+type RunsHandler struct {
+	queue          *flow.RunQueue
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewRunsHandler(log *slog.Logger, queue *flow.RunQueue, botService *bots.Service, accountService *accounts.Service) *RunsHandler {
+	return &RunsHandler{
+		queue:          queue,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "runs")),
+	}
+}
+
+func (h *RunsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/runs")
+	group.GET("", h.List)
+	group.POST("/:run_id/cancel", h.Cancel)
+}
+
+// List godoc
+// @Summary List agent runs
+// @Description List running and queued agent runs of a bot in queue order
+// @Tags runs
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} flow.RunInfo
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/runs [get]
+func (h *RunsHandler) List(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, h.queue.List(botID))
+}
+
+// Cancel godoc
+// @Summary Cancel an agent run
+// @Description Remove a queued run or stop a running generation; partial output is kept
+// @Tags runs
+// @Param bot_id path string true "Bot ID"
+// @Param run_id path string true "Run ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /bots/{bot_id}/runs/{run_id}/cancel [post]
+func (h *RunsHandler) Cancel(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	runID := strings.TrimSpace(c.Param("run_id"))
+	if runID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "run id is required")
+	}
+	if err := h.queue.Cancel(botID, runID); err != nil {
+		switch {
+		case errors.Is(err, flow.ErrRunNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, flow.ErrRunNotCancellable):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *RunsHandler) requireBotAccess(c echo.Context) (string, error) {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
+
+func (h *RunsHandler) authorizeBotAccess(ctx context.Context, channelIdentityID, botID string) (bots.Bot, error) {
+	return AuthorizeBotAccess(ctx, h.botService, h.accountService, channelIdentityID, botID, bots.AccessPolicy{AllowPublicMember: false})
+}
@@ -77,7 +77,7 @@ func (h *SearchProvidersHandler) Create(c echo.Context) error {
 // @Tags search-providers
 // @Accept json
 // @Produce json
-// @Param provider query string false "Provider filter (brave)"
+// @Param provider query string false "Provider filter (brave, searxng, tavily, bing, google, duckduckgo)"
 // @Success 200 {array} searchproviders.GetResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /search-providers [get]
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/secrets"
+)
+
+type SecretsHandler struct {
+	service        *secrets.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewSecretsHandler(log *slog.Logger, service *secrets.Service, botService *bots.Service, accountService *accounts.Service) *SecretsHandler {
+	return &SecretsHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "secrets")),
+	}
+}
+
+func (h *SecretsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/secrets")
+	group.GET("", h.List)
+	group.PUT("/:name", h.Upsert)
+	group.DELETE("/:name", h.Delete)
+}
+
+// List godoc
+// @Summary List bot secrets
+// @Description List a bot's secrets with masked values. Reference them as ${secret:name} in MCP env and headers and in channel credentials.
+// @Tags secrets
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} secrets.ListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/secrets [get]
+func (h *SecretsHandler) List(c echo.Context) error {
+	botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.List(c.Request().Context(), botID)
+	if err != nil {
+		return secretsHTTPError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, secrets.ListResponse{Items: items})
+}
+
+// Upsert godoc
+// @Summary Create or rotate a bot secret
+// @Description Store a secret under a name. Storing an existing name rotates it; references pick up the new value on next use.
+// @Tags secrets
+// @Param bot_id path string true "Bot ID"
+// @Param name path string true "Secret name"
+// @Param payload body secrets.UpsertRequest true "Secret value"
+// @Success 200 {object} secrets.Secret
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/secrets/{name} [put]
+func (h *SecretsHandler) Upsert(c echo.Context) error {
+	botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	var req secrets.UpsertRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	item, err := h.service.Set(c.Request().Context(), botID, c.Param("name"), req)
+	if err != nil {
+		return secretsHTTPError(err, http.StatusBadRequest)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// Delete godoc
+// @Summary Delete a bot secret
+// @Tags secrets
+// @Param bot_id path string true "Bot ID"
+// @Param name path string true "Secret name"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/secrets/{name} [delete]
+func (h *SecretsHandler) Delete(c echo.Context) error {
+	botID, err := h.requireBot(c)
+	if err != nil {
+		return err
+	}
+	if err := h.service.Delete(c.Request().Context(), botID, c.Param("name")); err != nil {
+		return secretsHTTPError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *SecretsHandler) requireBot(c echo.Context) (string, error) {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
+
+func (h *SecretsHandler) authorizeBotAccess(ctx context.Context, channelIdentityID, botID string) (bots.Bot, error) {
+	return AuthorizeBotAccess(ctx, h.botService, h.accountService, channelIdentityID, botID, bots.AccessPolicy{AllowPublicMember: false})
+}
+
+func secretsHTTPError(err error, fallback int) error {
+	switch {
+	case errors.Is(err, secrets.ErrVaultDisabled):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, secrets.ErrSecretNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	default:
+		return echo.NewHTTPError(fallback, err.Error())
+	}
+}
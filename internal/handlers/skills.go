@@ -126,6 +126,7 @@ func (h *ContainerdHandler) UpsertSkills(c echo.Context) error {
 		}
 	}
 
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, skillsOpResponse{OK: true})
 }
 
@@ -168,6 +169,7 @@ func (h *ContainerdHandler) DeleteSkills(c echo.Context) error {
 		}
 	}
 
+	h.invalidateToolResults(botID)
 	return c.JSON(http.StatusOK, skillsOpResponse{OK: true})
 }
 
@@ -802,6 +802,7 @@ func (h *UsersHandler) GetBotChannelConfig(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	resp.Credentials = h.registry.MaskCredentials(channelType, resp.Credentials)
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -852,6 +853,7 @@ func (h *UsersHandler) UpsertBotChannelConfig(c echo.Context) error {
 		}
 		return echo.NewHTTPError(status, err.Error())
 	}
+	resp.Credentials = h.registry.MaskCredentials(channelType, resp.Credentials)
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -902,6 +904,7 @@ func (h *UsersHandler) UpdateBotChannelStatus(c echo.Context) error {
 		}
 		return echo.NewHTTPError(status, err.Error())
 	}
+	resp.Credentials = h.registry.MaskCredentials(channelType, resp.Credentials)
 	return c.JSON(http.StatusOK, resp)
 }
 
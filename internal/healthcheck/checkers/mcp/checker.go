@@ -30,6 +30,12 @@ type ToolLister interface {
 	ListTools(ctx context.Context, session mcp.ToolSessionContext) ([]mcp.ToolDescriptor, error)
 }
 
+// CircuitReporter reports the circuit breaker state of an MCP connection.
+// Tool listers implementing it get checks for disabled connections.
+type CircuitReporter interface {
+	CircuitStatus(connectionID string) (mcp.CircuitStatus, bool)
+}
+
 // Checker evaluates MCP connection health checks.
 type Checker struct {
 	logger      *slog.Logger
@@ -146,8 +152,17 @@ func (c *Checker) ListChecks(ctx context.Context, botID string) []healthcheck.Ch
 		return checks
 	}
 
+	circuits, _ := c.tools.(CircuitReporter)
 	results := make([]healthcheck.CheckResult, 0, len(items))
 	for idx, conn := range items {
+		if circuits != nil {
+			if status, ok := circuits.CircuitStatus(strings.TrimSpace(conn.ID)); ok {
+				if item, tripped := circuitCheck(conn, idx, status); tripped {
+					results = append(results, item)
+					continue
+				}
+			}
+		}
 		connName := displayConnectionName(conn.Name)
 		prefix := sanitizeToolPrefix(conn.Name)
 		toolCount := 0
@@ -185,6 +200,38 @@ func (c *Checker) ListChecks(ctx context.Context, botID string) []healthcheck.Ch
 	return results
 }
 
+// circuitCheck reports a connection whose circuit breaker is not closed; its
+// tools are hidden, so counting them says nothing.
+func circuitCheck(conn mcp.Connection, idx int, status mcp.CircuitStatus) (healthcheck.CheckResult, bool) {
+	connName := displayConnectionName(conn.Name)
+	item := healthcheck.CheckResult{
+		ID:       buildCheckID(conn, idx),
+		Type:     checkTypeMCPConnection,
+		TitleKey: titleKeyMCPConnection,
+		Subtitle: connName,
+		Detail:   status.LastError,
+		Metadata: map[string]any{
+			"connection_id":        strings.TrimSpace(conn.ID),
+			"name":                 strings.TrimSpace(conn.Name),
+			"type":                 strings.TrimSpace(conn.Type),
+			"circuit_state":        status.State,
+			"consecutive_failures": status.Failures,
+			"retry_at":             status.RetryAt.UTC().Format(time.RFC3339),
+		},
+	}
+	switch status.State {
+	case mcp.CircuitOpen:
+		item.Status = healthcheck.StatusError
+		item.Summary = fmt.Sprintf("MCP server %q is disabled after repeated failures.", connName)
+	case mcp.CircuitHalfOpen:
+		item.Status = healthcheck.StatusWarn
+		item.Summary = fmt.Sprintf("MCP server %q is being retried after repeated failures.", connName)
+	default:
+		return healthcheck.CheckResult{}, false
+	}
+	return item, true
+}
+
 func buildCheckID(conn mcp.Connection, idx int) string {
 	connectionID := strings.TrimSpace(conn.ID)
 	if connectionID != "" {
@@ -110,3 +110,47 @@ func TestCheckerListChecksToolListError(t *testing.T) {
 		t.Fatalf("expected non-empty detail")
 	}
 }
+
+type fakeCircuitToolLister struct {
+	fakeToolLister
+	circuits map[string]mcp.CircuitStatus
+}
+
+func (f *fakeCircuitToolLister) CircuitStatus(connectionID string) (mcp.CircuitStatus, bool) {
+	status, ok := f.circuits[connectionID]
+	return status, ok
+}
+
+func TestCheckerListChecksReportsOpenCircuit(t *testing.T) {
+	t.Parallel()
+
+	checker := NewChecker(
+		newTestLogger(),
+		&fakeConnectionLister{
+			items: []mcp.Connection{
+				{ID: "conn-1", Name: "Broken", Type: "http"},
+				{ID: "conn-2", Name: "Flaky", Type: "sse"},
+			},
+		},
+		&fakeCircuitToolLister{
+			circuits: map[string]mcp.CircuitStatus{
+				"conn-1": {State: mcp.CircuitOpen, Failures: 3, LastError: "connection refused"},
+				"conn-2": {State: mcp.CircuitHalfOpen, Failures: 4},
+			},
+		},
+	)
+
+	items := checker.ListChecks(context.Background(), "bot-1")
+	if len(items) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(items))
+	}
+	if items[0].Status != "error" || items[0].Detail != "connection refused" {
+		t.Fatalf("expected error status for open circuit, got %+v", items[0])
+	}
+	if items[0].Metadata["circuit_state"] != mcp.CircuitOpen || items[0].Metadata["consecutive_failures"] != 3 {
+		t.Fatalf("expected circuit metadata, got %v", items[0].Metadata)
+	}
+	if items[1].Status != "warn" {
+		t.Fatalf("expected warn status for half-open circuit, got %s", items[1].Status)
+	}
+}
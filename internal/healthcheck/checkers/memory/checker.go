@@ -0,0 +1,116 @@
+package memorychecker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/healthcheck"
+	"github.com/memohai/memoh/internal/memory"
+)
+
+const (
+	checkTypeMemoryEmbedding = "memory.embedding"
+	titleKeyMemoryEmbedding  = "bots.checks.titles.memoryEmbedding"
+)
+
+// EmbeddingObserver reads whether a bot's memories match its embedding model.
+type EmbeddingObserver interface {
+	EmbeddingStatus(ctx context.Context, botID string) (memory.EmbeddingStatus, error)
+}
+
+// Checker evaluates memory embedding health checks.
+type Checker struct {
+	logger   *slog.Logger
+	observer EmbeddingObserver
+}
+
+// NewChecker creates a memory embedding health checker.
+func NewChecker(log *slog.Logger, observer EmbeddingObserver) *Checker {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Checker{
+		logger:   log.With(slog.String("checker", "healthcheck_memory")),
+		observer: observer,
+	}
+}
+
+// ListChecks reports the bot's embedding model status and re-embedding progress.
+func (c *Checker) ListChecks(ctx context.Context, botID string) []healthcheck.CheckResult {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	botID = strings.TrimSpace(botID)
+	if botID == "" || c.observer == nil {
+		return []healthcheck.CheckResult{}
+	}
+	status, err := c.observer.EmbeddingStatus(ctx, botID)
+	if err != nil {
+		c.logger.Warn("memory embedding status failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return []healthcheck.CheckResult{
+			{
+				ID:       checkTypeMemoryEmbedding,
+				Type:     checkTypeMemoryEmbedding,
+				TitleKey: titleKeyMemoryEmbedding,
+				Status:   healthcheck.StatusUnknown,
+				Summary:  "Memory embedding status is unavailable.",
+				Detail:   err.Error(),
+			},
+		}
+	}
+	if status.Model == nil && status.Job == nil {
+		return []healthcheck.CheckResult{}
+	}
+
+	item := healthcheck.CheckResult{
+		ID:       checkTypeMemoryEmbedding,
+		Type:     checkTypeMemoryEmbedding,
+		TitleKey: titleKeyMemoryEmbedding,
+		Status:   healthcheck.StatusOK,
+		Metadata: map[string]any{
+			"vector_name":       status.VectorName,
+			"vector_dimensions": status.VectorDimensions,
+			"needs_reembed":     status.NeedsReembed,
+		},
+	}
+	if status.Model != nil {
+		item.Subtitle = status.Model.ModelID
+		item.Metadata["model_id"] = status.Model.ModelID
+		item.Metadata["dimensions"] = status.Model.Dimensions
+		item.Summary = "Memories are embedded with the configured model."
+	}
+	if job := status.Job; job != nil {
+		item.Metadata["job_id"] = job.ID
+		item.Metadata["job_status"] = string(job.Status)
+		item.Metadata["job_phase"] = string(job.Phase)
+		item.Metadata["progress"] = job.Progress
+		item.Metadata["processed"] = job.Processed
+		item.Metadata["total"] = job.Total
+		item.Metadata["failed"] = job.Failed
+		switch job.Status {
+		case memory.ReembedStatusRunning:
+			item.Status = healthcheck.StatusUnknown
+			item.Summary = fmt.Sprintf("Re-embedding memories with %s: %.0f%% (%s).", job.ModelID, job.Progress*100, job.Phase)
+			return []healthcheck.CheckResult{item}
+		case memory.ReembedStatusFailed:
+			if status.NeedsReembed {
+				item.Status = healthcheck.StatusError
+				item.Summary = fmt.Sprintf("Re-embedding memories with %s failed.", job.ModelID)
+				item.Detail = strings.Join(job.Errors, "\n")
+				if item.Detail == "" {
+					item.Detail = job.Error
+				}
+				return []healthcheck.CheckResult{item}
+			}
+		}
+	}
+	if status.NeedsReembed {
+		item.Status = healthcheck.StatusWarn
+		item.Summary = "Memories are embedded with a different model; start a re-embedding job to use the configured one."
+		item.Detail = fmt.Sprintf("configured %s (%d dims), searching %s (%d dims)",
+			status.Model.ModelID, status.Model.Dimensions, status.VectorName, status.VectorDimensions)
+	}
+	return []healthcheck.CheckResult{item}
+}
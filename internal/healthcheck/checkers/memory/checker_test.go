@@ -0,0 +1,75 @@
+package memorychecker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/memohai/memoh/internal/healthcheck"
+	"github.com/memohai/memoh/internal/memory"
+)
+
+type fakeEmbeddingObserver struct {
+	status memory.EmbeddingStatus
+	err    error
+}
+
+func (f *fakeEmbeddingObserver) EmbeddingStatus(_ context.Context, _ string) (memory.EmbeddingStatus, error) {
+	return f.status, f.err
+}
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestCheckerListChecks(t *testing.T) {
+	t.Parallel()
+
+	model := &memory.EmbeddingModel{ModelID: "large", Dimensions: 8}
+	cases := []struct {
+		name   string
+		status memory.EmbeddingStatus
+		err    error
+		want   string
+		count  int
+	}{
+		{name: "no model", status: memory.EmbeddingStatus{}, count: 0},
+		{name: "ok", status: memory.EmbeddingStatus{Model: model, VectorName: "large", VectorDimensions: 8}, want: healthcheck.StatusOK, count: 1},
+		{name: "needs reembed", status: memory.EmbeddingStatus{Model: model, VectorName: "small", VectorDimensions: 4, NeedsReembed: true}, want: healthcheck.StatusWarn, count: 1},
+		{
+			name: "running",
+			status: memory.EmbeddingStatus{Model: model, NeedsReembed: true, Job: &memory.ReembedJob{
+				ID: "job-1", Status: memory.ReembedStatusRunning, Phase: memory.ReembedPhaseCollection, Progress: 0.5,
+			}},
+			want:  healthcheck.StatusUnknown,
+			count: 1,
+		},
+		{
+			name: "failed",
+			status: memory.EmbeddingStatus{Model: model, NeedsReembed: true, Job: &memory.ReembedJob{
+				ID: "job-1", Status: memory.ReembedStatusFailed, Error: "boom", Errors: []string{"boom"},
+			}},
+			want:  healthcheck.StatusError,
+			count: 1,
+		},
+		{name: "observer error", err: errors.New("boom"), want: healthcheck.StatusUnknown, count: 1},
+	}
+	for _, tc := range cases {
+		checker := NewChecker(newTestLogger(), &fakeEmbeddingObserver{status: tc.status, err: tc.err})
+		items := checker.ListChecks(context.Background(), "bot-1")
+		if len(items) != tc.count {
+			t.Fatalf("%s: expected %d checks, got %d", tc.name, tc.count, len(items))
+		}
+		if tc.count == 0 {
+			continue
+		}
+		if items[0].Status != tc.want {
+			t.Fatalf("%s: expected %s, got %s (%s)", tc.name, tc.want, items[0].Status, items[0].Summary)
+		}
+		if items[0].Type != checkTypeMemoryEmbedding {
+			t.Fatalf("%s: unexpected type %s", tc.name, items[0].Type)
+		}
+	}
+}
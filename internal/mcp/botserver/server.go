@@ -0,0 +1,318 @@
+// Package botserver exposes a bot as an MCP server to external clients such
+// as IDEs and other agents. Clients authenticate with a scoped bot token and
+// get a small, stable tool set that is served by the bot's regular tool
+// executors, so tool policies and the tool call audit log apply as usual.
+// Sessions started with a bot token are restricted: the gateway only offers
+// the tools their scopes grant and policies treat the caller as a guest, also
+// in the chats it starts.
+package botserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/conversation/flow"
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+// Token scopes. Each exposed tool requires exactly one of them, and the
+// tool gateway grants restricted sessions the same tools.
+const (
+	ScopeChat         = mcp.ScopeChat
+	ScopeMemoryRead   = mcp.ScopeMemoryRead
+	ScopeMemoryWrite  = mcp.ScopeMemoryWrite
+	ScopeScheduleRead = mcp.ScopeScheduleRead
+	ScopeMessageSend  = mcp.ScopeMessageSend
+)
+
+// Scopes lists every scope a token can be issued with.
+var Scopes = []string{ScopeChat, ScopeMemoryRead, ScopeMemoryWrite, ScopeScheduleRead, ScopeMessageSend}
+
+const (
+	toolChatWithBot   = "chat_with_bot"
+	toolSearchMemory  = "search_memory"
+	toolAddMemory     = "add_memory"
+	toolListSchedules = "list_schedules"
+	toolSendMessage   = "send_message"
+
+	// chatTokenTTL bounds the scoped chat token handed to the agent for one
+	// chat turn.
+	chatTokenTTL = 10 * time.Minute
+)
+
+// ToolGateway lists and calls the bot's tools.
+type ToolGateway interface {
+	ListTools(ctx context.Context, session mcp.ToolSessionContext) ([]mcp.ToolDescriptor, error)
+	CallTool(ctx context.Context, session mcp.ToolSessionContext, payload mcp.ToolCallPayload) (map[string]any, error)
+}
+
+// ChatRunner runs one synchronous chat turn.
+type ChatRunner interface {
+	Chat(ctx context.Context, req conversation.ChatRequest) (conversation.ChatResponse, error)
+}
+
+// exposedTool is a tool of the external server. Tools with a gatewayTool are
+// forwarded to that gateway tool with only the listed arguments.
+type exposedTool struct {
+	descriptor  mcp.ToolDescriptor
+	scope       string
+	gatewayTool string
+	arguments   []string
+}
+
+var exposedTools = []exposedTool{
+	{
+		scope: ScopeChat,
+		descriptor: mcp.ToolDescriptor{
+			Name:        toolChatWithBot,
+			Description: "Send a message to the bot and wait for its reply. The bot answers with its own model, memory and tools, and the exchange is kept in its history",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"message": map[string]any{"type": "string", "description": "The message to send"},
+				},
+				"required": []string{"message"},
+			},
+		},
+	},
+	{
+		scope:       ScopeMemoryRead,
+		gatewayTool: "search_memory",
+		arguments:   []string{"query", "limit"},
+		descriptor: mcp.ToolDescriptor{
+			Name:        toolSearchMemory,
+			Description: "Search the bot's long-term memory",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{"type": "string", "description": "What to search for"},
+					"limit": map[string]any{"type": "integer", "description": "Maximum number of results"},
+				},
+				"required": []string{"query"},
+			},
+		},
+	},
+	{
+		scope:       ScopeMemoryWrite,
+		gatewayTool: "add_memory",
+		arguments:   []string{"content", "infer", "importance"},
+		descriptor: mcp.ToolDescriptor{
+			Name:        toolAddMemory,
+			Description: "Store a memory for the bot. The content is stored as written unless infer is set, in which case facts are extracted from it and merged with existing memories",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content":    map[string]any{"type": "string", "description": "The memory to store"},
+					"infer":      map[string]any{"type": "boolean", "description": "Extract facts instead of storing the content verbatim"},
+					"importance": map[string]any{"type": "number", "description": "Importance between 0 and 1"},
+				},
+				"required": []string{"content"},
+			},
+		},
+	},
+	{
+		scope:       ScopeScheduleRead,
+		gatewayTool: "list_schedule",
+		descriptor: mcp.ToolDescriptor{
+			Name:        toolListSchedules,
+			Description: "List the bot's scheduled tasks",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+		},
+	},
+	{
+		scope:       ScopeMessageSend,
+		gatewayTool: "send",
+		arguments:   []string{"platform", "target", "text", "reply_to"},
+		descriptor: mcp.ToolDescriptor{
+			Name:        toolSendMessage,
+			Description: "Send a text message through one of the bot's channels",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"platform": map[string]any{"type": "string", "description": "Channel platform name, e.g. telegram"},
+					"target":   map[string]any{"type": "string", "description": "Channel target (chat, group or thread ID)"},
+					"text":     map[string]any{"type": "string", "description": "Message text"},
+					"reply_to": map[string]any{"type": "string", "description": "Message ID to reply to"},
+				},
+				"required": []string{"platform", "target", "text"},
+			},
+		},
+	},
+}
+
+// Server serves the external tool set of a bot.
+type Server struct {
+	logger    *slog.Logger
+	gateway   ToolGateway
+	chat      ChatRunner
+	jwtSecret string
+}
+
+// NewServer creates a Server that forwards to the bot's tool gateway.
+func NewServer(log *slog.Logger, gateway ToolGateway) *Server {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Server{
+		logger:  log.With(slog.String("service", "mcp_bot_server")),
+		gateway: gateway,
+	}
+}
+
+// SetChatRunner enables chat_with_bot. The secret signs the short-lived chat
+// token the agent uses for its own tool calls during the turn. The token
+// carries the principal's scopes, so the turn can only use the tools those
+// scopes grant; the chat scope alone grants none.
+func (s *Server) SetChatRunner(runner ChatRunner, jwtSecret string) {
+	s.chat = runner
+	s.jwtSecret = jwtSecret
+}
+
+// ListTools returns the tools the principal's scopes grant. Tools served by
+// the gateway are hidden when the gateway does not offer them, e.g. because
+// a tool policy disables them.
+func (s *Server) ListTools(ctx context.Context, principal Principal) ([]mcp.ToolDescriptor, error) {
+	var available map[string]bool
+	tools := make([]mcp.ToolDescriptor, 0, len(exposedTools))
+	for _, tool := range exposedTools {
+		if !principal.Allows(tool.scope) || !s.enabled(tool) {
+			continue
+		}
+		if tool.gatewayTool != "" {
+			if available == nil {
+				var err error
+				if available, err = s.gatewayTools(ctx, principal); err != nil {
+					return nil, err
+				}
+			}
+			if !available[tool.gatewayTool] {
+				continue
+			}
+		}
+		tools = append(tools, tool.descriptor)
+	}
+	return tools, nil
+}
+
+// CallTool runs an exposed tool for the principal.
+func (s *Server) CallTool(ctx context.Context, principal Principal, name string, arguments map[string]any) (map[string]any, error) {
+	tool, ok := lookupTool(name)
+	if !ok || !s.enabled(tool) {
+		return nil, mcp.ErrToolNotFound
+	}
+	if !principal.Allows(tool.scope) {
+		return mcp.BuildToolErrorResult(fmt.Sprintf("token lacks the %s scope", tool.scope)), nil
+	}
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	switch {
+	case tool.gatewayTool != "":
+		forwarded := make(map[string]any, len(tool.arguments))
+		for _, key := range tool.arguments {
+			if value, ok := arguments[key]; ok {
+				forwarded[key] = value
+			}
+		}
+		return s.gateway.CallTool(ctx, s.session(principal), mcp.ToolCallPayload{Name: tool.gatewayTool, Arguments: forwarded})
+	case tool.descriptor.Name == toolChatWithBot:
+		return s.callChat(ctx, principal, arguments)
+	default:
+		return nil, mcp.ErrToolNotFound
+	}
+}
+
+func (s *Server) callChat(ctx context.Context, principal Principal, arguments map[string]any) (map[string]any, error) {
+	message := mcp.StringArg(arguments, "message")
+	if message == "" {
+		return mcp.BuildToolErrorResult("message is required"), nil
+	}
+	signed, _, err := auth.GenerateChatToken(auth.ChatToken{
+		BotID:             principal.BotID,
+		ChatID:            principal.BotID,
+		UserID:            principal.UserID,
+		ChannelIdentityID: principal.UserID,
+		Scopes:            principal.scopes(),
+	}, s.jwtSecret, chatTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.chat.Chat(ctx, conversation.ChatRequest{
+		BotID:                   principal.BotID,
+		ChatID:                  principal.BotID,
+		Token:                   "Bearer " + signed,
+		ChatToken:               signed,
+		UserID:                  principal.UserID,
+		SourceChannelIdentityID: principal.UserID,
+		DisplayName:             principal.TokenName,
+		Query:                   message,
+	})
+	if err != nil {
+		s.logger.Warn("mcp chat failed", slog.String("bot_id", principal.BotID), slog.Any("error", err))
+		return mcp.BuildToolErrorResult("chat failed: " + err.Error()), nil
+	}
+	replies := make([]string, 0, len(resp.Messages))
+	for _, output := range flow.ExtractAssistantOutputs(resp.Messages) {
+		if output.Content != "" {
+			replies = append(replies, output.Content)
+		}
+	}
+	reply := strings.Join(replies, "\n\n")
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": reply}},
+		"structuredContent": map[string]any{
+			"reply": reply,
+			"model": resp.Model,
+		},
+	}, nil
+}
+
+func (s *Server) enabled(tool exposedTool) bool {
+	switch tool.descriptor.Name {
+	case toolChatWithBot:
+		return s.chat != nil && s.jwtSecret != ""
+	default:
+		return s.gateway != nil
+	}
+}
+
+func (s *Server) gatewayTools(ctx context.Context, principal Principal) (map[string]bool, error) {
+	tools, err := s.gateway.ListTools(ctx, s.session(principal))
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		available[tool.Name] = true
+	}
+	return available, nil
+}
+
+// session runs gateway tools as the token issuer in the bot-wide chat,
+// restricted to the token's scopes.
+func (s *Server) session(principal Principal) mcp.ToolSessionContext {
+	return mcp.ToolSessionContext{
+		BotID:             principal.BotID,
+		ChatID:            principal.BotID,
+		ChannelIdentityID: principal.UserID,
+		Scopes:            principal.scopes(),
+	}
+}
+
+func lookupTool(name string) (exposedTool, bool) {
+	name = strings.TrimSpace(name)
+	for _, tool := range exposedTools {
+		if tool.descriptor.Name == name {
+			return tool, true
+		}
+	}
+	return exposedTool{}, false
+}
@@ -0,0 +1,210 @@
+package botserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+type fakeGateway struct {
+	tools    []string
+	session  mcp.ToolSessionContext
+	payloads []mcp.ToolCallPayload
+}
+
+func (g *fakeGateway) ListTools(_ context.Context, _ mcp.ToolSessionContext) ([]mcp.ToolDescriptor, error) {
+	items := make([]mcp.ToolDescriptor, 0, len(g.tools))
+	for _, name := range g.tools {
+		items = append(items, mcp.ToolDescriptor{Name: name})
+	}
+	return items, nil
+}
+
+func (g *fakeGateway) CallTool(_ context.Context, session mcp.ToolSessionContext, payload mcp.ToolCallPayload) (map[string]any, error) {
+	g.session = session
+	g.payloads = append(g.payloads, payload)
+	return mcp.BuildToolSuccessResult(map[string]any{"ok": true}), nil
+}
+
+type fakeChatRunner struct {
+	req  conversation.ChatRequest
+	resp conversation.ChatResponse
+}
+
+func (r *fakeChatRunner) Chat(_ context.Context, req conversation.ChatRequest) (conversation.ChatResponse, error) {
+	r.req = req
+	return r.resp, nil
+}
+
+func testPrincipal(scopes ...string) Principal {
+	return Principal{
+		TokenID:   "token-1",
+		TokenName: "ide",
+		BotID:     "bot-1",
+		UserID:    "user-1",
+		Scopes:    scopes,
+	}
+}
+
+func toolNames(items []mcp.ToolDescriptor) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestServerListToolsFiltersByScope(t *testing.T) {
+	gateway := &fakeGateway{tools: []string{"search_memory", "add_memory", "list_schedule"}}
+	server := NewServer(slog.Default(), gateway)
+
+	tools, err := server.ListTools(context.Background(), testPrincipal(ScopeMemoryRead, ScopeMemoryWrite, ScopeMessageSend))
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	// send_message is granted but hidden because the gateway does not offer send.
+	got := strings.Join(toolNames(tools), ",")
+	if got != "search_memory,add_memory" {
+		t.Fatalf("unexpected tools: %s", got)
+	}
+}
+
+func TestServerListToolsHidesUnconfiguredTools(t *testing.T) {
+	server := NewServer(slog.Default(), &fakeGateway{})
+	tools, err := server.ListTools(context.Background(), testPrincipal(ScopeChat, ScopeMemoryWrite))
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	if len(tools) != 0 {
+		t.Fatalf("expected no tools without chat runner and gateway tools, got %v", toolNames(tools))
+	}
+}
+
+func TestServerCallToolForwardsAllowedArguments(t *testing.T) {
+	gateway := &fakeGateway{tools: []string{"send"}}
+	server := NewServer(slog.Default(), gateway)
+
+	_, err := server.CallTool(context.Background(), testPrincipal(ScopeMessageSend), toolSendMessage, map[string]any{
+		"platform": "telegram",
+		"target":   "42",
+		"text":     "hi",
+		"bot_id":   "bot-2",
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if len(gateway.payloads) != 1 {
+		t.Fatalf("expected one gateway call, got %d", len(gateway.payloads))
+	}
+	payload := gateway.payloads[0]
+	if payload.Name != "send" {
+		t.Fatalf("unexpected gateway tool: %s", payload.Name)
+	}
+	if _, ok := payload.Arguments["bot_id"]; ok {
+		t.Fatalf("bot_id must not be forwarded: %v", payload.Arguments)
+	}
+	if payload.Arguments["text"] != "hi" {
+		t.Fatalf("unexpected arguments: %v", payload.Arguments)
+	}
+	if gateway.session.BotID != "bot-1" || gateway.session.ChannelIdentityID != "user-1" {
+		t.Fatalf("unexpected session: %+v", gateway.session)
+	}
+	if !gateway.session.Restricted() {
+		t.Fatal("expected the gateway session to be restricted")
+	}
+}
+
+func TestServerCallToolRequiresScope(t *testing.T) {
+	gateway := &fakeGateway{tools: []string{"search_memory"}}
+	server := NewServer(slog.Default(), gateway)
+
+	result, err := server.CallTool(context.Background(), testPrincipal(ScopeChat), toolSearchMemory, map[string]any{"query": "x"})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if isError, _ := result["isError"].(bool); !isError {
+		t.Fatalf("expected error result, got %v", result)
+	}
+	if len(gateway.payloads) != 0 {
+		t.Fatal("gateway must not be called without scope")
+	}
+
+	if _, err := server.CallTool(context.Background(), testPrincipal(ScopeChat), "exec", nil); !errors.Is(err, mcp.ErrToolNotFound) {
+		t.Fatalf("expected ErrToolNotFound, got %v", err)
+	}
+}
+
+func TestServerChatWithBot(t *testing.T) {
+	runner := &fakeChatRunner{resp: conversation.ChatResponse{
+		Model: "gpt-test",
+		Messages: []conversation.ModelMessage{
+			{Role: "user", Content: conversation.NewTextContent("hello")},
+			{Role: "assistant", Content: conversation.NewTextContent("hi there")},
+		},
+	}}
+	server := NewServer(slog.Default(), &fakeGateway{})
+	server.SetChatRunner(runner, "secret")
+
+	result, err := server.CallTool(context.Background(), testPrincipal(ScopeChat), toolChatWithBot, map[string]any{"message": "hello"})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	structured, _ := result["structuredContent"].(map[string]any)
+	if structured["reply"] != "hi there" || structured["model"] != "gpt-test" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if runner.req.BotID != "bot-1" || runner.req.UserID != "user-1" || runner.req.Query != "hello" {
+		t.Fatalf("unexpected chat request: %+v", runner.req)
+	}
+	if runner.req.Token != "Bearer "+runner.req.ChatToken {
+		t.Fatalf("expected the chat token as bearer token, got %q", runner.req.Token)
+	}
+	token, err := auth.ParseChatToken(runner.req.ChatToken, "secret")
+	if err != nil {
+		t.Fatalf("parse chat token: %v", err)
+	}
+	if token.BotID != "bot-1" || token.UserID != "user-1" || strings.Join(token.Scopes, ",") != ScopeChat {
+		t.Fatalf("unexpected chat token: %+v", token)
+	}
+}
+
+func TestServerAddMemoryUsesGateway(t *testing.T) {
+	gateway := &fakeGateway{tools: []string{"add_memory"}}
+	server := NewServer(slog.Default(), gateway)
+
+	if _, err := server.CallTool(context.Background(), testPrincipal(ScopeMemoryWrite), toolAddMemory, map[string]any{"content": "likes tea", "importance": 0.5, "bot_id": "bot-2"}); err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if len(gateway.payloads) != 1 || gateway.payloads[0].Name != "add_memory" {
+		t.Fatalf("unexpected gateway calls: %+v", gateway.payloads)
+	}
+	args := gateway.payloads[0].Arguments
+	if args["content"] != "likes tea" || args["importance"] != 0.5 {
+		t.Fatalf("unexpected arguments: %v", args)
+	}
+	if _, ok := args["bot_id"]; ok {
+		t.Fatalf("bot_id must not be forwarded: %v", args)
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	scopes, err := normalizeScopes([]string{" Chat ", "memory:read", "chat", ""})
+	if err != nil {
+		t.Fatalf("normalize scopes: %v", err)
+	}
+	if strings.Join(scopes, ",") != "chat,memory:read" {
+		t.Fatalf("unexpected scopes: %v", scopes)
+	}
+	if _, err := normalizeScopes([]string{"admin"}); err == nil {
+		t.Fatal("expected error for unknown scope")
+	}
+	if _, err := normalizeScopes(nil); err == nil {
+		t.Fatal("expected error for empty scopes")
+	}
+}
@@ -0,0 +1,287 @@
+package botserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// tokenPrefix marks Memoh MCP tokens so they are recognizable in configs
+// and secret scanners.
+const tokenPrefix = "memoh_mcp_"
+
+var (
+	// ErrInvalidToken means the token is unknown, expired or issued for
+	// another bot.
+	ErrInvalidToken = errors.New("invalid mcp token")
+	// ErrTokenNotFound means the token to revoke does not exist.
+	ErrTokenNotFound = errors.New("mcp token not found")
+)
+
+// Token is a stored access token. The secret itself is only returned once,
+// in IssuedToken, when the token is created.
+type Token struct {
+	ID             string     `json:"id"`
+	BotID          string     `json:"bot_id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	Scopes         []string   `json:"scopes"`
+	IssuedByUserID string     `json:"issued_by_user_id"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IssuedToken is a newly created token together with its secret.
+type IssuedToken struct {
+	Token
+	Secret string `json:"token"`
+	// Endpoint is the URL MCP clients connect to, filled in by the handler.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// IssueRequest creates a token for external MCP clients.
+type IssueRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresInDays makes the token expire; zero issues a token that stays
+	// valid until it is revoked.
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+// TokenListResponse wraps token list responses.
+type TokenListResponse struct {
+	Items []Token `json:"items"`
+}
+
+// Principal is the caller authenticated by a token. Calls run on behalf of
+// the user who issued the token, limited to the token's scopes.
+type Principal struct {
+	TokenID   string
+	TokenName string
+	BotID     string
+	UserID    string
+	Scopes    []string
+}
+
+// Allows reports whether the principal holds a scope.
+func (p Principal) Allows(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// scopes returns a copy of the principal's scopes that is never nil, so
+// sessions and chat tokens built from it are always restricted.
+func (p Principal) scopes() []string {
+	return append([]string{}, p.Scopes...)
+}
+
+// TokenService issues, lists, revokes and verifies bot MCP tokens. Only a
+// SHA-256 hash of each token is stored.
+type TokenService struct {
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewTokenService creates a TokenService backed by sqlc queries.
+func NewTokenService(log *slog.Logger, queries *sqlc.Queries) *TokenService {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &TokenService{
+		queries: queries,
+		logger:  log.With(slog.String("service", "mcp_tokens")),
+	}
+}
+
+// Issue creates a token for a bot on behalf of a user.
+func (s *TokenService) Issue(ctx context.Context, botID, userID string, req IssueRequest) (IssuedToken, error) {
+	if s.queries == nil {
+		return IssuedToken{}, fmt.Errorf("mcp token queries not configured")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return IssuedToken{}, fmt.Errorf("name is required")
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	if req.ExpiresInDays < 0 {
+		return IssuedToken{}, fmt.Errorf("expires_in_days must not be negative")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresInDays > 0 {
+		expiresAt = pgtype.Timestamptz{Time: time.Now().UTC().AddDate(0, 0, req.ExpiresInDays), Valid: true}
+	}
+	row, err := s.queries.CreateBotMCPToken(ctx, sqlc.CreateBotMCPTokenParams{
+		BotID:          pgBotID,
+		Name:           name,
+		TokenHash:      hashSecret(secret),
+		TokenPrefix:    secret[:len(tokenPrefix)+6],
+		Scopes:         scopes,
+		IssuedByUserID: pgUserID,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	return IssuedToken{Token: normalizeToken(row), Secret: secret}, nil
+}
+
+// List returns the tokens of a bot, newest first.
+func (s *TokenService) List(ctx context.Context, botID string) ([]Token, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("mcp token queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBotMCPTokens(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Token, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, normalizeToken(row))
+	}
+	return items, nil
+}
+
+// Revoke deletes a token; clients using it are rejected from then on.
+func (s *TokenService) Revoke(ctx context.Context, botID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("mcp token queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return ErrTokenNotFound
+	}
+	deleted, err := s.queries.DeleteBotMCPToken(ctx, sqlc.DeleteBotMCPTokenParams{ID: pgID, BotID: pgBotID})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves the principal of a token presented for a bot.
+func (s *TokenService) Authenticate(ctx context.Context, botID, secret string) (Principal, error) {
+	if s.queries == nil {
+		return Principal{}, fmt.Errorf("mcp token queries not configured")
+	}
+	secret = strings.TrimSpace(secret)
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return Principal{}, ErrInvalidToken
+	}
+	row, err := s.queries.GetBotMCPTokenByHash(ctx, hashSecret(secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Principal{}, ErrInvalidToken
+		}
+		return Principal{}, err
+	}
+	if row.BotID.String() != strings.TrimSpace(botID) {
+		return Principal{}, ErrInvalidToken
+	}
+	if row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(time.Now()) {
+		return Principal{}, ErrInvalidToken
+	}
+	if err := s.queries.TouchBotMCPToken(ctx, row.ID); err != nil {
+		s.logger.Warn("touch mcp token failed", slog.String("token_id", row.ID.String()), slog.Any("error", err))
+	}
+	return Principal{
+		TokenID:   row.ID.String(),
+		TokenName: row.Name,
+		BotID:     row.BotID.String(),
+		UserID:    row.IssuedByUserID.String(),
+		Scopes:    row.Scopes,
+	}, nil
+}
+
+func normalizeScopes(values []string) ([]string, error) {
+	scopes := make([]string, 0, len(values))
+	for _, value := range values {
+		scope := strings.ToLower(strings.TrimSpace(value))
+		if scope == "" || slices.Contains(scopes, scope) {
+			continue
+		}
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("invalid scope: %s", value)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeToken(row sqlc.BotMcpToken) Token {
+	token := Token{
+		ID:             row.ID.String(),
+		BotID:          row.BotID.String(),
+		Name:           row.Name,
+		Prefix:         row.TokenPrefix,
+		Scopes:         row.Scopes,
+		IssuedByUserID: row.IssuedByUserID.String(),
+		CreatedAt:      db.TimeFromPg(row.CreatedAt),
+	}
+	if token.Scopes == nil {
+		token.Scopes = []string{}
+	}
+	if row.ExpiresAt.Valid {
+		expiresAt := row.ExpiresAt.Time
+		token.ExpiresAt = &expiresAt
+	}
+	if row.LastUsedAt.Valid {
+		lastUsedAt := row.LastUsedAt.Time
+		token.LastUsedAt = &lastUsedAt
+	}
+	return token
+}
@@ -0,0 +1,419 @@
+// Package catalog provides the curated list of MCP servers that can be
+// installed on a bot with one click. The catalog combines the registry
+// shipped with Memoh and optional remote registries, and turns an entry into
+// an install plan: the setup command, the binaries it needs in the bot
+// container and the resulting mcpServers entry.
+package catalog
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+// Install runtimes.
+const (
+	RuntimeNPM = "npm"
+	RuntimeUVX = "uvx"
+)
+
+// SourceBuiltin marks entries from the registry shipped with Memoh. Entries
+// from remote registries carry the registry URL as source.
+const SourceBuiltin = "builtin"
+
+const (
+	remoteTTL          = 10 * time.Minute
+	remoteFetchTimeout = 10 * time.Second
+	maxRegistryBytes   = 4 << 20
+)
+
+// ErrEntryNotFound means the catalog has no server with the requested ID.
+var ErrEntryNotFound = errors.New("mcp catalog entry not found")
+
+//go:embed registry.yaml
+var builtinRegistry []byte
+
+var (
+	entryIDPattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// Registry is the file format of built-in and remote registries.
+type Registry struct {
+	Version int     `yaml:"version" json:"version"`
+	Servers []Entry `yaml:"servers" json:"servers"`
+}
+
+// Entry describes an installable MCP server. Local servers set Install and
+// run as stdio processes in the bot container; remote servers set URL.
+type Entry struct {
+	ID          string            `yaml:"id" json:"id"`
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description"`
+	Homepage    string            `yaml:"homepage,omitempty" json:"homepage,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Install     *Install          `yaml:"install,omitempty" json:"install,omitempty"`
+	Command     string            `yaml:"command,omitempty" json:"command,omitempty"`
+	Args        []string          `yaml:"args,omitempty" json:"args,omitempty"`
+	URL         string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Transport   string            `yaml:"transport,omitempty" json:"transport,omitempty"`
+	Auth        string            `yaml:"auth,omitempty" json:"auth,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Env         []EnvVar          `yaml:"env,omitempty" json:"env,omitempty"`
+	Source      string            `yaml:"-" json:"source"`
+}
+
+// Install is the package a local server is installed from.
+type Install struct {
+	Runtime string `yaml:"runtime" json:"runtime"`
+	Package string `yaml:"package" json:"package"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// EnvVar is a value the user supplies at install time. Local servers get it
+// as environment variable; it also fills ${NAME} placeholders.
+type EnvVar struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	Secret      bool   `yaml:"secret,omitempty" json:"secret,omitempty"`
+	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// Local reports whether the server runs inside the bot container.
+func (e Entry) Local() bool {
+	return e.URL == ""
+}
+
+// ListFilter narrows List results.
+type ListFilter struct {
+	Query string
+	Tag   string
+}
+
+// ListResponse wraps catalog list responses.
+type ListResponse struct {
+	Items []Entry `json:"items"`
+}
+
+// InstallRequest customizes an install.
+type InstallRequest struct {
+	// Name of the connection; defaults to the entry ID.
+	Name string            `json:"name,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+}
+
+// Plan is everything needed to install an entry on a bot.
+type Plan struct {
+	Name string
+	// Setup is the command that installs the package in the bot container.
+	Setup []string
+	// Requires lists binaries that must exist in the bot container.
+	Requires []string
+	Server   mcp.MCPServerEntry
+}
+
+type remoteRegistry struct {
+	entries   []Entry
+	fetchedAt time.Time
+}
+
+// Service serves the merged catalog. Remote registries are fetched lazily
+// and cached; a registry that fails to load keeps its last good entries.
+type Service struct {
+	logger  *slog.Logger
+	client  *http.Client
+	urls    []string
+	builtin []Entry
+
+	mu     sync.Mutex
+	remote map[string]remoteRegistry
+}
+
+// NewService creates a Service from the built-in registry and the remote
+// registries configured in mcp.catalog_urls.
+func NewService(log *slog.Logger, cfg config.Config) (*Service, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	registry, err := parseRegistry(builtinRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("parse builtin mcp catalog: %w", err)
+	}
+	builtin := make([]Entry, 0, len(registry.Servers))
+	for _, entry := range registry.Servers {
+		if err := validateEntry(entry); err != nil {
+			return nil, fmt.Errorf("builtin mcp catalog: %w", err)
+		}
+		entry.Source = SourceBuiltin
+		builtin = append(builtin, entry)
+	}
+	urls := make([]string, 0, len(cfg.MCP.CatalogURLs))
+	for _, raw := range cfg.MCP.CatalogURLs {
+		if url := strings.TrimSpace(raw); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return &Service{
+		logger:  log.With(slog.String("service", "mcp_catalog")),
+		client:  &http.Client{Timeout: remoteFetchTimeout},
+		urls:    urls,
+		builtin: builtin,
+		remote:  map[string]remoteRegistry{},
+	}, nil
+}
+
+// List returns the catalog entries matching the filter. Built-in entries
+// win over remote entries with the same ID, and earlier registries win over
+// later ones.
+func (s *Service) List(ctx context.Context, filter ListFilter) []Entry {
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	tag := strings.ToLower(strings.TrimSpace(filter.Tag))
+	items := make([]Entry, 0, len(s.builtin))
+	for _, entry := range s.entries(ctx) {
+		if tag != "" && !slices.ContainsFunc(entry.Tags, func(value string) bool {
+			return strings.EqualFold(value, tag)
+		}) {
+			continue
+		}
+		if query != "" && !entryMatches(entry, query) {
+			continue
+		}
+		items = append(items, entry)
+	}
+	return items
+}
+
+// Get returns the entry with the given ID.
+func (s *Service) Get(ctx context.Context, id string) (Entry, error) {
+	id = strings.TrimSpace(id)
+	for _, entry := range s.entries(ctx) {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return Entry{}, ErrEntryNotFound
+}
+
+// PlanInstall resolves an entry and the user's values into an install plan.
+func PlanInstall(entry Entry, req InstallRequest) (Plan, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = entry.ID
+	}
+	values := make(map[string]string, len(entry.Env))
+	for _, item := range entry.Env {
+		value := strings.TrimSpace(req.Env[item.Name])
+		if value == "" {
+			value = item.Default
+		}
+		if value == "" {
+			if item.Required {
+				return Plan{}, fmt.Errorf("env %s is required", item.Name)
+			}
+			continue
+		}
+		values[item.Name] = value
+	}
+	expand := func(value string) string {
+		return placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+			key := placeholderPattern.FindStringSubmatch(match)[1]
+			if resolved, ok := values[key]; ok {
+				return resolved
+			}
+			return match
+		})
+	}
+	args := make([]string, 0, len(entry.Args))
+	for _, arg := range entry.Args {
+		args = append(args, expand(arg))
+	}
+
+	plan := Plan{Name: name}
+	if !entry.Local() {
+		headers := make(map[string]string, len(entry.Headers))
+		for key, value := range entry.Headers {
+			headers[key] = expand(value)
+		}
+		plan.Server = mcp.MCPServerEntry{
+			URL:       expand(entry.URL),
+			Headers:   headers,
+			Transport: entry.Transport,
+			Auth:      entry.Auth,
+		}
+		return plan, nil
+	}
+
+	command := entry.Command
+	if entry.Install != nil {
+		pkg := entry.Install.Package
+		switch entry.Install.Runtime {
+		case RuntimeNPM:
+			if entry.Install.Version != "" {
+				pkg += "@" + entry.Install.Version
+			}
+			plan.Setup = []string{"npm", "install", "-g", pkg}
+			plan.Requires = []string{"npm", "npx"}
+			if command == "" {
+				command = "npx"
+				args = append([]string{"-y", pkg}, args...)
+			}
+		case RuntimeUVX:
+			spec := pkg
+			if entry.Install.Version != "" {
+				spec += "==" + entry.Install.Version
+			}
+			plan.Setup = []string{"uv", "tool", "install", spec}
+			plan.Requires = []string{"uv", "uvx"}
+			if command == "" {
+				command = "uvx"
+				if spec != pkg {
+					args = append([]string{"--from", spec, pkg}, args...)
+				} else {
+					args = append([]string{pkg}, args...)
+				}
+			}
+		}
+	}
+	if command != "" && !slices.Contains(plan.Requires, command) {
+		plan.Requires = append(plan.Requires, command)
+	}
+	plan.Server = mcp.MCPServerEntry{
+		Command: command,
+		Args:    args,
+		Env:     values,
+	}
+	return plan, nil
+}
+
+func (s *Service) entries(ctx context.Context) []Entry {
+	items := make([]Entry, 0, len(s.builtin))
+	seen := make(map[string]bool, len(s.builtin))
+	items = append(items, s.builtin...)
+	for _, entry := range s.builtin {
+		seen[entry.ID] = true
+	}
+	for _, url := range s.urls {
+		for _, entry := range s.remoteEntries(ctx, url) {
+			if seen[entry.ID] {
+				continue
+			}
+			seen[entry.ID] = true
+			items = append(items, entry)
+		}
+	}
+	return items
+}
+
+func (s *Service) remoteEntries(ctx context.Context, url string) []Entry {
+	s.mu.Lock()
+	cached, ok := s.remote[url]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < remoteTTL {
+		return cached.entries
+	}
+	entries, err := s.fetchRegistry(ctx, url)
+	if err != nil {
+		s.logger.Warn("fetch mcp catalog failed", slog.String("url", url), slog.Any("error", err))
+		// Keep serving the last good copy and retry after the TTL.
+		entries = cached.entries
+	}
+	s.mu.Lock()
+	s.remote[url] = remoteRegistry{entries: entries, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return entries
+}
+
+func (s *Service) fetchRegistry(ctx context.Context, url string) ([]Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryBytes))
+	if err != nil {
+		return nil, err
+	}
+	registry, err := parseRegistry(body)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(registry.Servers))
+	for _, entry := range registry.Servers {
+		if err := validateEntry(entry); err != nil {
+			s.logger.Warn("skip invalid mcp catalog entry", slog.String("url", url), slog.Any("error", err))
+			continue
+		}
+		entry.Source = url
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseRegistry decodes a registry in YAML or JSON, which is valid YAML.
+func parseRegistry(data []byte) (Registry, error) {
+	var registry Registry
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return Registry{}, err
+	}
+	return registry, nil
+}
+
+func validateEntry(entry Entry) error {
+	if !entryIDPattern.MatchString(entry.ID) {
+		return fmt.Errorf("invalid entry id %q", entry.ID)
+	}
+	if strings.TrimSpace(entry.Name) == "" {
+		return fmt.Errorf("entry %s: name is required", entry.ID)
+	}
+	hasLocal := entry.Install != nil || strings.TrimSpace(entry.Command) != ""
+	hasRemote := strings.TrimSpace(entry.URL) != ""
+	if hasLocal == hasRemote {
+		return fmt.Errorf("entry %s: exactly one of install/command or url is required", entry.ID)
+	}
+	if entry.Install != nil {
+		if entry.Install.Runtime != RuntimeNPM && entry.Install.Runtime != RuntimeUVX {
+			return fmt.Errorf("entry %s: unsupported runtime %q", entry.ID, entry.Install.Runtime)
+		}
+		if strings.TrimSpace(entry.Install.Package) == "" {
+			return fmt.Errorf("entry %s: install package is required", entry.ID)
+		}
+	}
+	for _, item := range entry.Env {
+		if !envNamePattern.MatchString(item.Name) {
+			return fmt.Errorf("entry %s: invalid env name %q", entry.ID, item.Name)
+		}
+	}
+	return nil
+}
+
+func entryMatches(entry Entry, query string) bool {
+	for _, value := range append([]string{entry.ID, entry.Name, entry.Description}, entry.Tags...) {
+		if strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+	return false
+}
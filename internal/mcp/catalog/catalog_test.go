@@ -0,0 +1,169 @@
+package catalog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+func newTestService(t *testing.T, urls ...string) *Service {
+	t.Helper()
+	cfg := config.Config{}
+	cfg.MCP.CatalogURLs = urls
+	svc, err := NewService(slog.Default(), cfg)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return svc
+}
+
+func TestBuiltinRegistryIsValid(t *testing.T) {
+	svc := newTestService(t)
+	items := svc.List(context.Background(), ListFilter{})
+	if len(items) == 0 {
+		t.Fatal("expected builtin entries")
+	}
+	seen := map[string]bool{}
+	for _, item := range items {
+		if seen[item.ID] {
+			t.Fatalf("duplicate entry id: %s", item.ID)
+		}
+		seen[item.ID] = true
+		if item.Source != SourceBuiltin {
+			t.Fatalf("unexpected source for %s: %s", item.ID, item.Source)
+		}
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	svc := newTestService(t)
+	items := svc.List(context.Background(), ListFilter{Tag: "WEB"})
+	if len(items) == 0 {
+		t.Fatal("expected entries tagged web")
+	}
+	for _, item := range items {
+		if !slices.Contains(item.Tags, "web") {
+			t.Fatalf("entry %s lacks web tag", item.ID)
+		}
+	}
+	items = svc.List(context.Background(), ListFilter{Query: "knowledge graph"})
+	if len(items) != 1 || items[0].ID != "memory" {
+		t.Fatalf("unexpected query result: %+v", items)
+	}
+}
+
+func TestRemoteRegistryMerged(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(`{"version":1,"servers":[
+			{"id":"fetch","name":"Shadowed","url":"https://example.com/mcp"},
+			{"id":"weather","name":"Weather","url":"https://example.com/weather"},
+			{"id":"Bad ID","name":"Invalid","url":"https://example.com/bad"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, srv.URL)
+	entry, err := svc.Get(context.Background(), "weather")
+	if err != nil {
+		t.Fatalf("get remote entry: %v", err)
+	}
+	if entry.Source != srv.URL {
+		t.Fatalf("unexpected source: %s", entry.Source)
+	}
+	builtin, err := svc.Get(context.Background(), "fetch")
+	if err != nil || builtin.Source != SourceBuiltin {
+		t.Fatalf("builtin entry must win over remote, got %+v (%v)", builtin, err)
+	}
+	if _, err := svc.Get(context.Background(), "Bad ID"); err != ErrEntryNotFound {
+		t.Fatalf("invalid remote entry must be skipped, got %v", err)
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("expected registry to be fetched once, got %d", got)
+	}
+}
+
+func TestPlanInstallNPM(t *testing.T) {
+	entry := Entry{
+		ID:      "search",
+		Name:    "Search",
+		Install: &Install{Runtime: RuntimeNPM, Package: "@acme/search-mcp", Version: "1.2.0"},
+		Args:    []string{"--key", "${API_KEY}", "${UNKNOWN}"},
+		Env: []EnvVar{
+			{Name: "API_KEY", Required: true, Secret: true},
+			{Name: "REGION", Default: "eu"},
+		},
+	}
+	if _, err := PlanInstall(entry, InstallRequest{}); err == nil || !strings.Contains(err.Error(), "API_KEY") {
+		t.Fatalf("expected missing env error, got %v", err)
+	}
+	plan, err := PlanInstall(entry, InstallRequest{Env: map[string]string{"API_KEY": "secret"}})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Name != "search" {
+		t.Fatalf("unexpected name: %s", plan.Name)
+	}
+	if got := strings.Join(plan.Setup, " "); got != "npm install -g @acme/search-mcp@1.2.0" {
+		t.Fatalf("unexpected setup: %s", got)
+	}
+	if got := strings.Join(plan.Requires, ","); got != "npm,npx" {
+		t.Fatalf("unexpected requires: %s", got)
+	}
+	if plan.Server.Command != "npx" {
+		t.Fatalf("unexpected command: %s", plan.Server.Command)
+	}
+	if got := strings.Join(plan.Server.Args, " "); got != "-y @acme/search-mcp@1.2.0 --key secret ${UNKNOWN}" {
+		t.Fatalf("unexpected args: %s", got)
+	}
+	if plan.Server.Env["REGION"] != "eu" || plan.Server.Env["API_KEY"] != "secret" {
+		t.Fatalf("unexpected env: %v", plan.Server.Env)
+	}
+}
+
+func TestPlanInstallRemote(t *testing.T) {
+	entry := Entry{
+		ID:      "hosted",
+		Name:    "Hosted",
+		URL:     "https://example.com/mcp",
+		Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+		Env:     []EnvVar{{Name: "TOKEN", Required: true}},
+	}
+	plan, err := PlanInstall(entry, InstallRequest{Name: "work", Env: map[string]string{"TOKEN": "abc"}})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Name != "work" || len(plan.Setup) != 0 || len(plan.Requires) != 0 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if plan.Server.Headers["Authorization"] != "Bearer abc" || len(plan.Server.Env) != 0 {
+		t.Fatalf("unexpected server: %+v", plan.Server)
+	}
+}
+
+func TestValidateEntry(t *testing.T) {
+	cases := []Entry{
+		{ID: "", Name: "x", URL: "https://example.com"},
+		{ID: "x", Name: "", URL: "https://example.com"},
+		{ID: "x", Name: "x"},
+		{ID: "x", Name: "x", URL: "https://example.com", Command: "run"},
+		{ID: "x", Name: "x", Install: &Install{Runtime: "pip", Package: "x"}},
+		{ID: "x", Name: "x", Command: "run", Env: []EnvVar{{Name: "BAD-NAME"}}},
+	}
+	for i, entry := range cases {
+		if err := validateEntry(entry); err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+	}
+	if err := validateEntry(Entry{ID: "ok", Name: "Ok", Install: &Install{Runtime: RuntimeUVX, Package: "ok"}}); err != nil {
+		t.Fatalf("expected valid entry, got %v", err)
+	}
+}
@@ -10,8 +10,13 @@ import (
 
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/db/sqlc"
+	"github.com/memohai/memoh/internal/secrets"
 )
 
+// AuthOAuth marks a remote connection that authorizes with the MCP OAuth
+// flow instead of static headers.
+const AuthOAuth = "oauth"
+
 // Connection represents a stored MCP connection for a bot.
 type Connection struct {
 	ID        string         `json:"id"`
@@ -24,6 +29,49 @@ type Connection struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 }
 
+// UsesOAuth reports whether the connection authorizes with OAuth.
+func (c Connection) UsesOAuth() bool {
+	if c.Type != "http" && c.Type != "sse" {
+		return false
+	}
+	auth, _ := c.Config["auth"].(string)
+	return strings.EqualFold(strings.TrimSpace(auth), AuthOAuth)
+}
+
+// Timeout returns the per-call timeout of the connection's tools, or zero
+// for the gateway default.
+func (c Connection) Timeout() time.Duration {
+	return time.Duration(configSeconds(c.Config, "timeout")) * time.Second
+}
+
+// Masked returns a copy of the connection with env and header values masked
+// for display.
+func (c Connection) Masked() Connection {
+	if c.Config == nil {
+		return c
+	}
+	config := make(map[string]any, len(c.Config))
+	for key, value := range c.Config {
+		config[key] = value
+	}
+	for _, key := range []string{"env", "headers"} {
+		if values := configStringMap(c.Config, key); values != nil {
+			config[key] = secrets.MaskMap(values)
+		}
+	}
+	c.Config = config
+	return c
+}
+
+// MaskConnections masks every connection of a list.
+func MaskConnections(items []Connection) []Connection {
+	masked := make([]Connection, 0, len(items))
+	for _, item := range items {
+		masked = append(masked, item.Masked())
+	}
+	return masked
+}
+
 // UpsertRequest accepts standard mcpServers item format.
 // Type is auto-inferred: command present -> stdio, url present -> http (default) or sse (if transport:"sse").
 type UpsertRequest struct {
@@ -35,7 +83,11 @@ type UpsertRequest struct {
 	URL       string            `json:"url,omitempty"`
 	Headers   map[string]string `json:"headers,omitempty"`
 	Transport string            `json:"transport,omitempty"`
-	Active    *bool             `json:"is_active,omitempty"`
+	// Auth is "oauth" for remote servers that require the MCP OAuth flow.
+	Auth string `json:"auth,omitempty"`
+	// Timeout bounds each tool call to the server, in seconds.
+	Timeout int   `json:"timeout,omitempty"`
+	Active  *bool `json:"is_active,omitempty"`
 }
 
 // ImportRequest accepts a standard mcpServers dict for batch import.
@@ -52,6 +104,15 @@ type MCPServerEntry struct {
 	URL       string            `json:"url,omitempty"`
 	Headers   map[string]string `json:"headers,omitempty"`
 	Transport string            `json:"transport,omitempty"`
+	Auth      string            `json:"auth,omitempty"`
+	Timeout   int               `json:"timeout,omitempty"`
+}
+
+// Masked returns a copy of the entry with env and header values masked.
+func (e MCPServerEntry) Masked() MCPServerEntry {
+	e.Env = secrets.MaskMap(e.Env)
+	e.Headers = secrets.MaskMap(e.Headers)
+	return e
 }
 
 // ListResponse wraps MCP connection list responses.
@@ -198,10 +259,19 @@ func (s *ConnectionService) Update(ctx context.Context, botID, id string, req Up
 	if name == "" {
 		return Connection{}, fmt.Errorf("name is required")
 	}
+	existing, existingErr := s.Get(ctx, botID, id)
+	if existingErr == nil {
+		req = restoreMaskedValues(req, existing)
+	}
 	mcpType, config, err := inferTypeAndConfig(req)
 	if err != nil {
 		return Connection{}, err
 	}
+	if existingErr == nil && urlChanged(existing.Config, config) {
+		if err := s.queries.DeleteMCPOAuthToken(ctx, connUUID); err != nil {
+			return Connection{}, err
+		}
+	}
 	active := true
 	if req.Active != nil {
 		active = *req.Active
@@ -239,6 +309,14 @@ func (s *ConnectionService) Import(ctx context.Context, botID string, req Import
 	if len(req.MCPServers) == 0 {
 		return []Connection{}, nil
 	}
+	current, err := s.ListByBot(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]Connection, len(current))
+	for _, conn := range current {
+		existing[conn.Name] = conn
+	}
 	results := make([]Connection, 0, len(req.MCPServers))
 	for name, entry := range req.MCPServers {
 		name = strings.TrimSpace(name)
@@ -246,10 +324,23 @@ func (s *ConnectionService) Import(ctx context.Context, botID string, req Import
 			continue
 		}
 		upsert := entryToUpsertRequest(name, entry)
+		conn, found := existing[name]
+		if found {
+			upsert = restoreMaskedValues(upsert, conn)
+		}
 		mcpType, config, err := inferTypeAndConfig(upsert)
 		if err != nil {
 			return nil, fmt.Errorf("server %q: %w", name, err)
 		}
+		if found && urlChanged(conn.Config, config) {
+			connUUID, err := db.ParseUUID(conn.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.queries.DeleteMCPOAuthToken(ctx, connUUID); err != nil {
+				return nil, fmt.Errorf("server %q: %w", name, err)
+			}
+		}
 		configPayload, err := json.Marshal(config)
 		if err != nil {
 			return nil, err
@@ -263,11 +354,11 @@ func (s *ConnectionService) Import(ctx context.Context, botID string, req Import
 		if err != nil {
 			return nil, fmt.Errorf("server %q: %w", name, err)
 		}
-		conn, err := normalizeMCPConnection(row)
+		updated, err := normalizeMCPConnection(row)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, conn)
+		results = append(results, updated)
 	}
 	return results, nil
 }
@@ -368,7 +459,14 @@ func inferTypeAndConfig(req UpsertRequest) (string, map[string]any, error) {
 		return "", nil, fmt.Errorf("command and url are mutually exclusive")
 	}
 
+	if req.Timeout < 0 {
+		return "", nil, fmt.Errorf("timeout must not be negative")
+	}
+
 	config := map[string]any{}
+	if req.Timeout > 0 {
+		config["timeout"] = req.Timeout
+	}
 
 	if hasCommand {
 		config["command"] = strings.TrimSpace(req.Command)
@@ -388,6 +486,13 @@ func inferTypeAndConfig(req UpsertRequest) (string, map[string]any, error) {
 	if len(req.Headers) > 0 {
 		config["headers"] = req.Headers
 	}
+	switch auth := strings.ToLower(strings.TrimSpace(req.Auth)); auth {
+	case "":
+	case AuthOAuth:
+		config["auth"] = AuthOAuth
+	default:
+		return "", nil, fmt.Errorf("unsupported auth %q", req.Auth)
+	}
 	transport := strings.ToLower(strings.TrimSpace(req.Transport))
 	if transport == "sse" {
 		return "sse", config, nil
@@ -406,12 +511,14 @@ func entryToUpsertRequest(name string, entry MCPServerEntry) UpsertRequest {
 		URL:       entry.URL,
 		Headers:   entry.Headers,
 		Transport: entry.Transport,
+		Auth:      entry.Auth,
+		Timeout:   entry.Timeout,
 	}
 }
 
 // connectionToExportEntry converts a stored connection to standard mcpServers entry.
 func connectionToExportEntry(conn Connection) MCPServerEntry {
-	entry := MCPServerEntry{}
+	entry := MCPServerEntry{Timeout: configSeconds(conn.Config, "timeout")}
 	switch conn.Type {
 	case "stdio":
 		entry.Command, _ = conn.Config["command"].(string)
@@ -427,34 +534,65 @@ func connectionToExportEntry(conn Connection) MCPServerEntry {
 				entry.Args = v
 			}
 		}
-		if rawEnv, ok := conn.Config["env"]; ok {
-			if m, ok := rawEnv.(map[string]any); ok {
-				entry.Env = make(map[string]string, len(m))
-				for k, v := range m {
-					if s, ok := v.(string); ok {
-						entry.Env[k] = s
-					}
-				}
-			}
-		}
+		entry.Env = configStringMap(conn.Config, "env")
 		if cwd, ok := conn.Config["cwd"].(string); ok && cwd != "" {
 			entry.Cwd = cwd
 		}
 	case "http", "sse":
 		entry.URL, _ = conn.Config["url"].(string)
-		if rawHeaders, ok := conn.Config["headers"]; ok {
-			if m, ok := rawHeaders.(map[string]any); ok {
-				entry.Headers = make(map[string]string, len(m))
-				for k, v := range m {
-					if s, ok := v.(string); ok {
-						entry.Headers[k] = s
-					}
-				}
-			}
-		}
+		entry.Headers = configStringMap(conn.Config, "headers")
 		if conn.Type == "sse" {
 			entry.Transport = "sse"
 		}
+		if conn.UsesOAuth() {
+			entry.Auth = AuthOAuth
+		}
 	}
 	return entry
 }
+
+// configStringMap reads a string map such as env or headers from a decoded
+// connection config.
+// urlChanged reports whether an edit moves a connection to another server
+// URL. OAuth tokens were issued for the old URL and are deleted then.
+func urlChanged(before, after map[string]any) bool {
+	oldURL, _ := before["url"].(string)
+	newURL, _ := after["url"].(string)
+	return strings.TrimSpace(oldURL) != strings.TrimSpace(newURL)
+}
+
+func configStringMap(config map[string]any, key string) map[string]string {
+	switch raw := config[key].(type) {
+	case map[string]string:
+		return raw
+	case map[string]any:
+		values := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				values[k] = s
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// configSeconds reads a number of seconds from a decoded connection config,
+// where JSON numbers arrive as float64.
+func configSeconds(config map[string]any, key string) int {
+	switch v := config[key].(type) {
+	case int:
+		return max(v, 0)
+	case float64:
+		return max(int(v), 0)
+	}
+	return 0
+}
+
+// restoreMaskedValues keeps the stored env and header values a client sent
+// back in their masked form.
+func restoreMaskedValues(req UpsertRequest, existing Connection) UpsertRequest {
+	req.Env = secrets.UnmaskMap(req.Env, configStringMap(existing.Config, "env"))
+	req.Headers = secrets.UnmaskMap(req.Headers, configStringMap(existing.Config, "headers"))
+	return req
+}
@@ -57,6 +57,26 @@ func TestInferTypeAndConfig_HTTP(t *testing.T) {
 	}
 }
 
+func TestInferTypeAndConfig_OAuth(t *testing.T) {
+	typ, config, err := inferTypeAndConfig(UpsertRequest{Name: "linear", URL: "https://mcp.linear.app/mcp", Auth: "OAuth"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn := Connection{Type: typ, Config: config}
+	if !conn.UsesOAuth() {
+		t.Fatalf("expected oauth connection, got %v", config)
+	}
+	if entry := connectionToExportEntry(conn); entry.Auth != AuthOAuth {
+		t.Fatalf("expected auth in export, got %q", entry.Auth)
+	}
+	if _, _, err := inferTypeAndConfig(UpsertRequest{Name: "x", URL: "https://example.com", Auth: "basic"}); err == nil {
+		t.Fatal("expected error for unsupported auth")
+	}
+	if (Connection{Type: "stdio", Config: map[string]any{"auth": AuthOAuth}}).UsesOAuth() {
+		t.Fatal("expected stdio connection to never use oauth")
+	}
+}
+
 func TestInferTypeAndConfig_SSE(t *testing.T) {
 	req := UpsertRequest{
 		Name:      "sse-server",
@@ -171,3 +191,39 @@ func TestEntryToUpsertRequest(t *testing.T) {
 		t.Fatalf("expected 2 args, got %v", req.Args)
 	}
 }
+
+func TestConnectionMasked(t *testing.T) {
+	conn := Connection{
+		Type: "http",
+		Config: map[string]any{
+			"url":     "https://example.com/mcp",
+			"headers": map[string]any{"Authorization": "Bearer abcdefghijkl", "X-Token": "${secret:token}"},
+		},
+	}
+	masked := conn.Masked()
+	headers := configStringMap(masked.Config, "headers")
+	if headers["Authorization"] != "Bear***************" {
+		t.Fatalf("expected masked header, got %q", headers["Authorization"])
+	}
+	if headers["X-Token"] != "${secret:token}" {
+		t.Fatalf("expected reference kept, got %q", headers["X-Token"])
+	}
+	if configStringMap(conn.Config, "headers")["Authorization"] != "Bearer abcdefghijkl" {
+		t.Fatal("Masked modified the connection")
+	}
+
+	req := restoreMaskedValues(UpsertRequest{Headers: headers}, conn)
+	if req.Headers["Authorization"] != "Bearer abcdefghijkl" {
+		t.Fatalf("expected masked header restored, got %q", req.Headers["Authorization"])
+	}
+}
+
+func TestURLChanged(t *testing.T) {
+	before := map[string]any{"url": "https://a.example/mcp", "auth": "oauth"}
+	if urlChanged(before, map[string]any{"url": " https://a.example/mcp ", "auth": "oauth", "timeout": 30}) {
+		t.Fatal("expected an edit that keeps the url to be unchanged")
+	}
+	if !urlChanged(before, map[string]any{"url": "https://b.example/mcp", "auth": "oauth"}) {
+		t.Fatal("expected a new url to be a change")
+	}
+}
@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// sealer encrypts stored credentials with AES-256-GCM. The key is derived
+// from the server secret, so rotating the secret invalidates stored tokens
+// and connections have to be authorized again.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(secret string) (*sealer, error) {
+	if strings.TrimSpace(secret) == "" {
+		return nil, fmt.Errorf("encryption secret is required")
+	}
+	key := sha256.Sum256([]byte("memoh-mcp-oauth:" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts plaintext bound to aad. Empty values stay empty.
+func (s *sealer) seal(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+func (s *sealer) open(ciphertext, aad string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("decode sealed value: %w", err)
+	}
+	size := s.aead.NonceSize()
+	if len(raw) < size {
+		return "", fmt.Errorf("sealed value is too short")
+	}
+	plaintext, err := s.aead.Open(nil, raw[:size], raw[size:], []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("decrypt sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
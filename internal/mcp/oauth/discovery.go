@@ -0,0 +1,219 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxMetadataBytes caps metadata and token responses read from remote servers.
+const maxMetadataBytes = 1 << 20
+
+// ServerMetadata is the subset of OAuth authorization server metadata
+// (RFC 8414) the authorization flow needs.
+type ServerMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// protectedResourceMetadata is OAuth protected resource metadata (RFC 9728).
+type protectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+var errMetadataNotFound = errors.New("metadata not found")
+
+// discovery is the outcome of discovering how to authorize against an MCP
+// server.
+type discovery struct {
+	Server ServerMetadata
+	Scopes []string
+}
+
+// discover finds the authorization server of the MCP server at resourceURL.
+// It follows the protected resource metadata advertised by the server and
+// falls back to treating the server's origin as the authorization server.
+func discover(ctx context.Context, client *http.Client, resourceURL string) (discovery, error) {
+	resource, err := url.Parse(strings.TrimSpace(resourceURL))
+	if err != nil || resource.Scheme == "" || resource.Host == "" {
+		return discovery{}, fmt.Errorf("invalid mcp url %q", resourceURL)
+	}
+
+	var prm protectedResourceMetadata
+	found := false
+	for _, candidate := range protectedResourceCandidates(ctx, client, resource) {
+		if err := getJSON(ctx, client, candidate, &prm); err == nil && len(prm.AuthorizationServers) > 0 {
+			found = true
+			break
+		}
+	}
+
+	issuer := originOf(resource)
+	scopes := []string(nil)
+	if found {
+		issuer = strings.TrimSpace(prm.AuthorizationServers[0])
+		scopes = prm.ScopesSupported
+	}
+	issuerURL, err := url.Parse(issuer)
+	if err != nil || issuerURL.Scheme == "" || issuerURL.Host == "" {
+		return discovery{}, fmt.Errorf("invalid authorization server %q", issuer)
+	}
+
+	var meta ServerMetadata
+	metaFound := false
+	for _, candidate := range serverMetadataCandidates(issuerURL) {
+		if err := getJSON(ctx, client, candidate, &meta); err == nil && meta.AuthorizationEndpoint != "" && meta.TokenEndpoint != "" {
+			metaFound = true
+			break
+		}
+	}
+	if !metaFound {
+		if found {
+			return discovery{}, fmt.Errorf("authorization server %s publishes no metadata", issuer)
+		}
+		// Servers without any metadata use the default endpoints at the
+		// MCP server's origin.
+		origin := originOf(resource)
+		meta = ServerMetadata{
+			Issuer:                origin,
+			AuthorizationEndpoint: origin + "/authorize",
+			TokenEndpoint:         origin + "/token",
+			RegistrationEndpoint:  origin + "/register",
+		}
+	}
+	if meta.Issuer == "" {
+		meta.Issuer = issuer
+	}
+	if len(scopes) == 0 {
+		scopes = meta.ScopesSupported
+	}
+	if len(meta.CodeChallengeMethodsSupported) > 0 && !containsFold(meta.CodeChallengeMethodsSupported, "S256") {
+		return discovery{}, fmt.Errorf("authorization server %s does not support PKCE with S256", meta.Issuer)
+	}
+	return discovery{Server: meta, Scopes: scopes}, nil
+}
+
+// protectedResourceCandidates lists where the resource metadata may live:
+// the URL advertised in a 401 challenge first, then the well-known paths.
+func protectedResourceCandidates(ctx context.Context, client *http.Client, resource *url.URL) []string {
+	candidates := make([]string, 0, 3)
+	if advertised := probeResourceMetadata(ctx, client, resource.String()); advertised != "" {
+		candidates = append(candidates, advertised)
+	}
+	origin := originOf(resource)
+	if path := strings.TrimSuffix(resource.EscapedPath(), "/"); path != "" {
+		candidates = append(candidates, origin+"/.well-known/oauth-protected-resource"+path)
+	}
+	return append(candidates, origin+"/.well-known/oauth-protected-resource")
+}
+
+func serverMetadataCandidates(issuer *url.URL) []string {
+	origin := originOf(issuer)
+	path := strings.TrimSuffix(issuer.EscapedPath(), "/")
+	if path == "" {
+		return []string{
+			origin + "/.well-known/oauth-authorization-server",
+			origin + "/.well-known/openid-configuration",
+		}
+	}
+	return []string{
+		origin + "/.well-known/oauth-authorization-server" + path,
+		origin + "/.well-known/openid-configuration" + path,
+		origin + path + "/.well-known/openid-configuration",
+	}
+}
+
+// probeResourceMetadata makes an unauthenticated request to the MCP server
+// and returns the resource_metadata URL of its Bearer challenge, if any.
+func probeResourceMetadata(ctx context.Context, client *http.Client, resourceURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxMetadataBytes))
+	if resp.StatusCode != http.StatusUnauthorized {
+		return ""
+	}
+	for _, challenge := range resp.Header.Values("WWW-Authenticate") {
+		if value := challengeParam(challenge, "resource_metadata"); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// challengeParam extracts an auth-param from a WWW-Authenticate header value.
+func challengeParam(challenge, name string) string {
+	lower := strings.ToLower(challenge)
+	key := strings.ToLower(name) + "="
+	idx := strings.Index(lower, key)
+	for idx > 0 && lower[idx-1] != ' ' && lower[idx-1] != ',' {
+		next := strings.Index(lower[idx+1:], key)
+		if next < 0 {
+			return ""
+		}
+		idx += next + 1
+	}
+	if idx < 0 {
+		return ""
+	}
+	value := challenge[idx+len(key):]
+	if strings.HasPrefix(value, `"`) {
+		value = value[1:]
+		if end := strings.Index(value, `"`); end >= 0 {
+			return value[:end]
+		}
+		return value
+	}
+	if end := strings.IndexAny(value, ", "); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value)
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return errMetadataNotFound
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxMetadataBytes)).Decode(out)
+}
+
+func originOf(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
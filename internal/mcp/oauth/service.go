@@ -0,0 +1,447 @@
+// Package oauth implements OAuth 2.1 authorization for remote MCP connections:
+// authorization server discovery, dynamic client registration, the browser
+// redirect flow with PKCE, encrypted token storage and refresh.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+const (
+	// pendingTTL bounds how long a started authorization can be completed.
+	pendingTTL = 10 * time.Minute
+	// refreshSkew refreshes access tokens shortly before they expire.
+	refreshSkew = 60 * time.Second
+	clientName  = "Memoh"
+)
+
+var (
+	// ErrAuthorizationRequired means the connection has no usable token and
+	// has to be authorized in the browser.
+	ErrAuthorizationRequired = errors.New("mcp connection requires oauth authorization")
+	// ErrInvalidState means the callback state is unknown or expired.
+	ErrInvalidState = errors.New("oauth state is invalid or expired")
+	// ErrNotOAuth means the connection is not configured for OAuth.
+	ErrNotOAuth = errors.New("mcp connection does not use oauth")
+)
+
+// AuthorizationStatus describes the stored authorization of a connection.
+type AuthorizationStatus struct {
+	Authorized bool       `json:"authorized"`
+	Issuer     string     `json:"issuer,omitempty"`
+	Scope      string     `json:"scope,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// Completion is the result of a finished authorization.
+type Completion struct {
+	BotID        string
+	ConnectionID string
+	ReturnURL    string
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Service runs the authorization flow and hands out access tokens.
+type Service struct {
+	logger   *slog.Logger
+	store    TokenStore
+	pendings PendingStore
+	sealer   *sealer
+	client   *http.Client
+	now      func() time.Time
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewService creates a Service. Secret derives the key that encrypts stored
+// tokens and client credentials.
+func NewService(log *slog.Logger, store TokenStore, secret string) (*Service, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	if store == nil {
+		return nil, fmt.Errorf("token store is required")
+	}
+	sealer, err := newSealer(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		logger:   log.With(slog.String("service", "mcp_oauth")),
+		store:    store,
+		pendings: newMemoryPendingStore(),
+		sealer:   sealer,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+		locks:    map[string]*sync.Mutex{},
+	}, nil
+}
+
+// SetPendingStore replaces the in-process store of started authorizations.
+func (s *Service) SetPendingStore(store PendingStore) {
+	if store == nil {
+		return
+	}
+	s.pendings = store
+}
+
+// StartAuthorization discovers the authorization server of conn, registers
+// a client and returns the URL the user has to open in the browser.
+func (s *Service) StartAuthorization(ctx context.Context, conn mcp.Connection, redirectURI, returnURL string) (string, error) {
+	if !conn.UsesOAuth() {
+		return "", ErrNotOAuth
+	}
+	resource := connectionURL(conn)
+	found, err := discover(ctx, s.client, resource)
+	if err != nil {
+		return "", fmt.Errorf("discover authorization server: %w", err)
+	}
+	clientID, clientSecret, err := s.registerClient(ctx, found.Server, redirectURI)
+	if err != nil {
+		return "", err
+	}
+
+	verifier, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	state, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := url.Parse(found.Server.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	query.Set("resource", resource)
+	if len(found.Scopes) > 0 {
+		query.Set("scope", strings.Join(found.Scopes, " "))
+	}
+	authURL.RawQuery = query.Encode()
+
+	// The verifier and client secret are sealed to the state, so a leaked
+	// row cannot be used to finish another authorization.
+	sealedVerifier, err := s.sealer.seal(verifier, state)
+	if err != nil {
+		return "", err
+	}
+	sealedSecret, err := s.sealer.seal(clientSecret, state)
+	if err != nil {
+		return "", err
+	}
+	if err := s.pendings.Put(ctx, Pending{
+		State:        state,
+		BotID:        conn.BotID,
+		ConnectionID: conn.ID,
+		Resource:     resource,
+		Verifier:     sealedVerifier,
+		RedirectURI:  redirectURI,
+		ReturnURL:    returnURL,
+		Server:       found.Server,
+		ClientID:     clientID,
+		ClientSecret: sealedSecret,
+		ExpiresAt:    s.now().Add(pendingTTL),
+	}); err != nil {
+		return "", fmt.Errorf("store pending authorization: %w", err)
+	}
+	return authURL.String(), nil
+}
+
+// CompleteAuthorization exchanges the authorization code of a callback and
+// stores the resulting tokens.
+func (s *Service) CompleteAuthorization(ctx context.Context, state, code string) (Completion, error) {
+	if strings.TrimSpace(state) == "" {
+		return Completion{}, ErrInvalidState
+	}
+	pending, ok, err := s.pendings.Take(ctx, state)
+	if err != nil {
+		return Completion{}, err
+	}
+	if !ok || s.now().After(pending.ExpiresAt) {
+		return Completion{}, ErrInvalidState
+	}
+	completion := Completion{BotID: pending.BotID, ConnectionID: pending.ConnectionID, ReturnURL: pending.ReturnURL}
+	if strings.TrimSpace(code) == "" {
+		return completion, fmt.Errorf("authorization code is missing")
+	}
+	verifier, err := s.sealer.open(pending.Verifier, state)
+	if err != nil {
+		return completion, err
+	}
+	clientSecret, err := s.sealer.open(pending.ClientSecret, state)
+	if err != nil {
+		return completion, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", pending.RedirectURI)
+	form.Set("code_verifier", verifier)
+	form.Set("resource", pending.Resource)
+	token, err := s.requestToken(ctx, pending.Server.TokenEndpoint, pending.ClientID, clientSecret, form)
+	if err != nil {
+		return completion, err
+	}
+	record := Record{
+		ConnectionID:  pending.ConnectionID,
+		BotID:         pending.BotID,
+		Resource:      pending.Resource,
+		Issuer:        pending.Server.Issuer,
+		TokenEndpoint: pending.Server.TokenEndpoint,
+		ClientID:      pending.ClientID,
+	}
+	if err := s.saveToken(ctx, record, clientSecret, token, ""); err != nil {
+		return completion, err
+	}
+	return completion, nil
+}
+
+// AccessToken returns a valid access token for conn, refreshing it when it
+// is about to expire. Tokens issued for another server URL are never used,
+// so editing the URL of a connection requires a new authorization.
+func (s *Service) AccessToken(ctx context.Context, conn mcp.Connection) (string, error) {
+	lock := s.connectionLock(conn.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	record, ok, err := s.store.Get(ctx, conn.ID)
+	if err != nil {
+		return "", err
+	}
+	if !ok || record.Resource != connectionURL(conn) {
+		return "", ErrAuthorizationRequired
+	}
+	accessToken, err := s.sealer.open(record.AccessToken, conn.ID)
+	if err != nil {
+		return "", err
+	}
+	if accessToken != "" && (record.ExpiresAt.IsZero() || s.now().Add(refreshSkew).Before(record.ExpiresAt)) {
+		return accessToken, nil
+	}
+
+	refreshToken, err := s.sealer.open(record.RefreshToken, conn.ID)
+	if err != nil {
+		return "", err
+	}
+	if refreshToken == "" {
+		return "", ErrAuthorizationRequired
+	}
+	clientSecret, err := s.sealer.open(record.ClientSecret, conn.ID)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	form.Set("resource", record.Resource)
+	token, err := s.requestToken(ctx, record.TokenEndpoint, record.ClientID, clientSecret, form)
+	if err != nil {
+		s.logger.Warn("mcp oauth refresh failed",
+			slog.String("connection_id", conn.ID),
+			slog.Any("error", err))
+		return "", fmt.Errorf("%w: %v", ErrAuthorizationRequired, err)
+	}
+	if err := s.saveToken(ctx, record, clientSecret, token, refreshToken); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// Status reports the stored authorization of conn.
+func (s *Service) Status(ctx context.Context, conn mcp.Connection) (AuthorizationStatus, error) {
+	record, ok, err := s.store.Get(ctx, conn.ID)
+	if err != nil || !ok || record.Resource != connectionURL(conn) {
+		return AuthorizationStatus{}, err
+	}
+	status := AuthorizationStatus{
+		Authorized: true,
+		Issuer:     record.Issuer,
+		Scope:      record.Scope,
+	}
+	if !record.ExpiresAt.IsZero() {
+		expiresAt := record.ExpiresAt
+		status.ExpiresAt = &expiresAt
+	}
+	if !record.UpdatedAt.IsZero() {
+		updatedAt := record.UpdatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	return status, nil
+}
+
+// Revoke forgets the stored tokens of conn.
+func (s *Service) Revoke(ctx context.Context, conn mcp.Connection) error {
+	lock := s.connectionLock(conn.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	return s.store.Delete(ctx, conn.ID)
+}
+
+// saveToken seals and stores token on top of record. The previous refresh
+// token is kept when the server does not rotate it.
+func (s *Service) saveToken(ctx context.Context, record Record, clientSecret string, token tokenResponse, previousRefresh string) error {
+	var err error
+	if record.ClientSecret, err = s.sealer.seal(clientSecret, record.ConnectionID); err != nil {
+		return err
+	}
+	if record.AccessToken, err = s.sealer.seal(token.AccessToken, record.ConnectionID); err != nil {
+		return err
+	}
+	refreshToken := token.RefreshToken
+	if refreshToken == "" {
+		refreshToken = previousRefresh
+	}
+	if record.RefreshToken, err = s.sealer.seal(refreshToken, record.ConnectionID); err != nil {
+		return err
+	}
+	record.TokenType = token.TokenType
+	if record.TokenType == "" {
+		record.TokenType = "Bearer"
+	}
+	if token.Scope != "" {
+		record.Scope = token.Scope
+	}
+	record.ExpiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		record.ExpiresAt = s.now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC()
+	}
+	return s.store.Save(ctx, record)
+}
+
+// registerClient registers Memoh as a public client (RFC 7591).
+func (s *Service) registerClient(ctx context.Context, server ServerMetadata, redirectURI string) (string, string, error) {
+	if server.RegistrationEndpoint == "" {
+		return "", "", fmt.Errorf("authorization server %s does not support dynamic client registration", server.Issuer)
+	}
+	body, err := json.Marshal(map[string]any{
+		"client_name":                clientName,
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.RegistrationEndpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("register client: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+	if err != nil {
+		return "", "", fmt.Errorf("register client: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", "", fmt.Errorf("register client: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	var registered struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.Unmarshal(raw, &registered); err != nil {
+		return "", "", fmt.Errorf("register client: %w", err)
+	}
+	if registered.ClientID == "" {
+		return "", "", fmt.Errorf("register client: response has no client_id")
+	}
+	return registered.ClientID, registered.ClientSecret, nil
+}
+
+func (s *Service) requestToken(ctx context.Context, endpoint, clientID, clientSecret string, form url.Values) (tokenResponse, error) {
+	form.Set("client_id", clientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("token request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMetadataBytes)).Decode(&token); err != nil {
+		return tokenResponse{}, fmt.Errorf("token request: status %d: %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		if token.ErrorDescription != "" {
+			return tokenResponse{}, fmt.Errorf("token request: %s: %s", token.Error, token.ErrorDescription)
+		}
+		return tokenResponse{}, fmt.Errorf("token request: %s", token.Error)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return tokenResponse{}, fmt.Errorf("token request: status %d without access token", resp.StatusCode)
+	}
+	return token, nil
+}
+
+func (s *Service) connectionLock(connectionID string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[connectionID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[connectionID] = lock
+	}
+	return lock
+}
+
+func connectionURL(conn mcp.Connection) string {
+	raw, _ := conn.Config["url"].(string)
+	return strings.TrimSpace(raw)
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,270 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+type fakeTokenStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func (f *fakeTokenStore) Get(_ context.Context, connectionID string) (Record, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[connectionID]
+	return record, ok, nil
+}
+
+func (f *fakeTokenStore) Save(_ context.Context, record Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[record.ConnectionID] = record
+	return nil
+}
+
+func (f *fakeTokenStore) Delete(_ context.Context, connectionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, connectionID)
+	return nil
+}
+
+// fakeAuthServer is an MCP server that delegates to an authorization server
+// on the same origin.
+type fakeAuthServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	verifier string
+	forms    []url.Values
+	issued   int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	f := &fakeAuthServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="`+f.URL+`/meta/resource"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/meta/resource", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"resource":              f.URL + "/mcp",
+			"authorization_servers": []string{f.URL + "/auth"},
+			"scopes_supported":      []string{"tools"},
+		})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server/auth", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(ServerMetadata{
+			Issuer:                        f.URL + "/auth",
+			AuthorizationEndpoint:         f.URL + "/auth/authorize",
+			TokenEndpoint:                 f.URL + "/auth/token",
+			RegistrationEndpoint:          f.URL + "/auth/register",
+			CodeChallengeMethodsSupported: []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/auth/register", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"client_id": "client-1"})
+	})
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		f.forms = append(f.forms, r.PostForm)
+		f.issued++
+		issued := f.issued
+		f.mu.Unlock()
+		resp := map[string]any{"access_token": "access-" + string(rune('0'+issued)), "token_type": "Bearer", "expires_in": 3600}
+		if r.PostForm.Get("grant_type") == "authorization_code" {
+			resp["refresh_token"] = "refresh-1"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func newTestService(t *testing.T, store TokenStore) *Service {
+	t.Helper()
+	s, err := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), store, "secret")
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return s
+}
+
+func TestSealerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s, err := newSealer("secret")
+	if err != nil {
+		t.Fatalf("new sealer: %v", err)
+	}
+	sealed, err := s.seal("token", "conn-1")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if sealed == "" || strings.Contains(sealed, "token") {
+		t.Fatalf("expected ciphertext, got %q", sealed)
+	}
+	if opened, err := s.open(sealed, "conn-1"); err != nil || opened != "token" {
+		t.Fatalf("expected round trip, got %q (%v)", opened, err)
+	}
+	if _, err := s.open(sealed, "conn-2"); err == nil {
+		t.Fatal("expected ciphertext to be bound to its connection")
+	}
+	if _, err := newSealer(" "); err == nil {
+		t.Fatal("expected empty secret to be rejected")
+	}
+}
+
+func TestChallengeParam(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{header: `Bearer resource_metadata="https://a.example/meta"`, want: "https://a.example/meta"},
+		{header: `Bearer error="invalid_token", resource_metadata=https://b.example/meta`, want: "https://b.example/meta"},
+		{header: `Bearer x_resource_metadata="nope"`, want: ""},
+		{header: `Bearer realm="x"`, want: ""},
+	}
+	for _, tc := range cases {
+		if got := challengeParam(tc.header, "resource_metadata"); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestAuthorizationFlow(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeAuthServer(t)
+	store := &fakeTokenStore{records: map[string]Record{}}
+	s := newTestService(t, store)
+	conn := mcp.Connection{
+		ID:     "conn-1",
+		BotID:  "bot-1",
+		Type:   "http",
+		Config: map[string]any{"url": server.URL + "/mcp", "auth": "oauth"},
+	}
+	ctx := context.Background()
+
+	if _, err := s.AccessToken(ctx, conn); !errors.Is(err, ErrAuthorizationRequired) {
+		t.Fatalf("expected ErrAuthorizationRequired, got %v", err)
+	}
+
+	authURL, err := s.StartAuthorization(ctx, conn, "https://memoh.example/mcp/oauth/callback", "/bots/bot-1")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := parsed.Query()
+	if parsed.Path != "/auth/authorize" || query.Get("client_id") != "client-1" || query.Get("code_challenge_method") != "S256" || query.Get("scope") != "tools" {
+		t.Fatalf("unexpected authorization url %s", authURL)
+	}
+	if query.Get("resource") != server.URL+"/mcp" {
+		t.Fatalf("expected resource indicator, got %q", query.Get("resource"))
+	}
+
+	pending := s.pendings.(*memoryPendingStore).items[query.Get("state")]
+	if pending.Verifier == "" || pending.ConnectionID != "conn-1" {
+		t.Fatalf("expected pending authorization to be stored, got %+v", pending)
+	}
+
+	if _, err := s.CompleteAuthorization(ctx, "unknown", "code"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState, got %v", err)
+	}
+	completion, err := s.CompleteAuthorization(ctx, query.Get("state"), "code-1")
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if completion.BotID != "bot-1" || completion.ConnectionID != "conn-1" || completion.ReturnURL != "/bots/bot-1" {
+		t.Fatalf("unexpected completion %+v", completion)
+	}
+	if _, err := s.CompleteAuthorization(ctx, query.Get("state"), "code-1"); !errors.Is(err, ErrInvalidState) {
+		t.Fatal("expected state to be single use")
+	}
+	if form := server.forms[0]; form.Get("code_verifier") == "" || form.Get("code") != "code-1" {
+		t.Fatalf("unexpected token exchange form %v", form)
+	}
+	if server.forms[0].Get("code_verifier") == pending.Verifier {
+		t.Fatal("expected verifier to be stored sealed")
+	}
+
+	record := store.records["conn-1"]
+	if record.AccessToken == "access-1" || record.RefreshToken == "refresh-1" {
+		t.Fatal("expected tokens to be stored encrypted")
+	}
+	token, err := s.AccessToken(ctx, conn)
+	if err != nil || token != "access-1" {
+		t.Fatalf("expected stored access token, got %q (%v)", token, err)
+	}
+
+	// Move past expiry so the next call refreshes.
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	token, err = s.AccessToken(ctx, conn)
+	if err != nil || token != "access-2" {
+		t.Fatalf("expected refreshed access token, got %q (%v)", token, err)
+	}
+	if form := server.forms[1]; form.Get("grant_type") != "refresh_token" || form.Get("refresh_token") != "refresh-1" {
+		t.Fatalf("unexpected refresh form %v", form)
+	}
+	refresh, err := s.sealer.open(store.records["conn-1"].RefreshToken, "conn-1")
+	if err != nil || refresh != "refresh-1" {
+		t.Fatalf("expected refresh token to be kept, got %q (%v)", refresh, err)
+	}
+
+	// The tokens were issued for the old URL and must not follow an edit.
+	moved := conn
+	moved.Config = map[string]any{"url": "https://other.example/mcp", "auth": "oauth"}
+	if _, err := s.AccessToken(ctx, moved); !errors.Is(err, ErrAuthorizationRequired) {
+		t.Fatalf("expected ErrAuthorizationRequired after the url changed, got %v", err)
+	}
+	if len(server.forms) != 2 {
+		t.Fatalf("expected no token request for the new url, got %d requests", len(server.forms))
+	}
+	if status, _ := s.Status(ctx, moved); status.Authorized {
+		t.Fatal("expected connection with a new url to be unauthorized")
+	}
+
+	status, err := s.Status(ctx, conn)
+	if err != nil || !status.Authorized || status.Issuer != server.URL+"/auth" || status.ExpiresAt == nil {
+		t.Fatalf("unexpected status %+v (%v)", status, err)
+	}
+	if err := s.Revoke(ctx, conn); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if status, _ := s.Status(ctx, conn); status.Authorized {
+		t.Fatal("expected revoked connection to be unauthorized")
+	}
+}
+
+func TestStartAuthorizationRequiresOAuthConnection(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t, &fakeTokenStore{records: map[string]Record{}})
+	conn := mcp.Connection{ID: "conn-1", Type: "http", Config: map[string]any{"url": "https://example.com/mcp"}}
+	if _, err := s.StartAuthorization(context.Background(), conn, "https://memoh.example/cb", ""); !errors.Is(err, ErrNotOAuth) {
+		t.Fatalf("expected ErrNotOAuth, got %v", err)
+	}
+}
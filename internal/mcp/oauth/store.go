@@ -0,0 +1,246 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// Record is a stored token set. Secret fields hold sealed values; Resource
+// is the server URL the tokens were issued for.
+type Record struct {
+	ConnectionID  string
+	BotID         string
+	Resource      string
+	Issuer        string
+	TokenEndpoint string
+	ClientID      string
+	ClientSecret  string
+	AccessToken   string
+	RefreshToken  string
+	TokenType     string
+	Scope         string
+	ExpiresAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TokenStore persists token sets per connection.
+type TokenStore interface {
+	Get(ctx context.Context, connectionID string) (Record, bool, error)
+	Save(ctx context.Context, record Record) error
+	Delete(ctx context.Context, connectionID string) error
+}
+
+// DBTokenStore stores token sets in the mcp_oauth_tokens table.
+type DBTokenStore struct {
+	queries *sqlc.Queries
+}
+
+// NewDBTokenStore creates a TokenStore backed by the database.
+func NewDBTokenStore(queries *sqlc.Queries) *DBTokenStore {
+	return &DBTokenStore{queries: queries}
+}
+
+func (s *DBTokenStore) Get(ctx context.Context, connectionID string) (Record, bool, error) {
+	pgID, err := db.ParseUUID(connectionID)
+	if err != nil {
+		return Record{}, false, err
+	}
+	row, err := s.queries.GetMCPOAuthToken(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, false, nil
+		}
+		return Record{}, false, err
+	}
+	return Record{
+		ConnectionID:  connectionID,
+		BotID:         row.BotID.String(),
+		Resource:      row.Resource,
+		Issuer:        row.Issuer,
+		TokenEndpoint: row.TokenEndpoint,
+		ClientID:      row.ClientID,
+		ClientSecret:  row.ClientSecret,
+		AccessToken:   row.AccessToken,
+		RefreshToken:  row.RefreshToken,
+		TokenType:     row.TokenType,
+		Scope:         row.Scope,
+		ExpiresAt:     db.TimeFromPg(row.ExpiresAt),
+		UpdatedAt:     db.TimeFromPg(row.UpdatedAt),
+	}, true, nil
+}
+
+func (s *DBTokenStore) Save(ctx context.Context, record Record) error {
+	connID, err := db.ParseUUID(record.ConnectionID)
+	if err != nil {
+		return err
+	}
+	botID, err := db.ParseUUID(record.BotID)
+	if err != nil {
+		return err
+	}
+	expiresAt := pgtype.Timestamptz{}
+	if !record.ExpiresAt.IsZero() {
+		expiresAt = pgtype.Timestamptz{Time: record.ExpiresAt, Valid: true}
+	}
+	_, err = s.queries.UpsertMCPOAuthToken(ctx, sqlc.UpsertMCPOAuthTokenParams{
+		ConnectionID:  connID,
+		BotID:         botID,
+		Resource:      record.Resource,
+		Issuer:        record.Issuer,
+		TokenEndpoint: record.TokenEndpoint,
+		ClientID:      record.ClientID,
+		ClientSecret:  record.ClientSecret,
+		AccessToken:   record.AccessToken,
+		RefreshToken:  record.RefreshToken,
+		TokenType:     record.TokenType,
+		Scope:         record.Scope,
+		ExpiresAt:     expiresAt,
+	})
+	return err
+}
+
+func (s *DBTokenStore) Delete(ctx context.Context, connectionID string) error {
+	pgID, err := db.ParseUUID(connectionID)
+	if err != nil {
+		return err
+	}
+	return s.queries.DeleteMCPOAuthToken(ctx, pgID)
+}
+
+// Pending is a started authorization waiting for its callback. Verifier and
+// ClientSecret hold sealed values.
+type Pending struct {
+	State        string
+	BotID        string
+	ConnectionID string
+	Resource     string
+	Verifier     string
+	RedirectURI  string
+	ReturnURL    string
+	Server       ServerMetadata
+	ClientID     string
+	ClientSecret string
+	ExpiresAt    time.Time
+}
+
+// PendingStore persists started authorizations by state until the callback
+// arrives, so the callback may reach any server instance.
+type PendingStore interface {
+	Put(ctx context.Context, pending Pending) error
+	// Take removes and returns the authorization of state. It reports false
+	// when the state is unknown or expired.
+	Take(ctx context.Context, state string) (Pending, bool, error)
+}
+
+// memoryPendingStore keeps started authorizations in process. It is used
+// when no database is configured and only works for a single instance.
+type memoryPendingStore struct {
+	mu    sync.Mutex
+	items map[string]Pending
+}
+
+func newMemoryPendingStore() *memoryPendingStore {
+	return &memoryPendingStore{items: map[string]Pending{}}
+}
+
+func (s *memoryPendingStore) Put(_ context.Context, pending Pending) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for state, item := range s.items {
+		if now.After(item.ExpiresAt) {
+			delete(s.items, state)
+		}
+	}
+	s.items[pending.State] = pending
+	return nil
+}
+
+func (s *memoryPendingStore) Take(_ context.Context, state string) (Pending, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.items[state]
+	delete(s.items, state)
+	if !ok || time.Now().After(pending.ExpiresAt) {
+		return Pending{}, false, nil
+	}
+	return pending, true, nil
+}
+
+// DBPendingStore stores started authorizations in the mcp_oauth_pending
+// table.
+type DBPendingStore struct {
+	queries *sqlc.Queries
+}
+
+// NewDBPendingStore creates a PendingStore backed by the database.
+func NewDBPendingStore(queries *sqlc.Queries) *DBPendingStore {
+	return &DBPendingStore{queries: queries}
+}
+
+func (s *DBPendingStore) Put(ctx context.Context, pending Pending) error {
+	if err := s.queries.DeleteExpiredMCPOAuthPending(ctx); err != nil {
+		return err
+	}
+	botID, err := db.ParseUUID(pending.BotID)
+	if err != nil {
+		return err
+	}
+	connID, err := db.ParseUUID(pending.ConnectionID)
+	if err != nil {
+		return err
+	}
+	server, err := json.Marshal(pending.Server)
+	if err != nil {
+		return err
+	}
+	return s.queries.InsertMCPOAuthPending(ctx, sqlc.InsertMCPOAuthPendingParams{
+		State:        pending.State,
+		BotID:        botID,
+		ConnectionID: connID,
+		Resource:     pending.Resource,
+		Verifier:     pending.Verifier,
+		RedirectUri:  pending.RedirectURI,
+		ReturnUrl:    pending.ReturnURL,
+		Server:       server,
+		ClientID:     pending.ClientID,
+		ClientSecret: pending.ClientSecret,
+		ExpiresAt:    pgtype.Timestamptz{Time: pending.ExpiresAt.UTC(), Valid: true},
+	})
+}
+
+func (s *DBPendingStore) Take(ctx context.Context, state string) (Pending, bool, error) {
+	row, err := s.queries.TakeMCPOAuthPending(ctx, state)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Pending{}, false, nil
+		}
+		return Pending{}, false, err
+	}
+	pending := Pending{
+		State:        row.State,
+		BotID:        row.BotID.String(),
+		ConnectionID: row.ConnectionID.String(),
+		Resource:     row.Resource,
+		Verifier:     row.Verifier,
+		RedirectURI:  row.RedirectUri,
+		ReturnURL:    row.ReturnUrl,
+		ClientID:     row.ClientID,
+		ClientSecret: row.ClientSecret,
+		ExpiresAt:    db.TimeFromPg(row.ExpiresAt),
+	}
+	if err := json.Unmarshal(row.Server, &pending.Server); err != nil {
+		return Pending{}, false, fmt.Errorf("decode authorization server: %w", err)
+	}
+	return pending, true, nil
+}
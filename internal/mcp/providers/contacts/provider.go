@@ -4,12 +4,19 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/memohai/memoh/internal/channel/route"
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 )
 
-const toolGetContacts = "get_contacts"
+const (
+	toolGetContacts = "get_contacts"
+
+	// contactsCacheTTL keeps repeated lookups cheap; new contacts show up
+	// once it expires.
+	contactsCacheTTL = time.Minute
+)
 
 // Executor exposes get_contacts as an MCP tool.
 type Executor struct {
@@ -46,6 +53,7 @@ func (p *Executor) ListTools(_ context.Context, _ mcpgw.ToolSessionContext) ([]m
 				},
 				"required": []string{},
 			},
+			Cache: &mcpgw.ToolCachePolicy{TTL: contactsCacheTTL},
 		},
 	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 )
@@ -18,6 +19,13 @@ const (
 	defaultExecWorkDir = "/data"
 	shellCommandName   = "/bin/sh"
 	shellCommandFlag   = "-c"
+
+	// readCacheTTL bounds how long read results are reused. Writes through
+	// write and edit drop the file's result right away; exec drops them all.
+	readCacheTTL = time.Minute
+	// execToolTimeout lets builds and installs outlast the gateway's default
+	// tool timeout.
+	execToolTimeout = 5 * time.Minute
 )
 
 // ExecRunner runs a command in the bot container and returns stdout, stderr and exit code.
@@ -65,6 +73,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"path"},
 			},
+			Cache: &mcpgw.ToolCachePolicy{TTL: readCacheTTL, Resource: pathResource},
 		},
 		{
 			Name:        toolWrite,
@@ -77,6 +86,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"path", "content"},
 			},
+			Invalidates: []mcpgw.ToolInvalidation{{Tool: toolRead, Resource: pathResource}},
 		},
 		{
 			Name:        toolList,
@@ -102,6 +112,7 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"path", "old_text", "new_text"},
 			},
+			Invalidates: []mcpgw.ToolInvalidation{{Tool: toolRead, Resource: pathResource}},
 		},
 		{
 			Name:        toolExec,
@@ -120,6 +131,9 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"command"},
 			},
+			// A command can change any file.
+			Invalidates: []mcpgw.ToolInvalidation{{Tool: toolRead}},
+			Timeout:     execToolTimeout,
 		},
 	}, nil
 }
@@ -141,6 +155,11 @@ func normalizePath(path string) string {
 	return path
 }
 
+// pathResource identifies the file a call reads or changes.
+func pathResource(arguments map[string]any) string {
+	return normalizePath(mcpgw.StringArg(arguments, "path"))
+}
+
 // CallTool dispatches to the appropriate container-exec backed implementation.
 func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
 	botID := strings.TrimSpace(session.BotID)
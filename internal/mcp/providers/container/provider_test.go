@@ -234,3 +234,60 @@ func TestNormalizePath(t *testing.T) {
 		}
 	}
 }
+
+func TestExecutor_ListResources(t *testing.T) {
+	runner := &fakeExecRunner{
+		result: &mcpgw.ExecWithCaptureResult{
+			Stdout:   "./notes.md|regular file|42|644|1700000000\n./subdir|directory|4096|755|1700000000\n./subdir/a.json|regular file|7|644|1700000000\n",
+			ExitCode: 0,
+		},
+	}
+	exec := NewExecutor(nil, runner, "/data")
+	resources, err := exec.ListResources(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("got %d resources, want 2 files", len(resources))
+	}
+	if resources[0].URI != "file:///data/notes.md" || resources[0].Size != 42 {
+		t.Errorf("unexpected resource %+v", resources[0])
+	}
+	if resources[1].URI != "file:///data/subdir/a.json" || resources[1].MimeType != "application/json" {
+		t.Errorf("unexpected resource %+v", resources[1])
+	}
+}
+
+func TestExecutor_ReadResource(t *testing.T) {
+	runner := &fakeExecRunner{
+		result: &mcpgw.ExecWithCaptureResult{Stdout: "hello", ExitCode: 0},
+	}
+	exec := NewExecutor(nil, runner, "/data")
+	session := mcpgw.ToolSessionContext{BotID: "bot1"}
+
+	contents, err := exec.ReadResource(context.Background(), session, "file:///data/notes/today.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents) != 1 || contents[0].Text != "hello" {
+		t.Fatalf("unexpected contents %+v", contents)
+	}
+	if !strings.Contains(runner.lastReq.Command[2], "notes/today.txt") {
+		t.Errorf("unexpected command %v", runner.lastReq.Command)
+	}
+
+	runner.result = &mcpgw.ExecWithCaptureResult{Stdout: "\xff\xfe", ExitCode: 0}
+	contents, err = exec.ReadResource(context.Background(), session, "file:///data/blob.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents[0].Blob != base64.StdEncoding.EncodeToString([]byte("\xff\xfe")) || contents[0].Text != "" {
+		t.Errorf("expected binary content as blob, got %+v", contents[0])
+	}
+
+	for _, uri := range []string{"file:///etc/passwd", "file:///data/../etc/passwd", "file:///data", "memoh://inbox/unread"} {
+		if _, err := exec.ReadResource(context.Background(), session, uri); err != mcpgw.ErrResourceNotFound {
+			t.Errorf("%s: expected ErrResourceNotFound, got %v", uri, err)
+		}
+	}
+}
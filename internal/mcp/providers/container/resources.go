@@ -0,0 +1,110 @@
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+const (
+	fileResourceScheme = "file://"
+	// maxFileResources bounds how many files of the data directory are listed.
+	maxFileResources = 200
+)
+
+// ListResources exposes the files of the bot's data directory as file://
+// resources.
+func (p *Executor) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if p.execRunner == nil || botID == "" {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	entries, err := ExecList(ctx, p.execRunner, botID, p.execWorkDir, ".", true)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	resources := make([]mcpgw.ResourceDescriptor, 0, min(len(entries), maxFileResources))
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		if len(resources) >= maxFileResources {
+			break
+		}
+		resources = append(resources, mcpgw.ResourceDescriptor{
+			URI:      fileResourceScheme + path.Join(p.execWorkDir, entry.Path),
+			Name:     entry.Path,
+			MimeType: fileMimeType(entry.Path),
+			Size:     entry.Size,
+		})
+	}
+	return resources, nil
+}
+
+// ReadResource reads a file:// resource inside the data directory. Content
+// that is not valid UTF-8 is returned as a base64 blob.
+func (p *Executor) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if p.execRunner == nil || botID == "" {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	rel, ok := p.resourcePath(uri)
+	if !ok {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	content, err := ExecRead(ctx, p.execRunner, botID, p.execWorkDir, rel)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := fileMimeType(rel)
+	if !utf8.ValidString(content) {
+		if mimeType == "text/plain" {
+			mimeType = "application/octet-stream"
+		}
+		return []mcpgw.ResourceContent{{
+			URI:      uri,
+			MimeType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString([]byte(content)),
+		}}, nil
+	}
+	return mcpgw.TextResource(uri, mimeType, content), nil
+}
+
+// ResourceTool authorizes reading a file resource like the read tool.
+func (p *Executor) ResourceTool(_ context.Context, _ mcpgw.ToolSessionContext, uri string) (string, map[string]any, bool) {
+	rel, ok := p.resourcePath(uri)
+	if !ok {
+		return "", nil, false
+	}
+	return toolRead, map[string]any{"path": path.Join(p.execWorkDir, rel)}, true
+}
+
+// resourcePath maps a file:// URI to a path relative to the data directory,
+// rejecting anything outside of it.
+func (p *Executor) resourcePath(uri string) (string, bool) {
+	raw, ok := strings.CutPrefix(uri, fileResourceScheme)
+	if !ok {
+		return "", false
+	}
+	root := path.Clean(p.execWorkDir)
+	clean := path.Clean(raw)
+	rel, ok := strings.CutPrefix(clean, root+"/")
+	if !ok || rel == "" {
+		return "", false
+	}
+	return rel, true
+}
+
+func fileMimeType(name string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	return "text/plain"
+}
@@ -0,0 +1,77 @@
+package inbox
+
+import (
+	"context"
+	"strings"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+
+	inboxsvc "github.com/memohai/memoh/internal/inbox"
+)
+
+const (
+	inboxResourcePrefix  = "memoh://inbox/"
+	inboxUnreadResource  = inboxResourcePrefix + "unread"
+	inboxRecentResource  = inboxResourcePrefix + "recent"
+	inboxResourceMaxSize = 50
+)
+
+// ListResources exposes the unread and the most recent inbox items. Single
+// items can be read as memoh://inbox/{id}.
+func (e *Executor) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	if e.service == nil || strings.TrimSpace(session.BotID) == "" {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	return []mcpgw.ResourceDescriptor{
+		{
+			URI:         inboxUnreadResource,
+			Name:        "unread inbox",
+			Description: "Unread inbox items of this bot",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         inboxRecentResource,
+			Name:        "recent inbox",
+			Description: "Most recent inbox items of this bot, read or not",
+			MimeType:    "application/json",
+		},
+	}, nil
+}
+
+func (e *Executor) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if e.service == nil || botID == "" {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	switch uri {
+	case inboxUnreadResource:
+		items, err := e.service.ListUnread(ctx, botID, inboxResourceMaxSize)
+		if err != nil {
+			return nil, err
+		}
+		return mcpgw.JSONResource(uri, items)
+	case inboxRecentResource:
+		items, err := e.service.List(ctx, botID, inboxsvc.ListFilter{Limit: inboxResourceMaxSize})
+		if err != nil {
+			return nil, err
+		}
+		return mcpgw.JSONResource(uri, items)
+	}
+	id, ok := strings.CutPrefix(uri, inboxResourcePrefix)
+	if !ok || strings.TrimSpace(id) == "" {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	item, err := e.service.GetByID(ctx, botID, id)
+	if err != nil {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	return mcpgw.JSONResource(uri, item)
+}
+
+// ResourceTool authorizes reading the inbox like the search_inbox tool.
+func (e *Executor) ResourceTool(_ context.Context, _ mcpgw.ToolSessionContext, uri string) (string, map[string]any, bool) {
+	if !strings.HasPrefix(uri, inboxResourcePrefix) {
+		return "", nil, false
+	}
+	return toolSearchInbox, nil, true
+}
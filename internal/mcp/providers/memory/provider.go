@@ -2,34 +2,70 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/memohai/memoh/internal/conversation"
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 	mem "github.com/memohai/memoh/internal/memory"
+	"github.com/memohai/memoh/internal/message"
 )
 
 const (
-	toolSearchMemory       = "search_memory"
-	defaultMemoryToolLimit = 8
-	maxMemoryToolLimit     = 50
-	sharedMemoryNamespace  = "bot"
+	toolSearchMemory        = "search_memory"
+	toolSearchHistory       = "search_history"
+	toolAddMemory           = "add_memory"
+	defaultMemoryToolLimit  = 8
+	maxMemoryToolLimit      = 50
+	defaultHistoryToolLimit = 5
+	maxHistoryToolLimit     = 20
+	defaultHistoryContext   = 2
+	maxHistoryContext       = 5
+	sharedMemoryNamespace   = "bot"
+	// allHistoryRoutes as route_id searches every conversation of the bot.
+	allHistoryRoutes = "all"
 )
 
 type MemorySearcher interface {
 	Search(ctx context.Context, req mem.SearchRequest) (mem.SearchResponse, error)
 }
 
+// MemoryWriter stores memories.
+type MemoryWriter interface {
+	Add(ctx context.Context, req mem.AddRequest) (mem.SearchResponse, error)
+}
+
+// HistorySearcher searches a bot's indexed message history.
+type HistorySearcher interface {
+	SearchMessages(ctx context.Context, req mem.MessageSearchRequest) ([]mem.MessageHit, error)
+}
+
+// MessageContextReader loads the messages surrounding a history hit.
+type MessageContextReader interface {
+	ListAround(ctx context.Context, botID, routeID string, at time.Time, before, after int32) ([]message.Message, error)
+}
+
 type AdminChecker interface {
 	IsAdmin(ctx context.Context, channelIdentityID string) (bool, error)
 }
 
+// RoleResolver resolves the role a user holds in a bot.
+type RoleResolver interface {
+	MemberRole(ctx context.Context, botID, userID string) (string, error)
+}
+
 type Executor struct {
 	searcher     MemorySearcher
 	chatAccessor conversation.Accessor
 	adminChecker AdminChecker
+	history      HistorySearcher
+	context      MessageContextReader
+	roles        RoleResolver
+	reader       MemoryReader
+	writer       MemoryWriter
 	logger       *slog.Logger
 }
 
@@ -45,12 +81,32 @@ func NewExecutor(log *slog.Logger, searcher MemorySearcher, chatAccessor convers
 	}
 }
 
+// SetHistory enables the search_history tool. reader is optional and adds
+// surrounding messages to each result.
+func (p *Executor) SetHistory(searcher HistorySearcher, reader MessageContextReader) {
+	p.history = searcher
+	p.context = reader
+}
+
+// SetRoleResolver lets bot owners and admins search the history of every
+// conversation. Without it only system admins can.
+func (p *Executor) SetRoleResolver(roles RoleResolver) {
+	p.roles = roles
+}
+
+// SetMemoryWriter enables the add_memory tool for restricted sessions, i.e.
+// external MCP clients. The bot itself stores memories after each turn.
+func (p *Executor) SetMemoryWriter(writer MemoryWriter) {
+	p.writer = writer
+}
+
 func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ToolDescriptor, error) {
-	if p.searcher == nil || p.chatAccessor == nil {
+	if p.chatAccessor == nil {
 		return []mcpgw.ToolDescriptor{}, nil
 	}
-	return []mcpgw.ToolDescriptor{
-		{
+	tools := []mcpgw.ToolDescriptor{}
+	if p.searcher != nil {
+		tools = append(tools, mcpgw.ToolDescriptor{
 			Name:        toolSearchMemory,
 			Description: "Search for memories relevant to the current chat",
 			InputSchema: map[string]any{
@@ -67,14 +123,97 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"query"},
 			},
-		},
-	}, nil
+		})
+	}
+	if p.history != nil {
+		tools = append(tools, mcpgw.ToolDescriptor{
+			Name:        toolSearchHistory,
+			Description: "Search past conversation messages of this bot, e.g. to recall what someone said earlier. Returns matching messages with timestamps and the surrounding conversation",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "What to look for in past messages",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("Maximum number of matching messages (default %d, max %d)", defaultHistoryToolLimit, maxHistoryToolLimit),
+					},
+					"route_id": map[string]any{
+						"type":        "string",
+						"description": fmt.Sprintf("Conversation route to search; defaults to the current conversation. Bot owners and admins may pass another route ID, or %q for every conversation", allHistoryRoutes),
+					},
+					"sender_id": map[string]any{
+						"type":        "string",
+						"description": "Only search messages sent by this channel identity",
+					},
+					"role": map[string]any{
+						"type":        "string",
+						"enum":        []string{"user", "assistant"},
+						"description": "Only search messages with this role",
+					},
+					"since": map[string]any{
+						"type":        "string",
+						"description": "Only search messages sent at or after this time (RFC3339 or YYYY-MM-DD)",
+					},
+					"until": map[string]any{
+						"type":        "string",
+						"description": "Only search messages sent at or before this time (RFC3339 or YYYY-MM-DD)",
+					},
+					"context": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("Messages to include before and after each match (default %d, max %d)", defaultHistoryContext, maxHistoryContext),
+					},
+				},
+				"required": []string{"query"},
+			},
+		})
+	}
+	if p.writer != nil && session.Restricted() {
+		tools = append(tools, mcpgw.ToolDescriptor{
+			Name:        toolAddMemory,
+			Description: "Store a memory for the bot. The content is stored as written unless infer is set, in which case facts are extracted from it and merged with existing memories",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content": map[string]any{
+						"type":        "string",
+						"description": "The memory to store",
+					},
+					"infer": map[string]any{
+						"type":        "boolean",
+						"description": "Extract facts instead of storing the content verbatim",
+					},
+					"importance": map[string]any{
+						"type":        "number",
+						"description": "Importance between 0 and 1",
+					},
+				},
+				"required": []string{"content"},
+			},
+		})
+	}
+	return tools, nil
 }
 
 func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
-	if toolName != toolSearchMemory {
+	switch toolName {
+	case toolSearchMemory:
+		return p.callSearchMemory(ctx, session, arguments)
+	case toolSearchHistory:
+		return p.callSearchHistory(ctx, session, arguments)
+	case toolAddMemory:
+		if !session.Restricted() {
+			return nil, mcpgw.ErrToolNotFound
+		}
+		return p.callAddMemory(ctx, session, arguments)
+	default:
 		return nil, mcpgw.ErrToolNotFound
 	}
+}
+
+func (p *Executor) callSearchMemory(ctx context.Context, session mcpgw.ToolSessionContext, arguments map[string]any) (map[string]any, error) {
 	if p.searcher == nil || p.chatAccessor == nil {
 		return mcpgw.BuildToolErrorResult("memory service not available"), nil
 	}
@@ -83,48 +222,14 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 	if query == "" {
 		return mcpgw.BuildToolErrorResult("query is required"), nil
 	}
-	botID := strings.TrimSpace(session.BotID)
-	chatID := strings.TrimSpace(session.ChatID)
-	channelIdentityID := strings.TrimSpace(session.ChannelIdentityID)
-	if botID == "" {
-		return mcpgw.BuildToolErrorResult("bot_id is required"), nil
-	}
-	if chatID == "" {
-		chatID = botID
+	botID, errMsg := p.authorizeBot(ctx, session)
+	if errMsg != "" {
+		return mcpgw.BuildToolErrorResult(errMsg), nil
 	}
 
-	limit := defaultMemoryToolLimit
-	if value, ok, err := mcpgw.IntArg(arguments, "limit"); err != nil {
+	limit, err := limitArg(arguments, defaultMemoryToolLimit, maxMemoryToolLimit)
+	if err != nil {
 		return mcpgw.BuildToolErrorResult(err.Error()), nil
-	} else if ok {
-		limit = value
-	}
-	if limit <= 0 {
-		limit = defaultMemoryToolLimit
-	}
-	if limit > maxMemoryToolLimit {
-		limit = maxMemoryToolLimit
-	}
-
-	// When ChatID equals BotID (e.g. tools called without conversation context), search by bot scope only.
-	// Otherwise require the conversation to exist and the caller to be a participant.
-	if chatID != botID {
-		chatObj, err := p.chatAccessor.Get(ctx, chatID)
-		if err != nil {
-			return mcpgw.BuildToolErrorResult("chat not found"), nil
-		}
-		if strings.TrimSpace(chatObj.BotID) != botID {
-			return mcpgw.BuildToolErrorResult("bot mismatch"), nil
-		}
-		if channelIdentityID != "" {
-			allowed, err := p.canAccessChat(ctx, chatID, channelIdentityID)
-			if err != nil {
-				return mcpgw.BuildToolErrorResult(err.Error()), nil
-			}
-			if !allowed {
-				return mcpgw.BuildToolErrorResult("not a chat participant"), nil
-			}
-		}
 	}
 
 	resp, err := p.searcher.Search(ctx, mem.SearchRequest{
@@ -169,6 +274,296 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 	}), nil
 }
 
+func (p *Executor) callAddMemory(ctx context.Context, session mcpgw.ToolSessionContext, arguments map[string]any) (map[string]any, error) {
+	if p.writer == nil || p.chatAccessor == nil {
+		return mcpgw.BuildToolErrorResult("memory service not available"), nil
+	}
+
+	content := mcpgw.StringArg(arguments, "content")
+	if content == "" {
+		return mcpgw.BuildToolErrorResult("content is required"), nil
+	}
+	botID, errMsg := p.authorizeBot(ctx, session)
+	if errMsg != "" {
+		return mcpgw.BuildToolErrorResult(errMsg), nil
+	}
+
+	infer, _ := arguments["infer"].(bool)
+	req := mem.AddRequest{
+		Message: content,
+		BotID:   botID,
+		Infer:   &infer,
+		Filters: map[string]any{
+			"namespace": sharedMemoryNamespace,
+			"scopeId":   botID,
+			"bot_id":    botID,
+		},
+		Metadata: map[string]any{
+			"source": "mcp",
+		},
+	}
+	if importance, ok := arguments["importance"].(float64); ok {
+		if importance < 0 || importance > 1 {
+			return mcpgw.BuildToolErrorResult("importance must be between 0 and 1"), nil
+		}
+		req.Importance = &importance
+	}
+	resp, err := p.writer.Add(ctx, req)
+	if err != nil {
+		p.logger.Warn("add memory failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return mcpgw.BuildToolErrorResult("add memory failed"), nil
+	}
+
+	results := make([]map[string]any, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		results = append(results, map[string]any{
+			"id":     item.ID,
+			"memory": item.Memory,
+		})
+	}
+	return mcpgw.BuildToolSuccessResult(map[string]any{
+		"total":   len(results),
+		"results": results,
+	}), nil
+}
+
+func (p *Executor) callSearchHistory(ctx context.Context, session mcpgw.ToolSessionContext, arguments map[string]any) (map[string]any, error) {
+	if p.history == nil || p.chatAccessor == nil {
+		return mcpgw.BuildToolErrorResult("message history search not available"), nil
+	}
+
+	query := mcpgw.StringArg(arguments, "query")
+	if query == "" {
+		return mcpgw.BuildToolErrorResult("query is required"), nil
+	}
+	botID, errMsg := p.authorizeBot(ctx, session)
+	if errMsg != "" {
+		return mcpgw.BuildToolErrorResult(errMsg), nil
+	}
+
+	limit, err := limitArg(arguments, defaultHistoryToolLimit, maxHistoryToolLimit)
+	if err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	}
+	contextSize := defaultHistoryContext
+	if value, ok, err := mcpgw.IntArg(arguments, "context"); err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	} else if ok {
+		contextSize = max(0, min(value, maxHistoryContext))
+	}
+	since, err := timeArg(arguments, "since", false)
+	if err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	}
+	until, err := timeArg(arguments, "until", true)
+	if err != nil {
+		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	}
+	role := strings.ToLower(mcpgw.StringArg(arguments, "role"))
+	if role != "" && role != "user" && role != "assistant" {
+		return mcpgw.BuildToolErrorResult("role must be user or assistant"), nil
+	}
+	routeID, errMsg := p.historyRoute(ctx, session, mcpgw.StringArg(arguments, "route_id"))
+	if errMsg != "" {
+		return mcpgw.BuildToolErrorResult(errMsg), nil
+	}
+
+	hits, err := p.history.SearchMessages(ctx, mem.MessageSearchRequest{
+		BotID:                   botID,
+		Query:                   query,
+		RouteID:                 routeID,
+		SenderChannelIdentityID: mcpgw.StringArg(arguments, "sender_id"),
+		Role:                    role,
+		Since:                   since,
+		Until:                   until,
+		Limit:                   limit,
+	})
+	if err != nil {
+		p.logger.Warn("message history search failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return mcpgw.BuildToolErrorResult("message history search failed"), nil
+	}
+
+	results := make([]map[string]any, 0, len(hits))
+	for _, hit := range hits {
+		result := map[string]any{
+			"id":         hit.MessageID,
+			"text":       hit.Text,
+			"role":       hit.Role,
+			"sender":     hit.SenderName,
+			"route_id":   hit.RouteID,
+			"created_at": hit.CreatedAt.UTC().Format(time.RFC3339),
+			"score":      hit.Score,
+		}
+		if contextSize > 0 && p.context != nil {
+			result["context"] = p.surroundingMessages(ctx, botID, hit, contextSize)
+		}
+		results = append(results, result)
+	}
+
+	return mcpgw.BuildToolSuccessResult(map[string]any{
+		"query":   query,
+		"total":   len(results),
+		"results": results,
+	}), nil
+}
+
+// historyRoute returns the route a history search is limited to, or an
+// error message. Searches default to the current conversation; other
+// conversations are only visible to bot owners and admins. An empty route
+// searches every conversation.
+func (p *Executor) historyRoute(ctx context.Context, session mcpgw.ToolSessionContext, requested string) (string, string) {
+	current := strings.TrimSpace(session.RouteID)
+	requested = strings.TrimSpace(requested)
+	if requested == "" || requested == current {
+		if current != "" {
+			return current, ""
+		}
+		requested = allHistoryRoutes
+	}
+	allowed, err := p.canSearchAllRoutes(ctx, session)
+	if err != nil {
+		p.logger.Warn("resolve history access failed", slog.String("bot_id", session.BotID), slog.Any("error", err))
+		return "", "message history search failed"
+	}
+	if !allowed {
+		if current == "" {
+			return "", "message history search is only available in a conversation"
+		}
+		return "", "only bot owners and admins can search other conversations"
+	}
+	if strings.EqualFold(requested, allHistoryRoutes) {
+		return "", ""
+	}
+	return requested, ""
+}
+
+// canSearchAllRoutes reports whether the caller owns or administers the bot.
+// Restricted sessions never do.
+func (p *Executor) canSearchAllRoutes(ctx context.Context, session mcpgw.ToolSessionContext) (bool, error) {
+	channelIdentityID := strings.TrimSpace(session.ChannelIdentityID)
+	if channelIdentityID == "" || session.Restricted() {
+		return false, nil
+	}
+	if p.adminChecker != nil {
+		isAdmin, err := p.adminChecker.IsAdmin(ctx, channelIdentityID)
+		if err != nil {
+			return false, err
+		}
+		if isAdmin {
+			return true, nil
+		}
+	}
+	if p.roles == nil {
+		return false, nil
+	}
+	role, err := p.roles.MemberRole(ctx, session.BotID, channelIdentityID)
+	if err != nil {
+		return false, err
+	}
+	return role == mcpgw.ToolRoleOwner || role == mcpgw.ToolRoleAdmin, nil
+}
+
+// surroundingMessages returns up to size messages before and after hit in
+// the same route, oldest first. Failures drop the context, not the hit.
+func (p *Executor) surroundingMessages(ctx context.Context, botID string, hit mem.MessageHit, size int) []map[string]any {
+	// The hit itself is the first message at its timestamp, so ask for one more.
+	msgs, err := p.context.ListAround(ctx, botID, hit.RouteID, hit.CreatedAt, int32(size), int32(size+1))
+	if err != nil {
+		p.logger.Warn("load message context failed", slog.String("message_id", hit.MessageID), slog.Any("error", err))
+		return []map[string]any{}
+	}
+	out := make([]map[string]any, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.ID == hit.MessageID {
+			continue
+		}
+		text := mem.MessageText(msg.Content)
+		if text == "" {
+			continue
+		}
+		out = append(out, map[string]any{
+			"id":         msg.ID,
+			"role":       msg.Role,
+			"sender":     msg.SenderDisplayName,
+			"text":       text,
+			"created_at": msg.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return out
+}
+
+// authorizeBot returns the session's bot ID, or an error message when the
+// caller may not read the bot's memory.
+func (p *Executor) authorizeBot(ctx context.Context, session mcpgw.ToolSessionContext) (string, string) {
+	botID := strings.TrimSpace(session.BotID)
+	chatID := strings.TrimSpace(session.ChatID)
+	channelIdentityID := strings.TrimSpace(session.ChannelIdentityID)
+	if botID == "" {
+		return "", "bot_id is required"
+	}
+	if chatID == "" {
+		chatID = botID
+	}
+
+	// When ChatID equals BotID (e.g. tools called without conversation context), search by bot scope only.
+	// Otherwise require the conversation to exist and the caller to be a participant.
+	if chatID != botID {
+		chatObj, err := p.chatAccessor.Get(ctx, chatID)
+		if err != nil {
+			return "", "chat not found"
+		}
+		if strings.TrimSpace(chatObj.BotID) != botID {
+			return "", "bot mismatch"
+		}
+		if channelIdentityID != "" {
+			allowed, err := p.canAccessChat(ctx, chatID, channelIdentityID)
+			if err != nil {
+				return "", err.Error()
+			}
+			if !allowed {
+				return "", "not a chat participant"
+			}
+		}
+	}
+	return botID, ""
+}
+
+func limitArg(arguments map[string]any, fallback, maximum int) (int, error) {
+	limit := fallback
+	if value, ok, err := mcpgw.IntArg(arguments, "limit"); err != nil {
+		return 0, err
+	} else if ok {
+		limit = value
+	}
+	if limit <= 0 {
+		limit = fallback
+	}
+	if limit > maximum {
+		limit = maximum
+	}
+	return limit, nil
+}
+
+// timeArg parses an RFC3339 timestamp or a YYYY-MM-DD date. A date used as
+// an upper bound covers the whole day.
+func timeArg(arguments map[string]any, key string, endOfDay bool) (time.Time, error) {
+	raw := mcpgw.StringArg(arguments, key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 time or a YYYY-MM-DD date", key)
+	}
+	if endOfDay {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return day, nil
+}
+
 func (p *Executor) canAccessChat(ctx context.Context, chatID, channelIdentityID string) (bool, error) {
 	if p.adminChecker != nil {
 		isAdmin, err := p.adminChecker.IsAdmin(ctx, channelIdentityID)
@@ -2,12 +2,15 @@ package memory
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/memohai/memoh/internal/conversation"
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 	"github.com/memohai/memoh/internal/memory"
+	"github.com/memohai/memoh/internal/message"
 )
 
 type fakeSearcher struct {
@@ -22,6 +25,31 @@ func (f *fakeSearcher) Search(ctx context.Context, req memory.SearchRequest) (me
 	return f.resp, nil
 }
 
+type fakeHistorySearcher struct {
+	hits []memory.MessageHit
+	err  error
+	req  memory.MessageSearchRequest
+}
+
+func (f *fakeHistorySearcher) SearchMessages(ctx context.Context, req memory.MessageSearchRequest) ([]memory.MessageHit, error) {
+	f.req = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.hits, nil
+}
+
+type fakeContextReader struct {
+	msgs   []message.Message
+	before int32
+	after  int32
+}
+
+func (f *fakeContextReader) ListAround(ctx context.Context, botID, routeID string, at time.Time, before, after int32) ([]message.Message, error) {
+	f.before, f.after = before, after
+	return f.msgs, nil
+}
+
 type fakeChatAccessor struct {
 	chat           conversation.Conversation
 	getErr         error
@@ -282,3 +310,276 @@ func TestDeduplicateMemoryItems(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutor_ListTools_WithHistory(t *testing.T) {
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, nil)
+	exec.SetHistory(&fakeHistorySearcher{}, nil)
+	tools, err := exec.ListTools(context.Background(), mcpgw.ToolSessionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 2 || tools[1].Name != toolSearchHistory {
+		t.Fatalf("expected search_memory and search_history, got %+v", tools)
+	}
+}
+
+func TestExecutor_SearchHistory(t *testing.T) {
+	at := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	history := &fakeHistorySearcher{hits: []memory.MessageHit{
+		{MessageID: "m2", RouteID: "r1", Role: "user", SenderName: "Ann", Text: "the wifi password is hunter2", CreatedAt: at, Score: 0.03},
+	}}
+	reader := &fakeContextReader{msgs: []message.Message{
+		{ID: "m1", Role: "assistant", Content: json.RawMessage(`{"role":"assistant","content":"what is it?"}`), CreatedAt: at.Add(-time.Minute)},
+		{ID: "m2", Role: "user", Content: json.RawMessage(`{"role":"user","content":"the wifi password is hunter2"}`), CreatedAt: at},
+		{ID: "m3", Role: "assistant", Content: json.RawMessage(`{"role":"assistant","content":"thanks"}`), CreatedAt: at.Add(time.Minute)},
+	}}
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, nil)
+	exec.SetHistory(history, reader)
+
+	result, err := exec.CallTool(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1", RouteID: "r1"}, toolSearchHistory, map[string]any{
+		"query":   "wifi password",
+		"role":    "User",
+		"since":   "2026-02-01",
+		"until":   "2026-02-03",
+		"context": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mcpgw.PayloadError(result); err != nil {
+		t.Fatal(err)
+	}
+	if history.req.BotID != "bot1" || history.req.RouteID != "r1" || history.req.Role != "user" || history.req.Limit != defaultHistoryToolLimit {
+		t.Fatalf("unexpected request: %+v", history.req)
+	}
+	if !history.req.Since.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("since = %v", history.req.Since)
+	}
+	if want := time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond); !history.req.Until.Equal(want) {
+		t.Fatalf("until = %v, want end of day", history.req.Until)
+	}
+	if reader.before != 1 || reader.after != 2 {
+		t.Fatalf("context window = %d/%d", reader.before, reader.after)
+	}
+	content, _ := result["structuredContent"].(map[string]any)
+	results, _ := content["results"].([]map[string]any)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %v", content["results"])
+	}
+	if results[0]["created_at"] != "2026-02-03T10:00:00Z" || results[0]["sender"] != "Ann" {
+		t.Fatalf("unexpected result: %+v", results[0])
+	}
+	surrounding, _ := results[0]["context"].([]map[string]any)
+	if len(surrounding) != 2 || surrounding[0]["id"] != "m1" || surrounding[1]["text"] != "thanks" {
+		t.Fatalf("unexpected context: %+v", surrounding)
+	}
+}
+
+func TestExecutor_SearchHistory_InvalidArgs(t *testing.T) {
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, nil)
+	exec.SetHistory(&fakeHistorySearcher{}, nil)
+	for name, args := range map[string]map[string]any{
+		"no query":   {},
+		"bad since":  {"query": "q", "since": "yesterday"},
+		"bad role":   {"query": "q", "role": "tool"},
+		"bad limit":  {"query": "q", "limit": "many"},
+		"bad window": {"query": "q", "context": "wide"},
+	} {
+		result, err := exec.CallTool(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"}, toolSearchHistory, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isErr, _ := result["isError"].(bool); !isErr {
+			t.Errorf("%s: expected error result", name)
+		}
+	}
+}
+
+type fakeRoleResolver struct {
+	roles map[string]string
+}
+
+func (f *fakeRoleResolver) MemberRole(ctx context.Context, botID, userID string) (string, error) {
+	if role, ok := f.roles[userID]; ok {
+		return role, nil
+	}
+	return "guest", nil
+}
+
+func TestExecutor_SearchHistory_RouteScope(t *testing.T) {
+	history := &fakeHistorySearcher{}
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, &fakeAdminChecker{})
+	exec.SetHistory(history, nil)
+	exec.SetRoleResolver(&fakeRoleResolver{roles: map[string]string{"owner1": "owner", "member1": "member"}})
+
+	cases := []struct {
+		name      string
+		session   mcpgw.ToolSessionContext
+		routeID   string
+		wantErr   bool
+		wantRoute string
+	}{
+		{name: "member defaults to current route", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "member1", RouteID: "r1"}, wantRoute: "r1"},
+		{name: "member cannot read another route", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "member1", RouteID: "r1"}, routeID: "r2", wantErr: true},
+		{name: "member cannot search all routes", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "member1", RouteID: "r1"}, routeID: "all", wantErr: true},
+		{name: "no route without access", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "member1"}, wantErr: true},
+		{name: "owner defaults to current route", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "owner1", RouteID: "r1"}, wantRoute: "r1"},
+		{name: "owner reads another route", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "owner1", RouteID: "r1"}, routeID: "r2", wantRoute: "r2"},
+		{name: "owner searches all routes", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "owner1", RouteID: "r1"}, routeID: "all", wantRoute: ""},
+		{name: "owner outside a conversation", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "owner1"}, wantRoute: ""},
+		{name: "restricted owner cannot search all routes", session: mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "owner1", Scopes: []string{"memory:read"}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		history.req = memory.MessageSearchRequest{RouteID: "unset"}
+		args := map[string]any{"query": "q"}
+		if tc.routeID != "" {
+			args["route_id"] = tc.routeID
+		}
+		result, err := exec.CallTool(context.Background(), tc.session, toolSearchHistory, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isErr, _ := result["isError"].(bool)
+		if isErr != tc.wantErr {
+			t.Fatalf("%s: isError = %v, result %+v", tc.name, isErr, result)
+		}
+		if !tc.wantErr && history.req.RouteID != tc.wantRoute {
+			t.Fatalf("%s: route = %q, want %q", tc.name, history.req.RouteID, tc.wantRoute)
+		}
+	}
+}
+
+func TestExecutor_SearchHistory_NotParticipant(t *testing.T) {
+	accessor := &fakeChatAccessor{chat: conversation.Conversation{BotID: "bot1", ID: "c1"}}
+	exec := NewExecutor(nil, nil, accessor, &fakeAdminChecker{})
+	exec.SetHistory(&fakeHistorySearcher{}, nil)
+	session := mcpgw.ToolSessionContext{BotID: "bot1", ChatID: "c1", ChannelIdentityID: "user1"}
+	result, err := exec.CallTool(context.Background(), session, toolSearchHistory, map[string]any{"query": "q"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error when not participant")
+	}
+}
+
+type fakeMemoryWriter struct {
+	req memory.AddRequest
+}
+
+func (f *fakeMemoryWriter) Add(ctx context.Context, req memory.AddRequest) (memory.SearchResponse, error) {
+	f.req = req
+	return memory.SearchResponse{Results: []memory.MemoryItem{{ID: "m1", Memory: req.Message}}}, nil
+}
+
+func TestExecutor_AddMemory(t *testing.T) {
+	writer := &fakeMemoryWriter{}
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, nil)
+	exec.SetMemoryWriter(writer)
+
+	tools, err := exec.ListTools(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("expected add_memory to be hidden from unrestricted sessions, got %d tools", len(tools))
+	}
+	if _, err := exec.CallTool(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"}, toolAddMemory, map[string]any{"content": "x"}); err != mcpgw.ErrToolNotFound {
+		t.Fatalf("expected ErrToolNotFound, got %v", err)
+	}
+
+	session := mcpgw.ToolSessionContext{BotID: "bot1", ChannelIdentityID: "user1", Scopes: []string{"memory:write"}}
+	tools, err = exec.ListTools(context.Background(), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 2 || tools[1].Name != toolAddMemory {
+		t.Fatalf("expected add_memory for restricted sessions, got %+v", tools)
+	}
+	result, err := exec.CallTool(context.Background(), session, toolAddMemory, map[string]any{"content": "likes tea"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); isErr {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	if writer.req.Infer == nil || *writer.req.Infer {
+		t.Error("expected infer to default to false")
+	}
+	if writer.req.Filters["scopeId"] != "bot1" || writer.req.Metadata["source"] != "mcp" {
+		t.Errorf("unexpected add request: %+v", writer.req)
+	}
+
+	result, err = exec.CallTool(context.Background(), session, toolAddMemory, map[string]any{"content": "x", "importance": 2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Error("expected error for out-of-range importance")
+	}
+}
+
+type fakeMemoryReader struct {
+	items   map[string]memory.MemoryItem
+	lastReq memory.GetAllRequest
+}
+
+func (f *fakeMemoryReader) GetAll(ctx context.Context, req memory.GetAllRequest) (memory.SearchResponse, error) {
+	f.lastReq = req
+	resp := memory.SearchResponse{}
+	for _, item := range f.items {
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
+
+func (f *fakeMemoryReader) Get(ctx context.Context, memoryID string) (memory.MemoryItem, error) {
+	item, ok := f.items[memoryID]
+	if !ok {
+		return memory.MemoryItem{}, errors.New("memory not found")
+	}
+	return item, nil
+}
+
+func TestExecutor_MemoryResources(t *testing.T) {
+	reader := &fakeMemoryReader{items: map[string]memory.MemoryItem{
+		"m1": {ID: "m1", Memory: "likes   green tea", BotID: "bot1", UpdatedAt: "2026-01-01T00:00:00Z"},
+		"m2": {ID: "m2", Memory: "other bot", BotID: "bot2"},
+	}}
+	exec := NewExecutor(nil, &fakeSearcher{}, &fakeChatAccessor{}, nil)
+	ctx := context.Background()
+	session := mcpgw.ToolSessionContext{BotID: "bot1", ChatID: "bot1"}
+
+	if resources, err := exec.ListResources(ctx, session); err != nil || len(resources) != 0 {
+		t.Fatalf("expected no resources without a reader, got %v (%v)", resources, err)
+	}
+	exec.SetMemoryReader(reader)
+	resources, err := exec.ListResources(ctx, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.lastReq.BotID != "bot1" || reader.lastReq.Filters["scopeId"] != "bot1" {
+		t.Errorf("unexpected listing request %+v", reader.lastReq)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("got %d resources", len(resources))
+	}
+
+	contents, err := exec.ReadResource(ctx, session, "memoh://memory/m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(contents[0].Text), &payload); err != nil || payload["memory"] != "likes   green tea" {
+		t.Fatalf("unexpected content %q (%v)", contents[0].Text, err)
+	}
+	if _, err := exec.ReadResource(ctx, session, "memoh://memory/m2"); !errors.Is(err, mcpgw.ErrResourceNotFound) {
+		t.Errorf("expected memory of another bot to be hidden, got %v", err)
+	}
+	if _, err := exec.ReadResource(ctx, session, "memoh://schedules"); !errors.Is(err, mcpgw.ErrResourceNotFound) {
+		t.Errorf("expected foreign uri to be rejected, got %v", err)
+	}
+	if got := memoryName("likes   green tea"); got != "likes green tea" {
+		t.Errorf("memoryName = %q", got)
+	}
+}
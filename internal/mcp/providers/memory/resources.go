@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+	mem "github.com/memohai/memoh/internal/memory"
+)
+
+const (
+	memoryResourcePrefix = "memoh://memory/"
+	// maxMemoryResources bounds how many memories are listed as resources.
+	maxMemoryResources = 100
+	memoryNameRunes    = 60
+)
+
+// MemoryReader lists and loads single memories.
+type MemoryReader interface {
+	GetAll(ctx context.Context, req mem.GetAllRequest) (mem.SearchResponse, error)
+	Get(ctx context.Context, memoryID string) (mem.MemoryItem, error)
+}
+
+// SetMemoryReader exposes the bot's memories as memoh://memory/{id} resources.
+func (p *Executor) SetMemoryReader(reader MemoryReader) {
+	p.reader = reader
+}
+
+func (p *Executor) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	if p.reader == nil || p.chatAccessor == nil {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	botID, errMsg := p.authorizeBot(ctx, session)
+	if errMsg != "" {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	resp, err := p.reader.GetAll(ctx, mem.GetAllRequest{
+		BotID: botID,
+		Limit: maxMemoryResources,
+		Filters: map[string]any{
+			"namespace": sharedMemoryNamespace,
+			"scopeId":   botID,
+		},
+		NoStats: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]mcpgw.ResourceDescriptor, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		if strings.TrimSpace(item.ID) == "" {
+			continue
+		}
+		description := "Memory"
+		if item.UpdatedAt != "" {
+			description = fmt.Sprintf("Memory updated %s", item.UpdatedAt)
+		} else if item.CreatedAt != "" {
+			description = fmt.Sprintf("Memory created %s", item.CreatedAt)
+		}
+		resources = append(resources, mcpgw.ResourceDescriptor{
+			URI:         memoryResourcePrefix + item.ID,
+			Name:        memoryName(item.Memory),
+			Description: description,
+			MimeType:    "application/json",
+		})
+	}
+	return resources, nil
+}
+
+func (p *Executor) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	id, ok := strings.CutPrefix(uri, memoryResourcePrefix)
+	if !ok || strings.TrimSpace(id) == "" || p.reader == nil || p.chatAccessor == nil {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	botID, errMsg := p.authorizeBot(ctx, session)
+	if errMsg != "" {
+		return nil, fmt.Errorf("%s", errMsg)
+	}
+	item, err := p.reader.Get(ctx, id)
+	if err != nil || item.BotID != botID {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	return mcpgw.JSONResource(uri, map[string]any{
+		"id":         item.ID,
+		"memory":     item.Memory,
+		"importance": item.Importance,
+		"created_at": item.CreatedAt,
+		"updated_at": item.UpdatedAt,
+		"expires_at": item.ExpiresAt,
+		"metadata":   item.Metadata,
+	})
+}
+
+// ResourceTool authorizes reading a memory like the search_memory tool.
+func (p *Executor) ResourceTool(_ context.Context, _ mcpgw.ToolSessionContext, uri string) (string, map[string]any, bool) {
+	if !strings.HasPrefix(uri, memoryResourcePrefix) {
+		return "", nil, false
+	}
+	return toolSearchMemory, nil, true
+}
+
+func memoryName(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= memoryNameRunes {
+		return text
+	}
+	return string(runes[:memoryNameRunes]) + "…"
+}
@@ -0,0 +1,214 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+const (
+	toolListResources = "list_resources"
+	toolReadResource  = "read_resource"
+	toolListPrompts   = "list_prompts"
+	toolGetPrompt     = "get_prompt"
+
+	// maxResourceTextRunes bounds the text returned by read_resource.
+	maxResourceTextRunes = 50000
+)
+
+// Catalog federates MCP resources and prompts; the tool gateway implements it.
+type Catalog interface {
+	ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error)
+	ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error)
+	ListPrompts(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.PromptDescriptor, error)
+	GetPrompt(ctx context.Context, session mcpgw.ToolSessionContext, name string, arguments map[string]string) (mcpgw.PromptResult, error)
+}
+
+// Executor exposes MCP resources and prompts to the agent as tools, since
+// the agent only consumes tools.
+type Executor struct {
+	catalog Catalog
+	logger  *slog.Logger
+}
+
+func NewExecutor(log *slog.Logger) *Executor {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Executor{
+		logger: log.With(slog.String("provider", "resources_tool")),
+	}
+}
+
+// SetCatalog wires the catalog after the tool gateway is built, as the
+// gateway itself owns this executor.
+func (p *Executor) SetCatalog(catalog Catalog) {
+	p.catalog = catalog
+}
+
+func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ToolDescriptor, error) {
+	if p.catalog == nil {
+		return []mcpgw.ToolDescriptor{}, nil
+	}
+	return []mcpgw.ToolDescriptor{
+		{
+			Name:        toolListResources,
+			Description: "List resources available to this bot: memories, schedules, inbox, files in /data and resources of connected MCP servers",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"prefix": map[string]any{
+						"type":        "string",
+						"description": "Only list resources whose URI starts with this prefix (e.g. memoh://schedules)",
+					},
+				},
+			},
+		},
+		{
+			Name:        toolReadResource,
+			Description: "Read a resource by URI as returned by list_resources",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"uri": map[string]any{
+						"type":        "string",
+						"description": "Resource URI",
+					},
+				},
+				"required": []string{"uri"},
+			},
+		},
+		{
+			Name:        toolListPrompts,
+			Description: "List prompt templates offered by connected MCP servers",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+		},
+		{
+			Name:        toolGetPrompt,
+			Description: "Render a prompt template from a connected MCP server",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{
+						"type":        "string",
+						"description": "Prompt name as returned by list_prompts",
+					},
+					"arguments": map[string]any{
+						"type":                 "object",
+						"description":          "Prompt arguments",
+						"additionalProperties": map[string]any{"type": "string"},
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+	}, nil
+}
+
+func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
+	switch toolName {
+	case toolListResources, toolReadResource, toolListPrompts, toolGetPrompt:
+	default:
+		return nil, mcpgw.ErrToolNotFound
+	}
+	if p.catalog == nil {
+		return mcpgw.BuildToolErrorResult("resources not available"), nil
+	}
+	if strings.TrimSpace(session.BotID) == "" {
+		return mcpgw.BuildToolErrorResult("bot_id is required"), nil
+	}
+
+	switch toolName {
+	case toolListResources:
+		items, err := p.catalog.ListResources(ctx, session)
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		prefix := mcpgw.StringArg(arguments, "prefix")
+		results := make([]mcpgw.ResourceDescriptor, 0, len(items))
+		for _, item := range items {
+			if prefix == "" || strings.HasPrefix(item.URI, prefix) {
+				results = append(results, item)
+			}
+		}
+		return mcpgw.BuildToolSuccessResult(map[string]any{
+			"total":     len(results),
+			"resources": results,
+		}), nil
+	case toolReadResource:
+		uri := mcpgw.StringArg(arguments, "uri")
+		if uri == "" {
+			return mcpgw.BuildToolErrorResult("uri is required"), nil
+		}
+		contents, err := p.catalog.ReadResource(ctx, session, uri)
+		if err != nil {
+			if errors.Is(err, mcpgw.ErrResourceNotFound) {
+				return mcpgw.BuildToolErrorResult("resource not found: " + uri), nil
+			}
+			p.logger.Warn("read resource failed", slog.String("uri", uri), slog.Any("error", err))
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		return mcpgw.BuildToolSuccessResult(map[string]any{
+			"uri":      uri,
+			"contents": summarizeContents(contents),
+		}), nil
+	case toolListPrompts:
+		prompts, err := p.catalog.ListPrompts(ctx, session)
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		return mcpgw.BuildToolSuccessResult(map[string]any{
+			"total":   len(prompts),
+			"prompts": prompts,
+		}), nil
+	default:
+		name := mcpgw.StringArg(arguments, "name")
+		if name == "" {
+			return mcpgw.BuildToolErrorResult("name is required"), nil
+		}
+		promptArgs := map[string]string{}
+		if raw, ok := arguments["arguments"].(map[string]any); ok {
+			for key := range raw {
+				promptArgs[key] = mcpgw.StringArg(raw, key)
+			}
+		}
+		prompt, err := p.catalog.GetPrompt(ctx, session, name, promptArgs)
+		if err != nil {
+			return mcpgw.BuildToolErrorResult(err.Error()), nil
+		}
+		return mcpgw.BuildToolSuccessResult(prompt), nil
+	}
+}
+
+// summarizeContents keeps text contents (truncated) and replaces binary
+// blobs with a note, as the model cannot use base64 payloads.
+func summarizeContents(contents []mcpgw.ResourceContent) []map[string]any {
+	out := make([]map[string]any, 0, len(contents))
+	for _, content := range contents {
+		item := map[string]any{"uri": content.URI}
+		if content.MimeType != "" {
+			item["mime_type"] = content.MimeType
+		}
+		switch {
+		case content.Text != "":
+			text := []rune(content.Text)
+			if len(text) > maxResourceTextRunes {
+				item["text"] = string(text[:maxResourceTextRunes])
+				item["truncated"] = true
+			} else {
+				item["text"] = content.Text
+			}
+		case content.Blob != "":
+			item["binary"] = true
+			item["size"] = len(content.Blob) * 3 / 4
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package resources
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+type fakeCatalog struct {
+	resources  []mcpgw.ResourceDescriptor
+	contents   map[string][]mcpgw.ResourceContent
+	promptArgs map[string]string
+}
+
+func (f *fakeCatalog) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	return f.resources, nil
+}
+
+func (f *fakeCatalog) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	contents, ok := f.contents[uri]
+	if !ok {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	return contents, nil
+}
+
+func (f *fakeCatalog) ListPrompts(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.PromptDescriptor, error) {
+	return []mcpgw.PromptDescriptor{{Name: "docs_summarize"}}, nil
+}
+
+func (f *fakeCatalog) GetPrompt(ctx context.Context, session mcpgw.ToolSessionContext, name string, arguments map[string]string) (mcpgw.PromptResult, error) {
+	f.promptArgs = arguments
+	return mcpgw.PromptResult{Description: name}, nil
+}
+
+func TestExecutor_ListTools(t *testing.T) {
+	exec := NewExecutor(nil)
+	tools, _ := exec.ListTools(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"})
+	if len(tools) != 0 {
+		t.Fatalf("expected no tools without catalog, got %d", len(tools))
+	}
+	exec.SetCatalog(&fakeCatalog{})
+	tools, _ = exec.ListTools(context.Background(), mcpgw.ToolSessionContext{BotID: "bot1"})
+	if len(tools) != 4 {
+		t.Fatalf("expected 4 tools, got %d", len(tools))
+	}
+}
+
+func TestExecutor_CallTool(t *testing.T) {
+	catalog := &fakeCatalog{
+		resources: []mcpgw.ResourceDescriptor{
+			{URI: "memoh://schedules"},
+			{URI: "file:///data/a.txt"},
+		},
+		contents: map[string][]mcpgw.ResourceContent{
+			"file:///data/a.txt": {{URI: "file:///data/a.txt", Text: strings.Repeat("x", maxResourceTextRunes+10)}},
+			"file:///data/b.png": {{URI: "file:///data/b.png", MimeType: "image/png", Blob: "AAAA"}},
+		},
+	}
+	exec := NewExecutor(nil)
+	exec.SetCatalog(catalog)
+	ctx := context.Background()
+	session := mcpgw.ToolSessionContext{BotID: "bot1"}
+
+	result, err := exec.CallTool(ctx, session, toolListResources, map[string]any{"prefix": "file://"})
+	if err != nil || mcpgw.PayloadError(result) != nil {
+		t.Fatalf("list_resources failed: %v", result)
+	}
+	content := result["structuredContent"].(map[string]any)
+	if content["total"] != 1 {
+		t.Fatalf("expected prefix filter, got %v", content["total"])
+	}
+
+	result, _ = exec.CallTool(ctx, session, toolReadResource, map[string]any{"uri": "file:///data/a.txt"})
+	items := result["structuredContent"].(map[string]any)["contents"].([]map[string]any)
+	if items[0]["truncated"] != true || len([]rune(items[0]["text"].(string))) != maxResourceTextRunes {
+		t.Fatalf("expected truncated text, got %v", items[0]["truncated"])
+	}
+	result, _ = exec.CallTool(ctx, session, toolReadResource, map[string]any{"uri": "file:///data/b.png"})
+	items = result["structuredContent"].(map[string]any)["contents"].([]map[string]any)
+	if items[0]["binary"] != true || items[0]["size"] != 3 {
+		t.Fatalf("expected blob summary, got %v", items[0])
+	}
+	result, _ = exec.CallTool(ctx, session, toolReadResource, map[string]any{"uri": "file:///missing"})
+	if result["isError"] != true {
+		t.Fatal("expected error for missing resource")
+	}
+
+	result, _ = exec.CallTool(ctx, session, toolGetPrompt, map[string]any{"name": "docs_summarize", "arguments": map[string]any{"topic": "go", "depth": 2}})
+	if mcpgw.PayloadError(result) != nil || catalog.promptArgs["topic"] != "go" || catalog.promptArgs["depth"] != "2" {
+		t.Fatalf("unexpected prompt arguments %v", catalog.promptArgs)
+	}
+
+	if _, err := exec.CallTool(ctx, session, "other", nil); err != mcpgw.ErrToolNotFound {
+		t.Fatalf("expected ErrToolNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"context"
+	"strings"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+const scheduleResourcePrefix = "memoh://schedules"
+
+// ListResources exposes the bot's schedules: the whole list and one resource
+// per schedule.
+func (p *Executor) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if p.service == nil || botID == "" {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	items, err := p.service.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]mcpgw.ResourceDescriptor, 0, len(items)+1)
+	resources = append(resources, mcpgw.ResourceDescriptor{
+		URI:         scheduleResourcePrefix,
+		Name:        "schedules",
+		Description: "All schedules of this bot",
+		MimeType:    "application/json",
+	})
+	for _, item := range items {
+		resources = append(resources, mcpgw.ResourceDescriptor{
+			URI:         scheduleResourcePrefix + "/" + item.ID,
+			Name:        item.Name,
+			Description: strings.TrimSpace(item.Description + " (" + item.Pattern + ")"),
+			MimeType:    "application/json",
+		})
+	}
+	return resources, nil
+}
+
+func (p *Executor) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if p.service == nil || botID == "" {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	if uri == scheduleResourcePrefix {
+		items, err := p.service.List(ctx, botID)
+		if err != nil {
+			return nil, err
+		}
+		return mcpgw.JSONResource(uri, items)
+	}
+	id, ok := strings.CutPrefix(uri, scheduleResourcePrefix+"/")
+	if !ok || strings.TrimSpace(id) == "" {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	item, err := p.service.Get(ctx, id)
+	if err != nil || item.BotID != botID {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	return mcpgw.JSONResource(uri, item)
+}
+
+// ResourceTool authorizes reading schedules like the list_schedule and
+// get_schedule tools.
+func (p *Executor) ResourceTool(_ context.Context, _ mcpgw.ToolSessionContext, uri string) (string, map[string]any, bool) {
+	if uri == scheduleResourcePrefix {
+		return toolScheduleList, nil, true
+	}
+	id, ok := strings.CutPrefix(uri, scheduleResourcePrefix+"/")
+	if !ok || strings.TrimSpace(id) == "" {
+		return "", nil, false
+	}
+	return toolScheduleGet, map[string]any{"id": id}, true
+}
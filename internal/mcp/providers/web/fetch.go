@@ -0,0 +1,345 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+
+	textprune "github.com/memohai/memoh/internal/prune"
+)
+
+const (
+	webUserAgent = "MemohBot/1.0 (+https://github.com/memohai/memoh)"
+	// robotsAgentToken is matched against robots.txt user-agent lines.
+	robotsAgentToken = "memohbot"
+
+	webFetchTimeout      = 20 * time.Second
+	webFetchMaxBytes     = 2 << 20
+	webFetchMaxRedirects = 5
+	robotsMaxBytes       = 512 << 10
+	robotsCacheTTL       = time.Hour
+
+	fetchOutputMaxBytes  = 32 * 1024
+	fetchOutputMaxLines  = 800
+	fetchOutputHeadBytes = 24 * 1024
+	fetchOutputTailBytes = 4 * 1024
+	fetchOutputHeadLines = 600
+	fetchOutputTailLines = 100
+)
+
+var errPrivateAddress = errors.New("fetching private network addresses is not allowed")
+
+// fetcher downloads pages for web_fetch, honouring robots.txt.
+type fetcher struct {
+	client       *http.Client
+	robotsClient *http.Client
+	// allowPrivate permits loopback and private addresses; tests only.
+	allowPrivate bool
+
+	mu     sync.Mutex
+	robots map[string]robotsEntry
+}
+
+type robotsEntry struct {
+	rules   robotsRules
+	fetched time.Time
+}
+
+func newFetcher(allowPrivate bool) *fetcher {
+	f := &fetcher{allowPrivate: allowPrivate, robots: map[string]robotsEntry{}}
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: f.checkAddress}
+	// No proxy: the address check must see the real destination.
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	f.robotsClient = &http.Client{Timeout: webFetchTimeout, Transport: transport}
+	f.client = &http.Client{
+		Timeout:   webFetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= webFetchMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", webFetchMaxRedirects)
+			}
+			if err := validateFetchURL(req.URL); err != nil {
+				return err
+			}
+			if !f.allowed(req.Context(), req.URL) {
+				return fmt.Errorf("redirect target %s is disallowed by robots.txt", req.URL)
+			}
+			return nil
+		},
+	}
+	return f
+}
+
+// checkAddress rejects connections to non-public addresses so the tool cannot
+// reach the host or its internal network.
+func (f *fetcher) checkAddress(_, address string, _ syscall.RawConn) error {
+	if f.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+type fetchedPage struct {
+	URL         string
+	Title       string
+	ContentType string
+	Content     string
+	Truncated   bool
+}
+
+func (f *fetcher) fetch(ctx context.Context, rawURL string) (fetchedPage, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return fetchedPage{}, fmt.Errorf("invalid url")
+	}
+	if err := validateFetchURL(target); err != nil {
+		return fetchedPage{}, err
+	}
+	if !f.allowed(ctx, target) {
+		return fetchedPage{}, fmt.Errorf("fetching %s is disallowed by robots.txt", target)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return fetchedPage{}, err
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fetchedPage{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fetchedPage{}, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !isTextMediaType(mediaType) {
+		return fetchedPage{}, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webFetchMaxBytes+1))
+	if err != nil {
+		return fetchedPage{}, err
+	}
+	page := fetchedPage{URL: resp.Request.URL.String(), ContentType: mediaType}
+	if len(body) > webFetchMaxBytes {
+		body = body[:webFetchMaxBytes]
+		page.Truncated = true
+	}
+
+	text := string(body)
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" || (mediaType == "" && looksLikeHTML(body)) {
+		doc, err := html.Parse(bytes.NewReader(body))
+		if err != nil {
+			return fetchedPage{}, fmt.Errorf("parse html: %w", err)
+		}
+		page.Title, text = htmlToMarkdown(doc, resp.Request.URL)
+	}
+	if textprune.Exceeds(text, fetchOutputMaxBytes, fetchOutputMaxLines) {
+		page.Truncated = true
+		text = textprune.PruneWithEdges(text, "page content", textprune.Config{
+			MaxBytes:  fetchOutputMaxBytes,
+			MaxLines:  fetchOutputMaxLines,
+			HeadBytes: fetchOutputHeadBytes,
+			TailBytes: fetchOutputTailBytes,
+			HeadLines: fetchOutputHeadLines,
+			TailLines: fetchOutputTailLines,
+			Marker:    textprune.DefaultMarker,
+		})
+	}
+	page.Content = text
+	return page, nil
+}
+
+func validateFetchURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("only http and https urls can be fetched")
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("url host is required")
+	}
+	if u.User != nil {
+		return fmt.Errorf("urls with credentials are not allowed")
+	}
+	return nil
+}
+
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case mediaType == "", strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/xhtml+xml", mediaType == "application/json", mediaType == "application/xml",
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+func looksLikeHTML(body []byte) bool {
+	head := bytes.ToLower(bytes.TrimSpace(body[:min(len(body), 512)]))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+// allowed reports whether robots.txt of the target's origin permits fetching
+// its path. Missing or unreachable robots.txt allows everything.
+func (f *fetcher) allowed(ctx context.Context, target *url.URL) bool {
+	origin := target.Scheme + "://" + target.Host
+	f.mu.Lock()
+	entry, ok := f.robots[origin]
+	f.mu.Unlock()
+	if !ok || time.Since(entry.fetched) > robotsCacheTTL {
+		entry = robotsEntry{rules: f.loadRobots(ctx, origin), fetched: time.Now()}
+		f.mu.Lock()
+		for key, cached := range f.robots {
+			if time.Since(cached.fetched) > robotsCacheTTL {
+				delete(f.robots, key)
+			}
+		}
+		f.robots[origin] = entry
+		f.mu.Unlock()
+	}
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return entry.rules.allows(path)
+}
+
+func (f *fetcher) loadRobots(ctx context.Context, origin string) robotsRules {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return robotsRules{}
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	resp, err := f.robotsClient.Do(req)
+	if err != nil {
+		return robotsRules{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return robotsRules{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, robotsMaxBytes), robotsAgentToken)
+}
+
+type robotsRule struct {
+	prefix string
+	allow  bool
+}
+
+type robotsRules struct {
+	rules []robotsRule
+}
+
+// allows applies the longest matching rule; Allow wins ties.
+func (r robotsRules) allows(path string) bool {
+	best, allowed := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.prefix, path) {
+			continue
+		}
+		if len(rule.prefix) > best || (len(rule.prefix) == best && rule.allow) {
+			best, allowed = len(rule.prefix), rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch supports the "*" wildcard and the "$" end anchor.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for _, part := range parts[1:] {
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// parseRobots returns the rules of the group naming agent, falling back to
+// the "*" group.
+func parseRobots(r io.Reader, agent string) robotsRules {
+	var specific, wildcard []robotsRule
+	var groupAgents []string
+	inRules := false
+	hasSpecific := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			ua := strings.ToLower(value)
+			if ua != "" && ua != "*" && strings.Contains(agent, ua) {
+				hasSpecific = true
+			}
+			groupAgents = append(groupAgents, ua)
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{prefix: value, allow: key == "allow"}
+			for _, ua := range groupAgents {
+				switch {
+				case ua == "*":
+					wildcard = append(wildcard, rule)
+				case ua != "" && strings.Contains(agent, ua):
+					specific = append(specific, rule)
+				}
+			}
+		}
+	}
+	if hasSpecific {
+		return robotsRules{rules: specific}
+	}
+	return robotsRules{rules: wildcard}
+}
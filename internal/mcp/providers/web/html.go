@@ -0,0 +1,291 @@
+package web
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements never contribute readable text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Head:     true,
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// htmlToMarkdown extracts the page title and the readable content as
+// markdown. The <article> or <main> element is preferred when present;
+// navigation, scripts and forms are dropped. Relative links resolve against base.
+func htmlToMarkdown(doc *html.Node, base *url.URL) (string, string) {
+	title := ""
+	if node := findElement(doc, atom.Title); node != nil {
+		title = collapseSpace(nodeText(node))
+	}
+	root := findElement(doc, atom.Article)
+	if root == nil {
+		root = findElement(doc, atom.Main)
+	}
+	if root == nil {
+		root = findElement(doc, atom.Body)
+	}
+	if root == nil {
+		root = doc
+	}
+	w := &markdownWriter{base: base}
+	w.children(root)
+	text := blankLines.ReplaceAllString(string(w.b), "\n\n")
+	return title, strings.TrimSpace(text)
+}
+
+type markdownWriter struct {
+	b    []byte
+	base *url.URL
+	list []int // item counters of enclosing lists; -1 for unordered
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if text == "" {
+			if strings.TrimSpace(n.Data) == "" && n.Data != "" {
+				w.space()
+			}
+			return
+		}
+		if n.Data[0] == ' ' || n.Data[0] == '\n' || n.Data[0] == '\t' {
+			w.space()
+		}
+		w.write(text)
+		if last := n.Data[len(n.Data)-1]; last == ' ' || last == '\n' || last == '\t' {
+			w.write(" ")
+		}
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+	if skippedElements[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.block()
+		w.write(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		w.write(collapseSpace(nodeText(n)))
+		w.block()
+	case atom.P, atom.Div, atom.Section, atom.Table, atom.Figure, atom.Dl:
+		w.block()
+		w.children(n)
+		w.block()
+	case atom.Br:
+		w.write("\n")
+	case atom.Hr:
+		w.block()
+		w.write("---")
+		w.block()
+	case atom.Tr, atom.Dt, atom.Dd, atom.Figcaption:
+		w.line()
+		w.children(n)
+		w.line()
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.write(" | ")
+	case atom.Ul, atom.Ol:
+		counter := -1
+		if n.DataAtom == atom.Ol {
+			counter = 0
+		}
+		w.line()
+		w.list = append(w.list, counter)
+		w.children(n)
+		w.list = w.list[:len(w.list)-1]
+		w.line()
+	case atom.Li:
+		w.line()
+		depth := len(w.list)
+		marker := "- "
+		if depth > 0 && w.list[depth-1] >= 0 {
+			w.list[depth-1]++
+			marker = strconv.Itoa(w.list[depth-1]) + ". "
+		}
+		w.write(strings.Repeat("  ", max(depth-1, 0)) + marker)
+		w.children(n)
+		w.line()
+	case atom.Pre:
+		w.block()
+		w.write("```\n" + strings.Trim(nodeText(n), "\n") + "\n```")
+		w.block()
+	case atom.Code:
+		w.write("`" + nodeText(n) + "`")
+	case atom.Strong, atom.B:
+		w.wrap(n, "**")
+	case atom.Em, atom.I:
+		w.wrap(n, "_")
+	case atom.Blockquote:
+		w.block()
+		inner := &markdownWriter{base: w.base}
+		inner.children(n)
+		for _, line := range strings.Split(strings.TrimSpace(string(inner.b)), "\n") {
+			w.write("> " + line + "\n")
+		}
+		w.block()
+	case atom.A:
+		text := collapseSpace(nodeText(n))
+		href := w.resolve(attr(n, "href"))
+		if text == "" {
+			return
+		}
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			w.write(text)
+			return
+		}
+		w.write("[" + text + "](" + href + ")")
+	case atom.Img:
+		if alt := collapseSpace(attr(n, "alt")); alt != "" {
+			w.write("![" + alt + "](" + w.resolve(attr(n, "src")) + ")")
+		}
+	default:
+		w.children(n)
+	}
+}
+
+func (w *markdownWriter) wrap(n *html.Node, marker string) {
+	text := collapseSpace(nodeText(n))
+	if text == "" {
+		return
+	}
+	w.write(marker + text + marker)
+}
+
+func (w *markdownWriter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || w.base == nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return w.base.ResolveReference(ref).String()
+}
+
+func (w *markdownWriter) write(s string) {
+	w.b = append(w.b, s...)
+}
+
+func (w *markdownWriter) endsWith(suffix string) bool {
+	return bytes.HasSuffix(w.b, []byte(suffix))
+}
+
+// space separates inline content without doubling whitespace.
+func (w *markdownWriter) space() {
+	if len(w.b) > 0 && !w.endsWith(" ") && !w.endsWith("\n") {
+		w.write(" ")
+	}
+}
+
+func (w *markdownWriter) line() {
+	for len(w.b) > 0 && w.b[len(w.b)-1] == ' ' {
+		w.b = w.b[:len(w.b)-1]
+	}
+	if len(w.b) > 0 && !w.endsWith("\n") {
+		w.write("\n")
+	}
+}
+
+func (w *markdownWriter) block() {
+	w.line()
+	if len(w.b) > 0 && !w.endsWith("\n\n") {
+		w.write("\n")
+	}
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText concatenates the text below n, skipping non-content elements.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style) {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, value := range strings.Fields(attr(n, "class")) {
+		if value == class {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
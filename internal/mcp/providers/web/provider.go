@@ -4,13 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
+	"github.com/memohai/memoh/internal/db/sqlc"
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 	"github.com/memohai/memoh/internal/searchproviders"
 	"github.com/memohai/memoh/internal/settings"
@@ -18,12 +17,17 @@ import (
 
 const (
 	toolWebSearch = "web_search"
+	toolWebFetch  = "web_fetch"
+
+	webSearchCacheTTL = 10 * time.Minute
+	webFetchCacheTTL  = 5 * time.Minute
 )
 
 type Executor struct {
 	logger          *slog.Logger
 	settings        *settings.Service
 	searchProviders *searchproviders.Service
+	fetcher         *fetcher
 }
 
 func NewExecutor(log *slog.Logger, settingsSvc *settings.Service, searchSvc *searchproviders.Service) *Executor {
@@ -34,14 +38,29 @@ func NewExecutor(log *slog.Logger, settingsSvc *settings.Service, searchSvc *sea
 		logger:          log.With(slog.String("provider", "web_tool")),
 		settings:        settingsSvc,
 		searchProviders: searchSvc,
+		fetcher:         newFetcher(false),
 	}
 }
 
 func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ToolDescriptor, error) {
+	tools := []mcpgw.ToolDescriptor{
+		{
+			Name:        toolWebFetch,
+			Description: "Download a web page and return its readable text as markdown. Respects robots.txt; long pages are truncated.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"url": map[string]any{"type": "string", "description": "http or https URL to fetch"},
+				},
+				"required": []string{"url"},
+			},
+			Cache: &mcpgw.ToolCachePolicy{TTL: webFetchCacheTTL},
+		},
+	}
 	if p.settings == nil || p.searchProviders == nil {
-		return []mcpgw.ToolDescriptor{}, nil
+		return tools, nil
 	}
-	return []mcpgw.ToolDescriptor{
+	return append([]mcpgw.ToolDescriptor{
 		{
 			Name:        toolWebSearch,
 			Description: "Search web results via configured search provider.",
@@ -53,11 +72,20 @@ func (p *Executor) ListTools(ctx context.Context, session mcpgw.ToolSessionConte
 				},
 				"required": []string{"query"},
 			},
+			Cache: &mcpgw.ToolCachePolicy{TTL: webSearchCacheTTL},
 		},
-	}, nil
+	}, tools...), nil
 }
 
 func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
+	switch toolName {
+	case toolWebFetch:
+		return p.callWebFetch(ctx, arguments)
+	case toolWebSearch:
+	default:
+		return nil, mcpgw.ErrToolNotFound
+	}
+
 	if p.settings == nil || p.searchProviders == nil {
 		return mcpgw.BuildToolErrorResult("web tools are not available"), nil
 	}
@@ -69,24 +97,41 @@ func (p *Executor) CallTool(ctx context.Context, session mcpgw.ToolSessionContex
 	if err != nil {
 		return mcpgw.BuildToolErrorResult(err.Error()), nil
 	}
-	searchProviderID := strings.TrimSpace(botSettings.SearchProviderID)
-	if searchProviderID == "" {
+	providers := p.searchChain(ctx, botSettings)
+	if len(providers) == 0 {
 		return mcpgw.BuildToolErrorResult("search provider not configured for this bot"), nil
 	}
-	provider, err := p.searchProviders.GetRawByID(ctx, searchProviderID)
-	if err != nil {
-		return mcpgw.BuildToolErrorResult(err.Error()), nil
-	}
+	return p.callWebSearch(ctx, providers, arguments)
+}
 
-	switch toolName {
-	case toolWebSearch:
-		return p.callWebSearch(ctx, provider.Provider, provider.Config, arguments)
-	default:
-		return nil, mcpgw.ErrToolNotFound
+// searchChain resolves the bot's search provider followed by its fallbacks,
+// skipping providers that no longer exist.
+func (p *Executor) searchChain(ctx context.Context, botSettings settings.Settings) []sqlc.SearchProvider {
+	ids := append([]string{botSettings.SearchProviderID}, botSettings.FallbackSearchProviderIDs...)
+	seen := make(map[string]struct{}, len(ids))
+	providers := make([]sqlc.SearchProvider, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		provider, err := p.searchProviders.GetRawByID(ctx, id)
+		if err != nil {
+			p.logger.Warn("search provider lookup failed", slog.String("search_provider_id", id), slog.Any("error", err))
+			continue
+		}
+		providers = append(providers, provider)
 	}
+	return providers
 }
 
-func (p *Executor) callWebSearch(ctx context.Context, providerName string, configJSON []byte, arguments map[string]any) (map[string]any, error) {
+// callWebSearch tries each provider in order and returns the first successful
+// response.
+func (p *Executor) callWebSearch(ctx context.Context, providers []sqlc.SearchProvider, arguments map[string]any) (map[string]any, error) {
 	query := strings.TrimSpace(mcpgw.StringArg(arguments, "query"))
 	if query == "" {
 		return mcpgw.BuildToolErrorResult("query is required"), nil
@@ -101,67 +146,59 @@ func (p *Executor) callWebSearch(ctx context.Context, providerName string, confi
 		count = 20
 	}
 
-	if strings.TrimSpace(providerName) != string(searchproviders.ProviderBrave) {
-		return mcpgw.BuildToolErrorResult("unsupported search provider"), nil
-	}
-	cfg := parseConfig(configJSON)
-	endpoint := strings.TrimRight(firstNonEmpty(stringValue(cfg["base_url"]), "https://api.search.brave.com/res/v1/web/search"), "/")
-	reqURL, err := url.Parse(endpoint)
-	if err != nil {
-		return mcpgw.BuildToolErrorResult("invalid search provider base_url"), nil
+	var failures []string
+	for _, provider := range providers {
+		backend, ok := searchBackends[strings.TrimSpace(provider.Provider)]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: unsupported search provider", provider.Name))
+			continue
+		}
+		client := &http.Client{Timeout: parseTimeout(provider.Config, 15*time.Second)}
+		results, err := backend(ctx, client, parseConfig(provider.Config), query, count)
+		if err != nil {
+			p.logger.Warn("web search failed", slog.String("search_provider", provider.Name), slog.Any("error", err))
+			failures = append(failures, fmt.Sprintf("%s: %v", provider.Name, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		items := make([]map[string]any, 0, len(results))
+		for _, item := range results {
+			items = append(items, map[string]any{
+				"title":       item.Title,
+				"url":         item.URL,
+				"description": item.Description,
+			})
+		}
+		return mcpgw.BuildToolSuccessResult(map[string]any{
+			"query":    query,
+			"provider": provider.Name,
+			"results":  items,
+		}), nil
 	}
-	params := reqURL.Query()
-	params.Set("q", query)
-	params.Set("count", fmt.Sprintf("%d", count))
-	reqURL.RawQuery = params.Encode()
+	return mcpgw.BuildToolErrorResult("search request failed: " + strings.Join(failures, "; ")), nil
+}
 
-	timeout := parseTimeout(configJSON, 15*time.Second)
-	client := &http.Client{Timeout: timeout}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
-	if err != nil {
-		return mcpgw.BuildToolErrorResult(err.Error()), nil
+func (p *Executor) callWebFetch(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+	rawURL := strings.TrimSpace(mcpgw.StringArg(arguments, "url"))
+	if rawURL == "" {
+		return mcpgw.BuildToolErrorResult("url is required"), nil
 	}
-	req.Header.Set("Accept", "application/json")
-	apiKey := stringValue(cfg["api_key"])
-	if strings.TrimSpace(apiKey) != "" {
-		req.Header.Set("X-Subscription-Token", strings.TrimSpace(apiKey))
-	}
-	resp, err := client.Do(req)
+	page, err := p.fetcher.fetch(ctx, rawURL)
 	if err != nil {
 		return mcpgw.BuildToolErrorResult(err.Error()), nil
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return mcpgw.BuildToolErrorResult(err.Error()), nil
+	result := map[string]any{
+		"url":          page.URL,
+		"content_type": page.ContentType,
+		"content":      page.Content,
+		"truncated":    page.Truncated,
+	}
+	if page.Title != "" {
+		result["title"] = page.Title
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return mcpgw.BuildToolErrorResult("search request failed"), nil
-	}
-	var raw struct {
-		Web struct {
-			Results []struct {
-				Title       string `json:"title"`
-				URL         string `json:"url"`
-				Description string `json:"description"`
-			} `json:"results"`
-		} `json:"web"`
-	}
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return mcpgw.BuildToolErrorResult("invalid search response"), nil
-	}
-	results := make([]map[string]any, 0, len(raw.Web.Results))
-	for _, item := range raw.Web.Results {
-		results = append(results, map[string]any{
-			"title":       item.Title,
-			"url":         item.URL,
-			"description": item.Description,
-		})
-	}
-	return mcpgw.BuildToolSuccessResult(map[string]any{
-		"query":   query,
-		"results": results,
-	}), nil
+	return mcpgw.BuildToolSuccessResult(result), nil
 }
 
 func parseTimeout(configJSON []byte, fallback time.Duration) time.Duration {
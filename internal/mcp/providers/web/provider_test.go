@@ -0,0 +1,274 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+func testExecutor(allowPrivate bool) *Executor {
+	return &Executor{
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		fetcher: newFetcher(allowPrivate),
+	}
+}
+
+func searchProvider(t *testing.T, name, provider string, cfg map[string]any) sqlc.SearchProvider {
+	t.Helper()
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	return sqlc.SearchProvider{Name: name, Provider: provider, Config: raw}
+}
+
+func structured(t *testing.T, result map[string]any) map[string]any {
+	t.Helper()
+	if isErr, _ := result["isError"].(bool); isErr {
+		t.Fatalf("unexpected tool error: %+v", result)
+	}
+	content, ok := result["structuredContent"].(map[string]any)
+	if !ok {
+		t.Fatalf("missing structured content: %+v", result)
+	}
+	return content
+}
+
+func TestCallWebSearchFailsOver(t *testing.T) {
+	t.Parallel()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer broken.Close()
+	searxng := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("q") != "memoh" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"title":"Memoh","url":"https://example.com","content":"bot platform"},{"title":"Other","url":"https://example.org","content":"x"}]}`))
+	}))
+	defer searxng.Close()
+
+	p := testExecutor(true)
+	result, err := p.callWebSearch(context.Background(), []sqlc.SearchProvider{
+		searchProvider(t, "primary", "brave", map[string]any{"base_url": broken.URL, "api_key": "k"}),
+		searchProvider(t, "backup", "searxng", map[string]any{"base_url": searxng.URL}),
+	}, map[string]any{"query": "memoh", "count": 1})
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	content := structured(t, result)
+	if content["provider"] != "backup" {
+		t.Fatalf("expected fallback provider, got %v", content["provider"])
+	}
+	results, _ := content["results"].([]map[string]any)
+	if len(results) != 1 || results[0]["description"] != "bot platform" {
+		t.Fatalf("unexpected results: %+v", content["results"])
+	}
+}
+
+func TestCallWebSearchAllProvidersFail(t *testing.T) {
+	t.Parallel()
+
+	p := testExecutor(true)
+	result, err := p.callWebSearch(context.Background(), []sqlc.SearchProvider{
+		searchProvider(t, "tavily", "tavily", map[string]any{}),
+		searchProvider(t, "legacy", "altavista", map[string]any{}),
+	}, map[string]any{"query": "memoh"})
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatalf("expected tool error, got %+v", result)
+	}
+}
+
+func TestSearchBackends(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tavily":
+			if r.Header.Get("Authorization") != "Bearer tk" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"title":"T","url":"https://t.example","content":"tavily"}]}`))
+		case "/bing":
+			if r.Header.Get("Ocp-Apim-Subscription-Key") != "bk" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"webPages":{"value":[{"name":"B","url":"https://b.example","snippet":"bing"}]}}`))
+		case "/google":
+			if r.URL.Query().Get("cx") != "engine" || r.URL.Query().Get("num") != "10" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[{"title":"G","link":"https://g.example","snippet":"google"}]}`))
+		case "/ddg/":
+			_ = r.ParseForm()
+			if r.PostForm.Get("q") != "memoh" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`<div class="result"><a class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fd.example%2Fpage">D <b>title</b></a>
+				<a class="result__snippet">duck snippet</a></div>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		provider string
+		cfg      map[string]any
+		want     searchResult
+	}{
+		{"tavily", map[string]any{"base_url": server.URL + "/tavily", "api_key": "tk"}, searchResult{"T", "https://t.example", "tavily"}},
+		{"bing", map[string]any{"base_url": server.URL + "/bing", "api_key": "bk"}, searchResult{"B", "https://b.example", "bing"}},
+		{"google", map[string]any{"base_url": server.URL + "/google", "api_key": "gk", "cx": "engine"}, searchResult{"G", "https://g.example", "google"}},
+		{"duckduckgo", map[string]any{"base_url": server.URL + "/ddg/"}, searchResult{"D title", "https://d.example/page", "duck snippet"}},
+	}
+	for _, tc := range cases {
+		backend := searchBackends[tc.provider]
+		results, err := backend(context.Background(), server.Client(), tc.cfg, "memoh", 20)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.provider, err)
+		}
+		if len(results) != 1 || results[0] != tc.want {
+			t.Fatalf("%s: unexpected results %+v", tc.provider, results)
+		}
+	}
+}
+
+func TestCallWebFetch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case "/article":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><title>Hello</title><script>alert(1)</script></head><body>
+				<nav>Home | About</nav>
+				<article><h1>Big news</h1><p>Memoh now <a href="/docs">fetches</a> pages.</p><ul><li>one</li><li>two</li></ul></article>
+				<footer>copyright</footer></body></html>`))
+		case "/private/page", "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/long":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(strings.Repeat("line of text\n", 5000)))
+		}
+	}))
+	defer server.Close()
+
+	p := testExecutor(true)
+	result, err := p.callWebFetch(context.Background(), map[string]any{"url": server.URL + "/article"})
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	content := structured(t, result)
+	if content["title"] != "Hello" {
+		t.Fatalf("unexpected title: %v", content["title"])
+	}
+	text, _ := content["content"].(string)
+	want := "# Big news\n\nMemoh now [fetches](" + server.URL + "/docs) pages.\n\n- one\n- two"
+	if text != want {
+		t.Fatalf("unexpected content:\n%q\nwant:\n%q", text, want)
+	}
+
+	result, _ = p.callWebFetch(context.Background(), map[string]any{"url": server.URL + "/private/page"})
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatalf("expected robots.txt to block fetch, got %+v", result)
+	}
+	result, _ = p.callWebFetch(context.Background(), map[string]any{"url": server.URL + "/image"})
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatalf("expected binary content to be rejected, got %+v", result)
+	}
+
+	result, _ = p.callWebFetch(context.Background(), map[string]any{"url": server.URL + "/long"})
+	content = structured(t, result)
+	if content["truncated"] != true || len(content["content"].(string)) > fetchOutputMaxBytes {
+		t.Fatalf("expected long page to be pruned, got %d bytes", len(content["content"].(string)))
+	}
+}
+
+func TestWebFetchBlocksPrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	p := testExecutor(false)
+	result, _ := p.callWebFetch(context.Background(), map[string]any{"url": server.URL})
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatalf("expected loopback fetch to be blocked, got %+v", result)
+	}
+	result, _ = p.callWebFetch(context.Background(), map[string]any{"url": "file:///etc/passwd"})
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatalf("expected non-http url to be rejected, got %+v", result)
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	t.Parallel()
+
+	robots := `
+User-agent: *
+Disallow: /
+
+User-agent: MemohBot
+User-agent: OtherBot
+Disallow: /admin
+Allow: /admin/public
+Disallow: /*.pdf$
+`
+	rules := parseRobots(strings.NewReader(robots), robotsAgentToken)
+	cases := map[string]bool{
+		"/":                  true,
+		"/admin/settings":    false,
+		"/admin/public/page": true,
+		"/files/report.pdf":  false,
+		"/files/report.pdfx": true,
+	}
+	for path, want := range cases {
+		if got := rules.allows(path); got != want {
+			t.Fatalf("%s: expected %v, got %v", path, want, got)
+		}
+	}
+	if parseRobots(strings.NewReader(robots), "somebot").allows("/page") {
+		t.Fatalf("expected wildcard group to apply to other agents")
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	t.Parallel()
+
+	doc, err := html.Parse(strings.NewReader(`<body><main><h2>Steps</h2><ol><li>First <strong>bold</strong></li><li>Second</li></ol>
+		<pre>go test ./...</pre><blockquote><p>quoted</p></blockquote><div hidden>secret</div></main></body>`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	base, _ := url.Parse("https://example.com/")
+	_, text := htmlToMarkdown(doc, base)
+	want := "## Steps\n\n1. First **bold**\n2. Second\n\n```\ngo test ./...\n```\n\n> quoted"
+	if text != want {
+		t.Fatalf("unexpected markdown:\n%q\nwant:\n%q", text, want)
+	}
+}
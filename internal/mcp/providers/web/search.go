@@ -0,0 +1,340 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/memohai/memoh/internal/searchproviders"
+)
+
+// searchResponseMaxBytes bounds the body read from a search API.
+const searchResponseMaxBytes = 4 << 20
+
+type searchResult struct {
+	Title       string
+	URL         string
+	Description string
+}
+
+// searchBackend queries one kind of search provider.
+type searchBackend func(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error)
+
+var searchBackends = map[string]searchBackend{
+	string(searchproviders.ProviderBrave):      searchBrave,
+	string(searchproviders.ProviderSearXNG):    searchSearXNG,
+	string(searchproviders.ProviderTavily):     searchTavily,
+	string(searchproviders.ProviderBing):       searchBing,
+	string(searchproviders.ProviderGoogle):     searchGoogle,
+	string(searchproviders.ProviderDuckDuckGo): searchDuckDuckGo,
+}
+
+func searchBrave(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	reqURL, err := endpointURL(cfg, "https://api.search.brave.com/res/v1/web/search", url.Values{
+		"q":     {query},
+		"count": {strconv.Itoa(count)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey := stringValue(cfg["api_key"]); apiKey != "" {
+		req.Header.Set("X-Subscription-Token", apiKey)
+	}
+	var raw struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := doSearchJSON(client, req, &raw); err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, 0, len(raw.Web.Results))
+	for _, item := range raw.Web.Results {
+		results = append(results, searchResult{Title: item.Title, URL: item.URL, Description: item.Description})
+	}
+	return results, nil
+}
+
+func searchSearXNG(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	base := strings.TrimRight(stringValue(cfg["base_url"]), "/")
+	if base == "" {
+		return nil, fmt.Errorf("searxng base_url is required")
+	}
+	if !strings.HasSuffix(base, "/search") {
+		base += "/search"
+	}
+	reqURL, err := endpointURL(map[string]any{"base_url": base}, "", url.Values{
+		"q":      {query},
+		"format": {"json"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey := stringValue(cfg["api_key"]); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	var raw struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := doSearchJSON(client, req, &raw); err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, 0, len(raw.Results))
+	for _, item := range raw.Results {
+		results = append(results, searchResult{Title: item.Title, URL: item.URL, Description: item.Content})
+	}
+	return limitResults(results, count), nil
+}
+
+func searchTavily(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	apiKey := stringValue(cfg["api_key"])
+	if apiKey == "" {
+		return nil, fmt.Errorf("tavily api_key is required")
+	}
+	reqURL, err := endpointURL(cfg, "https://api.tavily.com/search", nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]any{
+		"query":        query,
+		"max_results":  count,
+		"search_depth": firstNonEmpty(stringValue(cfg["search_depth"]), "basic"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	var raw struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := doSearchJSON(client, req, &raw); err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, 0, len(raw.Results))
+	for _, item := range raw.Results {
+		results = append(results, searchResult{Title: item.Title, URL: item.URL, Description: item.Content})
+	}
+	return limitResults(results, count), nil
+}
+
+func searchBing(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	apiKey := stringValue(cfg["api_key"])
+	if apiKey == "" {
+		return nil, fmt.Errorf("bing api_key is required")
+	}
+	params := url.Values{
+		"q":     {query},
+		"count": {strconv.Itoa(count)},
+	}
+	if market := stringValue(cfg["market"]); market != "" {
+		params.Set("mkt", market)
+	}
+	reqURL, err := endpointURL(cfg, "https://api.bing.microsoft.com/v7.0/search", params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
+	var raw struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := doSearchJSON(client, req, &raw); err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, 0, len(raw.WebPages.Value))
+	for _, item := range raw.WebPages.Value {
+		results = append(results, searchResult{Title: item.Name, URL: item.URL, Description: item.Snippet})
+	}
+	return limitResults(results, count), nil
+}
+
+// googleMaxResults is the page size limit of the Custom Search JSON API.
+const googleMaxResults = 10
+
+func searchGoogle(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	apiKey := stringValue(cfg["api_key"])
+	cx := stringValue(cfg["cx"])
+	if apiKey == "" || cx == "" {
+		return nil, fmt.Errorf("google api_key and cx are required")
+	}
+	reqURL, err := endpointURL(cfg, "https://www.googleapis.com/customsearch/v1", url.Values{
+		"key": {apiKey},
+		"cx":  {cx},
+		"q":   {query},
+		"num": {strconv.Itoa(min(count, googleMaxResults))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Items []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := doSearchJSON(client, req, &raw); err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, 0, len(raw.Items))
+	for _, item := range raw.Items {
+		results = append(results, searchResult{Title: item.Title, URL: item.Link, Description: item.Snippet})
+	}
+	return limitResults(results, count), nil
+}
+
+// searchDuckDuckGo scrapes the keyless HTML endpoint.
+func searchDuckDuckGo(ctx context.Context, client *http.Client, cfg map[string]any, query string, count int) ([]searchResult, error) {
+	reqURL, err := endpointURL(cfg, "https://html.duckduckgo.com/html/", nil)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{"q": {query}}
+	if region := stringValue(cfg["region"]); region != "" {
+		form.Set("kl", region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", webUserAgent)
+	body, err := doSearch(client, req)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid search response")
+	}
+	var results []searchResult
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasClass(n, "result__a") {
+			results = append(results, searchResult{
+				Title: strings.TrimSpace(nodeText(n)),
+				URL:   duckDuckGoTarget(attr(n, "href")),
+			})
+		}
+		if n.Type == html.ElementNode && hasClass(n, "result__snippet") && len(results) > 0 {
+			results[len(results)-1].Description = strings.TrimSpace(nodeText(n))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return limitResults(results, count), nil
+}
+
+// duckDuckGoTarget unwraps DuckDuckGo redirect links ("//duckduckgo.com/l/?uddg=...").
+func duckDuckGoTarget(href string) string {
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if target := parsed.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if parsed.Scheme == "" && parsed.Host != "" {
+		parsed.Scheme = "https"
+	}
+	return parsed.String()
+}
+
+func endpointURL(cfg map[string]any, fallback string, params url.Values) (string, error) {
+	endpoint := firstNonEmpty(stringValue(cfg["base_url"]), fallback)
+	reqURL, err := url.Parse(endpoint)
+	if err != nil || reqURL.Host == "" {
+		return "", fmt.Errorf("invalid search provider base_url")
+	}
+	query := reqURL.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	reqURL.RawQuery = query.Encode()
+	return reqURL.String(), nil
+}
+
+func doSearch(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, searchResponseMaxBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("search request failed with status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+func doSearchJSON(client *http.Client, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	body, err := doSearch(client, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid search response")
+	}
+	return nil
+}
+
+func limitResults(results []searchResult, count int) []searchResult {
+	if count > 0 && len(results) > count {
+		return results[:count]
+	}
+	return results
+}
@@ -0,0 +1,307 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+type cachedResourceRegistry struct {
+	expiresAt time.Time
+	resources []ResourceDescriptor
+	owners    map[string]ResourceProvider
+}
+
+type cachedPromptRegistry struct {
+	expiresAt time.Time
+	prompts   []PromptDescriptor
+	owners    map[string]PromptProvider
+}
+
+// ListResources federates resources from executors and sources. Executors
+// come first, so local resources win when a URI is listed twice. Resources
+// the session may not read are left out.
+func (s *ToolGatewayService) ListResources(ctx context.Context, session ToolSessionContext) ([]ResourceDescriptor, error) {
+	registry, err := s.getResourceRegistry(ctx, session, false)
+	if err != nil {
+		return nil, err
+	}
+	if s.authorizer == nil {
+		return append([]ResourceDescriptor(nil), registry.resources...), nil
+	}
+	// Most resources map onto a tool without arguments; decide those once.
+	decided := map[string]error{}
+	out := make([]ResourceDescriptor, 0, len(registry.resources))
+	for _, item := range registry.resources {
+		toolName, arguments := s.resourceTool(ctx, session, registry.owners[item.URI], item.URI)
+		authErr, ok := decided[toolName]
+		if !ok || arguments != nil {
+			authErr = s.authorizer.AuthorizeCall(ctx, session, toolName, arguments)
+			if arguments == nil {
+				decided[toolName] = authErr
+			}
+		}
+		if errors.Is(authErr, ErrToolNotAllowed) {
+			continue
+		}
+		if authErr != nil {
+			return nil, authErr
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// ReadResource reads a resource from the provider that listed it. Resources
+// that were not listed (e.g. beyond a provider's listing limit) are offered
+// to the local executors.
+func (s *ToolGatewayService) ReadResource(ctx context.Context, session ToolSessionContext, uri string) ([]ResourceContent, error) {
+	uri = strings.TrimSpace(uri)
+	if uri == "" {
+		return nil, fmt.Errorf("resource uri is required")
+	}
+	registry, err := s.getResourceRegistry(ctx, session, false)
+	if err != nil {
+		return nil, err
+	}
+	owner, ok := registry.owners[uri]
+	if !ok {
+		registry, err = s.getResourceRegistry(ctx, session, true)
+		if err != nil {
+			return nil, err
+		}
+		owner, ok = registry.owners[uri]
+	}
+	if ok {
+		if err := s.authorizeResource(ctx, session, owner, uri); err != nil {
+			return nil, err
+		}
+		return owner.ReadResource(ctx, session, uri)
+	}
+	for _, executor := range s.executors {
+		provider, ok := executor.(ResourceProvider)
+		if !ok {
+			continue
+		}
+		if err := s.authorizeResource(ctx, session, provider, uri); err != nil {
+			return nil, err
+		}
+		contents, err := provider.ReadResource(ctx, session, uri)
+		if errors.Is(err, ErrResourceNotFound) {
+			continue
+		}
+		return contents, err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+}
+
+// ListPrompts federates prompts from executors and sources. Prompts the
+// session may not use are left out.
+func (s *ToolGatewayService) ListPrompts(ctx context.Context, session ToolSessionContext) ([]PromptDescriptor, error) {
+	registry, err := s.getPromptRegistry(ctx, session, false)
+	if err != nil {
+		return nil, err
+	}
+	if s.authorizer == nil {
+		return append([]PromptDescriptor(nil), registry.prompts...), nil
+	}
+	out := make([]PromptDescriptor, 0, len(registry.prompts))
+	for _, item := range registry.prompts {
+		err := s.authorizer.AuthorizeCall(ctx, session, item.Name, nil)
+		if errors.Is(err, ErrToolNotAllowed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// GetPrompt renders a prompt through the provider that listed it.
+func (s *ToolGatewayService) GetPrompt(ctx context.Context, session ToolSessionContext, name string, arguments map[string]string) (PromptResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return PromptResult{}, fmt.Errorf("prompt name is required")
+	}
+	registry, err := s.getPromptRegistry(ctx, session, false)
+	if err != nil {
+		return PromptResult{}, err
+	}
+	owner, ok := registry.owners[name]
+	if !ok {
+		registry, err = s.getPromptRegistry(ctx, session, true)
+		if err != nil {
+			return PromptResult{}, err
+		}
+		if owner, ok = registry.owners[name]; !ok {
+			return PromptResult{}, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+		}
+	}
+	if s.authorizer != nil {
+		if err := s.authorizer.AuthorizeCall(ctx, session, name, nil); err != nil {
+			return PromptResult{}, err
+		}
+	}
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+	return owner.GetPrompt(ctx, session, name, arguments)
+}
+
+// authorizeResource applies the tool policy that covers reading uri from
+// provider.
+func (s *ToolGatewayService) authorizeResource(ctx context.Context, session ToolSessionContext, provider ResourceProvider, uri string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	toolName, arguments := s.resourceTool(ctx, session, provider, uri)
+	return s.authorizer.AuthorizeCall(ctx, session, toolName, arguments)
+}
+
+// resourceTool returns the tool a resource is authorized as. Resources of
+// providers without a mapping are checked under their URI.
+func (s *ToolGatewayService) resourceTool(ctx context.Context, session ToolSessionContext, provider ResourceProvider, uri string) (string, map[string]any) {
+	if mapper, ok := provider.(ResourceToolMapper); ok {
+		if toolName, arguments, ok := mapper.ResourceTool(ctx, session, uri); ok {
+			return toolName, arguments
+		}
+	}
+	return uri, nil
+}
+
+func (s *ToolGatewayService) resourceProviders() []ResourceProvider {
+	providers := make([]ResourceProvider, 0, len(s.executors)+len(s.sources))
+	for _, executor := range s.executors {
+		if provider, ok := executor.(ResourceProvider); ok {
+			providers = append(providers, provider)
+		}
+	}
+	for _, source := range s.sources {
+		if provider, ok := source.(ResourceProvider); ok {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+func (s *ToolGatewayService) promptProviders() []PromptProvider {
+	providers := make([]PromptProvider, 0, len(s.executors)+len(s.sources))
+	for _, executor := range s.executors {
+		if provider, ok := executor.(PromptProvider); ok {
+			providers = append(providers, provider)
+		}
+	}
+	for _, source := range s.sources {
+		if provider, ok := source.(PromptProvider); ok {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+func (s *ToolGatewayService) getResourceRegistry(ctx context.Context, session ToolSessionContext, force bool) (cachedResourceRegistry, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return cachedResourceRegistry{}, fmt.Errorf("bot id is required")
+	}
+	if !force {
+		s.mu.Lock()
+		cached, ok := s.resourceCache[botID]
+		s.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached, nil
+		}
+	}
+
+	registry := cachedResourceRegistry{
+		resources: []ResourceDescriptor{},
+		owners:    map[string]ResourceProvider{},
+	}
+	for _, provider := range s.resourceProviders() {
+		items, err := provider.ListResources(ctx, session)
+		if err != nil {
+			s.logger.Warn("list resources from provider failed", slog.Any("error", err))
+			continue
+		}
+		for _, item := range items {
+			uri := strings.TrimSpace(item.URI)
+			if uri == "" {
+				continue
+			}
+			if _, exists := registry.owners[uri]; exists {
+				s.logger.Warn("skip duplicated resource", slog.String("uri", uri))
+				continue
+			}
+			item.URI = uri
+			if strings.TrimSpace(item.Name) == "" {
+				item.Name = uri
+			}
+			registry.owners[uri] = provider
+			registry.resources = append(registry.resources, item)
+		}
+	}
+	sort.SliceStable(registry.resources, func(i, j int) bool {
+		return registry.resources[i].URI < registry.resources[j].URI
+	})
+	registry.expiresAt = time.Now().Add(s.cacheTTL)
+
+	s.mu.Lock()
+	s.resourceCache[botID] = registry
+	s.mu.Unlock()
+	return registry, nil
+}
+
+func (s *ToolGatewayService) getPromptRegistry(ctx context.Context, session ToolSessionContext, force bool) (cachedPromptRegistry, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return cachedPromptRegistry{}, fmt.Errorf("bot id is required")
+	}
+	if !force {
+		s.mu.Lock()
+		cached, ok := s.promptCache[botID]
+		s.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached, nil
+		}
+	}
+
+	registry := cachedPromptRegistry{
+		prompts: []PromptDescriptor{},
+		owners:  map[string]PromptProvider{},
+	}
+	for _, provider := range s.promptProviders() {
+		items, err := provider.ListPrompts(ctx, session)
+		if err != nil {
+			s.logger.Warn("list prompts from provider failed", slog.Any("error", err))
+			continue
+		}
+		for _, item := range items {
+			name := strings.TrimSpace(item.Name)
+			if name == "" {
+				continue
+			}
+			if _, exists := registry.owners[name]; exists {
+				s.logger.Warn("skip duplicated prompt", slog.String("prompt", name))
+				continue
+			}
+			item.Name = name
+			registry.owners[name] = provider
+			registry.prompts = append(registry.prompts, item)
+		}
+	}
+	sort.SliceStable(registry.prompts, func(i, j int) bool {
+		return registry.prompts[i].Name < registry.prompts[j].Name
+	})
+	registry.expiresAt = time.Now().Add(s.cacheTTL)
+
+	s.mu.Lock()
+	s.promptCache[botID] = registry
+	s.mu.Unlock()
+	return registry, nil
+}
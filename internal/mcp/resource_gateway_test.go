@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type resourceTestProvider struct {
+	gatewayTestProvider
+	resources []ResourceDescriptor
+	prompts   []PromptDescriptor
+	reads     []string
+}
+
+func (p *resourceTestProvider) ListResources(ctx context.Context, session ToolSessionContext) ([]ResourceDescriptor, error) {
+	return p.resources, nil
+}
+
+func (p *resourceTestProvider) ReadResource(ctx context.Context, session ToolSessionContext, uri string) ([]ResourceContent, error) {
+	p.reads = append(p.reads, uri)
+	if !strings.HasPrefix(uri, "memoh://") {
+		return nil, ErrResourceNotFound
+	}
+	return TextResource(uri, "", "content of "+uri), nil
+}
+
+func (p *resourceTestProvider) ListPrompts(ctx context.Context, session ToolSessionContext) ([]PromptDescriptor, error) {
+	return p.prompts, nil
+}
+
+func (p *resourceTestProvider) GetPrompt(ctx context.Context, session ToolSessionContext, name string, arguments map[string]string) (PromptResult, error) {
+	return PromptResult{Messages: []PromptMessage{{Role: "user", Content: map[string]any{"type": "text", "text": name + ":" + arguments["topic"]}}}}, nil
+}
+
+func TestToolGatewayServiceResources(t *testing.T) {
+	local := &resourceTestProvider{resources: []ResourceDescriptor{
+		{URI: "memoh://schedules", Name: "schedules"},
+		{URI: "shared://x", Name: "local x"},
+	}}
+	remote := &resourceTestProvider{resources: []ResourceDescriptor{
+		{URI: "shared://x", Name: "remote x"},
+		{URI: "remote://doc"},
+	}}
+	plain := &gatewayTestProvider{}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{plain, local}, []ToolSource{remote})
+	session := ToolSessionContext{BotID: "bot-1"}
+
+	resources, err := service.ListResources(context.Background(), session)
+	if err != nil {
+		t.Fatalf("list resources failed: %v", err)
+	}
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources after dedupe, got %+v", resources)
+	}
+	for _, resource := range resources {
+		if resource.URI == "shared://x" && resource.Name != "local x" {
+			t.Fatalf("expected local resource to win, got %+v", resource)
+		}
+		if resource.URI == "remote://doc" && resource.Name != "remote://doc" {
+			t.Fatalf("expected name to default to the uri, got %+v", resource)
+		}
+	}
+
+	if _, err := service.ReadResource(context.Background(), session, "remote://doc"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected read to reach the owning source, got %v", err)
+	}
+	if len(remote.reads) != 1 || len(local.reads) != 0 {
+		t.Fatalf("expected read routed to remote, got local=%v remote=%v", local.reads, remote.reads)
+	}
+	// Unlisted URIs fall back to the local executors.
+	contents, err := service.ReadResource(context.Background(), session, "memoh://memory/m1")
+	if err != nil || len(contents) != 1 || contents[0].Text != "content of memoh://memory/m1" {
+		t.Fatalf("unexpected contents %+v (%v)", contents, err)
+	}
+	if _, err := service.ReadResource(context.Background(), session, "unknown://x"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+func TestToolGatewayServicePrompts(t *testing.T) {
+	remote := &resourceTestProvider{prompts: []PromptDescriptor{{Name: "docs_summarize"}}}
+	service := NewToolGatewayService(slog.Default(), nil, []ToolSource{remote})
+	session := ToolSessionContext{BotID: "bot-1"}
+
+	prompts, err := service.ListPrompts(context.Background(), session)
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("unexpected prompts %+v (%v)", prompts, err)
+	}
+	result, err := service.GetPrompt(context.Background(), session, "docs_summarize", map[string]string{"topic": "go"})
+	if err != nil {
+		t.Fatalf("get prompt failed: %v", err)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Content["text"] != "docs_summarize:go" {
+		t.Fatalf("unexpected prompt result %+v", result)
+	}
+	if _, err := service.GetPrompt(context.Background(), session, "missing", nil); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected ErrPromptNotFound, got %v", err)
+	}
+}
+
+type mappedResourceProvider struct {
+	resourceTestProvider
+}
+
+func (p *mappedResourceProvider) ResourceTool(ctx context.Context, session ToolSessionContext, uri string) (string, map[string]any, bool) {
+	if !strings.HasPrefix(uri, "memoh://") {
+		return "", nil, false
+	}
+	return "read", nil, true
+}
+
+func TestToolGatewayServiceAppliesPoliciesToResources(t *testing.T) {
+	local := &mappedResourceProvider{resourceTestProvider{resources: []ResourceDescriptor{
+		{URI: "memoh://schedules", Name: "schedules"},
+	}}}
+	remote := &resourceTestProvider{prompts: []PromptDescriptor{{Name: "docs_summarize"}, {Name: "web_fetch"}}}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{local}, []ToolSource{remote})
+	service.SetToolAuthorizer(newTestToolPolicyService(nil,
+		ToolPolicy{Tool: "read", Enabled: false},
+		ToolPolicy{Tool: "docs_*", Enabled: false},
+	))
+	session := ToolSessionContext{BotID: "bot-1"}
+	ctx := context.Background()
+
+	resources, err := service.ListResources(ctx, session)
+	if err != nil || len(resources) != 0 {
+		t.Fatalf("expected resources of a disabled tool to be hidden, got %+v (%v)", resources, err)
+	}
+	if _, err := service.ReadResource(ctx, session, "memoh://schedules"); !errors.Is(err, ErrToolNotAllowed) {
+		t.Fatalf("expected read to be rejected, got %v", err)
+	}
+	if len(local.reads) != 0 {
+		t.Fatalf("expected provider not to be read, got %v", local.reads)
+	}
+
+	prompts, err := service.ListPrompts(ctx, session)
+	if err != nil || len(prompts) != 1 || prompts[0].Name != "web_fetch" {
+		t.Fatalf("expected disabled prompt to be hidden, got %+v (%v)", prompts, err)
+	}
+	if _, err := service.GetPrompt(ctx, session, "docs_summarize", nil); !errors.Is(err, ErrToolNotAllowed) {
+		t.Fatalf("expected prompt to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ResourceDescriptor is the MCP resources/list item shape used by the gateway.
+type ResourceDescriptor struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// ResourceContent is one item of a resources/read result. Blob holds base64
+// encoded binary content.
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// PromptArgument describes an argument of a prompt template.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptDescriptor is the MCP prompts/list item shape used by the gateway.
+type PromptDescriptor struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptMessage is one message of a rendered prompt. Content keeps the MCP
+// content block as is (text, image, embedded resource, ...).
+type PromptMessage struct {
+	Role    string         `json:"role"`
+	Content map[string]any `json:"content"`
+}
+
+// PromptResult is the MCP prompts/get result.
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// ResourceProvider is implemented by executors and sources that expose MCP
+// resources in addition to tools.
+type ResourceProvider interface {
+	ListResources(ctx context.Context, session ToolSessionContext) ([]ResourceDescriptor, error)
+	ReadResource(ctx context.Context, session ToolSessionContext, uri string) ([]ResourceContent, error)
+}
+
+// ResourceToolMapper is implemented by resource providers whose resources
+// are also reachable through one of their tools. Reading a resource is
+// authorized like calling that tool with the returned arguments, so tool
+// policies cover both. ok is false for URIs the provider does not own.
+type ResourceToolMapper interface {
+	ResourceTool(ctx context.Context, session ToolSessionContext, uri string) (toolName string, arguments map[string]any, ok bool)
+}
+
+// PromptProvider is implemented by executors and sources that expose MCP
+// prompts in addition to tools. Prompts are authorized like a tool of the
+// same name.
+type PromptProvider interface {
+	ListPrompts(ctx context.Context, session ToolSessionContext) ([]PromptDescriptor, error)
+	GetPrompt(ctx context.Context, session ToolSessionContext, name string, arguments map[string]string) (PromptResult, error)
+}
+
+var (
+	// ErrResourceNotFound indicates the provider does not own the requested resource.
+	ErrResourceNotFound = fmt.Errorf("resource not found")
+	// ErrPromptNotFound indicates the provider does not own the requested prompt.
+	ErrPromptNotFound = fmt.Errorf("prompt not found")
+)
+
+// TextResource builds the contents of a text resource.
+func TextResource(uri, mimeType, text string) []ResourceContent {
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	return []ResourceContent{{URI: uri, MimeType: mimeType, Text: text}}
+}
+
+// JSONResource builds the contents of a resource rendered as JSON.
+func JSONResource(uri string, value any) ([]ResourceContent, error) {
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return TextResource(uri, "application/json", string(payload)), nil
+}
@@ -0,0 +1,128 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+const (
+	// breakerThreshold is the number of consecutive failures that opens a
+	// connection's circuit.
+	breakerThreshold = 3
+	// breakerCooldown is how long an open circuit hides the connection
+	// before one probe is let through. Failed probes double it up to
+	// breakerMaxCooldown.
+	breakerCooldown    = 30 * time.Second
+	breakerMaxCooldown = 5 * time.Minute
+)
+
+type circuit struct {
+	state     string
+	failures  int
+	lastError string
+	openedAt  time.Time
+	retryAt   time.Time
+	cooldown  time.Duration
+	probing   bool
+}
+
+// breaker tracks consecutive failures of MCP connections and stops using a
+// connection that keeps failing until its cooldown has passed.
+type breaker struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func newBreaker() *breaker {
+	return &breaker{now: time.Now, circuits: map[string]*circuit{}}
+}
+
+// allow reports whether the connection may be used. Once the cooldown of an
+// open circuit has passed, it lets a single probe through; every allowed
+// use must be followed by record.
+func (b *breaker) allow(connectionID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[connectionID]
+	if !ok || c.state == mcpgw.CircuitClosed {
+		return true
+	}
+	if c.state == mcpgw.CircuitOpen {
+		if b.now().Before(c.retryAt) {
+			return false
+		}
+		c.state = mcpgw.CircuitHalfOpen
+	}
+	if c.probing {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// record reports the outcome of using a connection and returns whether it
+// opened the circuit. Calls cancelled by their caller say nothing about the
+// connection and are ignored.
+func (b *breaker) record(ctx context.Context, connectionID string, err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[connectionID]
+	if err == nil {
+		if ok {
+			delete(b.circuits, connectionID)
+		}
+		return false
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		if ok {
+			c.probing = false
+		}
+		return false
+	}
+	if !ok {
+		c = &circuit{state: mcpgw.CircuitClosed}
+		b.circuits[connectionID] = c
+	}
+	c.failures++
+	c.lastError = err.Error()
+	switch {
+	case c.state == mcpgw.CircuitHalfOpen:
+		c.cooldown = min(c.cooldown*2, breakerMaxCooldown)
+	case c.state == mcpgw.CircuitClosed && c.failures >= breakerThreshold:
+		c.cooldown = breakerCooldown
+	default:
+		return false
+	}
+	now := b.now()
+	c.state = mcpgw.CircuitOpen
+	c.probing = false
+	c.openedAt = now
+	c.retryAt = now.Add(c.cooldown)
+	return true
+}
+
+func (b *breaker) status(connectionID string) (mcpgw.CircuitStatus, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[connectionID]
+	if !ok {
+		return mcpgw.CircuitStatus{}, false
+	}
+	state := c.state
+	if state == mcpgw.CircuitOpen && !b.now().Before(c.retryAt) {
+		state = mcpgw.CircuitHalfOpen
+	}
+	return mcpgw.CircuitStatus{
+		State:     state,
+		Failures:  c.failures,
+		LastError: c.lastError,
+		OpenedAt:  c.openedAt,
+		RetryAt:   c.retryAt,
+	}, true
+}
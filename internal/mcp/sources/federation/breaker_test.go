@@ -0,0 +1,113 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	b := newBreaker()
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+	failure := errors.New("connection refused")
+
+	for range breakerThreshold - 1 {
+		if !b.allow("conn-1") {
+			t.Fatal("expected closed circuit to allow calls")
+		}
+		b.record(ctx, "conn-1", failure)
+	}
+	if !b.allow("conn-1") || !b.record(ctx, "conn-1", failure) {
+		t.Fatal("expected threshold failure to open the circuit")
+	}
+	if b.allow("conn-1") {
+		t.Fatal("expected open circuit to reject calls")
+	}
+	status, ok := b.status("conn-1")
+	if !ok || status.State != mcpgw.CircuitOpen || status.Failures != breakerThreshold || status.LastError != failure.Error() {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	now = now.Add(breakerCooldown)
+	if !b.allow("conn-1") {
+		t.Fatal("expected a probe after the cooldown")
+	}
+	if b.allow("conn-1") {
+		t.Fatal("expected only one probe at a time")
+	}
+	b.record(ctx, "conn-1", failure)
+	status, _ = b.status("conn-1")
+	if status.State != mcpgw.CircuitOpen || !status.RetryAt.Equal(now.Add(2*breakerCooldown)) {
+		t.Fatalf("expected failed probe to double the cooldown: %+v", status)
+	}
+
+	now = now.Add(2 * breakerCooldown)
+	if !b.allow("conn-1") {
+		t.Fatal("expected a probe after the cooldown")
+	}
+	b.record(ctx, "conn-1", nil)
+	if _, ok := b.status("conn-1"); ok {
+		t.Fatal("expected successful probe to close the circuit")
+	}
+}
+
+func TestBreakerIgnoresCancelledCalls(t *testing.T) {
+	b := newBreaker()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range breakerThreshold {
+		b.record(ctx, "conn-1", ctx.Err())
+	}
+	if _, ok := b.status("conn-1"); ok {
+		t.Fatal("expected cancelled calls not to count as failures")
+	}
+}
+
+func TestSourceHidesToolsOfOpenCircuit(t *testing.T) {
+	gateway := &testGateway{
+		listHTTP: []mcpgw.ToolDescriptor{{Name: "search", InputSchema: map[string]any{"type": "object"}}},
+		callErr:  errors.New("upstream unavailable"),
+	}
+	lister := &testConnectionLister{
+		items: []mcpgw.Connection{
+			{ID: "conn-1", Name: "remote", Type: "http", Active: true, Config: map[string]any{"url": "http://example.com/mcp", "timeout": float64(10)}},
+		},
+	}
+	source := NewSource(slog.Default(), gateway, lister)
+	session := mcpgw.ToolSessionContext{BotID: "bot-1"}
+
+	tools, err := source.ListTools(context.Background(), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 1 || tools[0].Timeout != 10*time.Second {
+		t.Fatalf("expected one tool with the connection timeout, got %+v", tools)
+	}
+	for range breakerThreshold {
+		if _, err := source.CallTool(context.Background(), session, "remote_search", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status, ok := source.CircuitStatus("conn-1")
+	if !ok || status.State != mcpgw.CircuitOpen {
+		t.Fatalf("expected open circuit, got %+v", status)
+	}
+
+	gateway.lastCallType = ""
+	if _, err := source.CallTool(context.Background(), session, "remote_search", nil); !errors.Is(err, mcpgw.ErrToolNotFound) || gateway.lastCallType != "" {
+		t.Fatalf("expected the open circuit to hide the tool without reaching the server, got %v", err)
+	}
+	tools, err = source.ListTools(context.Background(), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 0 {
+		t.Fatalf("expected tools of the open circuit to be hidden, got %+v", tools)
+	}
+}
@@ -0,0 +1,226 @@
+package federation
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpgw "github.com/memohai/memoh/internal/mcp"
+)
+
+// CapabilityGateway is implemented by gateways that can also reach the
+// resources and prompts of downstream MCP servers.
+type CapabilityGateway interface {
+	ListConnectionResources(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ResourceDescriptor, error)
+	ReadConnectionResource(ctx context.Context, botID string, connection mcpgw.Connection, uri string) ([]mcpgw.ResourceContent, error)
+	ListConnectionPrompts(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.PromptDescriptor, error)
+	GetConnectionPrompt(ctx context.Context, botID string, connection mcpgw.Connection, name string, arguments map[string]string) (mcpgw.PromptResult, error)
+}
+
+type promptRoute struct {
+	originalName string
+	connection   mcpgw.Connection
+}
+
+type resourceCacheEntry struct {
+	expiresAt time.Time
+	resources []mcpgw.ResourceDescriptor
+	routes    map[string]mcpgw.Connection
+}
+
+type promptCacheEntry struct {
+	expiresAt time.Time
+	prompts   []mcpgw.PromptDescriptor
+	routes    map[string]promptRoute
+}
+
+// ListResources lists the resources of the bot's active connections. URIs
+// are kept as published by the server; when two connections publish the
+// same URI the first connection by name wins.
+func (s *Source) ListResources(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.ResourceDescriptor, error) {
+	botID := strings.TrimSpace(session.BotID)
+	gateway, ok := s.gateway.(CapabilityGateway)
+	if botID == "" || !ok {
+		return []mcpgw.ResourceDescriptor{}, nil
+	}
+	entry := s.resourceEntry(ctx, gateway, botID, false)
+	return append([]mcpgw.ResourceDescriptor(nil), entry.resources...), nil
+}
+
+// ReadResource reads a resource from the connection that published it.
+func (s *Source) ReadResource(ctx context.Context, session mcpgw.ToolSessionContext, uri string) ([]mcpgw.ResourceContent, error) {
+	botID := strings.TrimSpace(session.BotID)
+	gateway, ok := s.gateway.(CapabilityGateway)
+	if botID == "" || !ok {
+		return nil, mcpgw.ErrResourceNotFound
+	}
+	uri = strings.TrimSpace(uri)
+	connection, ok := s.resourceEntry(ctx, gateway, botID, false).routes[uri]
+	if !ok {
+		if connection, ok = s.resourceEntry(ctx, gateway, botID, true).routes[uri]; !ok {
+			return nil, mcpgw.ErrResourceNotFound
+		}
+	}
+	return gateway.ReadConnectionResource(ctx, botID, connection, uri)
+}
+
+// ResourceTool authorizes reading a resource like a tool named
+// "<connection>_read_resource", so policies on a connection's tool prefix
+// also cover its resources.
+func (s *Source) ResourceTool(ctx context.Context, session mcpgw.ToolSessionContext, uri string) (string, map[string]any, bool) {
+	botID := strings.TrimSpace(session.BotID)
+	gateway, ok := s.gateway.(CapabilityGateway)
+	if botID == "" || !ok {
+		return "", nil, false
+	}
+	connection, ok := s.resourceEntry(ctx, gateway, botID, false).routes[strings.TrimSpace(uri)]
+	if !ok {
+		return "", nil, false
+	}
+	return sanitizePrefix(connection.Name) + "_read_resource", nil, true
+}
+
+// ListPrompts lists the prompts of the bot's active connections, prefixed
+// with the connection name like federated tools.
+func (s *Source) ListPrompts(ctx context.Context, session mcpgw.ToolSessionContext) ([]mcpgw.PromptDescriptor, error) {
+	botID := strings.TrimSpace(session.BotID)
+	gateway, ok := s.gateway.(CapabilityGateway)
+	if botID == "" || !ok {
+		return []mcpgw.PromptDescriptor{}, nil
+	}
+	entry := s.promptEntry(ctx, gateway, botID, false)
+	return append([]mcpgw.PromptDescriptor(nil), entry.prompts...), nil
+}
+
+// GetPrompt renders a prompt on the connection that published it.
+func (s *Source) GetPrompt(ctx context.Context, session mcpgw.ToolSessionContext, name string, arguments map[string]string) (mcpgw.PromptResult, error) {
+	botID := strings.TrimSpace(session.BotID)
+	gateway, ok := s.gateway.(CapabilityGateway)
+	if botID == "" || !ok {
+		return mcpgw.PromptResult{}, mcpgw.ErrPromptNotFound
+	}
+	name = strings.TrimSpace(name)
+	route, ok := s.promptEntry(ctx, gateway, botID, false).routes[name]
+	if !ok {
+		if route, ok = s.promptEntry(ctx, gateway, botID, true).routes[name]; !ok {
+			return mcpgw.PromptResult{}, mcpgw.ErrPromptNotFound
+		}
+	}
+	return gateway.GetConnectionPrompt(ctx, botID, route.connection, route.originalName, arguments)
+}
+
+func (s *Source) resourceEntry(ctx context.Context, gateway CapabilityGateway, botID string, force bool) resourceCacheEntry {
+	if !force {
+		s.mu.Lock()
+		cached, ok := s.resourceCache[botID]
+		s.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached
+		}
+	}
+	entry := resourceCacheEntry{
+		resources: []mcpgw.ResourceDescriptor{},
+		routes:    map[string]mcpgw.Connection{},
+	}
+	for _, connection := range s.activeConnections(ctx, botID) {
+		items, err := gateway.ListConnectionResources(ctx, botID, connection)
+		if err != nil {
+			s.logger.Debug("list resources from connection failed", slog.String("connection_id", connection.ID), slog.String("name", connection.Name), slog.Any("error", err))
+			continue
+		}
+		label := strings.TrimSpace(connection.Name)
+		for _, item := range items {
+			uri := strings.TrimSpace(item.URI)
+			if uri == "" {
+				continue
+			}
+			if _, exists := entry.routes[uri]; exists {
+				continue
+			}
+			item.URI = uri
+			if strings.TrimSpace(item.Description) != "" {
+				item.Description = "[" + label + "] " + strings.TrimSpace(item.Description)
+			} else {
+				item.Description = "[" + label + "]"
+			}
+			entry.routes[uri] = connection
+			entry.resources = append(entry.resources, item)
+		}
+	}
+	entry.expiresAt = time.Now().Add(cacheTTL)
+	s.mu.Lock()
+	s.resourceCache[botID] = entry
+	s.mu.Unlock()
+	return entry
+}
+
+func (s *Source) promptEntry(ctx context.Context, gateway CapabilityGateway, botID string, force bool) promptCacheEntry {
+	if !force {
+		s.mu.Lock()
+		cached, ok := s.promptCache[botID]
+		s.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached
+		}
+	}
+	entry := promptCacheEntry{
+		prompts: []mcpgw.PromptDescriptor{},
+		routes:  map[string]promptRoute{},
+	}
+	for _, connection := range s.activeConnections(ctx, botID) {
+		items, err := gateway.ListConnectionPrompts(ctx, botID, connection)
+		if err != nil {
+			s.logger.Debug("list prompts from connection failed", slog.String("connection_id", connection.ID), slog.String("name", connection.Name), slog.Any("error", err))
+			continue
+		}
+		prefix := sanitizePrefix(connection.Name)
+		label := strings.TrimSpace(connection.Name)
+		for _, item := range items {
+			origin := strings.TrimSpace(item.Name)
+			if origin == "" {
+				continue
+			}
+			alias := prefix + "_" + origin
+			for i := 2; ; i++ {
+				if _, exists := entry.routes[alias]; !exists {
+					break
+				}
+				alias = prefix + "_" + origin + "_" + strconv.Itoa(i)
+			}
+			item.Name = alias
+			if strings.TrimSpace(item.Description) != "" {
+				item.Description = "[" + label + "] " + strings.TrimSpace(item.Description)
+			} else {
+				item.Description = "[" + label + "] " + origin
+			}
+			entry.routes[alias] = promptRoute{originalName: origin, connection: connection}
+			entry.prompts = append(entry.prompts, item)
+		}
+	}
+	entry.expiresAt = time.Now().Add(cacheTTL)
+	s.mu.Lock()
+	s.promptCache[botID] = entry
+	s.mu.Unlock()
+	return entry
+}
+
+func (s *Source) activeConnections(ctx context.Context, botID string) []mcpgw.Connection {
+	if s.connections == nil {
+		return nil
+	}
+	items, err := s.connections.ListActiveByBot(ctx, botID)
+	if err != nil {
+		s.logger.Warn("list mcp connections failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return nil
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name == items[j].Name {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
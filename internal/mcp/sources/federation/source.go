@@ -2,6 +2,7 @@ package federation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sort"
@@ -13,7 +14,12 @@ import (
 	mcpgw "github.com/memohai/memoh/internal/mcp"
 )
 
-const cacheTTL = 5 * time.Second
+const (
+	cacheTTL = 5 * time.Second
+	// listTimeout bounds listing the tools of one connection so a hung
+	// server does not stall the others.
+	listTimeout = 15 * time.Second
+)
 
 type ConnectionLister interface {
 	ListActiveByBot(ctx context.Context, botID string) ([]mcpgw.Connection, error)
@@ -46,9 +52,12 @@ type Source struct {
 	logger      *slog.Logger
 	gateway     Gateway
 	connections ConnectionLister
+	breaker     *breaker
 
-	mu    sync.Mutex
-	cache map[string]cacheEntry
+	mu            sync.Mutex
+	cache         map[string]cacheEntry
+	resourceCache map[string]resourceCacheEntry
+	promptCache   map[string]promptCacheEntry
 }
 
 func NewSource(log *slog.Logger, gateway Gateway, connections ConnectionLister) *Source {
@@ -59,7 +68,11 @@ func NewSource(log *slog.Logger, gateway Gateway, connections ConnectionLister)
 		logger:      log.With(slog.String("source", "federated_mcp_tool")),
 		gateway:     gateway,
 		connections: connections,
+		breaker:     newBreaker(),
 		cache:       map[string]cacheEntry{},
+
+		resourceCache: map[string]resourceCacheEntry{},
+		promptCache:   map[string]promptCacheEntry{},
 	}
 }
 
@@ -103,6 +116,9 @@ func (s *Source) CallTool(ctx context.Context, session mcpgw.ToolSessionContext,
 	if arguments == nil {
 		arguments = map[string]any{}
 	}
+	if !s.breaker.allow(route.connection.ID) {
+		return mcpgw.BuildToolErrorResult("mcp server " + route.connection.Name + " is temporarily disabled after repeated failures"), nil
+	}
 
 	var (
 		payload map[string]any
@@ -116,8 +132,11 @@ func (s *Source) CallTool(ctx context.Context, session mcpgw.ToolSessionContext,
 	case "stdio":
 		payload, err = s.gateway.CallStdioConnectionTool(ctx, botID, route.connection, route.originalName, arguments)
 	default:
+		s.breaker.record(ctx, route.connection.ID, nil)
 		return mcpgw.BuildToolErrorResult("unsupported federated source"), nil
 	}
+	// Errors reported by the server itself show that it is reachable.
+	s.recordConnection(ctx, botID, route.connection, err)
 	if err != nil {
 		return mcpgw.BuildToolErrorResult(err.Error()), nil
 	}
@@ -171,18 +190,17 @@ func (s *Source) buildToolsAndRoutes(ctx context.Context, botID string) ([]mcpgw
 				return items[i].Name < items[j].Name
 			})
 			for _, connection := range items {
-				var connTools []mcpgw.ToolDescriptor
-				switch strings.ToLower(strings.TrimSpace(connection.Type)) {
-				case "http":
-					connTools, err = s.gateway.ListHTTPConnectionTools(ctx, connection)
-				case "sse":
-					connTools, err = s.gateway.ListSSEConnectionTools(ctx, connection)
-				case "stdio":
-					connTools, err = s.gateway.ListStdioConnectionTools(ctx, botID, connection)
-				default:
+				if !s.breaker.allow(connection.ID) {
+					// Hide the tools of a failing server until its cooldown passed.
+					continue
+				}
+				connTools, err := s.listConnectionTools(ctx, botID, connection)
+				if errors.Is(err, errUnsupportedConnection) {
+					s.breaker.record(ctx, connection.ID, nil)
 					s.logger.Warn("unsupported mcp connection type", slog.String("connection_id", connection.ID), slog.String("type", connection.Type))
 					continue
 				}
+				s.recordConnection(ctx, botID, connection, err)
 				if err != nil {
 					s.logger.Warn("list tools from connection failed", slog.String("connection_id", connection.ID), slog.String("name", connection.Name), slog.Any("error", err))
 					continue
@@ -195,6 +213,7 @@ func (s *Source) buildToolsAndRoutes(ctx context.Context, botID string) ([]mcpgw
 						alias = prefix + "_" + origin
 					}
 					tool.Name = alias
+					tool.Timeout = connection.Timeout()
 					if strings.TrimSpace(tool.Description) != "" {
 						tool.Description = "[" + strings.TrimSpace(connection.Name) + "] " + tool.Description
 					} else {
@@ -212,6 +231,51 @@ func (s *Source) buildToolsAndRoutes(ctx context.Context, botID string) ([]mcpgw
 	return tools, routes
 }
 
+var errUnsupportedConnection = errors.New("unsupported mcp connection type")
+
+func (s *Source) listConnectionTools(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ToolDescriptor, error) {
+	timeout := listTimeout
+	if connTimeout := connection.Timeout(); connTimeout > 0 {
+		timeout = connTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	switch strings.ToLower(strings.TrimSpace(connection.Type)) {
+	case "http":
+		return s.gateway.ListHTTPConnectionTools(ctx, connection)
+	case "sse":
+		return s.gateway.ListSSEConnectionTools(ctx, connection)
+	case "stdio":
+		return s.gateway.ListStdioConnectionTools(ctx, botID, connection)
+	default:
+		return nil, errUnsupportedConnection
+	}
+}
+
+// recordConnection feeds the outcome of using a connection to its circuit
+// breaker. When the circuit opens, the bot's cached tools are dropped so the
+// connection's tools disappear right away.
+func (s *Source) recordConnection(ctx context.Context, botID string, connection mcpgw.Connection, err error) {
+	if !s.breaker.record(ctx, connection.ID, err) {
+		return
+	}
+	s.logger.Warn("mcp connection disabled after repeated failures",
+		slog.String("bot_id", botID),
+		slog.String("connection_id", connection.ID),
+		slog.String("name", connection.Name),
+		slog.Any("error", err),
+	)
+	s.mu.Lock()
+	delete(s.cache, botID)
+	s.mu.Unlock()
+}
+
+// CircuitStatus returns the circuit breaker state of a connection that has
+// failed since its last success.
+func (s *Source) CircuitStatus(connectionID string) (mcpgw.CircuitStatus, bool) {
+	return s.breaker.status(strings.TrimSpace(connectionID))
+}
+
 func sanitizePrefix(raw string) string {
 	raw = strings.TrimSpace(strings.ToLower(raw))
 	if raw == "" {
@@ -242,11 +306,22 @@ func cloneTools(items []mcpgw.ToolDescriptor) []mcpgw.ToolDescriptor {
 			Name:        item.Name,
 			Description: item.Description,
 			InputSchema: item.InputSchema,
+			Timeout:     item.Timeout,
 		})
 	}
 	return out
 }
 
+// Invalidate drops the cached tools, resources and prompts of a bot.
+func (s *Source) Invalidate(botID string) {
+	botID = strings.TrimSpace(botID)
+	s.mu.Lock()
+	delete(s.cache, botID)
+	delete(s.resourceCache, botID)
+	delete(s.promptCache, botID)
+	s.mu.Unlock()
+}
+
 func (s *Source) getCache(botID string) (cacheEntry, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -274,6 +349,15 @@ func (s *Source) getRoute(botID, toolName string) (toolRoute, bool) {
 	return route, exists
 }
 
+// ToolOrigin returns the connection serving a federated tool.
+func (s *Source) ToolOrigin(botID, toolName string) (mcpgw.ToolOrigin, bool) {
+	route, ok := s.getRoute(strings.TrimSpace(botID), toolName)
+	if !ok {
+		return mcpgw.ToolOrigin{}, false
+	}
+	return mcpgw.ToolOrigin{ConnectionID: route.connection.ID, ConnectionName: route.connection.Name}, true
+}
+
 func (s *Source) String() string {
 	return fmt.Sprintf("FederationSource(%p)", s)
 }
@@ -25,6 +25,7 @@ type testGateway struct {
 	listSSE   []mcpgw.ToolDescriptor
 	listStdio []mcpgw.ToolDescriptor
 
+	callErr      error
 	lastCallType string
 }
 
@@ -34,6 +35,9 @@ func (g *testGateway) ListHTTPConnectionTools(ctx context.Context, connection mc
 
 func (g *testGateway) CallHTTPConnectionTool(ctx context.Context, connection mcpgw.Connection, toolName string, args map[string]any) (map[string]any, error) {
 	g.lastCallType = "http"
+	if g.callErr != nil {
+		return nil, g.callErr
+	}
 	return map[string]any{"result": map[string]any{"ok": true, "route": "http"}}, nil
 }
 
@@ -124,3 +128,103 @@ func TestSourceCallToolRoutesToSSEConnection(t *testing.T) {
 		t.Fatalf("expected ok=true in result")
 	}
 }
+
+type testCapabilityGateway struct {
+	testGateway
+	resources map[string][]mcpgw.ResourceDescriptor
+	prompts   map[string][]mcpgw.PromptDescriptor
+
+	lastRead   string
+	lastPrompt string
+}
+
+func (g *testCapabilityGateway) ListConnectionResources(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.ResourceDescriptor, error) {
+	return g.resources[connection.ID], nil
+}
+
+func (g *testCapabilityGateway) ReadConnectionResource(ctx context.Context, botID string, connection mcpgw.Connection, uri string) ([]mcpgw.ResourceContent, error) {
+	g.lastRead = connection.ID + " " + uri
+	return mcpgw.TextResource(uri, "", "ok"), nil
+}
+
+func (g *testCapabilityGateway) ListConnectionPrompts(ctx context.Context, botID string, connection mcpgw.Connection) ([]mcpgw.PromptDescriptor, error) {
+	return g.prompts[connection.ID], nil
+}
+
+func (g *testCapabilityGateway) GetConnectionPrompt(ctx context.Context, botID string, connection mcpgw.Connection, name string, arguments map[string]string) (mcpgw.PromptResult, error) {
+	g.lastPrompt = connection.ID + " " + name
+	return mcpgw.PromptResult{Description: name}, nil
+}
+
+func TestSourceResourcesAndPrompts(t *testing.T) {
+	gateway := &testCapabilityGateway{
+		resources: map[string][]mcpgw.ResourceDescriptor{
+			"a": {{URI: "docs://readme", Name: "readme", Description: "Project readme"}},
+			"b": {{URI: "docs://readme", Name: "other readme"}, {URI: "db://tables", Name: "tables"}},
+		},
+		prompts: map[string][]mcpgw.PromptDescriptor{
+			"a": {{Name: "summarize"}},
+			"b": {{Name: "summarize", Description: "Summarize a table"}},
+		},
+	}
+	source := NewSource(slog.Default(), gateway, &testConnectionLister{items: []mcpgw.Connection{
+		{ID: "b", Name: "Database", Type: "stdio"},
+		{ID: "a", Name: "Docs", Type: "http"},
+	}})
+	session := mcpgw.ToolSessionContext{BotID: "bot-1"}
+
+	resources, err := source.ListResources(context.Background(), session)
+	if err != nil {
+		t.Fatalf("list resources failed: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected duplicated uri to be skipped, got %+v", resources)
+	}
+	if resources[0].URI != "docs://readme" || resources[0].Description != "[Database]" {
+		t.Fatalf("expected first connection by name to win, got %+v", resources[0])
+	}
+	if _, err := source.ReadResource(context.Background(), session, "db://tables"); err != nil || gateway.lastRead != "b db://tables" {
+		t.Fatalf("unexpected read route %q (%v)", gateway.lastRead, err)
+	}
+	if _, err := source.ReadResource(context.Background(), session, "missing://x"); err != mcpgw.ErrResourceNotFound {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+
+	prompts, err := source.ListPrompts(context.Background(), session)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("unexpected prompts %+v (%v)", prompts, err)
+	}
+	if prompts[0].Name != "database_summarize" || prompts[1].Name != "docs_summarize" {
+		t.Fatalf("expected prefixed prompt names, got %s and %s", prompts[0].Name, prompts[1].Name)
+	}
+	if _, err := source.GetPrompt(context.Background(), session, "docs_summarize", nil); err != nil || gateway.lastPrompt != "a summarize" {
+		t.Fatalf("unexpected prompt route %q (%v)", gateway.lastPrompt, err)
+	}
+
+	plain := NewSource(slog.Default(), &testGateway{}, &testConnectionLister{})
+	if resources, _ := plain.ListResources(context.Background(), session); len(resources) != 0 {
+		t.Fatal("expected no resources from a tools-only gateway")
+	}
+}
+
+func TestSourceInvalidateRefreshesTools(t *testing.T) {
+	gateway := &testGateway{
+		listHTTP: []mcpgw.ToolDescriptor{{Name: "search", InputSchema: map[string]any{"type": "object"}}},
+	}
+	lister := &testConnectionLister{items: []mcpgw.Connection{{ID: "c1", Name: "remote", Type: "http", Active: true}}}
+	source := NewSource(slog.Default(), gateway, lister)
+	session := mcpgw.ToolSessionContext{BotID: "bot-1"}
+
+	tools, err := source.ListTools(context.Background(), session)
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("unexpected tools %+v (%v)", tools, err)
+	}
+	gateway.listHTTP = append(gateway.listHTTP, mcpgw.ToolDescriptor{Name: "fetch", InputSchema: map[string]any{"type": "object"}})
+	if tools, _ = source.ListTools(context.Background(), session); len(tools) != 1 {
+		t.Fatalf("expected cached tools before invalidation, got %d", len(tools))
+	}
+	source.Invalidate("bot-1")
+	if tools, _ = source.ListTools(context.Background(), session); len(tools) != 2 {
+		t.Fatalf("expected refreshed tools after invalidation, got %d", len(tools))
+	}
+}
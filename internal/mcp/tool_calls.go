@@ -0,0 +1,471 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// Tool call statuses stored in the audit log.
+const (
+	ToolCallStatusOK       = "ok"
+	ToolCallStatusError    = "error"
+	ToolCallStatusDenied   = "denied"
+	ToolCallStatusNotFound = "not_found"
+)
+
+// Tool call sources stored in the audit log.
+const (
+	ToolCallSourceBuiltin    = "builtin"
+	ToolCallSourceFederation = "federation"
+)
+
+// Orderings accepted by ToolCallLogService.Stats.
+const (
+	ToolStatsSortCalls   = "calls"
+	ToolStatsSortSlowest = "slowest"
+	ToolStatsSortFailing = "failing"
+)
+
+const (
+	defaultToolCallListLimit = 50
+	maxToolCallListLimit     = 200
+	defaultToolStatsWindow   = 7 * 24 * time.Hour
+	maxToolCallErrorRunes    = 1000
+	maxToolCallArgumentRunes = 500
+	redactedArgumentValue    = "[redacted]"
+	toolCallSweepInterval    = time.Hour
+)
+
+// sensitiveArgumentKeys are argument name fragments whose values are never
+// stored in the audit log.
+var sensitiveArgumentKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie", "credential", "private_key"}
+
+// ToolCallRecord describes one finished tool call for the audit log.
+type ToolCallRecord struct {
+	Session        ToolSessionContext
+	Tool           string
+	Source         string
+	ConnectionID   string
+	ConnectionName string
+	Arguments      map[string]any
+	Status         string
+	Error          string
+	StartedAt      time.Time
+	Duration       time.Duration
+}
+
+// ToolCall is a stored tool call. Arguments are redacted: secret-looking
+// values are masked and long values truncated; ArgumentsHash identifies the
+// original arguments.
+type ToolCall struct {
+	ID                string         `json:"id"`
+	BotID             string         `json:"bot_id"`
+	ChatID            string         `json:"chat_id,omitempty"`
+	ChannelIdentityID string         `json:"channel_identity_id,omitempty"`
+	Tool              string         `json:"tool"`
+	Source            string         `json:"source"`
+	ConnectionID      string         `json:"connection_id,omitempty"`
+	ConnectionName    string         `json:"connection_name,omitempty"`
+	Arguments         map[string]any `json:"arguments"`
+	ArgumentsHash     string         `json:"arguments_hash"`
+	Status            string         `json:"status"`
+	Error             string         `json:"error,omitempty"`
+	DurationMs        int32          `json:"duration_ms"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+// ToolCallListResponse wraps tool call list responses.
+type ToolCallListResponse struct {
+	Items []ToolCall `json:"items"`
+}
+
+// ToolCallListFilter narrows ToolCallLogService.List.
+type ToolCallListFilter struct {
+	Tool   string
+	Status string
+	Before time.Time
+	Limit  int
+}
+
+// ToolUsageStat aggregates the calls of one tool.
+type ToolUsageStat struct {
+	Tool           string    `json:"tool"`
+	Source         string    `json:"source"`
+	ConnectionName string    `json:"connection_name,omitempty"`
+	Calls          int64     `json:"calls"`
+	Errors         int64     `json:"errors"`
+	Denied         int64     `json:"denied"`
+	ErrorRate      float64   `json:"error_rate"`
+	AvgDurationMs  float64   `json:"avg_duration_ms"`
+	P95DurationMs  float64   `json:"p95_duration_ms"`
+	MaxDurationMs  int32     `json:"max_duration_ms"`
+	LastCalledAt   time.Time `json:"last_called_at"`
+}
+
+// ToolUsageStatsQuery selects the window and ordering of tool usage stats.
+type ToolUsageStatsQuery struct {
+	Since time.Time
+	Sort  string
+	Limit int
+}
+
+// ToolUsageStatsResponse wraps tool usage stats.
+type ToolUsageStatsResponse struct {
+	Since time.Time       `json:"since"`
+	Sort  string          `json:"sort"`
+	Items []ToolUsageStat `json:"items"`
+}
+
+// ToolCallLogService writes and queries the tool call audit log.
+type ToolCallLogService struct {
+	queries   *sqlc.Queries
+	logger    *slog.Logger
+	retention time.Duration
+}
+
+// NewToolCallLogService creates a ToolCallLogService backed by sqlc queries.
+func NewToolCallLogService(log *slog.Logger, queries *sqlc.Queries) *ToolCallLogService {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &ToolCallLogService{
+		queries: queries,
+		logger:  log.With(slog.String("service", "tool_calls")),
+	}
+}
+
+// SetRetention configures how long RunSweeper keeps tool calls; 0 keeps
+// them forever.
+func (s *ToolCallLogService) SetRetention(retention time.Duration) {
+	s.retention = retention
+}
+
+// RunSweeper deletes tool calls older than the retention until ctx is
+// cancelled.
+func (s *ToolCallLogService) RunSweeper(ctx context.Context) {
+	if s.queries == nil || s.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(toolCallSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Prune(ctx, time.Now().Add(-s.retention))
+			if err != nil {
+				s.logger.Warn("tool call sweep failed", slog.Any("error", err))
+				continue
+			}
+			if deleted > 0 {
+				s.logger.Info("tool call sweep finished", slog.Int64("deleted", deleted))
+			}
+		}
+	}
+}
+
+// Prune deletes the tool calls made before the given time.
+func (s *ToolCallLogService) Prune(ctx context.Context, before time.Time) (int64, error) {
+	if s.queries == nil {
+		return 0, fmt.Errorf("tool call queries not configured")
+	}
+	return s.queries.DeleteToolCallsBefore(ctx, pgtype.Timestamptz{Time: before.UTC(), Valid: true})
+}
+
+// RecordToolCall stores a tool call. Failures are logged and never surface
+// to the caller of the tool.
+func (s *ToolCallLogService) RecordToolCall(ctx context.Context, call ToolCallRecord) {
+	if s.queries == nil {
+		return
+	}
+	params, err := toolCallParams(call)
+	if err != nil {
+		s.logger.Warn("build tool call record failed", slog.String("tool", call.Tool), slog.Any("error", err))
+		return
+	}
+	if err := s.queries.CreateToolCall(context.WithoutCancel(ctx), params); err != nil {
+		s.logger.Warn("record tool call failed",
+			slog.String("bot_id", call.Session.BotID),
+			slog.String("tool", call.Tool),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// List returns the most recent tool calls of a bot, newest first.
+func (s *ToolCallLogService) List(ctx context.Context, botID string, filter ToolCallListFilter) ([]ToolCall, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("tool call queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	params := sqlc.ListToolCallsParams{
+		BotID:    pgBotID,
+		MaxCount: int32(clampLimit(filter.Limit, defaultToolCallListLimit, maxToolCallListLimit)),
+	}
+	if tool := strings.TrimSpace(filter.Tool); tool != "" {
+		params.Tool = pgtype.Text{String: tool, Valid: true}
+	}
+	if status := strings.TrimSpace(filter.Status); status != "" {
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+	if !filter.Before.IsZero() {
+		params.Before = pgtype.Timestamptz{Time: filter.Before, Valid: true}
+	}
+	rows, err := s.queries.ListToolCalls(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ToolCall, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, normalizeToolCall(row))
+	}
+	return items, nil
+}
+
+// Stats aggregates the tool calls of a bot since the given time, ordered by
+// call count, by p95 duration ("slowest") or by error count ("failing").
+func (s *ToolCallLogService) Stats(ctx context.Context, botID string, query ToolUsageStatsQuery) (ToolUsageStatsResponse, error) {
+	if s.queries == nil {
+		return ToolUsageStatsResponse{}, fmt.Errorf("tool call queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return ToolUsageStatsResponse{}, err
+	}
+	sortBy := strings.ToLower(strings.TrimSpace(query.Sort))
+	switch sortBy {
+	case "":
+		sortBy = ToolStatsSortCalls
+	case ToolStatsSortCalls, ToolStatsSortSlowest, ToolStatsSortFailing:
+	default:
+		return ToolUsageStatsResponse{}, fmt.Errorf("invalid sort: %s", query.Sort)
+	}
+	since := query.Since
+	if since.IsZero() {
+		since = time.Now().Add(-defaultToolStatsWindow)
+	}
+	rows, err := s.queries.GetToolCallStats(ctx, sqlc.GetToolCallStatsParams{
+		BotID: pgBotID,
+		Since: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return ToolUsageStatsResponse{}, err
+	}
+	items := make([]ToolUsageStat, 0, len(rows))
+	for _, row := range rows {
+		item := ToolUsageStat{
+			Tool:           row.Tool,
+			Source:         row.Source,
+			ConnectionName: row.ConnectionName,
+			Calls:          row.Calls,
+			Errors:         row.Errors,
+			Denied:         row.Denied,
+			AvgDurationMs:  row.AvgDurationMs,
+			P95DurationMs:  row.P95DurationMs,
+			MaxDurationMs:  row.MaxDurationMs,
+			LastCalledAt:   db.TimeFromPg(row.LastCalledAt),
+		}
+		if item.Calls > 0 {
+			item.ErrorRate = float64(item.Errors) / float64(item.Calls)
+		}
+		items = append(items, item)
+	}
+	sortToolUsageStats(items, sortBy)
+	if query.Limit > 0 && len(items) > query.Limit {
+		items = items[:query.Limit]
+	}
+	return ToolUsageStatsResponse{Since: since, Sort: sortBy, Items: items}, nil
+}
+
+func sortToolUsageStats(items []ToolUsageStat, sortBy string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch sortBy {
+		case ToolStatsSortSlowest:
+			if a.P95DurationMs != b.P95DurationMs {
+				return a.P95DurationMs > b.P95DurationMs
+			}
+			if a.MaxDurationMs != b.MaxDurationMs {
+				return a.MaxDurationMs > b.MaxDurationMs
+			}
+		case ToolStatsSortFailing:
+			if a.Errors != b.Errors {
+				return a.Errors > b.Errors
+			}
+			if a.ErrorRate != b.ErrorRate {
+				return a.ErrorRate > b.ErrorRate
+			}
+		}
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Tool < b.Tool
+	})
+}
+
+func toolCallParams(call ToolCallRecord) (sqlc.CreateToolCallParams, error) {
+	pgBotID, err := db.ParseUUID(call.Session.BotID)
+	if err != nil {
+		return sqlc.CreateToolCallParams{}, err
+	}
+	arguments, err := json.Marshal(redactArguments(call.Arguments))
+	if err != nil {
+		return sqlc.CreateToolCallParams{}, err
+	}
+	var connectionID pgtype.UUID
+	if id := strings.TrimSpace(call.ConnectionID); id != "" {
+		if connectionID, err = db.ParseUUID(id); err != nil {
+			return sqlc.CreateToolCallParams{}, err
+		}
+	}
+	startedAt := call.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	return sqlc.CreateToolCallParams{
+		BotID:             pgBotID,
+		ChatID:            strings.TrimSpace(call.Session.ChatID),
+		ChannelIdentityID: strings.TrimSpace(call.Session.ChannelIdentityID),
+		Tool:              call.Tool,
+		Source:            call.Source,
+		ConnectionID:      connectionID,
+		ConnectionName:    call.ConnectionName,
+		Arguments:         arguments,
+		ArgumentsHash:     hashArguments(call.Arguments),
+		Status:            call.Status,
+		Error:             truncateRunes(strings.TrimSpace(call.Error), maxToolCallErrorRunes),
+		DurationMs:        int32(call.Duration.Milliseconds()),
+		CreatedAt:         pgtype.Timestamptz{Time: startedAt, Valid: true},
+	}, nil
+}
+
+// hashArguments returns a stable digest of the arguments; json.Marshal sorts
+// map keys, so equal arguments hash equally.
+func hashArguments(arguments map[string]any) string {
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	payload, err := json.Marshal(arguments)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// redactArguments masks secret-looking values and truncates long strings.
+func redactArguments(arguments map[string]any) map[string]any {
+	out := make(map[string]any, len(arguments))
+	for key, value := range arguments {
+		if isSensitiveArgument(key) {
+			out[key] = redactedArgumentValue
+			continue
+		}
+		out[key] = redactArgumentValue(value)
+	}
+	return out
+}
+
+func redactArgumentValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return truncateRunes(v, maxToolCallArgumentRunes)
+	case map[string]any:
+		return redactArguments(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redactArgumentValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isSensitiveArgument(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, fragment := range sensitiveArgumentKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit]) + "…"
+}
+
+func clampLimit(limit, fallback, ceiling int) int {
+	if limit <= 0 {
+		return fallback
+	}
+	if limit > ceiling {
+		return ceiling
+	}
+	return limit
+}
+
+func normalizeToolCall(row sqlc.ToolCall) ToolCall {
+	arguments := map[string]any{}
+	if len(row.Arguments) > 0 {
+		_ = json.Unmarshal(row.Arguments, &arguments)
+	}
+	item := ToolCall{
+		ID:                row.ID.String(),
+		BotID:             row.BotID.String(),
+		ChatID:            row.ChatID,
+		ChannelIdentityID: row.ChannelIdentityID,
+		Tool:              row.Tool,
+		Source:            row.Source,
+		ConnectionName:    row.ConnectionName,
+		Arguments:         arguments,
+		ArgumentsHash:     row.ArgumentsHash,
+		Status:            row.Status,
+		Error:             row.Error,
+		DurationMs:        row.DurationMs,
+		CreatedAt:         db.TimeFromPg(row.CreatedAt),
+	}
+	if row.ConnectionID.Valid {
+		item.ConnectionID = row.ConnectionID.String()
+	}
+	return item
+}
+
+// toolResultError returns the error message of an isError tool result.
+func toolResultError(result map[string]any) (string, bool) {
+	if isErr, _ := result["isError"].(bool); !isErr {
+		return "", false
+	}
+	switch content := result["content"].(type) {
+	case []map[string]any:
+		if len(content) > 0 {
+			text, _ := content[0]["text"].(string)
+			return text, true
+		}
+	case []any:
+		return ContentText(result), true
+	}
+	return "", true
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactArguments(t *testing.T) {
+	long := strings.Repeat("a", maxToolCallArgumentRunes+20)
+	redacted := redactArguments(map[string]any{
+		"path":    "/data/a.txt",
+		"api-key": "sk-123",
+		"headers": map[string]any{"Authorization": "Bearer x", "accept": "json"},
+		"items":   []any{long, 3},
+	})
+	if redacted["path"] != "/data/a.txt" {
+		t.Fatalf("expected plain argument to be kept, got %v", redacted["path"])
+	}
+	if redacted["api-key"] != redactedArgumentValue {
+		t.Fatalf("expected api key to be redacted, got %v", redacted["api-key"])
+	}
+	headers := redacted["headers"].(map[string]any)
+	if headers["Authorization"] != redactedArgumentValue || headers["accept"] != "json" {
+		t.Fatalf("expected nested secrets to be redacted, got %v", headers)
+	}
+	items := redacted["items"].([]any)
+	if got := []rune(items[0].(string)); len(got) != maxToolCallArgumentRunes+1 || items[1] != 3 {
+		t.Fatalf("expected long strings to be truncated, got %d runes", len(got))
+	}
+}
+
+func TestHashArgumentsIsStable(t *testing.T) {
+	a := hashArguments(map[string]any{"a": 1, "b": "x"})
+	b := hashArguments(map[string]any{"b": "x", "a": 1})
+	if a == "" || a != b {
+		t.Fatalf("expected equal hashes, got %q and %q", a, b)
+	}
+	if hashArguments(nil) != hashArguments(map[string]any{}) {
+		t.Fatal("expected nil and empty arguments to hash equally")
+	}
+	if a == hashArguments(map[string]any{"a": 2, "b": "x"}) {
+		t.Fatal("expected different arguments to hash differently")
+	}
+}
+
+func TestSortToolUsageStats(t *testing.T) {
+	stats := func() []ToolUsageStat {
+		return []ToolUsageStat{
+			{Tool: "read", Calls: 50, Errors: 1, ErrorRate: 0.02, P95DurationMs: 20},
+			{Tool: "exec", Calls: 10, Errors: 5, ErrorRate: 0.5, P95DurationMs: 900},
+			{Tool: "web_search", Calls: 20, Errors: 5, ErrorRate: 0.25, P95DurationMs: 1500},
+		}
+	}
+	cases := map[string][]string{
+		ToolStatsSortCalls:   {"read", "web_search", "exec"},
+		ToolStatsSortSlowest: {"web_search", "exec", "read"},
+		ToolStatsSortFailing: {"exec", "web_search", "read"},
+	}
+	for sortBy, want := range cases {
+		items := stats()
+		sortToolUsageStats(items, sortBy)
+		for i, name := range want {
+			if items[i].Tool != name {
+				t.Fatalf("sort %s: expected %v at %d, got %s", sortBy, name, i, items[i].Tool)
+			}
+		}
+	}
+}
+
+func TestToolResultError(t *testing.T) {
+	if _, isErr := toolResultError(BuildToolSuccessResult(map[string]any{"ok": true})); isErr {
+		t.Fatal("expected success result not to be an error")
+	}
+	if message, isErr := toolResultError(BuildToolErrorResult("boom")); !isErr || message != "boom" {
+		t.Fatalf("expected boom, got %q", message)
+	}
+	remote := map[string]any{"isError": true, "content": []any{map[string]any{"type": "text", "text": "remote failed"}}}
+	if message, isErr := toolResultError(remote); !isErr || message != "remote failed" {
+		t.Fatalf("expected remote failure, got %q", message)
+	}
+}
@@ -26,8 +26,16 @@ type ToolGatewayService struct {
 	sources   []ToolSource
 	cacheTTL  time.Duration
 
-	mu    sync.Mutex
-	cache map[string]cachedToolRegistry
+	authorizer ToolAuthorizer
+	recorder   ToolCallRecorder
+	results    *toolResultCache
+
+	mu            sync.Mutex
+	limits        ToolCallLimits
+	slots         map[string]chan struct{}
+	cache         map[string]cachedToolRegistry
+	resourceCache map[string]cachedResourceRegistry
+	promptCache   map[string]cachedPromptRegistry
 }
 
 func NewToolGatewayService(log *slog.Logger, executors []ToolExecutor, sources []ToolSource) *ToolGatewayService {
@@ -52,6 +60,12 @@ func NewToolGatewayService(log *slog.Logger, executors []ToolExecutor, sources [
 		sources:   filteredSources,
 		cacheTTL:  defaultToolRegistryCacheTTL,
 		cache:     map[string]cachedToolRegistry{},
+		results:   newToolResultCache(defaultToolResultCacheEntries),
+		limits:    ToolCallLimits{}.withDefaults(),
+		slots:     map[string]chan struct{}{},
+
+		resourceCache: map[string]cachedResourceRegistry{},
+		promptCache:   map[string]cachedPromptRegistry{},
 	}
 }
 
@@ -62,6 +76,12 @@ func (s *ToolGatewayService) InitializeResult() map[string]any {
 			"tools": map[string]any{
 				"listChanged": false,
 			},
+			"resources": map[string]any{
+				"listChanged": false,
+			},
+			"prompts": map[string]any{
+				"listChanged": false,
+			},
 		},
 		"serverInfo": map[string]any{
 			"name":    "memoh-tools-gateway",
@@ -70,12 +90,35 @@ func (s *ToolGatewayService) InitializeResult() map[string]any {
 	}
 }
 
+// SetToolAuthorizer enforces tool policies on listed and called tools.
+func (s *ToolGatewayService) SetToolAuthorizer(authorizer ToolAuthorizer) {
+	s.authorizer = authorizer
+}
+
+// SetToolCallRecorder writes every tool call to the audit log.
+func (s *ToolGatewayService) SetToolCallRecorder(recorder ToolCallRecorder) {
+	s.recorder = recorder
+}
+
 func (s *ToolGatewayService) ListTools(ctx context.Context, session ToolSessionContext) ([]ToolDescriptor, error) {
 	registry, err := s.getRegistry(ctx, session, false)
 	if err != nil {
 		return nil, err
 	}
-	return registry.List(), nil
+	tools := registry.List()
+	if session.Restricted() {
+		allowed := make([]ToolDescriptor, 0, len(tools))
+		for _, tool := range tools {
+			if session.AllowsTool(tool.Name) {
+				allowed = append(allowed, tool)
+			}
+		}
+		tools = allowed
+	}
+	if s.authorizer == nil {
+		return tools, nil
+	}
+	return s.authorizer.FilterTools(ctx, session, tools)
 }
 
 func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionContext, payload ToolCallPayload) (map[string]any, error) {
@@ -83,31 +126,79 @@ func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionCo
 	if toolName == "" {
 		return nil, fmt.Errorf("tool name is required")
 	}
+	arguments := payload.Arguments
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	call := ToolCallRecord{
+		Session:   session,
+		Tool:      toolName,
+		Source:    ToolCallSourceBuiltin,
+		Arguments: arguments,
+		StartedAt: time.Now(),
+	}
+	result, err := s.callTool(ctx, session, toolName, arguments, &call)
+	if s.recorder != nil {
+		call.Duration = time.Since(call.StartedAt)
+		switch {
+		case err != nil:
+			call.Status, call.Error = ToolCallStatusError, err.Error()
+		case call.Status == "":
+			call.Status = ToolCallStatusOK
+			if message, isErr := toolResultError(result); isErr {
+				call.Status, call.Error = ToolCallStatusError, message
+			}
+		}
+		s.recorder.RecordToolCall(ctx, call)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
+// callTool routes a call to the executor owning the tool and fills in the
+// origin and, for calls that never reached it, the status of the record.
+func (s *ToolGatewayService) callTool(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any, call *ToolCallRecord) (map[string]any, error) {
 	registry, err := s.getRegistry(ctx, session, false)
 	if err != nil {
 		return nil, err
 	}
-	executor, _, ok := registry.Lookup(toolName)
+	executor, tool, ok := registry.Lookup(toolName)
 	if !ok {
 		// Refresh once for dynamic executors/sources.
 		registry, err = s.getRegistry(ctx, session, true)
 		if err != nil {
 			return nil, err
 		}
-		executor, _, ok = registry.Lookup(toolName)
+		executor, tool, ok = registry.Lookup(toolName)
 		if !ok {
+			call.Status = ToolCallStatusNotFound
 			return BuildToolErrorResult("tool not found: " + toolName), nil
 		}
 	}
+	if resolver, ok := executor.(ToolOriginResolver); ok {
+		call.Source = ToolCallSourceFederation
+		if origin, found := resolver.ToolOrigin(session.BotID, toolName); found {
+			call.ConnectionID, call.ConnectionName = origin.ConnectionID, origin.ConnectionName
+		}
+	}
 
-	arguments := payload.Arguments
-	if arguments == nil {
-		arguments = map[string]any{}
+	if !session.AllowsTool(toolName) {
+		err := fmt.Errorf("%w: %s is not granted by the token scopes", ErrToolNotAllowed, toolName)
+		call.Status, call.Error = ToolCallStatusDenied, err.Error()
+		return BuildToolErrorResult(err.Error()), nil
+	}
+	if s.authorizer != nil {
+		if err := s.authorizer.AuthorizeCall(ctx, session, toolName, arguments); err != nil {
+			call.Status, call.Error = ToolCallStatusDenied, err.Error()
+			return BuildToolErrorResult(err.Error()), nil
+		}
 	}
-	result, err := executor.CallTool(ctx, session, toolName, arguments)
+	result, err := s.execute(ctx, session, executor, tool, arguments)
 	if err != nil {
 		if errors.Is(err, ErrToolNotFound) {
+			call.Status = ToolCallStatusNotFound
 			return BuildToolErrorResult("tool not found: " + toolName), nil
 		}
 		return BuildToolErrorResult(err.Error()), nil
@@ -118,6 +209,78 @@ func (s *ToolGatewayService) CallTool(ctx context.Context, session ToolSessionCo
 	return result, nil
 }
 
+// execute runs a tool within its call limits, serving cacheable tools from
+// the result cache and applying the invalidations of successful calls.
+func (s *ToolGatewayService) execute(ctx context.Context, session ToolSessionContext, executor ToolExecutor, tool ToolDescriptor, arguments map[string]any) (map[string]any, error) {
+	botID := strings.TrimSpace(session.BotID)
+	call := func() (map[string]any, error) {
+		return s.runLimited(ctx, botID, tool, func(ctx context.Context) (map[string]any, error) {
+			return executor.CallTool(ctx, session, tool.Name, arguments)
+		})
+	}
+	var (
+		result map[string]any
+		err    error
+	)
+	if tool.Cache != nil && tool.Cache.TTL > 0 {
+		var hit bool
+		result, hit, err = s.results.do(ctx, botID, tool, arguments, call)
+		if hit {
+			s.logger.Debug("tool result served from cache", slog.String("bot_id", botID), slog.String("tool", tool.Name))
+		}
+	} else {
+		result, err = call()
+	}
+	if err != nil || len(tool.Invalidates) == 0 {
+		return result, err
+	}
+	if _, isErr := toolResultError(result); !isErr {
+		for _, inv := range tool.Invalidates {
+			resource := ""
+			if inv.Resource != nil {
+				resource = inv.Resource(arguments)
+			}
+			s.results.invalidate(botID, inv.Tool, resource)
+		}
+	}
+	return result, nil
+}
+
+// InvalidateToolResults drops the cached tool results of a bot, e.g. after
+// its container was replaced.
+func (s *ToolGatewayService) InvalidateToolResults(botID string) {
+	s.results.invalidateBot(strings.TrimSpace(botID))
+}
+
+// CircuitStatus reports the circuit breaker state of an MCP connection from
+// the first source that guards it.
+func (s *ToolGatewayService) CircuitStatus(connectionID string) (CircuitStatus, bool) {
+	for _, source := range s.sources {
+		if reporter, ok := source.(CircuitReporter); ok {
+			if status, found := reporter.CircuitStatus(connectionID); found {
+				return status, true
+			}
+		}
+	}
+	return CircuitStatus{}, false
+}
+
+// Invalidate drops the cached tools, resources and prompts of a bot, in the
+// gateway and in every source that caches them.
+func (s *ToolGatewayService) Invalidate(botID string) {
+	botID = strings.TrimSpace(botID)
+	s.mu.Lock()
+	delete(s.cache, botID)
+	delete(s.resourceCache, botID)
+	delete(s.promptCache, botID)
+	s.mu.Unlock()
+	for _, source := range s.sources {
+		if invalidator, ok := source.(CacheInvalidator); ok {
+			invalidator.Invalidate(botID)
+		}
+	}
+}
+
 func (s *ToolGatewayService) getRegistry(ctx context.Context, session ToolSessionContext, force bool) (*ToolRegistry, error) {
 	botID := strings.TrimSpace(session.BotID)
 	if botID == "" {
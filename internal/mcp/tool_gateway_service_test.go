@@ -124,3 +124,137 @@ func TestToolGatewayServiceCallToolProviderError(t *testing.T) {
 		t.Fatalf("expected isError=true for provider failure")
 	}
 }
+
+func TestToolGatewayServiceAppliesToolPolicies(t *testing.T) {
+	provider := &gatewayTestProvider{
+		tools: []ToolDescriptor{
+			{Name: "exec", InputSchema: map[string]any{"type": "object"}},
+			{Name: "read", InputSchema: map[string]any{"type": "object"}},
+		},
+		callResult: map[string]map[string]any{
+			"exec": {"content": []any{}},
+		},
+	}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{provider}, nil)
+	service.SetToolAuthorizer(newTestToolPolicyService(nil, ToolPolicy{Tool: "exec", Enabled: false}))
+	session := ToolSessionContext{BotID: "bot-1"}
+
+	tools, err := service.ListTools(context.Background(), session)
+	if err != nil {
+		t.Fatalf("list tools failed: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "read" {
+		t.Fatalf("expected disabled tool to be hidden, got %v", tools)
+	}
+	result, err := service.CallTool(context.Background(), session, ToolCallPayload{Name: "exec"})
+	if err != nil {
+		t.Fatalf("call should return mcp error result instead of failing: %v", err)
+	}
+	if isErr, _ := result["isError"].(bool); !isErr {
+		t.Fatal("expected isError=true for disabled tool")
+	}
+}
+
+func TestToolGatewayServiceEnforcesSessionScopes(t *testing.T) {
+	provider := &gatewayTestProvider{
+		tools: []ToolDescriptor{
+			{Name: "search_memory", InputSchema: map[string]any{"type": "object"}},
+			{Name: "add_memory", InputSchema: map[string]any{"type": "object"}},
+			{Name: "exec", InputSchema: map[string]any{"type": "object"}},
+		},
+		callResult: map[string]map[string]any{
+			"search_memory": {"content": []any{}},
+			"add_memory":    {"content": []any{}},
+			"exec":          {"content": []any{}},
+		},
+	}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{provider}, nil)
+	ctx := context.Background()
+
+	// A bot with no policies still limits scoped tokens to their scopes.
+	session := ToolSessionContext{BotID: "bot-1", Scopes: []string{ScopeChat, ScopeMemoryRead}}
+	tools, err := service.ListTools(ctx, session)
+	if err != nil {
+		t.Fatalf("list tools failed: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "search_memory" {
+		t.Fatalf("expected only search_memory for a memory:read token, got %v", tools)
+	}
+	for name, allowed := range map[string]bool{"search_memory": true, "add_memory": false, "exec": false} {
+		result, err := service.CallTool(ctx, session, ToolCallPayload{Name: name})
+		if err != nil {
+			t.Fatalf("call %s failed: %v", name, err)
+		}
+		if isErr, _ := result["isError"].(bool); isErr == allowed {
+			t.Fatalf("call %s: expected allowed=%v, got %v", name, allowed, result)
+		}
+	}
+
+	// The chat scope alone grants no tools.
+	tools, err = service.ListTools(ctx, ToolSessionContext{BotID: "bot-1", Scopes: []string{ScopeChat}})
+	if err != nil {
+		t.Fatalf("list tools failed: %v", err)
+	}
+	if len(tools) != 0 {
+		t.Fatalf("expected no tools for a chat-only token, got %v", tools)
+	}
+}
+
+type gatewayTestRecorder struct {
+	calls []ToolCallRecord
+}
+
+func (r *gatewayTestRecorder) RecordToolCall(ctx context.Context, call ToolCallRecord) {
+	r.calls = append(r.calls, call)
+}
+
+func TestToolGatewayServiceRecordsToolCalls(t *testing.T) {
+	provider := &gatewayTestProvider{
+		tools: []ToolDescriptor{
+			{Name: "echo_tool", InputSchema: map[string]any{"type": "object"}},
+			{Name: "broken_tool", InputSchema: map[string]any{"type": "object"}},
+			{Name: "exec", InputSchema: map[string]any{"type": "object"}},
+		},
+		callResult: map[string]map[string]any{
+			"echo_tool": {"content": []any{}},
+		},
+		callErr: map[string]error{
+			"broken_tool": errors.New("boom"),
+		},
+	}
+	recorder := &gatewayTestRecorder{}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{provider}, nil)
+	service.SetToolAuthorizer(newTestToolPolicyService(nil, ToolPolicy{Tool: "exec", Enabled: false}))
+	service.SetToolCallRecorder(recorder)
+	session := ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "user-1"}
+
+	for _, name := range []string{"echo_tool", "broken_tool", "exec", "missing_tool"} {
+		if _, err := service.CallTool(context.Background(), session, ToolCallPayload{Name: name, Arguments: map[string]any{"q": name}}); err != nil {
+			t.Fatalf("call %s failed: %v", name, err)
+		}
+	}
+	want := []string{ToolCallStatusOK, ToolCallStatusError, ToolCallStatusDenied, ToolCallStatusNotFound}
+	if len(recorder.calls) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(recorder.calls))
+	}
+	for i, status := range want {
+		call := recorder.calls[i]
+		if call.Status != status {
+			t.Fatalf("record %d (%s): expected status %s, got %s", i, call.Tool, status, call.Status)
+		}
+		if call.Source != ToolCallSourceBuiltin || call.Session.ChannelIdentityID != "user-1" || call.Arguments["q"] != call.Tool {
+			t.Fatalf("unexpected record %+v", call)
+		}
+	}
+	if recorder.calls[1].Error != "boom" {
+		t.Fatalf("expected provider error to be recorded, got %q", recorder.calls[1].Error)
+	}
+
+	// Calls failing before they reach a tool are recorded too.
+	if _, err := service.CallTool(context.Background(), ToolSessionContext{BotID: " "}, ToolCallPayload{Name: "echo_tool"}); err == nil {
+		t.Fatal("expected call without bot to fail")
+	}
+	if last := recorder.calls[len(recorder.calls)-1]; len(recorder.calls) != len(want)+1 || last.Status != ToolCallStatusError || last.Error == "" {
+		t.Fatalf("expected failed call to be recorded, got %+v", recorder.calls)
+	}
+}
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+const (
+	defaultToolCallTimeout        = 60 * time.Second
+	defaultMaxConcurrentToolCalls = 8
+)
+
+// ToolCallLimits bounds how long a tool call may run and how many calls a
+// bot runs at once.
+type ToolCallLimits struct {
+	// Timeout applies to tools that declare no timeout of their own.
+	Timeout time.Duration
+	// ToolTimeouts overrides the timeout of single tools by name, including
+	// timeouts the tools declare.
+	ToolTimeouts map[string]time.Duration
+	// MaxConcurrentPerBot caps the calls of one bot in flight; further calls
+	// wait for a free slot until their timeout.
+	MaxConcurrentPerBot int
+}
+
+// NewToolCallLimits reads the limits from the MCP config. Unset values use
+// the defaults.
+func NewToolCallLimits(cfg config.MCPConfig) ToolCallLimits {
+	limits := ToolCallLimits{
+		Timeout:             time.Duration(cfg.ToolTimeoutSeconds) * time.Second,
+		MaxConcurrentPerBot: cfg.MaxConcurrentToolCalls,
+	}
+	for name, seconds := range cfg.ToolTimeouts {
+		name = strings.TrimSpace(name)
+		if name == "" || seconds <= 0 {
+			continue
+		}
+		if limits.ToolTimeouts == nil {
+			limits.ToolTimeouts = map[string]time.Duration{}
+		}
+		limits.ToolTimeouts[name] = time.Duration(seconds) * time.Second
+	}
+	return limits.withDefaults()
+}
+
+func (l ToolCallLimits) withDefaults() ToolCallLimits {
+	if l.Timeout <= 0 {
+		l.Timeout = defaultToolCallTimeout
+	}
+	if l.MaxConcurrentPerBot <= 0 {
+		l.MaxConcurrentPerBot = defaultMaxConcurrentToolCalls
+	}
+	return l
+}
+
+// timeout returns the timeout of a call to the tool: a configured override,
+// then the tool's own timeout, then the default.
+func (l ToolCallLimits) timeout(tool ToolDescriptor) time.Duration {
+	if timeout, ok := l.ToolTimeouts[tool.Name]; ok {
+		return timeout
+	}
+	if tool.Timeout > 0 {
+		return tool.Timeout
+	}
+	return l.Timeout
+}
+
+// SetCallLimits replaces the timeouts and the per-bot concurrency limit of
+// tool calls.
+func (s *ToolGatewayService) SetCallLimits(limits ToolCallLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits.withDefaults()
+	s.slots = map[string]chan struct{}{}
+}
+
+// runLimited runs a call within the tool's timeout after taking one of the
+// bot's call slots. It returns when the timeout expires even if the tool
+// ignores the cancelled context, but the slot is only freed once the call
+// returns, so tools that hang cannot pile up beyond the bot's limit.
+func (s *ToolGatewayService) runLimited(ctx context.Context, botID string, tool ToolDescriptor, call func(context.Context) (map[string]any, error)) (map[string]any, error) {
+	s.mu.Lock()
+	timeout := s.limits.timeout(tool)
+	slot, ok := s.slots[botID]
+	if !ok {
+		slot = make(chan struct{}, s.limits.MaxConcurrentPerBot)
+		s.slots[botID] = slot
+	}
+	s.mu.Unlock()
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case slot <- struct{}{}:
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("too many concurrent tool calls: %s did not start within %s", tool.Name, timeout)
+	}
+
+	type outcome struct {
+		result map[string]any
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() { <-slot }()
+		result, err := call(callCtx)
+		done <- outcome{result: result, err: err}
+	}()
+	select {
+	case out := <-done:
+		if out.err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, toolTimeoutError(tool.Name, timeout)
+		}
+		return out.result, out.err
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, toolTimeoutError(tool.Name, timeout)
+	}
+}
+
+func toolTimeoutError(toolName string, timeout time.Duration) error {
+	return fmt.Errorf("tool %s timed out after %s: %w", toolName, timeout, context.DeadlineExceeded)
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+type blockingTestExecutor struct {
+	release chan struct{}
+	started chan struct{}
+}
+
+func (e *blockingTestExecutor) ListTools(ctx context.Context, session ToolSessionContext) ([]ToolDescriptor, error) {
+	return []ToolDescriptor{
+		{Name: "hang"},
+		{Name: "slow", Timeout: time.Minute},
+	}, nil
+}
+
+func (e *blockingTestExecutor) CallTool(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
+	if e.started != nil {
+		e.started <- struct{}{}
+	}
+	// Ignore ctx like a hung server would.
+	<-e.release
+	return BuildToolSuccessResult(map[string]any{"ok": true}), nil
+}
+
+func TestNewToolCallLimits(t *testing.T) {
+	limits := NewToolCallLimits(config.MCPConfig{ToolTimeouts: map[string]int{"exec": 600, "bad": 0}})
+	if limits.Timeout != defaultToolCallTimeout || limits.MaxConcurrentPerBot != defaultMaxConcurrentToolCalls {
+		t.Fatalf("expected defaults, got %+v", limits)
+	}
+	if got := limits.timeout(ToolDescriptor{Name: "exec", Timeout: time.Minute}); got != 10*time.Minute {
+		t.Fatalf("expected configured override to win, got %s", got)
+	}
+	if got := limits.timeout(ToolDescriptor{Name: "slow", Timeout: time.Minute}); got != time.Minute {
+		t.Fatalf("expected the tool's timeout, got %s", got)
+	}
+	if got := limits.timeout(ToolDescriptor{Name: "bad"}); got != defaultToolCallTimeout {
+		t.Fatalf("expected the default timeout, got %s", got)
+	}
+}
+
+func TestToolGatewayTimesOutHungTools(t *testing.T) {
+	executor := &blockingTestExecutor{release: make(chan struct{})}
+	defer close(executor.release)
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+	service.SetCallLimits(ToolCallLimits{Timeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	result := callTestTool(t, service, "bot-1", "hang", nil)
+	if message, isErr := toolResultError(result); !isErr {
+		t.Fatalf("expected timeout error result, got %v", result)
+	} else if message != "tool hang timed out after 20ms: context deadline exceeded" {
+		t.Fatalf("unexpected error: %s", message)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected call to return at its timeout, took %s", elapsed)
+	}
+}
+
+func TestToolGatewayLimitsConcurrentCallsPerBot(t *testing.T) {
+	executor := &blockingTestExecutor{release: make(chan struct{}), started: make(chan struct{}, 4)}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+	service.SetCallLimits(ToolCallLimits{Timeout: 50 * time.Millisecond, ToolTimeouts: map[string]time.Duration{"slow": time.Second}, MaxConcurrentPerBot: 1})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		callTestTool(t, service, "bot-1", "slow", nil)
+	}()
+	<-executor.started
+
+	result := callTestTool(t, service, "bot-1", "hang", nil)
+	if message, isErr := toolResultError(result); !isErr || message != "too many concurrent tool calls: hang did not start within 50ms" {
+		t.Fatalf("expected concurrency limit error, got %v", result)
+	}
+	close(executor.release)
+	wg.Wait()
+	// Other bots have slots of their own.
+	callTestTool(t, service, "bot-2", "hang", nil)
+}
+
+func TestToolGatewayKeepsSlotsOfAbandonedCalls(t *testing.T) {
+	executor := &blockingTestExecutor{release: make(chan struct{})}
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+	service.SetCallLimits(ToolCallLimits{Timeout: 20 * time.Millisecond, MaxConcurrentPerBot: 1})
+
+	result := callTestTool(t, service, "bot-1", "hang", nil)
+	if _, isErr := toolResultError(result); !isErr {
+		t.Fatalf("expected timeout error result, got %v", result)
+	}
+	// The timed out call still runs and holds the bot's only slot.
+	result = callTestTool(t, service, "bot-1", "hang", nil)
+	if message, isErr := toolResultError(result); !isErr || message != "too many concurrent tool calls: hang did not start within 20ms" {
+		t.Fatalf("expected concurrency limit error, got %v", result)
+	}
+
+	close(executor.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		result = callTestTool(t, service, "bot-1", "hang", nil)
+		if _, isErr := toolResultError(result); !isErr {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the slot to be freed once the call returned, got %v", result)
+		}
+	}
+}
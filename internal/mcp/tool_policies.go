@@ -0,0 +1,567 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// Conversation kinds accepted in ToolPolicy.ConversationTypes besides the
+// raw platform conversation types.
+const (
+	ConversationKindDirect = "direct"
+	ConversationKindGroup  = "group"
+)
+
+// Member roles accepted in ToolPolicy.Roles. Callers that are neither the
+// owner nor a member of the bot are guests.
+const (
+	ToolRoleOwner  = "owner"
+	ToolRoleAdmin  = "admin"
+	ToolRoleMember = "member"
+	ToolRoleGuest  = "guest"
+)
+
+const toolPolicyCacheTTL = 5 * time.Second
+
+// ErrToolNotAllowed is returned when a tool policy rejects a call.
+var ErrToolNotAllowed = errors.New("tool not allowed")
+
+// ToolPolicy restricts one tool of a bot, or every tool matching a pattern
+// such as "mcp_github_*". Only the most specific matching policy applies: an
+// exact name wins over patterns, and a longer pattern over a shorter one.
+type ToolPolicy struct {
+	ID      string `json:"id"`
+	BotID   string `json:"bot_id"`
+	Tool    string `json:"tool"`
+	Enabled bool   `json:"enabled"`
+	// ConversationTypes limits the tool to these conversation types. "direct"
+	// and "group" match the respective types of every platform. Empty allows
+	// all conversations; otherwise calls of unknown type are denied.
+	ConversationTypes []string `json:"conversation_types"`
+	// Roles limits the tool to callers with these roles in the bot. Empty
+	// allows everyone with access to the bot.
+	Roles []string `json:"roles"`
+	// Arguments constrains argument values by argument name.
+	Arguments map[string]ArgumentRule `json:"arguments"`
+	CreatedAt time.Time               `json:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// ArgumentRule lists the values an argument may take. Values are matched as
+// path patterns; a pattern ending in "/**" allows a whole directory tree.
+// Array arguments must match for every element.
+type ArgumentRule struct {
+	Allowed  []string `json:"allowed"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// ToolPolicyUpsertRequest creates or replaces the policy of a tool.
+type ToolPolicyUpsertRequest struct {
+	Tool              string                  `json:"tool"`
+	Enabled           *bool                   `json:"enabled,omitempty"`
+	ConversationTypes []string                `json:"conversation_types,omitempty"`
+	Roles             []string                `json:"roles,omitempty"`
+	Arguments         map[string]ArgumentRule `json:"arguments,omitempty"`
+}
+
+// ToolPolicyListResponse wraps tool policy list responses.
+type ToolPolicyListResponse struct {
+	Items []ToolPolicy `json:"items"`
+}
+
+// MemberRoleResolver resolves the role a user holds in a bot.
+type MemberRoleResolver interface {
+	MemberRole(ctx context.Context, botID, userID string) (string, error)
+}
+
+type cachedToolPolicies struct {
+	expiresAt time.Time
+	items     []ToolPolicy
+}
+
+// ToolPolicyService stores tool policies and enforces them for the tool
+// gateway.
+type ToolPolicyService struct {
+	queries *sqlc.Queries
+	logger  *slog.Logger
+	roles   MemberRoleResolver
+
+	mu    sync.Mutex
+	cache map[string]cachedToolPolicies
+}
+
+// NewToolPolicyService creates a ToolPolicyService backed by sqlc queries.
+func NewToolPolicyService(log *slog.Logger, queries *sqlc.Queries) *ToolPolicyService {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &ToolPolicyService{
+		queries: queries,
+		logger:  log.With(slog.String("service", "tool_policies")),
+		cache:   map[string]cachedToolPolicies{},
+	}
+}
+
+// SetRoleResolver enables role restrictions. Without a resolver every caller
+// is treated as a guest.
+func (s *ToolPolicyService) SetRoleResolver(resolver MemberRoleResolver) {
+	s.roles = resolver
+}
+
+// List returns the tool policies of a bot.
+func (s *ToolPolicyService) List(ctx context.Context, botID string) ([]ToolPolicy, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("tool policy queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBotToolPolicies(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ToolPolicy, 0, len(rows))
+	for _, row := range rows {
+		item, err := normalizeToolPolicy(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Upsert creates or replaces the policy of a tool or tool pattern.
+func (s *ToolPolicyService) Upsert(ctx context.Context, botID string, req ToolPolicyUpsertRequest) (ToolPolicy, error) {
+	if s.queries == nil {
+		return ToolPolicy{}, fmt.Errorf("tool policy queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return ToolPolicy{}, err
+	}
+	policy, err := validateToolPolicy(req)
+	if err != nil {
+		return ToolPolicy{}, err
+	}
+	arguments, err := json.Marshal(policy.Arguments)
+	if err != nil {
+		return ToolPolicy{}, err
+	}
+	row, err := s.queries.UpsertBotToolPolicy(ctx, sqlc.UpsertBotToolPolicyParams{
+		BotID:             pgBotID,
+		Tool:              policy.Tool,
+		Enabled:           policy.Enabled,
+		ConversationTypes: policy.ConversationTypes,
+		Roles:             policy.Roles,
+		Arguments:         arguments,
+	})
+	if err != nil {
+		return ToolPolicy{}, err
+	}
+	s.invalidate(botID)
+	return normalizeToolPolicy(row)
+}
+
+// Delete removes a tool policy.
+func (s *ToolPolicyService) Delete(ctx context.Context, botID, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("tool policy queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.queries.DeleteBotToolPolicy(ctx, sqlc.DeleteBotToolPolicyParams{
+		ID:    pgID,
+		BotID: pgBotID,
+	}); err != nil {
+		return err
+	}
+	s.invalidate(botID)
+	return nil
+}
+
+// FilterTools drops the tools the session may not use and narrows the input
+// schemas of the others to their allowed argument values.
+func (s *ToolPolicyService) FilterTools(ctx context.Context, session ToolSessionContext, tools []ToolDescriptor) ([]ToolDescriptor, error) {
+	policies, err := s.policies(ctx, session.BotID)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return tools, nil
+	}
+	subject := s.subject(ctx, session)
+	out := make([]ToolDescriptor, 0, len(tools))
+	for _, tool := range tools {
+		policy, ok := matchToolPolicy(policies, tool.Name)
+		if !ok {
+			out = append(out, tool)
+			continue
+		}
+		if policy.permits(subject) != nil {
+			continue
+		}
+		out = append(out, policy.constrain(tool))
+	}
+	return out, nil
+}
+
+// AuthorizeCall returns an error wrapping ErrToolNotAllowed when the session
+// may not call the tool with these arguments.
+func (s *ToolPolicyService) AuthorizeCall(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any) error {
+	policies, err := s.policies(ctx, session.BotID)
+	if err != nil {
+		return err
+	}
+	policy, ok := matchToolPolicy(policies, toolName)
+	if !ok {
+		return nil
+	}
+	if err := policy.permits(s.subject(ctx, session)); err != nil {
+		return fmt.Errorf("%w: %s %s", ErrToolNotAllowed, toolName, err.Error())
+	}
+	if err := policy.checkArguments(arguments); err != nil {
+		return fmt.Errorf("%w: %s", ErrToolNotAllowed, err.Error())
+	}
+	return nil
+}
+
+func (s *ToolPolicyService) policies(ctx context.Context, botID string) ([]ToolPolicy, error) {
+	botID = strings.TrimSpace(botID)
+	s.mu.Lock()
+	cached, ok := s.cache[botID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.items, nil
+	}
+	items, err := s.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache[botID] = cachedToolPolicies{expiresAt: time.Now().Add(toolPolicyCacheTTL), items: items}
+	s.mu.Unlock()
+	return items, nil
+}
+
+func (s *ToolPolicyService) invalidate(botID string) {
+	s.mu.Lock()
+	delete(s.cache, strings.TrimSpace(botID))
+	s.mu.Unlock()
+}
+
+// subject resolves the caller's role lazily, as most policies do not
+// restrict roles. Restricted sessions act as guests whatever role the user
+// behind the token holds.
+func (s *ToolPolicyService) subject(ctx context.Context, session ToolSessionContext) *toolPolicySubject {
+	return &toolPolicySubject{
+		conversationType: session.ConversationType,
+		resolveRole: func() string {
+			userID := strings.TrimSpace(session.ChannelIdentityID)
+			if s.roles == nil || userID == "" || session.Restricted() {
+				return ToolRoleGuest
+			}
+			role, err := s.roles.MemberRole(ctx, session.BotID, userID)
+			if err != nil {
+				s.logger.Warn("resolve member role failed", slog.String("bot_id", session.BotID), slog.Any("error", err))
+				return ToolRoleGuest
+			}
+			return role
+		},
+	}
+}
+
+type toolPolicySubject struct {
+	conversationType string
+	resolveRole      func() string
+	role             string
+}
+
+func (s *toolPolicySubject) memberRole() string {
+	if s.role == "" {
+		s.role = s.resolveRole()
+	}
+	return s.role
+}
+
+// matchToolPolicy returns the most specific policy matching a tool name.
+func matchToolPolicy(policies []ToolPolicy, toolName string) (ToolPolicy, bool) {
+	var (
+		best      ToolPolicy
+		bestScore = -1
+	)
+	for _, policy := range policies {
+		score := -1
+		if policy.Tool == toolName {
+			score = 1 << 16
+		} else if ok, _ := path.Match(policy.Tool, toolName); ok {
+			score = len(policy.Tool)
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+func (p ToolPolicy) permits(subject *toolPolicySubject) error {
+	if !p.Enabled {
+		return errors.New("is disabled")
+	}
+	if len(p.ConversationTypes) > 0 {
+		conversationType := strings.ToLower(strings.TrimSpace(subject.conversationType))
+		// The type comes from the caller; without it the restriction cannot
+		// be checked, so it fails closed.
+		if conversationType == "" {
+			return errors.New("is not available when the conversation type is unknown")
+		}
+		if !slices.Contains(p.ConversationTypes, conversationType) && !slices.Contains(p.ConversationTypes, conversationKind(conversationType)) {
+			return fmt.Errorf("is not available in %s conversations", conversationKind(conversationType))
+		}
+	}
+	if len(p.Roles) > 0 && !slices.Contains(p.Roles, subject.memberRole()) {
+		return fmt.Errorf("is not available to %s", subject.memberRole())
+	}
+	return nil
+}
+
+func (p ToolPolicy) checkArguments(arguments map[string]any) error {
+	names := make([]string, 0, len(p.Arguments))
+	for name := range p.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule := p.Arguments[name]
+		value, ok := arguments[name]
+		if !ok || value == nil {
+			if rule.Required {
+				return fmt.Errorf("argument %s is required", name)
+			}
+			continue
+		}
+		values := []any{value}
+		if items, isList := value.([]any); isList {
+			values = items
+		}
+		for _, item := range values {
+			text := argumentText(item)
+			if !argumentAllowed(rule.Allowed, text) {
+				return fmt.Errorf("argument %s value %q is not allowed", name, text)
+			}
+		}
+	}
+	return nil
+}
+
+// constrain narrows the input schema to the allowed argument values: literal
+// lists become enums, patterns are spelled out in the description.
+func (p ToolPolicy) constrain(tool ToolDescriptor) ToolDescriptor {
+	if len(p.Arguments) == 0 {
+		return tool
+	}
+	schema := make(map[string]any, len(tool.InputSchema))
+	for key, value := range tool.InputSchema {
+		schema[key] = value
+	}
+	properties := map[string]any{}
+	if raw, ok := schema["properties"].(map[string]any); ok {
+		for key, value := range raw {
+			properties[key] = value
+		}
+	}
+	var required []string
+	switch raw := schema["required"].(type) {
+	case []string:
+		required = slices.Clone(raw)
+	case []any:
+		for _, item := range raw {
+			if name, ok := item.(string); ok {
+				required = append(required, name)
+			}
+		}
+	}
+	for name, rule := range p.Arguments {
+		property := map[string]any{}
+		if raw, ok := properties[name].(map[string]any); ok {
+			for key, value := range raw {
+				property[key] = value
+			}
+		}
+		if allLiteral(rule.Allowed) && property["type"] == "string" {
+			property["enum"] = slices.Clone(rule.Allowed)
+		} else {
+			note := "Allowed values: " + strings.Join(rule.Allowed, ", ")
+			if description, _ := property["description"].(string); description != "" {
+				note = description + ". " + note
+			}
+			property["description"] = note
+		}
+		properties[name] = property
+		if rule.Required && !slices.Contains(required, name) {
+			required = append(required, name)
+		}
+	}
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	tool.InputSchema = schema
+	return tool
+}
+
+func argumentText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		payload, _ := json.Marshal(v)
+		return string(payload)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func argumentAllowed(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if root, ok := strings.CutSuffix(pattern, "/**"); ok {
+			clean := path.Clean(value)
+			if clean == root || strings.HasPrefix(clean, strings.TrimSuffix(root, "/")+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func allLiteral(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, `*?[\`) {
+			return false
+		}
+	}
+	return len(patterns) > 0
+}
+
+// conversationKind maps platform conversation types onto direct or group.
+func conversationKind(conversationType string) string {
+	switch strings.ToLower(strings.TrimSpace(conversationType)) {
+	case "p2p", "private", "direct", "dm":
+		return ConversationKindDirect
+	default:
+		return ConversationKindGroup
+	}
+}
+
+func validateToolPolicy(req ToolPolicyUpsertRequest) (ToolPolicy, error) {
+	tool := strings.TrimSpace(req.Tool)
+	if tool == "" {
+		return ToolPolicy{}, fmt.Errorf("tool is required")
+	}
+	if _, err := path.Match(tool, ""); err != nil {
+		return ToolPolicy{}, fmt.Errorf("invalid tool pattern: %w", err)
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	conversationTypes := normalizeTokens(req.ConversationTypes)
+	roles := normalizeTokens(req.Roles)
+	for _, role := range roles {
+		switch role {
+		case ToolRoleOwner, ToolRoleAdmin, ToolRoleMember, ToolRoleGuest:
+		default:
+			return ToolPolicy{}, fmt.Errorf("invalid role: %s", role)
+		}
+	}
+	arguments := map[string]ArgumentRule{}
+	for name, rule := range req.Arguments {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return ToolPolicy{}, fmt.Errorf("argument name is required")
+		}
+		allowed := make([]string, 0, len(rule.Allowed))
+		for _, pattern := range rule.Allowed {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				return ToolPolicy{}, fmt.Errorf("invalid pattern for argument %s: %w", name, err)
+			}
+			allowed = append(allowed, pattern)
+		}
+		if len(allowed) == 0 {
+			return ToolPolicy{}, fmt.Errorf("argument %s needs at least one allowed value", name)
+		}
+		arguments[name] = ArgumentRule{Allowed: allowed, Required: rule.Required}
+	}
+	return ToolPolicy{
+		Tool:              tool,
+		Enabled:           enabled,
+		ConversationTypes: conversationTypes,
+		Roles:             roles,
+		Arguments:         arguments,
+	}, nil
+}
+
+func normalizeTokens(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" && !slices.Contains(out, value) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func normalizeToolPolicy(row sqlc.BotToolPolicy) (ToolPolicy, error) {
+	arguments := map[string]ArgumentRule{}
+	if len(row.Arguments) > 0 {
+		if err := json.Unmarshal(row.Arguments, &arguments); err != nil {
+			return ToolPolicy{}, err
+		}
+	}
+	return ToolPolicy{
+		ID:                row.ID.String(),
+		BotID:             row.BotID.String(),
+		Tool:              row.Tool,
+		Enabled:           row.Enabled,
+		ConversationTypes: nonNilStrings(row.ConversationTypes),
+		Roles:             nonNilStrings(row.Roles),
+		Arguments:         arguments,
+		CreatedAt:         db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:         db.TimeFromPg(row.UpdatedAt),
+	}, nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
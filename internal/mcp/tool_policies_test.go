@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeRoleResolver struct {
+	roles map[string]string
+	calls int
+}
+
+func (f *fakeRoleResolver) MemberRole(ctx context.Context, botID, userID string) (string, error) {
+	f.calls++
+	if role, ok := f.roles[userID]; ok {
+		return role, nil
+	}
+	return ToolRoleGuest, nil
+}
+
+func newTestToolPolicyService(resolver MemberRoleResolver, policies ...ToolPolicy) *ToolPolicyService {
+	svc := NewToolPolicyService(slog.Default(), nil)
+	svc.SetRoleResolver(resolver)
+	svc.cache["bot-1"] = cachedToolPolicies{expiresAt: time.Now().Add(time.Hour), items: policies}
+	return svc
+}
+
+func TestMatchToolPolicyPrefersMostSpecific(t *testing.T) {
+	policies := []ToolPolicy{
+		{Tool: "*", Enabled: false},
+		{Tool: "mcp_github_*", Enabled: true},
+		{Tool: "mcp_github_delete_repo", Enabled: false},
+	}
+	cases := map[string]string{
+		"mcp_github_delete_repo": "mcp_github_delete_repo",
+		"mcp_github_list_issues": "mcp_github_*",
+		"read":                   "*",
+	}
+	for name, want := range cases {
+		policy, ok := matchToolPolicy(policies, name)
+		if !ok || policy.Tool != want {
+			t.Fatalf("expected %s to match %s, got %q", name, want, policy.Tool)
+		}
+	}
+	if _, ok := matchToolPolicy(policies[1:], "read"); ok {
+		t.Fatal("expected no policy for read")
+	}
+}
+
+func TestToolPolicyServiceFilterTools(t *testing.T) {
+	resolver := &fakeRoleResolver{roles: map[string]string{"owner-1": ToolRoleOwner}}
+	svc := newTestToolPolicyService(resolver,
+		ToolPolicy{Tool: "exec", Enabled: true, Roles: []string{ToolRoleOwner}},
+		ToolPolicy{Tool: "send", Enabled: true, ConversationTypes: []string{ConversationKindDirect}},
+		ToolPolicy{Tool: "web_*", Enabled: false},
+		ToolPolicy{Tool: "read", Enabled: true, Arguments: map[string]ArgumentRule{
+			"path": {Allowed: []string{"/data/**"}, Required: true},
+		}},
+	)
+	tools := []ToolDescriptor{
+		{Name: "exec"},
+		{Name: "send"},
+		{Name: "web_search"},
+		{Name: "read", InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": map[string]any{"type": "string"}},
+		}},
+		{Name: "list"},
+	}
+	names := func(items []ToolDescriptor) []string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.Name)
+		}
+		return out
+	}
+
+	filtered, err := svc.FilterTools(context.Background(), ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "owner-1", ConversationType: "p2p"}, tools)
+	if err != nil {
+		t.Fatalf("filter tools failed: %v", err)
+	}
+	if got := names(filtered); !slices.Equal(got, []string{"exec", "send", "read", "list"}) {
+		t.Fatalf("unexpected tools for owner in direct chat: %v", got)
+	}
+	schema := filtered[2].InputSchema
+	property := schema["properties"].(map[string]any)["path"].(map[string]any)
+	if property["description"] != "Allowed values: /data/**" {
+		t.Fatalf("expected allowed values in description, got %v", property["description"])
+	}
+	if required, _ := schema["required"].([]string); !slices.Equal(required, []string{"path"}) {
+		t.Fatalf("expected path to be required, got %v", schema["required"])
+	}
+	if _, ok := tools[3].InputSchema["required"]; ok {
+		t.Fatal("expected original schema to be left untouched")
+	}
+
+	filtered, err = svc.FilterTools(context.Background(), ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "user-2", ConversationType: "group"}, tools)
+	if err != nil {
+		t.Fatalf("filter tools failed: %v", err)
+	}
+	if got := names(filtered); !slices.Equal(got, []string{"read", "list"}) {
+		t.Fatalf("unexpected tools for guest in group chat: %v", got)
+	}
+
+	filtered, err = svc.FilterTools(context.Background(), ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "owner-1"}, tools)
+	if err != nil {
+		t.Fatalf("filter tools failed: %v", err)
+	}
+	if got := names(filtered); !slices.Equal(got, []string{"exec", "read", "list"}) {
+		t.Fatalf("expected conversation restricted tools to be hidden without a type: %v", got)
+	}
+}
+
+func TestToolPolicyServiceAuthorizeCall(t *testing.T) {
+	resolver := &fakeRoleResolver{roles: map[string]string{"member-1": ToolRoleMember}}
+	svc := newTestToolPolicyService(resolver,
+		ToolPolicy{Tool: "exec", Enabled: true, Roles: []string{ToolRoleOwner, ToolRoleMember}},
+		ToolPolicy{Tool: "read", Enabled: true, Arguments: map[string]ArgumentRule{
+			"path": {Allowed: []string{"/data/**", "/tmp/*.txt"}},
+		}},
+		ToolPolicy{Tool: "send", Enabled: true, Arguments: map[string]ArgumentRule{
+			"platform": {Allowed: []string{"telegram"}, Required: true},
+		}},
+	)
+	ctx := context.Background()
+	member := ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "member-1"}
+	guest := ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "user-2"}
+
+	if err := svc.AuthorizeCall(ctx, member, "exec", nil); err != nil {
+		t.Fatalf("expected member to call exec: %v", err)
+	}
+	if err := svc.AuthorizeCall(ctx, guest, "exec", nil); !errors.Is(err, ErrToolNotAllowed) {
+		t.Fatalf("expected guest to be rejected, got %v", err)
+	}
+	if err := svc.AuthorizeCall(ctx, guest, "list", nil); err != nil {
+		t.Fatalf("expected unrestricted tool to pass: %v", err)
+	}
+	if resolver.calls != 2 {
+		t.Fatalf("expected roles to be resolved only for role restricted tools, got %d lookups", resolver.calls)
+	}
+	restricted := ToolSessionContext{BotID: "bot-1", ChannelIdentityID: "member-1", Scopes: []string{"chat"}}
+	if err := svc.AuthorizeCall(ctx, restricted, "exec", nil); !errors.Is(err, ErrToolNotAllowed) {
+		t.Fatalf("expected restricted session to act as guest, got %v", err)
+	}
+
+	allowed := []map[string]any{
+		{"path": "/data"},
+		{"path": "/data/notes/a.md"},
+		{"path": "/tmp/out.txt"},
+		{},
+	}
+	for _, arguments := range allowed {
+		if err := svc.AuthorizeCall(ctx, guest, "read", arguments); err != nil {
+			t.Fatalf("expected %v to be allowed: %v", arguments, err)
+		}
+	}
+	denied := []map[string]any{
+		{"path": "/database"},
+		{"path": "/data/../etc/passwd"},
+		{"path": "/tmp/sub/out.txt"},
+		{"path": []any{"/data/a", "/etc/b"}},
+	}
+	for _, arguments := range denied {
+		if err := svc.AuthorizeCall(ctx, guest, "read", arguments); !errors.Is(err, ErrToolNotAllowed) {
+			t.Fatalf("expected %v to be rejected, got %v", arguments, err)
+		}
+	}
+	if err := svc.AuthorizeCall(ctx, guest, "send", map[string]any{}); !errors.Is(err, ErrToolNotAllowed) {
+		t.Fatalf("expected missing required argument to be rejected, got %v", err)
+	}
+}
+
+func TestValidateToolPolicy(t *testing.T) {
+	disabled := false
+	policy, err := validateToolPolicy(ToolPolicyUpsertRequest{
+		Tool:              " exec ",
+		Enabled:           &disabled,
+		ConversationTypes: []string{"Group", "group"},
+		Roles:             []string{"Owner"},
+	})
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if policy.Tool != "exec" || policy.Enabled || !slices.Equal(policy.ConversationTypes, []string{"group"}) || !slices.Equal(policy.Roles, []string{"owner"}) {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+	invalid := []ToolPolicyUpsertRequest{
+		{},
+		{Tool: "[exec"},
+		{Tool: "exec", Roles: []string{"superuser"}},
+		{Tool: "read", Arguments: map[string]ArgumentRule{"path": {}}},
+	}
+	for _, req := range invalid {
+		if _, err := validateToolPolicy(req); err == nil {
+			t.Fatalf("expected %+v to be rejected", req)
+		}
+	}
+}
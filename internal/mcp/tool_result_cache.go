@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultToolResultCacheEntries = 1000
+
+type cachedToolResult struct {
+	botID     string
+	tool      string
+	resource  string
+	result    map[string]any
+	expiresAt time.Time
+}
+
+// pendingToolCall is a call in flight that identical calls wait for instead
+// of running the tool again.
+type pendingToolCall struct {
+	done   chan struct{}
+	result map[string]any
+	err    error
+}
+
+// toolResultCache keeps results of cacheable tools per bot and arguments
+// and deduplicates identical concurrent calls.
+type toolResultCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cachedToolResult
+	pending map[string]*pendingToolCall
+	// epochs counts invalidations per bot so a call that was in flight while
+	// its bot's results were invalidated does not store a stale result.
+	epochs map[string]uint64
+}
+
+func newToolResultCache(maxEntries int) *toolResultCache {
+	return &toolResultCache{
+		maxEntries: maxEntries,
+		entries:    map[string]cachedToolResult{},
+		pending:    map[string]*pendingToolCall{},
+		epochs:     map[string]uint64{},
+	}
+}
+
+// do returns the cached result of the call or runs it. Results that are not
+// tool errors are kept for the tool's TTL. hit reports whether the tool was
+// skipped. Callers waiting for a call whose own context ended run the call
+// again instead of sharing its cancellation.
+func (c *toolResultCache) do(ctx context.Context, botID string, tool ToolDescriptor, arguments map[string]any, call func() (map[string]any, error)) (map[string]any, bool, error) {
+	key, ok := toolResultCacheKey(botID, tool.Name, arguments)
+	if !ok {
+		result, err := call()
+		return result, false, err
+	}
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			if time.Now().Before(entry.expiresAt) {
+				c.mu.Unlock()
+				return maps.Clone(entry.result), true, nil
+			}
+			delete(c.entries, key)
+		}
+		if pending, ok := c.pending[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-pending.done:
+				if isContextError(pending.err) && ctx.Err() == nil {
+					continue
+				}
+				return maps.Clone(pending.result), true, pending.err
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+		}
+		pending := &pendingToolCall{done: make(chan struct{})}
+		c.pending[key] = pending
+		epoch := c.epochs[botID]
+		c.mu.Unlock()
+
+		pending.result, pending.err = call()
+
+		c.mu.Lock()
+		delete(c.pending, key)
+		if _, isErr := toolResultError(pending.result); pending.err == nil && !isErr && c.epochs[botID] == epoch {
+			resource := ""
+			if tool.Cache.Resource != nil {
+				resource = tool.Cache.Resource(arguments)
+			}
+			c.storeLocked(key, cachedToolResult{
+				botID:     botID,
+				tool:      tool.Name,
+				resource:  resource,
+				result:    pending.result,
+				expiresAt: time.Now().Add(tool.Cache.TTL),
+			})
+		}
+		c.mu.Unlock()
+		close(pending.done)
+		return maps.Clone(pending.result), false, pending.err
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// invalidate drops the cached results of a bot's tool. A non-empty resource
+// limits it to results read from that resource.
+func (c *toolResultCache) invalidate(botID, tool, resource string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs[botID]++
+	for key, entry := range c.entries {
+		if entry.botID != botID || entry.tool != tool {
+			continue
+		}
+		if resource != "" && entry.resource != resource {
+			continue
+		}
+		delete(c.entries, key)
+	}
+}
+
+// invalidateBot drops every cached result of a bot.
+func (c *toolResultCache) invalidateBot(botID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs[botID]++
+	for key, entry := range c.entries {
+		if entry.botID == botID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *toolResultCache) storeLocked(key string, entry cachedToolResult) {
+	if len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		for k, existing := range c.entries {
+			if !now.Before(existing.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		// Evict the entry closest to expiry.
+		var oldestKey string
+		var oldest time.Time
+		for k, existing := range c.entries {
+			if oldestKey == "" || existing.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, existing.expiresAt
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = entry
+}
+
+// toolResultCacheKey identifies a call by bot, tool and normalized
+// arguments: string values are trimmed and null values dropped. Map keys
+// are sorted by the JSON encoding.
+func toolResultCacheKey(botID, toolName string, arguments map[string]any) (string, bool) {
+	normalized := make(map[string]any, len(arguments))
+	for key, value := range arguments {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			normalized[key] = strings.TrimSpace(v)
+		default:
+			normalized[key] = v
+		}
+	}
+	payload, err := json.Marshal(normalized)
+	if err != nil {
+		return "", false
+	}
+	return botID + "\x00" + toolName + "\x00" + string(payload), true
+}
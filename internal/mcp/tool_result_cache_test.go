@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cachingTestExecutor struct {
+	calls   map[string]*atomic.Int32
+	release chan struct{}
+}
+
+func newCachingTestExecutor() *cachingTestExecutor {
+	return &cachingTestExecutor{calls: map[string]*atomic.Int32{
+		"read":  {},
+		"write": {},
+		"exec":  {},
+		"fail":  {},
+	}}
+}
+
+func pathArg(arguments map[string]any) string {
+	return StringArg(arguments, "path")
+}
+
+func (e *cachingTestExecutor) ListTools(ctx context.Context, session ToolSessionContext) ([]ToolDescriptor, error) {
+	return []ToolDescriptor{
+		{Name: "read", Cache: &ToolCachePolicy{TTL: time.Minute, Resource: pathArg}},
+		{Name: "fail", Cache: &ToolCachePolicy{TTL: time.Minute}},
+		{Name: "write", Invalidates: []ToolInvalidation{{Tool: "read", Resource: pathArg}}},
+		{Name: "exec", Invalidates: []ToolInvalidation{{Tool: "read"}}},
+	}, nil
+}
+
+func (e *cachingTestExecutor) CallTool(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
+	n := e.calls[toolName].Add(1)
+	if e.release != nil && toolName == "read" {
+		<-e.release
+	}
+	if toolName == "fail" {
+		return BuildToolErrorResult("boom"), nil
+	}
+	return BuildToolSuccessResult(map[string]any{"call": n, "path": pathArg(arguments)}), nil
+}
+
+func callTestTool(t *testing.T, service *ToolGatewayService, botID, tool string, arguments map[string]any) map[string]any {
+	t.Helper()
+	result, err := service.CallTool(context.Background(), ToolSessionContext{BotID: botID}, ToolCallPayload{Name: tool, Arguments: arguments})
+	if err != nil {
+		t.Fatalf("call %s: %v", tool, err)
+	}
+	return result
+}
+
+func TestToolResultCacheReusesResults(t *testing.T) {
+	executor := newCachingTestExecutor()
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "a.txt"})
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": " a.txt ", "unused": nil})
+	if got := executor.calls["read"].Load(); got != 1 {
+		t.Fatalf("expected normalized arguments to hit the cache, got %d calls", got)
+	}
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "b.txt"})
+	callTestTool(t, service, "bot-2", "read", map[string]any{"path": "a.txt"})
+	if got := executor.calls["read"].Load(); got != 3 {
+		t.Fatalf("expected other arguments and bots to miss the cache, got %d calls", got)
+	}
+
+	callTestTool(t, service, "bot-1", "fail", nil)
+	callTestTool(t, service, "bot-1", "fail", nil)
+	if got := executor.calls["fail"].Load(); got != 2 {
+		t.Fatalf("expected error results not to be cached, got %d calls", got)
+	}
+}
+
+func TestToolResultCacheInvalidation(t *testing.T) {
+	executor := newCachingTestExecutor()
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "a.txt"})
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "b.txt"})
+	callTestTool(t, service, "bot-1", "write", map[string]any{"path": "a.txt"})
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "a.txt"})
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "b.txt"})
+	if got := executor.calls["read"].Load(); got != 3 {
+		t.Fatalf("expected write to drop only the written path, got %d reads", got)
+	}
+
+	callTestTool(t, service, "bot-1", "exec", nil)
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "b.txt"})
+	if got := executor.calls["read"].Load(); got != 4 {
+		t.Fatalf("expected exec to drop every read, got %d reads", got)
+	}
+
+	service.InvalidateToolResults("bot-1")
+	callTestTool(t, service, "bot-1", "read", map[string]any{"path": "b.txt"})
+	if got := executor.calls["read"].Load(); got != 5 {
+		t.Fatalf("expected bot invalidation to drop results, got %d reads", got)
+	}
+}
+
+func TestToolResultCacheDeduplicatesConcurrentCalls(t *testing.T) {
+	executor := newCachingTestExecutor()
+	executor.release = make(chan struct{})
+	service := NewToolGatewayService(slog.Default(), []ToolExecutor{executor}, nil)
+	// Warm the tool registry so every caller reaches the result cache.
+	if _, err := service.ListTools(context.Background(), ToolSessionContext{BotID: "bot-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			callTestTool(t, service, "bot-1", "read", map[string]any{"path": "a.txt"})
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for executor.calls["read"].Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(executor.release)
+	wg.Wait()
+	if got := executor.calls["read"].Load(); got != 1 {
+		t.Fatalf("expected concurrent identical calls to share one execution, got %d", got)
+	}
+}
+
+func TestToolResultCacheRetriesAfterCancelledLeader(t *testing.T) {
+	cache := newToolResultCache(defaultToolResultCacheEntries)
+	tool := ToolDescriptor{Name: "read", Cache: &ToolCachePolicy{TTL: time.Minute}}
+	arguments := map[string]any{"path": "a.txt"}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, _, err := cache.do(leaderCtx, "bot-1", tool, arguments, func() (map[string]any, error) {
+			close(started)
+			<-leaderCtx.Done()
+			return nil, leaderCtx.Err()
+		})
+		leaderDone <- err
+	}()
+	<-started
+
+	var calls atomic.Int32
+	type outcome struct {
+		hit bool
+		err error
+	}
+	waiterDone := make(chan outcome, 1)
+	go func() {
+		_, hit, err := cache.do(context.Background(), "bot-1", tool, arguments, func() (map[string]any, error) {
+			calls.Add(1)
+			return BuildToolSuccessResult(map[string]any{"ok": true}), nil
+		})
+		waiterDone <- outcome{hit: hit, err: err}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderDone; err == nil {
+		t.Fatal("expected the cancelled leader to fail")
+	}
+	got := <-waiterDone
+	if got.err != nil || got.hit {
+		t.Fatalf("expected the waiter to run the call itself, got hit=%v err=%v", got.hit, got.err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected one retried call, got %d", calls.Load())
+	}
+}
@@ -5,9 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
+	"time"
 )
 
+// Scopes of restricted sessions, such as those started with an MCP bot token.
+const (
+	ScopeChat         = "chat"
+	ScopeMemoryRead   = "memory:read"
+	ScopeMemoryWrite  = "memory:write"
+	ScopeScheduleRead = "schedule:read"
+	ScopeMessageSend  = "message:send"
+)
+
+// scopedTools are the only gateway tools a restricted session can use, with
+// the scope each requires. ScopeChat starts a chat turn and grants no tools.
+var scopedTools = map[string]string{
+	"search_memory": ScopeMemoryRead,
+	"add_memory":    ScopeMemoryWrite,
+	"list_schedule": ScopeScheduleRead,
+	"send":          ScopeMessageSend,
+}
+
 // ToolSessionContext carries request-scoped identity for tool execution.
 type ToolSessionContext struct {
 	BotID             string
@@ -16,13 +36,62 @@ type ToolSessionContext struct {
 	SessionToken      string
 	CurrentPlatform   string
 	ReplyTarget       string
+	ConversationType  string
+	// RouteID is the conversation route of the chat, read from the verified
+	// session token. It is empty outside of channel conversations.
+	RouteID string
+	// Scopes are the scopes of the token that started the session, e.g. an
+	// MCP bot token. Nil means the session is not restricted.
+	Scopes []string
+}
+
+// Restricted reports whether the session was started by a scoped token.
+// Tool policies treat restricted callers as guests.
+func (s ToolSessionContext) Restricted() bool {
+	return s.Scopes != nil
+}
+
+// AllowsTool reports whether the session may list and call a gateway tool.
+// Restricted sessions only get the tools their scopes grant.
+func (s ToolSessionContext) AllowsTool(name string) bool {
+	if !s.Restricted() {
+		return true
+	}
+	scope, ok := scopedTools[strings.TrimSpace(name)]
+	return ok && slices.Contains(s.Scopes, scope)
 }
 
 // ToolDescriptor is the MCP tools/list item shape used by the gateway.
+// Cache, Invalidates and Timeout are gateway metadata and are not listed to
+// clients.
 type ToolDescriptor struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description,omitempty"`
 	InputSchema map[string]any `json:"inputSchema"`
+	// Cache marks a read-only tool whose results the gateway may reuse.
+	Cache *ToolCachePolicy `json:"-"`
+	// Invalidates lists the cached results a successful call makes stale.
+	Invalidates []ToolInvalidation `json:"-"`
+	// Timeout bounds a call of the tool; zero uses the gateway default.
+	Timeout time.Duration `json:"-"`
+}
+
+// ToolCachePolicy declares how long results of a read-only tool stay valid.
+// Results are cached per bot and normalized arguments.
+type ToolCachePolicy struct {
+	TTL time.Duration
+	// Resource identifies what a call reads, such as a file path, so that
+	// calls changing it can drop the result. Nil results are only dropped by
+	// invalidations of the whole tool.
+	Resource func(arguments map[string]any) string
+}
+
+// ToolInvalidation drops cached results of Tool after a successful call.
+type ToolInvalidation struct {
+	Tool string
+	// Resource identifies what the call changed; only results read from the
+	// same resource are dropped. Nil drops every cached result of Tool.
+	Resource func(arguments map[string]any) string
 }
 
 // ToolExecutor represents business-facing tools (message/schedule/memory).
@@ -38,6 +107,58 @@ type ToolSource interface {
 	CallTool(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error)
 }
 
+// ToolAuthorizer decides which tools a session may list and call.
+type ToolAuthorizer interface {
+	FilterTools(ctx context.Context, session ToolSessionContext, tools []ToolDescriptor) ([]ToolDescriptor, error)
+	AuthorizeCall(ctx context.Context, session ToolSessionContext, toolName string, arguments map[string]any) error
+}
+
+// ToolCallRecorder stores finished tool calls in the audit log.
+type ToolCallRecorder interface {
+	RecordToolCall(ctx context.Context, call ToolCallRecord)
+}
+
+// ToolOrigin identifies the MCP connection that serves a federated tool.
+type ToolOrigin struct {
+	ConnectionID   string
+	ConnectionName string
+}
+
+// ToolOriginResolver is implemented by sources that can tell which MCP
+// connection serves a tool.
+type ToolOriginResolver interface {
+	ToolOrigin(botID, toolName string) (ToolOrigin, bool)
+}
+
+// CacheInvalidator is implemented by sources that cache what they list and
+// can drop a bot's entries when the upstream lists change.
+type CacheInvalidator interface {
+	Invalidate(botID string)
+}
+
+// Circuit breaker states of an MCP connection.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// CircuitStatus is the circuit breaker state of an MCP connection. Tools of
+// an open connection are hidden until RetryAt.
+type CircuitStatus struct {
+	State     string
+	Failures  int
+	LastError string
+	OpenedAt  time.Time
+	RetryAt   time.Time
+}
+
+// CircuitReporter is implemented by sources that guard MCP connections with
+// a circuit breaker.
+type CircuitReporter interface {
+	CircuitStatus(connectionID string) (CircuitStatus, bool)
+}
+
 // ToolCallPayload is the MCP tools/call params payload.
 type ToolCallPayload struct {
 	Name      string         `json:"name"`
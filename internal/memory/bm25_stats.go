@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// DBBM25StatsStore keeps BM25 corpus statistics in Postgres. Updates are
+// relative increments, so concurrent instances never overwrite each other.
+// Every change of a bot and language holds a shared advisory lock that a
+// warmup rebuild of the same corpus takes exclusively.
+type DBBM25StatsStore struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+// NewDBBM25StatsStore creates a Postgres-backed BM25 statistics store.
+func NewDBBM25StatsStore(pool *pgxpool.Pool, queries *sqlc.Queries) *DBBM25StatsStore {
+	return &DBBM25StatsStore{pool: pool, queries: queries}
+}
+
+// inTx runs fn in a transaction holding the advisory lock of botID and lang.
+func (d *DBBM25StatsStore) inTx(ctx context.Context, botID, lang string, exclusive bool, fn func(qtx *sqlc.Queries) error) error {
+	tx, err := d.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin bm25 stats tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	qtx := d.queries.WithTx(tx)
+	if exclusive {
+		err = qtx.LockBM25Corpus(ctx, sqlc.LockBM25CorpusParams{BotID: botID, Lang: lang})
+	} else {
+		err = qtx.LockBM25CorpusShared(ctx, sqlc.LockBM25CorpusSharedParams{BotID: botID, Lang: lang})
+	}
+	if err != nil {
+		return fmt.Errorf("lock bm25 stats: %w", err)
+	}
+	if err := fn(qtx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (d *DBBM25StatsStore) AddDocument(ctx context.Context, botID, lang string, terms []string, docLen int) (BM25Stats, error) {
+	var stats BM25Stats
+	err := d.inTx(ctx, botID, lang, false, func(qtx *sqlc.Queries) error {
+		var err error
+		stats, err = addBM25Document(ctx, qtx, botID, lang, terms, docLen)
+		return err
+	})
+	if err != nil {
+		return BM25Stats{}, err
+	}
+	return stats, nil
+}
+
+func addBM25Document(ctx context.Context, queries *sqlc.Queries, botID, lang string, terms []string, docLen int) (BM25Stats, error) {
+	corpus, err := queries.IncrementBM25Corpus(ctx, sqlc.IncrementBM25CorpusParams{
+		BotID:       botID,
+		Lang:        lang,
+		TotalDocLen: int64(docLen),
+	})
+	if err != nil {
+		return BM25Stats{}, err
+	}
+	stats := corpusStats(corpus)
+	if len(terms) == 0 {
+		return stats, nil
+	}
+	rows, err := queries.IncrementBM25Terms(ctx, sqlc.IncrementBM25TermsParams{
+		BotID: botID,
+		Lang:  lang,
+		Terms: terms,
+	})
+	if err != nil {
+		return BM25Stats{}, err
+	}
+	for _, row := range rows {
+		stats.DocFreq[row.Term] = int(row.DocFreq)
+	}
+	return stats, nil
+}
+
+func (d *DBBM25StatsStore) RemoveDocument(ctx context.Context, botID, lang string, terms []string, docLen int) error {
+	return d.inTx(ctx, botID, lang, false, func(qtx *sqlc.Queries) error {
+		return removeBM25Document(ctx, qtx, botID, lang, terms, docLen)
+	})
+}
+
+func removeBM25Document(ctx context.Context, queries *sqlc.Queries, botID, lang string, terms []string, docLen int) error {
+	if err := queries.DecrementBM25Corpus(ctx, sqlc.DecrementBM25CorpusParams{
+		BotID:       botID,
+		Lang:        lang,
+		TotalDocLen: int64(docLen),
+	}); err != nil {
+		return err
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	if err := queries.DecrementBM25Terms(ctx, sqlc.DecrementBM25TermsParams{
+		BotID: botID,
+		Lang:  lang,
+		Terms: terms,
+	}); err != nil {
+		return err
+	}
+	return queries.DeleteEmptyBM25Terms(ctx, sqlc.DeleteEmptyBM25TermsParams{
+		BotID: botID,
+		Lang:  lang,
+		Terms: terms,
+	})
+}
+
+func (d *DBBM25StatsStore) Stats(ctx context.Context, botID, lang string, terms []string) (BM25Stats, error) {
+	corpus, err := d.queries.GetBM25Corpus(ctx, sqlc.GetBM25CorpusParams{BotID: botID, Lang: lang})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BM25Stats{DocFreq: map[string]int{}}, nil
+		}
+		return BM25Stats{}, err
+	}
+	stats := corpusStats(corpus)
+	if len(terms) == 0 || stats.DocCount == 0 {
+		return stats, nil
+	}
+	rows, err := d.queries.ListBM25TermStats(ctx, sqlc.ListBM25TermStatsParams{
+		BotID: botID,
+		Lang:  lang,
+		Terms: terms,
+	})
+	if err != nil {
+		return BM25Stats{}, err
+	}
+	for _, row := range rows {
+		stats.DocFreq[row.Term] = int(row.DocFreq)
+	}
+	return stats, nil
+}
+
+func (d *DBBM25StatsStore) Warmed(ctx context.Context, botID, lang string) (bool, error) {
+	return d.queries.IsBM25CorpusWarmed(ctx, sqlc.IsBM25CorpusWarmedParams{BotID: botID, Lang: lang})
+}
+
+// ReplaceCorpus swaps the statistics of botID and lang for corpus in one
+// transaction. An instance that waited for another one's rebuild skips its own.
+func (d *DBBM25StatsStore) ReplaceCorpus(ctx context.Context, botID, lang string, corpus BM25Corpus) (bool, error) {
+	replaced := false
+	err := d.inTx(ctx, botID, lang, true, func(qtx *sqlc.Queries) error {
+		warmed, err := qtx.IsBM25CorpusWarmed(ctx, sqlc.IsBM25CorpusWarmedParams{BotID: botID, Lang: lang})
+		if err != nil || warmed {
+			return err
+		}
+		if err := qtx.ReplaceBM25Corpus(ctx, sqlc.ReplaceBM25CorpusParams{
+			BotID:       botID,
+			Lang:        lang,
+			DocCount:    int64(corpus.DocCount),
+			TotalDocLen: corpus.TotalDocLen,
+		}); err != nil {
+			return err
+		}
+		if err := qtx.DeleteBM25TermsByCorpus(ctx, sqlc.DeleteBM25TermsByCorpusParams{BotID: botID, Lang: lang}); err != nil {
+			return err
+		}
+		if len(corpus.DocFreq) > 0 {
+			terms := make([]string, 0, len(corpus.DocFreq))
+			for term := range corpus.DocFreq {
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+			docFreqs := make([]int32, 0, len(terms))
+			for _, term := range terms {
+				docFreqs = append(docFreqs, int32(corpus.DocFreq[term]))
+			}
+			if err := qtx.InsertBM25Terms(ctx, sqlc.InsertBM25TermsParams{
+				BotID:    botID,
+				Lang:     lang,
+				Terms:    terms,
+				DocFreqs: docFreqs,
+			}); err != nil {
+				return err
+			}
+		}
+		replaced = true
+		return qtx.MarkBM25CorpusWarmed(ctx, sqlc.MarkBM25CorpusWarmedParams{BotID: botID, Lang: lang})
+	})
+	if err != nil {
+		return false, err
+	}
+	return replaced, nil
+}
+
+func corpusStats(row sqlc.Bm25CorpusStat) BM25Stats {
+	stats := BM25Stats{
+		DocCount: int(row.DocCount),
+		DocFreq:  map[string]int{},
+	}
+	if row.DocCount > 0 {
+		stats.AvgDocLen = float64(row.TotalDocLen) / float64(row.DocCount)
+	}
+	return stats
+}
@@ -0,0 +1,610 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCompactionUndoWindow is how long the replaced memories of an
+	// applied compaction are retained for undo.
+	DefaultCompactionUndoWindow = time.Hour
+	compactionPreviewTTL        = 30 * time.Minute
+	// compactionLockTTL bounds how long an apply or undo holds a compaction
+	// before another instance may take it over.
+	compactionLockTTL = 5 * time.Minute
+)
+
+var (
+	// ErrCompactionNotFound indicates the compaction does not exist, has expired
+	// or belongs to another scope.
+	ErrCompactionNotFound = errors.New("compaction not found")
+	// ErrCompactionStale indicates memories changed after the preview was made.
+	ErrCompactionStale = errors.New("memories changed since the compaction preview")
+	// ErrCompactionState indicates the compaction cannot move to the requested state.
+	ErrCompactionState = errors.New("compaction is not in a valid state for this operation")
+)
+
+// CompactionStatus is the lifecycle state of a compaction.
+type CompactionStatus string
+
+const (
+	CompactionStatusPreview CompactionStatus = "preview"
+	CompactionStatusApplied CompactionStatus = "applied"
+	CompactionStatusUndone  CompactionStatus = "undone"
+)
+
+// CompactionMerge describes one new fact and the memories merged into it.
+type CompactionMerge struct {
+	Fact    string       `json:"fact"`
+	Sources []MemoryItem `json:"sources"`
+	Diff    string       `json:"diff"`
+}
+
+// CompactionPlan is a compaction preview. Applying it replaces every memory in
+// the plan with the merged facts; memories not referenced by any fact are listed
+// as dropped.
+type CompactionPlan struct {
+	ID            string            `json:"id"`
+	Status        CompactionStatus  `json:"status"`
+	BeforeCount   int               `json:"before_count"`
+	AfterCount    int               `json:"after_count"`
+	TargetCount   int               `json:"target_count"`
+	Ratio         float64           `json:"ratio"`
+	Merges        []CompactionMerge `json:"merges"`
+	Dropped       []MemoryItem      `json:"dropped"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	AppliedAt     *time.Time        `json:"applied_at,omitempty"`
+	UndoExpiresAt *time.Time        `json:"undo_expires_at,omitempty"`
+}
+
+// compaction is the server-side state behind a CompactionPlan.
+type compaction struct {
+	plan     CompactionPlan
+	scope    string
+	filters  map[string]any
+	original []qdrantPoint
+	created  []string
+}
+
+// SetCompactionUndoWindow configures how long applied compactions can be undone.
+func (s *Service) SetCompactionUndoWindow(window time.Duration) {
+	if window <= 0 {
+		window = DefaultCompactionUndoWindow
+	}
+	s.compactionUndoWindow = window
+}
+
+// Compact previews and immediately applies a compaction.
+func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio float64, decayDays int) (CompactResult, error) {
+	plan, err := s.PreviewCompaction(ctx, filters, ratio, decayDays)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	if len(plan.Merges) == 0 && len(plan.Dropped) == 0 {
+		items, err := s.listScope(ctx, filters)
+		if err != nil {
+			return CompactResult{}, err
+		}
+		return CompactResult{BeforeCount: plan.BeforeCount, AfterCount: plan.BeforeCount, Ratio: 1.0, Results: items}, nil
+	}
+	return s.ApplyCompaction(ctx, plan.ID, filters)
+}
+
+// PreviewCompaction asks the LLM to consolidate the memories in filters and
+// returns the proposed merges without changing anything.
+func (s *Service) PreviewCompaction(ctx context.Context, filters map[string]any, ratio float64, decayDays int) (CompactionPlan, error) {
+	if s.llm == nil {
+		return CompactionPlan{}, fmt.Errorf("llm not configured")
+	}
+	if s.store == nil {
+		return CompactionPlan{}, fmt.Errorf("qdrant store not configured")
+	}
+	if ratio <= 0 || ratio > 1 {
+		ratio = 0.5
+	}
+	ctx = WithBotID(ctx, resolveBotID("", filters))
+
+	points, err := s.store.List(ctx, 0, filters, false)
+	if err != nil {
+		return CompactionPlan{}, err
+	}
+	now := time.Now().UTC()
+	plan := CompactionPlan{
+		ID:          uuid.NewString(),
+		Status:      CompactionStatusPreview,
+		BeforeCount: len(points),
+		AfterCount:  len(points),
+		Ratio:       1.0,
+		Merges:      []CompactionMerge{},
+		Dropped:     []MemoryItem{},
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(compactionPreviewTTL),
+	}
+	if len(points) <= 1 {
+		// Nothing to compact.
+		return plan, nil
+	}
+
+	candidates := make([]CandidateMemory, 0, len(points))
+	for _, p := range points {
+		candidates = append(candidates, CandidateMemory{
+			ID:        p.ID,
+			Memory:    fmt.Sprint(p.Payload["data"]),
+			CreatedAt: fmt.Sprint(p.Payload["created_at"]),
+		})
+	}
+	targetCount := int(math.Round(float64(len(points)) * ratio))
+	if targetCount < 1 {
+		targetCount = 1
+	}
+	compactResp, err := s.llm.Compact(ctx, CompactRequest{
+		Memories:    candidates,
+		TargetCount: targetCount,
+		DecayDays:   decayDays,
+	})
+	if err != nil {
+		return CompactionPlan{}, fmt.Errorf("compact llm call failed: %w", err)
+	}
+	merges, dropped := buildCompactionMerges(points, compactResp.Facts)
+	if len(merges) == 0 {
+		return CompactionPlan{}, fmt.Errorf("compact returned no facts")
+	}
+	plan.TargetCount = targetCount
+	plan.Merges = merges
+	plan.Dropped = dropped
+	plan.AfterCount = len(merges)
+	plan.Ratio = math.Round(float64(len(merges))/float64(len(points))*100) / 100
+
+	if err := s.compactions.put(ctx, &compaction{
+		plan:     plan,
+		scope:    compactionScope(filters),
+		filters:  cloneFilters(filters),
+		original: points,
+	}); err != nil {
+		return CompactionPlan{}, fmt.Errorf("save compaction preview: %w", err)
+	}
+	return plan, nil
+}
+
+// GetCompaction returns a compaction preview or an applied compaction that can
+// still be undone.
+func (s *Service) GetCompaction(ctx context.Context, id string, filters map[string]any) (CompactionPlan, error) {
+	item, err := s.compactions.get(ctx, id, compactionScope(filters))
+	if err != nil {
+		return CompactionPlan{}, err
+	}
+	return item.plan, nil
+}
+
+// acquireCompaction locks the compaction for an apply or undo. A compaction
+// that another call is working on is reported as ErrCompactionState.
+func (s *Service) acquireCompaction(ctx context.Context, id string, filters map[string]any) (*compaction, func(), error) {
+	id = strings.TrimSpace(id)
+	scope := compactionScope(filters)
+	locked, err := s.compactions.lock(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !locked {
+		if _, err := s.compactions.get(ctx, id, scope); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrCompactionState
+	}
+	release := func() { s.compactions.unlock(context.WithoutCancel(ctx), id) }
+	item, err := s.compactions.get(ctx, id, scope)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return item, release, nil
+}
+
+// saveCompaction stores the new state after the memories were changed. The
+// change itself already happened, so a failure only costs the undo.
+func (s *Service) saveCompaction(ctx context.Context, item *compaction) {
+	if err := s.compactions.put(context.WithoutCancel(ctx), item); err != nil {
+		s.logger.Warn("save compaction failed",
+			slog.String("compaction_id", item.plan.ID),
+			slog.String("status", string(item.plan.Status)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// ApplyCompaction applies a preview. New facts are written first; the replaced
+// memories are deleted only after every write succeeded and are retained for
+// the undo window.
+func (s *Service) ApplyCompaction(ctx context.Context, id string, filters map[string]any) (CompactResult, error) {
+	if s.store == nil {
+		return CompactResult{}, fmt.Errorf("qdrant store not configured")
+	}
+	item, release, err := s.acquireCompaction(ctx, id, filters)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	defer release()
+	if item.plan.Status != CompactionStatusPreview {
+		return CompactResult{}, ErrCompactionState
+	}
+	ctx = WithBotID(ctx, resolveBotID("", item.filters))
+	if err := s.checkCompactionSources(ctx, item.original); err != nil {
+		return CompactResult{}, err
+	}
+	// The dense vectors are kept with the undo snapshot, so undo restores
+	// the memories as they were instead of sparse-only points.
+	if err := s.store.snapshotDenseVectors(ctx, item.original); err != nil {
+		return CompactResult{}, fmt.Errorf("compact snapshot failed: %w", err)
+	}
+
+	created, err := s.writeCompactedPoints(ctx, item.plan.Merges, item.filters)
+	if err != nil {
+		return CompactResult{}, fmt.Errorf("compact add failed: %w", err)
+	}
+	oldIDs := pointIDs(item.original)
+	if err := s.store.DeleteBatch(ctx, oldIDs); err != nil {
+		s.discardPoints(ctx, created)
+		return CompactResult{}, fmt.Errorf("compact delete old failed: %w", err)
+	}
+	s.removeBM25Documents(ctx, item.original)
+	s.recordCompactionHistory(ctx, item, created)
+
+	now := time.Now().UTC()
+	undoUntil := now.Add(s.undoWindow())
+	item.plan.Status = CompactionStatusApplied
+	item.plan.AppliedAt = &now
+	item.plan.UndoExpiresAt = &undoUntil
+	item.created = pointIDs(created)
+	s.saveCompaction(ctx, item)
+
+	results, err := s.listScope(ctx, item.filters)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	return CompactResult{
+		CompactionID:  item.plan.ID,
+		BeforeCount:   item.plan.BeforeCount,
+		AfterCount:    len(created),
+		Ratio:         item.plan.Ratio,
+		Results:       results,
+		UndoExpiresAt: &undoUntil,
+	}, nil
+}
+
+// UndoCompaction restores the memories replaced by an applied compaction and
+// removes the facts it created.
+func (s *Service) UndoCompaction(ctx context.Context, id string, filters map[string]any) (CompactResult, error) {
+	if s.store == nil {
+		return CompactResult{}, fmt.Errorf("qdrant store not configured")
+	}
+	item, release, err := s.acquireCompaction(ctx, id, filters)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	defer release()
+	if item.plan.Status != CompactionStatusApplied {
+		return CompactResult{}, ErrCompactionState
+	}
+	ctx = WithBotID(ctx, resolveBotID("", item.filters))
+
+	restored := make([]qdrantPoint, 0, len(item.original))
+	for _, original := range item.original {
+		point, err := s.indexPoint(ctx, original.ID, original.Payload)
+		if err != nil {
+			s.discardPoints(ctx, restored)
+			return CompactResult{}, fmt.Errorf("compact undo restore failed: %w", err)
+		}
+		restored = append(restored, withSnapshotVectors(point, original))
+	}
+	if err := s.store.Upsert(ctx, restored); err != nil {
+		s.removeBM25Documents(ctx, restored)
+		return CompactResult{}, fmt.Errorf("compact undo restore failed: %w", err)
+	}
+	created := make([]qdrantPoint, 0, len(item.created))
+	for _, createdID := range item.created {
+		existing, err := s.store.Get(ctx, createdID)
+		if err != nil {
+			return CompactResult{}, err
+		}
+		if existing != nil {
+			created = append(created, *existing)
+		}
+	}
+	if err := s.store.DeleteBatch(ctx, pointIDs(created)); err != nil {
+		return CompactResult{}, fmt.Errorf("compact undo delete failed: %w", err)
+	}
+	s.removeBM25Documents(ctx, created)
+	item.plan.Status = CompactionStatusUndone
+	s.saveCompaction(ctx, item)
+	undoMetadata := map[string]any{"compaction_id": item.plan.ID}
+	for _, p := range restored {
+		s.recordHistory(ctx, p.ID, HistoryEventRevert, "", fmt.Sprint(p.Payload["data"]), p.Payload, undoMetadata)
+	}
+	for _, p := range created {
+		s.recordHistory(ctx, p.ID, HistoryEventDelete, fmt.Sprint(p.Payload["data"]), "", p.Payload, undoMetadata)
+	}
+
+	results, err := s.listScope(ctx, item.filters)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	return CompactResult{
+		CompactionID: item.plan.ID,
+		BeforeCount:  len(created),
+		AfterCount:   len(restored),
+		Ratio:        1.0,
+		Results:      results,
+	}, nil
+}
+
+// recordCompactionHistory records a COMPACT entry for every merged, dropped
+// and created memory. created is in the order of the plan's merges.
+func (s *Service) recordCompactionHistory(ctx context.Context, item *compaction, created []qdrantPoint) {
+	if s.history == nil {
+		return
+	}
+	payloads := make(map[string]map[string]any, len(item.original))
+	for _, p := range item.original {
+		payloads[p.ID] = p.Payload
+	}
+	for i, merge := range item.plan.Merges {
+		if i >= len(created) {
+			break
+		}
+		newID := created[i].ID
+		sourceIDs := make([]string, 0, len(merge.Sources))
+		for _, source := range merge.Sources {
+			sourceIDs = append(sourceIDs, source.ID)
+			s.recordHistory(ctx, source.ID, HistoryEventCompact, source.Memory, merge.Fact, payloads[source.ID], map[string]any{
+				"compaction_id": item.plan.ID,
+				"replaced_by":   newID,
+			})
+		}
+		s.recordHistory(ctx, newID, HistoryEventCompact, "", merge.Fact, created[i].Payload, map[string]any{
+			"compaction_id": item.plan.ID,
+			"source_ids":    sourceIDs,
+		})
+	}
+	for _, dropped := range item.plan.Dropped {
+		s.recordHistory(ctx, dropped.ID, HistoryEventCompact, dropped.Memory, "", payloads[dropped.ID], map[string]any{
+			"compaction_id": item.plan.ID,
+		})
+	}
+}
+
+func (s *Service) undoWindow() time.Duration {
+	if s.compactionUndoWindow <= 0 {
+		return DefaultCompactionUndoWindow
+	}
+	return s.compactionUndoWindow
+}
+
+// checkCompactionSources fails when a memory of the plan was changed or removed
+// after the preview.
+func (s *Service) checkCompactionSources(ctx context.Context, points []qdrantPoint) error {
+	for _, point := range points {
+		current, err := s.store.Get(ctx, point.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil || fmt.Sprint(current.Payload["hash"]) != fmt.Sprint(point.Payload["hash"]) {
+			return ErrCompactionStale
+		}
+	}
+	return nil
+}
+
+// writeCompactedPoints indexes and upserts the merged facts in one batch. On
+// failure the BM25 statistics are rolled back and nothing is written.
+func (s *Service) writeCompactedPoints(ctx context.Context, merges []CompactionMerge, filters map[string]any) ([]qdrantPoint, error) {
+	points := make([]qdrantPoint, 0, len(merges))
+	for _, merge := range merges {
+		payload := buildPayload(merge.Fact, filters, nil, "")
+		if err := applyLifecycle(payload, mergedLifecycle(merge.Sources)); err != nil {
+			s.removeBM25Documents(ctx, points)
+			return nil, err
+		}
+		point, err := s.indexPoint(ctx, uuid.NewString(), payload)
+		if err != nil {
+			s.removeBM25Documents(ctx, points)
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	if err := s.store.Upsert(ctx, points); err != nil {
+		s.removeBM25Documents(ctx, points)
+		return nil, err
+	}
+	return points, nil
+}
+
+// indexPoint builds a sparse-indexed point for payload and adds it to the BM25
+// statistics.
+func (s *Service) indexPoint(ctx context.Context, id string, payload map[string]any) (qdrantPoint, error) {
+	if s.bm25 == nil {
+		return qdrantPoint{}, fmt.Errorf("bm25 indexer not configured")
+	}
+	text := fmt.Sprint(payload["data"])
+	lang, _ := payload["lang"].(string)
+	if strings.TrimSpace(lang) == "" {
+		detected, err := s.detectLanguage(ctx, text)
+		if err != nil {
+			return qdrantPoint{}, err
+		}
+		lang = detected
+	}
+	termFreq, docLen, err := s.bm25.TermFrequencies(lang, text)
+	if err != nil {
+		return qdrantPoint{}, err
+	}
+	indices, values, err := s.bm25.AddDocument(ctx, resolveBotID("", payload), lang, termFreq, docLen)
+	if err != nil {
+		return qdrantPoint{}, err
+	}
+	copied := make(map[string]any, len(payload)+1)
+	for key, value := range payload {
+		copied[key] = value
+	}
+	copied["lang"] = lang
+	return qdrantPoint{
+		ID:               id,
+		SparseIndices:    indices,
+		SparseValues:     values,
+		SparseVectorName: s.store.sparseVectorName,
+		Payload:          copied,
+	}, nil
+}
+
+// withSnapshotVectors adds the dense vectors snapshotted with original to a
+// re-indexed point.
+func withSnapshotVectors(point, original qdrantPoint) qdrantPoint {
+	point.Vector = original.Vector
+	point.VectorName = original.VectorName
+	point.DenseVectors = original.DenseVectors
+	return point
+}
+
+// discardPoints deletes points written by an operation that is being rolled back.
+func (s *Service) discardPoints(ctx context.Context, points []qdrantPoint) {
+	if len(points) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	if err := s.store.DeleteBatch(ctx, pointIDs(points)); err != nil {
+		s.logger.Warn("compaction rollback failed", slog.Int("points", len(points)), slog.Any("error", err))
+	}
+	s.removeBM25Documents(ctx, points)
+}
+
+func (s *Service) removeBM25Documents(ctx context.Context, points []qdrantPoint) {
+	for _, p := range points {
+		s.removeBM25Document(ctx, p.Payload)
+	}
+}
+
+func (s *Service) listScope(ctx context.Context, filters map[string]any) ([]MemoryItem, error) {
+	points, err := s.store.List(ctx, 0, filters, false)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]MemoryItem, 0, len(points))
+	for _, p := range points {
+		items = append(items, payloadToMemoryItem(p.ID, p.Payload))
+	}
+	return items, nil
+}
+
+// buildCompactionMerges maps the LLM facts back to the memories they replace.
+// Unknown source IDs are ignored; memories no fact refers to are dropped.
+func buildCompactionMerges(points []qdrantPoint, facts []CompactFact) ([]CompactionMerge, []MemoryItem) {
+	byID := make(map[string]MemoryItem, len(points))
+	for _, p := range points {
+		byID[p.ID] = payloadToMemoryItem(p.ID, p.Payload)
+	}
+	used := make(map[string]struct{}, len(points))
+	merges := make([]CompactionMerge, 0, len(facts))
+	for _, fact := range facts {
+		text := strings.TrimSpace(fact.Text)
+		if text == "" {
+			continue
+		}
+		sources := make([]MemoryItem, 0, len(fact.SourceIDs))
+		for _, sourceID := range fact.SourceIDs {
+			item, ok := byID[strings.TrimSpace(sourceID)]
+			if !ok {
+				continue
+			}
+			used[item.ID] = struct{}{}
+			sources = append(sources, item)
+		}
+		merges = append(merges, CompactionMerge{
+			Fact:    text,
+			Sources: sources,
+			Diff:    compactionDiff(sources, text),
+		})
+	}
+	dropped := make([]MemoryItem, 0)
+	for _, p := range points {
+		if _, ok := used[p.ID]; !ok {
+			dropped = append(dropped, byID[p.ID])
+		}
+	}
+	sort.SliceStable(dropped, func(i, j int) bool { return dropped[i].CreatedAt < dropped[j].CreatedAt })
+	return merges, dropped
+}
+
+// compactionDiff renders the change of one merge as removed and added lines.
+// Unchanged text is shown once without a marker.
+func compactionDiff(sources []MemoryItem, fact string) string {
+	var b strings.Builder
+	unchanged := false
+	for _, source := range sources {
+		if strings.TrimSpace(source.Memory) == fact {
+			unchanged = true
+			continue
+		}
+		b.WriteString("- ")
+		b.WriteString(source.Memory)
+		b.WriteString("\n")
+	}
+	if unchanged {
+		b.WriteString("  ")
+	} else {
+		b.WriteString("+ ")
+	}
+	b.WriteString(fact)
+	return b.String()
+}
+
+// mergedLifecycle keeps the highest importance of the merged memories; the
+// fact only expires if every source expires, at the latest expiry.
+func mergedLifecycle(sources []MemoryItem) memoryLifecycle {
+	var lifecycle memoryLifecycle
+	latestExpiry := ""
+	for i, source := range sources {
+		if source.Importance > 0 && (lifecycle.Importance == nil || source.Importance > *lifecycle.Importance) {
+			importance := source.Importance
+			lifecycle.Importance = &importance
+		}
+		if source.ExpiresAt == "" {
+			latestExpiry = ""
+			break
+		}
+		if i == 0 || source.ExpiresAt > latestExpiry {
+			latestExpiry = source.ExpiresAt
+		}
+	}
+	lifecycle.ExpiresAt = latestExpiry
+	return lifecycle
+}
+
+func compactionScope(filters map[string]any) string {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+fmt.Sprint(filters[key]))
+	}
+	return strings.Join(parts, "&")
+}
+
+func pointIDs(points []qdrantPoint) []string {
+	ids := make([]string, 0, len(points))
+	for _, p := range points {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
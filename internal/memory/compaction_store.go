@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// compactionStore keeps previews and applied compactions until they expire.
+// lock guards apply and undo, so one compaction is changed by one call at a
+// time.
+type compactionStore interface {
+	put(ctx context.Context, item *compaction) error
+	// get returns ErrCompactionNotFound when id is unknown, expired or
+	// belongs to another scope.
+	get(ctx context.Context, id, scope string) (*compaction, error)
+	// lock reports false when the compaction is unknown or already locked.
+	lock(ctx context.Context, id string) (bool, error)
+	unlock(ctx context.Context, id string)
+}
+
+// compactionExpiry is the time after which a compaction is no longer usable.
+func compactionExpiry(plan CompactionPlan) time.Time {
+	switch plan.Status {
+	case CompactionStatusPreview:
+		return plan.ExpiresAt
+	case CompactionStatusApplied:
+		if plan.UndoExpiresAt != nil {
+			return *plan.UndoExpiresAt
+		}
+	}
+	return time.Now().UTC()
+}
+
+// memoryCompactionStore keeps compactions in process. It is used when no
+// database is configured and only works for a single instance.
+type memoryCompactionStore struct {
+	mu     sync.Mutex
+	items  map[string]*compaction
+	locked map[string]struct{}
+}
+
+func newMemoryCompactionStore() *memoryCompactionStore {
+	return &memoryCompactionStore{
+		items:  map[string]*compaction{},
+		locked: map[string]struct{}{},
+	}
+}
+
+func (c *memoryCompactionStore) put(_ context.Context, item *compaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	copied := *item
+	c.items[item.plan.ID] = &copied
+	delete(c.locked, item.plan.ID)
+	return nil
+}
+
+func (c *memoryCompactionStore) get(_ context.Context, id, scope string) (*compaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	item, ok := c.items[strings.TrimSpace(id)]
+	if !ok || item.scope != scope {
+		return nil, ErrCompactionNotFound
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (c *memoryCompactionStore) lock(_ context.Context, id string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	if _, ok := c.items[id]; !ok {
+		return false, nil
+	}
+	if _, ok := c.locked[id]; ok {
+		return false, nil
+	}
+	c.locked[id] = struct{}{}
+	return true, nil
+}
+
+func (c *memoryCompactionStore) unlock(_ context.Context, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.locked, id)
+}
+
+func (c *memoryCompactionStore) pruneLocked(now time.Time) {
+	for id, item := range c.items {
+		if now.After(compactionExpiry(item.plan)) {
+			delete(c.items, id)
+			delete(c.locked, id)
+		}
+	}
+}
+
+// DBCompactionStore keeps compaction previews and undo snapshots in Postgres,
+// so they survive restarts and can be applied or undone on any instance.
+type DBCompactionStore struct {
+	queries *sqlc.Queries
+	logger  *slog.Logger
+}
+
+// NewDBCompactionStore creates a Postgres-backed compaction store.
+func NewDBCompactionStore(log *slog.Logger, queries *sqlc.Queries) *DBCompactionStore {
+	return &DBCompactionStore{
+		queries: queries,
+		logger:  log.With(slog.String("store", "memory_compactions")),
+	}
+}
+
+// SetCompactionStore replaces the in-process compaction store.
+func (s *Service) SetCompactionStore(store *DBCompactionStore) {
+	if store == nil {
+		return
+	}
+	s.compactions = store
+}
+
+func (d *DBCompactionStore) put(ctx context.Context, item *compaction) error {
+	if _, err := d.queries.DeleteExpiredMemoryCompactions(ctx); err != nil {
+		d.logger.Warn("prune memory compactions failed", slog.Any("error", err))
+	}
+	id, err := db.ParseUUID(item.plan.ID)
+	if err != nil {
+		return err
+	}
+	botID := pgtype.UUID{}
+	if parsed, err := db.ParseUUID(resolveBotID("", item.filters)); err == nil {
+		botID = parsed
+	}
+	plan, err := json.Marshal(item.plan)
+	if err != nil {
+		return err
+	}
+	filters, err := json.Marshal(item.filters)
+	if err != nil {
+		return err
+	}
+	original, err := json.Marshal(item.original)
+	if err != nil {
+		return err
+	}
+	created, err := json.Marshal(item.created)
+	if err != nil {
+		return err
+	}
+	return d.queries.UpsertMemoryCompaction(ctx, sqlc.UpsertMemoryCompactionParams{
+		ID:        id,
+		BotID:     botID,
+		Scope:     item.scope,
+		Status:    string(item.plan.Status),
+		Plan:      plan,
+		Filters:   filters,
+		Original:  original,
+		Created:   created,
+		ExpiresAt: pgtype.Timestamptz{Time: compactionExpiry(item.plan).UTC(), Valid: true},
+	})
+}
+
+func (d *DBCompactionStore) get(ctx context.Context, id, scope string) (*compaction, error) {
+	pgID, err := db.ParseUUID(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrCompactionNotFound
+	}
+	row, err := d.queries.GetMemoryCompaction(ctx, sqlc.GetMemoryCompactionParams{ID: pgID, Scope: scope})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompactionNotFound
+		}
+		return nil, err
+	}
+	item := &compaction{scope: row.Scope}
+	if err := json.Unmarshal(row.Plan, &item.plan); err != nil {
+		return nil, fmt.Errorf("decode compaction plan: %w", err)
+	}
+	if err := json.Unmarshal(row.Filters, &item.filters); err != nil {
+		return nil, fmt.Errorf("decode compaction filters: %w", err)
+	}
+	if err := json.Unmarshal(row.Original, &item.original); err != nil {
+		return nil, fmt.Errorf("decode compaction snapshot: %w", err)
+	}
+	if err := json.Unmarshal(row.Created, &item.created); err != nil {
+		return nil, fmt.Errorf("decode compaction results: %w", err)
+	}
+	return item, nil
+}
+
+func (d *DBCompactionStore) lock(ctx context.Context, id string) (bool, error) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return false, nil
+	}
+	rows, err := d.queries.LockMemoryCompaction(ctx, sqlc.LockMemoryCompactionParams{
+		LockedUntil: pgtype.Timestamptz{Time: time.Now().Add(compactionLockTTL).UTC(), Valid: true},
+		ID:          pgID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (d *DBCompactionStore) unlock(ctx context.Context, id string) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return
+	}
+	if err := d.queries.UnlockMemoryCompaction(ctx, pgID); err != nil {
+		d.logger.Warn("unlock memory compaction failed", slog.String("compaction_id", id), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompactFact_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var resp CompactResponse
+	raw := `{"facts":["plain fact",{"text":"merged fact","source_ids":["a","b"]}]}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(resp.Facts) != 2 {
+		t.Fatalf("expected 2 facts, got %d", len(resp.Facts))
+	}
+	if resp.Facts[0].Text != "plain fact" || len(resp.Facts[0].SourceIDs) != 0 {
+		t.Fatalf("unexpected plain fact: %+v", resp.Facts[0])
+	}
+	if resp.Facts[1].Text != "merged fact" || len(resp.Facts[1].SourceIDs) != 2 {
+		t.Fatalf("unexpected merged fact: %+v", resp.Facts[1])
+	}
+}
+
+func TestBuildCompactionMerges(t *testing.T) {
+	t.Parallel()
+
+	points := []qdrantPoint{
+		{ID: "a", Payload: map[string]any{"data": "User likes tea", "created_at": "2024-01-01T00:00:00Z"}},
+		{ID: "b", Payload: map[string]any{"data": "User drinks green tea", "created_at": "2024-01-02T00:00:00Z"}},
+		{ID: "c", Payload: map[string]any{"data": "User lives in Berlin", "created_at": "2024-01-03T00:00:00Z"}},
+		{ID: "d", Payload: map[string]any{"data": "Outdated note", "created_at": "2023-01-01T00:00:00Z"}},
+	}
+	facts := []CompactFact{
+		{Text: "User likes green tea", SourceIDs: []string{"a", "b", "unknown"}},
+		{Text: "User lives in Berlin", SourceIDs: []string{"c"}},
+		{Text: "   "},
+	}
+
+	merges, dropped := buildCompactionMerges(points, facts)
+	if len(merges) != 2 {
+		t.Fatalf("expected 2 merges, got %d", len(merges))
+	}
+	if len(merges[0].Sources) != 2 {
+		t.Fatalf("expected unknown source to be ignored: %+v", merges[0].Sources)
+	}
+	wantDiff := "- User likes tea\n- User drinks green tea\n+ User likes green tea"
+	if merges[0].Diff != wantDiff {
+		t.Fatalf("unexpected diff:\n%s", merges[0].Diff)
+	}
+	if merges[1].Diff != "  User lives in Berlin" {
+		t.Fatalf("expected unchanged fact diff, got %q", merges[1].Diff)
+	}
+	if len(dropped) != 1 || dropped[0].ID != "d" {
+		t.Fatalf("expected d to be dropped, got %+v", dropped)
+	}
+}
+
+func TestCompactionStoreScopeAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemoryCompactionStore()
+	filters := map[string]any{"namespace": "bot", "scopeId": "bot-1"}
+	now := time.Now().UTC()
+	_ = store.put(ctx, &compaction{
+		plan:  CompactionPlan{ID: "live", Status: CompactionStatusPreview, ExpiresAt: now.Add(time.Minute)},
+		scope: compactionScope(filters),
+	})
+	_ = store.put(ctx, &compaction{
+		plan:  CompactionPlan{ID: "expired", Status: CompactionStatusPreview, ExpiresAt: now.Add(-time.Minute)},
+		scope: compactionScope(filters),
+	})
+
+	if _, err := store.get(ctx, "live", compactionScope(filters)); err != nil {
+		t.Fatalf("expected live preview to be found, got %v", err)
+	}
+	if _, err := store.get(ctx, "live", compactionScope(map[string]any{"namespace": "bot", "scopeId": "bot-2"})); !errors.Is(err, ErrCompactionNotFound) {
+		t.Fatalf("expected preview to be hidden from other scopes, got %v", err)
+	}
+	if _, err := store.get(ctx, "expired", compactionScope(filters)); !errors.Is(err, ErrCompactionNotFound) {
+		t.Fatalf("expected expired preview to be pruned, got %v", err)
+	}
+}
+
+func TestCompactionStoreLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemoryCompactionStore()
+	item := &compaction{plan: CompactionPlan{ID: "c1", Status: CompactionStatusPreview, ExpiresAt: time.Now().Add(time.Minute)}}
+	_ = store.put(ctx, item)
+
+	if ok, _ := store.lock(ctx, "c1"); !ok {
+		t.Fatalf("expected first lock to succeed")
+	}
+	if ok, _ := store.lock(ctx, "c1"); ok {
+		t.Fatalf("expected second lock to fail while held")
+	}
+	if ok, _ := store.lock(ctx, "missing"); ok {
+		t.Fatalf("expected unknown compaction not to lock")
+	}
+	store.unlock(ctx, "c1")
+	if ok, _ := store.lock(ctx, "c1"); !ok {
+		t.Fatalf("expected lock after unlock to succeed")
+	}
+}
+
+func TestCompactionUndoKeepsDenseVectors(t *testing.T) {
+	t.Parallel()
+
+	// The undo snapshot is stored as JSON, so the vectors must survive it.
+	snapshot, err := json.Marshal([]qdrantPoint{
+		{
+			ID:           "m1",
+			Payload:      map[string]any{"data": "likes tea"},
+			DenseVectors: map[string][]float32{"text_model": {0.1, 0.2, 0.3}},
+		},
+		{
+			ID:      "m2",
+			Payload: map[string]any{"data": "lives in Oslo"},
+			Vector:  []float32{0.4, 0.5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+	var originals []qdrantPoint
+	if err := json.Unmarshal(snapshot, &originals); err != nil {
+		t.Fatalf("unmarshal snapshot: %v", err)
+	}
+
+	for _, original := range originals {
+		indexed := qdrantPoint{
+			ID:               original.ID,
+			SparseIndices:    []uint32{1},
+			SparseValues:     []float32{1},
+			SparseVectorName: sparseHashVectorName,
+			Payload:          original.Payload,
+		}
+		restored := withSnapshotVectors(indexed, original)
+		if len(restored.SparseIndices) != 1 {
+			t.Fatalf("expected %s to keep its sparse vector", original.ID)
+		}
+		switch original.ID {
+		case "m1":
+			if got := restored.DenseVectors["text_model"]; len(got) != 3 || got[2] != 0.3 {
+				t.Fatalf("expected m1 to keep its named dense vector, got %v", restored.DenseVectors)
+			}
+		case "m2":
+			if len(restored.Vector) != 2 || restored.Vector[1] != 0.5 {
+				t.Fatalf("expected m2 to keep its dense vector, got %v", restored.Vector)
+			}
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// History event types.
+const (
+	HistoryEventAdd     = "ADD"
+	HistoryEventUpdate  = "UPDATE"
+	HistoryEventDelete  = "DELETE"
+	HistoryEventCompact = "COMPACT"
+	HistoryEventRevert  = "REVERT"
+)
+
+// History actor types.
+const (
+	// HistoryActorSystem is used when no actor was attached to the context.
+	HistoryActorSystem = "system"
+	// HistoryActorUser is a user acting through the API.
+	HistoryActorUser = "user"
+	// HistoryActorChat is memory extraction triggered by a chat message.
+	HistoryActorChat = "chat"
+)
+
+const defaultHistoryLimit = 50
+
+// ErrHistoryNotFound indicates the history entry does not exist or belongs to
+// another memory.
+var ErrHistoryNotFound = errors.New("memory history entry not found")
+
+// HistoryActor identifies who or what caused a memory change.
+type HistoryActor struct {
+	Type string
+	// ID is the channel identity of the acting user.
+	ID string
+	// MessageID is the message that triggered the change, if any.
+	MessageID string
+}
+
+const memoryHistoryActorContextKey contextKey = "memory_history_actor"
+
+// WithHistoryActor attaches the actor recorded in memory history.
+func WithHistoryActor(ctx context.Context, actor HistoryActor) context.Context {
+	return context.WithValue(ctx, memoryHistoryActorContextKey, actor)
+}
+
+func historyActorFromContext(ctx context.Context) HistoryActor {
+	actor, _ := ctx.Value(memoryHistoryActorContextKey).(HistoryActor)
+	if strings.TrimSpace(actor.Type) == "" {
+		actor.Type = HistoryActorSystem
+	}
+	return actor
+}
+
+// HistoryEntry is one recorded change of a memory.
+type HistoryEntry struct {
+	ID        string         `json:"id"`
+	MemoryID  string         `json:"memory_id"`
+	BotID     string         `json:"bot_id,omitempty"`
+	Event     string         `json:"event"`
+	OldMemory string         `json:"old_memory,omitempty"`
+	NewMemory string         `json:"new_memory,omitempty"`
+	Scope     map[string]any `json:"scope,omitempty"`
+	ActorType string         `json:"actor_type"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	MessageID string         `json:"message_id,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// HistoryResponse lists the history of one memory, newest first.
+type HistoryResponse struct {
+	Items []HistoryEntry `json:"items"`
+}
+
+// HistoryStore persists memory history.
+type HistoryStore interface {
+	Record(ctx context.Context, entry HistoryEntry) (HistoryEntry, error)
+	// List returns the entries of memoryID recorded for botID, newest first.
+	List(ctx context.Context, botID, memoryID string, limit int) ([]HistoryEntry, error)
+	Get(ctx context.Context, id string) (HistoryEntry, error)
+}
+
+// DBHistoryStore stores memory history in Postgres.
+type DBHistoryStore struct {
+	queries *sqlc.Queries
+}
+
+// NewDBHistoryStore creates a Postgres-backed history store.
+func NewDBHistoryStore(queries *sqlc.Queries) *DBHistoryStore {
+	return &DBHistoryStore{queries: queries}
+}
+
+func (d *DBHistoryStore) Record(ctx context.Context, entry HistoryEntry) (HistoryEntry, error) {
+	botID := pgtype.UUID{}
+	if value := strings.TrimSpace(entry.BotID); value != "" {
+		if parsed, err := db.ParseUUID(value); err == nil {
+			botID = parsed
+		}
+	}
+	scope, err := marshalHistoryObject(entry.Scope)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	metadata, err := marshalHistoryObject(entry.Metadata)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	row, err := d.queries.InsertMemoryHistory(ctx, sqlc.InsertMemoryHistoryParams{
+		MemoryID:  entry.MemoryID,
+		BotID:     botID,
+		Event:     entry.Event,
+		OldMemory: entry.OldMemory,
+		NewMemory: entry.NewMemory,
+		Scope:     scope,
+		ActorType: entry.ActorType,
+		ActorID:   entry.ActorID,
+		MessageID: entry.MessageID,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	return toHistoryEntry(row), nil
+}
+
+func (d *DBHistoryStore) List(ctx context.Context, botID, memoryID string, limit int) ([]HistoryEntry, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return []HistoryEntry{}, nil
+	}
+	rows, err := d.queries.ListMemoryHistory(ctx, sqlc.ListMemoryHistoryParams{
+		BotID:    pgBotID,
+		MemoryID: memoryID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toHistoryEntry(row))
+	}
+	return items, nil
+}
+
+func (d *DBHistoryStore) Get(ctx context.Context, id string) (HistoryEntry, error) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return HistoryEntry{}, ErrHistoryNotFound
+	}
+	row, err := d.queries.GetMemoryHistoryByID(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return HistoryEntry{}, ErrHistoryNotFound
+		}
+		return HistoryEntry{}, err
+	}
+	return toHistoryEntry(row), nil
+}
+
+func toHistoryEntry(row sqlc.MemoryHistory) HistoryEntry {
+	entry := HistoryEntry{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		MemoryID:  row.MemoryID,
+		Event:     row.Event,
+		OldMemory: row.OldMemory,
+		NewMemory: row.NewMemory,
+		ActorType: row.ActorType,
+		ActorID:   row.ActorID,
+		MessageID: row.MessageID,
+		CreatedAt: db.TimeFromPg(row.CreatedAt),
+	}
+	if row.BotID.Valid {
+		entry.BotID = uuid.UUID(row.BotID.Bytes).String()
+	}
+	if len(row.Scope) > 0 {
+		_ = json.Unmarshal(row.Scope, &entry.Scope)
+	}
+	if len(row.Metadata) > 0 {
+		_ = json.Unmarshal(row.Metadata, &entry.Metadata)
+	}
+	return entry
+}
+
+func marshalHistoryObject(value map[string]any) ([]byte, error) {
+	if len(value) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(value)
+}
+
+// SetHistoryStore enables the memory audit trail.
+func (s *Service) SetHistoryStore(store HistoryStore) {
+	s.history = store
+}
+
+// recordHistory appends an entry for a memory change. History is an audit
+// trail, so failures are logged and never fail the change itself.
+func (s *Service) recordHistory(ctx context.Context, memoryID, event, oldText, newText string, payload map[string]any, metadata map[string]any) {
+	if s.history == nil || strings.TrimSpace(memoryID) == "" {
+		return
+	}
+	actor := historyActorFromContext(ctx)
+	botID := resolveBotID("", payload)
+	if botID == "" {
+		botID = BotIDFromContext(ctx)
+	}
+	entry := HistoryEntry{
+		MemoryID:  memoryID,
+		BotID:     botID,
+		Event:     event,
+		OldMemory: oldText,
+		NewMemory: newText,
+		Scope:     historyScope(payload),
+		ActorType: actor.Type,
+		ActorID:   actor.ID,
+		MessageID: actor.MessageID,
+		Metadata:  metadata,
+	}
+	if _, err := s.history.Record(context.WithoutCancel(ctx), entry); err != nil {
+		s.logger.Warn("record memory history failed",
+			slog.String("memory_id", memoryID),
+			slog.String("event", event),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// historyScope keeps the scope keys of a payload so a deleted memory can be
+// restored into the same scope.
+func historyScope(payload map[string]any) map[string]any {
+	scope := map[string]any{}
+	for _, key := range []string{"namespace", "scopeId", "bot_id", "agent_id", "run_id"} {
+		if value, ok := payload[key]; ok && value != nil && fmt.Sprint(value) != "" {
+			scope[key] = value
+		}
+	}
+	return scope
+}
+
+// History returns the changes of a memory of botID, newest first.
+func (s *Service) History(ctx context.Context, botID, memoryID string, limit int) (HistoryResponse, error) {
+	if s.history == nil {
+		return HistoryResponse{}, fmt.Errorf("memory history not configured")
+	}
+	memoryID = strings.TrimSpace(memoryID)
+	if memoryID == "" {
+		return HistoryResponse{}, fmt.Errorf("memory_id is required")
+	}
+	items, err := s.history.List(ctx, botID, memoryID, limit)
+	if err != nil {
+		return HistoryResponse{}, err
+	}
+	if items == nil {
+		items = []HistoryEntry{}
+	}
+	return HistoryResponse{Items: items}, nil
+}
+
+// RevertMemory restores a memory of botID to the version recorded by a history
+// entry (see historyRevertText). A deleted memory is re-created with its
+// original ID and scope.
+func (s *Service) RevertMemory(ctx context.Context, botID, memoryID, historyID string) (MemoryItem, error) {
+	if s.history == nil {
+		return MemoryItem{}, fmt.Errorf("memory history not configured")
+	}
+	entry, err := s.history.Get(ctx, strings.TrimSpace(historyID))
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	if entry.MemoryID != strings.TrimSpace(memoryID) || entry.BotID != botID {
+		return MemoryItem{}, ErrHistoryNotFound
+	}
+	text := historyRevertText(entry)
+	if strings.TrimSpace(text) == "" {
+		return MemoryItem{}, fmt.Errorf("history entry has no memory text to restore")
+	}
+	ctx = WithBotID(ctx, botID)
+
+	existing, err := s.store.Get(ctx, entry.MemoryID)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	if existing != nil {
+		return s.update(ctx, UpdateRequest{MemoryID: entry.MemoryID, Memory: text}, HistoryEventRevert, map[string]any{"history_id": entry.ID})
+	}
+	item, err := s.RebuildAdd(ctx, entry.MemoryID, text, entry.Scope)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	s.recordHistory(ctx, entry.MemoryID, HistoryEventRevert, "", text, entry.Scope, map[string]any{"history_id": entry.ID})
+	return item, nil
+}
+
+// historyRevertText returns the version an entry points at: the text right
+// after the change, or the removed text when the change deleted the memory
+// (DELETE, or COMPACT of a memory merged into another).
+func historyRevertText(entry HistoryEntry) string {
+	switch entry.Event {
+	case HistoryEventDelete:
+		return entry.OldMemory
+	case HistoryEventCompact:
+		if strings.TrimSpace(entry.OldMemory) != "" {
+			return entry.OldMemory
+		}
+	}
+	if strings.TrimSpace(entry.NewMemory) != "" {
+		return entry.NewMemory
+	}
+	return entry.OldMemory
+}
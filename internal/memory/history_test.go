@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+type fakeHistoryStore struct {
+	entries []HistoryEntry
+}
+
+func (f *fakeHistoryStore) Record(_ context.Context, entry HistoryEntry) (HistoryEntry, error) {
+	f.entries = append(f.entries, entry)
+	return entry, nil
+}
+
+func (f *fakeHistoryStore) List(_ context.Context, botID, memoryID string, limit int) ([]HistoryEntry, error) {
+	var items []HistoryEntry
+	for i := len(f.entries) - 1; i >= 0 && (limit <= 0 || len(items) < limit); i-- {
+		if f.entries[i].BotID == botID && f.entries[i].MemoryID == memoryID {
+			items = append(items, f.entries[i])
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeHistoryStore) Get(_ context.Context, id string) (HistoryEntry, error) {
+	for _, entry := range f.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return HistoryEntry{}, ErrHistoryNotFound
+}
+
+func TestRecordHistory(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeHistoryStore{}
+	s := &Service{history: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	payload := map[string]any{"data": "new", "namespace": "bot", "scopeId": "bot-1", "hash": "x"}
+
+	s.recordHistory(context.Background(), "mem-1", HistoryEventAdd, "", "new", payload, nil)
+	ctx := WithHistoryActor(context.Background(), HistoryActor{Type: HistoryActorChat, ID: "user-1", MessageID: "msg-1"})
+	s.recordHistory(ctx, "mem-1", HistoryEventUpdate, "new", "newer", payload, nil)
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(store.entries))
+	}
+	first := store.entries[0]
+	if first.ActorType != HistoryActorSystem || first.BotID != "bot-1" {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if _, ok := first.Scope["hash"]; ok || first.Scope["scopeId"] != "bot-1" {
+		t.Fatalf("expected scope keys only, got %v", first.Scope)
+	}
+	second := store.entries[1]
+	if second.ActorType != HistoryActorChat || second.ActorID != "user-1" || second.MessageID != "msg-1" {
+		t.Fatalf("expected chat actor, got %+v", second)
+	}
+
+	resp, err := s.History(context.Background(), "bot-1", "mem-1", 0)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].Event != HistoryEventUpdate {
+		t.Fatalf("expected newest first, got %+v", resp.Items)
+	}
+	resp, err = s.History(context.Background(), "bot-2", "mem-1", 0)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(resp.Items) != 0 {
+		t.Fatalf("expected other bots to see nothing, got %+v", resp.Items)
+	}
+
+	// Entries of another bot must not use up the limit.
+	s.recordHistory(context.Background(), "mem-1", HistoryEventAdd, "", "other", map[string]any{"bot_id": "bot-2"}, nil)
+	resp, err = s.History(context.Background(), "bot-1", "mem-1", 1)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].BotID != "bot-1" {
+		t.Fatalf("expected the newest entry of bot-1, got %+v", resp.Items)
+	}
+}
+
+func TestHistoryRevertText(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		entry HistoryEntry
+		want  string
+	}{
+		{HistoryEntry{Event: HistoryEventAdd, NewMemory: "added"}, "added"},
+		{HistoryEntry{Event: HistoryEventUpdate, OldMemory: "old", NewMemory: "new"}, "new"},
+		{HistoryEntry{Event: HistoryEventDelete, OldMemory: "deleted"}, "deleted"},
+		{HistoryEntry{Event: HistoryEventCompact, OldMemory: "merged away", NewMemory: "fact"}, "merged away"},
+		{HistoryEntry{Event: HistoryEventCompact, NewMemory: "fact"}, "fact"},
+	}
+	for _, tc := range cases {
+		if got := historyRevertText(tc.entry); got != tc.want {
+			t.Fatalf("historyRevertText(%+v) = %q, want %q", tc.entry, got, tc.want)
+		}
+	}
+}
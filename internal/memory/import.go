@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxImportChunks caps the extraction calls a single import may make.
+	MaxImportChunks = 2000
+	// importJobRetention is how long finished import jobs can still be queried.
+	importJobRetention = 24 * time.Hour
+)
+
+// ErrImportNotFound indicates the import job does not exist, has expired or
+// belongs to another scope.
+var ErrImportNotFound = errors.New("import job not found")
+
+// ImportStatus is the lifecycle state of an import job.
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+// ImportRequest describes a file to ingest as memories.
+type ImportRequest struct {
+	Filename string
+	Format   ImportFormat
+	Data     []byte
+	BotID    string
+	Filters  map[string]any
+	Metadata map[string]any
+	// OnResults, when set, is called with the memories written by each chunk.
+	OnResults func(ctx context.Context, items []MemoryItem)
+}
+
+// ImportJob reports the progress of a background import.
+type ImportJob struct {
+	ID              string       `json:"id"`
+	Status          ImportStatus `json:"status"`
+	Format          ImportFormat `json:"format"`
+	Filename        string       `json:"filename,omitempty"`
+	TotalChunks     int          `json:"total_chunks"`
+	ProcessedChunks int          `json:"processed_chunks"`
+	FailedChunks    int          `json:"failed_chunks"`
+	Progress        float64      `json:"progress"`
+	Added           int          `json:"added"`
+	Updated         int          `json:"updated"`
+	Deleted         int          `json:"deleted"`
+	Error           string       `json:"error,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	FinishedAt      *time.Time   `json:"finished_at,omitempty"`
+}
+
+type importJob struct {
+	job   ImportJob
+	scope string
+}
+
+// importStore keeps import jobs in process; finished jobs are dropped after
+// importJobRetention.
+type importStore struct {
+	mu    sync.Mutex
+	items map[string]*importJob
+}
+
+func newImportStore() *importStore {
+	return &importStore{items: map[string]*importJob{}}
+}
+
+func (c *importStore) put(item *importJob) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	c.items[item.job.ID] = item
+}
+
+// get returns a snapshot of the job for id if it belongs to scope.
+func (c *importStore) get(id, scope string) (ImportJob, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	item, ok := c.items[strings.TrimSpace(id)]
+	if !ok || item.scope != scope {
+		return ImportJob{}, false
+	}
+	return item.job, true
+}
+
+func (c *importStore) update(id string, fn func(job *ImportJob)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.items[id]; ok {
+		fn(&item.job)
+	}
+}
+
+func (c *importStore) pruneLocked(now time.Time) {
+	for id, item := range c.items {
+		if item.job.FinishedAt != nil && now.Sub(*item.job.FinishedAt) > importJobRetention {
+			delete(c.items, id)
+		}
+	}
+}
+
+// StartImport parses the file and extracts memories from it in the
+// background. Parsing errors are returned immediately and wrap
+// ErrImportInvalid; progress is available through GetImport.
+func (s *Service) StartImport(ctx context.Context, req ImportRequest) (ImportJob, error) {
+	if s.llm == nil {
+		return ImportJob{}, fmt.Errorf("memory llm not configured")
+	}
+	format := req.Format
+	if format == "" {
+		format = DetectImportFormat(req.Filename, req.Data)
+	}
+	chunks, err := ParseImport(format, req.Data)
+	if err != nil {
+		return ImportJob{}, err
+	}
+	if len(chunks) == 0 {
+		return ImportJob{}, fmt.Errorf("%w: no content to import", ErrImportInvalid)
+	}
+	if len(chunks) > MaxImportChunks {
+		return ImportJob{}, fmt.Errorf("%w: %d chunks exceeds the limit of %d", ErrImportInvalid, len(chunks), MaxImportChunks)
+	}
+
+	job := ImportJob{
+		ID:          uuid.NewString(),
+		Status:      ImportStatusRunning,
+		Format:      format,
+		Filename:    req.Filename,
+		TotalChunks: len(chunks),
+		CreatedAt:   time.Now().UTC(),
+	}
+	s.imports.put(&importJob{job: job, scope: compactionScope(req.Filters)})
+	go s.runImport(context.WithoutCancel(ctx), job, req, chunks)
+	return job, nil
+}
+
+// GetImport returns the progress of an import job within the filters' scope.
+func (s *Service) GetImport(id string, filters map[string]any) (ImportJob, error) {
+	job, ok := s.imports.get(id, compactionScope(filters))
+	if !ok {
+		return ImportJob{}, ErrImportNotFound
+	}
+	return job, nil
+}
+
+func (s *Service) runImport(ctx context.Context, job ImportJob, req ImportRequest, chunks []ImportChunk) {
+	for i, chunk := range chunks {
+		metadata := mergeMetadata(req.Metadata, map[string]any{
+			"source":        "import",
+			"import_id":     job.ID,
+			"import_format": string(job.Format),
+			"import_chunk":  i,
+		})
+		if req.Filename != "" {
+			metadata["import_file"] = req.Filename
+		}
+		if chunk.Label != "" {
+			metadata["import_section"] = chunk.Label
+		}
+		resp, err := s.Add(ctx, AddRequest{
+			Messages: chunk.Messages,
+			BotID:    req.BotID,
+			Metadata: metadata,
+			Filters:  req.Filters,
+		})
+		if err != nil {
+			s.logger.Warn("memory import chunk failed", slog.String("import_id", job.ID), slog.Int("chunk", i), slog.Any("error", err))
+		} else if req.OnResults != nil && len(resp.Results) > 0 {
+			req.OnResults(ctx, resp.Results)
+		}
+		s.imports.update(job.ID, func(j *ImportJob) {
+			j.ProcessedChunks++
+			j.Progress = float64(j.ProcessedChunks) / float64(j.TotalChunks)
+			if err != nil {
+				j.FailedChunks++
+				j.Error = err.Error()
+				return
+			}
+			for _, item := range resp.Results {
+				switch item.Metadata["event"] {
+				case "ADD":
+					j.Added++
+				case "UPDATE":
+					j.Updated++
+				case "DELETE":
+					j.Deleted++
+				}
+			}
+		})
+	}
+
+	s.imports.update(job.ID, func(j *ImportJob) {
+		now := time.Now().UTC()
+		j.FinishedAt = &now
+		j.Status = ImportStatusCompleted
+		if j.FailedChunks == j.TotalChunks {
+			j.Status = ImportStatusFailed
+		}
+	})
+	s.logger.Info("memory import finished", slog.String("import_id", job.ID), slog.Int("chunks", len(chunks)))
+}
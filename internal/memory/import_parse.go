@@ -0,0 +1,629 @@
+package memory
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ImportFormat is the kind of file a memory import reads.
+type ImportFormat string
+
+const (
+	ImportFormatText     ImportFormat = "text"
+	ImportFormatMarkdown ImportFormat = "markdown"
+	ImportFormatPDF      ImportFormat = "pdf"
+	ImportFormatTelegram ImportFormat = "telegram"
+	ImportFormatWhatsApp ImportFormat = "whatsapp"
+)
+
+const (
+	// importChunkChars bounds the text sent to fact extraction per chunk.
+	importChunkChars = 4000
+	// importChatChunkMessages bounds the chat messages sent per chunk.
+	importChatChunkMessages = 50
+	// pdfMaxStreamBytes and pdfMaxDecodedBytes cap the decompressed size of
+	// one PDF content stream and of all streams of a document.
+	pdfMaxStreamBytes  = 16 << 20
+	pdfMaxDecodedBytes = 64 << 20
+)
+
+// ErrImportInvalid indicates the uploaded file cannot be imported.
+var ErrImportInvalid = errors.New("invalid import file")
+
+// ImportChunk is one unit of fact extraction.
+type ImportChunk struct {
+	Messages []Message
+	// Label locates the chunk in the source, e.g. a heading or a date range.
+	Label string
+}
+
+// ParseImportFormat validates a user-provided format name.
+func ParseImportFormat(raw string) (ImportFormat, error) {
+	switch format := ImportFormat(strings.ToLower(strings.TrimSpace(raw))); format {
+	case ImportFormatText, ImportFormatMarkdown, ImportFormatPDF, ImportFormatTelegram, ImportFormatWhatsApp:
+		return format, nil
+	case "md":
+		return ImportFormatMarkdown, nil
+	case "txt":
+		return ImportFormatText, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q", ErrImportInvalid, raw)
+	}
+}
+
+// DetectImportFormat guesses the format from the file name and content.
+func DetectImportFormat(filename string, data []byte) ImportFormat {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return ImportFormatPDF
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown":
+		return ImportFormatMarkdown
+	case ".json":
+		return ImportFormatTelegram
+	case ".pdf":
+		return ImportFormatPDF
+	}
+	if looksLikeWhatsApp(data) {
+		return ImportFormatWhatsApp
+	}
+	return ImportFormatText
+}
+
+// ParseImport splits a file into chunks ready for fact extraction.
+func ParseImport(format ImportFormat, data []byte) ([]ImportChunk, error) {
+	switch format {
+	case ImportFormatText:
+		return documentChunks("", string(data)), nil
+	case ImportFormatMarkdown:
+		return markdownChunks(string(data)), nil
+	case ImportFormatPDF:
+		text, err := extractPDFText(data)
+		if err != nil {
+			return nil, err
+		}
+		return documentChunks("", text), nil
+	case ImportFormatTelegram:
+		messages, err := parseTelegramExport(data)
+		if err != nil {
+			return nil, err
+		}
+		return chatChunks(messages), nil
+	case ImportFormatWhatsApp:
+		return chatChunks(parseWhatsAppExport(string(data))), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrImportInvalid, format)
+	}
+}
+
+// --- Documents ---
+
+func documentChunks(label, text string) []ImportChunk {
+	parts := chunkText(text, importChunkChars)
+	chunks := make([]ImportChunk, 0, len(parts))
+	for _, part := range parts {
+		chunks = append(chunks, ImportChunk{
+			Messages: []Message{{Role: "user", Content: part}},
+			Label:    label,
+		})
+	}
+	return chunks
+}
+
+var markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+
+// markdownChunks chunks each section separately so facts keep their heading.
+func markdownChunks(text string) []ImportChunk {
+	var chunks []ImportChunk
+	var heading string
+	var section strings.Builder
+	flush := func() {
+		chunks = append(chunks, documentChunks(heading, section.String())...)
+		section.Reset()
+	}
+	inFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			if match := markdownHeading.FindStringSubmatch(line); match != nil {
+				flush()
+				heading = match[1]
+			}
+		}
+		section.WriteString(line)
+		section.WriteByte('\n')
+	}
+	flush()
+	return chunks
+}
+
+// chunkText packs paragraphs into chunks of at most maxChars, splitting
+// oversized paragraphs by line and then by word.
+func chunkText(text string, maxChars int) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var units []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if len(paragraph) <= maxChars {
+			units = append(units, paragraph)
+			continue
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if len(line) <= maxChars {
+				units = append(units, line)
+				continue
+			}
+			units = append(units, packUnits(strings.Fields(line), " ", maxChars)...)
+		}
+	}
+	return packUnits(units, "\n\n", maxChars)
+}
+
+func packUnits(units []string, sep string, maxChars int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, unit := range units {
+		if current.Len() > 0 && current.Len()+len(sep)+len(unit) > maxChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(unit)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// --- Chat exports ---
+
+type chatExportMessage struct {
+	Sender string
+	Date   string
+	Text   string
+}
+
+// chatChunks groups consecutive messages, prefixing each with its sender so
+// extraction can attribute facts.
+func chatChunks(messages []chatExportMessage) []ImportChunk {
+	var chunks []ImportChunk
+	var current []chatExportMessage
+	size := 0
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		chunk := ImportChunk{Messages: make([]Message, 0, len(current))}
+		for _, msg := range current {
+			chunk.Messages = append(chunk.Messages, Message{Role: "user", Content: msg.Sender + ": " + msg.Text})
+		}
+		first, last := current[0].Date, current[len(current)-1].Date
+		switch {
+		case first == "" || first == last:
+			chunk.Label = first
+		default:
+			chunk.Label = first + " - " + last
+		}
+		chunks = append(chunks, chunk)
+		current = nil
+		size = 0
+	}
+	for _, msg := range messages {
+		if len(current) >= importChatChunkMessages || (size > 0 && size+len(msg.Text) > importChunkChars) {
+			flush()
+		}
+		current = append(current, msg)
+		size += len(msg.Text)
+	}
+	flush()
+	return chunks
+}
+
+type telegramChat struct {
+	Messages []telegramMessage `json:"messages"`
+}
+
+type telegramMessage struct {
+	Type string          `json:"type"`
+	Date string          `json:"date"`
+	From string          `json:"from"`
+	Text json.RawMessage `json:"text"`
+}
+
+// parseTelegramExport reads a Telegram Desktop JSON export of a single chat
+// or of the whole account.
+func parseTelegramExport(data []byte) ([]chatExportMessage, error) {
+	var export struct {
+		telegramChat
+		Chats struct {
+			List []telegramChat `json:"list"`
+		} `json:"chats"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: telegram export: %v", ErrImportInvalid, err)
+	}
+	chats := append([]telegramChat{export.telegramChat}, export.Chats.List...)
+	var messages []chatExportMessage
+	for _, chat := range chats {
+		for _, msg := range chat.Messages {
+			if msg.Type != "" && msg.Type != "message" {
+				continue
+			}
+			text := strings.TrimSpace(telegramText(msg.Text))
+			if text == "" {
+				continue
+			}
+			sender := strings.TrimSpace(msg.From)
+			if sender == "" {
+				sender = "Unknown"
+			}
+			messages = append(messages, chatExportMessage{Sender: sender, Date: msg.Date, Text: text})
+		}
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("%w: telegram export has no text messages", ErrImportInvalid)
+	}
+	return messages, nil
+}
+
+// telegramText flattens message text, which is either a string or a list of
+// strings and formatted entities.
+func telegramText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		var plain string
+		if err := json.Unmarshal(part, &plain); err == nil {
+			b.WriteString(plain)
+			continue
+		}
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &entity); err == nil {
+			b.WriteString(entity.Text)
+		}
+	}
+	return b.String()
+}
+
+// whatsAppLine matches both the Android ("31/12/21, 22:15 - Name: text") and
+// iOS ("[31/12/21, 22:15:03] Name: text") export layouts.
+var whatsAppLine = regexp.MustCompile(`^\[?(\d{1,4}[./-]\d{1,2}[./-]\d{1,4}),?\s+(\d{1,2}:\d{2}(?::\d{2})?(?:[\s\x{202f}]?[AaPp]\.?\s?[Mm]\.?)?)\]?\s*(?:-\s*)?(.*)$`)
+
+const whatsAppMediaOmitted = "<Media omitted>"
+
+// looksLikeWhatsApp checks that the file starts with a timestamped line and
+// most of its first lines are timestamped; the rest are continuations.
+func looksLikeWhatsApp(data []byte) bool {
+	lines, matched := 0, 0
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "\u200e\ufeff"))
+		if line == "" {
+			continue
+		}
+		ok := whatsAppLine.MatchString(line)
+		if lines == 0 && !ok {
+			return false
+		}
+		lines++
+		if ok {
+			matched++
+		}
+		if lines >= 10 {
+			break
+		}
+	}
+	return lines > 0 && matched*2 >= lines
+}
+
+// parseWhatsAppExport reads a WhatsApp "export chat" text file. Lines without
+// a timestamp continue the previous message; system notices are skipped.
+func parseWhatsAppExport(text string) []chatExportMessage {
+	var messages []chatExportMessage
+	continuing := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimLeft(line, "\u200e\ufeff")
+		match := whatsAppLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			if continuing && strings.TrimSpace(line) != "" {
+				last := &messages[len(messages)-1]
+				last.Text += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+		continuing = false
+		sender, body, ok := strings.Cut(match[3], ": ")
+		body = strings.TrimSpace(strings.TrimLeft(body, "\u200e"))
+		if !ok || body == "" || body == whatsAppMediaOmitted {
+			continue
+		}
+		messages = append(messages, chatExportMessage{
+			Sender: strings.TrimSpace(sender),
+			Date:   match[1] + " " + match[2],
+			Text:   body,
+		})
+		continuing = true
+	}
+	return messages
+}
+
+// --- PDF ---
+
+var pdfStreamStart = regexp.MustCompile(`stream\r?\n`)
+
+// extractPDFText pulls text drawn by content streams. It understands
+// uncompressed and FlateDecode streams with simple (single-byte) fonts, which
+// covers most generated documents; scanned or CID-encoded PDFs yield no text
+// and are rejected.
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("%w: not a PDF file", ErrImportInvalid)
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", fmt.Errorf("%w: encrypted PDFs are not supported", ErrImportInvalid)
+	}
+	var out strings.Builder
+	remaining := int64(pdfMaxDecodedBytes)
+	for _, loc := range pdfStreamStart.FindAllIndex(data, -1) {
+		if loc[0] > 0 && isPDFRegular(data[loc[0]-1]) {
+			continue // "endstream"
+		}
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		dict := data[:loc[0]]
+		if obj := bytes.LastIndex(dict, []byte(" obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		content, ok, err := decodePDFStream(dict, data[loc[1]:loc[1]+end], min(remaining, pdfMaxStreamBytes))
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		remaining -= int64(len(content))
+		if text := strings.TrimSpace(pdfContentText(content)); text != "" {
+			out.WriteString(text)
+			out.WriteString("\n\n")
+		}
+	}
+	text := strings.TrimSpace(out.String())
+	if text == "" {
+		return "", fmt.Errorf("%w: no extractable text in PDF", ErrImportInvalid)
+	}
+	return text, nil
+}
+
+// decodePDFStream returns the content of a text stream. Streams that
+// decompress to more than limit bytes fail the import.
+func decodePDFStream(dict, raw []byte, limit int64) ([]byte, bool, error) {
+	for _, skip := range []string{"/Image", "/Length1", "/XRef", "/ObjStm", "/Metadata"} {
+		if bytes.Contains(dict, []byte(skip)) {
+			return nil, false, nil
+		}
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		if int64(len(raw)) > limit {
+			return nil, false, fmt.Errorf("%w: PDF content exceeds %d bytes", ErrImportInvalid, limit)
+		}
+		return raw, true, nil
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) {
+		return nil, false, nil
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false, nil
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if int64(len(content)) > limit {
+		return nil, false, fmt.Errorf("%w: PDF content exceeds %d bytes", ErrImportInvalid, limit)
+	}
+	if err != nil && len(content) == 0 {
+		return nil, false, nil
+	}
+	return content, true, nil
+}
+
+// pdfContentText interprets the text-showing operators of a content stream.
+func pdfContentText(content []byte) string {
+	var out strings.Builder
+	var operands []string
+	inArray := false
+	newline := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := readPDFLiteral(content[i:])
+			operands = append(operands, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<', c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			s, n := readPDFHex(content[i:])
+			operands = append(operands, s)
+			i += n
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case !isPDFRegular(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && isPDFRegular(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if token[0] == '/' {
+				continue
+			}
+			if value, err := strconv.ParseFloat(token, 64); err == nil {
+				// Large negative kerning inside TJ arrays separates words.
+				if inArray && value < -200 {
+					operands = append(operands, " ")
+				}
+				continue
+			}
+			switch token {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				newline()
+				out.WriteString(strings.Join(operands, ""))
+			case "T*", "Td", "TD", "ET":
+				newline()
+			}
+			operands = operands[:0]
+		}
+	}
+	return out.String()
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '%':
+		return false
+	}
+	return true
+}
+
+// readPDFLiteral decodes a (literal string) and returns it with the number of
+// bytes consumed.
+func readPDFLiteral(data []byte) (string, int) {
+	var b []byte
+	depth := 0
+	i := 0
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfBytesToString(b), i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(data) {
+				continue
+			}
+			switch e := data[i]; e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				end := i + 1
+				for end < len(data) && end < i+3 && data[end] >= '0' && data[end] <= '7' {
+					end++
+				}
+				value, _ := strconv.ParseUint(string(data[i:end]), 8, 8)
+				b = append(b, byte(value))
+				i = end - 1
+			default:
+				b = append(b, e)
+			}
+			continue
+		}
+		b = append(b, c)
+	}
+	return pdfBytesToString(b), i
+}
+
+func readPDFHex(data []byte) (string, int) {
+	end := bytes.IndexByte(data, '>')
+	if end < 0 {
+		return "", len(data)
+	}
+	var digits []byte
+	for _, c := range data[1:end] {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		value, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		b[i] = byte(value)
+	}
+	return pdfBytesToString(b), end + 1
+}
+
+// pdfBytesToString decodes UTF-16BE strings (with BOM) and otherwise treats
+// bytes as Latin-1, dropping control characters.
+func pdfBytesToString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		if c >= 0x20 || c == '\n' || c == '\t' {
+			runes = append(runes, rune(c))
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestChunkText(t *testing.T) {
+	t.Parallel()
+
+	text := "first paragraph\n\nsecond paragraph\n\n" + strings.Repeat("word ", 30)
+	chunks := chunkText(text, 60)
+	if len(chunks) < 3 {
+		t.Fatalf("expected oversized text to be split, got %d chunks", len(chunks))
+	}
+	if chunks[0] != "first paragraph\n\nsecond paragraph" {
+		t.Fatalf("expected small paragraphs to be packed, got %q", chunks[0])
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 60 {
+			t.Fatalf("chunk exceeds limit: %d", len(chunk))
+		}
+	}
+}
+
+func TestMarkdownChunks(t *testing.T) {
+	t.Parallel()
+
+	chunks := markdownChunks("intro\n\n# Work\nI work at Acme.\n```\n# not a heading\n```\n## Hobbies\nI climb.\n")
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 sections, got %+v", chunks)
+	}
+	if chunks[1].Label != "Work" || !strings.Contains(chunks[1].Messages[0].Content, "# not a heading") {
+		t.Fatalf("unexpected work section: %+v", chunks[1])
+	}
+	if chunks[2].Label != "Hobbies" {
+		t.Fatalf("expected hobbies label, got %q", chunks[2].Label)
+	}
+}
+
+func TestParseTelegramExport(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"name":"Alice","messages":[
+		{"type":"service","date":"2024-01-01T10:00:00","actor":"Alice"},
+		{"type":"message","date":"2024-01-01T10:01:00","from":"Alice","text":"I moved to Berlin"},
+		{"type":"message","date":"2024-01-01T10:02:00","from":"Bob","text":["Nice, ",{"type":"bold","text":"welcome"},"!"]},
+		{"type":"message","date":"2024-01-01T10:03:00","from":"Bob","text":""}
+	]}`)
+	messages, err := parseTelegramExport(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 text messages, got %+v", messages)
+	}
+	if messages[1].Text != "Nice, welcome!" {
+		t.Fatalf("expected flattened entities, got %q", messages[1].Text)
+	}
+	chunks := chatChunks(messages)
+	if len(chunks) != 1 || chunks[0].Messages[0].Content != "Alice: I moved to Berlin" {
+		t.Fatalf("unexpected chunks: %+v", chunks)
+	}
+	if chunks[0].Label != "2024-01-01T10:01:00 - 2024-01-01T10:02:00" {
+		t.Fatalf("unexpected label: %q", chunks[0].Label)
+	}
+
+	if _, err := parseTelegramExport([]byte("not json")); !errors.Is(err, ErrImportInvalid) {
+		t.Fatalf("expected ErrImportInvalid, got %v", err)
+	}
+}
+
+func TestParseWhatsAppExport(t *testing.T) {
+	t.Parallel()
+
+	text := "12/31/21, 10:15 PM - Messages and calls are end-to-end encrypted.\n" +
+		"12/31/21, 10:16 PM - Alice: My birthday is in March\n" +
+		"and I love cake\n" +
+		"12/31/21, 10:17 PM - Bob: <Media omitted>\n" +
+		"[31/12/2021, 22:18:03] Bob: Noted\n"
+	if !looksLikeWhatsApp([]byte(text)) {
+		t.Fatalf("expected WhatsApp export to be detected")
+	}
+	messages := parseWhatsAppExport(text)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %+v", messages)
+	}
+	if messages[0].Sender != "Alice" || messages[0].Text != "My birthday is in March\nand I love cake" {
+		t.Fatalf("unexpected first message: %+v", messages[0])
+	}
+	if messages[1].Sender != "Bob" || messages[1].Text != "Noted" {
+		t.Fatalf("unexpected second message: %+v", messages[1])
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		data string
+		want ImportFormat
+	}{
+		{"notes.md", "# hi", ImportFormatMarkdown},
+		{"result.json", "{}", ImportFormatTelegram},
+		{"scan.bin", "%PDF-1.4", ImportFormatPDF},
+		{"chat.txt", "1/2/24, 09:00 - Alice: hi\n", ImportFormatWhatsApp},
+		{"notes.txt", "just some notes", ImportFormatText},
+	}
+	for _, tc := range cases {
+		if got := DetectImportFormat(tc.name, []byte(tc.data)); got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+	if _, err := ParseImportFormat("docx"); !errors.Is(err, ErrImportInvalid) {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestExtractPDFText(t *testing.T) {
+	t.Parallel()
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	_, _ = w.Write([]byte("BT /F1 12 Tf 72 720 Td (I live in \\(old\\) Lisbon) Tj 0 -14 Td [(Fav)10(orite) -300(color: blue)] TJ ET"))
+	_ = w.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Length 30 >>\nstream\nBT <FEFF00480069> Tj ET\nendstream\nendobj\n%%EOF\n")
+
+	text, err := extractPDFText(pdf.Bytes())
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	for _, want := range []string{"I live in (old) Lisbon", "Favorite color: blue", "Hi"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in %q", want, text)
+		}
+	}
+
+	if _, err := extractPDFText([]byte("%PDF-1.4\n%%EOF")); !errors.Is(err, ErrImportInvalid) {
+		t.Fatalf("expected empty PDF to be rejected, got %v", err)
+	}
+}
+
+func TestExtractPDFTextRejectsDecompressionBomb(t *testing.T) {
+	t.Parallel()
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	_, _ = w.Write(make([]byte, pdfMaxStreamBytes+1))
+	_ = w.Close()
+
+	var pdf bytes.Buffer
+	fmt.Fprintf(&pdf, "%%PDF-1.4\n4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+
+	if _, err := extractPDFText(pdf.Bytes()); !errors.Is(err, ErrImportInvalid) || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected oversized stream to fail the import, got %v", err)
+	}
+}
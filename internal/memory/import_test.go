@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeExtractLLM struct {
+	LLM
+	mu       sync.Mutex
+	metadata []map[string]any
+}
+
+func (f *fakeExtractLLM) Extract(_ context.Context, req ExtractRequest) (ExtractResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metadata = append(f.metadata, req.Metadata)
+	return ExtractResponse{}, nil
+}
+
+func TestServiceImport(t *testing.T) {
+	t.Parallel()
+
+	llm := &fakeExtractLLM{}
+	s := &Service{
+		llm:     llm,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		imports: newImportStore(),
+	}
+	filters := map[string]any{"namespace": "bot", "scopeId": "bot-1"}
+	job, err := s.StartImport(context.Background(), ImportRequest{
+		Filename: "notes.md",
+		Data:     []byte("# Work\nI work at Acme.\n# Home\nI live in Lisbon.\n"),
+		BotID:    "bot-1",
+		Filters:  filters,
+	})
+	if err != nil {
+		t.Fatalf("start import: %v", err)
+	}
+	if job.Format != ImportFormatMarkdown || job.TotalChunks != 2 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err = s.GetImport(job.ID, filters)
+		if err != nil {
+			t.Fatalf("get import: %v", err)
+		}
+		if job.Status != ImportStatusRunning || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != ImportStatusCompleted || job.ProcessedChunks != 2 || job.Progress != 1 {
+		t.Fatalf("expected completed job, got %+v", job)
+	}
+	llm.mu.Lock()
+	metadata := llm.metadata
+	llm.mu.Unlock()
+	if len(metadata) != 2 || metadata[1]["import_section"] != "Home" || metadata[1]["import_file"] != "notes.md" {
+		t.Fatalf("expected source metadata per chunk, got %+v", metadata)
+	}
+
+	if _, err := s.GetImport(job.ID, map[string]any{"namespace": "bot", "scopeId": "bot-2"}); !errors.Is(err, ErrImportNotFound) {
+		t.Fatalf("expected job to be hidden from other scopes, got %v", err)
+	}
+	if _, err := s.StartImport(context.Background(), ImportRequest{Format: ImportFormatTelegram, Data: []byte("{"), BotID: "bot-1"}); !errors.Is(err, ErrImportInvalid) {
+		t.Fatalf("expected invalid export to fail, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"hash/fnv"
 	"log/slog"
@@ -57,17 +58,45 @@ type BM25Indexer struct {
 	logger *slog.Logger
 	k1     float64
 	b      float64
+	store  BM25StatsStore
 
 	mu    sync.RWMutex
-	stats map[string]*bm25Stats
+	stats map[string]*BM25Stats
+	// warmed holds the in-memory statistics rebuilt from the vector store.
+	warmed map[string]struct{}
 }
 
-type bm25Stats struct {
+// BM25Stats are the corpus statistics of one bot and language. DocFreq may
+// only hold the terms a caller asked for.
+type BM25Stats struct {
 	DocCount  int
 	AvgDocLen float64
 	DocFreq   map[string]int
 }
 
+// BM25StatsStore persists corpus statistics so that all instances rank
+// against the same corpus and nothing has to be rebuilt on startup.
+type BM25StatsStore interface {
+	// AddDocument counts a document and returns the updated statistics for
+	// its terms.
+	AddDocument(ctx context.Context, botID, lang string, terms []string, docLen int) (BM25Stats, error)
+	RemoveDocument(ctx context.Context, botID, lang string, terms []string, docLen int) error
+	Stats(ctx context.Context, botID, lang string, terms []string) (BM25Stats, error)
+	// Warmed reports whether the statistics of botID and lang were rebuilt
+	// from the vector store.
+	Warmed(ctx context.Context, botID, lang string) (bool, error)
+	// ReplaceCorpus replaces the statistics of botID and lang with corpus and
+	// marks them warmed. It reports false when they were already warmed.
+	ReplaceCorpus(ctx context.Context, botID, lang string, corpus BM25Corpus) (bool, error)
+}
+
+// BM25Corpus are the complete statistics of one bot and language.
+type BM25Corpus struct {
+	DocCount    int
+	TotalDocLen int64
+	DocFreq     map[string]int
+}
+
 func NewBM25Indexer(log *slog.Logger) *BM25Indexer {
 	if log == nil {
 		log = slog.Default()
@@ -77,10 +106,113 @@ func NewBM25Indexer(log *slog.Logger) *BM25Indexer {
 		logger: log.With(slog.String("indexer", "bm25")),
 		k1:     defaultBM25K1,
 		b:      defaultBM25B,
-		stats:  map[string]*bm25Stats{},
+		stats:  map[string]*BM25Stats{},
+		warmed: map[string]struct{}{},
 	}
 }
 
+// SetStatsStore persists statistics in store instead of process memory.
+func (b *BM25Indexer) SetStatsStore(store BM25StatsStore) {
+	b.store = store
+}
+
+type bm25CorpusKey struct {
+	botID string
+	lang  string
+}
+
+// bm25Warmup rebuilds the statistics of every bot and language that was not
+// rebuilt from the vector store yet, e.g. after a crash halfway through.
+type bm25Warmup struct {
+	indexer *BM25Indexer
+	needed  map[bm25CorpusKey]bool
+	corpora map[bm25CorpusKey]*BM25Corpus
+}
+
+func (b *BM25Indexer) newWarmup() *bm25Warmup {
+	return &bm25Warmup{
+		indexer: b,
+		needed:  map[bm25CorpusKey]bool{},
+		corpora: map[bm25CorpusKey]*BM25Corpus{},
+	}
+}
+
+// needs reports whether documents of botID in lang must be counted.
+func (w *bm25Warmup) needs(ctx context.Context, botID, lang string) (bool, error) {
+	name, _ := w.indexer.normalizeAnalyzer(lang)
+	key := bm25CorpusKey{botID: botID, lang: name}
+	if needed, ok := w.needed[key]; ok {
+		return needed, nil
+	}
+	warmed, err := w.indexer.warmedCorpus(ctx, botID, name)
+	if err != nil {
+		return false, err
+	}
+	w.needed[key] = !warmed
+	return !warmed, nil
+}
+
+// add counts one document of a corpus that needs a warmup.
+func (w *bm25Warmup) add(botID, lang string, termFreq map[string]int, docLen int) {
+	name, _ := w.indexer.normalizeAnalyzer(lang)
+	key := bm25CorpusKey{botID: botID, lang: name}
+	corpus := w.corpora[key]
+	if corpus == nil {
+		corpus = &BM25Corpus{DocFreq: map[string]int{}}
+		w.corpora[key] = corpus
+	}
+	corpus.DocCount++
+	corpus.TotalDocLen += int64(docLen)
+	for term := range termFreq {
+		corpus.DocFreq[term]++
+	}
+}
+
+// commit replaces the collected statistics and returns how many corpora
+// were rebuilt.
+func (w *bm25Warmup) commit(ctx context.Context) (int, error) {
+	rebuilt := 0
+	for key, corpus := range w.corpora {
+		replaced, err := w.indexer.replaceCorpus(ctx, key.botID, key.lang, *corpus)
+		if err != nil {
+			return rebuilt, fmt.Errorf("replace bm25 stats of %s/%s: %w", key.botID, key.lang, err)
+		}
+		if replaced {
+			rebuilt++
+		}
+	}
+	return rebuilt, nil
+}
+
+func (b *BM25Indexer) warmedCorpus(ctx context.Context, botID, lang string) (bool, error) {
+	if b.store != nil {
+		return b.store.Warmed(ctx, botID, lang)
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.warmed[botID+"/"+lang]
+	return ok, nil
+}
+
+func (b *BM25Indexer) replaceCorpus(ctx context.Context, botID, lang string, corpus BM25Corpus) (bool, error) {
+	if b.store != nil {
+		return b.store.ReplaceCorpus(ctx, botID, lang, corpus)
+	}
+	stats := &BM25Stats{DocCount: corpus.DocCount, DocFreq: corpus.DocFreq}
+	if corpus.DocCount > 0 {
+		stats.AvgDocLen = float64(corpus.TotalDocLen) / float64(corpus.DocCount)
+	}
+	key := botID + "/" + lang
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.warmed[key]; ok {
+		return false, nil
+	}
+	b.stats[key] = stats
+	b.warmed[key] = struct{}{}
+	return true, nil
+}
+
 func (b *BM25Indexer) TermFrequencies(lang, text string) (map[string]int, int, error) {
 	analyzerName, err := b.normalizeAnalyzer(lang)
 	if err != nil {
@@ -104,28 +236,58 @@ func (b *BM25Indexer) TermFrequencies(lang, text string) (map[string]int, int, e
 	return freq, docLen, nil
 }
 
-func (b *BM25Indexer) AddDocument(lang string, termFreq map[string]int, docLen int) (indices []uint32, values []float32) {
+// AddDocument counts a document of botID in the corpus and returns its sparse
+// vector.
+func (b *BM25Indexer) AddDocument(ctx context.Context, botID, lang string, termFreq map[string]int, docLen int) (indices []uint32, values []float32, err error) {
+	name, _ := b.normalizeAnalyzer(lang)
+	if b.store != nil {
+		stats, err := b.store.AddDocument(ctx, botID, name, sortedTerms(termFreq), docLen)
+		if err != nil {
+			return nil, nil, fmt.Errorf("update bm25 stats: %w", err)
+		}
+		indices, values = b.buildDocVector(&stats, termFreq, docLen)
+		return indices, values, nil
+	}
 	b.mu.Lock()
-	stats := b.ensureStatsLocked(lang)
+	stats := b.ensureStatsLocked(botID, name)
 	b.updateStatsAddLocked(stats, termFreq, docLen)
-	indices, values = b.buildDocVectorLocked(stats, termFreq, docLen)
+	indices, values = b.buildDocVector(stats, termFreq, docLen)
 	b.mu.Unlock()
-	return indices, values
+	return indices, values, nil
 }
 
-func (b *BM25Indexer) RemoveDocument(lang string, termFreq map[string]int, docLen int) {
+// RemoveDocument removes a document of botID from the corpus statistics.
+func (b *BM25Indexer) RemoveDocument(ctx context.Context, botID, lang string, termFreq map[string]int, docLen int) error {
+	name, _ := b.normalizeAnalyzer(lang)
+	if b.store != nil {
+		if err := b.store.RemoveDocument(ctx, botID, name, sortedTerms(termFreq), docLen); err != nil {
+			return fmt.Errorf("update bm25 stats: %w", err)
+		}
+		return nil
+	}
 	b.mu.Lock()
-	stats := b.ensureStatsLocked(lang)
+	stats := b.ensureStatsLocked(botID, name)
 	b.updateStatsRemoveLocked(stats, termFreq, docLen)
 	b.mu.Unlock()
+	return nil
 }
 
-func (b *BM25Indexer) BuildQueryVector(lang string, termFreq map[string]int) (indices []uint32, values []float32) {
-	b.mu.RLock()
-	stats := b.ensureStatsLocked(lang)
-	indices, values = b.buildQueryVectorLocked(stats, termFreq)
-	b.mu.RUnlock()
-	return indices, values
+// BuildQueryVector returns the sparse query vector against botID's corpus.
+func (b *BM25Indexer) BuildQueryVector(ctx context.Context, botID, lang string, termFreq map[string]int) (indices []uint32, values []float32, err error) {
+	name, _ := b.normalizeAnalyzer(lang)
+	if b.store != nil {
+		stats, err := b.store.Stats(ctx, botID, name, sortedTerms(termFreq))
+		if err != nil {
+			return nil, nil, fmt.Errorf("load bm25 stats: %w", err)
+		}
+		indices, values = b.buildQueryVector(&stats, termFreq)
+		return indices, values, nil
+	}
+	b.mu.Lock()
+	stats := b.ensureStatsLocked(botID, name)
+	indices, values = b.buildQueryVector(stats, termFreq)
+	b.mu.Unlock()
+	return indices, values, nil
 }
 
 func (b *BM25Indexer) normalizeAnalyzer(lang string) (string, error) {
@@ -139,19 +301,19 @@ func (b *BM25Indexer) normalizeAnalyzer(lang string) (string, error) {
 	return normalized, nil
 }
 
-func (b *BM25Indexer) ensureStatsLocked(lang string) *bm25Stats {
-	name, _ := b.normalizeAnalyzer(lang)
-	stats := b.stats[name]
+func (b *BM25Indexer) ensureStatsLocked(botID, lang string) *BM25Stats {
+	key := botID + "/" + lang
+	stats := b.stats[key]
 	if stats == nil {
-		stats = &bm25Stats{
+		stats = &BM25Stats{
 			DocFreq: map[string]int{},
 		}
-		b.stats[name] = stats
+		b.stats[key] = stats
 	}
 	return stats
 }
 
-func (b *BM25Indexer) updateStatsAddLocked(stats *bm25Stats, termFreq map[string]int, docLen int) {
+func (b *BM25Indexer) updateStatsAddLocked(stats *BM25Stats, termFreq map[string]int, docLen int) {
 	totalDocs := stats.DocCount
 	stats.DocCount++
 	totalLen := stats.AvgDocLen * float64(totalDocs)
@@ -161,7 +323,7 @@ func (b *BM25Indexer) updateStatsAddLocked(stats *bm25Stats, termFreq map[string
 	}
 }
 
-func (b *BM25Indexer) updateStatsRemoveLocked(stats *bm25Stats, termFreq map[string]int, docLen int) {
+func (b *BM25Indexer) updateStatsRemoveLocked(stats *BM25Stats, termFreq map[string]int, docLen int) {
 	if stats.DocCount <= 0 {
 		return
 	}
@@ -182,7 +344,7 @@ func (b *BM25Indexer) updateStatsRemoveLocked(stats *bm25Stats, termFreq map[str
 	}
 }
 
-func (b *BM25Indexer) buildDocVectorLocked(stats *bm25Stats, termFreq map[string]int, docLen int) ([]uint32, []float32) {
+func (b *BM25Indexer) buildDocVector(stats *BM25Stats, termFreq map[string]int, docLen int) ([]uint32, []float32) {
 	if stats.DocCount == 0 || docLen == 0 {
 		return nil, nil
 	}
@@ -210,7 +372,7 @@ func (b *BM25Indexer) buildDocVectorLocked(stats *bm25Stats, termFreq map[string
 	return sparseWeightsToVector(weights)
 }
 
-func (b *BM25Indexer) buildQueryVectorLocked(stats *bm25Stats, termFreq map[string]int) ([]uint32, []float32) {
+func (b *BM25Indexer) buildQueryVector(stats *BM25Stats, termFreq map[string]int) ([]uint32, []float32) {
 	if stats.DocCount == 0 {
 		return nil, nil
 	}
@@ -229,6 +391,17 @@ func (b *BM25Indexer) buildQueryVectorLocked(stats *bm25Stats, termFreq map[stri
 	return sparseWeightsToVector(weights)
 }
 
+// sortedTerms orders terms so that concurrent writers lock term rows in the
+// same order.
+func sortedTerms(termFreq map[string]int) []string {
+	terms := make([]string, 0, len(termFreq))
+	for term := range termFreq {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
 func sparseWeightsToVector(weights map[uint32]float32) ([]uint32, []float32) {
 	if len(weights) == 0 {
 		return nil, nil
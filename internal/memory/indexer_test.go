@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -61,15 +62,16 @@ func TestBM25Indexer_TermFrequencies(t *testing.T) {
 
 func TestBM25Indexer_BM25Logic(t *testing.T) {
 	indexer := NewBM25Indexer(nil)
+	ctx := context.Background()
 
 	lang := "en"
 	tf1 := map[string]int{"golang": 1, "programming": 1}
 	len1 := 2
-	indices1, values1 := indexer.AddDocument(lang, tf1, len1)
+	indices1, values1, _ := indexer.AddDocument(ctx, "", lang, tf1, len1)
 
 	tf2 := map[string]int{"golang": 1, "tutorial": 1, "advanced": 1, "topics": 1}
 	len2 := 4
-	indices2, values2 := indexer.AddDocument(lang, tf2, len2)
+	indices2, values2, _ := indexer.AddDocument(ctx, "", lang, tf2, len2)
 
 	// In BM25, same term in a shorter doc should have higher weight than in a longer doc.
 	var weight1, weight2 float32
@@ -90,8 +92,8 @@ func TestBM25Indexer_BM25Logic(t *testing.T) {
 
 	// Add a doc without "golang" to increase doc count; IDF should increase.
 	oldWeight1 := weight1
-	indexer.AddDocument(lang, map[string]int{"rust": 1}, 1)
-	indices3, values3 := indexer.AddDocument(lang, tf1, len1)
+	_, _, _ = indexer.AddDocument(ctx, "", lang, map[string]int{"rust": 1}, 1)
+	indices3, values3, _ := indexer.AddDocument(ctx, "", lang, tf1, len1)
 
 	for i, idx := range indices3 {
 		if idx == termHash("golang") {
@@ -110,16 +112,16 @@ func TestBM25Indexer_RemoveDocument(t *testing.T) {
 	term := "test"
 
 	tf, docLen, _ := indexer.TermFrequencies(lang, term)
-	indexer.AddDocument(lang, tf, docLen)
+	_, _, _ = indexer.AddDocument(context.Background(), "", lang, tf, docLen)
 
 	indexer.mu.RLock()
-	stats := indexer.stats["en"]
+	stats := indexer.stats["/en"]
 	if stats.DocCount != 1 || stats.DocFreq[term] != 1 {
 		t.Errorf("Expected stats to be updated after add, got count=%d, freq=%d", stats.DocCount, stats.DocFreq[term])
 	}
 	indexer.mu.RUnlock()
 
-	indexer.RemoveDocument(lang, tf, docLen)
+	_ = indexer.RemoveDocument(context.Background(), "", lang, tf, docLen)
 
 	indexer.mu.RLock()
 	if stats.DocCount != 0 || stats.DocFreq[term] != 0 {
@@ -128,6 +130,160 @@ func TestBM25Indexer_RemoveDocument(t *testing.T) {
 	indexer.mu.RUnlock()
 }
 
+func TestBM25Indexer_PerBotStats(t *testing.T) {
+	indexer := NewBM25Indexer(nil)
+	ctx := context.Background()
+	tf := map[string]int{"golang": 1}
+
+	_, _, _ = indexer.AddDocument(ctx, "bot-1", "en", tf, 1)
+	indices, _, err := indexer.BuildQueryVector(ctx, "bot-2", "en", tf)
+	if err != nil {
+		t.Fatalf("BuildQueryVector() error = %v", err)
+	}
+	if len(indices) != 0 {
+		t.Errorf("Expected other bot's corpus to be empty, got %v", indices)
+	}
+	indices, _, _ = indexer.BuildQueryVector(ctx, "bot-1", "en", tf)
+	if len(indices) != 1 {
+		t.Errorf("Expected query vector against own corpus, got %v", indices)
+	}
+}
+
+type fakeBM25StatsStore struct {
+	docCount int
+	docLen   int
+	docFreq  map[string]int
+	terms    [][]string
+	warmed   map[string]bool
+}
+
+func (f *fakeBM25StatsStore) AddDocument(_ context.Context, _, _ string, terms []string, docLen int) (BM25Stats, error) {
+	f.docCount++
+	f.docLen += docLen
+	f.terms = append(f.terms, terms)
+	for _, term := range terms {
+		f.docFreq[term]++
+	}
+	return f.Stats(context.Background(), "", "", terms)
+}
+
+func (f *fakeBM25StatsStore) RemoveDocument(_ context.Context, _, _ string, terms []string, docLen int) error {
+	f.docCount--
+	f.docLen -= docLen
+	for _, term := range terms {
+		f.docFreq[term]--
+	}
+	return nil
+}
+
+func (f *fakeBM25StatsStore) Stats(_ context.Context, _, _ string, terms []string) (BM25Stats, error) {
+	stats := BM25Stats{DocCount: f.docCount, DocFreq: map[string]int{}}
+	if f.docCount > 0 {
+		stats.AvgDocLen = float64(f.docLen) / float64(f.docCount)
+	}
+	for _, term := range terms {
+		if f.docFreq[term] > 0 {
+			stats.DocFreq[term] = f.docFreq[term]
+		}
+	}
+	return stats, nil
+}
+
+func (f *fakeBM25StatsStore) Warmed(_ context.Context, botID, lang string) (bool, error) {
+	return f.warmed[botID+"/"+lang], nil
+}
+
+func (f *fakeBM25StatsStore) ReplaceCorpus(_ context.Context, botID, lang string, corpus BM25Corpus) (bool, error) {
+	if f.warmed[botID+"/"+lang] {
+		return false, nil
+	}
+	if f.warmed == nil {
+		f.warmed = map[string]bool{}
+	}
+	f.warmed[botID+"/"+lang] = true
+	f.docCount = corpus.DocCount
+	f.docLen = int(corpus.TotalDocLen)
+	f.docFreq = corpus.DocFreq
+	return true, nil
+}
+
+func TestBM25Indexer_StatsStore(t *testing.T) {
+	store := &fakeBM25StatsStore{docFreq: map[string]int{}}
+	indexer := NewBM25Indexer(nil)
+	indexer.SetStatsStore(store)
+	ctx := context.Background()
+
+	indices, _, err := indexer.AddDocument(ctx, "bot-1", "EN", map[string]int{"zeta": 1, "alpha": 2}, 3)
+	if err != nil {
+		t.Fatalf("AddDocument() error = %v", err)
+	}
+	if len(indices) != 2 {
+		t.Errorf("Expected doc vector from stored stats, got %v", indices)
+	}
+	if !reflect.DeepEqual(store.terms[0], []string{"alpha", "zeta"}) {
+		t.Errorf("Expected sorted terms, got %v", store.terms[0])
+	}
+	if len(indexer.stats) != 0 {
+		t.Errorf("Expected no in-memory stats with a store, got %d", len(indexer.stats))
+	}
+	if err := indexer.RemoveDocument(ctx, "bot-1", "en", map[string]int{"zeta": 1, "alpha": 2}, 3); err != nil {
+		t.Fatalf("RemoveDocument() error = %v", err)
+	}
+	if store.docCount != 0 || store.docFreq["alpha"] != 0 {
+		t.Errorf("Expected stored stats to be cleared, got count=%d", store.docCount)
+	}
+}
+
+func TestBM25Warmup_PerCorpus(t *testing.T) {
+	store := &fakeBM25StatsStore{docFreq: map[string]int{}, warmed: map[string]bool{"bot-1/en": true}}
+	indexer := NewBM25Indexer(nil)
+	indexer.SetStatsStore(store)
+	ctx := context.Background()
+
+	warmup := indexer.newWarmup()
+	if needed, _ := warmup.needs(ctx, "bot-1", "EN"); needed {
+		t.Errorf("Expected a warmed corpus to be skipped")
+	}
+	if needed, _ := warmup.needs(ctx, "bot-2", "en"); !needed {
+		t.Fatalf("Expected a corpus without marker to need a warmup")
+	}
+	warmup.add("bot-2", "en", map[string]int{"alpha": 2, "beta": 1}, 3)
+	warmup.add("bot-2", "en", map[string]int{"alpha": 1}, 1)
+	rebuilt, err := warmup.commit(ctx)
+	if err != nil || rebuilt != 1 {
+		t.Fatalf("commit() = %d, %v", rebuilt, err)
+	}
+	if store.docCount != 2 || store.docLen != 4 || store.docFreq["alpha"] != 2 || store.docFreq["beta"] != 1 {
+		t.Errorf("Expected replaced stats, got count=%d len=%d freq=%v", store.docCount, store.docLen, store.docFreq)
+	}
+	if rebuilt, _ := warmup.commit(ctx); rebuilt != 0 {
+		t.Errorf("Expected a second rebuild to be skipped, got %d", rebuilt)
+	}
+}
+
+func TestBM25Warmup_ReplacesInMemoryStats(t *testing.T) {
+	indexer := NewBM25Indexer(nil)
+	ctx := context.Background()
+	tf := map[string]int{"golang": 1}
+
+	// A document indexed before the warmup is part of the scanned corpus.
+	_, _, _ = indexer.AddDocument(ctx, "bot-1", "en", tf, 1)
+	warmup := indexer.newWarmup()
+	if needed, _ := warmup.needs(ctx, "bot-1", "en"); !needed {
+		t.Fatalf("Expected in-memory stats to need a warmup")
+	}
+	warmup.add("bot-1", "en", tf, 1)
+	if _, err := warmup.commit(ctx); err != nil {
+		t.Fatalf("commit() error = %v", err)
+	}
+	if stats := indexer.stats["bot-1/en"]; stats.DocCount != 1 || stats.DocFreq["golang"] != 1 {
+		t.Errorf("Expected the document to be counted once, got %+v", stats)
+	}
+	if needed, _ := indexer.newWarmup().needs(ctx, "bot-1", "en"); needed {
+		t.Errorf("Expected no second warmup")
+	}
+}
+
 func TestTermHash_CollisionResistance(t *testing.T) {
 	// Check that different terms get distinct hashes in 20-bit space (no collision in small sample).
 	h1 := termHash("apple")
@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// Payload keys of the memory lifecycle.
+const (
+	payloadImportance     = "importance"
+	payloadAccessCount    = "access_count"
+	payloadLastAccessedAt = "last_accessed_at"
+	payloadExpiresAt      = "expires_at"
+	payloadDecayedAt      = "decayed_at"
+)
+
+const (
+	// DefaultImportance is used for memories without an explicit importance.
+	DefaultImportance = 0.5
+	minImportance     = 0.05
+	// staleImportanceDecay is applied at most once a day to memories that
+	// have not been used for longer than the stale period.
+	staleImportanceDecay = 0.9
+	// recencyHalfLife controls how fast unused memories sink in ranking.
+	recencyHalfLife = 30 * 24 * time.Hour
+	sweepBatchSize  = 256
+)
+
+// SweepPolicy configures the background memory sweeper.
+type SweepPolicy struct {
+	// Interval between sweeps; zero disables the sweeper.
+	Interval time.Duration
+	// StaleAfter is how long a memory may go unused before its importance decays.
+	StaleAfter time.Duration
+}
+
+// DefaultSweepPolicy sweeps hourly and decays memories unused for 30 days.
+var DefaultSweepPolicy = SweepPolicy{
+	Interval:   time.Hour,
+	StaleAfter: 30 * 24 * time.Hour,
+}
+
+// SweepResult reports what one sweep changed.
+type SweepResult struct {
+	Expired int `json:"expired"`
+	Decayed int `json:"decayed"`
+}
+
+// memoryLifecycle carries caller-provided lifecycle attributes for a write.
+type memoryLifecycle struct {
+	Importance *float64
+	// ExpiresAt is an RFC3339 timestamp or a YYYY-MM-DD date.
+	ExpiresAt string
+}
+
+// SetSweepPolicy configures the background sweeper started by RunSweeper.
+func (s *Service) SetSweepPolicy(policy SweepPolicy) {
+	s.sweepPolicy = policy
+}
+
+// RunSweeper expires and down-ranks memories until ctx is cancelled.
+func (s *Service) RunSweeper(ctx context.Context) {
+	if s.store == nil || s.sweepPolicy.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.sweepPolicy.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Sweep(ctx)
+			if err != nil {
+				s.logger.Warn("memory sweep failed", slog.Any("error", err))
+				continue
+			}
+			if result.Expired > 0 || result.Decayed > 0 {
+				s.logger.Info("memory sweep finished", slog.Int("expired", result.Expired), slog.Int("decayed", result.Decayed))
+			}
+		}
+	}
+}
+
+// Sweep deletes expired memories and decays the importance of stale ones.
+func (s *Service) Sweep(ctx context.Context) (SweepResult, error) {
+	if s.store == nil {
+		return SweepResult{}, fmt.Errorf("qdrant store not configured")
+	}
+	now := time.Now().UTC()
+	var result SweepResult
+	for {
+		points, err := s.store.ListExpired(ctx, now, sweepBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(points) == 0 {
+			break
+		}
+		if err := s.store.DeleteBatch(ctx, pointIDs(points)); err != nil {
+			return result, err
+		}
+		s.removeBM25Documents(ctx, points)
+		for _, p := range points {
+			s.recordHistory(WithBotID(ctx, resolveBotID("", p.Payload)), p.ID, HistoryEventDelete, fmt.Sprint(p.Payload["data"]), "", p.Payload, map[string]any{"reason": "expired"})
+		}
+		result.Expired += len(points)
+		if len(points) < sweepBatchSize {
+			break
+		}
+	}
+
+	if s.sweepPolicy.StaleAfter <= 0 {
+		return result, nil
+	}
+	// Qdrant only returns memories due for decay; decayedImportance re-checks
+	// them against the payload.
+	cutoff := now.Add(-s.sweepPolicy.StaleAfter)
+	var offset *qdrant.PointId
+	for {
+		points, next, err := s.store.ListStale(ctx, cutoff, now.Add(-24*time.Hour), sweepBatchSize, offset)
+		if err != nil {
+			return result, err
+		}
+		updates := make(map[string]map[string]any, len(points))
+		for _, p := range points {
+			importance, ok := decayedImportance(p.Payload, now, s.sweepPolicy.StaleAfter)
+			if !ok {
+				continue
+			}
+			updates[p.ID] = map[string]any{
+				payloadImportance: importance,
+				payloadDecayedAt:  now.Format(time.RFC3339),
+			}
+		}
+		if err := s.store.SetPayloads(ctx, updates); err != nil {
+			return result, err
+		}
+		result.Decayed += len(updates)
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+	return result, nil
+}
+
+// decayedImportance returns the lowered importance of a memory unused for
+// longer than staleAfter, at most once a day.
+func decayedImportance(payload map[string]any, now time.Time, staleAfter time.Duration) (float64, bool) {
+	if now.Sub(lastActivity(payload)) < staleAfter {
+		return 0, false
+	}
+	if decayedAt, ok := payloadTime(payload, payloadDecayedAt); ok && now.Sub(decayedAt) < 24*time.Hour {
+		return 0, false
+	}
+	importance := payloadImportanceValue(payload)
+	if importance <= minImportance {
+		return 0, false
+	}
+	return math.Max(minImportance, importance*staleImportanceDecay), true
+}
+
+// recordAccess bumps access statistics of returned memories in the background.
+func (s *Service) recordAccess(ctx context.Context, items []MemoryItem) {
+	if s.store == nil || len(items) == 0 {
+		return
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	updates := make(map[string]map[string]any, len(items))
+	for _, item := range items {
+		updates[item.ID] = map[string]any{
+			payloadAccessCount:    item.AccessCount + 1,
+			payloadLastAccessedAt: now,
+		}
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.store.SetPayloads(ctx, updates); err != nil {
+			s.logger.Warn("record memory access failed", slog.Int("memories", len(updates)), slog.Any("error", err))
+		}
+	}()
+}
+
+// applyLifecycle sets importance and expiry on a payload. Memories always
+// carry an importance so ranking and decay can rely on it.
+func applyLifecycle(payload map[string]any, lifecycle memoryLifecycle) error {
+	if lifecycle.Importance != nil {
+		payload[payloadImportance] = clampImportance(*lifecycle.Importance)
+	} else if _, ok := payload[payloadImportance]; !ok {
+		payload[payloadImportance] = DefaultImportance
+	}
+	if strings.TrimSpace(lifecycle.ExpiresAt) != "" {
+		expiresAt, err := parseExpiresAt(lifecycle.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		payload[payloadExpiresAt] = expiresAt.Format(time.RFC3339)
+	}
+	return nil
+}
+
+// parseExpiresAt accepts an RFC3339 timestamp or a date, which expires at the
+// end of that day (UTC).
+func parseExpiresAt(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t.Add(24*time.Hour - time.Second).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expires_at %q: expected RFC3339 or YYYY-MM-DD", raw)
+}
+
+func clampImportance(value float64) float64 {
+	if math.IsNaN(value) {
+		return DefaultImportance
+	}
+	return math.Min(1, math.Max(0, value))
+}
+
+func payloadImportanceValue(payload map[string]any) float64 {
+	if value, ok := toFloat(payload[payloadImportance]); ok {
+		return clampImportance(value)
+	}
+	return DefaultImportance
+}
+
+func payloadTime(payload map[string]any, key string) (time.Time, bool) {
+	raw, ok := payload[key].(string)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// lastActivity is the latest of creation, update and access time.
+func lastActivity(payload map[string]any) time.Time {
+	var latest time.Time
+	for _, key := range []string{"created_at", "updated_at", payloadLastAccessedAt} {
+		if t, ok := payloadTime(payload, key); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+func isExpired(payload map[string]any, now time.Time) bool {
+	expiresAt, ok := payloadTime(payload, payloadExpiresAt)
+	return ok && !expiresAt.After(now)
+}
+
+// memoryRankWeight scales a fused relevance score by importance and recency.
+// A fresh memory of default importance has weight 1.
+func memoryRankWeight(payload map[string]any, now time.Time) float64 {
+	importance := payloadImportanceValue(payload)
+	recency := 1.0
+	if last := lastActivity(payload); !last.IsZero() && now.After(last) {
+		recency = math.Exp(-math.Ln2 * float64(now.Sub(last)) / float64(recencyHalfLife))
+	}
+	return (0.5 + importance) * (0.5 + 0.5*recency)
+}
+
+func dropExpiredItems(items []MemoryItem, now time.Time) []MemoryItem {
+	kept := items[:0]
+	for _, item := range items {
+		if item.ExpiresAt != "" {
+			if expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt); err == nil && !expiresAt.After(now) {
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiresAt(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseExpiresAt("2026-03-01T10:00:00+02:00")
+	if err != nil {
+		t.Fatalf("parse RFC3339: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	got, err = parseExpiresAt("2026-03-01")
+	if err != nil {
+		t.Fatalf("parse date: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected end of day %s, got %s", want, got)
+	}
+	if _, err := parseExpiresAt("next week"); err == nil {
+		t.Fatalf("expected error for free-form expiry")
+	}
+}
+
+func TestApplyLifecycle(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{}
+	if err := applyLifecycle(payload, memoryLifecycle{}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if payload[payloadImportance] != DefaultImportance {
+		t.Fatalf("expected default importance, got %v", payload[payloadImportance])
+	}
+	if _, ok := payload[payloadExpiresAt]; ok {
+		t.Fatalf("expected no expiry, got %v", payload[payloadExpiresAt])
+	}
+
+	high := 3.0
+	if err := applyLifecycle(payload, memoryLifecycle{Importance: &high, ExpiresAt: "2026-03-01"}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if payload[payloadImportance] != 1.0 {
+		t.Fatalf("expected importance clamped to 1, got %v", payload[payloadImportance])
+	}
+	if payload[payloadExpiresAt] != "2026-03-01T23:59:59Z" {
+		t.Fatalf("unexpected expiry: %v", payload[payloadExpiresAt])
+	}
+	if err := applyLifecycle(payload, memoryLifecycle{ExpiresAt: "soon"}); err == nil {
+		t.Fatalf("expected invalid expiry to fail")
+	}
+}
+
+func TestMemoryRankWeight(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := map[string]any{"created_at": now.Add(-time.Hour).Format(time.RFC3339)}
+	stale := map[string]any{"created_at": now.Add(-180 * 24 * time.Hour).Format(time.RFC3339)}
+	important := map[string]any{"created_at": now.Add(-time.Hour).Format(time.RFC3339), payloadImportance: 0.9}
+	accessed := map[string]any{
+		"created_at":          now.Add(-180 * 24 * time.Hour).Format(time.RFC3339),
+		payloadLastAccessedAt: now.Add(-time.Hour).Format(time.RFC3339),
+	}
+
+	if memoryRankWeight(fresh, now) <= memoryRankWeight(stale, now) {
+		t.Fatalf("expected fresh memory to outrank stale one")
+	}
+	if memoryRankWeight(important, now) <= memoryRankWeight(fresh, now) {
+		t.Fatalf("expected important memory to outrank default one")
+	}
+	if memoryRankWeight(accessed, now) <= memoryRankWeight(stale, now) {
+		t.Fatalf("expected recent access to refresh a memory")
+	}
+}
+
+func TestDecayedImportance(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	staleAfter := 30 * 24 * time.Hour
+	old := now.Add(-60 * 24 * time.Hour).Format(time.RFC3339)
+
+	importance, ok := decayedImportance(map[string]any{"created_at": old, payloadImportance: 0.8}, now, staleAfter)
+	if !ok || importance >= 0.8 {
+		t.Fatalf("expected stale memory to decay, got %v %v", importance, ok)
+	}
+	if _, ok := decayedImportance(map[string]any{"created_at": now.Add(-time.Hour).Format(time.RFC3339)}, now, staleAfter); ok {
+		t.Fatalf("expected recent memory to keep its importance")
+	}
+	recentlyDecayed := map[string]any{"created_at": old, payloadDecayedAt: now.Add(-time.Hour).Format(time.RFC3339)}
+	if _, ok := decayedImportance(recentlyDecayed, now, staleAfter); ok {
+		t.Fatalf("expected decay at most once a day")
+	}
+	if _, ok := decayedImportance(map[string]any{"created_at": old, payloadImportance: minImportance}, now, staleAfter); ok {
+		t.Fatalf("expected importance floor to stop decay")
+	}
+}
+
+func TestFuseByRankFusion_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	created := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	low := qdrantPoint{ID: "low", Payload: map[string]any{"data": "low", "created_at": created, payloadImportance: 0.1}}
+	high := qdrantPoint{ID: "high", Payload: map[string]any{"data": "high", "created_at": created, payloadImportance: 1.0}}
+	expired := qdrantPoint{ID: "expired", Payload: map[string]any{"data": "expired", payloadExpiresAt: "2000-01-01T00:00:00Z"}}
+
+	results := fuseByRankFusion(map[string][]qdrantPoint{"dense": {expired, low, high}}, nil)
+	if len(results) != 2 {
+		t.Fatalf("expected expired memory to be dropped, got %+v", results)
+	}
+	if results[0].ID != "high" {
+		t.Fatalf("expected important memory first, got %s", results[0].ID)
+	}
+	if results[0].Importance != 1.0 {
+		t.Fatalf("expected importance on item, got %v", results[0].Importance)
+	}
+}
+
+func TestDropExpiredItems(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	items := []MemoryItem{
+		{ID: "a"},
+		{ID: "b", ExpiresAt: "2026-05-01T00:00:00Z"},
+		{ID: "c", ExpiresAt: "2026-07-01T00:00:00Z"},
+	}
+	kept := dropExpiredItems(items, now)
+	if len(kept) != 2 || kept[0].ID != "a" || kept[1].ID != "c" {
+		t.Fatalf("unexpected items: %+v", kept)
+	}
+}
+
+func TestMergedLifecycle(t *testing.T) {
+	t.Parallel()
+
+	lifecycle := mergedLifecycle([]MemoryItem{
+		{Importance: 0.3, ExpiresAt: "2026-01-01T00:00:00Z"},
+		{Importance: 0.8, ExpiresAt: "2026-02-01T00:00:00Z"},
+	})
+	if lifecycle.Importance == nil || *lifecycle.Importance != 0.8 {
+		t.Fatalf("expected highest importance, got %v", lifecycle.Importance)
+	}
+	if lifecycle.ExpiresAt != "2026-02-01T00:00:00Z" {
+		t.Fatalf("expected latest expiry, got %q", lifecycle.ExpiresAt)
+	}
+	lifecycle = mergedLifecycle([]MemoryItem{{ExpiresAt: "2026-01-01T00:00:00Z"}, {}})
+	if lifecycle.ExpiresAt != "" {
+		t.Fatalf("expected no expiry when a source never expires, got %q", lifecycle.ExpiresAt)
+	}
+}
@@ -120,12 +120,23 @@ func (c *LLMClient) Decide(ctx context.Context, req DecideRequest) (DecideRespon
 			continue
 		}
 
-		actions = append(actions, DecisionAction{
+		action := DecisionAction{
 			Event:     event,
 			ID:        asString(item["id"]),
 			Text:      text,
 			OldMemory: asString(item["old_memory"]),
-		})
+		}
+		if importance, ok := toFloat(item["importance"]); ok {
+			importance = clampImportance(importance)
+			action.Importance = &importance
+		}
+		// Ignore expiry dates the model got wrong rather than failing the write.
+		if expiresAt := strings.TrimSpace(asString(item["expires_at"])); expiresAt != "" {
+			if _, err := parseExpiresAt(expiresAt); err == nil {
+				action.ExpiresAt = expiresAt
+			}
+		}
+		actions = append(actions, action)
 	}
 	return DecideResponse{Actions: actions}, nil
 }
@@ -185,6 +196,42 @@ func (c *LLMClient) DetectLanguage(ctx context.Context, text string) (string, er
 	return lang, nil
 }
 
+// Rerank judges the relevance of documents to query with the chat model, so
+// a chat model can serve as a bot's rerank model.
+func (c *LLMClient) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	indexed := make([]map[string]any, 0, len(documents))
+	for i, doc := range documents {
+		indexed = append(indexed, map[string]any{"index": i, "text": doc})
+	}
+	systemPrompt, userPrompt := getRerankMessages(query, indexed)
+	content, err := c.callChat(ctx, []chatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Scores []struct {
+			Index int     `json:"index"`
+			Score float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(removeCodeBlocks(content)), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+	scores := make([]float64, len(documents))
+	for _, entry := range parsed.Scores {
+		if entry.Index >= 0 && entry.Index < len(scores) {
+			scores[entry.Index] = clampImportance(entry.Score)
+		}
+	}
+	return scores, nil
+}
+
 type chatMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
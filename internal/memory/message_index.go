@@ -0,0 +1,572 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/memohai/memoh/internal/conversation"
+	"github.com/memohai/memoh/internal/embeddings"
+	"github.com/memohai/memoh/internal/message"
+)
+
+const (
+	// messageCorpusPrefix keeps the BM25 statistics of a bot's message
+	// history apart from the statistics of its memories.
+	messageCorpusPrefix = "messages:"
+	// maxIndexedMessageRunes caps the text embedded per message.
+	maxIndexedMessageRunes = 4000
+	messageIndexBatchSize  = 64
+	defaultMessageLimit    = 5
+	// messageCandidateFactor widens each search leg before fusion.
+	messageCandidateFactor = 4
+	// messageIndexQueueSize bounds the messages waiting to be indexed. When
+	// the queue is full new messages are dropped and left to a backfill.
+	messageIndexQueueSize = 1024
+	messageIndexWorkers   = 2
+	// messageIndexAttempts bounds the tries of a failed embedding or write.
+	messageIndexAttempts   = 3
+	messageIndexRetryDelay = time.Second
+)
+
+// ErrHistoryBackfillRunning is returned when a bot's message history is
+// already being indexed.
+var ErrHistoryBackfillRunning = errors.New("message history indexing is already running")
+
+// MessageSearchRequest searches a bot's indexed message history. Empty
+// filters match everything; zero times leave the range open.
+type MessageSearchRequest struct {
+	BotID                   string
+	Query                   string
+	RouteID                 string
+	SenderChannelIdentityID string
+	Role                    string
+	Since                   time.Time
+	Until                   time.Time
+	Limit                   int
+}
+
+// MessageHit is a past message matching a history search.
+type MessageHit struct {
+	MessageID               string    `json:"message_id"`
+	RouteID                 string    `json:"route_id,omitempty"`
+	SenderChannelIdentityID string    `json:"sender_channel_identity_id,omitempty"`
+	SenderName              string    `json:"sender_name,omitempty"`
+	Role                    string    `json:"role"`
+	Platform                string    `json:"platform,omitempty"`
+	Text                    string    `json:"text"`
+	CreatedAt               time.Time `json:"created_at"`
+	Score                   float64   `json:"score"`
+}
+
+// MessageLister reads a bot's persisted messages to backfill the index.
+type MessageLister interface {
+	List(ctx context.Context, botID string) ([]message.Message, error)
+}
+
+// MessageIndex indexes persisted chat messages with dense and BM25 vectors so
+// bots can recall past conversations. It implements message.Indexer.
+type MessageIndex struct {
+	store      *QdrantStore
+	embedder   embeddings.Embedder
+	vectorName string
+	bm25       *BM25Indexer
+	logger     *slog.Logger
+
+	queue     chan message.Message
+	startOnce sync.Once
+
+	mu        sync.Mutex
+	backfills map[string]struct{}
+}
+
+// NewMessageIndex creates a message history index. embedder may be nil, in
+// which case messages are only searchable by keywords.
+func NewMessageIndex(log *slog.Logger, store *QdrantStore, embedder embeddings.Embedder, vectorName string, bm25 *BM25Indexer) *MessageIndex {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &MessageIndex{
+		store:      store,
+		embedder:   embedder,
+		vectorName: strings.TrimSpace(vectorName),
+		bm25:       bm25,
+		logger:     log.With(slog.String("index", "messages")),
+		queue:      make(chan message.Message, messageIndexQueueSize),
+		backfills:  map[string]struct{}{},
+	}
+}
+
+// MessageText returns the plain text of a stored message content.
+func MessageText(content json.RawMessage) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var msg conversation.ModelMessage
+	if err := json.Unmarshal(content, &msg); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(msg.TextContent())
+}
+
+// IndexMessage queues a persisted message for indexing and returns at once.
+// Failures only make the message unsearchable, so they are logged.
+func (x *MessageIndex) IndexMessage(_ context.Context, msg message.Message) {
+	if x.store == nil {
+		return
+	}
+	x.startOnce.Do(func() {
+		for range messageIndexWorkers {
+			go x.runWorker(context.Background())
+		}
+	})
+	select {
+	case x.queue <- msg:
+	default:
+		x.logger.Warn("message index queue full, message left to backfill", slog.String("message_id", msg.ID), slog.String("bot_id", msg.BotID))
+	}
+}
+
+// runWorker indexes queued messages. Messages waiting behind the first one
+// join its batch, so a burst is embedded with few EmbedBatch calls.
+func (x *MessageIndex) runWorker(ctx context.Context) {
+	for msg := range x.queue {
+		batch := nextMessageBatch(msg, x.queue)
+		if _, err := x.index(ctx, batch); err != nil {
+			x.logger.Warn("index messages failed", slog.Int("messages", len(batch)), slog.Any("error", err))
+		}
+	}
+}
+
+// nextMessageBatch returns first followed by the queued messages, up to
+// messageIndexBatchSize, without waiting for more.
+func nextMessageBatch(first message.Message, queue <-chan message.Message) []message.Message {
+	batch := []message.Message{first}
+	for len(batch) < messageIndexBatchSize {
+		select {
+		case msg := <-queue:
+			batch = append(batch, msg)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// withIndexRetry calls fn up to messageIndexAttempts times, doubling the
+// delay after each failure.
+func withIndexRetry(ctx context.Context, fn func() error) error {
+	err := fn()
+	delay := messageIndexRetryDelay
+	for attempt := 1; err != nil && attempt < messageIndexAttempts; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// DeleteBot removes a bot's messages from the index and its BM25 corpus.
+func (x *MessageIndex) DeleteBot(ctx context.Context, botID string) error {
+	botID = strings.TrimSpace(botID)
+	if x.store == nil || botID == "" {
+		return nil
+	}
+	filters := map[string]any{"bot_id": botID}
+	if x.bm25 != nil {
+		var offset *qdrant.PointId
+		for {
+			points, next, err := x.store.Scroll(ctx, sweepBatchSize, filters, offset)
+			if err != nil {
+				return err
+			}
+			for _, point := range points {
+				x.removeBM25Document(ctx, botID, point.Payload)
+			}
+			if next == nil {
+				break
+			}
+			offset = next
+		}
+	}
+	return x.store.DeleteAll(ctx, filters)
+}
+
+// StartBackfill indexes every persisted message of the bot that is not in
+// the index yet. It runs in the background; only one backfill runs per bot.
+func (x *MessageIndex) StartBackfill(botID string, lister MessageLister) error {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return fmt.Errorf("bot id is required")
+	}
+	if x.store == nil || lister == nil {
+		return fmt.Errorf("message index not configured")
+	}
+	x.mu.Lock()
+	if _, running := x.backfills[botID]; running {
+		x.mu.Unlock()
+		return ErrHistoryBackfillRunning
+	}
+	x.backfills[botID] = struct{}{}
+	x.mu.Unlock()
+
+	go func() {
+		defer func() {
+			x.mu.Lock()
+			delete(x.backfills, botID)
+			x.mu.Unlock()
+		}()
+		indexed, err := x.backfill(context.Background(), botID, lister)
+		if err != nil {
+			x.logger.Warn("message history backfill failed", slog.String("bot_id", botID), slog.Int("indexed", indexed), slog.Any("error", err))
+			return
+		}
+		x.logger.Info("message history backfill finished", slog.String("bot_id", botID), slog.Int("indexed", indexed))
+	}()
+	return nil
+}
+
+func (x *MessageIndex) backfill(ctx context.Context, botID string, lister MessageLister) (int, error) {
+	msgs, err := lister.List(ctx, botID)
+	if err != nil {
+		return 0, err
+	}
+	existing := map[string]struct{}{}
+	var offset *qdrant.PointId
+	for {
+		points, next, err := x.store.Scroll(ctx, sweepBatchSize, map[string]any{"bot_id": botID}, offset)
+		if err != nil {
+			return 0, err
+		}
+		for _, point := range points {
+			existing[point.ID] = struct{}{}
+		}
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+	pending := make([]message.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if _, ok := existing[msg.ID]; !ok {
+			pending = append(pending, msg)
+		}
+	}
+	indexed := 0
+	for start := 0; start < len(pending); start += messageIndexBatchSize {
+		end := min(start+messageIndexBatchSize, len(pending))
+		n, err := x.index(ctx, pending[start:end])
+		indexed += n
+		if err != nil {
+			return indexed, err
+		}
+	}
+	return indexed, nil
+}
+
+// index embeds and stores the indexable messages in msgs and returns how
+// many were written.
+func (x *MessageIndex) index(ctx context.Context, msgs []message.Message) (int, error) {
+	if x.store == nil {
+		return 0, nil
+	}
+	type doc struct {
+		msg  message.Message
+		text string
+		lang string
+	}
+	docs := make([]doc, 0, len(msgs))
+	for _, msg := range msgs {
+		if !indexableRole(msg.Role) || strings.TrimSpace(msg.ID) == "" {
+			continue
+		}
+		text := truncateRunes(MessageText(msg.Content), maxIndexedMessageRunes)
+		if text == "" {
+			continue
+		}
+		docs = append(docs, doc{msg: msg, text: text, lang: fallbackLanguageCode(text)})
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	var vectors [][]float32
+	vectorName, dense := x.denseVector()
+	if dense {
+		inputs := make([]string, len(docs))
+		for i, d := range docs {
+			inputs[i] = d.text
+		}
+		var embedded [][]float32
+		err := withIndexRetry(ctx, func() error {
+			var err error
+			embedded, err = x.embedder.EmbedBatch(ctx, inputs)
+			if err == nil && len(embedded) != len(inputs) {
+				err = fmt.Errorf("embedder returned %d vectors for %d inputs", len(embedded), len(inputs))
+			}
+			return err
+		})
+		if err != nil {
+			x.logger.Warn("embed messages failed, indexing keywords only", slog.Any("error", err))
+		} else {
+			vectors = embedded
+		}
+	}
+
+	points := make([]qdrantPoint, 0, len(docs))
+	for i, d := range docs {
+		point := qdrantPoint{
+			ID:      d.msg.ID,
+			Payload: messagePayload(d.msg, d.text, d.lang),
+		}
+		if vectors != nil && len(vectors[i]) > 0 {
+			point.Vector = vectors[i]
+			point.VectorName = vectorName
+		}
+		if x.bm25 != nil {
+			termFreq, docLen, err := x.bm25.TermFrequencies(d.lang, d.text)
+			if err != nil {
+				x.logger.Warn("bm25 term frequencies failed", slog.String("message_id", d.msg.ID), slog.Any("error", err))
+			} else {
+				indices, values, err := x.bm25.AddDocument(ctx, messageCorpusPrefix+d.msg.BotID, d.lang, termFreq, docLen)
+				if err != nil {
+					return 0, err
+				}
+				point.SparseIndices, point.SparseValues = indices, values
+			}
+		}
+		if len(point.Vector) == 0 && len(point.SparseIndices) == 0 {
+			continue
+		}
+		points = append(points, point)
+	}
+	if err := withIndexRetry(ctx, func() error { return x.store.Upsert(ctx, points) }); err != nil {
+		return 0, err
+	}
+	return len(points), nil
+}
+
+// SearchMessages finds past messages matching the query, fusing dense and
+// keyword results by reciprocal rank.
+func (x *MessageIndex) SearchMessages(ctx context.Context, req MessageSearchRequest) ([]MessageHit, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	botID := strings.TrimSpace(req.BotID)
+	if botID == "" {
+		return nil, fmt.Errorf("bot id is required")
+	}
+	if x.store == nil {
+		return nil, fmt.Errorf("message index not configured")
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultMessageLimit
+	}
+	filters := buildMessageFilters(req)
+	candidates := limit * messageCandidateFactor
+
+	var lists [][]qdrantPoint
+	var errs []error
+	if vectorName, dense := x.denseVector(); dense {
+		vector, err := x.embedder.Embed(ctx, query)
+		if err == nil {
+			var points []qdrantPoint
+			points, _, err = x.store.Search(ctx, vector, candidates, filters, vectorName)
+			lists = append(lists, points)
+		}
+		if err != nil {
+			x.logger.Warn("dense message search failed", slog.String("bot_id", botID), slog.Any("error", err))
+			errs = append(errs, err)
+		}
+	}
+	if x.bm25 != nil {
+		lang := fallbackLanguageCode(query)
+		termFreq, _, err := x.bm25.TermFrequencies(lang, query)
+		if err == nil {
+			var indices []uint32
+			var values []float32
+			indices, values, err = x.bm25.BuildQueryVector(ctx, messageCorpusPrefix+botID, lang, termFreq)
+			if err == nil {
+				var points []qdrantPoint
+				points, _, err = x.store.SearchSparse(ctx, indices, values, candidates, filters, false)
+				lists = append(lists, points)
+			}
+		}
+		if err != nil {
+			x.logger.Warn("keyword message search failed", slog.String("bot_id", botID), slog.Any("error", err))
+			errs = append(errs, err)
+		}
+	}
+	if len(lists) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	hits := fuseMessageHits(lists)
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// denseVector reports the vector name to embed messages into, if the
+// collection has a vector matching the embedder.
+func (x *MessageIndex) denseVector() (string, bool) {
+	if x.embedder == nil || x.store == nil {
+		return "", false
+	}
+	dim, ok := x.store.vectorsSnapshot(x.vectorName)[x.vectorName]
+	if !ok || dim != x.embedder.Dimensions() {
+		return "", false
+	}
+	return x.vectorName, true
+}
+
+func (x *MessageIndex) removeBM25Document(ctx context.Context, botID string, payload map[string]any) {
+	text, _ := payload["data"].(string)
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	lang, _ := payload["lang"].(string)
+	if strings.TrimSpace(lang) == "" {
+		lang = fallbackLanguageCode(text)
+	}
+	termFreq, docLen, err := x.bm25.TermFrequencies(lang, text)
+	if err != nil {
+		x.logger.Warn("bm25 term frequencies failed", slog.String("lang", lang), slog.Any("error", err))
+		return
+	}
+	if err := x.bm25.RemoveDocument(ctx, messageCorpusPrefix+botID, lang, termFreq, docLen); err != nil {
+		x.logger.Warn("bm25 remove document failed", slog.Any("error", err))
+	}
+}
+
+func indexableRole(role string) bool {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "user", "assistant":
+		return true
+	}
+	return false
+}
+
+func messagePayload(msg message.Message, text, lang string) map[string]any {
+	createdAt := msg.CreatedAt.UTC()
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	payload := map[string]any{
+		"bot_id":          msg.BotID,
+		"message_id":      msg.ID,
+		"role":            strings.ToLower(strings.TrimSpace(msg.Role)),
+		"data":            text,
+		"lang":            lang,
+		"created_at":      createdAt.Format(time.RFC3339Nano),
+		"created_at_unix": float64(createdAt.Unix()),
+	}
+	for key, value := range map[string]string{
+		"route_id":                   msg.RouteID,
+		"sender_channel_identity_id": msg.SenderChannelIdentityID,
+		"sender_name":                msg.SenderDisplayName,
+		"platform":                   msg.Platform,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			payload[key] = value
+		}
+	}
+	return payload
+}
+
+func buildMessageFilters(req MessageSearchRequest) map[string]any {
+	filters := map[string]any{"bot_id": strings.TrimSpace(req.BotID)}
+	if routeID := strings.TrimSpace(req.RouteID); routeID != "" {
+		filters["route_id"] = routeID
+	}
+	if senderID := strings.TrimSpace(req.SenderChannelIdentityID); senderID != "" {
+		filters["sender_channel_identity_id"] = senderID
+	}
+	if role := strings.ToLower(strings.TrimSpace(req.Role)); role != "" {
+		filters["role"] = role
+	}
+	if !req.Since.IsZero() || !req.Until.IsZero() {
+		rangeFilter := map[string]any{}
+		if !req.Since.IsZero() {
+			rangeFilter["gte"] = float64(req.Since.Unix())
+		}
+		if !req.Until.IsZero() {
+			rangeFilter["lte"] = float64(req.Until.Unix())
+		}
+		filters["created_at_unix"] = rangeFilter
+	}
+	return filters
+}
+
+// fuseMessageHits merges ranked result lists by reciprocal rank fusion.
+// Ties go to the newer message.
+func fuseMessageHits(lists [][]qdrantPoint) []MessageHit {
+	scores := map[string]float64{}
+	payloads := map[string]map[string]any{}
+	for _, points := range lists {
+		for idx, point := range points {
+			if _, ok := payloads[point.ID]; !ok {
+				payloads[point.ID] = point.Payload
+			}
+			scores[point.ID] += 1.0 / (rrfK + float64(idx+1))
+		}
+	}
+	hits := make([]MessageHit, 0, len(payloads))
+	for id, payload := range payloads {
+		hit := payloadToMessageHit(id, payload)
+		hit.Score = scores[id]
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+	return hits
+}
+
+func payloadToMessageHit(id string, payload map[string]any) MessageHit {
+	str := func(key string) string {
+		value, _ := payload[key].(string)
+		return value
+	}
+	hit := MessageHit{
+		MessageID:               id,
+		RouteID:                 str("route_id"),
+		SenderChannelIdentityID: str("sender_channel_identity_id"),
+		SenderName:              str("sender_name"),
+		Role:                    str("role"),
+		Platform:                str("platform"),
+		Text:                    str("data"),
+	}
+	if createdAt, err := time.Parse(time.RFC3339Nano, str("created_at")); err == nil {
+		hit.CreatedAt = createdAt
+	}
+	return hit
+}
+
+func truncateRunes(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/message"
+)
+
+func TestMessageText(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "string", content: `{"role":"user","content":"  hello there "}`, want: "hello there"},
+		{name: "parts", content: `{"role":"assistant","content":[{"type":"text","text":"first"},{"type":"image","url":"x"},{"type":"text","text":"second"}]}`, want: "first\nsecond"},
+		{name: "tool calls only", content: `{"role":"assistant","tool_calls":[{"id":"1"}]}`, want: ""},
+		{name: "invalid", content: `not json`, want: ""},
+		{name: "empty", content: ``, want: ""},
+	}
+	for _, tc := range cases {
+		if got := MessageText(json.RawMessage(tc.content)); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBuildMessageFilters(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	filters := buildMessageFilters(MessageSearchRequest{
+		BotID:                   "bot-1",
+		RouteID:                 "route-1",
+		SenderChannelIdentityID: "sender-1",
+		Role:                    " User ",
+		Since:                   since,
+	})
+	if filters["bot_id"] != "bot-1" || filters["route_id"] != "route-1" || filters["sender_channel_identity_id"] != "sender-1" || filters["role"] != "user" {
+		t.Fatalf("unexpected filters: %#v", filters)
+	}
+	rangeFilter, ok := filters["created_at_unix"].(map[string]any)
+	if !ok || rangeFilter["gte"] != float64(since.Unix()) {
+		t.Fatalf("expected lower bound on created_at_unix, got %#v", filters["created_at_unix"])
+	}
+	if _, ok := rangeFilter["lte"]; ok {
+		t.Fatal("expected open upper bound")
+	}
+
+	filters = buildMessageFilters(MessageSearchRequest{BotID: "bot-1"})
+	if len(filters) != 1 {
+		t.Fatalf("expected only the bot filter, got %#v", filters)
+	}
+}
+
+func TestFuseMessageHits(t *testing.T) {
+	t.Parallel()
+
+	payload := func(text, createdAt string) map[string]any {
+		return map[string]any{"data": text, "role": "user", "created_at": createdAt}
+	}
+	dense := []qdrantPoint{
+		{ID: "a", Payload: payload("a", "2026-01-01T00:00:00Z")},
+		{ID: "b", Payload: payload("b", "2026-01-02T00:00:00Z")},
+	}
+	sparse := []qdrantPoint{
+		{ID: "c", Payload: payload("c", "2026-01-03T00:00:00Z")},
+		{ID: "b", Payload: payload("b", "2026-01-02T00:00:00Z")},
+	}
+	hits := fuseMessageHits([][]qdrantPoint{dense, sparse})
+	if len(hits) != 3 {
+		t.Fatalf("expected 3 hits, got %d", len(hits))
+	}
+	if hits[0].MessageID != "b" {
+		t.Fatalf("expected message found by both searches first, got %s", hits[0].MessageID)
+	}
+	// a and c tie on rank; the newer message wins.
+	if hits[1].MessageID != "c" || hits[2].MessageID != "a" {
+		t.Fatalf("unexpected order: %s, %s", hits[1].MessageID, hits[2].MessageID)
+	}
+	if hits[1].CreatedAt.IsZero() || hits[1].Text != "c" {
+		t.Fatalf("expected payload fields on hit, got %+v", hits[1])
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	t.Parallel()
+
+	if got := truncateRunes(" 你好世界 ", 2); got != "你好" {
+		t.Fatalf("expected rune-safe truncation, got %q", got)
+	}
+	if got := truncateRunes("short", 10); got != "short" {
+		t.Fatalf("expected text unchanged, got %q", got)
+	}
+}
+
+func TestNextMessageBatch(t *testing.T) {
+	t.Parallel()
+
+	queue := make(chan message.Message, messageIndexBatchSize+10)
+	for i := range messageIndexBatchSize + 10 {
+		queue <- message.Message{ID: fmt.Sprintf("m%d", i)}
+	}
+	batch := nextMessageBatch(message.Message{ID: "first"}, queue)
+	if len(batch) != messageIndexBatchSize || batch[0].ID != "first" || batch[1].ID != "m0" {
+		t.Fatalf("unexpected batch of %d starting %v", len(batch), batch[:2])
+	}
+	if rest := nextMessageBatch(message.Message{ID: "next"}, queue); len(rest) != 12 {
+		t.Fatalf("expected the remaining queued messages, got %d", len(rest))
+	}
+	if alone := nextMessageBatch(message.Message{ID: "alone"}, queue); len(alone) != 1 {
+		t.Fatalf("expected a batch of one from an empty queue, got %d", len(alone))
+	}
+}
+
+func TestWithIndexRetry(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := withIndexRetry(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("expected success on the second attempt, got %v after %d calls", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = withIndexRetry(ctx, func() error {
+		calls++
+		return errors.New("unavailable")
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("expected a cancelled context to stop retries, got %v after %d calls", err, calls)
+	}
+}
@@ -94,6 +94,10 @@ The new retrieved facts are mentioned below. You have to analyze the new retriev
 
 %s
 
+For ADD and UPDATE you may also return:
+- "importance": a number from 0 to 1. Use about 0.9 for identity and long-term preferences, 0.5 for ordinary facts and 0.2 for trivia.
+- "expires_at": a date (YYYY-MM-DD) after which the fact is no longer useful, only if the fact is explicitly temporary (e.g. "remind me about this for the next week"). Today's date is %s. Omit it for lasting facts.
+
 Follow the instruction mentioned below:
 - If the current memory is empty, then you have to add the new retrieved facts to the memory.
 - You should return the updated memory in only JSON format as shown below. The memory key should be the same if no changes are made.
@@ -103,7 +107,7 @@ Follow the instruction mentioned below:
 - DO NOT RETURN ANYTHING ELSE OTHER THAN THE JSON FORMAT.
 - DO NOT ADD ANY ADDITIONAL TEXT OR CODEBLOCK IN THE JSON FIELDS WHICH MAKE IT INVALID SUCH AS "%s" OR "%s".
 
-Do not return anything except the JSON format.`, toJSON(retrievedOldMemory), toJSON(newRetrievedFacts), "```json", "```")
+Do not return anything except the JSON format.`, toJSON(retrievedOldMemory), toJSON(newRetrievedFacts), time.Now().UTC().Format("2006-01-02"), "```json", "```")
 }
 
 func getCompactMemoryMessages(memories []map[string]string, targetCount int, decayDays int) (string, string) {
@@ -126,7 +130,7 @@ Guidelines:
 4. Each output fact should be a single, self-contained statement.
 5. Target approximately %d output facts (but use fewer if the information naturally consolidates to less, and never produce more than the input count).
 6. Keep the same language as the original memories. Do not translate.
-7. Return a JSON object with a single key "facts" containing an array of strings.
+7. Return a JSON object with a single key "facts" containing an array of objects, each with "text" (the fact) and "source_ids" (the ids of every input memory merged into it).
 8. DO NOT RETURN ANYTHING ELSE OTHER THAN THE JSON FORMAT.
 9. DO NOT ADD ANY ADDITIONAL TEXT OR CODEBLOCK IN THE JSON FIELDS WHICH MAKE IT INVALID SUCH AS "%s" OR "%s".%s
 
@@ -135,13 +139,26 @@ Input memories:
 [{"id":"1","text":"User likes dark mode","created_at":"2026-01-01"},{"id":"2","text":"User prefers dark theme for all apps","created_at":"2026-02-10"},{"id":"3","text":"User is a software engineer","created_at":"2026-01-15"},{"id":"4","text":"User works as a developer","created_at":"2026-02-01"}]
 Target: 2
 
-Output: {"facts": ["User prefers dark theme for all apps", "User is a software engineer"]}
+Output: {"facts": [{"text": "User prefers dark theme for all apps", "source_ids": ["1", "2"]}, {"text": "User is a software engineer", "source_ids": ["3", "4"]}]}
 `, targetCount, "```json", "```", decayInstruction)
 
 	userPrompt := fmt.Sprintf("Consolidate the following memories into approximately %d concise facts:\n\n%s", targetCount, toJSON(memories))
 	return systemPrompt, userPrompt
 }
 
+func getRerankMessages(query string, documents []map[string]any) (string, string) {
+	systemPrompt := `You are a relevance judge for a memory search engine.
+Given a query and a list of memories, rate how useful each memory is for answering the query.
+
+Guidelines:
+1. Score every memory from 0.0 (irrelevant) to 1.0 (directly answers the query).
+2. Judge meaning, not word overlap: a memory that implies the answer is relevant even if it shares no words with the query.
+3. Return a JSON object with a single key "scores" containing an array of objects with "index" (the memory index) and "score".
+4. Include every memory exactly once. Do not return anything except the JSON format.`
+	userPrompt := fmt.Sprintf("Query:\n%s\n\nMemories:\n%s", query, toJSON(documents))
+	return systemPrompt, userPrompt
+}
+
 func getLanguageDetectionMessages(text string) (string, string) {
 	systemPrompt := `You are a language classifier for the given input text.
 Return a JSON object with a single key "language" whose value is one of the allowed codes.
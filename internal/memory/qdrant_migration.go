@@ -0,0 +1,363 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// collectionName is the name point operations address: the active alias
+// when the store follows it, else the concrete collection.
+func (s *QdrantStore) collectionName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.followAlias {
+		return s.activeAlias()
+	}
+	return s.collection
+}
+
+// activeCollection is the concrete collection the store's schema describes.
+// Schema changes and collection management use it instead of the alias.
+func (s *QdrantStore) activeCollection() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.collection
+}
+
+func (s *QdrantStore) hasNamedVectors() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usesNamedVectors
+}
+
+// vectorSize reports the dimension of a named dense vector in the active collection.
+func (s *QdrantStore) vectorSize(name string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dim, ok := s.vectorNames[name]
+	return dim, ok
+}
+
+// vectorsSnapshot returns a copy of the active dense vector schema. Legacy
+// collections with a single unnamed vector report it under legacyName.
+func (s *QdrantStore) vectorsSnapshot(legacyName string) map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]int, len(s.vectorNames)+1)
+	if !s.usesNamedVectors {
+		if s.dimension > 0 && legacyName != "" {
+			out[legacyName] = s.dimension
+		}
+		return out
+	}
+	for name, dim := range s.vectorNames {
+		out[name] = dim
+	}
+	return out
+}
+
+func (s *QdrantStore) activeAlias() string {
+	return s.baseCollection + activeAliasSuffix
+}
+
+// resolveActiveAlias points the store at the collection a previous
+// re-embedding migration switched to, if any.
+func (s *QdrantStore) resolveActiveAlias(ctx context.Context) error {
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return err
+	}
+	alias := s.activeAlias()
+	for _, item := range aliases {
+		if item.GetAliasName() == alias && item.GetCollectionName() != "" {
+			s.collection = item.GetCollectionName()
+			s.dimension = 0
+			return nil
+		}
+	}
+	return nil
+}
+
+// ensureActiveAlias points the active alias at the store's collection when
+// no migration created it yet, and makes the store address the alias. Stores
+// keep addressing the collection directly when the alias cannot be created.
+func (s *QdrantStore) ensureActiveAlias(ctx context.Context) {
+	if s.client == nil {
+		return
+	}
+	alias := s.activeAlias()
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		s.logger.Warn("list memory collection aliases failed", slog.Any("error", err))
+		return
+	}
+	for _, item := range aliases {
+		if item.GetAliasName() == alias {
+			s.mu.Lock()
+			s.followAlias = true
+			s.mu.Unlock()
+			return
+		}
+	}
+	err = s.client.UpdateAliases(ctx, []*qdrant.AliasOperations{qdrant.NewAliasCreate(alias, s.activeCollection())})
+	if err != nil && status.Code(err) != codes.AlreadyExists && !strings.Contains(strings.ToLower(err.Error()), "already exists") {
+		s.logger.Warn("create memory collection alias failed", slog.String("alias", alias), slog.Any("error", err))
+		return
+	}
+	s.mu.Lock()
+	s.followAlias = true
+	s.mu.Unlock()
+}
+
+// newMigrationTarget creates a fresh collection with the given dense vectors
+// that shares the client and sparse settings of s.
+func (s *QdrantStore) newMigrationTarget(ctx context.Context, vectors map[string]int) (*QdrantStore, error) {
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("vectors map is required")
+	}
+	target := &QdrantStore{
+		client:            s.client,
+		baseCollection:    s.baseCollection,
+		collection:        fmt.Sprintf("%s_v%s", s.baseCollection, time.Now().UTC().Format("20060102150405")),
+		baseURL:           s.baseURL,
+		apiKey:            s.apiKey,
+		timeout:           s.timeout,
+		logger:            s.logger,
+		vectorNames:       vectors,
+		usesNamedVectors:  true,
+		sparseVectorName:  s.sparseVectorName,
+		usesSparseVectors: s.usesSparseVectors,
+	}
+	if err := target.ensureCollection(ctx, vectors); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// scrollVectors pages through the collection like Scroll but also returns
+// every stored vector in NamedVectors, so points can be copied verbatim.
+// A legacy unnamed dense vector is returned under legacyName.
+func (s *QdrantStore) scrollVectors(ctx context.Context, limit int, offset *qdrant.PointId, legacyName string) ([]qdrantPoint, *qdrant.PointId, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	points, nextOffset, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.collectionName(),
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		Offset:         offset,
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(true),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]qdrantPoint, 0, len(points))
+	for _, point := range points {
+		p := qdrantPoint{
+			ID:           pointIDToString(point.GetId()),
+			Payload:      valueMapToInterface(point.GetPayload()),
+			NamedVectors: map[string]*qdrant.Vector{},
+		}
+		vectors := point.GetVectors()
+		if named := vectors.GetVectors(); named != nil {
+			for name, out := range named.GetVectors() {
+				if vector := vectorFromOutput(out); vector != nil {
+					p.NamedVectors[name] = vector
+				}
+			}
+		} else if out := vectors.GetVector(); out != nil && legacyName != "" {
+			if vector := vectorFromOutput(out); vector != nil {
+				p.NamedVectors[legacyName] = vector
+			}
+		}
+		result = append(result, p)
+	}
+	return result, nextOffset, nil
+}
+
+func vectorFromOutput(out *qdrant.VectorOutput) *qdrant.Vector {
+	if out == nil {
+		return nil
+	}
+	if indices, values := extractSparseFromVectorOutput(out); len(indices) > 0 {
+		return qdrant.NewVectorSparse(indices, values)
+	}
+	if dense := out.GetDense(); dense != nil && len(dense.GetData()) > 0 {
+		return qdrant.NewVectorDense(dense.GetData())
+	}
+	if data := out.GetData(); len(data) > 0 {
+		return qdrant.NewVectorDense(data)
+	}
+	return nil
+}
+
+// updateVectors sets one named dense vector on existing points without
+// touching their payload or other vectors.
+func (s *QdrantStore) updateVectors(ctx context.Context, vectorName string, vectors map[string][]float32) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	points := make([]*qdrant.PointVectors, 0, len(vectors))
+	for id, vector := range vectors {
+		points = append(points, &qdrant.PointVectors{
+			Id: qdrant.NewIDUUID(id),
+			Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+				vectorName: qdrant.NewVectorDense(vector),
+			}),
+		})
+	}
+	_, err := s.client.UpdateVectors(ctx, &qdrant.UpdatePointVectors{
+		CollectionName: s.collectionName(),
+		Wait:           qdrant.PtrOf(true),
+		Points:         points,
+	})
+	return err
+}
+
+// switchTo atomically repoints the active alias at target and adopts its
+// collection and schema. It returns the collection that was active before;
+// the reembed janitor drops it after reembedCollectionGrace.
+func (s *QdrantStore) switchTo(ctx context.Context, target *QdrantStore) (string, error) {
+	alias := s.activeAlias()
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return "", err
+	}
+	ops := make([]*qdrant.AliasOperations, 0, 2)
+	for _, item := range aliases {
+		if item.GetAliasName() == alias {
+			ops = append(ops, qdrant.NewAliasDelete(alias))
+			break
+		}
+	}
+	ops = append(ops, qdrant.NewAliasCreate(alias, target.activeCollection()))
+	if err := s.client.UpdateAliases(ctx, ops); err != nil {
+		return "", err
+	}
+	previous := s.adopt(target)
+	s.logger.Info("memory collection switched",
+		slog.String("alias", alias), slog.String("collection", target.activeCollection()), slog.String("previous", previous))
+	return previous, nil
+}
+
+// reloadActive adopts the collection behind the active alias when another
+// instance switched it.
+func (s *QdrantStore) reloadActive(ctx context.Context) error {
+	if s.client == nil {
+		return nil
+	}
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return err
+	}
+	alias := s.activeAlias()
+	collection := ""
+	for _, item := range aliases {
+		if item.GetAliasName() == alias {
+			collection = item.GetCollectionName()
+			break
+		}
+	}
+	if collection == "" || collection == s.activeCollection() {
+		return nil
+	}
+	probe := &QdrantStore{
+		client:           s.client,
+		collection:       collection,
+		logger:           s.logger,
+		sparseVectorName: s.sparseVectorName,
+	}
+	if err := probe.refreshCollectionSchema(ctx, nil); err != nil {
+		return err
+	}
+	s.adopt(probe)
+	return nil
+}
+
+// adopt makes other's collection and dense schema the active ones and
+// returns the previously active collection.
+func (s *QdrantStore) adopt(other *QdrantStore) string {
+	other.mu.RLock()
+	collection := other.collection
+	named := other.usesNamedVectors
+	dimension := other.dimension
+	vectors := make(map[string]int, len(other.vectorNames))
+	for name, dim := range other.vectorNames {
+		vectors[name] = dim
+	}
+	other.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.collection
+	s.collection = collection
+	s.usesNamedVectors = named
+	s.vectorNames = vectors
+	s.dimension = dimension
+	return previous
+}
+
+// pinned returns a store bound to the currently active collection that does
+// not follow later switches.
+func (s *QdrantStore) pinned() *QdrantStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vectors := make(map[string]int, len(s.vectorNames))
+	for name, dim := range s.vectorNames {
+		vectors[name] = dim
+	}
+	return &QdrantStore{
+		client:            s.client,
+		baseCollection:    s.baseCollection,
+		collection:        s.collection,
+		dimension:         s.dimension,
+		baseURL:           s.baseURL,
+		apiKey:            s.apiKey,
+		timeout:           s.timeout,
+		logger:            s.logger,
+		vectorNames:       vectors,
+		usesNamedVectors:  s.usesNamedVectors,
+		sparseVectorName:  s.sparseVectorName,
+		usesSparseVectors: s.usesSparseVectors,
+	}
+}
+
+// dropCollection deletes the store's collection. It is used to clean up a
+// migration target that was never switched to.
+func (s *QdrantStore) dropCollection(ctx context.Context) error {
+	return s.client.DeleteCollection(ctx, s.activeCollection())
+}
+
+// dropInactiveCollection deletes collection unless the active alias points
+// at it. A collection that no longer exists is not an error.
+func (s *QdrantStore) dropInactiveCollection(ctx context.Context, collection string) error {
+	if collection == "" {
+		return nil
+	}
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return err
+	}
+	alias := s.activeAlias()
+	for _, item := range aliases {
+		if item.GetAliasName() == alias && item.GetCollectionName() == collection {
+			return nil
+		}
+	}
+	exists, err := s.client.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return err
+	}
+	if err := s.client.DeleteCollection(ctx, collection); err != nil {
+		return err
+	}
+	s.logger.Info("memory collection dropped", slog.String("collection", collection))
+	return nil
+}
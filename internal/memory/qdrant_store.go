@@ -7,30 +7,44 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/qdrant/go-client/qdrant"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	sparseHashVectorName  = "sparse_hash"
 	sparseVocabVectorName = "sparse_vocab"
+	// activeAliasSuffix names the alias that points at the collection a
+	// re-embedding migration switched to, e.g. "memory_active".
+	activeAliasSuffix = "_active"
 )
 
 type QdrantStore struct {
-	client            *qdrant.Client
+	client         *qdrant.Client
+	baseCollection string
+	baseURL        string
+	apiKey         string
+	timeout        time.Duration
+	logger         *slog.Logger
+
+	// mu guards the collection and its dense vector schema, which change
+	// when a re-embedding migration switches collections.
+	mu                sync.RWMutex
 	collection        string
 	dimension         int
-	baseURL           string
-	apiKey            string
-	timeout           time.Duration
-	logger            *slog.Logger
 	vectorNames       map[string]int
 	usesNamedVectors  bool
 	sparseVectorName  string
 	usesSparseVectors bool
+	// followAlias makes point operations address the active alias, so
+	// writes land in the collection a migration on another instance
+	// switched to before this instance reloads its schema.
+	followAlias bool
 }
 
 type qdrantPoint struct {
@@ -41,6 +55,11 @@ type qdrantPoint struct {
 	SparseValues     []float32      `json:"sparse_values,omitempty"`
 	SparseVectorName string         `json:"sparse_vector_name,omitempty"`
 	Payload          map[string]any `json:"payload,omitempty"`
+	// NamedVectors carries vectors copied verbatim from another collection.
+	NamedVectors map[string]*qdrant.Vector `json:"-"`
+	// DenseVectors snapshots the named dense vectors of a stored point, so
+	// it can be written back without re-embedding.
+	DenseVectors map[string][]float32 `json:"dense_vectors,omitempty"`
 }
 
 func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimension int, sparseVectorName string, timeout time.Duration) (*QdrantStore, error) {
@@ -71,6 +90,7 @@ func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimens
 
 	store := &QdrantStore{
 		client:            client,
+		baseCollection:    collection,
 		collection:        collection,
 		dimension:         dimension,
 		baseURL:           baseURL,
@@ -83,9 +103,13 @@ func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimens
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutOrDefault(timeout))
 	defer cancel()
+	if err := store.resolveActiveAlias(ctx); err != nil {
+		return nil, err
+	}
 	if err := store.ensureCollection(ctx, nil); err != nil {
 		return nil, err
 	}
+	store.ensureActiveAlias(ctx)
 	return store, nil
 }
 
@@ -121,6 +145,7 @@ func NewQdrantStoreWithVectors(log *slog.Logger, baseURL, apiKey, collection str
 
 	store := &QdrantStore{
 		client:            client,
+		baseCollection:    collection,
 		collection:        collection,
 		baseURL:           baseURL,
 		apiKey:            apiKey,
@@ -134,9 +159,13 @@ func NewQdrantStoreWithVectors(log *slog.Logger, baseURL, apiKey, collection str
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutOrDefault(timeout))
 	defer cancel()
+	if err := store.resolveActiveAlias(ctx); err != nil {
+		return nil, err
+	}
 	if err := store.ensureCollection(ctx, vectors); err != nil {
 		return nil, err
 	}
+	store.ensureActiveAlias(ctx)
 	return store, nil
 }
 
@@ -144,6 +173,7 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 	if len(points) == 0 {
 		return nil
 	}
+	namedVectors := s.hasNamedVectors()
 	qPoints := make([]*qdrant.PointStruct, 0, len(points))
 	for _, point := range points {
 		payload, err := qdrant.TryValueMap(point.Payload)
@@ -152,10 +182,16 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 		}
 		var vectors *qdrant.Vectors
 		vectorMap := map[string]*qdrant.Vector{}
+		for name, vector := range point.NamedVectors {
+			vectorMap[name] = vector
+		}
+		for name, vector := range point.DenseVectors {
+			vectorMap[name] = qdrant.NewVectorDense(vector)
+		}
 		if len(point.Vector) > 0 {
-			if point.VectorName != "" && s.usesNamedVectors {
+			if point.VectorName != "" && namedVectors {
 				vectorMap[point.VectorName] = qdrant.NewVectorDense(point.Vector)
-			} else if !s.usesNamedVectors && len(point.SparseIndices) == 0 {
+			} else if !namedVectors && len(point.SparseIndices) == 0 && len(vectorMap) == 0 {
 				vectors = qdrant.NewVectorsDense(point.Vector)
 			} else if point.VectorName != "" {
 				vectorMap[point.VectorName] = qdrant.NewVectorDense(point.Vector)
@@ -184,7 +220,7 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 		})
 	}
 	_, err := s.client.Upsert(ctx, &qdrant.UpsertPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Wait:           qdrant.PtrOf(true),
 		Points:         qPoints,
 	})
@@ -197,11 +233,11 @@ func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, f
 	}
 	filter := buildQdrantFilter(filters)
 	var using *string
-	if vectorName != "" && s.usesNamedVectors {
+	if vectorName != "" && s.hasNamedVectors() {
 		using = qdrant.PtrOf(vectorName)
 	}
 	results, err := s.client.Query(ctx, &qdrant.QueryPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Query:          qdrant.NewQueryDense(vector),
 		Using:          using,
 		Limit:          qdrant.PtrOf(uint64(limit)),
@@ -237,7 +273,7 @@ func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values
 	filter := buildQdrantFilter(filters)
 	using := qdrant.PtrOf(s.sparseVectorName)
 	query := &qdrant.QueryPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Query:          qdrant.NewQuerySparse(indices, values),
 		Using:          using,
 		Limit:          qdrant.PtrOf(uint64(limit)),
@@ -311,7 +347,7 @@ func (s *QdrantStore) SearchSparseBySources(ctx context.Context, indices []uint3
 
 func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error) {
 	result, err := s.client.Get(ctx, &qdrant.GetPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Ids:            []*qdrant.PointId{qdrant.NewIDUUID(id)},
 		WithPayload:    qdrant.NewWithPayload(true),
 	})
@@ -328,9 +364,66 @@ func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error)
 	}, nil
 }
 
+// snapshotDenseVectors loads the dense vectors of points into Vector (legacy
+// unnamed vector) or DenseVectors (named vectors). Sparse vectors are skipped;
+// they are rebuilt from the BM25 index.
+func (s *QdrantStore) snapshotDenseVectors(ctx context.Context, points []qdrantPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	ids := make([]*qdrant.PointId, 0, len(points))
+	for _, point := range points {
+		ids = append(ids, qdrant.NewIDUUID(point.ID))
+	}
+	result, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName(),
+		Ids:            ids,
+		WithVectors:    qdrant.NewWithVectors(true),
+	})
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*qdrant.VectorsOutput, len(result))
+	for _, point := range result {
+		byID[pointIDToString(point.GetId())] = point.GetVectors()
+	}
+	for i := range points {
+		vectors := byID[points[i].ID]
+		if vectors == nil {
+			continue
+		}
+		if named := vectors.GetVectors(); named != nil {
+			for name, out := range named.GetVectors() {
+				if dense := denseFromVectorOutput(out); len(dense) > 0 {
+					if points[i].DenseVectors == nil {
+						points[i].DenseVectors = map[string][]float32{}
+					}
+					points[i].DenseVectors[name] = dense
+				}
+			}
+		} else if dense := denseFromVectorOutput(vectors.GetVector()); len(dense) > 0 {
+			points[i].Vector = dense
+		}
+	}
+	return nil
+}
+
+func denseFromVectorOutput(out *qdrant.VectorOutput) []float32 {
+	if out == nil {
+		return nil
+	}
+	if indices, _ := extractSparseFromVectorOutput(out); len(indices) > 0 {
+		return nil
+	}
+	if dense := out.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	return out.GetData()
+}
+
 func (s *QdrantStore) Delete(ctx context.Context, id string) error {
 	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Wait:           qdrant.PtrOf(true),
 		Points:         qdrant.NewPointsSelectorIDs([]*qdrant.PointId{qdrant.NewIDUUID(id)}),
 	})
@@ -346,7 +439,7 @@ func (s *QdrantStore) DeleteBatch(ctx context.Context, ids []string) error {
 		pointIDs = append(pointIDs, qdrant.NewIDUUID(id))
 	}
 	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Wait:           qdrant.PtrOf(true),
 		Points:         qdrant.NewPointsSelectorIDs(pointIDs),
 	})
@@ -359,7 +452,7 @@ func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]an
 	}
 	filter := buildQdrantFilter(filters)
 	scroll := &qdrant.ScrollPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Limit:          qdrant.PtrOf(uint32(limit)),
 		Filter:         filter,
 		WithPayload:    qdrant.NewWithPayload(true),
@@ -392,7 +485,7 @@ func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]
 	}
 	filter := buildQdrantFilter(filters)
 	points, nextOffset, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Limit:          qdrant.PtrOf(uint32(limit)),
 		Filter:         filter,
 		Offset:         offset,
@@ -411,6 +504,106 @@ func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]
 	return result, nextOffset, nil
 }
 
+// SetPayloads merges per-point fields into the payloads of many points in
+// one request.
+func (s *QdrantStore) SetPayloads(ctx context.Context, updates map[string]map[string]any) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	ops := make([]*qdrant.PointsUpdateOperation, 0, len(updates))
+	for id, fields := range updates {
+		payload, err := qdrant.TryValueMap(fields)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, qdrant.NewPointsUpdateSetPayload(&qdrant.PointsUpdateOperation_SetPayload{
+			Payload:        payload,
+			PointsSelector: qdrant.NewPointsSelector(qdrant.NewIDUUID(id)),
+		}))
+	}
+	_, err := s.client.UpdateBatch(ctx, &qdrant.UpdateBatchPoints{
+		CollectionName: s.collectionName(),
+		Wait:           qdrant.PtrOf(true),
+		Operations:     ops,
+	})
+	return err
+}
+
+// ListStale returns up to limit points without any activity since cutoff
+// that were not decayed since decayedBefore and can still lose importance.
+func (s *QdrantStore) ListStale(ctx context.Context, cutoff, decayedBefore time.Time, limit int, offset *qdrant.PointId) ([]qdrantPoint, *qdrant.PointId, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	beforeOrMissing := func(field string, before time.Time) *qdrant.Condition {
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{
+			Should: []*qdrant.Condition{
+				qdrant.NewDatetimeRange(field, &qdrant.DatetimeRange{Lt: timestamppb.New(before)}),
+				qdrant.NewIsEmpty(field),
+			},
+		})
+	}
+	points, nextOffset, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.collectionName(),
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		Offset:         offset,
+		Filter: &qdrant.Filter{
+			Must: []*qdrant.Condition{
+				beforeOrMissing("created_at", cutoff),
+				beforeOrMissing("updated_at", cutoff),
+				beforeOrMissing(payloadLastAccessedAt, cutoff),
+				beforeOrMissing(payloadDecayedAt, decayedBefore),
+				qdrant.NewFilterAsCondition(&qdrant.Filter{
+					Should: []*qdrant.Condition{
+						qdrant.NewRange(payloadImportance, &qdrant.Range{Gt: qdrant.PtrOf(minImportance)}),
+						qdrant.NewIsEmpty(payloadImportance),
+					},
+				}),
+			},
+		},
+		WithPayload: qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]qdrantPoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, qdrantPoint{
+			ID:      pointIDToString(point.GetId()),
+			Payload: valueMapToInterface(point.GetPayload()),
+		})
+	}
+	return result, nextOffset, nil
+}
+
+// ListExpired returns up to limit points whose expires_at is before the given time.
+func (s *QdrantStore) ListExpired(ctx context.Context, before time.Time, limit int) ([]qdrantPoint, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	points, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.collectionName(),
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		Filter: &qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewDatetimeRange("expires_at", &qdrant.DatetimeRange{Lt: timestamppb.New(before)}),
+			},
+		},
+		WithPayload: qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]qdrantPoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, qdrantPoint{
+			ID:      pointIDToString(point.GetId()),
+			Payload: valueMapToInterface(point.GetPayload()),
+		})
+	}
+	return result, nil
+}
+
 // extractSparseVector extracts sparse indices and values from a VectorsOutput.
 // It handles both the new oneof format (GetSparse) and the deprecated flat fields
 // (GetIndices + GetData) for backward compatibility with older Qdrant servers.
@@ -447,7 +640,7 @@ func extractSparseFromVectorOutput(vecOut *qdrant.VectorOutput) ([]uint32, []flo
 func (s *QdrantStore) Count(ctx context.Context, filters map[string]any) (uint64, error) {
 	filter := buildQdrantFilter(filters)
 	result, err := s.client.Count(ctx, &qdrant.CountPoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Filter:         filter,
 		Exact:          qdrant.PtrOf(true),
 	})
@@ -463,7 +656,7 @@ func (s *QdrantStore) DeleteAll(ctx context.Context, filters map[string]any) err
 		return fmt.Errorf("delete all requires filters")
 	}
 	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
-		CollectionName: s.collection,
+		CollectionName: s.collectionName(),
 		Wait:           qdrant.PtrOf(true),
 		Points:         qdrant.NewPointsSelectorFilter(filter),
 	})
@@ -505,7 +698,7 @@ func (s *QdrantStore) ensureCollection(ctx context.Context, vectors map[string]i
 		})
 	}
 	if err := s.client.CreateCollection(ctx, &qdrant.CreateCollection{
-		CollectionName:      s.collection,
+		CollectionName:      s.activeCollection(),
 		VectorsConfig:       vectorsConfig,
 		SparseVectorsConfig: sparseConfig,
 	}); err != nil {
@@ -533,13 +726,14 @@ func (s *QdrantStore) refreshCollectionSchema(ctx context.Context, vectors map[s
 				s.vectorNames[name] = int(vec.GetSize())
 			}
 		}
-		if len(vectors) > 0 {
-			for name, dim := range vectors {
-				if existing, ok := s.vectorNames[name]; ok && existing == dim {
-					continue
-				}
-				return fmt.Errorf("collection missing vector %s (dim %d); migration required", name, dim)
+		// Models added after the collection was created have no vector yet;
+		// a re-embedding job migrates the collection when a bot needs one.
+		for name, dim := range vectors {
+			if existing, ok := s.vectorNames[name]; ok && existing == dim {
+				continue
 			}
+			s.logger.Warn("qdrant collection is missing an embedding vector; run a memory re-embedding job to add it",
+				slog.String("collection", s.collection), slog.String("vector", name), slog.Int("dimensions", dim))
 		}
 	} else {
 		s.usesNamedVectors = false
@@ -581,13 +775,26 @@ func (s *QdrantStore) ensurePayloadIndexes(ctx context.Context) error {
 	if s.client == nil {
 		return nil
 	}
-	fields := []string{"bot_id", "run_id"}
+	fields := []struct {
+		name      string
+		fieldType qdrant.FieldType
+	}{
+		{"bot_id", qdrant.FieldType_FieldTypeKeyword},
+		{"run_id", qdrant.FieldType_FieldTypeKeyword},
+		{"expires_at", qdrant.FieldType_FieldTypeDatetime},
+		// Used by the sweeper to find stale memories.
+		{"created_at", qdrant.FieldType_FieldTypeDatetime},
+		{"updated_at", qdrant.FieldType_FieldTypeDatetime},
+		{payloadLastAccessedAt, qdrant.FieldType_FieldTypeDatetime},
+		{payloadDecayedAt, qdrant.FieldType_FieldTypeDatetime},
+		{payloadImportance, qdrant.FieldType_FieldTypeFloat},
+	}
 	wait := true
 	for _, field := range fields {
 		_, err := s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: s.collection,
-			FieldName:      field,
-			FieldType:      qdrant.FieldType_FieldTypeKeyword.Enum(),
+			CollectionName: s.activeCollection(),
+			FieldName:      field.name,
+			FieldType:      field.fieldType.Enum(),
 			Wait:           &wait,
 		})
 		if err == nil {
@@ -610,7 +817,7 @@ func (s *QdrantStore) ensureSparseVectors(ctx context.Context) error {
 		return nil
 	}
 	err := s.client.UpdateCollection(ctx, &qdrant.UpdateCollection{
-		CollectionName: s.collection,
+		CollectionName: s.activeCollection(),
 		SparseVectorsConfig: qdrant.NewSparseVectorsConfig(map[string]*qdrant.SparseVectorParams{
 			s.sparseVectorName:    {Modifier: qdrant.PtrOf(qdrant.Modifier_None)},
 			sparseVocabVectorName: {Modifier: qdrant.PtrOf(qdrant.Modifier_None)},
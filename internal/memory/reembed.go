@@ -0,0 +1,682 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/memohai/memoh/internal/embeddings"
+)
+
+const (
+	// reembedJobRetention is how long finished re-embedding jobs can still be queried.
+	reembedJobRetention = 24 * time.Hour
+	// reembedPageSize is how many points are read and embedded per round.
+	reembedPageSize = 128
+	// reembedReconcilePasses bounds the catch-up passes for memories written
+	// while a job runs.
+	reembedReconcilePasses = 3
+	// maxReembedErrors caps the error samples kept on a job.
+	maxReembedErrors = 10
+	// reembedHeartbeatInterval is how often a running job reports that its
+	// worker is alive.
+	reembedHeartbeatInterval = time.Minute
+	// reembedStaleAfter is how long a running job may go without a heartbeat
+	// before the janitor fails it and drops its target collection.
+	reembedStaleAfter = 10 * time.Minute
+	// reembedCollectionGrace is how long the collection a migration switched
+	// away from is kept, so a bad switch can still be rolled back by hand.
+	reembedCollectionGrace = 24 * time.Hour
+	// reembedJanitorInterval is how often abandoned jobs and unused
+	// collections are looked for.
+	reembedJanitorInterval = 5 * time.Minute
+)
+
+var (
+	// ErrReembedNotFound indicates the re-embedding job does not exist, has
+	// expired or belongs to another bot.
+	ErrReembedNotFound = errors.New("re-embedding job not found")
+	// ErrReembedRunning indicates the bot already has a running re-embedding job.
+	ErrReembedRunning = errors.New("re-embedding job already running")
+	// ErrEmbeddingModelNotConfigured indicates the bot has no embedding model.
+	ErrEmbeddingModelNotConfigured = errors.New("bot has no embedding model configured")
+
+	errReembedAbandoned = errors.New("re-embedding worker stopped responding")
+)
+
+// ReembedStatus is the lifecycle state of a re-embedding job.
+type ReembedStatus string
+
+const (
+	ReembedStatusRunning   ReembedStatus = "running"
+	ReembedStatusCompleted ReembedStatus = "completed"
+	ReembedStatusFailed    ReembedStatus = "failed"
+)
+
+// ReembedPhase is the step a re-embedding job is in.
+type ReembedPhase string
+
+const (
+	// ReembedPhaseCollection copies every point into a new collection that
+	// has a vector for the new model, embedding the bot's memories on the way.
+	ReembedPhaseCollection ReembedPhase = "collection"
+	// ReembedPhaseVectors fills an existing vector of the active collection.
+	ReembedPhaseVectors ReembedPhase = "vectors"
+	// ReembedPhaseSwitch points the bot, and for a new collection every
+	// instance, at the new vectors.
+	ReembedPhaseSwitch ReembedPhase = "switch"
+)
+
+// ReembedJob reports the progress of a background re-embedding.
+type ReembedJob struct {
+	ID                 string        `json:"id"`
+	BotID              string        `json:"bot_id"`
+	Status             ReembedStatus `json:"status"`
+	Phase              ReembedPhase  `json:"phase"`
+	ModelID            string        `json:"model_id"`
+	Dimensions         int           `json:"dimensions"`
+	Collection         string        `json:"collection,omitempty"`
+	PreviousCollection string        `json:"previous_collection,omitempty"`
+	Total              int           `json:"total"`
+	Processed          int           `json:"processed"`
+	Embedded           int           `json:"embedded"`
+	Failed             int           `json:"failed"`
+	Skipped            int           `json:"skipped"`
+	Progress           float64       `json:"progress"`
+	Error              string        `json:"error,omitempty"`
+	Errors             []string      `json:"errors,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+	FinishedAt         *time.Time    `json:"finished_at,omitempty"`
+}
+
+// EmbeddingStatus compares a bot's configured embedding model with the
+// vector its memories are searched in.
+type EmbeddingStatus struct {
+	BotID            string          `json:"bot_id"`
+	Model            *EmbeddingModel `json:"model,omitempty"`
+	VectorName       string          `json:"vector_name,omitempty"`
+	VectorDimensions int             `json:"vector_dimensions,omitempty"`
+	NeedsReembed     bool            `json:"needs_reembed"`
+	Job              *ReembedJob     `json:"job,omitempty"`
+}
+
+func cloneReembedJob(job ReembedJob) ReembedJob {
+	if job.Errors != nil {
+		job.Errors = append([]string(nil), job.Errors...)
+	}
+	return job
+}
+
+func (j *ReembedJob) addError(err error) {
+	j.Error = err.Error()
+	if len(j.Errors) < maxReembedErrors {
+		j.Errors = append(j.Errors, err.Error())
+	}
+}
+
+// StartReembed re-embeds the bot's memories with its configured embedding
+// model in the background. Progress is available through GetReembed and
+// EmbeddingStatus.
+func (s *Service) StartReembed(ctx context.Context, botID string) (ReembedJob, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return ReembedJob{}, fmt.Errorf("bot_id is required")
+	}
+	if s.store == nil {
+		return ReembedJob{}, fmt.Errorf("qdrant store not configured")
+	}
+	if s.resolver == nil {
+		return ReembedJob{}, fmt.Errorf("embedding resolver not configured")
+	}
+	if s.vectorStates == nil {
+		return ReembedJob{}, fmt.Errorf("vector state store not configured")
+	}
+	model, err := s.botEmbeddingModel(ctx, botID)
+	if err != nil {
+		return ReembedJob{}, err
+	}
+	job := ReembedJob{
+		ID:         uuid.NewString(),
+		BotID:      botID,
+		Status:     ReembedStatusRunning,
+		Phase:      ReembedPhaseCollection,
+		ModelID:    model.ModelID,
+		Dimensions: model.Dimensions,
+		CreatedAt:  time.Now().UTC(),
+	}
+	// A migration reports its target collection once it is created.
+	if dim, ok := s.store.vectorSize(model.ModelID); ok && dim == model.Dimensions {
+		job.Phase = ReembedPhaseVectors
+		job.Collection = s.store.activeCollection()
+	}
+	if err := s.reembeds.start(ctx, job); err != nil {
+		return ReembedJob{}, err
+	}
+	go s.runReembed(WithBotID(context.WithoutCancel(ctx), botID), job, model)
+	return job, nil
+}
+
+// GetReembed returns the progress of a re-embedding job of the bot.
+func (s *Service) GetReembed(ctx context.Context, id, botID string) (ReembedJob, error) {
+	return s.reembeds.get(ctx, id, strings.TrimSpace(botID))
+}
+
+// EmbeddingStatus reports whether the bot's memories are embedded with its
+// configured model, along with the latest re-embedding job.
+func (s *Service) EmbeddingStatus(ctx context.Context, botID string) (EmbeddingStatus, error) {
+	botID = strings.TrimSpace(botID)
+	status := EmbeddingStatus{BotID: botID}
+	job, ok, err := s.reembeds.latest(ctx, botID)
+	if err != nil {
+		return status, err
+	}
+	if ok {
+		status.Job = &job
+	}
+	if s.store == nil {
+		return status, nil
+	}
+	status.VectorName, status.VectorDimensions = s.activeTextVector(ctx, botID)
+	model, err := s.botEmbeddingModel(ctx, botID)
+	if errors.Is(err, ErrEmbeddingModelNotConfigured) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Model = &model
+	status.NeedsReembed = model.ModelID != status.VectorName || model.Dimensions != status.VectorDimensions
+	return status, nil
+}
+
+func (s *Service) botEmbeddingModel(ctx context.Context, botID string) (EmbeddingModel, error) {
+	if s.embeddingModels == nil {
+		return EmbeddingModel{}, ErrEmbeddingModelNotConfigured
+	}
+	model, ok, err := s.embeddingModels.BotEmbeddingModel(ctx, botID)
+	if err != nil {
+		return EmbeddingModel{}, err
+	}
+	if !ok || strings.TrimSpace(model.ModelID) == "" {
+		return EmbeddingModel{}, ErrEmbeddingModelNotConfigured
+	}
+	if model.Dimensions <= 0 {
+		return EmbeddingModel{}, fmt.Errorf("embedding model %s has no dimensions", model.ModelID)
+	}
+	return model, nil
+}
+
+// activeTextVector returns the vector the bot's text memories are searched in.
+func (s *Service) activeTextVector(ctx context.Context, botID string) (string, int) {
+	if vector, ok := s.botVector(ctx, botID); ok {
+		if dim, exists := s.store.vectorSize(vector.VectorName); exists && dim == vector.Dimensions {
+			return vector.VectorName, dim
+		}
+	}
+	name := strings.TrimSpace(s.defaultTextModelID)
+	return name, s.store.vectorsSnapshot(name)[name]
+}
+
+func (s *Service) runReembed(ctx context.Context, job ReembedJob, model EmbeddingModel) {
+	embedder := &embeddings.ResolverTextEmbedder{Resolver: s.resolver, ModelID: model.ModelID, Dims: model.Dimensions}
+	run := reembedRun{
+		service:  s,
+		jobID:    job.ID,
+		botID:    job.BotID,
+		model:    model,
+		embedder: embedder,
+		seen:     map[string]string{},
+		failed:   map[string]struct{}{},
+	}
+	stopHeartbeat := s.reembedHeartbeat(ctx, job.ID)
+	defer stopHeartbeat()
+	var err error
+	if job.Phase == ReembedPhaseVectors {
+		err = run.fillVectors(ctx)
+	} else {
+		err = run.migrateCollection(ctx)
+	}
+	if err == nil {
+		run.setPhase(ctx, ReembedPhaseSwitch)
+		err = s.vectorStates.SaveBotVector(ctx, BotVector{BotID: job.BotID, VectorName: model.ModelID, Dimensions: model.Dimensions})
+	}
+	if err == nil {
+		vector := BotVector{BotID: job.BotID, VectorName: model.ModelID, Dimensions: model.Dimensions}
+		s.vectorCache.set(job.BotID, vector, true, time.Now())
+		// Catch memories written between the last pass and the switch.
+		if _, passErr := run.reconcile(ctx, run.source(), run.target(), false); passErr != nil {
+			s.logger.Warn("memory re-embedding final pass failed", slog.String("job_id", job.ID), slog.Any("error", passErr))
+		}
+	}
+
+	s.reembeds.update(ctx, job.ID, func(j *ReembedJob) {
+		now := time.Now().UTC()
+		j.FinishedAt = &now
+		if err != nil {
+			j.Status = ReembedStatusFailed
+			j.addError(err)
+			return
+		}
+		j.Status = ReembedStatusCompleted
+		j.Progress = 1
+	})
+	if err != nil {
+		s.logger.Error("memory re-embedding failed", slog.String("job_id", job.ID), slog.String("bot_id", job.BotID), slog.Any("error", err))
+		return
+	}
+	s.logger.Info("memory re-embedding finished", slog.String("job_id", job.ID), slog.String("bot_id", job.BotID), slog.String("model", model.ModelID))
+}
+
+// reembedHeartbeat touches the job until the returned function is called,
+// so the janitor can tell a running job from one whose worker died.
+func (s *Service) reembedHeartbeat(ctx context.Context, jobID string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(reembedHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.reembeds.touch(ctx, jobID)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// RunReembedJanitor periodically fails abandoned re-embedding jobs and drops
+// the collections migrations left behind.
+func (s *Service) RunReembedJanitor(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+	ticker := time.NewTicker(reembedJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.cleanupReembeds(ctx)
+		}
+	}
+}
+
+// cleanupReembeds fails running jobs whose worker stopped heartbeating, then
+// drops the target of every failed migration and, after
+// reembedCollectionGrace, the collection a migration switched away from.
+// The collection behind the active alias is never dropped.
+func (s *Service) cleanupReembeds(ctx context.Context) {
+	if s.store == nil || s.store.client == nil {
+		return
+	}
+	now := time.Now().UTC()
+	stale, err := s.reembeds.stale(ctx, now.Add(-reembedStaleAfter))
+	if err != nil {
+		s.logger.Warn("list stale re-embedding jobs failed", slog.Any("error", err))
+	}
+	for _, job := range stale {
+		job.Status = ReembedStatusFailed
+		job.FinishedAt = &now
+		job.addError(errReembedAbandoned)
+		if err := s.reembeds.put(ctx, job); err != nil {
+			s.logger.Warn("fail stale re-embedding job failed", slog.String("job_id", job.ID), slog.Any("error", err))
+			continue
+		}
+		s.logger.Warn("memory re-embedding job abandoned", slog.String("job_id", job.ID), slog.String("bot_id", job.BotID))
+	}
+	jobs, err := s.reembeds.uncleaned(ctx)
+	if err != nil {
+		s.logger.Warn("list re-embedding migrations failed", slog.Any("error", err))
+		return
+	}
+	for _, job := range jobs {
+		collection, ok := reembedLeftover(job, now)
+		if !ok {
+			continue
+		}
+		if err := s.store.dropInactiveCollection(ctx, collection); err != nil {
+			s.logger.Warn("drop re-embedding collection failed", slog.String("job_id", job.ID), slog.String("collection", collection), slog.Any("error", err))
+			continue
+		}
+		if err := s.reembeds.markCleaned(ctx, job.ID); err != nil {
+			s.logger.Warn("mark re-embedding job cleaned failed", slog.String("job_id", job.ID), slog.Any("error", err))
+		}
+	}
+}
+
+// reembedLeftover returns the collection a finished migration no longer
+// needs, and false while it must be kept. Once the switch happened that is
+// the previous collection, kept for reembedCollectionGrace; before it, the
+// target the migration was copying into.
+func reembedLeftover(job ReembedJob, now time.Time) (string, bool) {
+	if job.PreviousCollection != "" {
+		if job.FinishedAt == nil || now.Sub(*job.FinishedAt) < reembedCollectionGrace {
+			return "", false
+		}
+		return job.PreviousCollection, true
+	}
+	if job.Status != ReembedStatusFailed {
+		return "", true
+	}
+	return job.Collection, true
+}
+
+// reembedRun holds the state of one running job.
+type reembedRun struct {
+	service  *Service
+	jobID    string
+	botID    string
+	model    EmbeddingModel
+	embedder embeddings.Embedder
+	// seen maps point IDs to the payload fingerprint they were processed with.
+	seen map[string]string
+	// failed holds the IDs of memories whose last embedding attempt failed.
+	failed map[string]struct{}
+	// from and to are set by migrateCollection; fillVectors works in place.
+	from *QdrantStore
+	to   *QdrantStore
+}
+
+func (r *reembedRun) source() *QdrantStore {
+	if r.from != nil {
+		return r.from
+	}
+	return r.service.store
+}
+
+func (r *reembedRun) target() *QdrantStore {
+	if r.to != nil {
+		return r.to
+	}
+	return r.service.store
+}
+
+func (r *reembedRun) setPhase(ctx context.Context, phase ReembedPhase) {
+	r.service.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+		j.Phase = phase
+	})
+}
+
+// migrateCollection copies every point into a new collection that also has
+// the model's vector, then switches the store to it.
+func (r *reembedRun) migrateCollection(ctx context.Context) error {
+	s := r.service
+	r.from = s.store.pinned()
+	legacy := strings.TrimSpace(s.defaultTextModelID)
+	vectors := r.from.vectorsSnapshot(legacy)
+	vectors[r.model.ModelID] = r.model.Dimensions
+	target, err := r.from.newMigrationTarget(ctx, vectors)
+	if err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+	r.to = target
+	s.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+		j.Collection = target.activeCollection()
+	})
+	if err := r.run(ctx); err != nil {
+		if dropErr := target.dropCollection(context.WithoutCancel(ctx)); dropErr != nil {
+			s.logger.Warn("drop re-embedding collection failed", slog.String("collection", target.activeCollection()), slog.Any("error", dropErr))
+		}
+		return err
+	}
+	r.setPhase(ctx, ReembedPhaseSwitch)
+	previous, err := s.store.switchTo(ctx, target)
+	if err != nil {
+		return fmt.Errorf("switch collection: %w", err)
+	}
+	s.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+		j.PreviousCollection = previous
+	})
+	// Later passes read the old collection and write through the store.
+	r.to = nil
+	return nil
+}
+
+// fillVectors embeds the bot's memories into a vector the active collection
+// already has.
+func (r *reembedRun) fillVectors(ctx context.Context) error {
+	return r.run(ctx)
+}
+
+// run makes the initial pass and the reconcile passes.
+func (r *reembedRun) run(ctx context.Context) error {
+	total, err := r.source().Count(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("count memories: %w", err)
+	}
+	r.service.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+		j.Total = int(total)
+	})
+	if _, err := r.reconcile(ctx, r.source(), r.target(), true); err != nil {
+		return err
+	}
+	for pass := 0; pass < reembedReconcilePasses; pass++ {
+		changed, err := r.reconcile(ctx, r.source(), r.target(), false)
+		if err != nil {
+			return err
+		}
+		if changed == 0 {
+			break
+		}
+	}
+	if len(r.failed) > 0 {
+		return fmt.Errorf("%d memories failed to embed", len(r.failed))
+	}
+	return nil
+}
+
+// reconcile scans from and writes every point whose payload changed since it
+// was last processed into to. On a copy it also removes points deleted from
+// from. It returns how many points were written or removed.
+func (r *reembedRun) reconcile(ctx context.Context, from, to *QdrantStore, initial bool) (int, error) {
+	copying := from != to
+	legacy := strings.TrimSpace(r.service.defaultTextModelID)
+	present := map[string]struct{}{}
+	changed := 0
+	var offset *qdrant.PointId
+	for {
+		var (
+			page []qdrantPoint
+			next *qdrant.PointId
+			err  error
+		)
+		if copying {
+			page, next, err = from.scrollVectors(ctx, reembedPageSize, offset, legacy)
+		} else {
+			page, next, err = from.Scroll(ctx, reembedPageSize, nil, offset)
+		}
+		if err != nil {
+			return changed, fmt.Errorf("read memories: %w", err)
+		}
+		pending := make([]qdrantPoint, 0, len(page))
+		for _, point := range page {
+			present[point.ID] = struct{}{}
+			fingerprint := payloadFingerprint(point.Payload)
+			if previous, ok := r.seen[point.ID]; ok && previous == fingerprint {
+				continue
+			}
+			r.seen[point.ID] = fingerprint
+			pending = append(pending, point)
+		}
+		written, err := r.writePage(ctx, to, pending, copying)
+		if err != nil {
+			return changed, err
+		}
+		changed += written
+		if initial {
+			scanned := len(page)
+			r.service.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+				j.Processed += scanned
+				if j.Total > 0 {
+					j.Progress = min(float64(j.Processed)/float64(j.Total), 0.99)
+				}
+			})
+		}
+		if next == nil || len(page) == 0 {
+			break
+		}
+		offset = next
+	}
+	if !copying || initial || !r.copyingPhase(to) {
+		return changed, nil
+	}
+	removed, err := r.removeDeleted(ctx, to, present)
+	return changed + removed, err
+}
+
+// copyingPhase reports whether to is the migration target that has not been
+// switched to yet; only then may points missing from the source be removed.
+func (r *reembedRun) copyingPhase(to *QdrantStore) bool {
+	return r.to != nil && to == r.to
+}
+
+// writePage embeds the bot's memories of a page and writes the new vectors.
+func (r *reembedRun) writePage(ctx context.Context, to *QdrantStore, points []qdrantPoint, copying bool) (int, error) {
+	if len(points) == 0 {
+		return 0, nil
+	}
+	texts := make([]string, 0, len(points))
+	owned := make([]int, 0, len(points))
+	skipped := 0
+	for i, point := range points {
+		if resolveBotID("", point.Payload) != r.botID {
+			continue
+		}
+		text, _ := point.Payload["data"].(string)
+		if strings.TrimSpace(text) == "" {
+			skipped++
+			continue
+		}
+		texts = append(texts, text)
+		owned = append(owned, i)
+	}
+
+	var embedErr error
+	var embedded [][]float32
+	if len(texts) > 0 {
+		embedded, embedErr = r.embedder.EmbedBatch(ctx, texts)
+		if embedErr == nil && len(embedded) != len(texts) {
+			embedErr = fmt.Errorf("embedder returned %d vectors for %d inputs", len(embedded), len(texts))
+		}
+	}
+	vectors := map[string][]float32{}
+	if embedErr == nil {
+		for i, idx := range owned {
+			vectors[points[idx].ID] = embedded[i]
+		}
+	}
+
+	written := 0
+	if copying {
+		upserts := make([]qdrantPoint, 0, len(points))
+		for _, point := range points {
+			copied := qdrantPoint{ID: point.ID, Payload: point.Payload, NamedVectors: map[string]*qdrant.Vector{}}
+			for name, vector := range point.NamedVectors {
+				if fitsSchema(to, name, vector) {
+					copied.NamedVectors[name] = vector
+				}
+			}
+			if vector, ok := vectors[point.ID]; ok {
+				copied.NamedVectors[r.model.ModelID] = qdrant.NewVectorDense(vector)
+			}
+			if len(copied.NamedVectors) == 0 {
+				skipped++
+				continue
+			}
+			upserts = append(upserts, copied)
+		}
+		if err := to.Upsert(ctx, upserts); err != nil {
+			return 0, fmt.Errorf("copy memories: %w", err)
+		}
+		written = len(upserts)
+	} else {
+		if err := to.updateVectors(ctx, r.model.ModelID, vectors); err != nil {
+			return 0, fmt.Errorf("write vectors: %w", err)
+		}
+		written = len(vectors)
+	}
+
+	for _, idx := range owned {
+		id := points[idx].ID
+		if embedErr != nil {
+			// Forget the point so a reconcile pass retries it.
+			r.failed[id] = struct{}{}
+			delete(r.seen, id)
+			continue
+		}
+		delete(r.failed, id)
+	}
+	failed := len(r.failed)
+	r.service.reembeds.update(ctx, r.jobID, func(j *ReembedJob) {
+		j.Skipped += skipped
+		j.Embedded += len(vectors)
+		j.Failed = failed
+		if embedErr != nil {
+			j.addError(embedErr)
+		}
+	})
+	return written, nil
+}
+
+// removeDeleted removes points from the target that no longer exist in the source.
+func (r *reembedRun) removeDeleted(ctx context.Context, to *QdrantStore, present map[string]struct{}) (int, error) {
+	stale := []string{}
+	var offset *qdrant.PointId
+	for {
+		page, next, err := to.Scroll(ctx, reembedPageSize, nil, offset)
+		if err != nil {
+			return 0, fmt.Errorf("read migrated memories: %w", err)
+		}
+		for _, point := range page {
+			if _, ok := present[point.ID]; !ok {
+				stale = append(stale, point.ID)
+				delete(r.seen, point.ID)
+			}
+		}
+		if next == nil || len(page) == 0 {
+			break
+		}
+		offset = next
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+	if err := to.DeleteBatch(ctx, stale); err != nil {
+		return 0, fmt.Errorf("remove deleted memories: %w", err)
+	}
+	return len(stale), nil
+}
+
+// fitsSchema reports whether a copied vector can be stored in the target:
+// dense vectors must match the target's size for that name.
+func fitsSchema(to *QdrantStore, name string, vector *qdrant.Vector) bool {
+	dense := vector.GetDense()
+	if dense == nil {
+		return true
+	}
+	dim, ok := to.vectorSize(name)
+	return ok && dim == len(dense.GetData())
+}
+
+// payloadFingerprint identifies a payload version, so points changed while a
+// job runs are processed again.
+func payloadFingerprint(payload map[string]any) string {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,399 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// reembedStore keeps re-embedding jobs and the collections their migrations
+// left behind until the janitor dropped them.
+type reembedStore interface {
+	// start registers job unless the bot already has a running one. A
+	// collection migration copies every bot's vectors, so it never overlaps
+	// another job.
+	start(ctx context.Context, job ReembedJob) error
+	// get returns ErrReembedNotFound when id is unknown, expired or belongs
+	// to another bot.
+	get(ctx context.Context, id, botID string) (ReembedJob, error)
+	// latest returns the most recently created job of the bot.
+	latest(ctx context.Context, botID string) (ReembedJob, bool, error)
+	// update changes a job started by this process.
+	update(ctx context.Context, id string, fn func(job *ReembedJob))
+	// put overwrites a job, e.g. when the janitor fails an abandoned one.
+	put(ctx context.Context, job ReembedJob) error
+	// touch records that the worker running the job is alive.
+	touch(ctx context.Context, id string)
+	// stale returns running jobs whose worker stopped touching them before.
+	stale(ctx context.Context, before time.Time) ([]ReembedJob, error)
+	// uncleaned returns finished migrations whose unused collection was not
+	// dropped yet.
+	uncleaned(ctx context.Context) ([]ReembedJob, error)
+	markCleaned(ctx context.Context, id string) error
+}
+
+type reembedJob struct {
+	job ReembedJob
+	// migrates is set for jobs that copy the whole collection.
+	migrates  bool
+	heartbeat time.Time
+	cleaned   bool
+}
+
+// memoryReembedStore keeps re-embedding jobs in process; finished jobs are
+// dropped after reembedJobRetention once their collections are cleaned up.
+// It is used when no database is configured and only works for a single
+// instance.
+type memoryReembedStore struct {
+	mu    sync.Mutex
+	items map[string]*reembedJob
+}
+
+func newMemoryReembedStore() *memoryReembedStore {
+	return &memoryReembedStore{items: map[string]*reembedJob{}}
+}
+
+func (c *memoryReembedStore) start(_ context.Context, job ReembedJob) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	migrates := job.Phase == ReembedPhaseCollection
+	for _, item := range c.items {
+		if item.job.Status != ReembedStatusRunning {
+			continue
+		}
+		if err := reembedConflict(job.BotID, migrates, item.job.BotID, item.migrates); err != nil {
+			return err
+		}
+	}
+	c.items[job.ID] = &reembedJob{job: job, migrates: migrates, heartbeat: time.Now().UTC()}
+	return nil
+}
+
+func (c *memoryReembedStore) get(_ context.Context, id, botID string) (ReembedJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	item, ok := c.items[strings.TrimSpace(id)]
+	if !ok || item.job.BotID != botID {
+		return ReembedJob{}, ErrReembedNotFound
+	}
+	return cloneReembedJob(item.job), nil
+}
+
+func (c *memoryReembedStore) latest(_ context.Context, botID string) (ReembedJob, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(time.Now().UTC())
+	var found *ReembedJob
+	for _, item := range c.items {
+		if item.job.BotID != botID {
+			continue
+		}
+		if found == nil || item.job.CreatedAt.After(found.CreatedAt) {
+			found = &item.job
+		}
+	}
+	if found == nil {
+		return ReembedJob{}, false, nil
+	}
+	return cloneReembedJob(*found), true, nil
+}
+
+func (c *memoryReembedStore) update(_ context.Context, id string, fn func(job *ReembedJob)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.items[id]; ok {
+		fn(&item.job)
+	}
+}
+
+func (c *memoryReembedStore) put(_ context.Context, job ReembedJob) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.items[job.ID]; ok {
+		item.job = cloneReembedJob(job)
+	}
+	return nil
+}
+
+func (c *memoryReembedStore) touch(_ context.Context, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.items[id]; ok {
+		item.heartbeat = time.Now().UTC()
+	}
+}
+
+func (c *memoryReembedStore) stale(_ context.Context, before time.Time) ([]ReembedJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var jobs []ReembedJob
+	for _, item := range c.items {
+		if item.job.Status == ReembedStatusRunning && item.heartbeat.Before(before) {
+			jobs = append(jobs, cloneReembedJob(item.job))
+		}
+	}
+	return jobs, nil
+}
+
+func (c *memoryReembedStore) uncleaned(_ context.Context) ([]ReembedJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var jobs []ReembedJob
+	for _, item := range c.items {
+		if item.migrates && !item.cleaned && item.job.Status != ReembedStatusRunning {
+			jobs = append(jobs, cloneReembedJob(item.job))
+		}
+	}
+	return jobs, nil
+}
+
+func (c *memoryReembedStore) markCleaned(_ context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.items[id]; ok {
+		item.cleaned = true
+	}
+	return nil
+}
+
+func (c *memoryReembedStore) pruneLocked(now time.Time) {
+	for id, item := range c.items {
+		if item.migrates && !item.cleaned {
+			continue
+		}
+		if item.job.FinishedAt != nil && now.Sub(*item.job.FinishedAt) > reembedJobRetention {
+			delete(c.items, id)
+		}
+	}
+}
+
+// reembedConflict reports whether a new job of botID may not start next to
+// a running job of runningBot.
+func reembedConflict(botID string, migrates bool, runningBot string, runningMigrates bool) error {
+	if runningBot == botID {
+		return ErrReembedRunning
+	}
+	if migrates || runningMigrates {
+		return fmt.Errorf("%w: another bot is migrating the memory collection", ErrReembedRunning)
+	}
+	return nil
+}
+
+// DBReembedStore keeps re-embedding jobs in Postgres, so progress can be
+// read on any instance and collections left behind by a crashed or finished
+// migration are cleaned up. Jobs started by this process are also kept in
+// memory to apply progress updates.
+type DBReembedStore struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+	logger  *slog.Logger
+
+	mu    sync.Mutex
+	local map[string]*ReembedJob
+}
+
+// NewDBReembedStore creates a Postgres-backed re-embedding job store.
+func NewDBReembedStore(log *slog.Logger, pool *pgxpool.Pool, queries *sqlc.Queries) *DBReembedStore {
+	return &DBReembedStore{
+		pool:    pool,
+		queries: queries,
+		logger:  log.With(slog.String("store", "memory_reembed_jobs")),
+		local:   map[string]*ReembedJob{},
+	}
+}
+
+// SetReembedStore replaces the in-process re-embedding job store.
+func (s *Service) SetReembedStore(store *DBReembedStore) {
+	if store == nil {
+		return
+	}
+	s.reembeds = store
+}
+
+func (d *DBReembedStore) start(ctx context.Context, job ReembedJob) error {
+	if _, err := d.queries.DeleteExpiredMemoryReembedJobs(ctx, pgtype.Timestamptz{Time: time.Now().Add(-reembedJobRetention).UTC(), Valid: true}); err != nil {
+		d.logger.Warn("prune memory re-embedding jobs failed", slog.Any("error", err))
+	}
+	id, err := db.ParseUUID(job.ID)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	migrates := job.Phase == ReembedPhaseCollection
+
+	tx, err := d.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	qtx := d.queries.WithTx(tx)
+	if err := qtx.LockMemoryReembedJobs(ctx); err != nil {
+		return err
+	}
+	running, err := qtx.ListRunningMemoryReembedJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range running {
+		if err := reembedConflict(job.BotID, migrates, item.BotID, item.Migrates); err != nil {
+			return err
+		}
+	}
+	if err := qtx.InsertMemoryReembedJob(ctx, sqlc.InsertMemoryReembedJobParams{
+		ID:       id,
+		BotID:    job.BotID,
+		Status:   string(job.Status),
+		Migrates: migrates,
+		Job:      raw,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	copied := cloneReembedJob(job)
+	d.local[job.ID] = &copied
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *DBReembedStore) get(ctx context.Context, id, botID string) (ReembedJob, error) {
+	pgID, err := db.ParseUUID(strings.TrimSpace(id))
+	if err != nil {
+		return ReembedJob{}, ErrReembedNotFound
+	}
+	raw, err := d.queries.GetMemoryReembedJob(ctx, sqlc.GetMemoryReembedJobParams{ID: pgID, BotID: botID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ReembedJob{}, ErrReembedNotFound
+		}
+		return ReembedJob{}, err
+	}
+	return decodeReembedJob(raw)
+}
+
+func (d *DBReembedStore) latest(ctx context.Context, botID string) (ReembedJob, bool, error) {
+	raw, err := d.queries.GetLatestMemoryReembedJob(ctx, botID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ReembedJob{}, false, nil
+		}
+		return ReembedJob{}, false, err
+	}
+	job, err := decodeReembedJob(raw)
+	if err != nil {
+		return ReembedJob{}, false, err
+	}
+	return job, true, nil
+}
+
+func (d *DBReembedStore) update(ctx context.Context, id string, fn func(job *ReembedJob)) {
+	d.mu.Lock()
+	item, ok := d.local[id]
+	if !ok {
+		d.mu.Unlock()
+		return
+	}
+	fn(item)
+	job := cloneReembedJob(*item)
+	if job.Status != ReembedStatusRunning {
+		delete(d.local, id)
+	}
+	d.mu.Unlock()
+	if err := d.put(ctx, job); err != nil {
+		d.logger.Warn("save memory re-embedding job failed", slog.String("job_id", id), slog.Any("error", err))
+	}
+}
+
+func (d *DBReembedStore) put(ctx context.Context, job ReembedJob) error {
+	id, err := db.ParseUUID(job.ID)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	finishedAt := pgtype.Timestamptz{}
+	if job.FinishedAt != nil {
+		finishedAt = pgtype.Timestamptz{Time: job.FinishedAt.UTC(), Valid: true}
+	}
+	return d.queries.UpdateMemoryReembedJob(ctx, sqlc.UpdateMemoryReembedJobParams{
+		Status:     string(job.Status),
+		Job:        raw,
+		FinishedAt: finishedAt,
+		ID:         id,
+	})
+}
+
+func (d *DBReembedStore) touch(ctx context.Context, id string) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return
+	}
+	if err := d.queries.TouchMemoryReembedJob(ctx, pgID); err != nil {
+		d.logger.Warn("touch memory re-embedding job failed", slog.String("job_id", id), slog.Any("error", err))
+	}
+}
+
+func (d *DBReembedStore) stale(ctx context.Context, before time.Time) ([]ReembedJob, error) {
+	rows, err := d.queries.ListStaleMemoryReembedJobs(ctx, pgtype.Timestamptz{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	return decodeReembedJobs(rows)
+}
+
+func (d *DBReembedStore) uncleaned(ctx context.Context) ([]ReembedJob, error) {
+	rows, err := d.queries.ListUncleanedMemoryReembedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeReembedJobs(rows)
+}
+
+func (d *DBReembedStore) markCleaned(ctx context.Context, id string) error {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return d.queries.MarkMemoryReembedJobCleaned(ctx, pgID)
+}
+
+func decodeReembedJob(raw []byte) (ReembedJob, error) {
+	var job ReembedJob
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return ReembedJob{}, fmt.Errorf("decode re-embedding job: %w", err)
+	}
+	return job, nil
+}
+
+func decodeReembedJobs(rows [][]byte) ([]ReembedJob, error) {
+	jobs := make([]ReembedJob, 0, len(rows))
+	for _, raw := range rows {
+		job, err := decodeReembedJob(raw)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
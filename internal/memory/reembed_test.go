@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/embeddings"
+)
+
+type fakeVectorStates struct {
+	vectors map[string]BotVector
+}
+
+func (f *fakeVectorStates) BotVector(_ context.Context, botID string) (BotVector, bool, error) {
+	vector, ok := f.vectors[botID]
+	return vector, ok, nil
+}
+
+func (f *fakeVectorStates) SaveBotVector(_ context.Context, vector BotVector) error {
+	f.vectors[vector.BotID] = vector
+	return nil
+}
+
+type fakeEmbeddingModels struct {
+	models map[string]EmbeddingModel
+}
+
+func (f *fakeEmbeddingModels) BotEmbeddingModel(_ context.Context, botID string) (EmbeddingModel, bool, error) {
+	model, ok := f.models[botID]
+	return model, ok, nil
+}
+
+func newReembedTestService(states map[string]BotVector, models map[string]EmbeddingModel) *Service {
+	return &Service{
+		logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		store:              &QdrantStore{usesNamedVectors: true, vectorNames: map[string]int{"small": 4, "large": 8}},
+		resolver:           &embeddings.Resolver{},
+		defaultTextModelID: "small",
+		vectorStates:       &fakeVectorStates{vectors: states},
+		vectorCache:        newBotVectorCache(),
+		embeddingModels:    &fakeEmbeddingModels{models: models},
+		reembeds:           newMemoryReembedStore(),
+	}
+}
+
+func TestReembedStoreAllowsOneJobPerBot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemoryReembedStore()
+	now := time.Now().UTC()
+	if err := store.start(ctx, ReembedJob{ID: "a", BotID: "bot-1", Status: ReembedStatusRunning, Phase: ReembedPhaseVectors, CreatedAt: now}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := store.start(ctx, ReembedJob{ID: "b", BotID: "bot-1", Status: ReembedStatusRunning, Phase: ReembedPhaseVectors}); !errors.Is(err, ErrReembedRunning) {
+		t.Fatalf("expected ErrReembedRunning for the same bot, got %v", err)
+	}
+	if err := store.start(ctx, ReembedJob{ID: "c", BotID: "bot-2", Status: ReembedStatusRunning, Phase: ReembedPhaseCollection}); !errors.Is(err, ErrReembedRunning) {
+		t.Fatalf("expected a collection migration to wait for running jobs, got %v", err)
+	}
+	if err := store.start(ctx, ReembedJob{ID: "d", BotID: "bot-2", Status: ReembedStatusRunning, Phase: ReembedPhaseVectors, CreatedAt: now.Add(time.Second)}); err != nil {
+		t.Fatalf("start for another bot: %v", err)
+	}
+
+	if _, err := store.get(ctx, "a", "bot-2"); !errors.Is(err, ErrReembedNotFound) {
+		t.Fatalf("expected job to be scoped to its bot, got %v", err)
+	}
+	store.update(ctx, "a", func(job *ReembedJob) {
+		job.Status = ReembedStatusCompleted
+		job.Errors = []string{"x"}
+	})
+	job, err := store.get(ctx, "a", "bot-1")
+	if err != nil || job.Status != ReembedStatusCompleted {
+		t.Fatalf("unexpected job: %+v, %v", job, err)
+	}
+	job.Errors[0] = "changed"
+	if again, _ := store.get(ctx, "a", "bot-1"); again.Errors[0] != "x" {
+		t.Fatal("expected get to return a copy")
+	}
+	if latest, ok, _ := store.latest(ctx, "bot-2"); !ok || latest.ID != "d" {
+		t.Fatalf("expected latest job d, got %+v", latest)
+	}
+}
+
+func TestReembedStoreKeepsUncleanedMigrations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemoryReembedStore()
+	finished := time.Now().UTC().Add(-2 * reembedJobRetention)
+	if err := store.start(ctx, ReembedJob{ID: "m", BotID: "bot-1", Status: ReembedStatusRunning, Phase: ReembedPhaseCollection}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	store.update(ctx, "m", func(job *ReembedJob) {
+		job.Status = ReembedStatusCompleted
+		job.PreviousCollection = "memory"
+		job.FinishedAt = &finished
+	})
+	if _, err := store.get(ctx, "m", "bot-1"); err != nil {
+		t.Fatalf("expected migration to be kept until cleaned, got %v", err)
+	}
+	jobs, _ := store.uncleaned(ctx)
+	if len(jobs) != 1 || jobs[0].ID != "m" {
+		t.Fatalf("expected uncleaned migration, got %+v", jobs)
+	}
+	if err := store.markCleaned(ctx, "m"); err != nil {
+		t.Fatalf("mark cleaned: %v", err)
+	}
+	if _, err := store.get(ctx, "m", "bot-1"); !errors.Is(err, ErrReembedNotFound) {
+		t.Fatalf("expected cleaned migration to expire, got %v", err)
+	}
+
+	if err := store.start(ctx, ReembedJob{ID: "s", BotID: "bot-2", Status: ReembedStatusRunning, Phase: ReembedPhaseVectors}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if jobs, _ := store.stale(ctx, time.Now().UTC().Add(-time.Minute)); len(jobs) != 0 {
+		t.Fatalf("expected fresh job not to be stale, got %+v", jobs)
+	}
+	if jobs, _ := store.stale(ctx, time.Now().UTC().Add(time.Minute)); len(jobs) != 1 || jobs[0].ID != "s" {
+		t.Fatalf("expected stale job, got %+v", jobs)
+	}
+}
+
+func TestReembedLeftover(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	recent := now.Add(-time.Hour)
+	old := now.Add(-2 * reembedCollectionGrace)
+	cases := []struct {
+		name string
+		job  ReembedJob
+		want string
+		ok   bool
+	}{
+		{"switched within grace", ReembedJob{Status: ReembedStatusCompleted, Collection: "memory_v2", PreviousCollection: "memory", FinishedAt: &recent}, "", false},
+		{"switched after grace", ReembedJob{Status: ReembedStatusCompleted, Collection: "memory_v2", PreviousCollection: "memory", FinishedAt: &old}, "memory", true},
+		{"failed after switch", ReembedJob{Status: ReembedStatusFailed, Collection: "memory_v2", PreviousCollection: "memory", FinishedAt: &old}, "memory", true},
+		{"failed before switch", ReembedJob{Status: ReembedStatusFailed, Collection: "memory_v2", FinishedAt: &recent}, "memory_v2", true},
+		{"failed before target", ReembedJob{Status: ReembedStatusFailed, FinishedAt: &recent}, "", true},
+	}
+	for _, tc := range cases {
+		got, ok := reembedLeftover(tc.job, now)
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("%s: got %q, %v", tc.name, got, ok)
+		}
+	}
+}
+
+func TestServiceEmbeddingStatus(t *testing.T) {
+	t.Parallel()
+
+	s := newReembedTestService(
+		map[string]BotVector{"bot-2": {BotID: "bot-2", VectorName: "large", Dimensions: 8}},
+		map[string]EmbeddingModel{
+			"bot-1": {ModelID: "large", Dimensions: 8},
+			"bot-2": {ModelID: "large", Dimensions: 8},
+			"bot-3": {ModelID: "small", Dimensions: 4},
+		},
+	)
+	cases := []struct {
+		botID  string
+		vector string
+		needs  bool
+	}{
+		{botID: "bot-1", vector: "small", needs: true},
+		{botID: "bot-2", vector: "large", needs: false},
+		{botID: "bot-3", vector: "small", needs: false},
+	}
+	for _, tc := range cases {
+		status, err := s.EmbeddingStatus(context.Background(), tc.botID)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.botID, err)
+		}
+		if status.VectorName != tc.vector || status.NeedsReembed != tc.needs {
+			t.Fatalf("%s: unexpected status %+v", tc.botID, status)
+		}
+	}
+
+	status, err := s.EmbeddingStatus(context.Background(), "bot-4")
+	if err != nil {
+		t.Fatalf("bot-4: %v", err)
+	}
+	if status.Model != nil || status.NeedsReembed {
+		t.Fatalf("expected no model for bot-4, got %+v", status)
+	}
+}
+
+func TestServiceTextEmbeddingFollowsBotVector(t *testing.T) {
+	t.Parallel()
+
+	s := newReembedTestService(map[string]BotVector{
+		"bot-1": {BotID: "bot-1", VectorName: "large", Dimensions: 8},
+		"bot-2": {BotID: "bot-2", VectorName: "missing", Dimensions: 8},
+	}, nil)
+
+	embedder, name := s.textEmbedding(WithBotID(context.Background(), "bot-1"))
+	if name != "large" {
+		t.Fatalf("expected bot vector, got %q", name)
+	}
+	if resolver, ok := embedder.(*embeddings.ResolverTextEmbedder); !ok || resolver.ModelID != "large" || resolver.Dims != 8 {
+		t.Fatalf("unexpected embedder %#v", embedder)
+	}
+	if _, name := s.textEmbedding(WithBotID(context.Background(), "bot-2")); name != "small" {
+		t.Fatalf("expected default vector when the bot vector is missing, got %q", name)
+	}
+	if _, name := s.textEmbedding(WithBotID(context.Background(), "bot-3")); name != "small" {
+		t.Fatalf("expected default vector for bot without state, got %q", name)
+	}
+}
+
+func TestStartReembedRequiresModel(t *testing.T) {
+	t.Parallel()
+
+	s := newReembedTestService(map[string]BotVector{}, map[string]EmbeddingModel{})
+	if _, err := s.StartReembed(context.Background(), "bot-1"); !errors.Is(err, ErrEmbeddingModelNotConfigured) {
+		t.Fatalf("expected ErrEmbeddingModelNotConfigured, got %v", err)
+	}
+}
+
+func TestPayloadFingerprint(t *testing.T) {
+	t.Parallel()
+
+	a := payloadFingerprint(map[string]any{"data": "hello", "bot_id": "bot-1"})
+	b := payloadFingerprint(map[string]any{"bot_id": "bot-1", "data": "hello"})
+	if a == "" || a != b {
+		t.Fatalf("expected stable fingerprint, got %q and %q", a, b)
+	}
+	if c := payloadFingerprint(map[string]any{"data": "changed", "bot_id": "bot-1"}); c == a {
+		t.Fatal("expected fingerprint to change with the payload")
+	}
+}
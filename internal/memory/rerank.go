@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRerankCandidates is how many fused results are re-scored when a
+	// search asks for fewer.
+	DefaultRerankCandidates = 20
+	// defaultSearchLimit matches the store's default search limit.
+	defaultSearchLimit = 10
+)
+
+// ErrRerankerNotConfigured is returned by a Reranker when the bot has no
+// rerank model; search then keeps the fused ranking.
+var ErrRerankerNotConfigured = errors.New("memory reranker not configured")
+
+// Reranker re-scores search candidates against the query.
+type Reranker interface {
+	// Rerank returns one relevance score per document, in input order.
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// SetReranker enables the rerank stage of Search. The reranker is resolved per
+// call, so it can pick the bot's rerank model from the context.
+func (s *Service) SetReranker(reranker Reranker) {
+	s.reranker = reranker
+}
+
+// rerank re-scores items and returns the best limit of them. Score becomes
+// the rerank score and RetrievalScore keeps the fused score. On failure the
+// fused ranking is kept.
+func (s *Service) rerank(ctx context.Context, query string, items []MemoryItem, limit int) []MemoryItem {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+	if s.reranker == nil || len(items) == 0 {
+		return items[:limit]
+	}
+	documents := make([]string, len(items))
+	for i, item := range items {
+		documents[i] = item.Memory
+	}
+	scores, err := s.reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		if !errors.Is(err, ErrRerankerNotConfigured) {
+			s.logger.Warn("memory rerank failed, keeping fused ranking", slog.Any("error", err))
+		}
+		return items[:limit]
+	}
+	if len(scores) != len(items) {
+		s.logger.Warn("memory rerank returned wrong number of scores", slog.Int("want", len(items)), slog.Int("got", len(scores)))
+		return items[:limit]
+	}
+	reranked := make([]MemoryItem, len(items))
+	for i, item := range items {
+		item.RetrievalScore = item.Score
+		item.Score = scores[i]
+		item.Reranked = true
+		reranked[i] = item
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	return reranked[:limit]
+}
+
+// RerankClient calls a Cohere/Jina-compatible /rerank endpoint, as served by
+// most rerank model providers and by vLLM or Infinity.
+type RerankClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+	logger  *slog.Logger
+	http    *http.Client
+}
+
+func NewRerankClient(log *slog.Logger, baseURL, apiKey, model string, timeout time.Duration) (*RerankClient, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, fmt.Errorf("rerank client: base url is required")
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("rerank client: model is required")
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &RerankClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		logger:  log.With(slog.String("client", "rerank")),
+		http: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+func (c *RerankClient) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(rerankRequest{
+		Model:     c.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/rerank", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("rerank error: %s", strings.TrimSpace(string(b)))
+	}
+	var parsed rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(documents))
+	for _, result := range parsed.Results {
+		if result.Index >= 0 && result.Index < len(scores) {
+			scores[result.Index] = result.RelevanceScore
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeReranker struct {
+	scores []float64
+	err    error
+}
+
+func (f *fakeReranker) Rerank(_ context.Context, _ string, documents []string) ([]float64, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.scores[:len(documents)], nil
+}
+
+func TestServiceRerank(t *testing.T) {
+	t.Parallel()
+
+	items := []MemoryItem{
+		{ID: "a", Memory: "User likes tea", Score: 0.9},
+		{ID: "b", Memory: "User is allergic to nuts", Score: 0.5},
+		{ID: "c", Memory: "User lives in Berlin", Score: 0.1},
+	}
+	s := &Service{
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		reranker: &fakeReranker{scores: []float64{0.2, 0.95, 0.4}},
+	}
+
+	got := s.rerank(context.Background(), "what can the user not eat?", append([]MemoryItem(nil), items...), 2)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(got))
+	}
+	if got[0].ID != "b" || got[1].ID != "c" {
+		t.Fatalf("unexpected order: %s, %s", got[0].ID, got[1].ID)
+	}
+	if got[0].Score != 0.95 || got[0].RetrievalScore != 0.5 || !got[0].Reranked {
+		t.Fatalf("expected rerank and retrieval scores, got %+v", got[0])
+	}
+}
+
+func TestServiceRerankFallsBack(t *testing.T) {
+	t.Parallel()
+
+	items := []MemoryItem{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.5}, {ID: "c", Score: 0.1}}
+	for _, err := range []error{ErrRerankerNotConfigured, errors.New("provider down")} {
+		s := &Service{
+			logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			reranker: &fakeReranker{err: err},
+		}
+		got := s.rerank(context.Background(), "q", append([]MemoryItem(nil), items...), 2)
+		if len(got) != 2 || got[0].ID != "a" || got[0].Reranked {
+			t.Fatalf("expected fused ranking on %v, got %+v", err, got)
+		}
+	}
+}
+
+func TestRerankClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req rerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "rerank-v1" || len(req.Documents) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.8},{"index":0,"relevance_score":0.1}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewRerankClient(nil, server.URL+"/", "test-key", "rerank-v1", 0)
+	if err != nil {
+		t.Fatalf("new rerank client: %v", err)
+	}
+	scores, err := client.Rerank(context.Background(), "q", []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	if len(scores) != 2 || scores[0] != 0.1 || scores[1] != 0.8 {
+		t.Fatalf("unexpected scores: %v", scores)
+	}
+}
+
+func TestLLMClientRerank(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"scores\":[{\"index\":0,\"score\":0.3},{\"index\":1,\"score\":1.7},{\"index\":9,\"score\":1}]}"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewLLMClient(nil, server.URL, "test-key", "judge", 0)
+	if err != nil {
+		t.Fatalf("new llm client: %v", err)
+	}
+	scores, err := client.Rerank(context.Background(), "q", []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	if len(scores) != 2 || scores[0] != 0.3 || scores[1] != 1 {
+		t.Fatalf("expected clamped in-range scores, got %v", scores)
+	}
+}
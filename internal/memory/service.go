@@ -26,6 +26,16 @@ type Service struct {
 	logger                   *slog.Logger
 	defaultTextModelID       string
 	defaultMultimodalModelID string
+	compactions              compactionStore
+	compactionUndoWindow     time.Duration
+	imports                  *importStore
+	history                  HistoryStore
+	sweepPolicy              SweepPolicy
+	reranker                 Reranker
+	vectorStates             VectorStateStore
+	vectorCache              *botVectorCache
+	embeddingModels          EmbeddingModelResolver
+	reembeds                 reembedStore
 }
 
 func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store *QdrantStore, resolver *embeddings.Resolver, bm25 *BM25Indexer, defaultTextModelID, defaultMultimodalModelID string) *Service {
@@ -38,6 +48,12 @@ func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store *
 		logger:                   log.With(slog.String("service", "memory")),
 		defaultTextModelID:       defaultTextModelID,
 		defaultMultimodalModelID: defaultMultimodalModelID,
+		compactions:              newMemoryCompactionStore(),
+		compactionUndoWindow:     DefaultCompactionUndoWindow,
+		imports:                  newImportStore(),
+		vectorCache:              newBotVectorCache(),
+		reembeds:                 newMemoryReembedStore(),
+		sweepPolicy:              DefaultSweepPolicy,
 	}
 }
 
@@ -48,6 +64,11 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	if req.BotID == "" && req.AgentID == "" && req.RunID == "" {
 		return SearchResponse{}, fmt.Errorf("bot_id, agent_id or run_id is required")
 	}
+	if strings.TrimSpace(req.ExpiresAt) != "" {
+		if _, err := parseExpiresAt(req.ExpiresAt); err != nil {
+			return SearchResponse{}, err
+		}
+	}
 
 	messages := normalizeMessages(req)
 	filters := buildFilters(req)
@@ -55,7 +76,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 
 	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
 	if req.Infer != nil && !*req.Infer {
-		return s.addRawMessages(ctx, messages, filters, req.Metadata, embeddingEnabled)
+		return s.addRawMessages(ctx, messages, filters, req.Metadata, requestLifecycle(req, DecisionAction{}), embeddingEnabled)
 	}
 
 	extractResp, err := s.llm.Extract(ctx, ExtractRequest{
@@ -96,11 +117,30 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 		}
 	}
 
+	var vectors [][]float32
+	if embeddingEnabled {
+		texts := make([]string, len(actions))
+		for i, action := range actions {
+			switch strings.ToUpper(action.Event) {
+			case "ADD", "UPDATE":
+				texts[i] = action.Text
+			}
+		}
+		vectors, err = s.embedTexts(ctx, texts)
+		if err != nil {
+			return SearchResponse{}, err
+		}
+	}
+
 	results := make([]MemoryItem, 0, len(actions))
-	for _, action := range actions {
+	for i, action := range actions {
+		var vector []float32
+		if vectors != nil {
+			vector = vectors[i]
+		}
 		switch strings.ToUpper(action.Event) {
 		case "ADD":
-			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, embeddingEnabled)
+			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, requestLifecycle(req, action), vector)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -109,7 +149,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 			})
 			results = append(results, item)
 		case "UPDATE":
-			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, embeddingEnabled)
+			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, requestLifecycle(req, action), vector)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -135,7 +175,31 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	return SearchResponse{Results: results}, nil
 }
 
+// Search returns matching memories, hiding expired ones, and records the
+// access on every returned memory.
 func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	rerank := s.reranker != nil && !req.NoRerank
+	if rerank && req.Limit < DefaultRerankCandidates {
+		req.Limit = DefaultRerankCandidates
+	}
+	resp, err := s.search(ctx, req)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	resp.Results = dropExpiredItems(resp.Results, time.Now().UTC())
+	if rerank {
+		ctx = WithBotID(ctx, resolveBotID(req.BotID, buildSearchFilters(req)))
+		resp.Results = s.rerank(ctx, req.Query, resp.Results, limit)
+	}
+	s.recordAccess(ctx, resp.Results)
+	return resp, nil
+}
+
+func (s *Service) search(ctx context.Context, req SearchRequest) (SearchResponse, error) {
 	if strings.TrimSpace(req.Query) == "" {
 		return SearchResponse{}, fmt.Errorf("query is required")
 	}
@@ -190,14 +254,14 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 	}
 
 	if embeddingEnabled {
-		if s.embedder == nil {
+		embedder, vectorName := s.textEmbedding(ctx)
+		if embedder == nil {
 			return SearchResponse{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedder.Embed(ctx, req.Query)
+		vector, err := embedder.Embed(ctx, req.Query)
 		if err != nil {
 			return SearchResponse{}, err
 		}
-		vectorName := s.vectorNameForText()
 		if len(req.Sources) == 0 {
 			points, scores, err := s.store.Search(ctx, vector, req.Limit, filters, vectorName)
 			if err != nil {
@@ -232,7 +296,10 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 	if err != nil {
 		return SearchResponse{}, err
 	}
-	indices, values := s.bm25.BuildQueryVector(lang, termFreq)
+	indices, values, err := s.bm25.BuildQueryVector(ctx, resolveBotID(req.BotID, filters), lang, termFreq)
+	if err != nil {
+		return SearchResponse{}, err
+	}
 	wantStats := !req.NoStats
 	if len(req.Sources) == 0 {
 		points, scores, err := s.store.SearchSparse(ctx, indices, values, req.Limit, filters, wantStats)
@@ -312,7 +379,7 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	}
 
 	vectorName := ""
-	if s.store.usesNamedVectors {
+	if s.store.hasNamedVectors() {
 		vectorName = result.Model
 	}
 
@@ -330,6 +397,7 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	}}); err != nil {
 		return EmbedUpsertResponse{}, err
 	}
+	s.recordHistory(ctx, id, HistoryEventAdd, "", req.Input.Text, payload, nil)
 
 	item := payloadToMemoryItem(id, payload)
 	return EmbedUpsertResponse{
@@ -341,6 +409,10 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 }
 
 func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, error) {
+	return s.update(ctx, req, HistoryEventUpdate, nil)
+}
+
+func (s *Service) update(ctx context.Context, req UpdateRequest, event string, historyMetadata map[string]any) (MemoryItem, error) {
 	if strings.TrimSpace(req.MemoryID) == "" {
 		return MemoryItem{}, fmt.Errorf("memory_id is required")
 	}
@@ -364,23 +436,11 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 	ctx = WithBotID(ctx, resolveBotID("", existing.Payload))
 
 	payload := existing.Payload
-	oldText := fmt.Sprint(payload["data"])
-	oldLang := fmt.Sprint(payload["lang"])
-	if oldLang == "" && strings.TrimSpace(oldText) != "" {
-		var detectErr error
-		oldLang, detectErr = s.detectLanguage(ctx, oldText)
-		if detectErr != nil {
-			s.logger.Warn("detect language failed for old text", slog.Any("error", detectErr))
-		}
-	}
-	if strings.TrimSpace(oldText) != "" && strings.TrimSpace(oldLang) != "" {
-		oldFreq, oldLen, err := s.bm25.TermFrequencies(oldLang, oldText)
-		if err != nil {
-			s.logger.Warn("bm25 term frequencies failed", slog.String("lang", oldLang), slog.Any("error", err))
-		} else {
-			s.bm25.RemoveDocument(oldLang, oldFreq, oldLen)
-		}
+	if err := applyLifecycle(payload, memoryLifecycle{Importance: req.Importance, ExpiresAt: req.ExpiresAt}); err != nil {
+		return MemoryItem{}, err
 	}
+	oldText := fmt.Sprint(payload["data"])
+	s.removeBM25Document(ctx, payload)
 
 	newLang, err := s.detectLanguage(ctx, req.Memory)
 	if err != nil {
@@ -390,7 +450,10 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 	if err != nil {
 		return MemoryItem{}, err
 	}
-	sparseIndices, sparseValues := s.bm25.AddDocument(newLang, newFreq, newLen)
+	sparseIndices, sparseValues, err := s.bm25.AddDocument(ctx, resolveBotID("", payload), newLang, newFreq, newLen)
+	if err != nil {
+		return MemoryItem{}, err
+	}
 
 	payload["data"] = req.Memory
 	payload["hash"] = hashMemory(req.Memory)
@@ -406,19 +469,21 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 		Payload:          payload,
 	}
 	if embeddingEnabled {
-		if s.embedder == nil {
+		embedder, vectorName := s.textEmbedding(ctx)
+		if embedder == nil {
 			return MemoryItem{}, fmt.Errorf("embedder not configured")
 		}
-		vector, err := s.embedder.Embed(ctx, req.Memory)
+		vector, err := embedder.Embed(ctx, req.Memory)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
 	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
+	s.recordHistory(ctx, req.MemoryID, event, oldText, req.Memory, payload, historyMetadata)
 	return payloadToMemoryItem(req.MemoryID, payload), nil
 }
 
@@ -474,9 +539,11 @@ func (s *Service) Delete(ctx context.Context, memoryID string) (DeleteResponse,
 	if strings.TrimSpace(memoryID) == "" {
 		return DeleteResponse{}, fmt.Errorf("memory_id is required")
 	}
+	deleted := s.pointsForDelete(ctx, []string{memoryID})
 	if err := s.store.Delete(ctx, memoryID); err != nil {
 		return DeleteResponse{}, err
 	}
+	s.finishDeletes(ctx, deleted)
 	return DeleteResponse{Message: "Memory deleted successfully!"}, nil
 }
 
@@ -494,9 +561,11 @@ func (s *Service) DeleteBatch(ctx context.Context, memoryIDs []string) (DeleteRe
 	if len(cleaned) == 0 {
 		return DeleteResponse{}, fmt.Errorf("memory_ids is required")
 	}
+	deleted := s.pointsForDelete(ctx, cleaned)
 	if err := s.store.DeleteBatch(ctx, cleaned); err != nil {
 		return DeleteResponse{}, err
 	}
+	s.finishDeletes(ctx, deleted)
 	return DeleteResponse{Message: fmt.Sprintf("%d memories deleted successfully!", len(cleaned))}, nil
 }
 
@@ -517,113 +586,64 @@ func (s *Service) DeleteAll(ctx context.Context, req DeleteAllRequest) (DeleteRe
 	if len(filters) == 0 {
 		return DeleteResponse{}, fmt.Errorf("bot_id, agent_id or run_id is required")
 	}
+	var deleted []qdrantPoint
+	if s.history != nil || s.bm25 != nil {
+		points, err := s.scrollAll(ctx, filters)
+		if err != nil {
+			s.logger.Warn("list memories before delete failed", slog.Any("error", err))
+		}
+		deleted = points
+	}
 	if err := s.store.DeleteAll(ctx, filters); err != nil {
 		return DeleteResponse{}, err
 	}
+	s.finishDeletes(ctx, deleted)
 	return DeleteResponse{Message: "Memories deleted successfully!"}, nil
 }
 
-func (s *Service) Compact(ctx context.Context, filters map[string]any, ratio float64, decayDays int) (CompactResult, error) {
-	if s.llm == nil {
-		return CompactResult{}, fmt.Errorf("llm not configured")
-	}
-	if s.store == nil {
-		return CompactResult{}, fmt.Errorf("qdrant store not configured")
-	}
-	if ratio <= 0 || ratio > 1 {
-		ratio = 0.5
+// pointsForDelete loads points about to be deleted so they can be removed
+// from the BM25 statistics and recorded in history.
+func (s *Service) pointsForDelete(ctx context.Context, ids []string) []qdrantPoint {
+	if s.history == nil && s.bm25 == nil {
+		return nil
 	}
-	ctx = WithBotID(ctx, resolveBotID("", filters))
-
-	// Fetch all existing memories.
-	points, err := s.store.List(ctx, 0, filters, false)
-	if err != nil {
-		return CompactResult{}, err
-	}
-	beforeCount := len(points)
-	if beforeCount <= 1 {
-		// Nothing to compact.
-		items := make([]MemoryItem, 0, len(points))
-		for _, p := range points {
-			items = append(items, payloadToMemoryItem(p.ID, p.Payload))
+	points := make([]qdrantPoint, 0, len(ids))
+	for _, id := range ids {
+		point, err := s.store.Get(ctx, id)
+		if err != nil {
+			s.logger.Warn("load memory before delete failed", slog.String("memory_id", id), slog.Any("error", err))
+			continue
 		}
-		return CompactResult{
-			BeforeCount: beforeCount,
-			AfterCount:  beforeCount,
-			Ratio:       1.0,
-			Results:     items,
-		}, nil
-	}
-
-	// Build candidate list and compute target.
-	candidates := make([]CandidateMemory, 0, beforeCount)
-	for _, p := range points {
-		candidates = append(candidates, CandidateMemory{
-			ID:        p.ID,
-			Memory:    fmt.Sprint(p.Payload["data"]),
-			CreatedAt: fmt.Sprint(p.Payload["created_at"]),
-		})
-	}
-	targetCount := int(math.Round(float64(beforeCount) * ratio))
-	if targetCount < 1 {
-		targetCount = 1
-	}
-
-	// Ask LLM to consolidate.
-	compactResp, err := s.llm.Compact(ctx, CompactRequest{
-		Memories:    candidates,
-		TargetCount: targetCount,
-		DecayDays:   decayDays,
-	})
-	if err != nil {
-		return CompactResult{}, fmt.Errorf("compact llm call failed: %w", err)
-	}
-	if len(compactResp.Facts) == 0 {
-		return CompactResult{}, fmt.Errorf("compact returned no facts")
-	}
-
-	// Delete old memories.
-	if err := s.store.DeleteAll(ctx, filters); err != nil {
-		return CompactResult{}, fmt.Errorf("compact delete old failed: %w", err)
-	}
-
-	// Reset BM25 stats for deleted documents.
-	if s.bm25 != nil {
-		for _, p := range points {
-			text := fmt.Sprint(p.Payload["data"])
-			lang := fmt.Sprint(p.Payload["lang"])
-			if strings.TrimSpace(text) == "" || strings.TrimSpace(lang) == "" {
-				continue
-			}
-			freq, docLen, err := s.bm25.TermFrequencies(lang, text)
-			if err != nil {
-				continue
-			}
-			s.bm25.RemoveDocument(lang, freq, docLen)
+		if point != nil {
+			points = append(points, *point)
 		}
 	}
+	return points
+}
 
-	// Add compacted facts.
-	results := make([]MemoryItem, 0, len(compactResp.Facts))
-	for _, fact := range compactResp.Facts {
-		if strings.TrimSpace(fact) == "" {
-			continue
-		}
-		item, err := s.applyAdd(ctx, fact, filters, nil, false)
+// scrollAll loads every point matching filters.
+func (s *Service) scrollAll(ctx context.Context, filters map[string]any) ([]qdrantPoint, error) {
+	var all []qdrantPoint
+	var offset *qdrant.PointId
+	for {
+		points, next, err := s.store.Scroll(ctx, sweepBatchSize, filters, offset)
 		if err != nil {
-			return CompactResult{}, fmt.Errorf("compact add failed: %w", err)
+			return all, err
 		}
-		results = append(results, item)
+		all = append(all, points...)
+		if next == nil {
+			return all, nil
+		}
+		offset = next
 	}
+}
 
-	afterCount := len(results)
-	actualRatio := float64(afterCount) / float64(beforeCount)
-	return CompactResult{
-		BeforeCount: beforeCount,
-		AfterCount:  afterCount,
-		Ratio:       math.Round(actualRatio*100) / 100,
-		Results:     results,
-	}, nil
+// finishDeletes updates BM25 statistics and history for deleted points.
+func (s *Service) finishDeletes(ctx context.Context, points []qdrantPoint) {
+	s.removeBM25Documents(ctx, points)
+	for _, p := range points {
+		s.recordHistory(ctx, p.ID, HistoryEventDelete, fmt.Sprint(p.Payload["data"]), "", p.Payload, nil)
+	}
 }
 
 const (
@@ -660,10 +680,14 @@ func (s *Service) Usage(ctx context.Context, filters map[string]any) (UsageRespo
 	}, nil
 }
 
+// WarmupBM25 rebuilds the BM25 statistics of every bot and language that was
+// not rebuilt from the vector store yet. With a persistent statistics store
+// each corpus is rebuilt once, by one instance.
 func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 	if s.bm25 == nil || s.store == nil {
 		return nil
 	}
+	warmup := s.bm25.newWarmup()
 	var offset *qdrant.PointId
 	for {
 		points, next, err := s.store.Scroll(ctx, batchSize, nil, offset)
@@ -678,29 +702,86 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 			if strings.TrimSpace(text) == "" {
 				continue
 			}
-			lang := fmt.Sprint(point.Payload["lang"])
-			if lang == "" {
+			lang, _ := point.Payload["lang"].(string)
+			if strings.TrimSpace(lang) == "" {
 				lang = fallbackLanguageCode(text)
 			}
+			botID := resolveBotID("", point.Payload)
+			needed, err := warmup.needs(ctx, botID, lang)
+			if err != nil {
+				return err
+			}
+			if !needed {
+				continue
+			}
 			termFreq, docLen, err := s.bm25.TermFrequencies(lang, text)
 			if err != nil {
 				s.logger.Warn("bm25 warmup: term frequencies failed", slog.String("id", point.ID), slog.Any("error", err))
 				continue
 			}
-			s.bm25.AddDocument(lang, termFreq, docLen)
+			warmup.add(botID, lang, termFreq, docLen)
 		}
 		if next == nil {
 			break
 		}
 		offset = next
 	}
-	return nil
+	rebuilt, err := warmup.commit(ctx)
+	if rebuilt > 0 {
+		s.logger.Info("bm25 warmup finished", slog.Int("corpora", rebuilt))
+	}
+	return err
+}
+
+// removeBM25Document takes a stored memory out of the BM25 statistics.
+// Failures only skew ranking, so they are logged.
+func (s *Service) removeBM25Document(ctx context.Context, payload map[string]any) {
+	if s.bm25 == nil {
+		return
+	}
+	text := fmt.Sprint(payload["data"])
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	lang, _ := payload["lang"].(string)
+	if strings.TrimSpace(lang) == "" {
+		detected, err := s.detectLanguage(ctx, text)
+		if err != nil {
+			s.logger.Warn("detect language failed for old text", slog.Any("error", err))
+			return
+		}
+		lang = detected
+	}
+	termFreq, docLen, err := s.bm25.TermFrequencies(lang, text)
+	if err != nil {
+		s.logger.Warn("bm25 term frequencies failed", slog.String("lang", lang), slog.Any("error", err))
+		return
+	}
+	if err := s.bm25.RemoveDocument(ctx, resolveBotID("", payload), lang, termFreq, docLen); err != nil {
+		s.logger.Warn("bm25 remove document failed", slog.Any("error", err))
+	}
 }
 
-func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (SearchResponse, error) {
+func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, lifecycle memoryLifecycle, embeddingEnabled bool) (SearchResponse, error) {
+	var vectors [][]float32
+	if embeddingEnabled {
+		texts := make([]string, len(messages))
+		for i, message := range messages {
+			texts[i] = message.Content
+		}
+		var err error
+		vectors, err = s.embedTexts(ctx, texts)
+		if err != nil {
+			return SearchResponse{}, err
+		}
+	}
 	results := make([]MemoryItem, 0, len(messages))
-	for _, message := range messages {
-		item, err := s.applyAdd(ctx, message.Content, filters, metadata, embeddingEnabled)
+	for i, message := range messages {
+		var vector []float32
+		if vectors != nil {
+			vector = vectors[i]
+		}
+		item, err := s.applyAdd(ctx, message.Content, filters, metadata, lifecycle, vector)
 		if err != nil {
 			return SearchResponse{}, err
 		}
@@ -726,7 +807,10 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 		if err != nil {
 			return nil, err
 		}
-		indices, values := s.bm25.BuildQueryVector(lang, termFreq)
+		indices, values, err := s.bm25.BuildQueryVector(ctx, resolveBotID("", filters), lang, termFreq)
+		if err != nil {
+			return nil, err
+		}
 		points, _, err := s.store.SearchSparse(ctx, indices, values, 5, filters, false)
 		if err != nil {
 			return nil, err
@@ -748,13 +832,52 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 	return candidates, nil
 }
 
-func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+// embedTexts embeds texts in as few provider calls as the embedder allows.
+// Blank entries are skipped and get a nil vector.
+func (s *Service) embedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	embedder, _ := s.textEmbedding(ctx)
+	if embedder == nil {
+		return nil, fmt.Errorf("embedder not configured")
+	}
+	inputs := make([]string, 0, len(texts))
+	positions := make([]int, 0, len(texts))
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		inputs = append(inputs, text)
+		positions = append(positions, i)
+	}
+	vectors := make([][]float32, len(texts))
+	if len(inputs) == 0 {
+		return vectors, nil
+	}
+	embedded, err := embedder.EmbedBatch(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(inputs) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d inputs", len(embedded), len(inputs))
+	}
+	for i, vector := range embedded {
+		vectors[positions[i]] = vector
+	}
+	return vectors, nil
+}
+
+// applyAdd stores text as a new memory. vector is the precomputed dense
+// embedding; nil stores the memory with the sparse vector only.
+func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, lifecycle memoryLifecycle, vector []float32) (MemoryItem, error) {
 	if s.store == nil {
 		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
 	}
+	payload := buildPayload(text, filters, metadata, "")
+	if err := applyLifecycle(payload, lifecycle); err != nil {
+		return MemoryItem{}, err
+	}
 	lang, err := s.detectLanguage(ctx, text)
 	if err != nil {
 		return MemoryItem{}, err
@@ -763,9 +886,11 @@ func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]
 	if err != nil {
 		return MemoryItem{}, err
 	}
-	sparseIndices, sparseValues := s.bm25.AddDocument(lang, termFreq, docLen)
+	sparseIndices, sparseValues, err := s.bm25.AddDocument(ctx, resolveBotID("", payload), lang, termFreq, docLen)
+	if err != nil {
+		return MemoryItem{}, err
+	}
 	id := uuid.NewString()
-	payload := buildPayload(text, filters, metadata, "")
 	payload["lang"] = lang
 	point := qdrantPoint{
 		ID:               id,
@@ -774,20 +899,15 @@ func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]
 		SparseVectorName: s.store.sparseVectorName,
 		Payload:          payload,
 	}
-	if embeddingEnabled {
-		if s.embedder == nil {
-			return MemoryItem{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, text)
-		if err != nil {
-			return MemoryItem{}, err
-		}
+	if vector != nil {
+		_, vectorName := s.textEmbedding(ctx)
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
 	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
+	s.recordHistory(ctx, id, HistoryEventAdd, "", text, payload, nil)
 	return payloadToMemoryItem(id, payload), nil
 }
 
@@ -811,8 +931,11 @@ func (s *Service) RebuildAdd(ctx context.Context, id, text string, filters map[s
 	if err != nil {
 		return MemoryItem{}, err
 	}
-	sparseIndices, sparseValues := s.bm25.AddDocument(lang, termFreq, docLen)
 	payload := buildPayload(text, filters, nil, "")
+	sparseIndices, sparseValues, err := s.bm25.AddDocument(ctx, resolveBotID("", payload), lang, termFreq, docLen)
+	if err != nil {
+		return MemoryItem{}, err
+	}
 	payload["lang"] = lang
 	point := qdrantPoint{
 		ID:               id,
@@ -827,7 +950,7 @@ func (s *Service) RebuildAdd(ctx context.Context, id, text string, filters map[s
 	return payloadToMemoryItem(id, payload), nil
 }
 
-func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, lifecycle memoryLifecycle, vector []float32) (MemoryItem, error) {
 	if strings.TrimSpace(id) == "" {
 		return MemoryItem{}, fmt.Errorf("update action missing id")
 	}
@@ -840,22 +963,13 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 	}
 
 	payload := existing.Payload
-	oldText := fmt.Sprint(payload["data"])
-	oldLang := fmt.Sprint(payload["lang"])
-	if oldLang == "" && strings.TrimSpace(oldText) != "" {
-		var detectErr error
-		oldLang, detectErr = s.detectLanguage(ctx, oldText)
-		if detectErr != nil {
-			s.logger.Warn("detect language failed for old text", slog.Any("error", detectErr))
-		}
+	if err := applyLifecycle(payload, lifecycle); err != nil {
+		return MemoryItem{}, err
 	}
-	if strings.TrimSpace(oldText) != "" && strings.TrimSpace(oldLang) != "" {
-		oldFreq, oldLen, err := s.bm25.TermFrequencies(oldLang, oldText)
-		if err != nil {
-			s.logger.Warn("bm25 term frequencies failed", slog.String("lang", oldLang), slog.Any("error", err))
-		} else {
-			s.bm25.RemoveDocument(oldLang, oldFreq, oldLen)
-		}
+	oldText := fmt.Sprint(payload["data"])
+	s.removeBM25Document(ctx, payload)
+	if filters != nil {
+		applyFiltersToPayload(payload, filters)
 	}
 	newLang, err := s.detectLanguage(ctx, text)
 	if err != nil {
@@ -865,7 +979,10 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 	if err != nil {
 		return MemoryItem{}, err
 	}
-	sparseIndices, sparseValues := s.bm25.AddDocument(newLang, newFreq, newLen)
+	sparseIndices, sparseValues, err := s.bm25.AddDocument(ctx, resolveBotID("", payload), newLang, newFreq, newLen)
+	if err != nil {
+		return MemoryItem{}, err
+	}
 	payload["data"] = text
 	payload["hash"] = hashMemory(text)
 	payload["updated_at"] = time.Now().UTC().Format(time.RFC3339)
@@ -873,9 +990,6 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 	if metadata != nil {
 		payload["metadata"] = mergeMetadata(payload["metadata"], metadata)
 	}
-	if filters != nil {
-		applyFiltersToPayload(payload, filters)
-	}
 	point := qdrantPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
@@ -883,20 +997,15 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 		SparseVectorName: s.store.sparseVectorName,
 		Payload:          payload,
 	}
-	if embeddingEnabled {
-		if s.embedder == nil {
-			return MemoryItem{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, text)
-		if err != nil {
-			return MemoryItem{}, err
-		}
+	if vector != nil {
+		_, vectorName := s.textEmbedding(ctx)
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
 	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
 		return MemoryItem{}, err
 	}
+	s.recordHistory(ctx, id, HistoryEventUpdate, oldText, text, payload, nil)
 	return payloadToMemoryItem(id, payload), nil
 }
 
@@ -912,31 +1021,27 @@ func (s *Service) applyDelete(ctx context.Context, id string) (MemoryItem, error
 		return MemoryItem{}, fmt.Errorf("memory not found")
 	}
 	item := payloadToMemoryItem(id, existing.Payload)
-	if s.bm25 != nil {
-		oldText := fmt.Sprint(existing.Payload["data"])
-		oldLang := fmt.Sprint(existing.Payload["lang"])
-		if oldLang == "" && strings.TrimSpace(oldText) != "" {
-			var detectErr error
-			oldLang, detectErr = s.detectLanguage(ctx, oldText)
-			if detectErr != nil {
-				s.logger.Warn("detect language failed for old text", slog.Any("error", detectErr))
-			}
-		}
-		if strings.TrimSpace(oldText) != "" && strings.TrimSpace(oldLang) != "" {
-			oldFreq, oldLen, err := s.bm25.TermFrequencies(oldLang, oldText)
-			if err != nil {
-				s.logger.Warn("bm25 term frequencies failed", slog.String("lang", oldLang), slog.Any("error", err))
-			} else {
-				s.bm25.RemoveDocument(oldLang, oldFreq, oldLen)
-			}
-		}
-	}
 	if err := s.store.Delete(ctx, id); err != nil {
 		return MemoryItem{}, err
 	}
+	s.removeBM25Document(ctx, existing.Payload)
+	s.recordHistory(ctx, id, HistoryEventDelete, item.Memory, "", existing.Payload, nil)
 	return item, nil
 }
 
+// requestLifecycle prefers lifecycle attributes given by the caller over the
+// ones suggested by the LLM for a decision.
+func requestLifecycle(req AddRequest, action DecisionAction) memoryLifecycle {
+	lifecycle := memoryLifecycle{Importance: action.Importance, ExpiresAt: action.ExpiresAt}
+	if req.Importance != nil {
+		lifecycle.Importance = req.Importance
+	}
+	if strings.TrimSpace(req.ExpiresAt) != "" {
+		lifecycle.ExpiresAt = req.ExpiresAt
+	}
+	return lifecycle
+}
+
 func normalizeMessages(req AddRequest) []Message {
 	if len(req.Messages) > 0 {
 		return req.Messages
@@ -1095,14 +1200,14 @@ func buildEmbeddingPayload(req EmbedUpsertRequest, filters map[string]any) map[s
 }
 
 func (s *Service) vectorNameForText() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.hasNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultTextModelID)
 }
 
 func (s *Service) vectorNameForMultimodal() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.hasNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultMultimodalModelID)
@@ -1153,6 +1258,18 @@ func payloadToMemoryItem(id string, payload map[string]any) MemoryItem {
 	if v, ok := payload["run_id"].(string); ok {
 		item.RunID = v
 	}
+	if v, ok := toFloat(payload[payloadImportance]); ok {
+		item.Importance = clampImportance(v)
+	}
+	if v, ok := toFloat(payload[payloadAccessCount]); ok {
+		item.AccessCount = int(v)
+	}
+	if v, ok := payload[payloadLastAccessedAt].(string); ok {
+		item.LastAccessedAt = v
+	}
+	if v, ok := payload[payloadExpiresAt].(string); ok {
+		item.ExpiresAt = v
+	}
 	if meta, ok := payload["metadata"].(map[string]any); ok {
 		item.Metadata = meta
 	} else if payload["metadata"] == nil {
@@ -1265,10 +1382,16 @@ func fuseByRankFusion(pointsBySource map[string][]qdrantPoint, _ map[string][]fl
 		}
 	}
 
+	// Weight relevance by importance and recency so that fresh, important
+	// memories win ties over stale ones; expired memories are dropped.
+	now := time.Now().UTC()
 	items := make([]MemoryItem, 0, len(candidates))
 	for id, candidate := range candidates {
+		if isExpired(candidate.Payload, now) {
+			continue
+		}
 		item := payloadToMemoryItem(candidate.ID, candidate.Payload)
-		item.Score = rrfScores[id]
+		item.Score = rrfScores[id] * memoryRankWeight(candidate.Payload, now)
 		items = append(items, item)
 	}
 
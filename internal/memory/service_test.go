@@ -121,3 +121,41 @@ func TestRankFusion_Logic(t *testing.T) {
 		// Symmetric case: both get same RRF score (e.g. 1/(k+1)+1/(k+2) for k=60).
 	}
 }
+
+type batchEmbedder struct {
+	calls [][]string
+}
+
+func (b *batchEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	return nil, fmt.Errorf("single embed should not be used")
+}
+
+func (b *batchEmbedder) EmbedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	b.calls = append(b.calls, inputs)
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		vectors[i] = []float32{float32(len(input))}
+	}
+	return vectors, nil
+}
+
+func (b *batchEmbedder) Dimensions() int {
+	return 1
+}
+
+func TestServiceEmbedTextsBatches(t *testing.T) {
+	t.Parallel()
+
+	embedder := &batchEmbedder{}
+	s := &Service{embedder: embedder}
+	vectors, err := s.embedTexts(context.Background(), []string{"one", "", "three", "  "})
+	if err != nil {
+		t.Fatalf("embed texts: %v", err)
+	}
+	if len(embedder.calls) != 1 || len(embedder.calls[0]) != 2 {
+		t.Fatalf("expected one batch of two inputs, got %v", embedder.calls)
+	}
+	if len(vectors) != 4 || vectors[0][0] != 3 || vectors[1] != nil || vectors[2][0] != 5 || vectors[3] != nil {
+		t.Fatalf("unexpected vectors: %v", vectors)
+	}
+}
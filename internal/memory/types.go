@@ -1,6 +1,10 @@
 package memory
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 // LLM is the interface for LLM operations needed by memory service
 type LLM interface {
@@ -25,6 +29,8 @@ type AddRequest struct {
 	Filters          map[string]any `json:"filters,omitempty"`
 	Infer            *bool          `json:"infer,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
+	Importance       *float64       `json:"importance,omitempty"`
+	ExpiresAt        string         `json:"expires_at,omitempty"`
 }
 
 type SearchRequest struct {
@@ -37,12 +43,15 @@ type SearchRequest struct {
 	Sources          []string       `json:"sources,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 	NoStats          bool           `json:"no_stats,omitempty"`
+	NoRerank         bool           `json:"no_rerank,omitempty"`
 }
 
 type UpdateRequest struct {
-	MemoryID         string `json:"memory_id"`
-	Memory           string `json:"memory"`
-	EmbeddingEnabled *bool  `json:"embedding_enabled,omitempty"`
+	MemoryID         string   `json:"memory_id"`
+	Memory           string   `json:"memory"`
+	EmbeddingEnabled *bool    `json:"embedding_enabled,omitempty"`
+	Importance       *float64 `json:"importance,omitempty"`
+	ExpiresAt        string   `json:"expires_at,omitempty"`
 }
 
 type GetAllRequest struct {
@@ -88,18 +97,24 @@ type EmbedUpsertResponse struct {
 }
 
 type MemoryItem struct {
-	ID          string         `json:"id"`
-	Memory      string         `json:"memory"`
-	Hash        string         `json:"hash,omitempty"`
-	CreatedAt   string         `json:"created_at,omitempty"`
-	UpdatedAt   string         `json:"updated_at,omitempty"`
-	Score       float64        `json:"score,omitempty"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
-	BotID       string         `json:"bot_id,omitempty"`
-	AgentID     string         `json:"agent_id,omitempty"`
-	RunID       string         `json:"run_id,omitempty"`
-	TopKBuckets []TopKBucket   `json:"top_k_buckets,omitempty"`
-	CDFCurve    []CDFPoint     `json:"cdf_curve,omitempty"`
+	ID             string         `json:"id"`
+	Memory         string         `json:"memory"`
+	Hash           string         `json:"hash,omitempty"`
+	CreatedAt      string         `json:"created_at,omitempty"`
+	UpdatedAt      string         `json:"updated_at,omitempty"`
+	Score          float64        `json:"score,omitempty"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+	BotID          string         `json:"bot_id,omitempty"`
+	AgentID        string         `json:"agent_id,omitempty"`
+	RunID          string         `json:"run_id,omitempty"`
+	Importance     float64        `json:"importance,omitempty"`
+	AccessCount    int            `json:"access_count,omitempty"`
+	LastAccessedAt string         `json:"last_accessed_at,omitempty"`
+	ExpiresAt      string         `json:"expires_at,omitempty"`
+	RetrievalScore float64        `json:"retrieval_score,omitempty"`
+	Reranked       bool           `json:"reranked,omitempty"`
+	TopKBuckets    []TopKBucket   `json:"top_k_buckets,omitempty"`
+	CDFCurve       []CDFPoint     `json:"cdf_curve,omitempty"`
 }
 
 // TopKBucket represents one bar in the Top-K sparse dimension bar chart.
@@ -148,10 +163,12 @@ type DecideRequest struct {
 }
 
 type DecisionAction struct {
-	Event     string `json:"event"`
-	ID        string `json:"id,omitempty"`
-	Text      string `json:"text"`
-	OldMemory string `json:"old_memory,omitempty"`
+	Event      string   `json:"event"`
+	ID         string   `json:"id,omitempty"`
+	Text       string   `json:"text"`
+	OldMemory  string   `json:"old_memory,omitempty"`
+	Importance *float64 `json:"importance,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
 }
 
 type DecideResponse struct {
@@ -165,14 +182,38 @@ type CompactRequest struct {
 }
 
 type CompactResponse struct {
-	Facts []string `json:"facts"`
+	Facts []CompactFact `json:"facts"`
+}
+
+// CompactFact is one consolidated fact and the IDs of the memories it replaces.
+type CompactFact struct {
+	Text      string   `json:"text"`
+	SourceIDs []string `json:"source_ids,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare string for models that omit source IDs.
+func (f *CompactFact) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*f = CompactFact{Text: text}
+		return nil
+	}
+	type plain CompactFact
+	var parsed plain
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*f = CompactFact(parsed)
+	return nil
 }
 
 type CompactResult struct {
-	BeforeCount int          `json:"before_count"`
-	AfterCount  int          `json:"after_count"`
-	Ratio       float64      `json:"ratio"`
-	Results     []MemoryItem `json:"results"`
+	CompactionID  string       `json:"compaction_id,omitempty"`
+	BeforeCount   int          `json:"before_count"`
+	AfterCount    int          `json:"after_count"`
+	Ratio         float64      `json:"ratio"`
+	Results       []MemoryItem `json:"results"`
+	UndoExpiresAt *time.Time   `json:"undo_expires_at,omitempty"`
 }
 
 type UsageResponse struct {
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+	"github.com/memohai/memoh/internal/embeddings"
+)
+
+// botVectorCacheTTL bounds how long a bot's vector assignment is cached, so
+// a switch made by another instance is picked up quickly.
+const botVectorCacheTTL = 30 * time.Second
+
+// BotVector records the dense vector a bot's memories are embedded into
+// after a re-embedding job. Bots without one use the default text vector.
+type BotVector struct {
+	BotID      string
+	VectorName string
+	Dimensions int
+}
+
+// VectorStateStore persists per-bot vector assignments.
+type VectorStateStore interface {
+	// BotVector returns the bot's assignment; ok is false when it has none.
+	BotVector(ctx context.Context, botID string) (vector BotVector, ok bool, err error)
+	SaveBotVector(ctx context.Context, vector BotVector) error
+}
+
+// EmbeddingModel is the embedding model a bot is configured to use.
+type EmbeddingModel struct {
+	ModelID    string `json:"model_id"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// EmbeddingModelResolver looks up the embedding model configured for a bot.
+type EmbeddingModelResolver interface {
+	// BotEmbeddingModel returns the bot's model; ok is false when it has none.
+	BotEmbeddingModel(ctx context.Context, botID string) (model EmbeddingModel, ok bool, err error)
+}
+
+// DBVectorStateStore keeps per-bot vector assignments in Postgres.
+type DBVectorStateStore struct {
+	queries *sqlc.Queries
+}
+
+// NewDBVectorStateStore creates a Postgres-backed vector state store.
+func NewDBVectorStateStore(queries *sqlc.Queries) *DBVectorStateStore {
+	return &DBVectorStateStore{queries: queries}
+}
+
+func (d *DBVectorStateStore) BotVector(ctx context.Context, botID string) (BotVector, bool, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return BotVector{}, false, nil
+	}
+	row, err := d.queries.GetBotMemoryVector(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BotVector{}, false, nil
+		}
+		return BotVector{}, false, err
+	}
+	return BotVector{BotID: botID, VectorName: row.VectorName, Dimensions: int(row.Dimensions)}, true, nil
+}
+
+func (d *DBVectorStateStore) SaveBotVector(ctx context.Context, vector BotVector) error {
+	pgBotID, err := db.ParseUUID(vector.BotID)
+	if err != nil {
+		return err
+	}
+	_, err = d.queries.UpsertBotMemoryVector(ctx, sqlc.UpsertBotMemoryVectorParams{
+		BotID:      pgBotID,
+		VectorName: vector.VectorName,
+		Dimensions: int32(vector.Dimensions),
+	})
+	return err
+}
+
+type cachedBotVector struct {
+	vector  BotVector
+	ok      bool
+	expires time.Time
+}
+
+// botVectorCache avoids a database round trip per embedding call.
+type botVectorCache struct {
+	mu    sync.Mutex
+	items map[string]cachedBotVector
+}
+
+func newBotVectorCache() *botVectorCache {
+	return &botVectorCache{items: map[string]cachedBotVector{}}
+}
+
+func (c *botVectorCache) get(botID string, now time.Time) (cachedBotVector, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[botID]
+	if !ok || now.After(item.expires) {
+		return cachedBotVector{}, false
+	}
+	return item, true
+}
+
+func (c *botVectorCache) set(botID string, vector BotVector, ok bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[botID] = cachedBotVector{vector: vector, ok: ok, expires: now.Add(botVectorCacheTTL)}
+}
+
+// SetVectorStateStore enables per-bot embedding vectors written by
+// re-embedding jobs.
+func (s *Service) SetVectorStateStore(store VectorStateStore) {
+	s.vectorStates = store
+}
+
+// SetEmbeddingModelResolver lets the service compare a bot's configured
+// embedding model with the vector its memories live in.
+func (s *Service) SetEmbeddingModelResolver(resolver EmbeddingModelResolver) {
+	s.embeddingModels = resolver
+}
+
+// botVector returns the bot's vector assignment, reloading the store's
+// active collection when another instance switched it.
+func (s *Service) botVector(ctx context.Context, botID string) (BotVector, bool) {
+	botID = strings.TrimSpace(botID)
+	if s.vectorStates == nil || botID == "" {
+		return BotVector{}, false
+	}
+	now := time.Now()
+	if item, ok := s.vectorCache.get(botID, now); ok {
+		return item.vector, item.ok
+	}
+	vector, ok, err := s.vectorStates.BotVector(ctx, botID)
+	if err != nil {
+		s.logger.Warn("load bot memory vector failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return BotVector{}, false
+	}
+	// Reload on every cache miss, not only for unknown vectors: a switch to a
+	// collection with the same vector names still changes the schema sizes
+	// and the collection that searches read.
+	if s.store != nil {
+		if err := s.store.reloadActive(ctx); err != nil {
+			s.logger.Warn("reload memory collection failed", slog.Any("error", err))
+		}
+	}
+	s.vectorCache.set(botID, vector, ok, now)
+	return vector, ok
+}
+
+// textEmbedding returns the embedder and vector name for text memories of
+// the bot in the context.
+func (s *Service) textEmbedding(ctx context.Context) (embeddings.Embedder, string) {
+	if vector, ok := s.botVector(ctx, BotIDFromContext(ctx)); ok && s.resolver != nil && s.store != nil {
+		if dim, exists := s.store.vectorSize(vector.VectorName); exists && dim == vector.Dimensions {
+			return &embeddings.ResolverTextEmbedder{Resolver: s.resolver, ModelID: vector.VectorName, Dims: dim}, vector.VectorName
+		}
+	}
+	return s.embedder, s.vectorNameForText()
+}
@@ -20,6 +20,7 @@ type DBService struct {
 	queries   *sqlc.Queries
 	logger    *slog.Logger
 	publisher event.Publisher
+	indexer   Indexer
 }
 
 // NewService creates a message service.
@@ -38,6 +39,12 @@ func NewService(log *slog.Logger, queries *sqlc.Queries, publishers ...event.Pub
 	}
 }
 
+// SetIndexer registers an index that is updated when messages are persisted
+// or deleted.
+func (s *DBService) SetIndexer(indexer Indexer) {
+	s.indexer = indexer
+}
+
 // Persist writes a single message to bot_history_messages.
 func (s *DBService) Persist(ctx context.Context, input PersistInput) (Message, error) {
 	pgBotID, err := dbpkg.ParseUUID(input.BotID)
@@ -131,6 +138,9 @@ func (s *DBService) Persist(ctx context.Context, input PersistInput) (Message, e
 	}
 
 	s.publishMessageCreated(result)
+	if s.indexer != nil {
+		s.indexer.IndexMessage(context.WithoutCancel(ctx), result)
+	}
 	return result, nil
 }
 
@@ -222,13 +232,48 @@ func (s *DBService) ListBefore(ctx context.Context, botID string, before time.Ti
 	return msgs, nil
 }
 
+// ListAround returns up to before messages older than at and up to after
+// messages at or newer than at, ordered oldest-first. An empty routeID
+// includes every route of the bot.
+func (s *DBService) ListAround(ctx context.Context, botID, routeID string, at time.Time, before, after int32) ([]Message, error) {
+	pgBotID, err := dbpkg.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	pgRouteID, err := parseOptionalUUID(routeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route id: %w", err)
+	}
+	rows, err := s.queries.ListMessagesAround(ctx, sqlc.ListMessagesAroundParams{
+		BotID:       pgBotID,
+		RouteID:     pgRouteID,
+		CreatedAt:   pgtype.Timestamptz{Time: at, Valid: true},
+		BeforeCount: before,
+		AfterCount:  after,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msgs := toMessagesFromAround(rows)
+	s.enrichAssets(ctx, msgs)
+	return msgs, nil
+}
+
 // DeleteByBot deletes all messages for a bot.
 func (s *DBService) DeleteByBot(ctx context.Context, botID string) error {
 	pgBotID, err := dbpkg.ParseUUID(botID)
 	if err != nil {
 		return err
 	}
-	return s.queries.DeleteMessagesByBot(ctx, pgBotID)
+	if err := s.queries.DeleteMessagesByBot(ctx, pgBotID); err != nil {
+		return err
+	}
+	if s.indexer != nil {
+		if err := s.indexer.DeleteBot(ctx, botID); err != nil {
+			s.logger.Warn("delete message index failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+	}
+	return nil
 }
 
 func toMessageFromCreate(row sqlc.CreateMessageRow) Message {
@@ -428,6 +473,34 @@ func toMessagesFromBefore(rows []sqlc.ListMessagesBeforeRow) []Message {
 	return messages
 }
 
+func toMessageFromAroundRow(row sqlc.ListMessagesAroundRow) Message {
+	return toMessageFields(
+		row.ID,
+		row.BotID,
+		row.RouteID,
+		row.SenderChannelIdentityID,
+		row.SenderUserID,
+		row.SenderDisplayName,
+		row.SenderAvatarUrl,
+		row.Platform,
+		row.ExternalMessageID,
+		row.SourceReplyToMessageID,
+		row.Role,
+		row.Content,
+		row.Metadata,
+		row.Usage,
+		row.CreatedAt,
+	)
+}
+
+func toMessagesFromAround(rows []sqlc.ListMessagesAroundRow) []Message {
+	messages := make([]Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, toMessageFromAroundRow(row))
+	}
+	return messages
+}
+
 func parseOptionalUUID(id string) (pgtype.UUID, error) {
 	if strings.TrimSpace(id) == "" {
 		return pgtype.UUID{}, nil
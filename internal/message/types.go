@@ -79,3 +79,11 @@ type Service interface {
 	ListBefore(ctx context.Context, botID string, before time.Time, limit int32) ([]Message, error)
 	DeleteByBot(ctx context.Context, botID string) error
 }
+
+// Indexer keeps a search index of persisted messages in sync.
+type Indexer interface {
+	// IndexMessage is called on the persist path and must not block; the
+	// index queues the work.
+	IndexMessage(ctx context.Context, msg Message)
+	DeleteBot(ctx context.Context, botID string) error
+}
@@ -123,9 +123,9 @@ func (s *Service) List(ctx context.Context) ([]GetResponse, error) {
 	return convertToGetResponseList(dbModels), nil
 }
 
-// ListByType returns models filtered by type (chat or embedding)
+// ListByType returns models filtered by type (chat, embedding or rerank)
 func (s *Service) ListByType(ctx context.Context, modelType ModelType) ([]GetResponse, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !isValidModelType(modelType) {
 		return nil, fmt.Errorf("invalid model type: %s", modelType)
 	}
 
@@ -169,7 +169,7 @@ func (s *Service) ListByProviderID(ctx context.Context, providerID string) ([]Ge
 
 // ListByProviderIDAndType returns models filtered by provider ID and type.
 func (s *Service) ListByProviderIDAndType(ctx context.Context, providerID string, modelType ModelType) ([]GetResponse, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !isValidModelType(modelType) {
 		return nil, fmt.Errorf("invalid model type: %s", modelType)
 	}
 	if strings.TrimSpace(providerID) == "" {
@@ -321,7 +321,7 @@ func (s *Service) Count(ctx context.Context) (int64, error) {
 
 // CountByType returns the number of models of a specific type
 func (s *Service) CountByType(ctx context.Context, modelType ModelType) (int64, error) {
-	if modelType != ModelTypeChat && modelType != ModelTypeEmbedding {
+	if !isValidModelType(modelType) {
 		return 0, fmt.Errorf("invalid model type: %s", modelType)
 	}
 
@@ -380,7 +380,26 @@ func normalizeModalities(modalities []string, fallback []string) []string {
 	return modalities
 }
 
+func isValidModelType(modelType ModelType) bool {
+	switch modelType {
+	case ModelTypeChat, ModelTypeEmbedding, ModelTypeRerank:
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidClientType(clientType ClientType) bool {
+	switch clientType {
+	case ClientTypeOllama, ClientTypeCohere, ClientTypeVoyage:
+		return true
+	default:
+		return isChatClientType(clientType)
+	}
+}
+
+// isChatClientType reports whether chat models can use the client type.
+func isChatClientType(clientType ClientType) bool {
 	switch clientType {
 	case ClientTypeOpenAIResponses,
 		ClientTypeOpenAICompletions,
@@ -412,6 +431,67 @@ func SelectMemoryModel(ctx context.Context, modelsService *Service, queries *sql
 	return selected, provider, nil
 }
 
+// SelectRerankModelForBot returns the rerank model configured for a bot: a
+// rerank model, or a chat model used as an LLM judge. ok is false when the bot
+// has none.
+func SelectRerankModelForBot(ctx context.Context, queries *sqlc.Queries, botID string) (model GetResponse, provider sqlc.LlmProvider, ok bool, err error) {
+	if queries == nil {
+		return GetResponse{}, sqlc.LlmProvider{}, false, fmt.Errorf("queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(strings.TrimSpace(botID))
+	if err != nil {
+		return GetResponse{}, sqlc.LlmProvider{}, false, nil
+	}
+	settings, err := queries.GetSettingsByBotID(ctx, pgBotID)
+	if err != nil {
+		return GetResponse{}, sqlc.LlmProvider{}, false, err
+	}
+	if strings.TrimSpace(settings.RerankModelID.String) == "" {
+		return GetResponse{}, sqlc.LlmProvider{}, false, nil
+	}
+	dbModel, err := queries.GetModelByModelID(ctx, settings.RerankModelID.String)
+	if err != nil {
+		return GetResponse{}, sqlc.LlmProvider{}, false, err
+	}
+	model = convertToGetResponse(dbModel)
+	if model.Type != ModelTypeRerank && model.Type != ModelTypeChat {
+		return GetResponse{}, sqlc.LlmProvider{}, false, nil
+	}
+	provider, err = FetchProviderByID(ctx, queries, model.LlmProviderID)
+	if err != nil {
+		return GetResponse{}, sqlc.LlmProvider{}, false, err
+	}
+	return model, provider, true, nil
+}
+
+// SelectEmbeddingModelForBot returns the embedding model configured in bot
+// settings. ok is false when the bot has none.
+func SelectEmbeddingModelForBot(ctx context.Context, queries *sqlc.Queries, botID string) (model GetResponse, ok bool, err error) {
+	if queries == nil {
+		return GetResponse{}, false, fmt.Errorf("queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(strings.TrimSpace(botID))
+	if err != nil {
+		return GetResponse{}, false, nil
+	}
+	settings, err := queries.GetSettingsByBotID(ctx, pgBotID)
+	if err != nil {
+		return GetResponse{}, false, err
+	}
+	if strings.TrimSpace(settings.EmbeddingModelID.String) == "" {
+		return GetResponse{}, false, nil
+	}
+	dbModel, err := queries.GetModelByModelID(ctx, settings.EmbeddingModelID.String)
+	if err != nil {
+		return GetResponse{}, false, err
+	}
+	model = convertToGetResponse(dbModel)
+	if model.Type != ModelTypeEmbedding {
+		return GetResponse{}, false, nil
+	}
+	return model, true, nil
+}
+
 // SelectMemoryModelForBot selects memory model by bot settings first, then falls back to SelectMemoryModel.
 func SelectMemoryModelForBot(ctx context.Context, modelsService *Service, queries *sqlc.Queries, botID string) (GetResponse, sqlc.LlmProvider, error) {
 	botID = strings.TrimSpace(botID)
@@ -159,6 +159,36 @@ func TestModel_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "ollama embedding model",
+			model: models.Model{
+				ModelID:       "nomic-embed-text",
+				LlmProviderID: "11111111-1111-1111-1111-111111111111",
+				ClientType:    models.ClientTypeOllama,
+				Type:          models.ModelTypeEmbedding,
+				Dimensions:    768,
+			},
+			wantErr: false,
+		},
+		{
+			name: "chat model with embedding-only client_type",
+			model: models.Model{
+				ModelID:       "command-r",
+				LlmProviderID: "11111111-1111-1111-1111-111111111111",
+				ClientType:    models.ClientTypeCohere,
+				Type:          models.ModelTypeChat,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid rerank model",
+			model: models.Model{
+				ModelID:       "rerank-v3.5",
+				LlmProviderID: "11111111-1111-1111-1111-111111111111",
+				Type:          models.ModelTypeRerank,
+			},
+			wantErr: false,
+		},
 		{
 			name: "missing model_id",
 			model: models.Model{
@@ -12,6 +12,7 @@ type ModelType string
 const (
 	ModelTypeChat      ModelType = "chat"
 	ModelTypeEmbedding ModelType = "embedding"
+	ModelTypeRerank    ModelType = "rerank"
 )
 
 const (
@@ -29,6 +30,11 @@ const (
 	ClientTypeOpenAICompletions  ClientType = "openai-completions"
 	ClientTypeAnthropicMessages  ClientType = "anthropic-messages"
 	ClientTypeGoogleGenerativeAI ClientType = "google-generative-ai"
+
+	// Embedding-only client types.
+	ClientTypeOllama ClientType = "ollama"
+	ClientTypeCohere ClientType = "cohere"
+	ClientTypeVoyage ClientType = "voyage"
 )
 
 type Model struct {
@@ -57,17 +63,20 @@ func (m *Model) Validate() error {
 	if _, err := uuid.Parse(m.LlmProviderID); err != nil {
 		return errors.New("llm provider ID must be a valid UUID")
 	}
-	if m.Type != ModelTypeChat && m.Type != ModelTypeEmbedding {
+	if !isValidModelType(m.Type) {
 		return errors.New("invalid model type")
 	}
 	if m.Type == ModelTypeChat {
 		if m.ClientType == "" {
 			return errors.New("client_type is required for chat models")
 		}
-		if !isValidClientType(m.ClientType) {
+		if !isChatClientType(m.ClientType) {
 			return fmt.Errorf("invalid client_type: %s", m.ClientType)
 		}
 	}
+	if m.Type != ModelTypeChat && m.ClientType != "" && !isValidClientType(m.ClientType) {
+		return fmt.Errorf("invalid client_type: %s", m.ClientType)
+	}
 	if m.Type == ModelTypeEmbedding && m.Dimensions <= 0 {
 		return errors.New("dimensions must be greater than 0")
 	}
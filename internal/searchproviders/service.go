@@ -9,6 +9,7 @@ import (
 
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/db/sqlc"
+	"github.com/memohai/memoh/internal/secrets"
 )
 
 type Service struct {
@@ -36,26 +37,132 @@ func (s *Service) ListMeta(_ context.Context) []ProviderMeta {
 						Description: "Brave Search API key",
 						Required:    true,
 					},
-					"base_url": {
+					"base_url":        baseURLField("Brave API base URL", "https://api.search.brave.com/res/v1/web/search", false),
+					"timeout_seconds": timeoutField(),
+				},
+			},
+		},
+		{
+			Provider:    string(ProviderSearXNG),
+			DisplayName: "SearXNG",
+			ConfigSchema: ProviderConfigSchema{
+				Fields: map[string]ProviderFieldSchema{
+					"base_url": baseURLField("SearXNG instance URL; the JSON output format must be enabled", "https://searx.example.org", true),
+					"api_key": {
+						Type:        "secret",
+						Title:       "API Key",
+						Description: "Optional bearer token for protected instances",
+					},
+					"timeout_seconds": timeoutField(),
+				},
+			},
+		},
+		{
+			Provider:    string(ProviderTavily),
+			DisplayName: "Tavily",
+			ConfigSchema: ProviderConfigSchema{
+				Fields: map[string]ProviderFieldSchema{
+					"api_key": {
+						Type:        "secret",
+						Title:       "API Key",
+						Description: "Tavily API key",
+						Required:    true,
+					},
+					"search_depth": {
 						Type:        "string",
-						Title:       "Base URL",
-						Description: "Brave API base URL",
-						Required:    false,
-						Example:     "https://api.search.brave.com/res/v1/web/search",
+						Title:       "Search Depth",
+						Description: "Tavily search depth",
+						Enum:        []string{"basic", "advanced"},
+						Example:     "basic",
+					},
+					"base_url":        baseURLField("Tavily API base URL", "https://api.tavily.com/search", false),
+					"timeout_seconds": timeoutField(),
+				},
+			},
+		},
+		{
+			Provider:    string(ProviderBing),
+			DisplayName: "Bing",
+			ConfigSchema: ProviderConfigSchema{
+				Fields: map[string]ProviderFieldSchema{
+					"api_key": {
+						Type:        "secret",
+						Title:       "API Key",
+						Description: "Bing Web Search subscription key",
+						Required:    true,
 					},
-					"timeout_seconds": {
-						Type:        "number",
-						Title:       "Timeout (seconds)",
-						Description: "HTTP timeout in seconds",
-						Required:    false,
-						Example:     15,
+					"market": {
+						Type:        "string",
+						Title:       "Market",
+						Description: "Market code for results",
+						Example:     "en-US",
 					},
+					"base_url":        baseURLField("Bing Web Search API base URL", "https://api.bing.microsoft.com/v7.0/search", false),
+					"timeout_seconds": timeoutField(),
+				},
+			},
+		},
+		{
+			Provider:    string(ProviderGoogle),
+			DisplayName: "Google Programmable Search",
+			ConfigSchema: ProviderConfigSchema{
+				Fields: map[string]ProviderFieldSchema{
+					"api_key": {
+						Type:        "secret",
+						Title:       "API Key",
+						Description: "Google Custom Search JSON API key",
+						Required:    true,
+					},
+					"cx": {
+						Type:        "string",
+						Title:       "Search Engine ID",
+						Description: "Programmable Search Engine ID (cx)",
+						Required:    true,
+					},
+					"base_url":        baseURLField("Custom Search API base URL", "https://www.googleapis.com/customsearch/v1", false),
+					"timeout_seconds": timeoutField(),
+				},
+			},
+		},
+		{
+			Provider:    string(ProviderDuckDuckGo),
+			DisplayName: "DuckDuckGo",
+			ConfigSchema: ProviderConfigSchema{
+				Fields: map[string]ProviderFieldSchema{
+					"region": {
+						Type:        "string",
+						Title:       "Region",
+						Description: "DuckDuckGo region code",
+						Example:     "us-en",
+					},
+					"base_url":        baseURLField("DuckDuckGo HTML endpoint", "https://html.duckduckgo.com/html/", false),
+					"timeout_seconds": timeoutField(),
 				},
 			},
 		},
 	}
 }
 
+func baseURLField(description, example string, required bool) ProviderFieldSchema {
+	return ProviderFieldSchema{
+		Type:        "string",
+		Title:       "Base URL",
+		Description: description,
+		Required:    required,
+		Example:     example,
+	}
+}
+
+func timeoutField() ProviderFieldSchema {
+	return ProviderFieldSchema{
+		Type:        "number",
+		Title:       "Timeout (seconds)",
+		Description: "HTTP timeout in seconds",
+		Required:    false,
+		Example:     15,
+	}
+}
+
 func (s *Service) Create(ctx context.Context, req CreateRequest) (GetResponse, error) {
 	if !isValidProviderName(req.Provider) {
 		return GetResponse{}, fmt.Errorf("invalid provider: %s", req.Provider)
@@ -138,6 +245,7 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Get
 	}
 	config := current.Config
 	if req.Config != nil {
+		req.Config = s.restoreMaskedSecrets(provider, req.Config, current.Config)
 		configJSON, marshalErr := json.Marshal(req.Config)
 		if marshalErr != nil {
 			return GetResponse{}, fmt.Errorf("marshal config: %w", marshalErr)
@@ -171,6 +279,11 @@ func (s *Service) toGetResponse(row sqlc.SearchProvider) GetResponse {
 			s.logger.Warn("search provider config unmarshal failed", slog.String("id", row.ID.String()), slog.Any("error", err))
 		}
 	}
+	for key := range s.secretFields(row.Provider) {
+		if value, ok := cfg[key].(string); ok {
+			cfg[key] = secrets.Mask(value)
+		}
+	}
 	return GetResponse{
 		ID:        row.ID.String(),
 		Name:      row.Name,
@@ -181,9 +294,42 @@ func (s *Service) toGetResponse(row sqlc.SearchProvider) GetResponse {
 	}
 }
 
+// secretFields lists the config fields of a provider that hold credentials.
+func (s *Service) secretFields(provider string) map[string]struct{} {
+	fields := map[string]struct{}{}
+	for _, meta := range s.ListMeta(context.Background()) {
+		if meta.Provider != provider {
+			continue
+		}
+		for key, field := range meta.ConfigSchema.Fields {
+			if field.Type == "secret" {
+				fields[key] = struct{}{}
+			}
+		}
+	}
+	return fields
+}
+
+// restoreMaskedSecrets keeps stored credentials that a client sent back in
+// their masked form.
+func (s *Service) restoreMaskedSecrets(provider string, incoming map[string]any, stored []byte) map[string]any {
+	var current map[string]any
+	if err := json.Unmarshal(stored, &current); err != nil || len(current) == 0 {
+		return incoming
+	}
+	for key := range s.secretFields(provider) {
+		value, ok := incoming[key].(string)
+		existing, existingOK := current[key].(string)
+		if ok && existingOK {
+			incoming[key] = secrets.Unmask(value, existing)
+		}
+	}
+	return incoming
+}
+
 func isValidProviderName(name ProviderName) bool {
 	switch name {
-	case ProviderBrave:
+	case ProviderBrave, ProviderSearXNG, ProviderTavily, ProviderBing, ProviderGoogle, ProviderDuckDuckGo:
 		return true
 	default:
 		return false
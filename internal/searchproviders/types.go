@@ -5,7 +5,12 @@ import "time"
 type ProviderName string
 
 const (
-	ProviderBrave ProviderName = "brave"
+	ProviderBrave      ProviderName = "brave"
+	ProviderSearXNG    ProviderName = "searxng"
+	ProviderTavily     ProviderName = "tavily"
+	ProviderBing       ProviderName = "bing"
+	ProviderGoogle     ProviderName = "google"
+	ProviderDuckDuckGo ProviderName = "duckduckgo"
 )
 
 type ProviderConfigSchema struct {
@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+const keySize = 32
+
+// ErrUnknownKey means a data key was wrapped with a master key that is no
+// longer configured.
+var ErrUnknownKey = errors.New("secret master key not available")
+
+// Keyring implements envelope encryption. Every secret is sealed with its
+// own random data key, and the data key is sealed with the master key. A
+// master key rotation therefore only re-wraps data keys.
+type Keyring struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// LoadKeyring reads the master keys from config. It returns nil when no
+// master key is configured, which disables the vault.
+func LoadKeyring(cfg config.SecretsConfig) (*Keyring, error) {
+	raw := strings.TrimSpace(cfg.MasterKey)
+	if raw == "" && strings.TrimSpace(cfg.MasterKeyFile) != "" {
+		data, err := os.ReadFile(strings.TrimSpace(cfg.MasterKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("read master key file: %w", err)
+		}
+		raw = strings.TrimSpace(string(data))
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	current, err := parseMasterKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("master key: %w", err)
+	}
+	ring := &Keyring{
+		currentID: keyID(current),
+		keys:      map[string][]byte{},
+	}
+	ring.keys[ring.currentID] = current
+	for i, value := range cfg.PreviousMasterKeys {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		key, err := parseMasterKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("previous master key %d: %w", i+1, err)
+		}
+		ring.keys[keyID(key)] = key
+	}
+	return ring, nil
+}
+
+// CurrentKeyID identifies the master key new data keys are wrapped with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.currentID
+}
+
+// Encrypt seals plaintext with a new data key. aad binds the ciphertext to
+// its owner so rows cannot be swapped.
+func (k *Keyring) Encrypt(plaintext, aad []byte) (ciphertext, wrappedKey []byte, keyID string, err error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, "", err
+	}
+	ciphertext, err = seal(dataKey, plaintext, aad)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	wrappedKey, err = seal(k.keys[k.currentID], dataKey, []byte(k.currentID))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return ciphertext, wrappedKey, k.currentID, nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt.
+func (k *Keyring) Decrypt(ciphertext, wrappedKey []byte, keyID string, aad []byte) ([]byte, error) {
+	dataKey, err := k.unwrap(wrappedKey, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, ciphertext, aad)
+}
+
+// Rewrap re-seals a data key with the current master key.
+func (k *Keyring) Rewrap(wrappedKey []byte, keyID string) ([]byte, string, error) {
+	dataKey, err := k.unwrap(wrappedKey, keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	rewrapped, err := seal(k.keys[k.currentID], dataKey, []byte(k.currentID))
+	if err != nil {
+		return nil, "", err
+	}
+	return rewrapped, k.currentID, nil
+}
+
+func (k *Keyring) unwrap(wrappedKey []byte, keyID string) ([]byte, error) {
+	master, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return open(master, wrappedKey, []byte(keyID))
+}
+
+func parseMasterKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if key, err = base64.RawURLEncoding.DecodeString(value); err != nil {
+			return nil, fmt.Errorf("must be base64 encoded")
+		}
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// keyID fingerprints a master key without revealing it.
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// seal encrypts with AES-256-GCM and prepends the nonce.
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, data, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+func testMasterKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, keySize))
+}
+
+func TestLoadKeyringUnconfigured(t *testing.T) {
+	ring, err := LoadKeyring(config.SecretsConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ring != nil {
+		t.Fatal("expected no keyring without a master key")
+	}
+}
+
+func TestLoadKeyringRejectsInvalidKeys(t *testing.T) {
+	cases := []config.SecretsConfig{
+		{MasterKey: "not base64!"},
+		{MasterKey: base64.StdEncoding.EncodeToString([]byte("too short"))},
+		{MasterKey: testMasterKey(1), PreviousMasterKeys: []string{"short"}},
+	}
+	for _, cfg := range cases {
+		if _, err := LoadKeyring(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestLoadKeyringFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(path, []byte(testMasterKey(7)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := LoadKeyring(config.SecretsConfig{MasterKeyFile: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ring == nil || ring.CurrentKeyID() == "" {
+		t.Fatal("expected keyring loaded from file")
+	}
+}
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	ring, err := LoadKeyring(config.SecretsConfig{MasterKey: testMasterKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, wrappedKey, keyID, err := ring.Encrypt([]byte("hunter2"), []byte("bot/token"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if bytes.Contains(ciphertext, []byte("hunter2")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	plaintext, err := ring.Decrypt(ciphertext, wrappedKey, keyID, []byte("bot/token"))
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(plaintext) != "hunter2" {
+		t.Fatalf("plaintext = %q", plaintext)
+	}
+	if _, err := ring.Decrypt(ciphertext, wrappedKey, keyID, []byte("other-bot/token")); err == nil {
+		t.Fatal("expected decrypt with different associated data to fail")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldRing, err := LoadKeyring(config.SecretsConfig{MasterKey: testMasterKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, wrappedKey, oldID, err := oldRing.Encrypt([]byte("value"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newOnly, err := LoadKeyring(config.SecretsConfig{MasterKey: testMasterKey(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newOnly.Decrypt(ciphertext, wrappedKey, oldID, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+
+	rotated, err := LoadKeyring(config.SecretsConfig{
+		MasterKey:          testMasterKey(2),
+		PreviousMasterKeys: []string{testMasterKey(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrapped, newID, err := rotated.Rewrap(wrappedKey, oldID)
+	if err != nil {
+		t.Fatalf("rewrap: %v", err)
+	}
+	if newID == oldID || newID != rotated.CurrentKeyID() {
+		t.Fatalf("rewrapped key id = %s, want current %s", newID, rotated.CurrentKeyID())
+	}
+	plaintext, err := newOnly.Decrypt(ciphertext, rewrapped, newID, nil)
+	if err != nil {
+		t.Fatalf("decrypt after rewrap: %v", err)
+	}
+	if string(plaintext) != "value" {
+		t.Fatalf("plaintext = %q", plaintext)
+	}
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"regexp"
+	"strings"
+)
+
+// referencePattern matches ${secret:name} references.
+var referencePattern = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// HasReference reports whether value contains a ${secret:name} reference.
+func HasReference(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Reference returns the reference text for a secret name.
+func Reference(name string) string {
+	return "${secret:" + name + "}"
+}
+
+// referencedNames lists the secret names referenced in value.
+func referencedNames(value string) []string {
+	matches := referencePattern.FindAllStringSubmatch(value, -1)
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// Mask hides a credential for display: short values are fully masked,
+// longer ones keep their first four characters so they stay recognizable.
+// Values with secret references are shown as written, since the reference
+// is what the user configured and carries no secret itself.
+func Mask(value string) string {
+	if value == "" || HasReference(value) {
+		return value
+	}
+	runes := []rune(value)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + strings.Repeat("*", len(runes)-4)
+}
+
+// MaskMap masks every value of a string map.
+func MaskMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(values))
+	for key, value := range values {
+		masked[key] = Mask(value)
+	}
+	return masked
+}
+
+// Unmask returns the stored value when incoming is its masked form, so a
+// masked value sent back by a client does not overwrite the credential.
+func Unmask(incoming, stored string) string {
+	if stored != "" && incoming != stored && incoming == Mask(stored) {
+		return stored
+	}
+	return incoming
+}
+
+// UnmaskMap applies Unmask to every key present in both maps.
+func UnmaskMap(incoming, stored map[string]string) map[string]string {
+	if len(incoming) == 0 || len(stored) == 0 {
+		return incoming
+	}
+	out := make(map[string]string, len(incoming))
+	for key, value := range incoming {
+		out[key] = Unmask(value, stored[key])
+	}
+	return out
+}
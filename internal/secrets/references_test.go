@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestReferencedNames(t *testing.T) {
+	got := referencedNames("Bearer ${secret:api_token} for ${secret:org.id} and $HOME")
+	want := []string{"api_token", "org.id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("referencedNames = %v, want %v", got, want)
+	}
+	if HasReference("${secret:}") || HasReference("plain") {
+		t.Fatal("expected no reference")
+	}
+	if !HasReference(Reference("token")) {
+		t.Fatal("expected Reference output to be a reference")
+	}
+}
+
+func TestMask(t *testing.T) {
+	cases := map[string]string{
+		"":                   "",
+		"short":              "*****",
+		"sk-1234567890":      "sk-1*********",
+		"${secret:token}":    "${secret:token}",
+		"Bearer ${secret:x}": "Bearer ${secret:x}",
+	}
+	for input, want := range cases {
+		if got := Mask(input); got != want {
+			t.Fatalf("Mask(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestUnmaskMap(t *testing.T) {
+	stored := map[string]string{
+		"API_KEY": "sk-1234567890",
+		"REGION":  "eu",
+	}
+	incoming := MaskMap(stored)
+	incoming["REGION"] = "us"
+	incoming["NEW"] = "value"
+
+	got := UnmaskMap(incoming, stored)
+	want := map[string]string{
+		"API_KEY": "sk-1234567890",
+		"REGION":  "us",
+		"NEW":     "value",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnmaskMap = %v, want %v", got, want)
+	}
+}
+
+func TestExpandWithoutReferences(t *testing.T) {
+	svc := &Service{}
+	got, err := svc.Expand(context.Background(), "bot", "plain value")
+	if err != nil || got != "plain value" {
+		t.Fatalf("Expand = %q, %v", got, err)
+	}
+	creds, err := svc.ResolveCredentials(context.Background(), "bot", map[string]any{
+		"token":  "abc",
+		"nested": map[string]any{"port": 8080},
+	})
+	if err != nil {
+		t.Fatalf("ResolveCredentials: %v", err)
+	}
+	if creds["token"] != "abc" {
+		t.Fatalf("unexpected credentials: %v", creds)
+	}
+	if _, err := svc.Expand(context.Background(), "bot", "${secret:token}"); err == nil {
+		t.Fatal("expected reference to fail without a vault")
+	}
+}
+
+func TestReferenceVersionsWithoutReferences(t *testing.T) {
+	svc := &Service{}
+	got, err := svc.CredentialVersions(context.Background(), "bot", map[string]any{
+		"token":  "abc",
+		"nested": map[string]any{"secret": "plain"},
+	})
+	if err != nil || got != "" {
+		t.Fatalf("CredentialVersions = %q, %v", got, err)
+	}
+	if _, err := svc.ReferenceVersions(context.Background(), "bot", "${secret:token}"); err == nil {
+		t.Fatal("expected references to fail without queries")
+	}
+}
@@ -0,0 +1,391 @@
+// Package secrets is the credential vault of bots. Secrets are stored with
+// envelope encryption under a master key from config and are referenced as
+// ${secret:name} in MCP connection env and headers and in channel
+// credentials; references are resolved only when the credential is used.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+var (
+	// ErrVaultDisabled means no master key is configured.
+	ErrVaultDisabled = errors.New("secret vault is not configured")
+	// ErrSecretNotFound means the bot has no secret with the given name.
+	ErrSecretNotFound = errors.New("secret not found")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// Secret is the metadata of a stored secret. The value itself is never
+// returned, only its masked form.
+type Secret struct {
+	ID          string     `json:"id"`
+	BotID       string     `json:"bot_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Reference   string     `json:"reference"`
+	MaskedValue string     `json:"masked_value"`
+	Version     int        `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+}
+
+// UpsertRequest creates a secret or rotates its value.
+type UpsertRequest struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// ListResponse wraps secret list responses.
+type ListResponse struct {
+	Items []Secret `json:"items"`
+}
+
+// Service stores bot secrets and resolves references to them.
+type Service struct {
+	queries *sqlc.Queries
+	keyring *Keyring
+	logger  *slog.Logger
+}
+
+// NewService creates a Service. The vault is disabled when no master key is
+// configured; references then fail to resolve.
+func NewService(log *slog.Logger, cfg config.Config, queries *sqlc.Queries) (*Service, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	keyring, err := LoadKeyring(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	logger := log.With(slog.String("service", "secrets"))
+	if keyring == nil {
+		logger.Info("secret vault disabled: no master key configured")
+	}
+	return &Service{
+		queries: queries,
+		keyring: keyring,
+		logger:  logger,
+	}, nil
+}
+
+// Enabled reports whether a master key is configured.
+func (s *Service) Enabled() bool {
+	return s.keyring != nil
+}
+
+// List returns the secrets of a bot.
+func (s *Service) List(ctx context.Context, botID string) ([]Secret, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBotSecrets(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Secret, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, s.toSecret(row))
+	}
+	return items, nil
+}
+
+// Set stores a secret. Setting an existing name rotates it: the value gets
+// a new data key and the version increases.
+func (s *Service) Set(ctx context.Context, botID, name string, req UpsertRequest) (Secret, error) {
+	if err := s.ready(); err != nil {
+		return Secret{}, err
+	}
+	name = strings.TrimSpace(name)
+	if !namePattern.MatchString(name) {
+		return Secret{}, fmt.Errorf("invalid secret name %q", name)
+	}
+	if req.Value == "" {
+		return Secret{}, fmt.Errorf("value is required")
+	}
+	if HasReference(req.Value) {
+		return Secret{}, fmt.Errorf("secret values cannot contain references")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Secret{}, err
+	}
+	ciphertext, wrappedKey, keyID, err := s.keyring.Encrypt([]byte(req.Value), associatedData(botID, name))
+	if err != nil {
+		return Secret{}, err
+	}
+	row, err := s.queries.UpsertBotSecret(ctx, sqlc.UpsertBotSecretParams{
+		BotID:       pgBotID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Ciphertext:  ciphertext,
+		WrappedKey:  wrappedKey,
+		KeyID:       keyID,
+	})
+	if err != nil {
+		return Secret{}, err
+	}
+	return s.toSecret(row), nil
+}
+
+// Delete removes a secret. References to it stop resolving.
+func (s *Service) Delete(ctx context.Context, botID, name string) error {
+	if s.queries == nil {
+		return fmt.Errorf("secret queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.queries.DeleteBotSecret(ctx, sqlc.DeleteBotSecretParams{BotID: pgBotID, Name: strings.TrimSpace(name)})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSecretNotFound
+	}
+	return nil
+}
+
+// Value returns the plaintext of a secret.
+func (s *Service) Value(ctx context.Context, botID, name string) (string, error) {
+	if err := s.ready(); err != nil {
+		return "", err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return "", err
+	}
+	row, err := s.queries.GetBotSecret(ctx, sqlc.GetBotSecretParams{BotID: pgBotID, Name: name})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+		return "", err
+	}
+	return s.decrypt(row)
+}
+
+// Expand replaces the secret references in value with the bot's secrets.
+// Values without references are returned unchanged, even when the vault is
+// disabled.
+func (s *Service) Expand(ctx context.Context, botID, value string) (string, error) {
+	names := referencedNames(value)
+	if len(names) == 0 {
+		return value, nil
+	}
+	resolved := make(map[string]string, len(names))
+	for _, name := range names {
+		if _, ok := resolved[name]; ok {
+			continue
+		}
+		plaintext, err := s.Value(ctx, botID, name)
+		if err != nil {
+			return "", err
+		}
+		resolved[name] = plaintext
+	}
+	return referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		return resolved[referencePattern.FindStringSubmatch(match)[1]]
+	}), nil
+}
+
+// ExpandMap expands the references in every value of a string map.
+func (s *Service) ExpandMap(ctx context.Context, botID string, values map[string]string) (map[string]string, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		expanded, err := s.Expand(ctx, botID, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = expanded
+	}
+	return out, nil
+}
+
+// ResolveCredentials expands the references in the string values of a
+// credentials map, including nested maps.
+func (s *Service) ResolveCredentials(ctx context.Context, botID string, credentials map[string]any) (map[string]any, error) {
+	if len(credentials) == 0 {
+		return credentials, nil
+	}
+	out := make(map[string]any, len(credentials))
+	for key, raw := range credentials {
+		switch value := raw.(type) {
+		case string:
+			expanded, err := s.Expand(ctx, botID, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = expanded
+		case map[string]any:
+			nested, err := s.ResolveCredentials(ctx, botID, value)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = nested
+		default:
+			out[key] = raw
+		}
+	}
+	return out, nil
+}
+
+// ReferenceVersions identifies the secrets referenced in values by name and
+// version, without decrypting them. Holders of resolved values compare it to
+// notice rotated or deleted secrets. Values without references give "".
+func (s *Service) ReferenceVersions(ctx context.Context, botID string, values ...string) (string, error) {
+	names := make([]string, 0)
+	for _, value := range values {
+		names = append(names, referencedNames(value)...)
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	if s.queries == nil {
+		return "", fmt.Errorf("secret queries not configured")
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return "", err
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		row, err := s.queries.GetBotSecret(ctx, sqlc.GetBotSecretParams{BotID: pgBotID, Name: name})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			parts = append(parts, name+"@-")
+		case err != nil:
+			return "", err
+		default:
+			parts = append(parts, fmt.Sprintf("%s@%d", name, row.Version))
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// CredentialVersions is ReferenceVersions for the string values of a
+// credentials map, including nested maps.
+func (s *Service) CredentialVersions(ctx context.Context, botID string, credentials map[string]any) (string, error) {
+	return s.ReferenceVersions(ctx, botID, credentialStrings(credentials)...)
+}
+
+func credentialStrings(credentials map[string]any) []string {
+	values := make([]string, 0, len(credentials))
+	for _, raw := range credentials {
+		switch value := raw.(type) {
+		case string:
+			values = append(values, value)
+		case map[string]any:
+			values = append(values, credentialStrings(value)...)
+		}
+	}
+	return values
+}
+
+// RewrapKeys re-wraps data keys sealed with a previous master key using the
+// current one. Secrets whose master key is no longer configured are left
+// untouched and reported in the log.
+func (s *Service) RewrapKeys(ctx context.Context) (int, error) {
+	if !s.Enabled() || s.queries == nil {
+		return 0, nil
+	}
+	rows, err := s.queries.ListBotSecretsNotWrappedWith(ctx, s.keyring.CurrentKeyID())
+	if err != nil {
+		return 0, err
+	}
+	rewrapped := 0
+	for _, row := range rows {
+		wrappedKey, keyID, err := s.keyring.Rewrap(row.WrappedKey, row.KeyID)
+		if err != nil {
+			s.logger.Warn("rewrap secret failed",
+				slog.String("bot_id", row.BotID.String()),
+				slog.String("name", row.Name),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		if err := s.queries.UpdateBotSecretWrappedKey(ctx, sqlc.UpdateBotSecretWrappedKeyParams{
+			WrappedKey: wrappedKey,
+			KeyID:      keyID,
+			ID:         row.ID,
+		}); err != nil {
+			return rewrapped, err
+		}
+		rewrapped++
+	}
+	if rewrapped > 0 {
+		s.logger.Info("secrets rewrapped with current master key", slog.Int("count", rewrapped))
+	}
+	return rewrapped, nil
+}
+
+func (s *Service) ready() error {
+	if s.queries == nil {
+		return fmt.Errorf("secret queries not configured")
+	}
+	if !s.Enabled() {
+		return ErrVaultDisabled
+	}
+	return nil
+}
+
+func (s *Service) decrypt(row sqlc.BotSecret) (string, error) {
+	plaintext, err := s.keyring.Decrypt(row.Ciphertext, row.WrappedKey, row.KeyID, associatedData(row.BotID.String(), row.Name))
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %s: %w", row.Name, err)
+	}
+	return string(plaintext), nil
+}
+
+func (s *Service) toSecret(row sqlc.BotSecret) Secret {
+	item := Secret{
+		ID:          row.ID.String(),
+		BotID:       row.BotID.String(),
+		Name:        row.Name,
+		Description: row.Description,
+		Reference:   Reference(row.Name),
+		Version:     int(row.Version),
+		CreatedAt:   db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:   db.TimeFromPg(row.UpdatedAt),
+	}
+	if plaintext, err := s.decrypt(row); err == nil {
+		item.MaskedValue = Mask(plaintext)
+	} else {
+		s.logger.Warn("secret cannot be decrypted", slog.String("bot_id", item.BotID), slog.String("name", row.Name), slog.Any("error", err))
+	}
+	if row.RotatedAt.Valid {
+		rotatedAt := row.RotatedAt.Time
+		item.RotatedAt = &rotatedAt
+	}
+	return item
+}
+
+// associatedData binds a ciphertext to the bot and name it is stored under.
+func associatedData(botID, name string) []byte {
+	return []byte(strings.TrimSpace(botID) + "/" + name)
+}
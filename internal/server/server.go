@@ -48,12 +48,16 @@ func NewServer(log *slog.Logger, addr string, jwtSecret string,
 	}))
 	e.Use(auth.JWTMiddleware(jwtSecret, func(c echo.Context) bool {
 		path := c.Request().URL.Path
-		if path == "/ping" || path == "/health" || path == "/api/swagger.json" || path == "/auth/login" {
+		if path == "/ping" || path == "/health" || path == "/api/swagger.json" || path == "/auth/login" || path == "/mcp/oauth/callback" {
 			return true
 		}
 		if strings.HasPrefix(path, "/api/docs") {
 			return true
 		}
+		// Bot MCP servers authenticate with their own scoped tokens.
+		if strings.HasPrefix(path, "/mcp/bots/") {
+			return true
+		}
 		return false
 	}))
 
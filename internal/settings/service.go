@@ -8,21 +8,25 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/db/sqlc"
 )
 
 type Service struct {
+	pool    *pgxpool.Pool
 	queries *sqlc.Queries
 	logger  *slog.Logger
 }
 
 var ErrPersonalBotGuestAccessUnsupported = errors.New("personal bots do not support guest access")
 
-func NewService(log *slog.Logger, queries *sqlc.Queries) *Service {
+func NewService(log *slog.Logger, pool *pgxpool.Pool, queries *sqlc.Queries) *Service {
 	return &Service{
+		pool:    pool,
 		queries: queries,
 		logger:  log.With(slog.String("service", "settings")),
 	}
@@ -37,7 +41,16 @@ func (s *Service) GetBot(ctx context.Context, botID string) (Settings, error) {
 	if err != nil {
 		return Settings{}, err
 	}
-	return normalizeBotSettingsReadRow(row), nil
+	settings := normalizeBotSettingsReadRow(row)
+	fallbacks, err := s.queries.ListBotFallbackModels(ctx, pgID)
+	if err != nil {
+		return Settings{}, err
+	}
+	settings.FallbackModelIDs = normalizeFallbackModelIDs(fallbacks)
+	if settings.FallbackSearchProviderIDs, err = s.listFallbackSearchProviderIDs(ctx, pgID); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
 }
 
 func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest) (Settings, error) {
@@ -100,6 +113,23 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		}
 		embeddingModelUUID = modelID
 	}
+	rerankModelUUID := pgtype.UUID{}
+	clearRerankModel := false
+	if req.RerankModelID != nil {
+		value := strings.TrimSpace(*req.RerankModelID)
+		if value == "" {
+			clearRerankModel = true
+		} else {
+			row, err := s.queries.GetModelByModelID(ctx, value)
+			if err != nil {
+				return Settings{}, err
+			}
+			if row.Type != "rerank" && row.Type != "chat" {
+				return Settings{}, fmt.Errorf("rerank model %q must be a rerank or chat model", value)
+			}
+			rerankModelUUID = row.ID
+		}
+	}
 	searchProviderUUID := pgtype.UUID{}
 	if value := strings.TrimSpace(req.SearchProviderID); value != "" {
 		providerID, err := db.ParseUUID(value)
@@ -109,7 +139,34 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		searchProviderUUID = providerID
 	}
 
-	updated, err := s.queries.UpsertBotSettings(ctx, sqlc.UpsertBotSettingsParams{
+	var fallbackUUIDs []pgtype.UUID
+	if req.FallbackModelIDs != nil {
+		fallbackUUIDs, err = s.resolveFallbackModelUUIDs(ctx, req.FallbackModelIDs)
+		if err != nil {
+			return Settings{}, err
+		}
+	}
+
+	var fallbackSearchUUIDs []pgtype.UUID
+	if req.FallbackSearchProviderIDs != nil {
+		fallbackSearchUUIDs, err = s.resolveFallbackSearchProviderUUIDs(ctx, req.FallbackSearchProviderIDs)
+		if err != nil {
+			return Settings{}, err
+		}
+	}
+
+	// The settings and both fallback chains change together, so a failed
+	// write never leaves a bot with a partial chain.
+	queries := s.queries
+	var tx pgx.Tx
+	if s.pool != nil {
+		if tx, err = s.pool.BeginTx(ctx, pgx.TxOptions{}); err != nil {
+			return Settings{}, err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		queries = s.queries.WithTx(tx)
+	}
+	updated, err := queries.UpsertBotSettings(ctx, sqlc.UpsertBotSettingsParams{
 		ID:                 pgID,
 		MaxContextLoadTime: int32(current.MaxContextLoadTime),
 		MaxContextTokens:   int32(current.MaxContextTokens),
@@ -120,11 +177,37 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		MemoryModelID:      memoryModelUUID,
 		EmbeddingModelID:   embeddingModelUUID,
 		SearchProviderID:   searchProviderUUID,
+		ClearRerankModel:   clearRerankModel,
+		RerankModelID:      rerankModelUUID,
 	})
 	if err != nil {
 		return Settings{}, err
 	}
-	return normalizeBotSettingsWriteRow(updated), nil
+	if req.FallbackModelIDs != nil {
+		if err := replaceFallbackModels(ctx, queries, pgID, fallbackUUIDs); err != nil {
+			return Settings{}, err
+		}
+	}
+	if req.FallbackSearchProviderIDs != nil {
+		if err := replaceFallbackSearchProviders(ctx, queries, pgID, fallbackSearchUUIDs); err != nil {
+			return Settings{}, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return Settings{}, err
+		}
+	}
+	settings := normalizeBotSettingsWriteRow(updated)
+	fallbacks, err := s.queries.ListBotFallbackModels(ctx, pgID)
+	if err != nil {
+		return Settings{}, err
+	}
+	settings.FallbackModelIDs = normalizeFallbackModelIDs(fallbacks)
+	if settings.FallbackSearchProviderIDs, err = s.listFallbackSearchProviderIDs(ctx, pgID); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
 }
 
 func (s *Service) Delete(ctx context.Context, botID string) error {
@@ -135,7 +218,28 @@ func (s *Service) Delete(ctx context.Context, botID string) error {
 	if err != nil {
 		return err
 	}
-	return s.queries.DeleteSettingsByBotID(ctx, pgID)
+	if s.pool == nil {
+		return deleteBotSettings(ctx, s.queries, pgID)
+	}
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := deleteBotSettings(ctx, s.queries.WithTx(tx), pgID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func deleteBotSettings(ctx context.Context, queries *sqlc.Queries, botID pgtype.UUID) error {
+	if err := queries.DeleteBotFallbackModels(ctx, botID); err != nil {
+		return err
+	}
+	if err := queries.DeleteBotFallbackSearchProviders(ctx, botID); err != nil {
+		return err
+	}
+	return queries.DeleteSettingsByBotID(ctx, botID)
 }
 
 func normalizeBotSetting(maxContextLoadTime int32, maxContextTokens int32, maxInboxItems int32, language string, allowGuest bool) Settings {
@@ -172,6 +276,7 @@ func normalizeBotSettingsReadRow(row sqlc.GetSettingsByBotIDRow) Settings {
 		row.MemoryModelID,
 		row.EmbeddingModelID,
 		row.SearchProviderID,
+		row.RerankModelID,
 	)
 }
 
@@ -186,6 +291,7 @@ func normalizeBotSettingsWriteRow(row sqlc.UpsertBotSettingsRow) Settings {
 		row.MemoryModelID,
 		row.EmbeddingModelID,
 		row.SearchProviderID,
+		row.RerankModelID,
 	)
 }
 
@@ -199,11 +305,13 @@ func normalizeBotSettingsFields(
 	memoryModelID pgtype.Text,
 	embeddingModelID pgtype.Text,
 	searchProviderID pgtype.UUID,
+	rerankModelID pgtype.Text,
 ) Settings {
 	settings := normalizeBotSetting(maxContextLoadTime, maxContextTokens, maxInboxItems, language, allowGuest)
 	settings.ChatModelID = strings.TrimSpace(chatModelID.String)
 	settings.MemoryModelID = strings.TrimSpace(memoryModelID.String)
 	settings.EmbeddingModelID = strings.TrimSpace(embeddingModelID.String)
+	settings.RerankModelID = strings.TrimSpace(rerankModelID.String)
 	if searchProviderID.Valid {
 		settings.SearchProviderID = uuid.UUID(searchProviderID.Bytes).String()
 	}
@@ -220,3 +328,112 @@ func (s *Service) resolveModelUUID(ctx context.Context, modelID string) (pgtype.
 	}
 	return row.ID, nil
 }
+
+// resolveFallbackModelUUIDs validates a fallback chain: every entry must be a
+// chat model, and duplicates keep their first position.
+func (s *Service) resolveFallbackModelUUIDs(ctx context.Context, modelIDs []string) ([]pgtype.UUID, error) {
+	seen := make(map[string]struct{}, len(modelIDs))
+	result := make([]pgtype.UUID, 0, len(modelIDs))
+	for _, raw := range modelIDs {
+		modelID := strings.TrimSpace(raw)
+		if modelID == "" {
+			continue
+		}
+		if _, ok := seen[modelID]; ok {
+			continue
+		}
+		seen[modelID] = struct{}{}
+		row, err := s.queries.GetModelByModelID(ctx, modelID)
+		if err != nil {
+			return nil, fmt.Errorf("fallback model %q: %w", modelID, err)
+		}
+		if row.Type != "chat" {
+			return nil, fmt.Errorf("fallback model %q is not a chat model", modelID)
+		}
+		result = append(result, row.ID)
+	}
+	return result, nil
+}
+
+func replaceFallbackModels(ctx context.Context, queries *sqlc.Queries, botID pgtype.UUID, modelIDs []pgtype.UUID) error {
+	if err := queries.DeleteBotFallbackModels(ctx, botID); err != nil {
+		return err
+	}
+	for i, modelID := range modelIDs {
+		if err := queries.InsertBotFallbackModel(ctx, sqlc.InsertBotFallbackModelParams{
+			BotID:    botID,
+			ModelID:  modelID,
+			Priority: int32(i),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveFallbackSearchProviderUUIDs validates a search fallback chain: every
+// entry must be an existing search provider, and duplicates keep their first
+// position.
+func (s *Service) resolveFallbackSearchProviderUUIDs(ctx context.Context, providerIDs []string) ([]pgtype.UUID, error) {
+	seen := make(map[string]struct{}, len(providerIDs))
+	result := make([]pgtype.UUID, 0, len(providerIDs))
+	for _, raw := range providerIDs {
+		providerID := strings.TrimSpace(raw)
+		if providerID == "" {
+			continue
+		}
+		if _, ok := seen[providerID]; ok {
+			continue
+		}
+		seen[providerID] = struct{}{}
+		pgID, err := db.ParseUUID(providerID)
+		if err != nil {
+			return nil, fmt.Errorf("fallback search provider %q: %w", providerID, err)
+		}
+		if _, err := s.queries.GetSearchProviderByID(ctx, pgID); err != nil {
+			return nil, fmt.Errorf("fallback search provider %q: %w", providerID, err)
+		}
+		result = append(result, pgID)
+	}
+	return result, nil
+}
+
+func replaceFallbackSearchProviders(ctx context.Context, queries *sqlc.Queries, botID pgtype.UUID, providerIDs []pgtype.UUID) error {
+	if err := queries.DeleteBotFallbackSearchProviders(ctx, botID); err != nil {
+		return err
+	}
+	for i, providerID := range providerIDs {
+		if err := queries.InsertBotFallbackSearchProvider(ctx, sqlc.InsertBotFallbackSearchProviderParams{
+			BotID:            botID,
+			SearchProviderID: providerID,
+			Priority:         int32(i),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) listFallbackSearchProviderIDs(ctx context.Context, botID pgtype.UUID) ([]string, error) {
+	rows, err := s.queries.ListBotFallbackSearchProviders(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Valid {
+			result = append(result, row.String())
+		}
+	}
+	return result, nil
+}
+
+func normalizeFallbackModelIDs(modelIDs []string) []string {
+	result := make([]string, 0, len(modelIDs))
+	for _, modelID := range modelIDs {
+		if value := strings.TrimSpace(modelID); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
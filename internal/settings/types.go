@@ -16,6 +16,14 @@ type Settings struct {
 	MaxInboxItems      int    `json:"max_inbox_items"`
 	Language           string `json:"language"`
 	AllowGuest         bool   `json:"allow_guest"`
+	// FallbackModelIDs are chat models tried in order when the chat model fails.
+	FallbackModelIDs []string `json:"fallback_model_ids"`
+	// RerankModelID re-scores memory search results: a rerank model, or a
+	// chat model acting as an LLM judge. Empty disables reranking.
+	RerankModelID string `json:"rerank_model_id"`
+	// FallbackSearchProviderIDs are search providers tried in order when the
+	// search provider fails.
+	FallbackSearchProviderIDs []string `json:"fallback_search_provider_ids"`
 }
 
 type UpsertRequest struct {
@@ -28,4 +36,12 @@ type UpsertRequest struct {
 	MaxInboxItems      *int   `json:"max_inbox_items,omitempty"`
 	Language           string `json:"language,omitempty"`
 	AllowGuest         *bool  `json:"allow_guest,omitempty"`
+	// FallbackModelIDs replaces the fallback chain when set; an empty list clears it.
+	FallbackModelIDs []string `json:"fallback_model_ids,omitempty"`
+	// RerankModelID replaces the rerank model when set; an empty string
+	// disables reranking.
+	RerankModelID *string `json:"rerank_model_id,omitempty"`
+	// FallbackSearchProviderIDs replaces the search fallback chain when set; an
+	// empty list clears it.
+	FallbackSearchProviderIDs []string `json:"fallback_search_provider_ids,omitempty"`
 }